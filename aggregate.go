@@ -0,0 +1,165 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// Count returns the number of rows of the result.
+func (r *Result) Count() int {
+	return len(r.Rows)
+}
+
+// CountBy returns the number of rows of the result per value of the
+// column, e.g. the number of logical ports per datapath. The references
+// are counted by UUID, and the empty optional values under nil.
+func (r *Result) CountBy(column string) map[interface{}]int {
+	counts := make(map[interface{}]int)
+	for _, row := range r.Rows {
+		counts[sortKey(row[column])]++
+	}
+	return counts
+}
+
+// SumInt returns the sum of the integer column over the rows of the
+// result, e.g. n_packets. The empty optional values count as zero.
+func (r *Result) SumInt(column string) (int64, error) {
+	var sum int64
+	for i, row := range r.Rows {
+		var n int64
+		if err := row.Get(column, &n); err != nil {
+			return 0, fmt.Errorf("sum: row %d: %w", i, err)
+		}
+		sum += n
+	}
+	return sum, nil
+}
+
+// SumFloat returns the sum of the integer or real column over the rows of
+// the result.
+func (r *Result) SumFloat(column string) (float64, error) {
+	var sum float64
+	for i, row := range r.Rows {
+		var n float64
+		if err := row.Get(column, &n); err != nil {
+			return 0, fmt.Errorf("sum: row %d: %w", i, err)
+		}
+		sum += n
+	}
+	return sum, nil
+}
+
+// Min returns the smallest value of the column over the rows of the
+// result, compared as by ORDER BY, see Operation.Sort. The empty optional
+// values are skipped. It returns nil when there are no values.
+func (r *Result) Min(column string) interface{} {
+	return r.extreme(column, -1)
+}
+
+// Max returns the largest value of the column over the rows of the result,
+// see Min.
+func (r *Result) Max(column string) interface{} {
+	return r.extreme(column, 1)
+}
+
+// extreme returns the value of the column which compares to the others as
+// the sign, i.e. -1 for the smallest.
+func (r *Result) extreme(column string, sign int) interface{} {
+	var out interface{}
+	for _, row := range r.Rows {
+		v := sortKey(row[column])
+		if v == nil {
+			continue
+		}
+		if out == nil || compareAtoms(v, out) == sign {
+			out = v
+		}
+	}
+	return out
+}
+
+// Count returns the number of rows of the table matching the conditions.
+// Only the UUIDs of the rows are retrieved, and they are counted as they
+// are decoded, without holding the rows in memory.
+func (c *Client) Count(ctx context.Context, db, table string, where ...Condition) (int, error) {
+	if c == nil {
+		return 0, fmt.Errorf("interface is unavailable")
+	}
+	method := "transact"
+	op := Select(table, []string{"_uuid"}, where...)
+	if err := op.Validate(); err != nil {
+		return 0, fmt.Errorf("'%s' method, count of %s failed: %s", method, table, err)
+	}
+	ctx, done, err := c.admit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' method, count of %s failed: %w", method, table, err)
+	}
+	defer done()
+	response, err := c.transact(ctx, Transaction{Database: db, Operations: []Operation{op}})
+	if err != nil {
+		return 0, fmt.Errorf("'%s' method, count of %s failed: %w", method, table, err)
+	}
+	if err := operationError(response.Result); err != nil {
+		return 0, fmt.Errorf("'%s' method, count of %s failed: %w", method, table, err)
+	}
+	n := 0
+	if err := decodeRows(response.Result, func(Row) error {
+		n++
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("'%s' method, count of %s failed: %w", method, table, err)
+	}
+	return n, nil
+}
+
+// TableStat is the number of rows of a table, and the maximum number of
+// rows of the table in the schema, 0 when it is not limited.
+type TableStat struct {
+	Table   string
+	Rows    int
+	MaxRows int
+}
+
+// TableStats returns the number of rows of every table of the database,
+// sorted by table. The UUIDs of the rows of all tables are retrieved with a
+// single transaction, so that the counts are consistent with each other.
+func (c *Client) TableStats(ctx context.Context, db string) ([]TableStat, error) {
+	if c == nil {
+		return nil, fmt.Errorf("interface is unavailable")
+	}
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	tables := schema.GetTables()
+	if len(tables) == 0 {
+		return []TableStat{}, nil
+	}
+	ops := make([]Operation, len(tables))
+	for i, table := range tables {
+		ops[i] = Select(table, []string{"_uuid"})
+	}
+	results, err := c.TransactOperations(ctx, db, ops...)
+	if err != nil {
+		return nil, fmt.Errorf("table stats of '%s' database failed: %w", db, err)
+	}
+	stats := make([]TableStat, len(tables))
+	for i, table := range tables {
+		stats[i] = TableStat{Table: table, Rows: len(results[i].Rows), MaxRows: schema.Tables[table].MaxRows}
+	}
+	return stats, nil
+}
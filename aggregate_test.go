@@ -0,0 +1,174 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestResultAggregates(t *testing.T) {
+	var r Result
+	data := `{"rows":[
+		{"datapath":["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"],"n_packets":10,"tunnel_key":3,"chassis":["set",[]]},
+		{"datapath":["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"],"n_packets":5,"tunnel_key":1,"chassis":"hv1"},
+		{"datapath":["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b2"],"n_packets":0,"tunnel_key":2,"chassis":"hv2"}
+	]}`
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatalf("FAIL: failed to decode the result: %v", err)
+	}
+	if r.Count() != 3 {
+		t.Fatalf("FAIL: expected 3 rows, got %d", r.Count())
+	}
+	counts := r.CountBy("datapath")
+	if counts[UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1")] != 2 || counts[UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b2")] != 1 {
+		t.Fatalf("FAIL: unexpected counts per datapath: %v", counts)
+	}
+	if counts := r.CountBy("chassis"); counts[nil] != 1 || counts["hv1"] != 1 {
+		t.Fatalf("FAIL: unexpected counts per chassis: %v", counts)
+	}
+	t.Logf("PASS: counted the rows")
+
+	if sum, err := r.SumInt("n_packets"); err != nil || sum != 15 {
+		t.Fatalf("FAIL: expected a sum of 15, got %d, %v", sum, err)
+	}
+	if sum, err := r.SumFloat("n_packets"); err != nil || sum != 15 {
+		t.Fatalf("FAIL: expected a sum of 15, got %v, %v", sum, err)
+	}
+	if _, err := r.SumInt("chassis"); err == nil {
+		t.Fatalf("FAIL: expected an error for the sum of a string column")
+	}
+	if _, err := r.SumInt("missing"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("FAIL: expected ErrColumnNotFound, got %v", err)
+	}
+	t.Logf("PASS: summed the rows")
+
+	for _, test := range []struct {
+		got, want interface{}
+	}{
+		{r.Min("tunnel_key"), int64(1)},
+		{r.Max("tunnel_key"), int64(3)},
+		{r.Min("chassis"), "hv1"},
+		{r.Max("chassis"), "hv2"},
+		{r.Max("missing"), nil},
+	} {
+		if test.got != test.want {
+			t.Fatalf("FAIL: expected %#v, got %#v", test.want, test.got)
+		}
+	}
+	t.Logf("PASS: min and max of the rows")
+}
+
+func TestClientCount(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		var op struct {
+			Table   string   `json:"table"`
+			Columns []string `json:"columns"`
+		}
+		json.Unmarshal(args[1], &op)
+		if op.Table == "Missing" {
+			return []interface{}{map[string]interface{}{"error": "unknown table"}}
+		}
+		if len(op.Columns) != 1 || op.Columns[0] != "_uuid" {
+			return []interface{}{map[string]interface{}{"error": "expected the _uuid column only"}}
+		}
+		rows := []interface{}{}
+		for i := 0; i < 42; i++ {
+			rows = append(rows, map[string]interface{}{"_uuid": []interface{}{"uuid", fmt.Sprintf("00000000-0000-4000-8000-%012d", i)}})
+		}
+		return []interface{}{map[string]interface{}{"rows": rows}}
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	n, err := cli.Count(context.Background(), "OVN_Southbound", "Port_Binding", Equal("type", ""))
+	if err != nil || n != 42 {
+		t.Fatalf("FAIL: expected 42 rows, got %d, %v", n, err)
+	}
+	t.Logf("PASS: counted %d rows", n)
+	if _, err := cli.Count(context.Background(), "OVN_Southbound", "Missing"); err == nil {
+		t.Fatalf("FAIL: expected an error for a missing table")
+	}
+	t.Logf("PASS: count failed for a missing table")
+}
+
+func TestClientTableStats(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	schema := `{"name": "Test", "version": "1.0.0", "tables": {
+		"Global": {"columns": {"name": {"type": "string"}}, "maxRows": 1},
+		"Switch": {"columns": {"name": {"type": "string"}}}}}`
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(schema)
+		case "transact":
+			var args []json.RawMessage
+			json.Unmarshal(params, &args)
+			if len(args) != 3 {
+				return []interface{}{map[string]interface{}{"error": "expected a select of each table"}}
+			}
+			results := []interface{}{}
+			for i, n := range []int{1, 3} {
+				var op struct {
+					Table   string   `json:"table"`
+					Columns []string `json:"columns"`
+				}
+				json.Unmarshal(args[i+1], &op)
+				if len(op.Columns) != 1 || op.Columns[0] != "_uuid" {
+					return []interface{}{map[string]interface{}{"error": "expected the _uuid column only"}}
+				}
+				rows := []interface{}{}
+				for j := 0; j < n; j++ {
+					rows = append(rows, map[string]interface{}{"_uuid": []interface{}{"uuid", fmt.Sprintf("00000000-0000-4000-8000-%012d", j)}})
+				}
+				results = append(results, map[string]interface{}{"rows": rows})
+			}
+			return results
+		}
+		return nil
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	stats, err := cli.TableStats(context.Background(), "Test")
+	expected := []TableStat{{Table: "Global", Rows: 1, MaxRows: 1}, {Table: "Switch", Rows: 3}}
+	if err != nil || !reflect.DeepEqual(stats, expected) {
+		t.Fatalf("FAIL: expected %+v, got %+v, %v", expected, stats, err)
+	}
+	t.Logf("PASS: table stats: %+v", stats)
+}
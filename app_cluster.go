@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 	"strconv"
@@ -64,6 +65,13 @@ type ClusterState struct {
 
 // GetAppClusteringInfo returns the counters associated with clustering setup.
 func (cli *OvnClient) GetAppClusteringInfo(db string) (ClusterState, error) {
+	return cli.GetAppClusteringInfoContext(context.Background(), db)
+}
+
+// GetAppClusteringInfoContext is like GetAppClusteringInfo, but honors the context.
+func (cli *OvnClient) GetAppClusteringInfoContext(ctx context.Context, db string) (ClusterState, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
 	var app Client
 	var dbName string
 	var err error
@@ -72,10 +80,10 @@ func (cli *OvnClient) GetAppClusteringInfo(db string) (ClusterState, error) {
 	cmd := "cluster/status"
 	switch db {
 	case "ovsdb-server-northbound":
-		app, err = NewClient(cli.Database.Northbound.Socket.Control, cli.Timeout)
+		app, err = NewClientContext(ctx, cli.Database.Northbound.Socket.Control, cli.Timeout, cli.controlOptions()...)
 		dbName = cli.Database.Northbound.Name
 	case "ovsdb-server-southbound":
-		app, err = NewClient(cli.Database.Southbound.Socket.Control, cli.Timeout)
+		app, err = NewClientContext(ctx, cli.Database.Southbound.Socket.Control, cli.Timeout, cli.controlOptions()...)
 		dbName = cli.Database.Southbound.Name
 	default:
 		return server, fmt.Errorf("The '%s' database is unsupported for '%s'", db, cmd)
@@ -84,7 +92,7 @@ func (cli *OvnClient) GetAppClusteringInfo(db string) (ClusterState, error) {
 		app.Close()
 		return server, fmt.Errorf("failed '%s' from %s: %s", cmd, db, err)
 	}
-	r, err := app.query(cmd, dbName)
+	r, err := app.queryContext(ctx, cmd, dbName)
 	if err != nil {
 		app.Close()
 		return server, fmt.Errorf("the '%s' command failed for %s: %s", cmd, db, err)
@@ -15,22 +15,23 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
-func getAppCoverageMetrics(db string, sock string, timeout int) (map[string]map[string]float64, error) {
+func getAppCoverageMetrics(ctx context.Context, db string, sock string, timeout int, opts []ClientOption) (map[string]map[string]float64, error) {
 	var app Client
 	var err error
 	cmd := "coverage/show"
 	metrics := make(map[string]map[string]float64)
-	app, err = NewClient(sock, timeout)
+	app, err = NewClientContext(ctx, sock, timeout, opts...)
 	if err != nil {
 		app.Close()
 		return metrics, fmt.Errorf("failed '%s' from %s: %s", cmd, db, err)
 	}
-	r, err := app.query(cmd, nil)
+	r, err := app.queryContext(ctx, cmd, nil)
 	if err != nil {
 		app.Close()
 		return metrics, fmt.Errorf("the '%s' command failed for %s: %s", cmd, db, err)
@@ -71,13 +72,19 @@ func getAppCoverageMetrics(db string, sock string, timeout int) (map[string]map[
 // rates for the last few seconds, the last minute and the last hour, and the
 // total counts of all of the coverage counters.
 func (cli *OvnClient) GetAppCoverageMetrics(db string) (map[string]map[string]float64, error) {
-	cli.updateRefs()
+	return cli.GetAppCoverageMetricsContext(context.Background(), db)
+}
+
+// GetAppCoverageMetricsContext is like GetAppCoverageMetrics, but honors the context.
+func (cli *OvnClient) GetAppCoverageMetricsContext(ctx context.Context, db string) (map[string]map[string]float64, error) {
+	cli.readLockRefs()
+	defer cli.mux.RUnlock()
 	cmd := "coverage/show"
 	switch db {
 	case "ovsdb-server-northbound":
-		return getAppCoverageMetrics(db, cli.Database.Northbound.Socket.Control, cli.Timeout)
+		return getAppCoverageMetrics(ctx, db, cli.Database.Northbound.Socket.Control, cli.Timeout, cli.controlOptions())
 	case "ovsdb-server-southbound":
-		return getAppCoverageMetrics(db, cli.Database.Southbound.Socket.Control, cli.Timeout)
+		return getAppCoverageMetrics(ctx, db, cli.Database.Southbound.Socket.Control, cli.Timeout, cli.controlOptions())
 	default:
 		return nil, fmt.Errorf("The '%s' database is unsupported for '%s'", db, cmd)
 	}
@@ -88,13 +95,19 @@ func (cli *OvnClient) GetAppCoverageMetrics(db string) (map[string]map[string]fl
 // rates for the last few seconds, the last minute and the last hour, and the
 // total counts of all of the coverage counters.
 func (cli *OvsClient) GetAppCoverageMetrics(db string) (map[string]map[string]float64, error) {
-	cli.updateRefs()
+	return cli.GetAppCoverageMetricsContext(context.Background(), db)
+}
+
+// GetAppCoverageMetricsContext is like GetAppCoverageMetrics, but honors the context.
+func (cli *OvsClient) GetAppCoverageMetricsContext(ctx context.Context, db string) (map[string]map[string]float64, error) {
+	cli.readLockRefs()
+	defer cli.mux.RUnlock()
 	cmd := "coverage/show"
 	switch db {
 	case "ovsdb-server":
-		return getAppCoverageMetrics(db, cli.Database.Vswitch.Socket.Control, cli.Timeout)
+		return getAppCoverageMetrics(ctx, db, cli.Database.Vswitch.Socket.Control, cli.Timeout, cli.controlOptions())
 	case "vswitchd-service":
-		return getAppCoverageMetrics(db, cli.Service.Vswitchd.Socket.Control, cli.Timeout)
+		return getAppCoverageMetrics(ctx, db, cli.Service.Vswitchd.Socket.Control, cli.Timeout, cli.controlOptions())
 	default:
 		return nil, fmt.Errorf("The '%s' database is unsupported for '%s'", db, cmd)
 	}
@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 	"strconv"
@@ -26,19 +27,19 @@ import (
 // port number, datapath port number, and the type.
 //
 // Reference: http://www.openvswitch.org/support/dist-docs/ovs-vswitchd.8.txt
-func getAppDatapathInterfaces(db string, sock string, timeout int) ([]*OvsDatapath, []*OvsBridge, []*OvsInterface, error) {
+func getAppDatapathInterfaces(ctx context.Context, db string, sock string, timeout int, opts []ClientOption) ([]*OvsDatapath, []*OvsBridge, []*OvsInterface, error) {
 	var app Client
 	var err error
 	cmd := "dpif/show"
 	dps := []*OvsDatapath{}
 	brs := []*OvsBridge{}
 	intfs := []*OvsInterface{}
-	app, err = NewClient(sock, timeout)
+	app, err = NewClientContext(ctx, sock, timeout, opts...)
 	if err != nil {
 		app.Close()
 		return dps, brs, intfs, fmt.Errorf("failed '%s' from %s: %s", cmd, db, err)
 	}
-	r, err := app.query(cmd, nil)
+	r, err := app.queryContext(ctx, cmd, nil)
 	if err != nil {
 		app.Close()
 		return dps, brs, intfs, fmt.Errorf("the '%s' command failed for %s: %s", cmd, db, err)
@@ -165,17 +166,17 @@ func getAppDatapathInterfaces(db string, sock string, timeout int) ([]*OvsDatapa
 	return dps, brs, intfs, nil
 }
 
-func getAppDatapath(db string, sock string, timeout int) ([]*OvsDatapath, error) {
+func getAppDatapath(ctx context.Context, db string, sock string, timeout int, opts []ClientOption) ([]*OvsDatapath, error) {
 	var app Client
 	var err error
 	cmd := "dpctl/show"
 	dps := []*OvsDatapath{}
-	app, err = NewClient(sock, timeout)
+	app, err = NewClientContext(ctx, sock, timeout, opts...)
 	if err != nil {
 		app.Close()
 		return dps, fmt.Errorf("failed '%s' from %s: %s", cmd, db, err)
 	}
-	r, err := app.query(cmd, nil)
+	r, err := app.queryContext(ctx, cmd, nil)
 	if err != nil {
 		app.Close()
 		return dps, fmt.Errorf("the '%s' command failed for %s: %s", cmd, db, err)
@@ -285,23 +286,40 @@ func getAppDatapath(db string, sock string, timeout int) ([]*OvsDatapath, error)
 
 // GetAppDatapath returns the information about available datapaths.
 func (cli *OvsClient) GetAppDatapath(db string) ([]*OvsDatapath, []*OvsBridge, []*OvsInterface, error) {
-	cli.updateRefs()
+	return cli.GetAppDatapathContext(context.Background(), db)
+}
+
+// GetAppDatapathContext is like GetAppDatapath, but honors the context.
+func (cli *OvsClient) GetAppDatapathContext(ctx context.Context, db string) ([]*OvsDatapath, []*OvsBridge, []*OvsInterface, error) {
+	cli.readLockRefs()
+	defer cli.mux.RUnlock()
 	dps := []*OvsDatapath{}
 	brs := []*OvsBridge{}
 	intfs := []*OvsInterface{}
-	var err error
+	c := newCollector(ctx, cli.PartialResults)
 	switch db {
 	case "vswitchd-service":
-		dps, brs, intfs, err = getAppDatapathInterfaces(db, cli.Service.Vswitchd.Socket.Control, cli.Timeout)
+		err := c.run("dpif/show", func() (err error) {
+			dps, brs, intfs, err = getAppDatapathInterfaces(ctx, db, cli.Service.Vswitchd.Socket.Control, cli.Timeout, cli.controlOptions())
+			return err
+		})
 		if err != nil {
 			return dps, brs, intfs, err
 		}
-		dps, err = getAppDatapath(db, cli.Service.Vswitchd.Socket.Control, cli.Timeout)
+		err = c.run("dpctl/show", func() error {
+			d, err := getAppDatapath(ctx, db, cli.Service.Vswitchd.Socket.Control, cli.Timeout, cli.controlOptions())
+			// In the partial results mode, the datapaths of dpif/show are
+			// kept when the command fails.
+			if err == nil || !cli.PartialResults {
+				dps = d
+			}
+			return err
+		})
 		if err != nil {
 			return dps, brs, intfs, err
 		}
 	default:
 		return dps, brs, intfs, fmt.Errorf("The '%s' database is unsupported for '%s'", db, "dpif/show")
 	}
-	return dps, brs, intfs, nil
+	return dps, brs, intfs, c.err()
 }
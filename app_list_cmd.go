@@ -15,21 +15,22 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 	"strings"
 )
 
-func appListCommands(db string, sock string, timeout int) (map[string]bool, error) {
+func appListCommands(ctx context.Context, db string, sock string, timeout int, opts []ClientOption) (map[string]bool, error) {
 	var app Client
 	var err error
 	cmd := "list-commands"
 	cmds := make(map[string]bool)
-	app, err = NewClient(sock, timeout)
+	app, err = NewClientContext(ctx, sock, timeout, opts...)
 	if err != nil {
 		return cmds, fmt.Errorf("failed '%s' from %s: %s", cmd, db, err)
 	}
-	r, err := app.query(cmd, nil)
+	r, err := app.queryContext(ctx, cmd, nil)
 	if err != nil {
 		app.Close()
 		return cmds, fmt.Errorf("the '%s' command failed for %s: %s", cmd, db, err)
@@ -52,13 +53,19 @@ func appListCommands(db string, sock string, timeout int) (map[string]bool, erro
 // AppListCommands returns the list of commands supported by
 // ovs-appctl tool and the database.
 func (cli *OvnClient) AppListCommands(db string) (map[string]bool, error) {
+	return cli.AppListCommandsContext(context.Background(), db)
+}
+
+// AppListCommandsContext is like AppListCommands, but honors the context.
+func (cli *OvnClient) AppListCommandsContext(ctx context.Context, db string) (map[string]bool, error) {
 	cmd := "list-commands"
-	cli.updateRefs()
+	cli.readLockRefs()
+	defer cli.mux.RUnlock()
 	switch db {
 	case "ovsdb-server-northbound":
-		return appListCommands(db, cli.Database.Northbound.Socket.Control, cli.Timeout)
+		return appListCommands(ctx, db, cli.Database.Northbound.Socket.Control, cli.Timeout, cli.controlOptions())
 	case "ovsdb-server-southbound":
-		return appListCommands(db, cli.Database.Southbound.Socket.Control, cli.Timeout)
+		return appListCommands(ctx, db, cli.Database.Southbound.Socket.Control, cli.Timeout, cli.controlOptions())
 	default:
 		return nil, fmt.Errorf("The '%s' database is unsupported for '%s'", db, cmd)
 	}
@@ -67,13 +74,19 @@ func (cli *OvnClient) AppListCommands(db string) (map[string]bool, error) {
 // AppListCommands returns the list of commands supported by
 // ovs-appctl tool and the database.
 func (cli *OvsClient) AppListCommands(db string) (map[string]bool, error) {
-	cli.updateRefs()
+	return cli.AppListCommandsContext(context.Background(), db)
+}
+
+// AppListCommandsContext is like AppListCommands, but honors the context.
+func (cli *OvsClient) AppListCommandsContext(ctx context.Context, db string) (map[string]bool, error) {
+	cli.readLockRefs()
+	defer cli.mux.RUnlock()
 	cmd := "list-commands"
 	switch db {
 	case "ovsdb-server":
-		return appListCommands(db, cli.Database.Vswitch.Socket.Control, cli.Timeout)
+		return appListCommands(ctx, db, cli.Database.Vswitch.Socket.Control, cli.Timeout, cli.controlOptions())
 	case "vswitchd-service":
-		return appListCommands(db, cli.Service.Vswitchd.Socket.Control, cli.Timeout)
+		return appListCommands(ctx, db, cli.Service.Vswitchd.Socket.Control, cli.Timeout, cli.controlOptions())
 	default:
 		return nil, fmt.Errorf("The '%s' database is unsupported for '%s'", db, cmd)
 	}
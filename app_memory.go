@@ -15,22 +15,23 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
-func getAppMemoryMetrics(db string, sock string, timeout int) (map[string]float64, error) {
+func getAppMemoryMetrics(ctx context.Context, db string, sock string, timeout int, opts []ClientOption) (map[string]float64, error) {
 	var app Client
 	var err error
 	cmd := "memory/show"
 	metrics := make(map[string]float64)
-	app, err = NewClient(sock, timeout)
+	app, err = NewClientContext(ctx, sock, timeout, opts...)
 	if err != nil {
 		app.Close()
 		return metrics, fmt.Errorf("failed '%s' from %s: %s", cmd, db, err)
 	}
-	r, err := app.query(cmd, nil)
+	r, err := app.queryContext(ctx, cmd, nil)
 	if err != nil {
 		app.Close()
 		return metrics, fmt.Errorf("the '%s' command failed for %s: %s", cmd, db, err)
@@ -62,13 +63,19 @@ func getAppMemoryMetrics(db string, sock string, timeout int) (map[string]float6
 
 // GetAppMemoryMetrics returns memory usage counters.
 func (cli *OvnClient) GetAppMemoryMetrics(db string) (map[string]float64, error) {
-	cli.updateRefs()
+	return cli.GetAppMemoryMetricsContext(context.Background(), db)
+}
+
+// GetAppMemoryMetricsContext is like GetAppMemoryMetrics, but honors the context.
+func (cli *OvnClient) GetAppMemoryMetricsContext(ctx context.Context, db string) (map[string]float64, error) {
+	cli.readLockRefs()
+	defer cli.mux.RUnlock()
 	cmd := "memory/show"
 	switch db {
 	case "ovsdb-server-northbound":
-		return getAppMemoryMetrics(db, cli.Database.Northbound.Socket.Control, cli.Timeout)
+		return getAppMemoryMetrics(ctx, db, cli.Database.Northbound.Socket.Control, cli.Timeout, cli.controlOptions())
 	case "ovsdb-server-southbound":
-		return getAppMemoryMetrics(db, cli.Database.Southbound.Socket.Control, cli.Timeout)
+		return getAppMemoryMetrics(ctx, db, cli.Database.Southbound.Socket.Control, cli.Timeout, cli.controlOptions())
 	default:
 		return nil, fmt.Errorf("The '%s' database is unsupported for '%s'", db, cmd)
 	}
@@ -76,13 +83,19 @@ func (cli *OvnClient) GetAppMemoryMetrics(db string) (map[string]float64, error)
 
 // GetAppMemoryMetrics returns memory usage counters.
 func (cli *OvsClient) GetAppMemoryMetrics(db string) (map[string]float64, error) {
-	cli.updateRefs()
+	return cli.GetAppMemoryMetricsContext(context.Background(), db)
+}
+
+// GetAppMemoryMetricsContext is like GetAppMemoryMetrics, but honors the context.
+func (cli *OvsClient) GetAppMemoryMetricsContext(ctx context.Context, db string) (map[string]float64, error) {
+	cli.readLockRefs()
+	defer cli.mux.RUnlock()
 	cmd := "memory/show"
 	switch db {
 	case "ovsdb-server":
-		return getAppMemoryMetrics(db, cli.Database.Vswitch.Socket.Control, cli.Timeout)
+		return getAppMemoryMetrics(ctx, db, cli.Database.Vswitch.Socket.Control, cli.Timeout, cli.controlOptions())
 	case "vswitchd-service":
-		return getAppMemoryMetrics(db, cli.Service.Vswitchd.Socket.Control, cli.Timeout)
+		return getAppMemoryMetrics(ctx, db, cli.Service.Vswitchd.Socket.Control, cli.Timeout, cli.controlOptions())
 	default:
 		return nil, fmt.Errorf("The '%s' database is unsupported for '%s'", db, cmd)
 	}
@@ -0,0 +1,143 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// DefaultInsertChunkSize is the number of rows InsertRows inserts per
+// transaction by default.
+const DefaultInsertChunkSize = 1000
+
+// RowSpec is a row InsertRows inserts.
+type RowSpec struct {
+	// Name, when set, is the uuid-name of the row, so that the other rows
+	// may refer to it as NamedUUID(Name).
+	Name string
+	// Row holds the column values of the row, see Insert.
+	Row map[string]interface{}
+}
+
+// WithInsertChunkSize sets the number of rows InsertRows inserts per
+// transaction.
+func WithInsertChunkSize(size int) ClientOption {
+	return func(cli *Client) error {
+		if size < 0 {
+			return fmt.Errorf("invalid insert chunk size: %d", size)
+		}
+		cli.InsertChunkSize = size
+		return nil
+	}
+}
+
+// InsertRows inserts the rows into the table, InsertChunkSize rows per
+// transaction, and returns the UUIDs of the rows, in order. A row may refer
+// to the rows before it by their names: the references within a chunk are
+// sent as named UUIDs, and the references to the rows of the chunks
+// committed before as their UUIDs. The names must be unique, or no chunk
+// is sent. On error, the chunks committed before remain, and the UUIDs of
+// their rows are returned along with the error.
+func (c *Client) InsertRows(ctx context.Context, db, table string, rows []RowSpec) ([]string, error) {
+	if c == nil {
+		return nil, fmt.Errorf("interface is unavailable")
+	}
+	size := c.InsertChunkSize
+	if size <= 0 {
+		size = DefaultInsertChunkSize
+	}
+	seen := make(map[string]bool, len(rows))
+	for _, spec := range rows {
+		if spec.Name == "" {
+			continue
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("insert rows: duplicate row name %s", spec.Name)
+		}
+		seen[spec.Name] = true
+	}
+	uuids := make([]string, 0, len(rows))
+	committed := make(map[NamedUUID]string)
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+		ops := make([]Operation, 0, end-start)
+		for _, spec := range rows[start:end] {
+			row := make(map[string]interface{}, len(spec.Row))
+			for column, value := range spec.Row {
+				row[column] = resolveNamed(value, committed)
+			}
+			op := Insert(table, row)
+			op.UUIDName = spec.Name
+			ops = append(ops, op)
+		}
+		results, err := c.TransactOperations(ctx, db, ops...)
+		if err != nil {
+			return uuids, fmt.Errorf("insert rows %d-%d of %d: %w", start+1, end, len(rows), err)
+		}
+		for i, result := range results {
+			uuids = append(uuids, result.UUID)
+			if name := rows[start+i].Name; name != "" {
+				committed[NamedUUID(name)] = result.UUID
+			}
+		}
+	}
+	return uuids, nil
+}
+
+// resolveNamed replaces the named UUIDs of the value, which refer to the
+// rows committed before, with their UUIDs. The other values are left as
+// they are.
+func resolveNamed(v interface{}, committed map[NamedUUID]string) interface{} {
+	if name, ok := v.(NamedUUID); ok {
+		if uuid, exists := committed[name]; exists {
+			return UUID(uuid)
+		}
+		return v
+	}
+	if v == nil || len(committed) == 0 {
+		return v
+	}
+	switch v.(type) {
+	case OvsSet, OvsMap:
+	case json.Marshaler:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		elems := make([]interface{}, rv.Len())
+		for i := range elems {
+			elems[i] = resolveNamed(rv.Index(i).Interface(), committed)
+		}
+		if _, ok := v.(OvsSet); ok {
+			return OvsSet(elems)
+		}
+		return elems
+	case reflect.Map:
+		m := make(map[interface{}]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[resolveNamed(iter.Key().Interface(), committed)] = resolveNamed(iter.Value().Interface(), committed)
+		}
+		return OvsMap(m)
+	}
+	return v
+}
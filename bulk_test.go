@@ -0,0 +1,123 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestInsertRows(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var transactions [][]json.RawMessage
+	inserted := 0
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		mu.Lock()
+		defer mu.Unlock()
+		transactions = append(transactions, args[1:])
+		results := []interface{}{}
+		for _, arg := range args[1:] {
+			if strings.Contains(string(arg), `"table":"Missing"`) {
+				return []interface{}{map[string]interface{}{"error": "unknown table"}}
+			}
+			inserted++
+			results = append(results, map[string]interface{}{"uuid": []interface{}{"uuid", fmt.Sprintf("00000000-0000-4000-8000-%012d", inserted)}})
+		}
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1, WithInsertChunkSize(2))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	rows := []RowSpec{
+		{Name: "sw0", Row: map[string]interface{}{"name": "sw0"}},
+		{Row: map[string]interface{}{"name": "sw0-port1", "options": map[string]interface{}{"peer": NamedUUID("sw0")}}},
+		{Name: "sw1", Row: map[string]interface{}{"name": "sw1", "parent": NamedUUID("sw0")}},
+		{Row: map[string]interface{}{"name": "sw1-port1", "switches": []NamedUUID{"sw0", "sw1"}}},
+		{Row: map[string]interface{}{"name": "lr0"}},
+	}
+	uuids, err := cli.InsertRows(context.Background(), "OVN_Northbound", "Logical_Switch", rows)
+	if err != nil {
+		t.Fatalf("FAIL: expected the rows to be inserted, but failed with: %v", err)
+	}
+	if len(uuids) != 5 || uuids[4] != "00000000-0000-4000-8000-000000000005" {
+		t.Fatalf("FAIL: unexpected uuids: %v", uuids)
+	}
+	mu.Lock()
+	if len(transactions) != 3 || len(transactions[0]) != 2 || len(transactions[2]) != 1 {
+		t.Fatalf("FAIL: expected chunks of 2, 2, and 1 rows, got %v", transactions)
+	}
+	within := string(transactions[0][1])
+	across := string(transactions[1][0]) + string(transactions[1][1])
+	mu.Unlock()
+	if !strings.Contains(within, `["named-uuid","sw0"]`) {
+		t.Fatalf("FAIL: expected a named reference within the chunk, but sent: %s", within)
+	}
+	if !strings.Contains(across, `"parent":["uuid","00000000-0000-4000-8000-000000000001"]`) ||
+		!strings.Contains(across, `["uuid","00000000-0000-4000-8000-000000000001"],["named-uuid","sw1"]`) {
+		t.Fatalf("FAIL: expected the references to the committed rows to be resolved, but sent: %s", across)
+	}
+	t.Logf("PASS: inserted %d rows in %d transactions", len(uuids), 3)
+
+	rows = []RowSpec{
+		{Row: map[string]interface{}{"name": "a"}},
+		{Row: map[string]interface{}{"name": "b"}},
+		{Row: map[string]interface{}{"name": "c"}},
+	}
+	uuids, err = cli.InsertRows(context.Background(), "OVN_Northbound", "Logical_Switch", rows)
+	if err != nil || len(uuids) != 3 {
+		t.Fatalf("FAIL: expected 3 rows, got %v, %v", uuids, err)
+	}
+	uuids, err = cli.InsertRows(context.Background(), "OVN_Northbound", "Missing", rows)
+	if err == nil || len(uuids) != 0 || !strings.Contains(err.Error(), "insert rows 1-2 of 3") {
+		t.Fatalf("FAIL: expected the first chunk to fail, got %v, %v", uuids, err)
+	}
+	t.Logf("PASS: %v", err)
+
+	mu.Lock()
+	sent := len(transactions)
+	mu.Unlock()
+	rows = []RowSpec{
+		{Name: "a", Row: map[string]interface{}{"name": "a"}},
+		{Name: "b", Row: map[string]interface{}{"name": "b"}},
+		{Name: "a", Row: map[string]interface{}{"name": "c"}},
+	}
+	uuids, err = cli.InsertRows(context.Background(), "OVN_Northbound", "Logical_Switch", rows)
+	if err == nil || len(uuids) != 0 || !strings.Contains(err.Error(), "duplicate row name a") {
+		t.Fatalf("FAIL: expected the duplicate name to be rejected, got %v, %v", uuids, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(transactions) != sent {
+		t.Fatalf("FAIL: expected no transaction for the duplicate name, but sent %d", len(transactions)-sent)
+	}
+	t.Logf("PASS: %v", err)
+}
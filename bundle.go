@@ -0,0 +1,95 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+)
+
+// bundle holds the schemas of the databases of Open vSwitch, OVN, and the
+// hardware VTEPs.
+//
+//go:embed schemas/*.ovsschema
+var bundle embed.FS
+
+// BundledSchema returns the bundled schema of a database, i.e.
+// Open_vSwitch, OVN_Northbound, OVN_Southbound, or hardware_vtep. The bundled schemas
+// carry the tables and the columns of the versions they are named after,
+// but not their checksums, and serve the typed helpers, the validation of
+// the transactions, and ovsdb-gen when no database is reachable.
+func BundledSchema(name string) (Schema, error) {
+	schemas, err := bundledSchemas()
+	if err != nil {
+		return Schema{}, err
+	}
+	schema, exists := schemas[name]
+	if !exists {
+		return Schema{}, fmt.Errorf("no bundled schema for '%s' database", name)
+	}
+	return schema, nil
+}
+
+// BundledSchemas returns the sorted names of the databases whose schemas
+// are bundled.
+func BundledSchemas() []string {
+	schemas, err := bundledSchemas()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// bundledSchemas parses the bundled schemas, keyed by database name.
+func bundledSchemas() (map[string]Schema, error) {
+	files, err := bundle.ReadDir("schemas")
+	if err != nil {
+		return nil, err
+	}
+	schemas := make(map[string]Schema, len(files))
+	for _, f := range files {
+		b, err := bundle.ReadFile(path.Join("schemas", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var schema Schema
+		if err := json.Unmarshal(b, &schema); err != nil {
+			return nil, fmt.Errorf("parsing bundled %s failed: %s", f.Name(), err)
+		}
+		schemas[schema.Name] = schema
+	}
+	return schemas, nil
+}
+
+// WithSchema seeds the schema cache of a client with a schema, e.g. a
+// bundled one, so that the transactions are validated, and the typed
+// helpers work, without a get_schema round trip.
+func WithSchema(schema Schema) ClientOption {
+	return func(cli *Client) error {
+		if schema.Name == "" {
+			return fmt.Errorf("the schema has no name")
+		}
+		cli.Schemas[schema.Name] = schema
+		return nil
+	}
+}
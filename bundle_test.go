@@ -0,0 +1,67 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBundledSchemas(t *testing.T) {
+	names := BundledSchemas()
+	expected := []string{"OVN_Northbound", "OVN_Southbound", "Open_vSwitch", "hardware_vtep"}
+	if len(names) != len(expected) {
+		t.Fatalf("FAIL: expected bundled schemas %v, but got %v", expected, names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Fatalf("FAIL: expected bundled schemas %v, but got %v", expected, names)
+		}
+	}
+	testcases := []struct {
+		name    string
+		table   string
+		columns []string
+	}{
+		{name: "Open_vSwitch", table: "Bridge", columns: []string{"name", "ports", "datapath_id", "external_ids"}},
+		{name: "Open_vSwitch", table: "Interface", columns: []string{"name", "type", "ofport", "statistics"}},
+		{name: "OVN_Northbound", table: "Logical_Switch_Port", columns: []string{"name", "addresses", "up"}},
+		{name: "OVN_Southbound", table: "Chassis_Private", columns: []string{"name", "chassis", "nb_cfg", "nb_cfg_timestamp"}},
+		{name: "hardware_vtep", table: "Ucast_Macs_Remote", columns: []string{"MAC", "logical_switch", "locator", "ipaddr"}},
+	}
+	for _, test := range testcases {
+		schema, err := BundledSchema(test.name)
+		if err != nil {
+			t.Fatalf("FAIL: expected the %s schema to be bundled, but failed with: %v", test.name, err)
+		}
+		if !schema.HasColumns(test.table, test.columns...) {
+			t.Fatalf("FAIL: expected the %s schema to have %s.%v", test.name, test.table, test.columns)
+		}
+		t.Logf("PASS: bundled %s %s schema has %s", test.name, schema.Version, test.table)
+	}
+	if _, err := BundledSchema("Missing"); err == nil {
+		t.Fatalf("FAIL: expected no bundled schema for Missing database")
+	}
+
+	schema, _ := BundledSchema("Open_vSwitch")
+	if err := schema.ValidateOperation(Insert("Bridge", map[string]interface{}{"name": "br0", "fail_mode": Some("secure")})); err != nil {
+		t.Fatalf("FAIL: expected a valid insert, but failed with: %v", err)
+	}
+	err := schema.ValidateOperation(Insert("Port", map[string]interface{}{"name": "p0", "tag": Some(4096)}))
+	if !errors.Is(err, ErrConstraintViolation) {
+		t.Fatalf("FAIL: expected ErrConstraintViolation, but got: %v", err)
+	}
+	t.Logf("PASS: bundled schema validates operations")
+}
@@ -0,0 +1,623 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Cache holds the rows of tables of a database in memory, kept up to date
+// by a monitor, so that reading them does not cost a round trip to the
+// server. Its rows hold the monitored columns, and the _uuid column, and
+// must not be modified.
+type Cache struct {
+	client *Client
+	db     string
+	schema Schema
+	feed   cacheFeed
+	done   chan struct{}
+
+	mux    sync.RWMutex
+	tables map[string]*cacheTable
+	// lastSync is when the cache last applied the updates of its feed.
+	lastSync time.Time
+	// history holds the last changes of the tables, see KeepHistory.
+	history *cacheHistory
+	// invalidation are the rules the tables are resynced by, see
+	// SetInvalidation.
+	invalidation CacheInvalidation
+
+	// events is held while the updates are applied, and their events
+	// handled, so that the handlers observe them in order.
+	events   sync.Mutex
+	handlers map[string][]*eventHandler
+	// observers receive the events of the updates, by id, see observe.
+	observers    map[int]func(events []cacheEvent)
+	nextObserver int
+	// txnID is the last transaction applied, of a monitor_cond_since
+	// monitor, which the events guard too.
+	txnID    string
+	synced   chan struct{}
+	syncOnce sync.Once
+}
+
+// cacheFeed keeps a cache up to date: a Monitor, or a Poller.
+type cacheFeed interface {
+	Cancel(ctx context.Context) error
+	Err() error
+	SetConditions(ctx context.Context, where map[string][]Condition) error
+	heard() time.Time
+	wireRequests() ([]byte, error)
+}
+
+// cacheTable holds the rows of a table, by UUID, and by the values of its
+// indexes: those of the schema whose columns are all monitored, and those
+// added by AddIndex.
+type cacheTable struct {
+	request MonitorRequest
+	rows    map[UUID]Row
+	indexes []*cacheIndex
+	stats   cacheTableStats
+	// view is set for the views, see AddView, whose rows join those of
+	// the tables.
+	view *cacheView
+}
+
+// index returns the index of the table on the columns, or nil.
+func (t *cacheTable) index(columns []string) *cacheIndex {
+	for _, index := range t.indexes {
+		if sameColumns(index.columns, columns) {
+			return index
+		}
+	}
+	return nil
+}
+
+// cacheIndex holds the UUIDs of the rows by the values of the columns.
+type cacheIndex struct {
+	columns []string
+	rows    map[string]map[UUID]struct{}
+}
+
+func newCacheIndex(columns []string) *cacheIndex {
+	return &cacheIndex{columns: columns, rows: make(map[string]map[UUID]struct{})}
+}
+
+func (i *cacheIndex) add(uuid UUID, row Row) {
+	key := indexKey(row, i.columns)
+	uuids, exists := i.rows[key]
+	if !exists {
+		uuids = make(map[UUID]struct{}, 1)
+		i.rows[key] = uuids
+	}
+	uuids[uuid] = struct{}{}
+}
+
+func (i *cacheIndex) remove(uuid UUID, row Row) {
+	key := indexKey(row, i.columns)
+	delete(i.rows[key], uuid)
+	if len(i.rows[key]) == 0 {
+		delete(i.rows, key)
+	}
+}
+
+// NewCache monitors the tables of the database, see Monitor, and returns
+// once the cache holds their rows. The cache stays up to date until it is
+// closed, or its monitor ends, see Err.
+func (c *Client) NewCache(ctx context.Context, db string, tables []string, columns map[string][]string) (*Cache, error) {
+	requests := make(map[string]MonitorRequest, len(tables))
+	for _, table := range tables {
+		requests[table] = MonitorRequest{Columns: columns[table]}
+	}
+	return c.NewCacheTables(ctx, db, requests)
+}
+
+// NewCacheTables is like NewCache, but takes the columns, and the
+// conditions on the rows, of the cached tables, see MonitorTables.
+func (c *Client) NewCacheTables(ctx context.Context, db string, requests map[string]MonitorRequest) (*Cache, error) {
+	return c.newCache(ctx, db, requests, nil)
+}
+
+// newCache returns a cache of the tables, which holds the rows of the
+// snapshot, if any, until its monitor catches up with the server.
+func (c *Client) newCache(ctx context.Context, db string, requests map[string]MonitorRequest, snapshot *cacheSnapshot) (*Cache, error) {
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	cache := newCacheOf(c, db, schema, requests)
+	seed := cache.seed()
+	if snapshot != nil {
+		// The rows of the monitor have no _uuid column.
+		seed.rows = make(map[string]map[UUID]Row, len(snapshot.Tables))
+		restored := make(TableUpdates, len(snapshot.Tables))
+		for table, rows := range snapshot.Tables {
+			seed.rows[table] = make(map[UUID]Row, len(rows))
+			restored[table] = make(TableUpdate, len(rows))
+			for uuid, row := range rows {
+				r := make(Row, len(row))
+				for column, value := range row {
+					if column != "_uuid" {
+						r[column] = value
+					}
+				}
+				seed.rows[table][uuid] = r
+				restored[table][uuid] = RowUpdate{New: r}
+			}
+		}
+		seed.txnID = snapshot.TxnID
+		cache.apply(restored)
+		cache.txnID = snapshot.TxnID
+	}
+	m, err := c.monitorTables(ctx, db, requests, seed)
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	if err := cache.follow(ctx, m, m.Updates); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// follow keeps the cache up to date with the feed, whose Updates are
+// given, and waits for it to sync.
+func (c *Cache) follow(ctx context.Context, feed cacheFeed, updates <-chan TableUpdates) error {
+	c.feed = feed
+	go c.run(updates)
+	select {
+	case <-c.synced:
+	case <-c.done:
+		return fmt.Errorf("cache: %w", feed.Err())
+	case <-ctx.Done():
+		feed.Cancel(context.Background())
+		return fmt.Errorf("cache: %w", contextError(ctx))
+	}
+	return nil
+}
+
+// NewPollingCache is like NewCacheTables, but keeps the cache up to date
+// with a Poller, for the servers where monitors are not an option. The
+// handlers observe the changes of the rows between the polls.
+func (c *Client) NewPollingCache(ctx context.Context, db string, requests map[string]PollRequest, policy PollPolicy) (*Cache, error) {
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	tables := make(map[string]MonitorRequest, len(requests))
+	for table, request := range requests {
+		tables[table] = MonitorRequest{Columns: request.Columns, Where: request.Where}
+	}
+	cache := newCacheOf(c, db, schema, tables)
+	p, err := c.poll(ctx, db, requests, policy, cache.update)
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	if err := cache.follow(ctx, p, p.Updates); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// newCacheOf returns an empty cache of the tables, with the indexes of the
+// schema whose columns are all requested.
+func newCacheOf(client *Client, db string, schema Schema, requests map[string]MonitorRequest) *Cache {
+	cache := &Cache{
+		client:    client,
+		db:        db,
+		schema:    schema,
+		done:      make(chan struct{}),
+		synced:    make(chan struct{}),
+		tables:    make(map[string]*cacheTable, len(requests)),
+		handlers:  make(map[string][]*eventHandler),
+		observers: make(map[int]func(events []cacheEvent)),
+	}
+	for table, request := range requests {
+		t := &cacheTable{request: request, rows: make(map[UUID]Row)}
+		for _, index := range schema.GetIndexes(table) {
+			if request.monitors(index) {
+				t.indexes = append(t.indexes, newCacheIndex(index))
+			}
+		}
+		cache.tables[table] = t
+	}
+	return cache
+}
+
+// monitors returns true when the request monitors the columns.
+func (r MonitorRequest) monitors(columns []string) bool {
+	if len(r.Columns) == 0 {
+		return true
+	}
+	for _, column := range columns {
+		if !contains(r.Columns, column) {
+			return false
+		}
+	}
+	return true
+}
+
+// run waits for the feed of the cache to end. The feed passes the updates
+// to update, rather than to its Updates, which it closes once it no longer
+// does.
+func (c *Cache) run(updates <-chan TableUpdates) {
+	defer close(c.done)
+	for range updates {
+	}
+}
+
+// update applies the updates of the monitor, and hands their events over
+// to the handlers. The first one is the snapshot the cache syncs with.
+func (c *Cache) update(updates TableUpdates, txnID string, resync bool) {
+	c.events.Lock()
+	defer c.events.Unlock()
+	c.handle(updates, txnID, resync)
+}
+
+// handle applies the updates, and hands their events over to the
+// handlers. The events must be held.
+func (c *Cache) handle(updates TableUpdates, txnID string, resync bool) {
+	events := c.apply(updates)
+	c.record(events, txnID, resync)
+	c.notify(events)
+	if len(events) > 0 {
+		for _, fn := range c.observers {
+			fn(events)
+		}
+	}
+	if txnID != "" {
+		c.txnID = txnID
+	}
+	c.syncOnce.Do(func() {
+		close(c.synced)
+	})
+}
+
+// apply applies the changes of the rows to the cache, and returns them as
+// events, by table and UUID.
+func (c *Cache) apply(updates TableUpdates) []cacheEvent {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	var events []cacheEvent
+	for table, rows := range updates {
+		t, exists := c.tables[table]
+		if !exists {
+			continue
+		}
+		for uuid, u := range rows {
+			e := cacheEvent{table: table, uuid: uuid}
+			if old, exists := t.rows[uuid]; exists {
+				for _, index := range t.indexes {
+					index.remove(uuid, old)
+				}
+				delete(t.rows, uuid)
+				e.old = old
+			}
+			if u.New != nil {
+				row := make(Row, len(u.New)+1)
+				for column, value := range u.New {
+					row[column] = value
+				}
+				row["_uuid"] = []interface{}{"uuid", string(uuid)}
+				t.rows[uuid] = row
+				for _, index := range t.indexes {
+					index.add(uuid, row)
+				}
+				e.new = row
+			}
+			if e.old != nil || e.new != nil {
+				events = append(events, e)
+			}
+		}
+	}
+	events = append(events, c.refreshViews(events)...)
+	sortEvents(events)
+	return events
+}
+
+// indexKey returns the key of the row in the index of the columns. The
+// elements of the sets, and the pairs of the maps, are sorted, and the
+// sets of one element are atoms, so that equal values have equal keys.
+func indexKey(row Row, columns []string) string {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		kind, elems := splitValue(row[column])
+		switch {
+		case kind == "atom":
+			values[i] = row[column]
+		case kind == "set" && len(elems) == 1:
+			values[i] = elems[0]
+		default:
+			keys := make([]string, len(elems))
+			for j, elem := range elems {
+				b, _ := json.Marshal(elem)
+				keys[j] = string(b)
+			}
+			sort.Strings(keys)
+			values[i] = []interface{}{kind, keys}
+		}
+	}
+	b, _ := json.Marshal(values)
+	return string(b)
+}
+
+// Close cancels the monitor, or the poller, of the cache. The cache keeps
+// its rows.
+func (c *Cache) Close(ctx context.Context) error {
+	err := c.feed.Cancel(ctx)
+	<-c.done
+	c.flushEvents()
+	return err
+}
+
+// SetConditions replaces the conditions on the rows of the cached tables,
+// see Monitor.SetConditions and Poller.SetConditions. The rows which stop
+// matching them leave the cache, as deleted, and the tables are resynced
+// under the InvalidateOnConditions rule.
+func (c *Cache) SetConditions(ctx context.Context, where map[string][]Condition) error {
+	if err := c.feed.SetConditions(ctx, where); err != nil {
+		return fmt.Errorf("cache: %w", err)
+	}
+	c.mux.Lock()
+	for table, conds := range where {
+		if t, exists := c.tables[table]; exists {
+			t.request.Where = conds
+		}
+	}
+	resync := c.invalidation&InvalidateOnConditions != 0
+	c.mux.Unlock()
+	if resync {
+		tables := make([]string, 0, len(where))
+		for table := range where {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			if err := c.Resync(ctx, table); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Err returns why the cache is no longer kept up to date, see Monitor.Err
+// and Poller.Err.
+func (c *Cache) Err() error {
+	return c.feed.Err()
+}
+
+// Done returns a channel closed when the cache is no longer kept up to
+// date.
+func (c *Cache) Done() <-chan struct{} {
+	return c.done
+}
+
+// table returns the rows of the table, which the cache must be locked for.
+func (c *Cache) table(table string) (*cacheTable, error) {
+	return lookupTable(c.schema, c.tables, table)
+}
+
+// lookupTable returns the rows of the table, of the cached tables.
+func lookupTable(schema Schema, tables map[string]*cacheTable, table string) (*cacheTable, error) {
+	t, exists := tables[table]
+	if !exists {
+		if schema.Table(table) == nil {
+			return nil, fmt.Errorf("cache: %w", &tableNotFoundError{table})
+		}
+		return nil, fmt.Errorf("cache: table %s is not monitored", table)
+	}
+	return t, nil
+}
+
+// Len returns the number of rows of the table.
+func (c *Cache) Len(table string) int {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	if t, exists := c.tables[table]; exists {
+		return len(t.rows)
+	}
+	return 0
+}
+
+// Row returns the row of the table, and whether there is one.
+func (c *Cache) Row(table string, uuid UUID) (Row, bool) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	t, exists := c.tables[table]
+	if !exists {
+		return nil, false
+	}
+	row, exists := t.rows[uuid]
+	return row, exists
+}
+
+// Select returns the rows of the table matching all the conditions, as the
+// select operation would, see Select, ordered by UUID. The rows hold the
+// columns, or all the monitored columns when none are given.
+func (c *Cache) Select(table string, columns []string, where ...Condition) ([]Row, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	t, err := c.table(table)
+	if err != nil {
+		return nil, err
+	}
+	return t.selectRows(table, columns, where)
+}
+
+// selectRows returns the rows of the table matching all the conditions,
+// see Cache.Select.
+func (t *cacheTable) selectRows(table string, columns []string, where []Condition) ([]Row, error) {
+	uuids := make([]string, 0, len(t.rows))
+	for uuid := range t.rows {
+		uuids = append(uuids, string(uuid))
+	}
+	sort.Strings(uuids)
+	var rows []Row
+	for _, uuid := range uuids {
+		row := t.rows[UUID(uuid)]
+		matched, err := matchConditions(row, where)
+		if err != nil {
+			return nil, fmt.Errorf("cache: table %s: %w", table, err)
+		}
+		if !matched {
+			continue
+		}
+		if len(columns) == 0 {
+			rows = append(rows, row)
+			continue
+		}
+		out := make(Row, len(columns))
+		for _, column := range columns {
+			value, exists := row[column]
+			if !exists {
+				return nil, fmt.Errorf("cache: table %s: column %s: %w", table, column, ErrColumnNotFound)
+			}
+			out[column] = value
+		}
+		rows = append(rows, out)
+	}
+	return rows, nil
+}
+
+// RowByKey returns the row of the table whose index has the values of the
+// key, by column, like Client.GetRowByKey, and whether there is one.
+func (c *Cache) RowByKey(table string, key map[string]interface{}) (Row, bool, error) {
+	rows, err := c.LookupIndex(table, key)
+	if err != nil || len(rows) == 0 {
+		return nil, false, err
+	}
+	if len(rows) > 1 {
+		return nil, false, fmt.Errorf("cache: %d rows of table %s match the key", len(rows), table)
+	}
+	return rows[0], true, nil
+}
+
+// AddIndex adds a secondary index of the table on the monitored columns,
+// e.g. Port_Binding by logical_port, or Chassis by hostname, which the
+// cache maintains as the rows change, so that LookupIndex does not scan
+// the rows. Unlike those of the schema, its values need not be unique.
+func (c *Cache) AddIndex(table string, columns ...string) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	t, err := c.table(table)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("cache: table %s: no index columns", table)
+	}
+	for _, column := range columns {
+		if t.view == nil && c.schema.Table(table).Column(column) == nil {
+			return fmt.Errorf("cache: table %s: column %s: %w", table, column, ErrColumnNotFound)
+		}
+		if t.view != nil && !contains(t.request.Columns, column) {
+			return fmt.Errorf("cache: view %s: column %s: %w", table, column, ErrColumnNotFound)
+		}
+		if !t.request.monitors([]string{column}) {
+			return fmt.Errorf("cache: table %s: column %s is not monitored", table, column)
+		}
+	}
+	for _, index := range t.indexes {
+		if sameColumns(index.columns, columns) {
+			return nil
+		}
+	}
+	index := newCacheIndex(append([]string(nil), columns...))
+	for uuid, row := range t.rows {
+		index.add(uuid, row)
+	}
+	t.indexes = append(t.indexes, index)
+	return nil
+}
+
+// LookupIndex returns the rows of the table whose columns have the values
+// of the key, by column, ordered by UUID. The columns must be those of an
+// index of the cache, of the schema, or added by AddIndex, or _uuid. The
+// views are indexed by their key.
+func (c *Cache) LookupIndex(table string, key map[string]interface{}) ([]Row, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	t, err := c.table(table)
+	if err != nil {
+		return nil, err
+	}
+	return lookupIndex(c.schema, table, t, key, t.index)
+}
+
+// lookupIndex returns the rows of the table whose columns have the values
+// of the key, see Cache.LookupIndex, by the index the function returns of
+// the columns, or nil.
+func lookupIndex(schema Schema, table string, t *cacheTable, key map[string]interface{}, index func(columns []string) *cacheIndex) ([]Row, error) {
+	columns := make([]string, 0, len(key))
+	for column := range key {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	values := make(Row, len(key))
+	for column, value := range key {
+		if s, ok := value.(string); ok {
+			if col := schema.Table(table).Column(column); col != nil && col.Key.Type == "uuid" {
+				value = UUID(s)
+			}
+		}
+		v, err := encodeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("cache: table %s: column %s: %v", table, column, err)
+		}
+		values[column] = v
+	}
+	if len(columns) == 1 && columns[0] == "_uuid" {
+		uuid, ok := values["_uuid"].(UUID)
+		if !ok {
+			return nil, fmt.Errorf("cache: table %s: invalid uuid %v", table, key["_uuid"])
+		}
+		if row, exists := t.rows[uuid]; exists {
+			return []Row{row}, nil
+		}
+		return nil, nil
+	}
+	if index := index(columns); index != nil {
+		uuids := make([]string, 0, len(index.rows[indexKey(values, index.columns)]))
+		for uuid := range index.rows[indexKey(values, index.columns)] {
+			uuids = append(uuids, string(uuid))
+		}
+		sort.Strings(uuids)
+		rows := make([]Row, len(uuids))
+		for i, uuid := range uuids {
+			rows[i] = t.rows[UUID(uuid)]
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("cache: %v is not a cached index of table %s", columns, table)
+}
+
+// sameColumns returns true when the columns of the index are the columns,
+// in any order.
+func sameColumns(index, columns []string) bool {
+	if len(index) != len(columns) {
+		return false
+	}
+	for _, column := range columns {
+		if !contains(index, column) {
+			return false
+		}
+	}
+	return true
+}
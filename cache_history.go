@@ -0,0 +1,120 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"time"
+)
+
+// HistoryEntry is the change of the rows of a table by an update of a
+// cache, see Cache.KeepHistory.
+type HistoryEntry struct {
+	// Time is when the cache applied the update.
+	Time time.Time
+	// TxnID is the id of the transaction of the update, of a
+	// monitor_cond_since monitor, empty otherwise.
+	TxnID string
+	Table string
+	// Rows are the rows changed, by UUID, before and after the change. They
+	// hold the _uuid column, as the rows of the cache do, and must not be
+	// modified.
+	Rows TableUpdate
+}
+
+// cacheHistory is a ring of the last entries.
+type cacheHistory struct {
+	entries []HistoryEntry
+	// next is the index of the oldest entry once the ring is full.
+	next int
+	full bool
+}
+
+func (h *cacheHistory) add(e HistoryEntry) {
+	h.entries[h.next] = e
+	h.next++
+	if h.next == len(h.entries) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+// ordered returns the entries, the oldest first.
+func (h *cacheHistory) ordered() []HistoryEntry {
+	if !h.full {
+		return h.entries[:h.next]
+	}
+	return append(append([]HistoryEntry(nil), h.entries[h.next:]...), h.entries[:h.next]...)
+}
+
+// KeepHistory makes the cache keep the last n changes of the tables, from
+// now on, for History to answer what changed, e.g. in Port_Binding over
+// the last minutes. An update of several tables is a change of each of
+// them. The history kept so far is dropped; it is no longer kept when n
+// is zero.
+func (c *Cache) KeepHistory(n int) error {
+	if n < 0 {
+		return fmt.Errorf("cache: invalid history length: %d", n)
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.history = nil
+	if n > 0 {
+		c.history = &cacheHistory{entries: make([]HistoryEntry, n)}
+	}
+	return nil
+}
+
+// History returns the changes of the table kept since the time, or of all
+// the tables when the table is empty, the oldest first.
+func (c *Cache) History(table string, since time.Time) ([]HistoryEntry, error) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	if table != "" {
+		if _, err := c.table(table); err != nil {
+			return nil, err
+		}
+	}
+	if c.history == nil {
+		return nil, nil
+	}
+	var entries []HistoryEntry
+	for _, e := range c.history.ordered() {
+		if (table == "" || e.Table == table) && !e.Time.Before(since) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// remember adds the events of an update to the history, if it is kept. The
+// cache must be locked.
+func (c *Cache) remember(events []cacheEvent, txnID string, now time.Time) {
+	if c.history == nil || len(events) == 0 {
+		return
+	}
+	var e HistoryEntry
+	for _, event := range events {
+		// The events are ordered by table.
+		if event.table != e.Table {
+			if e.Rows != nil {
+				c.history.add(e)
+			}
+			e = HistoryEntry{Time: now, TxnID: txnID, Table: event.table, Rows: make(TableUpdate)}
+		}
+		e.Rows[event.uuid] = RowUpdate{Old: event.old, New: event.new}
+	}
+	c.history.add(e)
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// CacheInvalidation are the rules a cache resyncs its tables by, see
+// Cache.SetInvalidation, so that no stale rows linger in it.
+type CacheInvalidation int
+
+const (
+	// InvalidateOnConditions resyncs the tables whose conditions
+	// SetConditions replaces, e.g. for the servers which do not delete the
+	// rows which stop matching them.
+	InvalidateOnConditions CacheInvalidation = 1 << iota
+	// InvalidateOnSchemaChange resyncs all the tables when the monitor of
+	// the cache resumes, and the schema of the database changed while it
+	// was disconnected, e.g. as the server was upgraded. The cache, and
+	// its monitor, switch to the new schema before applying the updates
+	// which follow, and the rows of the tables it no longer has are
+	// deleted.
+	InvalidateOnSchemaChange
+)
+
+// SetInvalidation sets the rules the cache resyncs its tables by.
+func (c *Cache) SetInvalidation(rules CacheInvalidation) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.invalidation = rules
+}
+
+// Resync fetches the rows of the table anew, with a select of its cached
+// columns, and reconciles the cache with them: the handlers observe the
+// rows the cache missed as inserted, or modified, and those which vanished
+// from the server as deleted. The updates of the monitor wait meanwhile.
+func (c *Cache) Resync(ctx context.Context, table string) error {
+	c.mux.RLock()
+	t, err := c.table(table)
+	var request MonitorRequest
+	if err == nil {
+		request = t.request
+		if t.view != nil {
+			err = fmt.Errorf("cache: view %s cannot be resynced, unlike its tables", table)
+		}
+	}
+	c.mux.RUnlock()
+	if err != nil {
+		return err
+	}
+	c.events.Lock()
+	defer c.events.Unlock()
+	rows, err := c.client.selectTable(ctx, c.db, table, request)
+	if err != nil {
+		return fmt.Errorf("cache: resync of table %s: %w", table, err)
+	}
+	c.reconcile(table, rows)
+	return nil
+}
+
+// reconcile replaces the rows of the table with the rows, and hands the
+// events of their differences over to the handlers. The events must be
+// held.
+func (c *Cache) reconcile(table string, rows map[UUID]Row) {
+	c.mux.RLock()
+	t := c.tables[table]
+	u := make(TableUpdate)
+	for uuid, row := range rows {
+		old, exists := t.rows[uuid]
+		if !exists {
+			u[uuid] = RowUpdate{New: row}
+			continue
+		}
+		cached := make(Row, len(old))
+		for column, value := range old {
+			if column != "_uuid" {
+				cached[column] = value
+			}
+		}
+		if !reflect.DeepEqual(cached, row) {
+			u[uuid] = RowUpdate{Old: old, New: row}
+		}
+	}
+	for uuid, old := range t.rows {
+		if _, exists := rows[uuid]; !exists {
+			u[uuid] = RowUpdate{Old: old}
+		}
+	}
+	c.mux.RUnlock()
+	c.handle(TableUpdates{table: u}, "", false)
+	c.mux.Lock()
+	t.stats.resyncs++
+	c.mux.Unlock()
+}
+
+// seed returns the seed of the monitor of the cache, which applies the
+// updates to the cache, and follows the changes of the schema, should the
+// cache have the InvalidateOnSchemaChange rule.
+func (c *Cache) seed() monitorSeed {
+	return monitorSeed{sink: c.update, refreshSchema: c.followsSchema, onSchema: c.schemaChanged}
+}
+
+// followsSchema returns true when the cache has the
+// InvalidateOnSchemaChange rule.
+func (c *Cache) followsSchema() bool {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.invalidation&InvalidateOnSchemaChange != 0
+}
+
+// schemaChanged replaces the schema of the cache with that the monitor of
+// the cache resumed with, ahead of the updates applied with it, and
+// resyncs the tables. The rows of the tables the schema no longer has are
+// deleted.
+func (c *Cache) schemaChanged(schema Schema) {
+	c.events.Lock()
+	c.mux.Lock()
+	c.schema = schema
+	var tables []string
+	for name, t := range c.tables {
+		if t.view == nil {
+			tables = append(tables, name)
+		}
+	}
+	c.mux.Unlock()
+	sort.Strings(tables)
+	for _, table := range tables {
+		if schema.Table(table) == nil {
+			c.reconcile(table, nil)
+		}
+	}
+	c.events.Unlock()
+	// The tables are resynced once the updates which follow the resume
+	// are applied, as the resyncs hold them.
+	go func() {
+		for _, table := range tables {
+			if schema.Table(table) == nil {
+				continue
+			}
+			if err := c.Resync(context.Background(), table); err != nil {
+				c.client.logger().Warnf("cache of %s: %v", c.db, err)
+			}
+		}
+	}()
+}
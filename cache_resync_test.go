@@ -0,0 +1,212 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheResync(t *testing.T) {
+	const br0, br1, br2 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31", "8c5f6e2b-0c2f-4a4a-bd51-7e3067c6b1a4"
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	// The server does not delete the rows which stop matching the
+	// conditions, nor notifies the changes: the selects see them.
+	selected := map[string]string{br0: "secure", br2: "standalone"}
+	var selects []string
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "monitor_cond":
+			return map[string]interface{}{"Bridge": map[string]interface{}{
+				br0: map[string]interface{}{"initial": map[string]interface{}{"name": "br0", "fail_mode": "standalone"}},
+				br1: map[string]interface{}{"initial": map[string]interface{}{"name": "br1", "fail_mode": "standalone"}},
+			}}
+		case "monitor_cond_change", "monitor_cancel":
+			return map[string]interface{}{}
+		case "transact":
+			selects = append(selects, string(params))
+			rows := []interface{}{}
+			for uuid, mode := range selected {
+				name := map[string]string{br0: "br0", br1: "br1", br2: "br2"}[uuid]
+				rows = append(rows, map[string]interface{}{"_uuid": []interface{}{"uuid", uuid}, "name": name, "fail_mode": mode})
+			}
+			return []interface{}{map[string]interface{}{"rows": rows}}
+		}
+		return nil
+	})
+	c, err := NewClient("tcp:"+l.Addr().String(), 1, WithCapabilities(Capabilities{MonitorCond: true}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"name", "fail_mode"}})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	var events []string
+	cache.RegisterHandler("Bridge", EventHandler{
+		OnAdd:    func(_ string, row Row) { events = append(events, "add "+row["name"].(string)) },
+		OnUpdate: func(_ string, _, row Row) { events = append(events, "update "+row["name"].(string)) },
+		OnDelete: func(_ string, row Row) { events = append(events, "delete "+row["name"].(string)) },
+	})
+	events = nil
+
+	if err := cache.Resync(ctx, "Bridge"); err != nil {
+		t.Fatalf("FAIL: expected the table to be resynced, but failed with: %v", err)
+	}
+	if want := []string{"update br0", "delete br1", "add br2"}; !reflect.DeepEqual(events, want) {
+		t.Fatalf("FAIL: unexpected events of the resync: %q", events)
+	}
+	if row, _ := cache.Row("Bridge", br0); row["fail_mode"] != "secure" || cache.Len("Bridge") != 2 {
+		t.Fatalf("FAIL: unexpected rows after the resync: %v", row)
+	}
+	if stats := cache.Stats()["Bridge"]; stats.Resyncs != 1 {
+		t.Fatalf("FAIL: expected the resync to be counted, got: %+v", stats)
+	}
+	events = nil
+	if err := cache.Resync(ctx, "Bridge"); err != nil || len(events) != 0 {
+		t.Fatalf("FAIL: expected the resync of an up to date table to change nothing, got: %q, %v", events, err)
+	}
+	if err := cache.Resync(ctx, "Port"); err == nil {
+		t.Fatalf("FAIL: expected the resync of a table which is not cached to fail")
+	}
+	t.Logf("PASS: table resynced")
+
+	cache.SetInvalidation(InvalidateOnConditions)
+	mu.Lock()
+	delete(selected, br2)
+	mu.Unlock()
+	if err := cache.SetConditions(ctx, map[string][]Condition{"Bridge": {{Column: "name", Function: "==", Value: "br0", Type: "string"}}}); err != nil {
+		t.Fatalf("FAIL: expected the conditions to be set, but failed with: %v", err)
+	}
+	if want := []string{"delete br2"}; !reflect.DeepEqual(events, want) {
+		t.Fatalf("FAIL: unexpected events of the conditions: %q", events)
+	}
+	mu.Lock()
+	last := selects[len(selects)-1]
+	mu.Unlock()
+	if !strings.Contains(last, `["name","==","br0"]`) {
+		t.Fatalf("FAIL: expected the resync to select the rows of the conditions, got: %s", last)
+	}
+	t.Logf("PASS: table resynced with the conditions")
+}
+
+func TestCacheSchemaChange(t *testing.T) {
+	const br0 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"
+	// The upgrade turns datapath_id from an optional string, i.e. a set,
+	// into a string, whose diffs replace the value rather than toggle the
+	// elements of the set, and adds the status column.
+	upgraded := strings.Replace(testModelSchema,
+		`"datapath_id": {"type": {"key": "string", "min": 0, "max": 1}, "ephemeral": true},`,
+		`"datapath_id": {"type": "string"}, "status": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}},`, 1)
+	if upgraded == testModelSchema {
+		t.Fatalf("FAIL: the schema was not upgraded")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	schema, id, datapath := testModelSchema, interface{}(nil), "x"
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		var args []interface{}
+		json.Unmarshal(params, &args)
+		switch method {
+		case "get_schema":
+			return json.RawMessage(schema)
+		case "monitor_cond_since":
+			id = args[1]
+			return []interface{}{false, "txn-1", map[string]interface{}{"Bridge": map[string]interface{}{
+				br0: map[string]interface{}{"initial": map[string]interface{}{"name": "br0", "datapath_id": datapath}},
+			}}}
+		case "transact":
+			if strings.Contains(string(params), `"comment"`) {
+				datapath = "abc"
+				return testServerNotify{
+					method: "update3",
+					params: []interface{}{id, "txn-2", map[string]interface{}{"Bridge": map[string]interface{}{
+						br0: map[string]interface{}{"modify": map[string]interface{}{"datapath_id": datapath}},
+					}}},
+					result: []interface{}{map[string]interface{}{}},
+				}
+			}
+			row := map[string]interface{}{"_uuid": []interface{}{"uuid", br0}, "name": "br0", "datapath_id": datapath}
+			return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}
+		}
+		return nil
+	})
+	c, err := NewClient("tcp:"+l.Addr().String(), 1, WithCapabilities(Capabilities{MonitorCond: true, MonitorCondSince: true}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	cache.SetInvalidation(InvalidateOnSchemaChange)
+	if err := cache.AddIndex("Bridge", "status"); err == nil {
+		t.Fatalf("FAIL: expected the status column to be unknown before the upgrade")
+	}
+
+	mu.Lock()
+	schema = upgraded
+	mu.Unlock()
+	c.mux.Lock()
+	c.link.close()
+	c.mux.Unlock()
+	for deadline := time.Now().Add(5 * time.Second); cache.AddIndex("Bridge", "status") != nil; {
+		if time.Now().After(deadline) {
+			t.Fatalf("FAIL: expected the cache to switch to the upgraded schema")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Logf("PASS: the cache switched to the upgraded schema")
+
+	if _, err := c.TransactOperations(ctx, "Open_vSwitch", Comment("upgrade")); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		row, _ := cache.Row("Bridge", br0)
+		if row["datapath_id"] == "abc" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("FAIL: expected the diff to be applied with the upgraded schema, but got: %v", row["datapath_id"])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Logf("PASS: the monitor applied the diff with the upgraded schema")
+}
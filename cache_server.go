@@ -0,0 +1,700 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// cacheServerQueue is the number of messages queued for a reader of a
+// CacheServer, beyond which the reader is too slow, and disconnected.
+const cacheServerQueue = 1024
+
+// CacheServer serves the rows of a cache over OVSDB, read-only, to local
+// readers, e.g. the processes of a side-car, which would otherwise each
+// keep a connection, and a copy of the rows, of their own. It answers
+// list_dbs, get_schema, echo, transact with select and comment operations,
+// monitor, monitor_cond, and monitor_cancel, as ovsdb-server would, for the
+// cached tables and columns. The readers keep being served the rows the
+// cache holds when it is no longer kept up to date.
+type CacheServer struct {
+	cache     *Cache
+	listener  net.Listener
+	unobserve func()
+	done      chan struct{}
+
+	mux    sync.Mutex
+	conns  map[*cacheConn]struct{}
+	closed bool
+}
+
+// Listen serves the cache on the passive remote, e.g.
+// "punix:/var/run/ovn/sb-cache.sock", or "ptcp:6652:127.0.0.1".
+func (c *Cache) Listen(remote string) (*CacheServer, error) {
+	proto, addr, err := parseListenRemote(remote)
+	if err != nil {
+		return nil, err
+	}
+	if proto == "ssl" {
+		return nil, fmt.Errorf("the %s remote is not supported by the cache", remote)
+	}
+	if proto == "unix" && namedPipes {
+		return nil, fmt.Errorf("the %s remote is not supported on Windows", remote)
+	}
+	l, err := net.Listen(proto, addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &CacheServer{
+		cache:    c,
+		listener: l,
+		done:     make(chan struct{}),
+		conns:    make(map[*cacheConn]struct{}),
+	}
+	c.events.Lock()
+	s.unobserve = c.observe(s.relay)
+	c.events.Unlock()
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *CacheServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close stops listening, and disconnects the readers.
+func (s *CacheServer) Close() error {
+	s.mux.Lock()
+	if s.closed {
+		s.mux.Unlock()
+		return nil
+	}
+	s.closed = true
+	err := s.listener.Close()
+	for conn := range s.conns {
+		conn.close()
+	}
+	s.mux.Unlock()
+	<-s.done
+	s.cache.events.Lock()
+	s.unobserve()
+	s.cache.events.Unlock()
+	return err
+}
+
+func (s *CacheServer) serve() {
+	defer close(s.done)
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		conn := &cacheConn{
+			server:   s,
+			conn:     c,
+			out:      make(chan interface{}, cacheServerQueue),
+			closed:   make(chan struct{}),
+			monitors: make(map[string]*cacheMonitor),
+		}
+		s.mux.Lock()
+		if s.closed {
+			s.mux.Unlock()
+			c.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mux.Unlock()
+		go conn.write()
+		go conn.read()
+	}
+}
+
+// relay sends the events of an update of the cache to the monitors of the
+// readers. The events of the cache are held.
+func (s *CacheServer) relay(events []cacheEvent) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for conn := range s.conns {
+		for _, m := range conn.monitors {
+			if updates := m.updates(s.cache.schema, events); len(updates) > 0 {
+				method := "update"
+				if m.update2 {
+					method = "update2"
+				}
+				conn.send(map[string]interface{}{"id": nil, "method": method, "params": []interface{}{m.id, updates}})
+			}
+		}
+	}
+}
+
+// cacheConn is the connection of a reader of a CacheServer.
+type cacheConn struct {
+	server *CacheServer
+	conn   net.Conn
+	out    chan interface{}
+	closed chan struct{}
+	once   sync.Once
+	// monitors are the monitors of the reader, by id, which the events of
+	// the cache guard.
+	monitors map[string]*cacheMonitor
+}
+
+// cacheServerError is the error object of a response of a CacheServer.
+type cacheServerError struct {
+	Error   string `json:"error"`
+	Details string `json:"details,omitempty"`
+}
+
+// send queues the message, or disconnects the reader when it no longer
+// keeps up.
+func (c *cacheConn) send(msg interface{}) {
+	select {
+	case c.out <- msg:
+	default:
+		c.close()
+	}
+}
+
+func (c *cacheConn) close() {
+	c.once.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+	})
+}
+
+// write writes the messages queued, in order.
+func (c *cacheConn) write() {
+	enc := json.NewEncoder(c.conn)
+	for {
+		select {
+		case msg := <-c.out:
+			if err := enc.Encode(msg); err != nil {
+				c.close()
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// read answers the requests of the reader until it disconnects.
+func (c *cacheConn) read() {
+	defer func() {
+		c.close()
+		c.server.mux.Lock()
+		delete(c.server.conns, c)
+		c.server.mux.Unlock()
+	}()
+	dec := json.NewDecoder(c.conn)
+	for {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			ID     json.RawMessage `json:"id"`
+		}
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		if req.Method == "" || len(req.ID) == 0 || string(req.ID) == "null" {
+			// A response, e.g. to an echo, or a notification.
+			continue
+		}
+		var params []json.RawMessage
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				c.send(map[string]interface{}{"id": req.ID, "result": nil, "error": cacheServerError{Error: "syntax error", Details: err.Error()}})
+				continue
+			}
+		}
+		// The reply of a monitor is queued along with its registration, so
+		// that the updates follow it.
+		if err := c.answer(req.Method, req.ID, params, req.Params); err != nil {
+			c.send(map[string]interface{}{"id": req.ID, "result": nil, "error": err})
+		}
+	}
+}
+
+// answer queues the reply of the request, or returns its error object.
+func (c *cacheConn) answer(method string, id json.RawMessage, params []json.RawMessage, raw json.RawMessage) *cacheServerError {
+	cache := c.server.cache
+	reply := func(result interface{}) *cacheServerError {
+		c.send(map[string]interface{}{"id": id, "result": result, "error": nil})
+		return nil
+	}
+	switch method {
+	case "echo":
+		return reply(raw)
+	case "list_dbs":
+		return reply([]string{cache.db})
+	case "set_db_change_aware":
+		return reply(map[string]interface{}{})
+	case "get_schema":
+		if err := c.checkDatabase(params); err != nil {
+			return err
+		}
+		return reply(cache.servedSchema())
+	case "transact":
+		if err := c.checkDatabase(params); err != nil {
+			return err
+		}
+		return reply(cache.transact(params[1:]))
+	case "monitor", "monitor_cond":
+		if err := c.checkDatabase(params); err != nil {
+			return err
+		}
+		if len(params) != 3 {
+			return &cacheServerError{Error: "syntax error", Details: fmt.Sprintf("%s requires the database, the id, and the requests", method)}
+		}
+		m := &cacheMonitor{id: params[1], update2: method == "monitor_cond"}
+		if err := m.parse(cache, params[2]); err != nil {
+			return err
+		}
+		key := string(params[1])
+		cache.events.Lock()
+		defer cache.events.Unlock()
+		if _, exists := c.monitors[key]; exists {
+			return &cacheServerError{Error: "duplicate monitor ID"}
+		}
+		c.monitors[key] = m
+		return reply(m.initial(cache))
+	case "monitor_cancel":
+		if len(params) != 1 {
+			return &cacheServerError{Error: "syntax error", Details: "monitor_cancel requires the id"}
+		}
+		cache.events.Lock()
+		defer cache.events.Unlock()
+		if _, exists := c.monitors[string(params[0])]; !exists {
+			return &cacheServerError{Error: "unknown monitor"}
+		}
+		delete(c.monitors, string(params[0]))
+		return reply(map[string]interface{}{})
+	}
+	return &cacheServerError{Error: "unknown method", Details: method}
+}
+
+// checkDatabase checks that the first parameter is the cached database.
+func (c *cacheConn) checkDatabase(params []json.RawMessage) *cacheServerError {
+	var db string
+	if len(params) == 0 || json.Unmarshal(params[0], &db) != nil {
+		return &cacheServerError{Error: "syntax error", Details: "the database is missing"}
+	}
+	if db != c.server.cache.db {
+		return &cacheServerError{Error: "unknown database", Details: db}
+	}
+	return nil
+}
+
+// servedSchema returns the schema of the database, with the cached tables
+// and columns only.
+func (c *Cache) servedSchema() map[string]interface{} {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	schema := Schema{
+		Name:     c.schema.Name,
+		Version:  c.schema.Version,
+		Checksum: c.schema.Checksum,
+		Tables:   make(map[string]Table, len(c.tables)),
+	}
+	for name, t := range c.tables {
+		if t.view != nil {
+			continue
+		}
+		table := c.schema.Tables[name]
+		if len(t.request.Columns) > 0 {
+			columns := make(map[string]Column, len(t.request.Columns))
+			for _, column := range t.request.Columns {
+				columns[column] = table.Columns[column]
+			}
+			table.Columns = columns
+			// The indexes of the columns left out go too.
+			var indexes []interface{}
+			for _, index := range table.GetIndexes() {
+				if t.request.monitors(index) {
+					indexes = append(indexes, index)
+				}
+			}
+			table.Indexes = indexes
+		}
+		schema.Tables[name] = table
+	}
+	return schema.wire()
+}
+
+// cachedColumns returns the columns the cache holds of the table, sorted.
+func (c *Cache) cachedColumns(table string) []string {
+	t := c.tables[table]
+	if len(t.request.Columns) > 0 {
+		columns := append([]string(nil), t.request.Columns...)
+		sort.Strings(columns)
+		return columns
+	}
+	var columns []string
+	for column := range c.schema.Tables[table].Columns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// transact answers the operations of a transaction. The transaction stops
+// at the first operation which fails, whose result is an error object, and
+// the results of the operations following it are null, as in RFC 7047.
+func (c *Cache) transact(ops []json.RawMessage) []interface{} {
+	results := make([]interface{}, 0, len(ops))
+	fail := func(err interface{}) []interface{} {
+		results = append(results, err)
+		return append(results, make([]interface{}, len(ops)-len(results))...)
+	}
+	for _, raw := range ops {
+		var op struct {
+			Op      string          `json:"op"`
+			Table   string          `json:"table"`
+			Where   json.RawMessage `json:"where"`
+			Columns []string        `json:"columns"`
+		}
+		if err := json.Unmarshal(raw, &op); err != nil {
+			return fail(cacheServerError{Error: "syntax error", Details: err.Error()})
+		}
+		switch op.Op {
+		case "comment":
+			results = append(results, map[string]interface{}{})
+			continue
+		case "select":
+		default:
+			return fail(cacheServerError{Error: "not allowed", Details: fmt.Sprintf("the cache of %s is read-only, %s operations are not supported", c.db, op.Op)})
+		}
+		rows, err := c.selectWire(op.Table, op.Columns, op.Where)
+		if err != nil {
+			return fail(*err)
+		}
+		results = append(results, map[string]interface{}{"rows": rows})
+	}
+	return results
+}
+
+// selectWire returns the rows of the table matching the conditions, in
+// their JSON form, with the columns, or all those cached, and _uuid.
+func (c *Cache) selectWire(table string, columns []string, where json.RawMessage) ([]Row, *cacheServerError) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	t, exists := c.tables[table]
+	if !exists || t.view != nil {
+		return nil, &cacheServerError{Error: "unknown table", Details: table}
+	}
+	conds, err := c.parseWhere(table, where, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range columns {
+		if column != "_uuid" && !contains(c.cachedColumns(table), column) {
+			return nil, &cacheServerError{Error: "unknown column", Details: fmt.Sprintf("table %s: column %s is not cached", table, column)}
+		}
+	}
+	uuids := make([]string, 0, len(t.rows))
+	for uuid := range t.rows {
+		uuids = append(uuids, string(uuid))
+	}
+	sort.Strings(uuids)
+	rows := []Row{}
+	for _, uuid := range uuids {
+		row := t.rows[UUID(uuid)]
+		if !matchWireConditions(row, conds) {
+			continue
+		}
+		if len(columns) == 0 {
+			rows = append(rows, row)
+			continue
+		}
+		rows = append(rows, projectRow(row, columns))
+	}
+	return rows, nil
+}
+
+// parseWhere parses the conditions on the rows of the table, in their JSON
+// form. The boolean ones, of monitor_cond, are allowed when literals is.
+func (c *Cache) parseWhere(table string, where json.RawMessage, literals bool) ([]interface{}, *cacheServerError) {
+	if len(where) == 0 || string(where) == "null" {
+		return nil, nil
+	}
+	var conds []interface{}
+	if err := json.Unmarshal(where, &conds); err != nil {
+		return nil, &cacheServerError{Error: "syntax error", Details: fmt.Sprintf("invalid conditions %s", where)}
+	}
+	columns := c.cachedColumns(table)
+	for _, cond := range conds {
+		if _, ok := cond.(bool); ok && literals {
+			continue
+		}
+		triple, ok := cond.([]interface{})
+		if !ok || len(triple) != 3 {
+			return nil, &cacheServerError{Error: "syntax error", Details: fmt.Sprintf("invalid condition %v", cond)}
+		}
+		column, ok := triple[0].(string)
+		if _, isFunction := triple[1].(string); !ok || !isFunction {
+			return nil, &cacheServerError{Error: "syntax error", Details: fmt.Sprintf("invalid condition %v", cond)}
+		}
+		if column != "_uuid" && !contains(columns, column) {
+			return nil, &cacheServerError{Error: "unknown column", Details: fmt.Sprintf("table %s: column %s is not cached", table, column)}
+		}
+	}
+	return conds, nil
+}
+
+// matchWireConditions returns true when the row matches all the conditions
+// in their JSON form.
+func matchWireConditions(row Row, conds []interface{}) bool {
+	for _, cond := range conds {
+		if b, ok := cond.(bool); ok {
+			if !b {
+				return false
+			}
+			continue
+		}
+		triple := cond.([]interface{})
+		matched, err := matchWire(row, triple[0].(string), triple[1].(string), triple[2])
+		if err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// projectRow returns the columns of the row.
+func projectRow(row Row, columns []string) Row {
+	out := make(Row, len(columns))
+	for _, column := range columns {
+		if value, exists := row[column]; exists {
+			out[column] = value
+		}
+	}
+	return out
+}
+
+// cacheMonitor is a monitor of a reader of a CacheServer.
+type cacheMonitor struct {
+	id      json.RawMessage
+	update2 bool
+	tables  map[string]*cacheMonitorTable
+}
+
+// cacheMonitorTable is the request of a monitored table.
+type cacheMonitorTable struct {
+	columns                         []string
+	where                           []interface{}
+	initial, insert, delete, modify bool
+}
+
+// parse parses the requests of the monitor, by table, see RFC 7047,
+// Section 4.1.5, each of which may be a single request in an array.
+func (m *cacheMonitor) parse(cache *Cache, raw json.RawMessage) *cacheServerError {
+	var requests map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &requests); err != nil {
+		return &cacheServerError{Error: "syntax error", Details: fmt.Sprintf("invalid monitor requests %s", raw)}
+	}
+	m.tables = make(map[string]*cacheMonitorTable, len(requests))
+	cache.mux.RLock()
+	defer cache.mux.RUnlock()
+	for table, b := range requests {
+		if t, exists := cache.tables[table]; !exists || t.view != nil {
+			return &cacheServerError{Error: "unknown table", Details: table}
+		}
+		var list []json.RawMessage
+		if err := json.Unmarshal(b, &list); err == nil {
+			if len(list) != 1 {
+				return &cacheServerError{Error: "not supported", Details: fmt.Sprintf("table %s: a single monitor request is supported per table", table)}
+			}
+			b = list[0]
+		}
+		var request struct {
+			Columns []string        `json:"columns"`
+			Where   json.RawMessage `json:"where"`
+			Select  *struct {
+				Initial *bool `json:"initial"`
+				Insert  *bool `json:"insert"`
+				Delete  *bool `json:"delete"`
+				Modify  *bool `json:"modify"`
+			} `json:"select"`
+		}
+		if err := json.Unmarshal(b, &request); err != nil {
+			return &cacheServerError{Error: "syntax error", Details: fmt.Sprintf("table %s: invalid monitor request %s", table, b)}
+		}
+		cached := cache.cachedColumns(table)
+		t := &cacheMonitorTable{columns: request.Columns, initial: true, insert: true, delete: true, modify: true}
+		if len(t.columns) == 0 {
+			t.columns = cached
+		}
+		for _, column := range t.columns {
+			if !contains(cached, column) {
+				return &cacheServerError{Error: "unknown column", Details: fmt.Sprintf("table %s: column %s is not cached", table, column)}
+			}
+		}
+		if m.update2 {
+			where, err := cache.parseWhere(table, request.Where, true)
+			if err != nil {
+				return err
+			}
+			t.where = where
+		}
+		if s := request.Select; s != nil {
+			for _, flag := range []struct {
+				value *bool
+				set   *bool
+			}{{s.Initial, &t.initial}, {s.Insert, &t.insert}, {s.Delete, &t.delete}, {s.Modify, &t.modify}} {
+				if flag.value != nil {
+					*flag.set = *flag.value
+				}
+			}
+		}
+		m.tables[table] = t
+	}
+	return nil
+}
+
+// initial returns the reply of the monitor: the rows of the tables, for
+// those whose initial rows are selected. The events are held.
+func (m *cacheMonitor) initial(cache *Cache) map[string]map[UUID]interface{} {
+	cache.mux.RLock()
+	defer cache.mux.RUnlock()
+	reply := make(map[string]map[UUID]interface{})
+	for table, t := range m.tables {
+		if !t.initial {
+			continue
+		}
+		for uuid, row := range cache.tables[table].rows {
+			if !matchWireConditions(row, t.where) {
+				continue
+			}
+			if reply[table] == nil {
+				reply[table] = make(map[UUID]interface{})
+			}
+			if m.update2 {
+				reply[table][uuid] = map[string]interface{}{"initial": projectRow(row, t.columns)}
+			} else {
+				reply[table][uuid] = map[string]interface{}{"new": projectRow(row, t.columns)}
+			}
+		}
+	}
+	return reply
+}
+
+// updates returns the notification of the events to the monitor, by table
+// and UUID, in the form of its method. The rows which start, or stop,
+// matching the conditions are inserted, or deleted.
+func (m *cacheMonitor) updates(schema Schema, events []cacheEvent) map[string]map[UUID]interface{} {
+	updates := make(map[string]map[UUID]interface{})
+	for _, e := range events {
+		t, exists := m.tables[e.table]
+		if !exists {
+			continue
+		}
+		before := e.old != nil && matchWireConditions(e.old, t.where)
+		after := e.new != nil && matchWireConditions(e.new, t.where)
+		var u map[string]interface{}
+		switch {
+		case !before && after && t.insert:
+			if m.update2 {
+				u = map[string]interface{}{"insert": projectRow(e.new, t.columns)}
+			} else {
+				u = map[string]interface{}{"new": projectRow(e.new, t.columns)}
+			}
+		case before && !after && t.delete:
+			if m.update2 {
+				u = map[string]interface{}{"delete": nil}
+			} else {
+				u = map[string]interface{}{"old": projectRow(e.old, t.columns)}
+			}
+		case before && after && t.modify:
+			old := make(Row)
+			diff := make(Row)
+			for _, column := range t.columns {
+				if equalValues(e.old[column], e.new[column]) {
+					continue
+				}
+				old[column] = e.old[column]
+				diff[column] = diffValue(schema.Table(e.table).Column(column), e.old[column], e.new[column])
+			}
+			if len(old) == 0 {
+				continue
+			}
+			if m.update2 {
+				u = map[string]interface{}{"modify": diff}
+			} else {
+				u = map[string]interface{}{"old": old, "new": projectRow(e.new, t.columns)}
+			}
+		}
+		if u == nil {
+			continue
+		}
+		if updates[e.table] == nil {
+			updates[e.table] = make(map[UUID]interface{})
+		}
+		updates[e.table][e.uuid] = u
+	}
+	return updates
+}
+
+// diffValue returns the difference of the new value of a column with its
+// old one, which applyDiff applies: the new value of an atom, the elements
+// added to, or removed from, a set, or the pairs added to, removed from, or
+// updated in a map.
+func diffValue(column *Column, old, new interface{}) interface{} {
+	if column == nil || !(column.IsSet() || column.IsMap()) {
+		return new
+	}
+	var before, after []interface{}
+	if old != nil {
+		_, before = splitValue(old)
+	}
+	if new != nil {
+		_, after = splitValue(new)
+	}
+	same := func(a, b interface{}) bool {
+		if column.IsMap() {
+			return reflect.DeepEqual(pairKey(a), pairKey(b))
+		}
+		return reflect.DeepEqual(a, b)
+	}
+	find := func(elems []interface{}, elem interface{}) (interface{}, bool) {
+		for _, e := range elems {
+			if same(e, elem) {
+				return e, true
+			}
+		}
+		return nil, false
+	}
+	out := []interface{}{}
+	for _, elem := range after {
+		// The pairs of a map whose value changed carry the new value.
+		if e, found := find(before, elem); !found || !reflect.DeepEqual(e, elem) {
+			out = append(out, elem)
+		}
+	}
+	for _, elem := range before {
+		if _, found := find(after, elem); !found {
+			out = append(out, elem)
+		}
+	}
+	if column.IsMap() {
+		return []interface{}{"map", out}
+	}
+	return []interface{}{"set", out}
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheListen(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	bridge := func(name string, ids ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"name": name, "fail_mode": "secure", "external_ids": []interface{}{"map", append([]interface{}{}, ids...)}}
+	}
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": bridge("br0", []interface{}{"owner", "ovn"})},
+		}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{
+				"old": map[string]interface{}{"external_ids": []interface{}{"map", []interface{}{[]interface{}{"owner", "ovn"}}}},
+				"new": bridge("br0", []interface{}{"owner", "neutron"}, []interface{}{"zone", "a"}),
+			},
+			br1: map[string]interface{}{"new": bridge("br1")},
+		}}},
+	)
+	upstream, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer upstream.Close()
+	ctx := context.Background()
+	cache, err := upstream.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"name", "external_ids", "fail_mode"}})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	if _, err := cache.Listen("tcp:127.0.0.1:6640"); err == nil {
+		t.Fatalf("FAIL: expected an active remote to fail")
+	}
+	server, err := cache.Listen("ptcp:0:127.0.0.1")
+	if err != nil {
+		t.Fatalf("FAIL: expected to listen, but failed with: %v", err)
+	}
+	defer server.Close()
+	local := "tcp:" + server.Addr().String()
+
+	c, err := NewClient(local, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to the cache, but failed with: %v", err)
+	}
+	defer c.Close()
+	if dbs, err := c.ListDatabases(); err != nil || !reflect.DeepEqual(dbs, []string{"Open_vSwitch"}) {
+		t.Fatalf("FAIL: unexpected databases: %v, %v", dbs, err)
+	}
+	schema, err := c.GetSchemaContext(ctx, "Open_vSwitch")
+	if err != nil {
+		t.Fatalf("FAIL: expected the schema, but failed with: %v", err)
+	}
+	if len(schema.Tables) != 1 || len(schema.Tables["Bridge"].Columns) != 3 || !schema.IsIndex("Bridge", "name") {
+		t.Fatalf("FAIL: expected the schema of the cached columns, got: %+v", schema.Tables)
+	}
+	t.Logf("PASS: schema of the cached columns served")
+
+	results, err := c.TransactOperations(ctx, "Open_vSwitch", Select("Bridge", []string{"name", "_uuid"}, Equal("name", "br0")), Comment("read"))
+	if err != nil {
+		t.Fatalf("FAIL: expected the select to pass, but failed with: %v", err)
+	}
+	if rows := results[0].Rows; len(rows) != 1 || rows[0]["name"] != "br0" || len(rows[0]) != 2 {
+		t.Fatalf("FAIL: unexpected rows selected: %v", rows)
+	}
+	if _, err := c.TransactOperations(ctx, "Open_vSwitch", Delete("Bridge", Equal("name", "br0"))); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("FAIL: expected the delete to be refused, got: %v", err)
+	}
+	if _, err := c.TransactOperations(ctx, "Open_vSwitch", Select("Bridge", []string{"datapath_id"})); err == nil {
+		t.Fatalf("FAIL: expected the select of a column not cached to fail")
+	}
+	_, err = c.TransactOperations(ctx, "Open_vSwitch", Select("Bridge", []string{"name"}), Delete("Bridge", Equal("name", "br0")), Comment("never"))
+	var txErr *TransactionError
+	if !errors.As(err, &txErr) || txErr.Index != 1 || txErr.Err.Message != "not allowed" || len(txErr.Results) != 1 {
+		t.Fatalf("FAIL: expected the second of 3 operations to fail, got: %v", err)
+	}
+	t.Logf("PASS: read-only transactions served")
+
+	m, err := c.Monitor(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"name", "external_ids"}})
+	if err != nil {
+		t.Fatalf("FAIL: expected to monitor the cache, but failed with: %v", err)
+	}
+	if u := nextUpdates(t, m); len(u["Bridge"]) != 1 || u["Bridge"][br0].New["name"] != "br0" {
+		t.Fatalf("FAIL: unexpected initial rows: %v", u)
+	}
+	cond, err := NewClient(local, 1, WithCapabilities(Capabilities{MonitorCond: true}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to the cache, but failed with: %v", err)
+	}
+	defer cond.Close()
+	replica, err := cond.NewCacheTables(ctx, "Open_vSwitch", map[string]MonitorRequest{
+		"Bridge": {Columns: []string{"name", "external_ids"}, Where: []Condition{Equal("name", "br0")}},
+	})
+	if err != nil {
+		t.Fatalf("FAIL: expected the replica to sync, but failed with: %v", err)
+	}
+	defer replica.Close(ctx)
+
+	upstream.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+	u := nextUpdates(t, m)
+	if r := u["Bridge"][br0]; len(u["Bridge"]) != 2 || !equalValues(r.Old["external_ids"], []interface{}{"map", []interface{}{[]interface{}{"owner", "ovn"}}}) || u["Bridge"][br1].New["name"] != "br1" {
+		t.Fatalf("FAIL: unexpected updates: %v", u)
+	}
+	t.Logf("PASS: monitor served")
+
+	want := []interface{}{"map", []interface{}{[]interface{}{"owner", "neutron"}, []interface{}{"zone", "a"}}}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		row, _ := replica.Row("Bridge", br0)
+		if row != nil && equalValues(row["external_ids"], want) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("FAIL: timed out waiting for the replica, got: %v", row)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := replica.Len("Bridge"); n != 1 {
+		t.Fatalf("FAIL: expected the conditions to hold br0 only, got %d rows", n)
+	}
+	t.Logf("PASS: monitor_cond served")
+
+	if err := m.Cancel(ctx); err != nil {
+		t.Fatalf("FAIL: expected to cancel the monitor, but failed with: %v", err)
+	}
+	if err := server.Close(); err != nil {
+		t.Fatalf("FAIL: expected to close, but failed with: %v", err)
+	}
+	select {
+	case <-replica.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("FAIL: expected the replica to end once the server closed")
+	}
+	t.Logf("PASS: server closed")
+}
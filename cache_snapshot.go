@@ -0,0 +1,224 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// cacheSnapshot is the content of the file written by Cache.Save: the rows
+// of the cache, the last transaction they are at, and the requests of the
+// tables they were monitored with.
+type cacheSnapshot struct {
+	Database string                  `json:"database"`
+	TxnID    string                  `json:"txn_id,omitempty"`
+	Requests json.RawMessage         `json:"requests"`
+	Tables   map[string]map[UUID]Row `json:"tables"`
+}
+
+// Save writes the rows of the cache, and the last transaction they are at,
+// to the file, which LoadCache restores the cache from. The file is
+// replaced atomically, so that a crash leaves the previous one.
+func (c *Cache) Save(path string) error {
+	c.events.Lock()
+	requests, err := c.feed.wireRequests()
+	if err != nil {
+		c.events.Unlock()
+		return fmt.Errorf("cache: save: %v", err)
+	}
+	snapshot := cacheSnapshot{
+		Database: c.db,
+		TxnID:    c.txnID,
+		Requests: requests,
+		Tables:   make(map[string]map[UUID]Row, len(c.tables)),
+	}
+	c.mux.RLock()
+	for name, t := range c.tables {
+		if t.view != nil {
+			continue
+		}
+		snapshot.Tables[name] = t.rows
+	}
+	b, err := json.Marshal(snapshot)
+	c.mux.RUnlock()
+	c.events.Unlock()
+	if err != nil {
+		return fmt.Errorf("cache: save: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("cache: save: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cache: save: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cache: save: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cache: save: %v", err)
+	}
+	return nil
+}
+
+// LoadCache is like NewCacheTables, but the cache starts from the rows of
+// the file written by Cache.Save, and only fetches their changes: the
+// monitor_cond_since monitor resumes from the last transaction of the
+// file, and the others receive the rows, which are reconciled with those
+// of the file. The changes are reconciled the same way when the file was
+// written for other tables, columns, or conditions. When the file does not
+// exist, the cache syncs in full.
+func (c *Client) LoadCache(ctx context.Context, path string, db string, requests map[string]MonitorRequest) (*Cache, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c.NewCacheTables(ctx, db, requests)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cache: load: %v", err)
+	}
+	var snapshot cacheSnapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, fmt.Errorf("cache: load %s: %v", path, err)
+	}
+	if snapshot.Database != db {
+		return nil, fmt.Errorf("cache: load %s: the file holds the rows of the %s database", path, snapshot.Database)
+	}
+	m := &Monitor{Method: c.Capabilities().MonitorMethod(), requests: requests}
+	wire, err := json.Marshal(m.wire())
+	if err != nil {
+		return nil, fmt.Errorf("cache: load %s: %v", path, err)
+	}
+	if !bytes.Equal(wire, snapshot.Requests) {
+		// The changes since the transaction would miss rows.
+		snapshot.TxnID = ""
+	}
+	return c.newCache(ctx, db, requests, &snapshot)
+}
+
+// CacheSnapshot is a consistent view of the rows of all the tables of a
+// cache, and of its views, at the boundary of a transaction, see
+// Cache.Snapshot. It no longer changes as the cache does, so that the
+// computations over several tables, e.g. mapping the ports to their
+// chassis, do not see half of a transaction.
+type CacheSnapshot struct {
+	schema Schema
+	tables map[string]*cacheTable
+
+	// mux guards the indexes, built as they are first looked up.
+	mux sync.Mutex
+}
+
+// Snapshot returns a snapshot of the rows of the cache. Taking it copies
+// the maps of the rows, not the rows, which the cache replaces rather
+// than modifies.
+func (c *Cache) Snapshot() *CacheSnapshot {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	s := &CacheSnapshot{schema: c.schema, tables: make(map[string]*cacheTable, len(c.tables))}
+	for name, t := range c.tables {
+		rows := make(map[UUID]Row, len(t.rows))
+		for uuid, row := range t.rows {
+			rows[uuid] = row
+		}
+		indexes := make([]*cacheIndex, len(t.indexes))
+		for i, index := range t.indexes {
+			indexes[i] = &cacheIndex{columns: index.columns}
+		}
+		s.tables[name] = &cacheTable{request: t.request, rows: rows, indexes: indexes, view: t.view}
+	}
+	return s
+}
+
+// Tables returns the names of the tables, and of the views, of the
+// snapshot, sorted.
+func (s *CacheSnapshot) Tables() []string {
+	tables := make([]string, 0, len(s.tables))
+	for name := range s.tables {
+		tables = append(tables, name)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// Len returns the number of rows of the table.
+func (s *CacheSnapshot) Len(table string) int {
+	if t, exists := s.tables[table]; exists {
+		return len(t.rows)
+	}
+	return 0
+}
+
+// Row returns the row of the table, and whether there is one.
+func (s *CacheSnapshot) Row(table string, uuid UUID) (Row, bool) {
+	t, exists := s.tables[table]
+	if !exists {
+		return nil, false
+	}
+	row, exists := t.rows[uuid]
+	return row, exists
+}
+
+// Select is like Cache.Select, on the rows of the snapshot.
+func (s *CacheSnapshot) Select(table string, columns []string, where ...Condition) ([]Row, error) {
+	t, err := lookupTable(s.schema, s.tables, table)
+	if err != nil {
+		return nil, err
+	}
+	return t.selectRows(table, columns, where)
+}
+
+// RowByKey is like Cache.RowByKey, on the rows of the snapshot.
+func (s *CacheSnapshot) RowByKey(table string, key map[string]interface{}) (Row, bool, error) {
+	rows, err := s.LookupIndex(table, key)
+	if err != nil || len(rows) == 0 {
+		return nil, false, err
+	}
+	if len(rows) > 1 {
+		return nil, false, fmt.Errorf("cache: %d rows of table %s match the key", len(rows), table)
+	}
+	return rows[0], true, nil
+}
+
+// LookupIndex is like Cache.LookupIndex, on the rows of the snapshot, by
+// the indexes of the cache when it was taken.
+func (s *CacheSnapshot) LookupIndex(table string, key map[string]interface{}) ([]Row, error) {
+	t, err := lookupTable(s.schema, s.tables, table)
+	if err != nil {
+		return nil, err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return lookupIndex(s.schema, table, t, key, func(columns []string) *cacheIndex {
+		index := t.index(columns)
+		if index != nil && index.rows == nil {
+			index.rows = make(map[string]map[UUID]struct{})
+			for uuid, row := range t.rows {
+				index.add(uuid, row)
+			}
+		}
+		return index
+	})
+}
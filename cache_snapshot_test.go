@@ -0,0 +1,188 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestCacheSaveLoad(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	initial := func(names ...string) map[string]interface{} {
+		rows := make(map[string]interface{})
+		for i, name := range names {
+			rows[[]string{br0, br1}[i]] = map[string]interface{}{"initial": map[string]interface{}{"name": name}}
+		}
+		return map[string]interface{}{"Bridge": rows}
+	}
+	replies := []interface{}{
+		[]interface{}{false, "txn-1", initial("br0", "br1")},
+		[]interface{}{true, "txn-2", map[string]interface{}{"Bridge": map[string]interface{}{
+			br1: map[string]interface{}{"modify": map[string]interface{}{"name": "br2"}},
+		}}},
+		[]interface{}{false, "txn-3", initial("br0")},
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var since []interface{}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "monitor_cond_since":
+			var args []interface{}
+			json.Unmarshal(params, &args)
+			since = append(since, args[3])
+			reply := replies[0]
+			replies = replies[1:]
+			return reply
+		case "monitor_cancel":
+			return map[string]interface{}{}
+		}
+		return nil
+	})
+	c, err := NewClient("tcp:"+l.Addr().String(), 1, WithCapabilities(Capabilities{MonitorCond: true, MonitorCondSince: true}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.json")
+	requests := map[string]MonitorRequest{"Bridge": {Columns: []string{"name"}}}
+	names := func(cache *Cache) []string {
+		rows, err := cache.Select("Bridge", []string{"name"})
+		if err != nil {
+			t.Fatalf("FAIL: select failed: %v", err)
+		}
+		var names []string
+		for _, row := range rows {
+			names = append(names, row["name"].(string))
+		}
+		return names
+	}
+
+	cache, err := c.LoadCache(ctx, path, "Open_vSwitch", requests)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync without a file, but failed with: %v", err)
+	}
+	if err := cache.Save(path); err != nil {
+		t.Fatalf("FAIL: expected the cache to be saved, but failed with: %v", err)
+	}
+	cache.Close(ctx)
+	t.Logf("PASS: cache saved at txn-1")
+
+	cache, err = c.LoadCache(ctx, path, "Open_vSwitch", requests)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to be restored, but failed with: %v", err)
+	}
+	if got := names(cache); !reflect.DeepEqual(got, []string{"br0", "br2"}) {
+		t.Fatalf("FAIL: unexpected rows of the restored cache: %v", got)
+	}
+	if row, found, _ := cache.RowByKey("Bridge", map[string]interface{}{"name": "br0"}); !found || row["name"] != "br0" {
+		t.Fatalf("FAIL: expected the index to hold the restored rows, got: %v", row)
+	}
+	cache.Close(ctx)
+	t.Logf("PASS: cache resumed from txn-1")
+
+	cache, err = c.LoadCache(ctx, path, "Open_vSwitch", map[string]MonitorRequest{"Bridge": {}})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to be restored, but failed with: %v", err)
+	}
+	if got := names(cache); !reflect.DeepEqual(got, []string{"br0"}) {
+		t.Fatalf("FAIL: unexpected rows of the resynced cache: %v", got)
+	}
+	if n := cache.Stats()["Bridge"].Resyncs; n != 1 {
+		t.Fatalf("FAIL: expected the resync to be counted, got %d", n)
+	}
+	cache.Close(ctx)
+
+	if _, err := c.LoadCache(ctx, path, "OVN_Southbound", requests); err == nil {
+		t.Fatalf("FAIL: expected the file of another database to fail")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []interface{}{zeroTxnID, "txn-1", zeroTxnID}; !reflect.DeepEqual(since, want) {
+		t.Fatalf("FAIL: unexpected last transactions requested: %v", since)
+	}
+	t.Logf("PASS: cache resynced for other columns")
+}
+
+func TestCacheSnapshot(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0", "fail_mode": "secure"}},
+		}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"old": map[string]interface{}{"name": "br0"}, "new": map[string]interface{}{"name": "br2", "fail_mode": "secure"}},
+			br1: map[string]interface{}{"new": map[string]interface{}{"name": "br1", "fail_mode": "secure"}},
+		}}},
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"name", "fail_mode"}})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	if err := cache.AddIndex("Bridge", "fail_mode"); err != nil {
+		t.Fatalf("FAIL: expected the index to be added, but failed with: %v", err)
+	}
+	snapshot := cache.Snapshot()
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+	if _, err := cache.WaitFor(ctx, "Bridge", Condition{Column: "name", Function: "==", Value: "br1", Type: "string"}); err != nil {
+		t.Fatalf("FAIL: expected the update, but failed with: %v", err)
+	}
+	if cache.Len("Bridge") != 2 || snapshot.Len("Bridge") != 1 {
+		t.Fatalf("FAIL: expected the snapshot to keep its rows, got %d rows, and %d cached", snapshot.Len("Bridge"), cache.Len("Bridge"))
+	}
+	if row, exists := snapshot.Row("Bridge", br0); !exists || row["name"] != "br0" {
+		t.Fatalf("FAIL: unexpected row of the snapshot: %v", row)
+	}
+	if row, exists, err := snapshot.RowByKey("Bridge", map[string]interface{}{"name": "br0"}); err != nil || !exists || row["fail_mode"] != "secure" {
+		t.Fatalf("FAIL: unexpected row of the key: %v, %v", row, err)
+	}
+	if rows, err := snapshot.LookupIndex("Bridge", map[string]interface{}{"fail_mode": "secure"}); err != nil || len(rows) != 1 {
+		t.Fatalf("FAIL: unexpected rows of the index: %v, %v", rows, err)
+	}
+	if rows, err := snapshot.Select("Bridge", []string{"name"}); err != nil || len(rows) != 1 || rows[0]["name"] != "br0" {
+		t.Fatalf("FAIL: unexpected rows: %v, %v", rows, err)
+	}
+	if _, err := snapshot.Select("Port", nil); err == nil {
+		t.Fatalf("FAIL: expected the select of a table which is not cached to fail")
+	}
+	if got := snapshot.Tables(); len(got) != 1 || got[0] != "Bridge" {
+		t.Fatalf("FAIL: unexpected tables: %v", got)
+	}
+	if rows, _ := cache.LookupIndex("Bridge", map[string]interface{}{"fail_mode": "secure"}); len(rows) != 2 {
+		t.Fatalf("FAIL: expected the cache to hold the update, got: %v", rows)
+	}
+	t.Logf("PASS: snapshot kept its rows")
+}
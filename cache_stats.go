@@ -0,0 +1,129 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"time"
+)
+
+// CacheTableStats are the statistics of a table of a cache, see
+// Cache.Stats.
+type CacheTableStats struct {
+	// Rows is the number of rows of the table.
+	Rows int
+	// Changes is the number of rows inserted, modified, or deleted, since
+	// the cache started, including the rows of the initial snapshot.
+	Changes uint64
+	// Rate is the number of changes per second, over the last minute.
+	Rate float64
+	// LastChange is when a row of the table last changed, zero when none
+	// did.
+	LastChange time.Time
+	// Resyncs is the number of times the rows of the table were sent in
+	// full again, by a monitor_cond_since monitor whose server no longer
+	// had the transaction it resumed from.
+	Resyncs int
+}
+
+// cacheTableStats are the statistics a cache keeps of a table.
+type cacheTableStats struct {
+	changes    uint64
+	lastChange time.Time
+	resyncs    int
+	rate       rateWindow
+}
+
+// rateWindow counts the events of the last minute, by second.
+type rateWindow struct {
+	seconds [60]int64
+	counts  [60]uint64
+}
+
+func (w *rateWindow) add(now time.Time) {
+	s := now.Unix()
+	i := s % int64(len(w.seconds))
+	if w.seconds[i] != s {
+		w.seconds[i] = s
+		w.counts[i] = 0
+	}
+	w.counts[i]++
+}
+
+// rate returns the number of events per second of the last minute.
+func (w *rateWindow) rate(now time.Time) float64 {
+	s := now.Unix()
+	var n uint64
+	for i := range w.seconds {
+		if s-w.seconds[i] < int64(len(w.seconds)) {
+			n += w.counts[i]
+		}
+	}
+	return float64(n) / float64(len(w.seconds))
+}
+
+// record accounts for the events applied, of the transaction, if any, of
+// updates which resync the rows, or not.
+func (c *Cache) record(events []cacheEvent, txnID string, resync bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	now := time.Now()
+	c.lastSync = now
+	c.remember(events, txnID, now)
+	for _, e := range events {
+		t := c.tables[e.table]
+		t.stats.changes++
+		t.stats.lastChange = now
+		t.stats.rate.add(now)
+	}
+	if resync {
+		for _, t := range c.tables {
+			t.stats.resyncs++
+		}
+	}
+}
+
+// Stats returns the statistics of the cached tables, by table.
+func (c *Cache) Stats() map[string]CacheTableStats {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	now := time.Now()
+	stats := make(map[string]CacheTableStats, len(c.tables))
+	for name, t := range c.tables {
+		stats[name] = CacheTableStats{
+			Rows:       len(t.rows),
+			Changes:    t.stats.changes,
+			Rate:       t.stats.rate.rate(now),
+			LastChange: t.stats.lastChange,
+			Resyncs:    t.stats.resyncs,
+		}
+	}
+	return stats
+}
+
+// Staleness returns how long ago the cache was last known to be up to
+// date: when the connection of its monitor was last heard from, which the
+// inactivity probe of a live connection bounds, or when its poller last
+// polled the table polled the longest ago. It grows while the monitor is
+// resumed, and once the cache is no longer kept up to date, so that
+// alerting on it catches a monitor silently stuck.
+func (c *Cache) Staleness() time.Duration {
+	c.mux.RLock()
+	last := c.lastSync
+	c.mux.RUnlock()
+	if heard := c.feed.heard(); heard.After(last) {
+		last = heard
+	}
+	return time.Since(last)
+}
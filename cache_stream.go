@@ -0,0 +1,197 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// cacheStreamQueue is the number of updates queued for a reader of an
+// EventStream, beyond which the reader is too slow, and disconnected.
+const cacheStreamQueue = 1024
+
+// cacheStreamKeepalive is the interval of the comments an EventStream
+// sends to an idle reader, so that the proxies on the way keep the
+// connection open.
+const cacheStreamKeepalive = 15 * time.Second
+
+// cacheStreamEvent is the data of an event of an EventStream.
+type cacheStreamEvent struct {
+	Table string `json:"table"`
+	UUID  UUID   `json:"uuid"`
+	Old   Row    `json:"old,omitempty"`
+	New   Row    `json:"new,omitempty"`
+}
+
+// EventStream returns a handler serving the changes of the rows of the
+// cache over HTTP, as server-sent events, so that the programs which are
+// not written in Go, e.g. dashboards or scripts, tap into them through the
+// process embedding the cache. A GET request streams the rows of the
+// tables of its table parameters, all the cached tables and views when it
+// has none, as insert events, then a synced event, then their changes, as
+// insert, update, and delete events. The data of the events are JSON
+// objects with the table, uuid, and old and new rows, whose values are in
+// the JSON form of RFC 7047, Section 5.1. An end event closes the stream
+// when the cache is no longer kept up to date, and an overflow event when
+// the reader does not keep up.
+func (c *Cache) EventStream() http.Handler {
+	return http.HandlerFunc(c.serveEvents)
+}
+
+func (c *Cache) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	tables := r.URL.Query()["table"]
+	c.events.Lock()
+	c.mux.RLock()
+	for _, table := range tables {
+		if _, err := c.table(table); err != nil {
+			c.mux.RUnlock()
+			c.events.Unlock()
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+	}
+	if len(tables) == 0 {
+		for name := range c.tables {
+			tables = append(tables, name)
+		}
+		sort.Strings(tables)
+	}
+	var initial []cacheEvent
+	for _, table := range tables {
+		for uuid, row := range c.tables[table].rows {
+			initial = append(initial, cacheEvent{table: table, uuid: uuid, new: row})
+		}
+	}
+	c.mux.RUnlock()
+	sortEvents(initial)
+	out := make(chan []cacheEvent, cacheStreamQueue)
+	overflow := make(chan struct{})
+	var once sync.Once
+	unobserve := c.observe(func(events []cacheEvent) {
+		var streamed []cacheEvent
+		for _, e := range events {
+			if contains(tables, e.table) {
+				streamed = append(streamed, e)
+			}
+		}
+		if len(streamed) == 0 {
+			return
+		}
+		select {
+		case out <- streamed:
+		default:
+			once.Do(func() {
+				close(overflow)
+			})
+		}
+	})
+	c.events.Unlock()
+	defer func() {
+		c.events.Lock()
+		unobserve()
+		c.events.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if writeStreamEvents(w, initial) != nil {
+		return
+	}
+	if _, err := fmt.Fprint(w, "event: synced\ndata: {}\n\n"); err != nil {
+		return
+	}
+	flusher.Flush()
+	ticker := time.NewTicker(cacheStreamKeepalive)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case events := <-out:
+			err = writeStreamEvents(w, events)
+		case <-ticker.C:
+			_, err = fmt.Fprint(w, ": keepalive\n\n")
+		case <-overflow:
+			fmt.Fprintf(w, "event: overflow\ndata: %s\n\n", streamData(map[string]interface{}{"queued": cacheStreamQueue}))
+			flusher.Flush()
+			return
+		case <-c.done:
+			// The last updates precede the end.
+			for drained := false; !drained; {
+				select {
+				case events := <-out:
+					writeStreamEvents(w, events)
+				default:
+					drained = true
+				}
+			}
+			reason := ""
+			if err := c.Err(); err != nil {
+				reason = err.Error()
+			}
+			fmt.Fprintf(w, "event: end\ndata: %s\n\n", streamData(map[string]interface{}{"error": reason}))
+			flusher.Flush()
+			return
+		case <-r.Context().Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeStreamEvents writes the events as those of an EventStream.
+func writeStreamEvents(w http.ResponseWriter, events []cacheEvent) error {
+	for _, e := range events {
+		kind := "update"
+		switch {
+		case e.old == nil:
+			kind = "insert"
+		case e.new == nil:
+			kind = "delete"
+		}
+		data := streamData(cacheStreamEvent{Table: e.table, UUID: e.uuid, Old: e.old, New: e.new})
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", kind, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamData returns the data of an event, on a single line.
+func streamData(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	return b
+}
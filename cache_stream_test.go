@@ -0,0 +1,128 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheEventStream(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0"}},
+		}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"old": map[string]interface{}{"name": "br0"}},
+			br1: map[string]interface{}{"new": map[string]interface{}{"name": "br1"}},
+		}}},
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	server := httptest.NewServer(cache.EventStream())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?table=Port")
+	if err != nil {
+		t.Fatalf("FAIL: request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("FAIL: expected a table which is not cached to be not found, got: %s", resp.Status)
+	}
+
+	resp, err = http.Get(server.URL + "?table=Bridge")
+	if err != nil {
+		t.Fatalf("FAIL: request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("FAIL: unexpected content type: %s", ct)
+	}
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	type event struct {
+		kind string
+		data cacheStreamEvent
+	}
+	next := func() event {
+		t.Helper()
+		var e event
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatalf("FAIL: the stream ended")
+				}
+				switch {
+				case strings.HasPrefix(line, "event: "):
+					e.kind = strings.TrimPrefix(line, "event: ")
+				case strings.HasPrefix(line, "data: "):
+					if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e.data); err != nil {
+						t.Fatalf("FAIL: invalid data %q: %v", line, err)
+					}
+				case line == "" && e.kind != "":
+					return e
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("FAIL: timed out waiting for an event")
+			}
+		}
+	}
+	if e := next(); e.kind != "insert" || e.data.Table != "Bridge" || e.data.UUID != br0 || e.data.New["name"] != "br0" {
+		t.Fatalf("FAIL: unexpected event of the rows: %+v", e)
+	}
+	if e := next(); e.kind != "synced" {
+		t.Fatalf("FAIL: expected the stream to be synced, got: %+v", e)
+	}
+	t.Logf("PASS: rows streamed")
+
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+	if e := next(); e.kind != "delete" || e.data.UUID != br0 || e.data.Old["name"] != "br0" || e.data.New != nil {
+		t.Fatalf("FAIL: unexpected event of the deletion: %+v", e)
+	}
+	if e := next(); e.kind != "insert" || e.data.UUID != br1 || e.data.New["name"] != "br1" {
+		t.Fatalf("FAIL: unexpected event of the insertion: %+v", e)
+	}
+	t.Logf("PASS: changes streamed")
+
+	cache.Close(ctx)
+	if e := next(); e.kind != "end" {
+		t.Fatalf("FAIL: expected the end of the stream, got: %+v", e)
+	}
+	t.Logf("PASS: stream ended with the cache")
+}
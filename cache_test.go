@@ -0,0 +1,521 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClientCache(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": map[string]interface{}{
+				"name":         "br0",
+				"external_ids": []interface{}{"map", []interface{}{[]interface{}{"owner", "ovn"}}},
+				"flood_vlans":  []interface{}{"set", []interface{}{float64(10), float64(20)}},
+			}},
+			br1: map[string]interface{}{"new": map[string]interface{}{
+				"name":         "br1",
+				"external_ids": []interface{}{"map", []interface{}{}},
+				"flood_vlans":  float64(30),
+			}},
+		}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"old": map[string]interface{}{"name": "br0"}},
+			br1: map[string]interface{}{
+				"old": map[string]interface{}{"name": "br1"},
+				"new": map[string]interface{}{"name": "br2", "external_ids": []interface{}{"map", []interface{}{}}, "flood_vlans": float64(30)},
+			},
+		}}},
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	if n := cache.Len("Bridge"); n != 2 {
+		t.Fatalf("FAIL: expected 2 rows, got %d", n)
+	}
+	for i, test := range []struct {
+		where []Condition
+		names []string
+	}{
+		{names: []string{"br0", "br1"}},
+		{where: []Condition{Equal("name", "br1")}, names: []string{"br1"}},
+		{where: []Condition{NotEqual("name", "br1")}, names: []string{"br0"}},
+		{where: []Condition{Includes("external_ids", map[string]string{"owner": "ovn"})}, names: []string{"br0"}},
+		{where: []Condition{Excludes("external_ids", map[string]string{"owner": "ovn"})}, names: []string{"br1"}},
+		{where: []Condition{Includes("flood_vlans", []int{20})}, names: []string{"br0"}},
+		{where: []Condition{Equal("flood_vlans", []int{30})}, names: []string{"br1"}},
+		{where: []Condition{Equal("_uuid", UUID(br0))}, names: []string{"br0"}},
+	} {
+		rows, err := cache.Select("Bridge", []string{"name"}, test.where...)
+		if err != nil {
+			t.Fatalf("FAIL: test %d: select failed: %v", i, err)
+		}
+		var names []string
+		for _, row := range rows {
+			names = append(names, row["name"].(string))
+		}
+		if len(names) != len(test.names) || (len(names) > 0 && names[0] != test.names[0]) {
+			t.Fatalf("FAIL: test %d: expected %v, got %v", i, test.names, names)
+		}
+	}
+	t.Logf("PASS: selected the cached rows")
+
+	if row, found, err := cache.RowByKey("Bridge", map[string]interface{}{"name": "br1"}); err != nil || !found || row["flood_vlans"] != float64(30) {
+		t.Fatalf("FAIL: expected br1 by its name, got: %v, %t, %v", row, found, err)
+	}
+	if row, found, err := cache.RowByKey("Bridge", map[string]interface{}{"_uuid": br0}); err != nil || !found || row["name"] != "br0" {
+		t.Fatalf("FAIL: expected br0 by its uuid, got: %v, %t, %v", row, found, err)
+	}
+	if _, _, err := cache.RowByKey("Bridge", map[string]interface{}{"flood_vlans": 30}); err == nil {
+		t.Fatalf("FAIL: expected the lookup by a column which is not an index to fail")
+	}
+	if _, err := cache.Select("Port", nil); !errors.Is(err, ErrTableNotFound) {
+		t.Fatalf("FAIL: expected the unknown table to fail, got: %v", err)
+	}
+	if _, err := cache.Select("Bridge", nil, Equal("foo", "bar")); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("FAIL: expected the unknown column to fail, got: %v", err)
+	}
+
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.Len("Bridge") != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("FAIL: timed out waiting for the cache to apply the update")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, found, _ := cache.RowByKey("Bridge", map[string]interface{}{"name": "br1"}); found {
+		t.Fatalf("FAIL: expected the renamed bridge to leave the index")
+	}
+	if row, found, _ := cache.RowByKey("Bridge", map[string]interface{}{"name": "br2"}); !found || row["flood_vlans"] != float64(30) {
+		t.Fatalf("FAIL: expected the renamed bridge, got: %v", row)
+	}
+	if _, found := cache.Row("Bridge", br0); found {
+		t.Fatalf("FAIL: expected the deleted bridge to leave the cache")
+	}
+	t.Logf("PASS: cache updated")
+
+	if err := cache.Close(ctx); err != nil || cache.Err() != nil {
+		t.Fatalf("FAIL: expected the cache to close, got: %v, %v", err, cache.Err())
+	}
+	if n := cache.Len("Bridge"); n != 1 {
+		t.Fatalf("FAIL: expected the closed cache to keep its rows, got %d", n)
+	}
+}
+
+func TestCacheAddIndex(t *testing.T) {
+	const br0, br1, br2 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31", "8c5f6e2b-0c2f-4a4a-bd51-7e3067c6b1a4"
+	bridge := func(name, mode string, vlans ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"name": name, "fail_mode": mode, "flood_vlans": []interface{}{"set", vlans}}
+	}
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": bridge("br0", "secure", float64(10), float64(20))},
+			br1: map[string]interface{}{"new": bridge("br1", "secure")},
+			br2: map[string]interface{}{"new": bridge("br2", "standalone")},
+		}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br1: map[string]interface{}{"old": map[string]interface{}{"fail_mode": "secure"}, "new": bridge("br1", "standalone")},
+		}}},
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	lookup := func(key map[string]interface{}) []string {
+		t.Helper()
+		rows, err := cache.LookupIndex("Bridge", key)
+		if err != nil {
+			t.Fatalf("FAIL: lookup of %v failed: %v", key, err)
+		}
+		var names []string
+		for _, row := range rows {
+			names = append(names, row["name"].(string))
+		}
+		return names
+	}
+
+	if _, err := cache.LookupIndex("Bridge", map[string]interface{}{"fail_mode": "secure"}); err == nil {
+		t.Fatalf("FAIL: expected the lookup without an index to fail")
+	}
+	if err := cache.AddIndex("Bridge", "foo"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("FAIL: expected the index of an unknown column to fail, got: %v", err)
+	}
+	if err := cache.AddIndex("Bridge", "fail_mode"); err != nil {
+		t.Fatalf("FAIL: expected the index to be added, but failed with: %v", err)
+	}
+	if err := cache.AddIndex("Bridge", "flood_vlans"); err != nil {
+		t.Fatalf("FAIL: expected the index to be added, but failed with: %v", err)
+	}
+	if got := lookup(map[string]interface{}{"fail_mode": "secure"}); !reflect.DeepEqual(got, []string{"br0", "br1"}) {
+		t.Fatalf("FAIL: unexpected bridges of the secure mode: %v", got)
+	}
+	if got := lookup(map[string]interface{}{"flood_vlans": []int{20, 10}}); !reflect.DeepEqual(got, []string{"br0"}) {
+		t.Fatalf("FAIL: unexpected bridges of the vlans: %v", got)
+	}
+	if got := lookup(map[string]interface{}{"name": "br2"}); !reflect.DeepEqual(got, []string{"br2"}) {
+		t.Fatalf("FAIL: unexpected bridges of the name: %v", got)
+	}
+	t.Logf("PASS: secondary indexes added")
+
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+	deadline := time.Now().Add(5 * time.Second)
+	for len(lookup(map[string]interface{}{"fail_mode": "standalone"})) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("FAIL: timed out waiting for the index to be updated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := lookup(map[string]interface{}{"fail_mode": "secure"}); !reflect.DeepEqual(got, []string{"br0"}) {
+		t.Fatalf("FAIL: unexpected bridges of the secure mode after the update: %v", got)
+	}
+	if _, _, err := cache.RowByKey("Bridge", map[string]interface{}{"fail_mode": "standalone"}); err == nil {
+		t.Fatalf("FAIL: expected the lookup of a row by a key of many rows to fail")
+	}
+	t.Logf("PASS: secondary indexes updated")
+
+	names, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"name"}})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer names.Close(ctx)
+	if err := names.AddIndex("Bridge", "fail_mode"); err == nil {
+		t.Fatalf("FAIL: expected the index of a column which is not monitored to fail")
+	}
+}
+
+func TestCacheAddView(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0", "fail_mode": "secure", "datapath_id": []interface{}{"set", []interface{}{}}}},
+			br1: map[string]interface{}{"new": map[string]interface{}{"name": "br1", "fail_mode": []interface{}{"set", []interface{}{}}, "datapath_id": "br0"}},
+		}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br1: map[string]interface{}{"old": map[string]interface{}{"datapath_id": "br0"}, "new": map[string]interface{}{"name": "br1", "fail_mode": []interface{}{"set", []interface{}{}}, "datapath_id": "br1"}},
+		}}},
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"name", "fail_mode", "datapath_id"}})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	// The view joins each bridge with the bridge whose datapath_id is its
+	// name, if any.
+	sources := []ViewSource{
+		{Table: "Bridge", Key: "name", Columns: map[string]string{"mode": "fail_mode", "bridge": "_uuid"}},
+		{Table: "Bridge", Key: "datapath_id", Columns: map[string]string{"datapath_of": "name"}, Optional: true},
+	}
+	if err := cache.AddView("Bridge", "name", sources...); err == nil {
+		t.Fatalf("FAIL: expected a view named after a table to fail")
+	}
+	if err := cache.AddView("Bridge_View", "name", ViewSource{Table: "Bridge", Key: "name", Columns: map[string]string{"ports": "ports"}}); err == nil {
+		t.Fatalf("FAIL: expected a view of a column which is not monitored to fail")
+	}
+	if err := cache.AddView("Bridge_View", "name", sources[1]); err == nil {
+		t.Fatalf("FAIL: expected a view of an optional first source to fail")
+	}
+	if err := cache.AddView("Bridge_View", "name", sources[0], ViewSource{Table: "Bridge", Key: "name", Columns: map[string]string{"mode": "name"}}); err == nil {
+		t.Fatalf("FAIL: expected a view with duplicate columns to fail")
+	}
+	if err := cache.AddView("Bridge_View", "name", sources...); err != nil {
+		t.Fatalf("FAIL: expected the view to be added, but failed with: %v", err)
+	}
+	row := func(name string) Row {
+		t.Helper()
+		row, exists, err := cache.RowByKey("Bridge_View", map[string]interface{}{"name": name})
+		if err != nil || !exists {
+			t.Fatalf("FAIL: expected the row of %s in the view, got: %v, %v", name, exists, err)
+		}
+		return row
+	}
+	empty := []interface{}{"set", []interface{}{}}
+	if r := row("br0"); r["mode"] != "secure" || r["datapath_of"] != "br1" || !reflect.DeepEqual(r["bridge"], []interface{}{"uuid", br0}) {
+		t.Fatalf("FAIL: unexpected row of br0: %v", r)
+	}
+	if r := row("br1"); !reflect.DeepEqual(r["mode"], empty) || !reflect.DeepEqual(r["datapath_of"], empty) {
+		t.Fatalf("FAIL: unexpected row of br1: %v", r)
+	}
+	if rows, err := cache.Select("Bridge_View", []string{"name"}, Condition{Column: "mode", Function: "==", Value: "secure", Type: "string"}); err != nil || len(rows) != 1 || rows[0]["name"] != "br0" {
+		t.Fatalf("FAIL: unexpected rows of the secure bridges: %v, %v", rows, err)
+	}
+	t.Logf("PASS: view added")
+
+	updates := make(chan [2]Row, 4)
+	cache.RegisterHandler("Bridge_View", EventHandler{
+		OnUpdate: func(_ string, old, new Row) { updates <- [2]Row{old, new} },
+	})
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+	got := map[string]Row{}
+	for len(got) < 2 {
+		select {
+		case u := <-updates:
+			got[u[1]["name"].(string)] = u[1]
+		case <-time.After(5 * time.Second):
+			t.Fatalf("FAIL: timed out waiting for the view to be updated")
+		}
+	}
+	if !reflect.DeepEqual(got["br0"]["datapath_of"], empty) || got["br1"]["datapath_of"] != "br1" {
+		t.Fatalf("FAIL: unexpected rows of the view after the update: %v", got)
+	}
+	if r := row("br1"); r["datapath_of"] != "br1" {
+		t.Fatalf("FAIL: unexpected row of br1 after the update: %v", r)
+	}
+	t.Logf("PASS: view maintained")
+}
+
+func TestCacheStats(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0"}},
+		}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"old": map[string]interface{}{"name": "br0"}, "new": map[string]interface{}{"name": "br2"}},
+			br1: map[string]interface{}{"new": map[string]interface{}{"name": "br1"}},
+		}}},
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	start := time.Now()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	stats := cache.Stats()["Bridge"]
+	if stats.Rows != 1 || stats.Changes != 1 || stats.Resyncs != 0 || stats.LastChange.Before(start) || stats.Rate <= 0 {
+		t.Fatalf("FAIL: unexpected statistics of the snapshot: %+v", stats)
+	}
+	t.Logf("PASS: snapshot accounted for")
+
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.Len("Bridge") != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("FAIL: timed out waiting for the update")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats := cache.Stats()["Bridge"]; stats.Rows != 2 || stats.Changes != 3 || stats.Rate != 3.0/60 {
+		t.Fatalf("FAIL: unexpected statistics of the update: %+v", stats)
+	}
+	if s := cache.Staleness(); s < 0 || s > time.Second {
+		t.Fatalf("FAIL: expected a live cache to be fresh, got staleness %s", s)
+	}
+	t.Logf("PASS: update accounted for")
+
+	cache.Close(ctx)
+	time.Sleep(50 * time.Millisecond)
+	if s := cache.Staleness(); s < 50*time.Millisecond {
+		t.Fatalf("FAIL: expected a closed cache to grow stale, got staleness %s", s)
+	}
+	t.Logf("PASS: closed cache grows stale")
+}
+
+func TestCacheHistory(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	update := func(rows map[string]interface{}) testServerNotify {
+		return testServerNotify{method: "update", params: map[string]interface{}{"Bridge": rows}}
+	}
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0"}},
+		}},
+		update(map[string]interface{}{
+			br0: map[string]interface{}{"old": map[string]interface{}{"name": "br0"}, "new": map[string]interface{}{"name": "br2"}},
+		}),
+		update(map[string]interface{}{
+			br1: map[string]interface{}{"new": map[string]interface{}{"name": "br1"}},
+		}),
+		update(map[string]interface{}{
+			br0: map[string]interface{}{"old": map[string]interface{}{"name": "br2"}},
+		}),
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	if err := cache.KeepHistory(-1); err == nil {
+		t.Fatalf("FAIL: expected a negative length to fail")
+	}
+	if err := cache.KeepHistory(2); err != nil {
+		t.Fatalf("FAIL: expected to keep the history, but failed with: %v", err)
+	}
+	changes := make(chan struct{}, 4)
+	cache.RegisterHandler("Bridge", EventHandler{
+		OnAdd:    func(string, Row) { changes <- struct{}{} },
+		OnUpdate: func(string, Row, Row) { changes <- struct{}{} },
+		OnDelete: func(string, Row) { changes <- struct{}{} },
+	})
+	// The rows of the cache are replayed first.
+	<-changes
+	var times []time.Time
+	for i := 0; i < 3; i++ {
+		times = append(times, time.Now())
+		c.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+		select {
+		case <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("FAIL: timed out waiting for update %d", i)
+		}
+	}
+	entries, err := cache.History("Bridge", time.Time{})
+	if err != nil {
+		t.Fatalf("FAIL: expected the history, but failed with: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Table != "Bridge" || entries[0].Rows[br1].New["name"] != "br1" || entries[0].Rows[br1].Old != nil ||
+		entries[1].Rows[br0].Old["name"] != "br2" || entries[1].Rows[br0].New != nil || entries[1].Time.Before(entries[0].Time) {
+		t.Fatalf("FAIL: expected the last 2 changes, got: %+v", entries)
+	}
+	t.Logf("PASS: last changes kept")
+	if entries, _ := cache.History("Bridge", times[2]); len(entries) != 1 || entries[0].Rows[br0].New != nil {
+		t.Fatalf("FAIL: expected the changes since the deletion, got: %+v", entries)
+	}
+	if entries, _ := cache.History("", time.Now().Add(time.Minute)); len(entries) != 0 {
+		t.Fatalf("FAIL: expected no changes in the future, got: %+v", entries)
+	}
+	if _, err := cache.History("Port", time.Time{}); err == nil {
+		t.Fatalf("FAIL: expected the history of a table not cached to fail")
+	}
+	t.Logf("PASS: history queried")
+}
+
+func TestCacheWaitFor(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	update := func(rows map[string]interface{}) testServerNotify {
+		return testServerNotify{method: "update", params: map[string]interface{}{"Bridge": rows}}
+	}
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0", "fail_mode": "standalone"}},
+		}},
+		update(map[string]interface{}{
+			br1: map[string]interface{}{"new": map[string]interface{}{"name": "br1", "fail_mode": "secure"}},
+		}),
+		update(map[string]interface{}{
+			br0: map[string]interface{}{"old": map[string]interface{}{"fail_mode": "standalone"}, "new": map[string]interface{}{"name": "br0", "fail_mode": "secure"}},
+		}),
+		update(map[string]interface{}{
+			br0: map[string]interface{}{"old": map[string]interface{}{"name": "br0", "fail_mode": "secure"}},
+			br1: map[string]interface{}{"old": map[string]interface{}{"name": "br1", "fail_mode": "secure"}},
+		}),
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"name", "fail_mode"}})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	if _, err := cache.WaitFor(ctx, "Bridge", Equal("datapath_id", "x")); err == nil {
+		t.Fatalf("FAIL: expected a condition on a column not monitored to fail")
+	}
+	if row, err := cache.WaitFor(ctx, "Bridge", Equal("name", "br0")); err != nil || row["fail_mode"] != "standalone" {
+		t.Fatalf("FAIL: expected the cached row right away, got: %v, %v", row, err)
+	}
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := cache.WaitFor(short, "Bridge", Equal("name", "br1")); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("FAIL: expected the wait to time out, got: %v", err)
+	}
+	t.Logf("PASS: cached rows checked")
+
+	wait := func(fn func() (Row, error)) <-chan Row {
+		ch := make(chan Row, 1)
+		go func() {
+			row, err := fn()
+			if err != nil {
+				t.Errorf("FAIL: expected the wait to end, but failed with: %v", err)
+			}
+			ch <- row
+		}()
+		return ch
+	}
+	receive := func(ch <-chan Row) Row {
+		select {
+		case row := <-ch:
+			return row
+		case <-time.After(5 * time.Second):
+			t.Fatalf("FAIL: timed out waiting")
+		}
+		return nil
+	}
+	// The sleeps let the waits register before the updates are pushed.
+	appeared := wait(func() (Row, error) { return cache.WaitFor(ctx, "Bridge", Equal("name", "br1")) })
+	time.Sleep(20 * time.Millisecond)
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("insert"))
+	if row := receive(appeared); row["fail_mode"] != "secure" {
+		t.Fatalf("FAIL: unexpected row appeared: %v", row)
+	}
+	changed := wait(func() (Row, error) { return cache.WaitForChange(ctx, "Bridge", Equal("name", "br0")) })
+	time.Sleep(20 * time.Millisecond)
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("modify"))
+	if row := receive(changed); row["fail_mode"] != "secure" {
+		t.Fatalf("FAIL: unexpected row changed: %v", row)
+	}
+	gone := wait(func() (Row, error) { return nil, cache.WaitForAbsence(ctx, "Bridge", Equal("fail_mode", "secure")) })
+	time.Sleep(20 * time.Millisecond)
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("delete"))
+	receive(gone)
+	if n := cache.Len("Bridge"); n != 0 {
+		t.Fatalf("FAIL: expected the rows to be deleted, got %d", n)
+	}
+	if err := cache.WaitForAbsence(ctx, "Bridge"); err != nil {
+		t.Fatalf("FAIL: expected the absence right away, got: %v", err)
+	}
+	t.Logf("PASS: appearance, change, and absence waited for")
+}
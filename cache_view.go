@@ -0,0 +1,224 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ViewSource is a table joined into a view, see AddView.
+type ViewSource struct {
+	// Table is the cached table.
+	Table string
+	// Key is the column whose values the rows of the view join on, e.g.
+	// the name column of Chassis, or the chassis_name column of Encap.
+	Key string
+	// Columns are the columns of the table the view holds, by the name of
+	// the column of the view, e.g. "encap_ip": "ip". The _uuid column of
+	// the table may be one of them.
+	Columns map[string]string
+	// Optional sources leave their columns empty sets in the rows of the
+	// view whose key they have no row of. The first source of a view is
+	// never optional: the view has a row by key of its rows.
+	Optional bool
+}
+
+// cacheView is a join of cached tables, which the cache maintains as a
+// table of its own, by the values of the key.
+type cacheView struct {
+	name    string
+	key     string
+	sources []ViewSource
+}
+
+// AddView adds a view to the cache: a table whose rows join those of the
+// sources whose key columns have the same value, e.g. Chassis, Encap and
+// Chassis_Private by the chassis name. The cache maintains the view as
+// the rows of the sources change, and reads it like its tables, with Row,
+// Select, LookupIndex or RegisterHandler, under the name. The rows of the
+// view hold the key column, the columns of the sources, and a _uuid of
+// the value of the key. When several rows of a source have the key, that
+// of the lowest UUID is joined.
+//
+// Views are not served by Listen, nor saved by Save.
+func (c *Cache) AddView(name, key string, sources ...ViewSource) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if _, exists := c.tables[name]; exists || c.schema.Table(name) != nil {
+		return fmt.Errorf("cache: view %s: the name is that of a table", name)
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("cache: view %s: no sources", name)
+	}
+	if sources[0].Optional {
+		return fmt.Errorf("cache: view %s: the first source, %s, cannot be optional", name, sources[0].Table)
+	}
+	columns := []string{key}
+	for _, source := range sources {
+		t, err := c.table(source.Table)
+		if err != nil {
+			return err
+		}
+		if t.view != nil {
+			return fmt.Errorf("cache: view %s: source %s is a view", name, source.Table)
+		}
+		for _, column := range append([]string{source.Key}, valuesOf(source.Columns)...) {
+			if c.schema.Table(source.Table).Column(column) == nil {
+				return fmt.Errorf("cache: view %s: table %s: column %s: %w", name, source.Table, column, ErrColumnNotFound)
+			}
+			if column != "_uuid" && !t.request.monitors([]string{column}) {
+				return fmt.Errorf("cache: view %s: table %s: column %s is not monitored", name, source.Table, column)
+			}
+		}
+		for column := range source.Columns {
+			if column == "_uuid" || column == "_version" || contains(columns, column) {
+				return fmt.Errorf("cache: view %s: duplicate column %s", name, column)
+			}
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	v := &cacheView{name: name, key: key, sources: sources}
+	// The rows of the sources are looked up by key.
+	for _, source := range sources {
+		t := c.tables[source.Table]
+		if t.index([]string{source.Key}) == nil {
+			index := newCacheIndex([]string{source.Key})
+			for uuid, row := range t.rows {
+				index.add(uuid, row)
+			}
+			t.indexes = append(t.indexes, index)
+		}
+	}
+	c.tables[name] = &cacheTable{
+		request: MonitorRequest{Columns: columns},
+		rows:    make(map[UUID]Row),
+		indexes: []*cacheIndex{newCacheIndex([]string{key})},
+		view:    v,
+	}
+	for k := range c.tables[sources[0].Table].index([]string{sources[0].Key}).rows {
+		c.refreshView(v, k)
+	}
+	return nil
+}
+
+func valuesOf(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, value := range m {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// refreshViews updates the rows of the views whose keys the events of
+// their sources have, and returns the events of the views. The cache must
+// be locked.
+func (c *Cache) refreshViews(events []cacheEvent) []cacheEvent {
+	var changes []cacheEvent
+	for _, t := range c.tables {
+		if t.view == nil {
+			continue
+		}
+		keys := make(map[string]struct{})
+		for _, e := range events {
+			for _, source := range t.view.sources {
+				if source.Table != e.table {
+					continue
+				}
+				for _, row := range []Row{e.old, e.new} {
+					if row != nil {
+						keys[indexKey(row, []string{source.Key})] = struct{}{}
+					}
+				}
+			}
+		}
+		for key := range keys {
+			if e, changed := c.refreshView(t.view, key); changed {
+				changes = append(changes, e)
+			}
+		}
+	}
+	return changes
+}
+
+// refreshView joins the rows of the sources of the view with the key, in
+// the form of indexKey, and returns the change of the row of the view,
+// and whether there is one.
+func (c *Cache) refreshView(v *cacheView, key string) (cacheEvent, bool) {
+	t := c.tables[v.name]
+	uuid := viewUUID(v.name, key)
+	e := cacheEvent{table: v.name, uuid: uuid, old: t.rows[uuid], new: c.joinView(v, key, uuid)}
+	if e.old == nil && e.new == nil || reflect.DeepEqual(e.old, e.new) {
+		return cacheEvent{}, false
+	}
+	if e.old != nil {
+		for _, index := range t.indexes {
+			index.remove(uuid, e.old)
+		}
+		delete(t.rows, uuid)
+	}
+	if e.new != nil {
+		t.rows[uuid] = e.new
+		for _, index := range t.indexes {
+			index.add(uuid, e.new)
+		}
+	}
+	return e, true
+}
+
+// joinView returns the row of the view with the key, and the UUID, or nil
+// when the first source has no row of it.
+func (c *Cache) joinView(v *cacheView, key string, uuid UUID) Row {
+	row := Row{"_uuid": []interface{}{"uuid", string(uuid)}}
+	for i, source := range v.sources {
+		t := c.tables[source.Table]
+		var first UUID
+		for u := range t.index([]string{source.Key}).rows[key] {
+			if first == "" || u < first {
+				first = u
+			}
+		}
+		if first == "" {
+			if i == 0 {
+				return nil
+			}
+			for column := range source.Columns {
+				row[column] = []interface{}{"set", []interface{}{}}
+			}
+			continue
+		}
+		if i == 0 {
+			row[v.key] = t.rows[first][source.Key]
+		}
+		for column, from := range source.Columns {
+			row[column] = t.rows[first][from]
+		}
+	}
+	return row
+}
+
+// viewUUID returns the UUID of the row of the view with the key, derived
+// from both, as a version 5 UUID is, so that it is the same whenever the
+// row is.
+func viewUUID(view, key string) UUID {
+	b := sha1.Sum([]byte(view + "\x00" + key))
+	b[6] = b[6]&0x0f | 0x50
+	b[8] = b[8]&0x3f | 0x80
+	return UUID(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]))
+}
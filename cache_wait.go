@@ -0,0 +1,155 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// WaitFor waits until a row of the table matches all the conditions, and
+// returns it, e.g. until the Port_Binding of a logical port is bound to a
+// chassis. It returns a matching row the cache holds right away.
+func (c *Cache) WaitFor(ctx context.Context, table string, where ...Condition) (Row, error) {
+	return c.wait(ctx, table, where, func(rows map[UUID]Row) (Row, int) {
+		for _, row := range rows {
+			if matches(row, where) {
+				return row, 0
+			}
+		}
+		return nil, 0
+	}, func(e cacheEvent, matched int) (Row, int) {
+		if e.new != nil && matches(e.new, where) {
+			return e.new, 0
+		}
+		return nil, 0
+	})
+}
+
+// WaitForChange waits until a row of the table which matches all the
+// conditions, before or after the change, is modified, and returns the row
+// after the change. The changes made before the call do not count.
+func (c *Cache) WaitForChange(ctx context.Context, table string, where ...Condition) (Row, error) {
+	return c.wait(ctx, table, where, nil, func(e cacheEvent, matched int) (Row, int) {
+		if e.old != nil && e.new != nil && (matches(e.old, where) || matches(e.new, where)) {
+			return e.new, 0
+		}
+		return nil, 0
+	})
+}
+
+// WaitForAbsence waits until no row of the table matches all the
+// conditions, e.g. until a logical switch deleted leaves the southbound
+// database. It returns right away when no row the cache holds does.
+func (c *Cache) WaitForAbsence(ctx context.Context, table string, where ...Condition) error {
+	// The rows matching are counted, and the wait ends with the last one,
+	// returned as an empty row.
+	_, err := c.wait(ctx, table, where, func(rows map[UUID]Row) (Row, int) {
+		matched := 0
+		for _, row := range rows {
+			if matches(row, where) {
+				matched++
+			}
+		}
+		if matched == 0 {
+			return Row{}, 0
+		}
+		return nil, matched
+	}, func(e cacheEvent, matched int) (Row, int) {
+		if e.old != nil && matches(e.old, where) {
+			matched--
+		}
+		if e.new != nil && matches(e.new, where) {
+			matched++
+		}
+		if matched == 0 {
+			return Row{}, 0
+		}
+		return nil, matched
+	})
+	return err
+}
+
+// matches returns true when the row matches all the conditions.
+func matches(row Row, where []Condition) bool {
+	matched, err := matchConditions(row, where)
+	return err == nil && matched
+}
+
+// wait checks the rows of the table the cache holds, if check is set, then
+// the events of the updates of the table, until either returns a row. They
+// carry the number of rows matching along.
+func (c *Cache) wait(ctx context.Context, table string, where []Condition, check func(rows map[UUID]Row) (Row, int), next func(e cacheEvent, matched int) (Row, int)) (Row, error) {
+	c.events.Lock()
+	c.mux.RLock()
+	t, err := c.table(table)
+	if err == nil {
+		columns := c.cachedColumns(table)
+		for _, cond := range where {
+			if cond.Column != "_uuid" && !contains(columns, cond.Column) {
+				err = fmt.Errorf("cache: table %s: column %s is not monitored", table, cond.Column)
+				break
+			}
+		}
+	}
+	if err != nil {
+		c.mux.RUnlock()
+		c.events.Unlock()
+		return nil, err
+	}
+	var row Row
+	var matched int
+	if check != nil {
+		row, matched = check(t.rows)
+	}
+	c.mux.RUnlock()
+	if row != nil {
+		c.events.Unlock()
+		return row, nil
+	}
+	found := make(chan Row, 1)
+	unobserve := c.observe(func(events []cacheEvent) {
+		for _, e := range events {
+			if e.table != table {
+				continue
+			}
+			var row Row
+			if row, matched = next(e, matched); row != nil {
+				select {
+				case found <- row:
+				default:
+				}
+				return
+			}
+		}
+	})
+	c.events.Unlock()
+	defer func() {
+		c.events.Lock()
+		unobserve()
+		c.events.Unlock()
+	}()
+	select {
+	case row := <-found:
+		return row, nil
+	case <-c.done:
+		if err := c.Err(); err != nil {
+			return nil, fmt.Errorf("cache: %w", err)
+		}
+		return nil, fmt.Errorf("cache: closed")
+	case <-ctx.Done():
+		return nil, fmt.Errorf("cache: %w", contextError(ctx))
+	}
+}
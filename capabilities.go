@@ -0,0 +1,126 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Capabilities describes the server a client is connected to, as learned
+// by the handshake performed on every connection of the client.
+type Capabilities struct {
+	// Databases are the databases the server serves.
+	Databases []string
+	// ServerID is the UUID of the server. It is empty when the server
+	// does not support the get_server_id method.
+	ServerID string
+	// MonitorCond is true when the server supports the monitor_cond
+	// method. It is inferred from the presence of the _Server database.
+	MonitorCond bool
+	// MonitorCondSince is true when the server supports the
+	// monitor_cond_since method. It is inferred from the support of the
+	// get_server_id method, which was introduced along with it.
+	MonitorCondSince bool
+}
+
+// MonitorMethod returns the most capable monitor method the server
+// supports.
+func (c Capabilities) MonitorMethod() string {
+	switch {
+	case c.MonitorCondSince:
+		return "monitor_cond_since"
+	case c.MonitorCond:
+		return "monitor_cond"
+	}
+	return "monitor"
+}
+
+// WithHandshake makes the client perform the handshake after connecting,
+// and every time it reconnects, since the members of a cluster may run
+// different versions.
+func WithHandshake() ClientOption {
+	return func(cli *Client) error {
+		cli.Handshake = true
+		return nil
+	}
+}
+
+// WithCapabilities pins the capabilities of the server. The client does
+// not perform the handshake, and reports the capabilities as given, e.g.
+// to keep using monitor_cond with servers which support more.
+func WithCapabilities(caps Capabilities) ClientOption {
+	return func(cli *Client) error {
+		cli.Handshake = false
+		cli.capabilities = caps
+		cli.pinned = true
+		return nil
+	}
+}
+
+// Capabilities returns the capabilities of the server the client is
+// connected to. They are zero unless the client performed the handshake,
+// or the capabilities were pinned.
+func (cli *Client) Capabilities() Capabilities {
+	cli.cacheMux.Lock()
+	defer cli.cacheMux.Unlock()
+	caps := cli.capabilities
+	caps.Databases = append([]string{}, caps.Databases...)
+	return caps
+}
+
+// handshake learns the capabilities of the server over a fresh connection.
+// It talks to the messenger directly, because it runs while a request may
+// hold the client.
+func (cli *Client) handshake(ctx context.Context) error {
+	if cli.pinned {
+		return nil
+	}
+	caps := Capabilities{}
+	method := "list_dbs"
+	resp, err := cli.link.roundTrip(ctx, Request{Method: method})
+	if err != nil {
+		return fmt.Errorf("handshake: '%s' method failed: %w", method, err)
+	}
+	if caps.Databases, err = resp.Databases(); err != nil {
+		return fmt.Errorf("handshake: '%s' method failed: %v", method, err)
+	}
+	for _, db := range caps.Databases {
+		if db == ServerDatabaseName {
+			caps.MonitorCond = true
+		}
+	}
+	method = "get_server_id"
+	resp, err = cli.link.roundTrip(ctx, Request{Method: method})
+	var respErr *responseError
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(resp.Result, &caps.ServerID); err != nil {
+			return fmt.Errorf("handshake: '%s' method failed: %v", method, err)
+		}
+		caps.MonitorCond = true
+		caps.MonitorCondSince = true
+	case errors.As(err, &respErr):
+		// The server predates the method.
+	default:
+		return fmt.Errorf("handshake: '%s' method failed: %w", method, err)
+	}
+	cli.cacheMux.Lock()
+	cli.capabilities = caps
+	cli.cacheMux.Unlock()
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientHandshake(t *testing.T) {
+	unknownMethod := testServerError{map[string]interface{}{"error": "unknown method"}}
+	for i, test := range []struct {
+		dbs      []string
+		serverID interface{}
+		opts     []ClientOption
+		expected Capabilities
+		method   string
+	}{
+		{
+			dbs:      []string{"Open_vSwitch", "_Server"},
+			serverID: "3b7a5c1e-7d0f-4c2e-9b8a-5f1d2e3c4b5a",
+			opts:     []ClientOption{WithHandshake()},
+			expected: Capabilities{
+				Databases:        []string{"Open_vSwitch", "_Server"},
+				ServerID:         "3b7a5c1e-7d0f-4c2e-9b8a-5f1d2e3c4b5a",
+				MonitorCond:      true,
+				MonitorCondSince: true,
+			},
+			method: "monitor_cond_since",
+		},
+		{
+			dbs:      []string{"Open_vSwitch", "_Server"},
+			serverID: unknownMethod,
+			opts:     []ClientOption{WithHandshake()},
+			expected: Capabilities{
+				Databases:   []string{"Open_vSwitch", "_Server"},
+				MonitorCond: true,
+			},
+			method: "monitor_cond",
+		},
+		{
+			dbs:      []string{"Open_vSwitch"},
+			serverID: unknownMethod,
+			opts:     []ClientOption{WithHandshake()},
+			expected: Capabilities{
+				Databases: []string{"Open_vSwitch"},
+			},
+			method: "monitor",
+		},
+		{
+			dbs:      []string{"Open_vSwitch", "_Server"},
+			serverID: "3b7a5c1e-7d0f-4c2e-9b8a-5f1d2e3c4b5a",
+			opts:     []ClientOption{WithHandshake(), WithCapabilities(Capabilities{MonitorCond: true})},
+			expected: Capabilities{
+				Databases:   []string{},
+				MonitorCond: true,
+			},
+			method: "monitor_cond",
+		},
+		{
+			dbs:      []string{"Open_vSwitch", "_Server"},
+			serverID: "3b7a5c1e-7d0f-4c2e-9b8a-5f1d2e3c4b5a",
+			expected: Capabilities{
+				Databases: []string{},
+			},
+			method: "monitor",
+		},
+	} {
+		sock := filepath.Join(t.TempDir(), "db.sock")
+		l, err := net.Listen("unix", sock)
+		if err != nil {
+			t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+		}
+		newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+			switch method {
+			case "list_dbs":
+				return test.dbs
+			case "get_server_id":
+				return test.serverID
+			}
+			return nil
+		})
+		cli, err := NewClient("unix:"+sock, 1, test.opts...)
+		if err != nil {
+			t.Fatalf("FAIL: Test %d: expected to connect, but failed with: %v", i, err)
+		}
+		caps := cli.Capabilities()
+		cli.Close()
+		if len(caps.Databases) != len(test.expected.Databases) || caps.ServerID != test.expected.ServerID ||
+			caps.MonitorCond != test.expected.MonitorCond || caps.MonitorCondSince != test.expected.MonitorCondSince {
+			t.Fatalf("FAIL: Test %d: expected %+v, but got %+v", i, test.expected, caps)
+		}
+		if caps.MonitorMethod() != test.method {
+			t.Fatalf("FAIL: Test %d: expected %s monitor method, but got %s", i, test.method, caps.MonitorMethod())
+		}
+		t.Logf("PASS: Test %d: capabilities %+v", i, caps)
+	}
+}
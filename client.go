@@ -17,7 +17,11 @@
 package ovsdb
 
 import (
+	"bufio"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	//"github.com/davecgh/go-spew/spew"
@@ -26,112 +30,1005 @@ import (
 	"net"
 	"net/rpc"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Client DOCS-TBD
 type Client struct {
-	mux        sync.Mutex
+	mux sync.Mutex
+	// cacheMux protects Schemas, References, and the cluster state, so
+	// that transactions may be issued from multiple goroutines.
+	cacheMux   sync.Mutex
 	Endpoint   string
 	Timeout    int
 	MaxRetries int
 	Schemas    map[string]Schema
 	References map[string]map[string]map[string]string
-	txQueue    chan Request
-	rxQueue    chan Response
-	errQueue   chan error
-	closed     bool
+	TLSConfig  *tls.Config
+	// Reconnect controls the delays between reconnect attempts. The number
+	// of attempts is MaxRetries.
+	Reconnect ReconnectPolicy
+	// OnReconnect, when set, is called after every reconnect attempt.
+	OnReconnect func(ReconnectEvent)
+	// InsertChunkSize, when set, is the number of rows InsertRows inserts
+	// per transaction, DefaultInsertChunkSize by default.
+	InsertChunkSize int
+	// SkipSchemaValidation, when set, makes the client send its
+	// transactions without validating them against the cached schemas.
+	SkipSchemaValidation bool
+	// DecodeMode selects whether the rows the client cannot decode fail
+	// the calls, or are skipped, see WithDecodeMode.
+	DecodeMode DecodeMode
+	decodeLog  *decodeLog
+	// Converters, when set, convert the columns the client decodes, see
+	// WithConverters.
+	Converters *ConverterRegistry
+	// QueryCacheSize is the number of parsed queries the client keeps,
+	// DefaultQueryCacheSize by default. A negative size disables the cache.
+	QueryCacheSize int
+	queries        *queryCache
+	// OnLockChange, when set by WithLockHook, is called whenever the
+	// client acquires or loses a lock, see Lock.
+	OnLockChange func(id string, locked bool)
+	locks        *lockTable
+	monitors     *monitorTable
+	// TransactRetry controls the retries of the read-only transactions
+	// which fail with a transient error of the server, see IsTransient.
+	// The transactions are not retried unless its MaxRetries is set.
+	TransactRetry RetryPolicy
+	// ReadYourWrites, when set, makes the reads of the client observe the
+	// rows it has written, see WithReadYourWrites.
+	ReadYourWrites        bool
+	ReadYourWritesTimeout time.Duration
+	// written are the rows written which have not been read since.
+	written *writeLog
+	// ConnectRetries is the number of times NewClient retries to connect
+	// to an endpoint which is not reachable yet, waiting for
+	// ConnectRetryInterval between the attempts.
+	ConnectRetries       int
+	ConnectRetryInterval time.Duration
+	// Keepalive, when set, is the inactivity probe interval. A client
+	// which has not heard from the server for the interval sends an echo
+	// request, and drops the connection when the server does not answer
+	// it within another interval.
+	Keepalive time.Duration
+	// IdleCheck, when set, makes the client probe a connection which has
+	// been idle for longer than the interval with an echo request before
+	// sending a request on it, and re-dial when the probe is not answered
+	// within IdleProbeTimeout, one second by default. The request does not
+	// have to time out to discover a dead connection.
+	IdleCheck        time.Duration
+	IdleProbeTimeout time.Duration
+	// RequestTimeout, when set, bounds the time of every request
+	// issued with a context that has no deadline. Timeout bounds only
+	// establishing connections.
+	RequestTimeout time.Duration
+	// ClusterMode selects the members of a clustered database the
+	// transactions are sent to.
+	ClusterMode ClusterMode
+	// Handshake, when set, makes the client learn the capabilities of
+	// the server on every connection.
+	Handshake bool
+	// SocketCheck, when set, makes the client check the ownership and
+	// the permissions of unix sockets before connecting to them.
+	SocketCheck bool
+	// MaxResponseSize, when set, is the maximum size of a message of the
+	// server, in bytes. A larger message fails the request with
+	// ErrResponseTooLarge and drops the connection.
+	MaxResponseSize int
+	// ReadBufferSize, when set, is the size of the buffer the messages
+	// of the server are read through.
+	ReadBufferSize int
+	// SSH, when set, makes the client tunnel its connections through an
+	// SSH server. The remotes are connected to from the server.
+	SSH *SSHConfig
+	// MonitorBuffer is the number of updates the Updates of the monitors
+	// buffer, and MonitorOverflow the policy they follow once it is full,
+	// see WithMonitorBuffer. OnMonitorOverflow, when set, is called on
+	// every overflow.
+	MonitorBuffer     int
+	MonitorOverflow   OverflowPolicy
+	OnMonitorOverflow func(MonitorOverflow)
+	// OnSyncProgress, when set, is called with the progress of the initial
+	// sync of the monitors, see WithSyncProgress.
+	OnSyncProgress func(SyncProgress)
+	// Dial, when set, establishes the connections of the client in place
+	// of the built-in transports.
+	Dial DialFunc
+	// Logger, when set, receives the diagnostic messages of the client.
+	Logger Logger
+	// Observer, when set, receives the events of the client, e.g. to
+	// collect metrics.
+	Observer Observer
+	// Tracer, when set, traces the requests of the client.
+	Tracer Tracer
+	// Identity, when set, is added as a comment operation to every
+	// transaction of the client which modifies the database, so that the
+	// changes are attributable in the log of the database, e.g. in the
+	// output of "ovsdb-tool show-log".
+	Identity string
+	// CertificateReloader, when set, provides the client certificate of
+	// the "ssl:" connections, so that rotated certificates are picked up
+	// by the next connection.
+	CertificateReloader *CertificateReloader
+	// Rehandshake, when set, makes the client reconnect once the
+	// certificate it presented has been reloaded.
+	Rehandshake bool
+	// certGen is the generation of the certificate presented on the
+	// current connection, or zero.
+	certGen uint64
+	// remotes are the endpoints of the members of a clustered database.
+	// The client connects to one of them at a time.
+	remotes []string
+	remote  int
+	// connID identifies the current connection.
+	connID  uint64
+	leaders map[string]leaderState
+	leader  *Client
+	// leaderMux serializes the connections to the leader.
+	leaderMux sync.Mutex
+	options   []ClientOption
+	// capabilities are learned by the handshake, or pinned.
+	capabilities Capabilities
+	pinned       bool
+	limiter      *rateLimiter
+	breaker      *circuitBreaker
+	// closeMux protects the shutdown state.
+	closeMux sync.Mutex
+	closing  bool
+	inflight int
+	drained  chan struct{}
+	// passive is set for connections initiated by the server, which the
+	// client cannot re-establish.
+	passive bool
+	// lastSeen is the time the last message was received from the
+	// server, in nanoseconds since the epoch.
+	lastSeen *atomic.Int64
+	// received is the number of bytes received from the server.
+	received *atomic.Int64
+	// attached is the time the current connection was established.
+	attached time.Time
+	link     *link
+	closed   bool
+}
+
+// ClientOption configures a Client before it connects to its endpoint.
+type ClientOption func(*Client) error
+
+// DialFunc establishes a connection to the address on the network, which
+// is "unix", "tcp", or "pipe", e.g. over vsock or a proxy. For "ssl:"
+// remotes, the network is "tcp" and the client performs the TLS handshake
+// on the returned connection.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// WithDialer makes the client establish its connections with the function,
+// so that it can use transports of its own, e.g. vsock sockets of virtual
+// machines, proxied sockets, or in-memory pipes in tests. The socket check
+// does not apply, and the option cannot be combined with WithSSH.
+func WithDialer(fn DialFunc) ClientOption {
+	return func(cli *Client) error {
+		cli.Dial = fn
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for "ssl:" endpoints.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(cli *Client) error {
+		cli.TLSConfig = config
+		return nil
+	}
+}
+
+// WithCertificateReloader makes the client present the certificate of the
+// reloader on "ssl:" connections. With rehandshake, the client reconnects
+// as soon as it notices that the certificate has been reloaded, rather
+// than when the connection breaks.
+func WithCertificateReloader(r *CertificateReloader, rehandshake bool) ClientOption {
+	return func(cli *Client) error {
+		cli.CertificateReloader = r
+		cli.Rehandshake = rehandshake
+		return nil
+	}
+}
+
+// WithKeepalive sets the inactivity probe interval of a client.
+func WithKeepalive(interval time.Duration) ClientOption {
+	return func(cli *Client) error {
+		cli.Keepalive = interval
+		return nil
+	}
+}
+
+// WithIdleCheck makes the client probe the connections idle for longer
+// than the interval before using them, and re-dial the ones which do not
+// answer the probe within the timeout.
+func WithIdleCheck(idle, timeout time.Duration) ClientOption {
+	return func(cli *Client) error {
+		cli.IdleCheck = idle
+		cli.IdleProbeTimeout = timeout
+		return nil
+	}
+}
+
+// WithRequestTimeout sets the default request timeout of a client.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(cli *Client) error {
+		cli.RequestTimeout = timeout
+		return nil
+	}
+}
+
+// WithIdentity sets the identity the transactions of a client are stamped
+// with.
+func WithIdentity(identity string) ClientOption {
+	return func(cli *Client) error {
+		cli.Identity = identity
+		return nil
+	}
+}
+
+// WithMaxResponseSize sets the maximum size of a message of the server.
+func WithMaxResponseSize(size int) ClientOption {
+	return func(cli *Client) error {
+		if size < 0 {
+			return fmt.Errorf("invalid maximum response size: %d", size)
+		}
+		cli.MaxResponseSize = size
+		return nil
+	}
+}
+
+// WithReadBufferSize sets the size of the read buffer of a client.
+func WithReadBufferSize(size int) ClientOption {
+	return func(cli *Client) error {
+		if size < 0 {
+			return fmt.Errorf("invalid read buffer size: %d", size)
+		}
+		cli.ReadBufferSize = size
+		return nil
+	}
 }
 
 // NewClient TODO
-func NewClient(s string, t int) (Client, error) {
+func NewClient(s string, t int, opts ...ClientOption) (Client, error) {
+	return NewClientContext(context.Background(), s, t, opts...)
+}
+
+// NewClientContext creates a client and connects it to the endpoint. The
+// context bounds the time spent establishing the connection.
+func NewClientContext(ctx context.Context, s string, t int, opts ...ClientOption) (Client, error) {
 	cli := Client{}
+	if err := cli.init(s, t, opts); err != nil {
+		cli.closed = true
+		return cli, err //nolint:govet
+	}
+	err := cli.connectRetry(ctx)
+	return cli, err //nolint:govet
+}
+
+// init sets the defaults of a client and applies the options.
+func (cli *Client) init(s string, t int, opts []ClientOption) error {
 	cli.Endpoint = s
 	cli.Timeout = t
-	cli.MaxRetries = 2
+	cli.Reconnect = DefaultReconnectPolicy()
+	cli.MaxRetries = cli.Reconnect.MaxRetries
 	cli.Schemas = make(map[string]Schema)
 	cli.References = make(map[string]map[string]map[string]string)
-	// send only channel
-	cli.txQueue = make(chan Request, 1)
-	// receive only channels
-	cli.rxQueue = make(chan Response, 1)
-	cli.errQueue = make(chan error, 1)
-	go ovsdbMessenger(cli.Endpoint, cli.Timeout, cli.txQueue, cli.rxQueue, cli.errQueue)
-	err := <-cli.errQueue
-	return cli, err //nolint:govet
+	cli.remotes = splitRemotes(s)
+	cli.options = opts
+	cli.lastSeen = new(atomic.Int64)
+	cli.received = new(atomic.Int64)
+	cli.decodeLog = &decodeLog{}
+	for _, opt := range opts {
+		if err := opt(cli); err != nil {
+			return err
+		}
+	}
+	cli.queries = newQueryCache(cli.QueryCacheSize)
+	cli.locks = &lockTable{held: make(map[string]bool), onChange: cli.OnLockChange}
+	cli.monitors = &monitorTable{monitors: make(map[string]*Monitor)}
+	return nil
 }
 
 // Close TODO
 func (cli *Client) Close() error {
-	_, err := cli.query("shutdown", nil)
+	cli.cacheMux.Lock()
+	leader := cli.leader
+	cli.cacheMux.Unlock()
+	if leader != nil {
+		leader.Close()
+	}
+	if cli.monitors != nil {
+		cli.monitors.reset(fmt.Errorf("monitor ended: client closed: %w", ErrNotConnected), true)
+	}
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	cli.disconnect()
+	return nil
+}
+
+// Shutdown shuts the client down gracefully. It rejects new requests with
+// ErrClosing, waits for the requests in flight to complete, and closes the
+// client. When the context is done first, the client is closed anyway and
+// the requests still in flight fail. The client cannot be used afterwards.
+func (cli *Client) Shutdown(ctx context.Context) error {
+	cli.closeMux.Lock()
+	cli.closing = true
+	if cli.drained == nil {
+		cli.drained = make(chan struct{})
+		if cli.inflight == 0 {
+			close(cli.drained)
+		}
+	}
+	drained := cli.drained
+	cli.closeMux.Unlock()
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		cli.closeMux.Lock()
+		err = fmt.Errorf("closed with %d requests in flight: %w", cli.inflight, contextError(ctx))
+		cli.closeMux.Unlock()
+	}
+	cli.Close()
 	return err
 }
 
-func (cli *Client) query(method string, param interface{}) (*Response, error) {
-	if cli == nil {
-		return nil, fmt.Errorf("client was not initialized")
+// isClosing returns true when the client is shutting down.
+func (cli *Client) isClosing() bool {
+	cli.closeMux.Lock()
+	defer cli.closeMux.Unlock()
+	return cli.closing
+}
+
+// admittedKey marks the contexts of the requests admitted while the
+// client was not closing, so that the requests they issue in turn, e.g.
+// for the schema of a table, are not rejected midway.
+type admittedKey struct{}
+
+// admit admits a request, unless the client is shutting down. The done
+// function must be called when the request completes.
+func (cli *Client) admit(ctx context.Context) (context.Context, func(), error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ctx.Value(admittedKey{}) != nil {
+		return ctx, func() {}, nil
 	}
-	if method == "shutdown" && cli.closed {
-		return nil, nil
+	cli.closeMux.Lock()
+	defer cli.closeMux.Unlock()
+	if cli.closing {
+		return ctx, nil, ErrClosing
+	}
+	cli.inflight++
+	done := func() {
+		cli.closeMux.Lock()
+		defer cli.closeMux.Unlock()
+		cli.inflight--
+		if cli.inflight == 0 && cli.drained != nil {
+			close(cli.drained)
+		}
 	}
+	return context.WithValue(ctx, admittedKey{}, true), done, nil
+}
+
+// splitRemotes splits a comma-separated list of remotes, e.g.
+// "ssl:10.0.0.1:6642,ssl:10.0.0.2:6642".
+func splitRemotes(s string) []string {
+	remotes := []string{}
+	for _, remote := range strings.Split(s, ",") {
+		remote = strings.TrimSpace(remote)
+		if remote == "" {
+			continue
+		}
+		remotes = append(remotes, remote)
+	}
+	if len(remotes) == 0 {
+		remotes = append(remotes, s)
+	}
+	return remotes
+}
+
+// LastSeen returns the time the client last received a message from the
+// server, or the zero time when it has not received any yet. A connection
+// which has been silent for long is likely dead.
+func (cli *Client) LastSeen() time.Time {
+	if cli.lastSeen == nil || cli.lastSeen.Load() == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, cli.lastSeen.Load())
+}
+
+// Remote returns the remote the client is connected, or is going to
+// connect, to.
+func (cli *Client) Remote() string {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	return cli.remoteLocked()
+}
+
+// remoteLocked is Remote for the callers which hold the client lock.
+func (cli *Client) remoteLocked() string {
+	if len(cli.remotes) == 0 {
+		return cli.Endpoint
+	}
+	return cli.remotes[cli.remote]
+}
+
+// Failover drops the current connection and makes the client proceed to
+// the next remote on the list. The next request establishes the
+// connection.
+func (cli *Client) Failover() {
 	cli.mux.Lock()
 	defer cli.mux.Unlock()
+	cli.disconnect()
+	cli.nextRemote()
+}
+
+func (cli *Client) nextRemote() {
+	if len(cli.remotes) > 1 {
+		cli.remote = (cli.remote + 1) % len(cli.remotes)
+	}
+}
+
+// connect connects to the current remote. When the remote is unavailable,
+// it tries the other remotes on the list in order.
+func (cli *Client) connect(ctx context.Context) error {
+	if cli.passive {
+		return fmt.Errorf("the connection was initiated by the server, which has to reconnect")
+	}
+	if len(cli.remotes) == 0 {
+		cli.remotes = splitRemotes(cli.Endpoint)
+	}
 	errMsgs := []string{}
+	for i := 0; i < len(cli.remotes); i++ {
+		remote := cli.remotes[cli.remote]
+		cli.logger().Debugf("connecting to %s", remote)
+		err := cli.dial(ctx, remote)
+		if err == nil && cli.Handshake {
+			if err = cli.handshake(ctx); err != nil {
+				cli.disconnect()
+			}
+		}
+		if err == nil {
+			cli.logger().Infof("connected to %s", remote)
+			return nil
+		}
+		cli.logger().Warnf("failed connecting to %s: %v", remote, err)
+		if len(cli.remotes) == 1 || ctx.Err() != nil {
+			return err
+		}
+		errMsgs = append(errMsgs, fmt.Sprintf("%s: %s", remote, err))
+		cli.nextRemote()
+	}
+	return fmt.Errorf("failed connecting to any of the remotes: %s", strings.Join(errMsgs, "; "))
+}
+
+// dial dials the remote and starts a messenger for the connection.
+// Each messenger gets its own link, so that a messenger of a dropped
+// connection cannot deliver stale responses to the next one.
+func (cli *Client) dial(ctx context.Context, remote string) error {
+	t := cli.Timeout
+	if t == 0 {
+		t = 2
+	}
+	serverProto, serverAddr, err := parseSocket(remote)
+	if err != nil {
+		cli.closed = true
+		return err
+	}
+	cli.certGen = 0
+	if cli.Dial != nil {
+		return cli.dialCustom(ctx, remote, serverProto, serverAddr, time.Second*time.Duration(t))
+	}
+	if cli.SSH != nil {
+		return cli.dialSSH(ctx, remote, serverProto, serverAddr, time.Second*time.Duration(t))
+	}
+	if serverProto == "unix" && namedPipes {
+		serverProto, serverAddr = "pipe", pipeName(serverAddr)
+	}
+	if serverProto == "unix" && cli.SocketCheck {
+		if err := CheckSocket(serverAddr); err != nil {
+			cli.closed = true
+			return err
+		}
+	}
+	dialer := net.Dialer{
+		Timeout: time.Second * time.Duration(t),
+	}
+	var conn net.Conn
+	switch serverProto {
+	case "pipe":
+		conn, err = dialPipe(ctx, serverAddr, dialer.Timeout)
+	case "ssl":
+		if cli.TLSConfig == nil {
+			cli.closed = true
+			return fmt.Errorf("the %s endpoint requires TLS configuration", remote)
+		}
+		var gen uint64
+		tlsDialer := tls.Dialer{
+			NetDialer: &dialer,
+			Config:    cli.clientTLSConfig(&gen),
+		}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", serverAddr)
+		cli.certGen = gen
+	default:
+		conn, err = dialer.DialContext(ctx, serverProto, serverAddr)
+	}
+	if err != nil {
+		cli.closed = true
+		return err
+	}
+	cli.attach(conn)
+	return nil
+}
+
+// dialSSH connects to the remote from the SSH server of the client. The
+// socket checks do not apply, because the sockets are on the server.
+func (cli *Client) dialSSH(ctx context.Context, remote, serverProto, serverAddr string, timeout time.Duration) error {
+	network := serverProto
+	switch serverProto {
+	case "ssl":
+		if cli.TLSConfig == nil {
+			cli.closed = true
+			return fmt.Errorf("the %s endpoint requires TLS configuration", remote)
+		}
+		network = "tcp"
+	case "unix", "tcp":
+	default:
+		cli.closed = true
+		return fmt.Errorf("the %s endpoint is not supported over SSH", remote)
+	}
+	conn, err := dialSSHTunnel(ctx, cli.SSH, network, serverAddr, timeout)
+	if err != nil {
+		cli.closed = true
+		return err
+	}
+	if serverProto == "ssl" {
+		if conn, err = cli.handshakeTLS(ctx, conn, remote, serverAddr); err != nil {
+			cli.closed = true
+			return err
+		}
+	}
+	cli.attach(conn)
+	return nil
+}
+
+// dialCustom connects to the remote with the dial function of the client.
+func (cli *Client) dialCustom(ctx context.Context, remote, serverProto, serverAddr string, timeout time.Duration) error {
+	if cli.SSH != nil {
+		cli.closed = true
+		return fmt.Errorf("the dial function cannot be combined with SSH")
+	}
+	network := serverProto
+	if serverProto == "ssl" {
+		if cli.TLSConfig == nil {
+			cli.closed = true
+			return fmt.Errorf("the %s endpoint requires TLS configuration", remote)
+		}
+		network = "tcp"
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	conn, err := cli.Dial(dialCtx, network, serverAddr)
+	cancel()
+	if err != nil {
+		cli.closed = true
+		return err
+	}
+	if serverProto == "ssl" {
+		if conn, err = cli.handshakeTLS(ctx, conn, remote, serverAddr); err != nil {
+			cli.closed = true
+			return err
+		}
+	}
+	cli.attach(conn)
+	return nil
+}
+
+// handshakeTLS performs the TLS handshake on the connection to the remote.
+// The connection is closed when the handshake fails.
+func (cli *Client) handshakeTLS(ctx context.Context, conn net.Conn, remote, serverAddr string) (net.Conn, error) {
+	var gen uint64
+	config := cli.clientTLSConfig(&gen).Clone()
+	if config.ServerName == "" {
+		config.ServerName, _, _ = net.SplitHostPort(serverAddr)
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake with %s failed: %w", remote, err)
+	}
+	cli.certGen = gen
+	return tlsConn, nil
+}
+
+// clientTLSConfig returns the TLS configuration of a new connection. With
+// a certificate reloader, the generation of the certificate presented to
+// the server, if the server asks for one, is stored in gen.
+func (cli *Client) clientTLSConfig(gen *uint64) *tls.Config {
+	r := cli.CertificateReloader
+	if r == nil {
+		return cli.TLSConfig
+	}
+	config := cli.TLSConfig.Clone()
+	config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		cert, g := r.current()
+		*gen = g
+		return cert, nil
+	}
+	return config
+}
+
+// staleCertificate returns true when the client should reconnect, because
+// the certificate it presented on the current connection was reloaded.
+// The client must be locked.
+func (cli *Client) staleCertificate() bool {
+	if !cli.Rehandshake || cli.CertificateReloader == nil || cli.passive || cli.certGen == 0 {
+		return false
+	}
+	return cli.CertificateReloader.generation.Load() != cli.certGen
+}
+
+// attach starts a messenger for the connection.
+func (cli *Client) attach(conn net.Conn) {
+	cli.link = &link{
+		calls:  make(chan *call),
+		forget: make(chan *call),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+		conn:   conn,
+		log:    cli.logger(),
+	}
+	if cli.locks != nil {
+		// The locks are held by the connection they were requested on.
+		cli.locks.reset()
+	}
+	if cli.monitors != nil {
+		// So are the monitors.
+		cli.monitors.reset(fmt.Errorf("monitor ended: connection replaced: %w", ErrNotConnected), false)
+	}
+	if cli.locks != nil || cli.monitors != nil {
+		cli.link.notify = cli.notify
+	}
+	cli.connID++
+	cli.closed = false
+	cli.attached = time.Now()
+	if cli.lastSeen == nil {
+		cli.lastSeen = new(atomic.Int64)
+	}
+	if cli.received == nil {
+		cli.received = new(atomic.Int64)
+	}
+	var rw io.ReadWriteCloser = conn
+	if cli.Observer != nil {
+		rw = &observedConn{Conn: conn, observer: cli.Observer}
+	}
+	rw = &countedConn{ReadWriteCloser: rw, n: cli.received}
+	codec := newClientCodec(rw, cli.ReadBufferSize, cli.MaxResponseSize)
+	go ovsdbMessenger(codec, cli.link, cli.lastSeen, cli.Keepalive)
+}
+
+// disconnect tears down the current connection without waiting for the
+// messenger. The requests pending on the connection fail.
+func (cli *Client) disconnect() {
+	if cli.closed {
+		return
+	}
+	cli.closed = true
+	if cli.link != nil {
+		cli.link.close()
+	}
+}
+
+func (cli *Client) query(method string, param interface{}) (*Response, error) {
+	return cli.queryContext(context.Background(), method, param)
+}
+
+func (cli *Client) queryContext(ctx context.Context, method string, param interface{}) (*Response, error) {
+	if cli == nil {
+		return nil, fmt.Errorf("client was not initialized: %w", ErrNotConnected)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline && cli.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cli.RequestTimeout)
+		defer cancel()
+	}
+	ctx, done, err := cli.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
 	req := Request{
 		Method: method,
 		Params: param,
 	}
-	for {
-		if !cli.closed {
-			cli.txQueue <- req
-			select {
-			case err := <-cli.errQueue:
-				cli.closed = true
-				if method == "shutdown" {
-					return nil, nil
+	event := newRequestEvent(cli.Endpoint, method, param)
+	var finish func(error)
+	if cli.Tracer != nil {
+		ctx, finish = cli.Tracer.StartRequest(ctx, event)
+	}
+	start := time.Now()
+	resp, err := cli.throttledSend(ctx, req)
+	elapsed := time.Since(start)
+	if finish != nil {
+		finish(err)
+	}
+	if err != nil {
+		cli.logger().Debugf("'%s' request failed after %s: %v", method, elapsed, err)
+	} else {
+		cli.logger().Debugf("'%s' request completed in %s", method, elapsed)
+	}
+	if cli.Observer != nil {
+		event.Duration = elapsed
+		event.Err = err
+		cli.Observer.ObserveRequest(event)
+	}
+	return resp, err
+}
+
+// throttledSend sends the request, subject to the rate limiter and the
+// circuit breaker of the client.
+func (cli *Client) throttledSend(ctx context.Context, req Request) (*Response, error) {
+	if err := cli.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	if err := cli.breaker.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := cli.send(ctx, req)
+	cli.breaker.done(err)
+	return resp, err
+}
+
+// send sends the request, reconnecting the client as needed.
+func (cli *Client) send(ctx context.Context, req Request) (*Response, error) {
+	// A client that lost its connection earlier gets an extra attempt to
+	// reconnect, because it is not a retry of this request.
+	cli.mux.Lock()
+	budget := cli.MaxRetries
+	if cli.closed {
+		budget++
+	}
+	cli.mux.Unlock()
+	var cause, lastErr error
+	reconnects := 0
+	for failures := 0; ; failures++ {
+		if ctx.Err() != nil {
+			return nil, contextError(ctx)
+		}
+		// The client is locked only while it is being connected, so that
+		// the requests of many goroutines share the connection.
+		cli.mux.Lock()
+		if !cli.closed && cli.staleCertificate() {
+			// Reconnecting with the reloaded certificate is not a retry.
+			cli.logger().Infof("reconnecting to %s with the reloaded certificate", cli.remoteLocked())
+			cli.disconnect()
+			budget++
+		}
+		if !cli.closed && cli.idle() {
+			if err := cli.probe(ctx); err != nil {
+				if ctx.Err() != nil {
+					cli.mux.Unlock()
+					return nil, contextError(ctx)
 				}
-				errMsgs = append(errMsgs, err.Error())
-			case resp := <-cli.rxQueue:
-				return &resp, nil
+				// Re-dialing a dead connection is not a retry either.
+				cli.logger().Warnf("idle connection to %s is dead: %v", cli.remoteLocked(), err)
+				cli.disconnect()
+				budget++
 			}
 		}
-		retryAttempts := cli.MaxRetries
-		for {
-			if cli.closed {
-				go ovsdbMessenger(cli.Endpoint, cli.Timeout, cli.txQueue, cli.rxQueue, cli.errQueue)
-				err := <-cli.errQueue
-				if err == nil {
-					cli.closed = false
-					break
+		if cli.closed {
+			if cli.isClosing() {
+				cli.mux.Unlock()
+				return nil, fmt.Errorf("client unavailable: %w", ErrClosing)
+			}
+			if budget < 1 {
+				cli.mux.Unlock()
+				if lastErr == nil {
+					return nil, fmt.Errorf("client unavailable: %w", ErrNotConnected)
 				}
+				return nil, fmt.Errorf("client unavailable after %d reconnect attempts: %w: %w", reconnects, ErrNotConnected, lastErr)
 			}
-			if retryAttempts < 1 {
-				if len(errMsgs) == 0 {
-					return nil, fmt.Errorf("client unavailable")
+			budget--
+			reconnects++
+			if err := cli.reconnect(ctx, failures, reconnects, cause); err != nil {
+				cli.mux.Unlock()
+				if ctx.Err() != nil {
+					return nil, contextError(ctx)
 				}
-				return nil, fmt.Errorf("%s", errMsgs)
+				lastErr = err
+				continue
 			}
-			retryAttempts--
 		}
+		l := cli.link
+		cli.mux.Unlock()
+		resp, err := l.roundTrip(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		var respErr *responseError
+		if errors.As(err, &respErr) {
+			return nil, respErr.err
+		}
+		if ctx.Err() != nil {
+			return nil, contextError(ctx)
+		}
+		// The connection broke. Of the requests pending on it, the first
+		// one to notice makes the client proceed to the next remote.
+		cli.mux.Lock()
+		if cli.link == l && !cli.closed {
+			cli.logger().Warnf("connection to %s broke: %v", cli.remoteLocked(), err)
+			cli.disconnect()
+			cli.nextRemote()
+		}
+		cli.mux.Unlock()
+		if errors.Is(err, ErrResponseTooLarge) {
+			// Retrying would fetch the same response again.
+			return nil, err
+		}
+		cause, lastErr = err, err
+	}
+}
+
+// idle returns true when the current connection has been idle for longer
+// than the idle check interval. The client must be locked.
+func (cli *Client) idle() bool {
+	if cli.IdleCheck <= 0 {
+		return false
+	}
+	last := cli.attached
+	if seen := cli.LastSeen(); seen.After(last) {
+		last = seen
+	}
+	return time.Since(last) > cli.IdleCheck
+}
+
+// probe sends an echo request on the current connection. The client must
+// be locked, so that the requests of other goroutines wait for the probe.
+func (cli *Client) probe(ctx context.Context) error {
+	timeout := cli.IdleProbeTimeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	js, _ := encodeString("idle")
+	_, err := cli.link.roundTrip(ctx, Request{Method: "echo", Params: js})
+	return err
+}
+
+// reconnect waits for the backoff delay of the attempt and connects the
+// client. The client must be locked.
+func (cli *Client) reconnect(ctx context.Context, failures, attempt int, cause error) error {
+	delay := cli.Reconnect.backoff(failures)
+	cli.logger().Infof("reconnecting to %s in %s, attempt %d", cli.remoteLocked(), delay, attempt)
+	if err := sleepContext(ctx, delay); err != nil {
+		return err
+	}
+	err := cli.connect(ctx)
+	event := ReconnectEvent{
+		Endpoint: cli.remoteLocked(),
+		Attempt:  attempt,
+		Delay:    delay,
+		Cause:    cause,
+		Err:      err,
+	}
+	if cli.OnReconnect != nil {
+		cli.OnReconnect(event)
+	}
+	if cli.Observer != nil {
+		cli.Observer.ObserveReconnect(event)
+	}
+	return err
+}
+
+// link is a connection and the messenger serving it.
+type link struct {
+	calls  chan *call    // the requests to send
+	forget chan *call    // the requests abandoned by their callers
+	quit   chan struct{} // closed to stop the messenger
+	done   chan struct{} // closed when the messenger exits
+	err    error         // why the messenger exited, set before done is closed
+	conn   io.Closer
+	once   sync.Once
+	log    Logger
+	// notify, when set, receives the notifications of the locks and of
+	// the monitors.
+	notify func(method string, params json.RawMessage)
+}
+
+// call is a request waiting for its response.
+type call struct {
+	req  Request
+	seq  uint64     // assigned by the messenger
+	done chan reply // receives the outcome of the request
+}
+
+// reply is the outcome of a request.
+type reply struct {
+	resp Response
+	err  error
+}
+
+// close stops the messenger and closes the connection, which unblocks any
+// pending read or write of the messenger.
+func (l *link) close() {
+	l.once.Do(func() {
+		close(l.quit)
+		l.conn.Close()
+	})
+}
+
+// roundTrip sends the request to the messenger and waits for the response.
+// Many requests may be pending on a link at once; the messenger matches the
+// responses to them by request id.
+func (l *link) roundTrip(ctx context.Context, req Request) (*Response, error) {
+	c := &call{req: req, done: make(chan reply, 1)}
+	select {
+	case l.calls <- c:
+	case <-l.done:
+		return nil, l.err
+	case <-ctx.Done():
+		return nil, contextError(ctx)
+	}
+	select {
+	case r := <-c.done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return &r.resp, nil
+	case <-ctx.Done():
+		// The server offers no way to cancel a request. The messenger
+		// drops the response when it arrives.
+		go func() {
+			select {
+			case l.forget <- c:
+			case <-l.done:
+			}
+		}()
+		return nil, contextError(ctx)
 	}
 }
 
-func (cli *Client) getColumns(db, table string) (map[string]string, error) {
+// responseError is an error the server returned in response to a request.
+// Unlike other messenger errors, it leaves the connection usable.
+type responseError struct {
+	err error
+}
+
+func (e *responseError) Error() string {
+	return e.err.Error()
+}
+
+func (cli *Client) getColumns(ctx context.Context, db, table string) (map[string]string, error) {
+	cli.cacheMux.Lock()
 	if _, dbExists := cli.References[db]; dbExists {
 		if _, tblExists := cli.References[db][table]; tblExists {
+			defer cli.cacheMux.Unlock()
 			return cli.References[db][table], nil
 		}
 	}
-	schema, err := cli.GetSchema(db)
+	cli.cacheMux.Unlock()
+	schema, err := cli.GetSchemaContext(ctx, db)
 	if err != nil {
 		return make(map[string]string), err
 	}
-	cli.References[db] = make(map[string]map[string]string)
 	columns, err := schema.GetColumnsTypes(table)
 	if err != nil {
 		return columns, err
 	}
+	cli.cacheMux.Lock()
+	defer cli.cacheMux.Unlock()
+	if _, dbExists := cli.References[db]; !dbExists {
+		cli.References[db] = make(map[string]map[string]string)
+	}
 	cli.References[db][table] = columns
 	return columns, nil
 }
@@ -151,18 +1048,60 @@ type ovsdbCodec struct {
 	// and then look it up by request ID when filling out the rpc Response.
 	mutex   sync.Mutex        // protects pending
 	pending map[uint64]string // map request id to method name
+
+	// The reader answers the echo requests of the server while the
+	// messenger writes requests.
+	wmutex sync.Mutex // serializes writes
+
+	limiter *sizeLimiter // limits the size of messages, if set
 }
 
-// newClientCodec returns a new rpc.ClientCodec using JSON-RPC on conn.
-func newClientCodec(conn io.ReadWriteCloser) rpc.ClientCodec {
+// newClientCodec returns a new rpc.ClientCodec using JSON-RPC on conn. The
+// messages are read through a buffer of bufSize bytes, when set, and the
+// messages larger than maxSize bytes, when set, fail with
+// ErrResponseTooLarge.
+func newClientCodec(conn io.ReadWriteCloser, bufSize, maxSize int) *ovsdbCodec {
+	var r io.Reader = conn
+	if bufSize > 0 {
+		r = bufio.NewReaderSize(conn, bufSize)
+	}
+	var limiter *sizeLimiter
+	if maxSize > 0 {
+		limiter = &sizeLimiter{r: r, max: int64(maxSize)}
+		r = limiter
+	}
 	return &ovsdbCodec{
-		dec:     json.NewDecoder(conn),
+		dec:     json.NewDecoder(r),
 		enc:     newOvsdbEncoder(conn),
 		c:       conn,
 		pending: make(map[uint64]string),
+		limiter: limiter,
 	}
 }
 
+// sizeLimiter limits the size of the message being decoded. The decoder
+// reads ahead, so rather than counting the bytes of every message, it
+// allows reading up to the maximum size past the start of the message.
+type sizeLimiter struct {
+	r     io.Reader
+	max   int64
+	read  int64 // the number of bytes read so far
+	start int64 // the offset of the message being decoded
+}
+
+func (l *sizeLimiter) Read(p []byte) (int, error) {
+	remaining := l.start + l.max - l.read
+	if remaining <= 0 {
+		return 0, fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, l.max)
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	return n, err
+}
+
 type clientRequest struct {
 	Method string `json:"method"`
 	ID     uint64 `json:"id"`
@@ -171,10 +1110,12 @@ type clientRequest struct {
 }
 
 func (c *ovsdbCodec) WriteRequest(r *rpc.Request, param interface{}) error {
+	c.wmutex.Lock()
+	defer c.wmutex.Unlock()
 	c.req.Method = r.ServiceMethod
 	c.req.ID = r.Seq
 	c.req.Params[0] = param
-	if r.ServiceMethod != "echo" && r.Seq != 0 {
+	if r.Seq != 0 {
 		c.mutex.Lock()
 		c.pending[r.Seq] = r.ServiceMethod
 		c.mutex.Unlock()
@@ -187,9 +1128,15 @@ type clientResponse struct {
 	ID     interface{}      `json:"id"`
 	Result *json.RawMessage `json:"result"`
 	Error  interface{}      `json:"error"`
+	// Method and Params are set when the message is a request, or a
+	// notification, of the server.
+	Method string           `json:"method"`
+	Params *json.RawMessage `json:"params"`
 }
 
 func (r *clientResponse) reset() {
+	r.Method = ""
+	r.Params = nil
 	r.ID = 0
 	r.Result = nil
 	r.Error = nil
@@ -197,14 +1144,23 @@ func (r *clientResponse) reset() {
 
 func (c *ovsdbCodec) ReadResponseHeader(r *rpc.Response) error {
 	c.resp.reset()
+	if c.limiter != nil {
+		c.limiter.start = c.dec.InputOffset()
+	}
 	if err := c.dec.Decode(&c.resp); err != nil {
-		if err.Error() == "EOF" {
-			return nil
+		if err == io.EOF {
+			return err
 		}
-		return fmt.Errorf("codec decode: %v", err)
+		return fmt.Errorf("codec decode: %w", err)
 	}
 	//spew.Dump(c.resp)
 
+	if c.resp.Method != "" {
+		// The requests of the server have no sequence number.
+		r.ServiceMethod = c.resp.Method
+		r.Seq = 0
+		return nil
+	}
 	if reflect.ValueOf(c.resp.ID).Kind() == reflect.Float64 {
 		c.mutex.Lock()
 		r.Seq = uint64(c.resp.ID.(float64))
@@ -213,25 +1169,33 @@ func (c *ovsdbCodec) ReadResponseHeader(r *rpc.Response) error {
 		c.mutex.Unlock()
 	} else {
 		//spew.Dump(c)
-		r.ServiceMethod = "echo"
+		r.ServiceMethod = ""
 		r.Seq = 0
 		return nil
 	}
 
 	r.Error = ""
 	if c.resp.Error != nil || c.resp.Result == nil {
-		x, ok := c.resp.Error.(string)
-		if !ok {
-			return fmt.Errorf("invalid error %v", c.resp.Error)
-		}
-		if x == "" {
-			x = "unspecified error"
-		}
-		r.Error = x
+		r.Error = newOvsdbError(c.resp.Error).Error()
 	}
 	return nil
 }
 
+// serverError returns the error object of the response read last by
+// ReadResponseHeader.
+func (c *ovsdbCodec) serverError() *OvsdbError {
+	return newOvsdbError(c.resp.Error)
+}
+
+// params returns the parameters of the request, or of the notification,
+// of the server read last by ReadResponseHeader.
+func (c *ovsdbCodec) params() json.RawMessage {
+	if c.resp.Params == nil {
+		return nil
+	}
+	return *c.resp.Params
+}
+
 func (c *ovsdbCodec) ReadResponseBody(x interface{}) error {
 	if x == nil {
 		return nil
@@ -243,78 +1207,174 @@ func (c *ovsdbCodec) Close() error {
 	return c.c.Close()
 }
 
-func ovsdbMessenger(s string, t int, rxQueue <-chan Request, txQueue chan<- Response, errQueue chan<- error) {
-	var counter uint64 = 1
-	var resp rpc.Response
-	var respMsg Response
-	if t == 0 {
-		t = 2
-	}
-	serverProto, serverAddr, err := parseSocket(s)
-	if err != nil {
-		errQueue <- err
-		return
-	}
-	dialer := net.Dialer{
-		Timeout: time.Second * time.Duration(t),
+// message is a message the reader received from the server.
+type message struct {
+	resp rpc.Response
+	body Response
+	// serverErr is the error object of the response, if any.
+	serverErr *OvsdbError
+	err       error
+}
+
+// isNotification returns true for the notifications of the server the
+// client handles: those of the locks, and the updates of the monitors.
+func isNotification(method string) bool {
+	switch method {
+	case "locked", "stolen", "update", "update2", "update3":
+		return true
 	}
-	conn, err := dialer.Dial(serverProto, serverAddr)
-	if err != nil {
-		errQueue <- err
-		return
+	return false
+}
+
+// notify hands a notification of the server over to the locks, or to the
+// monitors.
+func (cli *Client) notify(method string, params json.RawMessage) {
+	switch method {
+	case "locked", "stolen":
+		if cli.locks != nil {
+			cli.locks.notify(method, params)
+		}
+	case "update", "update2", "update3":
+		if cli.monitors != nil {
+			cli.monitors.notify(params)
+		}
 	}
-	errQueue <- nil
-	cli := newClientCodec(conn)
+}
+
+// ovsdbReader reads the messages of the server. It answers the echo
+// requests of the server, hands the notifications of the locks and of the
+// monitors over to notify, and passes the responses to the messenger. It exits after
+// passing an error, or when done is closed.
+func ovsdbReader(codec *ovsdbCodec, logger Logger, lastSeen *atomic.Int64, notify func(string, json.RawMessage), msgs chan<- message, done <-chan struct{}) {
 	for {
-		reqMsg := <-rxQueue
-		var req rpc.Request
-		if reqMsg.Method == "shutdown" {
-			cli.Close()
-			errQueue <- nil
-			return
+		var msg message
+		if err := codec.ReadResponseHeader(&msg.resp); err != nil {
+			msg.err = err
+		} else {
+			lastSeen.Store(time.Now().UnixNano())
+			if msg.resp.Seq == 0 {
+				if notify != nil && isNotification(msg.resp.ServiceMethod) {
+					notify(msg.resp.ServiceMethod, codec.params())
+					continue
+				}
+				if msg.resp.ServiceMethod != "echo" {
+					// Neither notifications, nor responses with
+					// ids the client did not send are expected.
+					logger.Debugf("ignoring an unexpected message of the server: %q", msg.resp.ServiceMethod)
+					continue
+				}
+				// handling server echo
+				req := rpc.Request{ServiceMethod: "echo"}
+				if err := codec.WriteRequest(&req, nil); err != nil {
+					msg.err = err
+				} else {
+					continue
+				}
+			} else if msg.resp.Error == "" {
+				if err := codec.ReadResponseBody(&msg.body); err != nil {
+					msg.err = fmt.Errorf("decode body error: %v", err)
+				}
+			} else {
+				msg.serverErr = codec.serverError()
+			}
 		}
-		req.ServiceMethod = reqMsg.Method
-		req.Seq = counter
-		if err := cli.WriteRequest(&req, reqMsg.Params); err != nil {
-			errQueue <- err
+		select {
+		case msgs <- msg:
+		case <-done:
 			return
 		}
-		if err := cli.ReadResponseHeader(&resp); err != nil {
-			errQueue <- err
+		if msg.err != nil {
 			return
 		}
-		if resp.ServiceMethod == "echo" && resp.Seq == 0 {
-			// handling server echo
-			var req rpc.Request
-			req.ServiceMethod = resp.ServiceMethod
-			req.Seq = resp.Seq
-			if err := cli.WriteRequest(&req, nil); err != nil {
-				errQueue <- err
+	}
+}
+
+// ovsdbMessenger sends the requests of the link and delivers the responses
+// to them. When it exits, the requests still pending fail with the reason.
+func ovsdbMessenger(cli *ovsdbCodec, l *link, lastSeen *atomic.Int64, keepalive time.Duration) {
+	var counter uint64 = 1
+	pending := make(map[uint64]*call)
+	msgs := make(chan message)
+	done := make(chan struct{})
+	var err error
+	defer func() {
+		cli.Close()
+		close(done)
+		l.err = err
+		close(l.done)
+		for _, c := range pending {
+			c.done <- reply{err: err}
+		}
+	}()
+	go ovsdbReader(cli, l.log, lastSeen, l.notify, msgs, done)
+
+	var ticks <-chan time.Time
+	if keepalive > 0 {
+		ticker := time.NewTicker(keepalive)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	connected := time.Now()
+	// probe is the time the pending inactivity probe was sent.
+	var probe time.Time
+	for {
+		select {
+		case c := <-l.calls:
+			c.seq = counter
+			counter++
+			pending[c.seq] = c
+			req := rpc.Request{ServiceMethod: c.req.Method, Seq: c.seq}
+			if err = cli.WriteRequest(&req, c.req.Params); err != nil {
 				return
 			}
-			if err := cli.ReadResponseHeader(&resp); err != nil {
-				errQueue <- err
+		case c := <-l.forget:
+			delete(pending, c.seq)
+		case <-l.quit:
+			err = fmt.Errorf("connection closed")
+			return
+		case msg := <-msgs:
+			if msg.err != nil {
+				err = msg.err
 				return
 			}
-		} else {
-			if resp.Seq != counter {
-				errQueue <- fmt.Errorf("sequence mismatch: %v (request) vs. %v (response)", counter, resp.Seq)
+			c, ok := pending[msg.resp.Seq]
+			if !ok {
+				// a response to an inactivity probe, or to an
+				// abandoned request
+				continue
+			}
+			delete(pending, msg.resp.Seq)
+			switch {
+			case msg.resp.Error != "":
+				c.done <- reply{err: &responseError{fmt.Errorf("error in response header: %w", msg.serverErr)}}
+			case msg.body.Error.Message != "":
+				c.done <- reply{err: &responseError{fmt.Errorf("error in response body: %w", (*OvsdbError)(&msg.body.Error))}}
+			default:
+				c.done <- reply{resp: msg.body}
+			}
+		case now := <-ticks:
+			seen := connected
+			if t := time.Unix(0, lastSeen.Load()); t.After(seen) {
+				seen = t
+			}
+			if !probe.IsZero() && seen.Before(probe) {
+				if now.Sub(probe) >= keepalive {
+					err = fmt.Errorf("inactivity probe: no response from the server for %s", now.Sub(seen).Round(time.Millisecond))
+					return
+				}
+				continue
+			}
+			probe = time.Time{}
+			if now.Sub(seen) < keepalive {
+				continue
+			}
+			js, _ := encodeString("keepalive")
+			req := rpc.Request{ServiceMethod: "echo", Seq: counter}
+			if err = cli.WriteRequest(&req, js); err != nil {
 				return
 			}
 			counter++
+			probe = now
 		}
-		if resp.Error != "" {
-			errQueue <- fmt.Errorf("error in response header: %s", resp.Error)
-			return
-		}
-		if err := cli.ReadResponseBody(&respMsg); err != nil {
-			errQueue <- fmt.Errorf("decode body error: %v", err)
-			return
-		}
-		if respMsg.Error.Message != "" {
-			errQueue <- fmt.Errorf("error in response body: %s", respMsg.Error.String())
-			return
-		}
-		txQueue <- respMsg
 	}
 }
@@ -15,7 +15,16 @@
 package ovsdb
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -56,3 +65,504 @@ func TestNewClient(t *testing.T) {
 		t.Fatalf("Failed %d tests", testFailed)
 	}
 }
+
+// newSilentServer starts a unix socket server which accepts connections,
+// but never responds to requests.
+func newSilentServer(t *testing.T) string {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	conns := make(chan net.Conn, 16)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+	t.Cleanup(func() {
+		l.Close()
+		close(conns)
+		for conn := range conns {
+			conn.Close()
+		}
+	})
+	return "unix:" + sock
+}
+
+func TestClientQueryContext(t *testing.T) {
+	sock := newSilentServer(t)
+	cli, err := NewClient(sock, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to %s, but failed with: %v", sock, err)
+	}
+	defer cli.Close()
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		_, err = cli.TransactContext(ctx, "Open_vSwitch", "SELECT * FROM Open_vSwitch")
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("FAIL: attempt %d: expected deadline exceeded error, but got: %v", i, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("FAIL: attempt %d: the query was not abandoned in time: %v", i, elapsed)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cli.EchoContext(ctx, "test message"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("FAIL: expected cancelled error, but got: %v", err)
+	}
+	t.Logf("PASS: queries honor context deadlines and cancellation")
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	sock := newSilentServer(t)
+	cli, err := NewClient(sock, 1, WithRequestTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to %s, but failed with: %v", sock, err)
+	}
+	defer cli.Close()
+	for i, test := range []struct {
+		name string
+		call func() error
+	}{
+		{name: "default", call: func() error { return cli.Echo("test message") }},
+		{name: "echo", call: func() error { return cli.EchoWithTimeout("test message", 10*time.Millisecond) }},
+		{name: "schema", call: func() error {
+			_, err := cli.GetSchemaWithTimeout("Open_vSwitch", 10*time.Millisecond)
+			return err
+		}},
+		{name: "transact", call: func() error {
+			_, err := cli.TransactWithTimeout("Open_vSwitch", "SELECT * FROM Open_vSwitch", 10*time.Millisecond)
+			return err
+		}},
+	} {
+		start := time.Now()
+		err := test.call()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("FAIL: Test %d: %s: expected deadline exceeded error, but got: %v", i, test.name, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("FAIL: Test %d: %s: the request was not abandoned in time: %v", i, test.name, elapsed)
+		}
+		t.Logf("PASS: Test %d: %s: request timed out after %v", i, test.name, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// serveTestConn answers the requests received over the connection. The
+// echo method returns its parameters. The other methods are answered with
+// the responses provided by the handler, or the error objects wrapped in
+// testServerError.
+func serveTestConn(conn net.Conn, handler func(method string, params json.RawMessage) interface{}) {
+	defer conn.Close()
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			ID     interface{}     `json:"id"`
+		}
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+		var result interface{} = req.Params
+		if req.Method != "echo" && handler != nil {
+			result = handler(req.Method, req.Params)
+		}
+		n, notify := result.(testServerNotify)
+		if notify {
+			if !n.after {
+				if err := enc.Encode(map[string]interface{}{"id": nil, "method": n.method, "params": n.params}); err != nil {
+					return
+				}
+			}
+			result = n.result
+		}
+		resp := map[string]interface{}{"id": req.ID, "result": result, "error": nil}
+		if e, ok := result.(testServerError); ok {
+			resp["result"] = nil
+			resp["error"] = e.err
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+		if notify && n.after {
+			if err := enc.Encode(map[string]interface{}{"id": nil, "method": n.method, "params": n.params}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// testServerError makes serveTestConn answer with the error object.
+type testServerError struct {
+	err interface{}
+}
+
+// testServerNotify makes serveTestConn send the notification before the
+// result, or right after it.
+type testServerNotify struct {
+	method string
+	params interface{}
+	result interface{}
+	after  bool
+}
+
+// newTestServer serves OVSDB requests received by the listener.
+func newTestServer(t *testing.T, l net.Listener, handler func(method string, params json.RawMessage) interface{}) {
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestConn(conn, handler)
+		}
+	}()
+	t.Cleanup(func() {
+		l.Close()
+	})
+}
+
+func TestParseSocket(t *testing.T) {
+	for i, test := range []struct {
+		socket     string
+		proto      string
+		addr       string
+		shouldFail bool
+	}{
+		{socket: "unix:/var/run/openvswitch/db.sock", proto: "unix", addr: "/var/run/openvswitch/db.sock"},
+		{socket: "tcp:127.0.0.1:6640", proto: "tcp", addr: "127.0.0.1:6640"},
+		{socket: "tcp:[::1]:6641", proto: "tcp", addr: "[::1]:6641"},
+		{socket: "ssl:ovn-central.example.com:6642", proto: "ssl", addr: "ovn-central.example.com:6642"},
+		{socket: "127.0.0.1:6640", proto: "tcp", addr: "127.0.0.1:6640"},
+		{socket: "ssl:10.0.0.1", proto: "ssl", addr: "10.0.0.1:6640"},
+		{socket: "ptcp:6640", shouldFail: true},
+	} {
+		proto, addr, err := parseSocket(test.socket)
+		if err != nil {
+			if !test.shouldFail {
+				t.Fatalf("FAIL: Test %d: socket '%s', expected to pass, but failed with: %v", i, test.socket, err)
+			}
+			continue
+		}
+		if test.shouldFail {
+			t.Fatalf("FAIL: Test %d: socket '%s', expected to fail, but passed", i, test.socket)
+		}
+		if proto != test.proto || addr != test.addr {
+			t.Fatalf("FAIL: Test %d: socket '%s', expected %s %s, but got %s %s", i, test.socket, test.proto, test.addr, proto, addr)
+		}
+	}
+}
+
+func TestClientTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, nil)
+	sock := "tcp:" + l.Addr().String()
+	cli, err := NewClient(sock, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to %s, but failed with: %v", sock, err)
+	}
+	defer cli.Close()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	t.Logf("PASS: 'echo' method over %s completed successfully", sock)
+}
+
+func TestClientDialer(t *testing.T) {
+	var mu sync.Mutex
+	dialed := []string{}
+	dial := func(ctx context.Context, network, address string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, network+" "+address)
+		mu.Unlock()
+		client, server := net.Pipe()
+		go serveTestConn(server, nil)
+		return client, nil
+	}
+	cli, err := NewClient("tcp:vm-3:6640", 1, WithDialer(dial))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect with the dialer, but failed with: %v", err)
+	}
+	defer cli.Close()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	mu.Lock()
+	if len(dialed) != 1 || dialed[0] != "tcp vm-3:6640" {
+		t.Fatalf("FAIL: unexpected dials: %v", dialed)
+	}
+	mu.Unlock()
+
+	failing := func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, fmt.Errorf("no route to %s", address)
+	}
+	if _, err := NewClient("unix:/var/run/openvswitch/db.sock", 1, WithDialer(failing)); err == nil || !strings.Contains(err.Error(), "no route") {
+		t.Fatalf("FAIL: expected the error of the dialer, but got: %v", err)
+	}
+	if _, err := NewClient("ssl:vm-3:6640", 1, WithDialer(dial)); err == nil {
+		t.Fatalf("FAIL: expected to fail connecting to an ssl remote without TLS configuration")
+	}
+	t.Logf("PASS: client connected with the dialer")
+}
+
+func TestClientAnswersServerEcho(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	defer l.Close()
+	replies := make(chan map[string]interface{}, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// The server probes the idle client.
+		if _, err := conn.Write([]byte(`{"id":"echo","method":"echo","params":[]}`)); err != nil {
+			return
+		}
+		var reply map[string]interface{}
+		if err := json.NewDecoder(conn).Decode(&reply); err != nil {
+			return
+		}
+		replies <- reply
+	}()
+	cli, err := NewClient("unix:"+sock, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	select {
+	case reply := <-replies:
+		if reply["id"] != "echo" || reply["error"] != nil || reply["method"] != nil {
+			t.Fatalf("FAIL: unexpected reply to server echo: %v", reply)
+		}
+		t.Logf("PASS: client answered server echo: %v", reply)
+	case <-time.After(2 * time.Second):
+		t.Fatalf("FAIL: client did not answer server echo")
+	}
+	if cli.LastSeen().IsZero() {
+		t.Fatalf("FAIL: expected LastSeen to be set after the server echo")
+	}
+}
+
+func TestClientKeepalive(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	newTestServer(t, l, nil)
+	cli, err := NewClient("unix:"+sock, 1, WithKeepalive(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	if !cli.LastSeen().IsZero() {
+		t.Fatalf("FAIL: expected LastSeen to be zero before any message")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if time.Since(cli.LastSeen()) > 50*time.Millisecond {
+		t.Fatalf("FAIL: expected the probes to keep LastSeen recent, but it is %v", cli.LastSeen())
+	}
+	cli.MaxRetries = 0
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: expected the connection to stay up, but echo failed with: %v", err)
+	}
+	t.Logf("PASS: inactivity probes kept the connection up")
+
+	// A server which does not answer the probes is dropped.
+	silent := newSilentServer(t)
+	cli2, err := NewClient(silent, 1, WithKeepalive(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli2.Close()
+	time.Sleep(100 * time.Millisecond)
+	cli2.MaxRetries = 0
+	err = cli2.Echo("test message")
+	if err == nil || !strings.Contains(err.Error(), "inactivity probe") {
+		t.Fatalf("FAIL: expected the inactivity probe to drop the connection, but got: %v", err)
+	}
+	t.Logf("PASS: connection to an unresponsive server dropped: %v", err)
+}
+
+// newReorderingServer starts a server which collects batches of echo
+// requests and answers each batch in reverse order. It never answers the
+// echo requests of the "ignore" message.
+func newReorderingServer(t *testing.T, batch int) string {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				dec := json.NewDecoder(conn)
+				enc := json.NewEncoder(conn)
+				for {
+					reqs := []map[string]interface{}{}
+					for len(reqs) < batch {
+						var req map[string]interface{}
+						if err := dec.Decode(&req); err != nil {
+							return
+						}
+						if params, ok := req["params"].([]interface{}); ok && len(params) > 0 && params[0] == "ignore" {
+							continue
+						}
+						reqs = append(reqs, req)
+					}
+					for i := len(reqs) - 1; i >= 0; i-- {
+						resp := map[string]interface{}{"id": reqs[i]["id"], "result": reqs[i]["params"], "error": nil}
+						if err := enc.Encode(resp); err != nil {
+							return
+						}
+					}
+				}
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		l.Close()
+	})
+	return "unix:" + sock
+}
+
+func TestClientMultiplexing(t *testing.T) {
+	batch := 8
+	sock := newReorderingServer(t, batch)
+	cli, err := NewClient(sock, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to %s, but failed with: %v", sock, err)
+	}
+	defer cli.Close()
+
+	errs := make(chan error, batch)
+	for i := 0; i < batch; i++ {
+		go func(i int) {
+			errs <- cli.EchoWithTimeout(fmt.Sprintf("message %d", i), 5*time.Second)
+		}(i)
+	}
+	for i := 0; i < batch; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("FAIL: expected the responses to match the requests, but got: %v", err)
+		}
+	}
+	t.Logf("PASS: %d concurrent requests matched to the responses received in reverse order", batch)
+
+	// An abandoned request does not hold up the others.
+	if err := cli.EchoWithTimeout("ignore", 20*time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("FAIL: expected the unanswered request to time out, but got: %v", err)
+	}
+	for i := 0; i < batch; i++ {
+		go func(i int) {
+			errs <- cli.EchoWithTimeout(fmt.Sprintf("message %d", i), 5*time.Second)
+		}(i)
+	}
+	for i := 0; i < batch; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("FAIL: expected the connection to stay usable, but got: %v", err)
+		}
+	}
+	t.Logf("PASS: the connection stayed usable after a request was abandoned")
+}
+
+func TestClientShutdown(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	delay := make(chan time.Duration, 1)
+	delay <- 50 * time.Millisecond
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		d := <-delay
+		delay <- d
+		time.Sleep(d)
+		return []string{"Open_vSwitch"}
+	})
+	inflight := func(cli *Client) int {
+		cli.closeMux.Lock()
+		defer cli.closeMux.Unlock()
+		return cli.inflight
+	}
+
+	cli, err := NewClient("unix:"+sock, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := cli.Databases()
+			errs <- err
+		}()
+	}
+	for inflight(&cli) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := cli.Shutdown(ctx); err != nil {
+		t.Fatalf("FAIL: expected the requests in flight to drain, but got: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("FAIL: expected the request in flight to complete, but got: %v", err)
+		}
+	}
+	t.Logf("PASS: shutdown waited for 3 requests in flight")
+	if err := cli.Echo("test message"); !errors.Is(err, ErrClosing) {
+		t.Fatalf("FAIL: expected a new request to be rejected, but got: %v", err)
+	}
+	_, err = cli.Transact("Open_vSwitch", "SELECT * FROM Open_vSwitch")
+	if !errors.Is(err, ErrClosing) {
+		t.Fatalf("FAIL: expected a new transaction to be rejected, but got: %v", err)
+	}
+	t.Logf("PASS: requests after shutdown rejected with: %v", err)
+
+	// The requests still in flight at the deadline fail.
+	<-delay
+	delay <- time.Second
+	cli2, err := NewClient("unix:"+sock, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	go func() {
+		_, err := cli2.Databases()
+		errs <- err
+	}()
+	for inflight(&cli2) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := cli2.Shutdown(ctx); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("FAIL: expected shutdown to time out, but got: %v", err)
+	}
+	if err := <-errs; err == nil {
+		t.Fatalf("FAIL: expected the request in flight to fail")
+	}
+	t.Logf("PASS: shutdown closed the client at the deadline")
+}
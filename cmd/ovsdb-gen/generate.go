@@ -0,0 +1,291 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/supergate-hub/ovsdb"
+)
+
+// initialisms are the words of the names of the tables and columns which
+// are capitalized as a whole in the names of Go identifiers.
+var initialisms = map[string]bool{
+	"acl": true, "arp": true, "bfd": true, "cfm": true, "dhcp": true,
+	"dns": true, "id": true, "ids": true, "igmp": true, "ip": true,
+	"ipfix": true, "ipsec": true, "lacp": true, "lb": true, "mac": true,
+	"mtu": true, "nat": true, "nb": true, "qos": true, "sb": true,
+	"ssl": true, "stp": true, "tcp": true, "udp": true, "url": true,
+	"uuid": true, "vlan": true, "vtep": true,
+}
+
+// goName returns the exported Go identifier of a table or column, e.g.
+// ExternalIDs for external_ids.
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		if initialisms[strings.ToLower(word)] {
+			if strings.ToLower(word) == "ids" {
+				b.WriteString("IDs")
+				continue
+			}
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	s := b.String()
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		s = "X" + s
+	}
+	return s
+}
+
+// atomicTypes are the Go types of the atomic types of OVSDB.
+var atomicTypes = map[string]string{
+	"integer": "int",
+	"real":    "float64",
+	"boolean": "bool",
+	"string":  "string",
+	"uuid":    "ovsdb.UUID",
+}
+
+// types names the Go types of the enums and the references of a schema.
+type types struct {
+	// enums are the types of the string columns whose values are
+	// enumerated, e.g. InterfaceAdminState, by table and column.
+	enums map[string]map[string]string
+	// refs are the reference types of the tables referred to, e.g.
+	// PortUUID, by table.
+	refs map[string]string
+}
+
+// newTypes names the types of the enums and of the references of the
+// schema. The tables referred to which are missing from the schema are
+// referred to by ovsdb.UUID.
+func newTypes(schema ovsdb.Schema) types {
+	ts := types{enums: map[string]map[string]string{}, refs: map[string]string{}}
+	for table, t := range schema.Tables {
+		for column, c := range t.Columns {
+			if !c.IsMap() && c.Key.Type == "string" && len(c.Key.Enum) > 0 {
+				if ts.enums[table] == nil {
+					ts.enums[table] = map[string]string{}
+				}
+				ts.enums[table][column] = goName(table) + goName(column)
+			}
+			for _, bt := range []*ovsdb.BaseType{&c.Key, c.Value} {
+				if bt == nil || bt.RefTable == "" {
+					continue
+				}
+				if _, exists := schema.Tables[bt.RefTable]; exists {
+					ts.refs[bt.RefTable] = goName(bt.RefTable) + "UUID"
+				}
+			}
+		}
+	}
+	return ts
+}
+
+// atomType returns the Go type of the atoms of a column: the reference
+// type of the table referred to, the enum type of the column, when enum is
+// set, or the atomic type.
+func (ts types) atomType(table, column string, bt *ovsdb.BaseType, enum bool) (string, bool) {
+	if ref, exists := ts.refs[bt.RefTable]; exists {
+		return ref, true
+	}
+	if name, exists := ts.enums[table][column]; exists && enum {
+		return name, true
+	}
+	typ, ok := atomicTypes[bt.Type]
+	return typ, ok
+}
+
+// columnType returns the Go type of a column: the atomic type of the
+// scalars, Optional for at most one element, a slice for the sets and a
+// map for the maps.
+func (ts types) columnType(table, column string, c ovsdb.Column) (string, error) {
+	key, ok := ts.atomType(table, column, &c.Key, !c.IsMap())
+	if !ok {
+		return "", fmt.Errorf("unsupported type %v", c.Type)
+	}
+	switch {
+	case c.IsMap():
+		value, ok := ts.atomType(table, column, c.Value, false)
+		if !ok {
+			return "", fmt.Errorf("unsupported type %v", c.Type)
+		}
+		return fmt.Sprintf("map[%s]%s", key, value), nil
+	case c.IsOptional():
+		return fmt.Sprintf("ovsdb.Optional[%s]", key), nil
+	case c.IsSet():
+		return "[]" + key, nil
+	}
+	return key, nil
+}
+
+type field struct {
+	Name   string
+	Type   string
+	Column string
+}
+
+// fields returns the fields of the struct of a table, sorted by column,
+// preceded by those of the _uuid and _version columns.
+func (ts types) fields(table string, t ovsdb.Table) ([]field, error) {
+	uuid := "ovsdb.UUID"
+	if ref, exists := ts.refs[table]; exists {
+		uuid = ref
+	}
+	fs := []field{
+		{"UUID", uuid, "_uuid"},
+		{"Version", "ovsdb.UUID", "_version"},
+	}
+	names := map[string]bool{"UUID": true, "Version": true}
+	columns := []string{}
+	for column := range t.Columns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		typ, err := ts.columnType(table, column, t.Columns[column])
+		if err != nil {
+			return nil, fmt.Errorf("table %s, column %s: %s", table, column, err)
+		}
+		name := goName(column)
+		for names[name] {
+			name += "_"
+		}
+		names[name] = true
+		fs = append(fs, field{name, typ, column})
+	}
+	return fs, nil
+}
+
+// writeEnum writes the type of the enum of a column, and the constants of
+// its values, e.g. InterfaceAdminStateUp.
+func writeEnum(b *bytes.Buffer, name, table, column string, values []interface{}) {
+	fmt.Fprintf(b, "// %s is a value of the %s column of the %s table.\n", name, column, table)
+	fmt.Fprintf(b, "type %s string\n\n", name)
+	fmt.Fprintf(b, "// The values of the %s column of the %s table.\n", column, table)
+	b.WriteString("const (\n")
+	names := map[string]bool{}
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		constant := name + goName(s)
+		for names[constant] {
+			constant += "_"
+		}
+		names[constant] = true
+		fmt.Fprintf(b, "\t%s %s = %q\n", constant, name, s)
+	}
+	b.WriteString(")\n\n")
+}
+
+// writeRef writes the reference type of a table. The references which are
+// not valid UUIDs are encoded as the names of the rows inserted by the
+// transaction, see ovsdb.NamedUUID.
+func writeRef(b *bytes.Buffer, name, table string) {
+	fmt.Fprintf(b, "// %s is a reference to a row of the %s table.\n", name, table)
+	fmt.Fprintf(b, "type %s ovsdb.UUID\n\n", name)
+	b.WriteString("// MarshalJSON encodes the reference in the OVSDB notation.\n")
+	fmt.Fprintf(b, "func (u %s) MarshalJSON() ([]byte, error) {\n", name)
+	b.WriteString("\tif !ovsdb.UUID(u).Valid() {\n\t\treturn ovsdb.NamedUUID(u).MarshalJSON()\n\t}\n")
+	b.WriteString("\treturn ovsdb.UUID(u).MarshalJSON()\n}\n\n")
+	b.WriteString("// UnmarshalJSON decodes the reference from its OVSDB notation.\n")
+	fmt.Fprintf(b, "func (u *%s) UnmarshalJSON(b []byte) error {\n", name)
+	b.WriteString("\treturn (*ovsdb.UUID)(u).UnmarshalJSON(b)\n}\n\n")
+}
+
+// generate returns the formatted source of the bindings of the schema.
+func generate(schema ovsdb.Schema, pkg string) ([]byte, error) {
+	if schema.Name == "" {
+		return nil, fmt.Errorf("the schema has no name")
+	}
+	if pkg == "" {
+		pkg = strings.ToLower(strings.ReplaceAll(schema.Name, "_", ""))
+	}
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "// Code generated by ovsdb-gen from the %s schema, version %s. DO NOT EDIT.\n\n", schema.Name, schema.Version)
+	fmt.Fprintf(b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\t\"context\"\n\n\t\"github.com/supergate-hub/ovsdb\"\n)\n\n")
+	b.WriteString("const (\n")
+	b.WriteString("\t// DatabaseName is the name of the database of the schema.\n")
+	fmt.Fprintf(b, "\tDatabaseName = %q\n", schema.Name)
+	b.WriteString("\t// SchemaVersion is the version of the schema the bindings were generated from.\n")
+	fmt.Fprintf(b, "\tSchemaVersion = %q\n", schema.Version)
+	b.WriteString(")\n")
+
+	ts := newTypes(schema)
+	tables := schema.GetTables()
+	for _, table := range tables {
+		fs, err := ts.fields(table, schema.Tables[table])
+		if err != nil {
+			return nil, err
+		}
+		name := goName(table)
+		b.WriteString("\n")
+		if ref, exists := ts.refs[table]; exists {
+			writeRef(b, ref, table)
+		}
+		for _, column := range schema.GetColumns(table) {
+			if enum, exists := ts.enums[table][column]; exists {
+				writeEnum(b, enum, table, column, schema.Tables[table].Columns[column].Key.Enum)
+			}
+		}
+		fmt.Fprintf(b, "// %s is a row of the %s table.\n", name, table)
+		fmt.Fprintf(b, "type %s struct {\n", name)
+		for _, f := range fs {
+			fmt.Fprintf(b, "\t%s %s `ovsdb:%q`\n", f.Name, f.Type, f.Column)
+		}
+		b.WriteString("}\n\n")
+
+		fmt.Fprintf(b, "// The %s table and its columns.\n", table)
+		b.WriteString("const (\n")
+		fmt.Fprintf(b, "\t%sTable = %q\n", name, table)
+		for _, f := range fs[2:] {
+			fmt.Fprintf(b, "\t%sColumn%s = %q\n", name, f.Name, f.Column)
+		}
+		b.WriteString(")\n\n")
+
+		fmt.Fprintf(b, "// Select%s returns the rows of the %s table matching the conditions.\n", name, table)
+		fmt.Fprintf(b, "func Select%s(ctx context.Context, c *ovsdb.Client, where ...ovsdb.Condition) ([]%s, error) {\n", name, name)
+		fmt.Fprintf(b, "\tresults, err := c.TransactOperations(ctx, DatabaseName, ovsdb.Select(%sTable, nil, where...))\n", name)
+		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		fmt.Fprintf(b, "\trows := []%s{}\n", name)
+		b.WriteString("\tresult := ovsdb.Result{Rows: results[0].Rows}\n")
+		b.WriteString("\tif err := result.Unmarshal(&rows); err != nil {\n\t\treturn nil, err\n\t}\n")
+		b.WriteString("\treturn rows, nil\n}\n\n")
+
+		fmt.Fprintf(b, "// Insert%s returns the operation inserting the row in the %s table.\n", name, table)
+		fmt.Fprintf(b, "func Insert%s(row *%s) (ovsdb.Operation, error) {\n", name, name)
+		b.WriteString("\tm, err := ovsdb.MarshalRow(row)\n")
+		b.WriteString("\tif err != nil {\n\t\treturn ovsdb.Operation{}, err\n\t}\n")
+		fmt.Fprintf(b, "\treturn ovsdb.Insert(%sTable, m), nil\n}\n", name)
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting the generated source failed: %s", err)
+	}
+	return src, nil
+}
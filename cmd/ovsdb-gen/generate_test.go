@@ -0,0 +1,129 @@
+// Copyright 2020 Paul Greenberg greenpau@outlook.com
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/supergate-hub/ovsdb"
+)
+
+const testSchema = `{
+  "name": "Open_vSwitch",
+  "version": "8.3.0",
+  "tables": {
+    "Bridge": {
+      "columns": {
+        "name": {"type": "string", "mutable": false},
+        "ports": {"type": {"key": {"type": "uuid", "refTable": "Port"}, "min": 0, "max": "unlimited"}},
+        "external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}},
+        "datapath_id": {"type": {"key": "string", "min": 0, "max": 1}, "ephemeral": true},
+        "stp_enable": {"type": "boolean"}
+      },
+      "indexes": [["name"]],
+      "isRoot": true
+    },
+    "Port": {
+      "columns": {
+        "name": {"type": "string"},
+        "tag": {"type": {"key": {"type": "integer", "minInteger": 0, "maxInteger": 4095}, "min": 0, "max": 1}},
+        "trunks": {"type": {"key": {"type": "integer"}, "min": 0, "max": 4096}},
+        "vlan_mode": {"type": {"key": {"type": "string", "enum": ["set", ["access", "trunk"]]}, "min": 0, "max": 1}}
+      }
+    }
+  }
+}`
+
+func TestGenerate(t *testing.T) {
+	var schema ovsdb.Schema
+	if err := json.Unmarshal([]byte(testSchema), &schema); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	src, err := generate(schema, "vswitch")
+	if err != nil {
+		t.Fatalf("FAIL: expected to generate the bindings, but failed with: %v", err)
+	}
+	normalized := strings.Join(strings.Fields(string(src)), " ")
+	for _, expected := range []string{
+		"package vswitch",
+		`DatabaseName = "Open_vSwitch"`,
+		`SchemaVersion = "8.3.0"`,
+		"type Bridge struct {",
+		"UUID ovsdb.UUID `ovsdb:\"_uuid\"`",
+		"Version ovsdb.UUID `ovsdb:\"_version\"`",
+		"DatapathID ovsdb.Optional[string] `ovsdb:\"datapath_id\"`",
+		"ExternalIDs map[string]string `ovsdb:\"external_ids\"`",
+		"Ports []PortUUID `ovsdb:\"ports\"`",
+		"STPEnable bool `ovsdb:\"stp_enable\"`",
+		`BridgeTable = "Bridge"`,
+		`BridgeColumnExternalIDs = "external_ids"`,
+		"func SelectBridge(ctx context.Context, c *ovsdb.Client, where ...ovsdb.Condition) ([]Bridge, error) {",
+		"func InsertBridge(row *Bridge) (ovsdb.Operation, error) {",
+		"Tag ovsdb.Optional[int] `ovsdb:\"tag\"`",
+		"Trunks []int `ovsdb:\"trunks\"`",
+		"VLANMode ovsdb.Optional[PortVLANMode] `ovsdb:\"vlan_mode\"`",
+		"type PortVLANMode string",
+		`PortVLANModeAccess PortVLANMode = "access"`,
+		`PortVLANModeTrunk PortVLANMode = "trunk"`,
+		"type PortUUID ovsdb.UUID",
+		"func (u PortUUID) MarshalJSON() ([]byte, error) {",
+		"func (u *PortUUID) UnmarshalJSON(b []byte) error {",
+		"UUID PortUUID `ovsdb:\"_uuid\"`",
+	} {
+		if !strings.Contains(normalized, expected) {
+			t.Fatalf("FAIL: expected the bindings to contain %q, got:\n%s", expected, src)
+		}
+	}
+	t.Logf("PASS: generated %d bytes of bindings", len(src))
+
+	schema.Tables["Port"].Columns["bad"] = ovsdb.Column{Type: map[string]interface{}{"key": "blob"}}
+	if _, err := generate(schema, ""); err == nil || !strings.Contains(err.Error(), "table Port, column bad") {
+		t.Fatalf("FAIL: expected an error for the unsupported type, got: %v", err)
+	}
+	t.Logf("PASS: unsupported type rejected")
+}
+
+func TestGoName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Logical_Switch_Port": "LogicalSwitchPort",
+		"Open_vSwitch":        "OpenVSwitch",
+		"external_ids":        "ExternalIDs",
+		"mac_in_use":          "MACInUse",
+		"nb_cfg":              "NBCfg",
+		"other_config":        "OtherConfig",
+		"ip":                  "IP",
+		"802_1ad":             "X8021ad",
+	} {
+		if got := goName(name); got != expected {
+			t.Fatalf("FAIL: expected %s for %s, got %s", expected, name, got)
+		}
+	}
+	t.Logf("PASS: Go names")
+}
+
+func TestGenerateBundled(t *testing.T) {
+	for _, name := range ovsdb.BundledSchemas() {
+		schema, err := loadSchema("", name)
+		if err != nil {
+			t.Fatalf("FAIL: expected to load the bundled %s schema, but failed with: %v", name, err)
+		}
+		if _, err := generate(schema, "bindings"); err != nil {
+			t.Fatalf("FAIL: expected to generate the bindings of %s, but failed with: %v", name, err)
+		}
+		t.Logf("PASS: generated the bindings of %s", name)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command ovsdb-gen generates typed bindings for the tables of an OVSDB
+// schema, e.g. vswitch.ovsschema, or the ovn-nb and ovn-sb schemas of OVN.
+// For each table, it emits a struct, whose fields are tagged with the
+// columns for Row.Unmarshal and MarshalRow, the constants of the table and
+// its columns, and the helpers selecting and inserting rows. The values of
+// the enum columns, e.g. Interface.admin_state, have their own types and
+// constants, and so do the references to each table, e.g. PortUUID, so that
+// the invalid values fail to compile. For example:
+//
+//	//go:generate go run github.com/supergate-hub/ovsdb/cmd/ovsdb-gen -schema vswitch.ovsschema -package vswitch -out vswitch.go
+//
+// With -bundled, it generates the bindings of a schema bundled with the
+// library, e.g. -bundled OVN_Northbound, instead of reading a file.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/supergate-hub/ovsdb"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "", "the path to the .ovsschema file")
+	bundled := flag.String("bundled", "", "the name of a bundled schema, instead of -schema")
+	pkg := flag.String("package", "", "the package of the generated file, by default the lowercase schema name")
+	out := flag.String("out", "", "the path to the generated file, by default the standard output")
+	flag.Parse()
+	if (*schemaPath == "") == (*bundled == "") {
+		fmt.Fprintln(os.Stderr, "ovsdb-gen: either -schema or -bundled is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*schemaPath, *bundled, *pkg, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "ovsdb-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, bundled, pkg, out string) error {
+	schema, err := loadSchema(schemaPath, bundled)
+	if err != nil {
+		return err
+	}
+	src, err := generate(schema, pkg)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+// loadSchema reads the schema from a file, or from the bundle when the
+// name of a bundled schema is given.
+func loadSchema(schemaPath, bundled string) (ovsdb.Schema, error) {
+	if bundled != "" {
+		return ovsdb.BundledSchema(bundled)
+	}
+	b, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return ovsdb.Schema{}, err
+	}
+	var schema ovsdb.Schema
+	if err := json.Unmarshal(b, &schema); err != nil {
+		return ovsdb.Schema{}, fmt.Errorf("parsing %s failed: %s", schemaPath, err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SchemaMismatchError is returned by Schema.Compatible when a schema lacks
+// tables or columns of the other, or their types differ.
+type SchemaMismatchError struct {
+	Name string
+	// Missing are the tables, e.g. "Chassis_Private", and the columns,
+	// e.g. "Chassis.other_config", the schema lacks.
+	Missing []string
+	// Mismatched are the columns whose types differ.
+	Mismatched []string
+}
+
+func (e *SchemaMismatchError) Error() string {
+	var msgs []string
+	if len(e.Missing) > 0 {
+		msgs = append(msgs, fmt.Sprintf("missing %s", strings.Join(e.Missing, ", ")))
+	}
+	if len(e.Mismatched) > 0 {
+		msgs = append(msgs, fmt.Sprintf("mismatched types of %s", strings.Join(e.Mismatched, ", ")))
+	}
+	return fmt.Sprintf("the %s schema is incompatible: %s", e.Name, strings.Join(msgs, "; "))
+}
+
+// Compatible checks that the schema provides the tables and columns of the
+// other schema, e.g. the one bindings were generated from, with the same
+// types, so that the rows of the other may be read and written. The
+// constraints, e.g. the enums, of the columns are not compared, and the
+// schema may have more tables and columns. It returns a
+// *SchemaMismatchError describing the differences, or nil.
+func (sc *Schema) Compatible(other Schema) error {
+	e := &SchemaMismatchError{Name: sc.Name}
+	for _, table := range other.GetTables() {
+		t := sc.Table(table)
+		if t == nil {
+			e.Missing = append(e.Missing, table)
+			continue
+		}
+		for _, column := range other.GetColumns(table) {
+			c := t.Column(column)
+			switch {
+			case c == nil:
+				e.Missing = append(e.Missing, table+"."+column)
+			case !c.sameType(other.Table(table).Column(column)):
+				e.Mismatched = append(e.Mismatched, table+"."+column)
+			}
+		}
+	}
+	if len(e.Missing) == 0 && len(e.Mismatched) == 0 {
+		return nil
+	}
+	return e
+}
+
+// sameType returns true when the columns hold the same kind of values,
+// i.e. scalars, optional values, sets or maps, of the same atomic types,
+// referring to the same tables.
+func (c *Column) sameType(other *Column) bool {
+	if c.IsMap() != other.IsMap() || c.IsSet() != other.IsSet() || c.IsOptional() != other.IsOptional() {
+		return false
+	}
+	if c.Key.Type != other.Key.Type || c.Key.RefTable != other.Key.RefTable {
+		return false
+	}
+	if c.IsMap() {
+		return c.Value.Type == other.Value.Type && c.Value.RefTable == other.Value.RefTable
+	}
+	return true
+}
+
+// HasColumns returns true when the schema has the table and, when given,
+// its columns, e.g. HasColumns("Chassis_Private", "nb_cfg").
+func (sc *Schema) HasColumns(table string, columns ...string) bool {
+	t := sc.Table(table)
+	if t == nil {
+		return false
+	}
+	for _, column := range columns {
+		if t.Column(column) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// HasTable returns true when the schema of the database has the table. The
+// schema is retrieved once, and cached by the client.
+func (c *Client) HasTable(db, table string) (bool, error) {
+	return c.HasTableContext(context.Background(), db, table)
+}
+
+// HasTableContext is like HasTable, but honors the context.
+func (c *Client) HasTableContext(ctx context.Context, db, table string) (bool, error) {
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	return schema.HasColumns(table), nil
+}
+
+// HasColumn returns true when the schema of the database has the column of
+// the table, e.g. HasColumn("OVN_Northbound", "Logical_Switch_Port", "up").
+func (c *Client) HasColumn(db, table, column string) (bool, error) {
+	return c.HasColumnContext(context.Background(), db, table, column)
+}
+
+// HasColumnContext is like HasColumn, but honors the context.
+func (c *Client) HasColumnContext(ctx context.Context, db, table, column string) (bool, error) {
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	return schema.HasColumns(table, column), nil
+}
+
+// CheckFeatures tells which features the schema of the database supports.
+// The features are named by the caller, and map to the tables and columns
+// they use, e.g. "Chassis_Private" or "Chassis_Private.nb_cfg". A feature
+// is supported when the schema has all of them, so that callers may skip
+// the queries of the unsupported ones.
+func (c *Client) CheckFeatures(ctx context.Context, db string, features map[string][]string) (map[string]bool, error) {
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	return schema.CheckFeatures(features), nil
+}
+
+// CheckFeatures is like Client.CheckFeatures, for the schema.
+func (sc *Schema) CheckFeatures(features map[string][]string) map[string]bool {
+	supported := make(map[string]bool, len(features))
+	for name, uses := range features {
+		supported[name] = true
+		for _, use := range uses {
+			table, column, _ := strings.Cut(use, ".")
+			var columns []string
+			if column != "" {
+				columns = append(columns, column)
+			}
+			if !sc.HasColumns(table, columns...) {
+				supported[name] = false
+				break
+			}
+		}
+	}
+	return supported
+}
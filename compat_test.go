@@ -0,0 +1,197 @@
+// Copyright 2020 Paul Greenberg greenpau@outlook.com
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testOldSouthboundSchema = `{
+  "name": "OVN_Southbound",
+  "version": "2.1.0",
+  "tables": {
+    "Chassis": {
+      "columns": {
+        "name": {"type": "string"},
+        "encaps": {"type": {"key": {"type": "uuid", "refTable": "Encap"}, "min": 1, "max": "unlimited"}},
+        "nb_cfg": {"type": {"key": "integer"}}
+      }
+    },
+    "Encap": {"columns": {"ip": {"type": "string"}}}
+  }
+}`
+
+const testNewSouthboundSchema = `{
+  "name": "OVN_Southbound",
+  "version": "20.21.0",
+  "tables": {
+    "Chassis": {
+      "columns": {
+        "name": {"type": "string"},
+        "encaps": {"type": {"key": {"type": "uuid", "refTable": "Encap"}, "min": 1, "max": "unlimited"}},
+        "nb_cfg": {"type": {"key": "string"}},
+        "other_config": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+      }
+    },
+    "Chassis_Private": {"columns": {"name": {"type": "string"}, "nb_cfg": {"type": {"key": "integer"}}}},
+    "Encap": {"columns": {"ip": {"type": "string"}}}
+  }
+}`
+
+func TestSchemaCompatible(t *testing.T) {
+	var older, newer Schema
+	if err := json.Unmarshal([]byte(testOldSouthboundSchema), &older); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	if err := json.Unmarshal([]byte(testNewSouthboundSchema), &newer); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	if err := older.Compatible(older); err != nil {
+		t.Fatalf("FAIL: expected a schema to be compatible with itself, got: %v", err)
+	}
+	err := older.Compatible(newer)
+	var mismatch *SchemaMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("FAIL: expected a SchemaMismatchError, got: %v", err)
+	}
+	if len(mismatch.Missing) != 2 || mismatch.Missing[0] != "Chassis.other_config" || mismatch.Missing[1] != "Chassis_Private" {
+		t.Fatalf("FAIL: unexpected missing tables and columns: %v", mismatch.Missing)
+	}
+	if len(mismatch.Mismatched) != 1 || mismatch.Mismatched[0] != "Chassis.nb_cfg" {
+		t.Fatalf("FAIL: unexpected mismatched columns: %v", mismatch.Mismatched)
+	}
+	t.Logf("PASS: %v", err)
+
+	if !newer.HasColumns("Chassis_Private", "nb_cfg") || older.HasColumns("Chassis_Private") || older.HasColumns("Chassis", "name", "other_config") {
+		t.Fatalf("FAIL: unexpected columns")
+	}
+	features := map[string][]string{
+		"private":      {"Chassis_Private", "Chassis_Private.nb_cfg"},
+		"other_config": {"Chassis.other_config"},
+		"encaps":       {"Chassis.encaps", "Encap.ip"},
+	}
+	supported := older.CheckFeatures(features)
+	if supported["private"] || supported["other_config"] || !supported["encaps"] || len(supported) != 3 {
+		t.Fatalf("FAIL: unexpected features: %v", supported)
+	}
+	t.Logf("PASS: features of the older schema: %v", supported)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method == "get_schema" {
+			return json.RawMessage(testNewSouthboundSchema)
+		}
+		return nil
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	supported, err = cli.CheckFeatures(context.Background(), "OVN_Southbound", features)
+	if err != nil || !supported["private"] || !supported["other_config"] || !supported["encaps"] {
+		t.Fatalf("FAIL: unexpected features of the server: %v, %v", supported, err)
+	}
+	t.Logf("PASS: features of the server: %v", supported)
+}
+
+func TestClientHasColumn(t *testing.T) {
+	schema := `{"name": "OVN_Northbound", "version": "5.16.0", "tables": {
+		"Logical_Switch_Port": {"columns": {
+			"name": {"type": "string"},
+			"addresses": {"type": {"key": "string", "min": 0, "max": "unlimited"}},
+			"external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}}},
+		"Port_Binding": {"columns": {
+			"logical_port": {"type": "string"}}}}}`
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var schemaRequests atomic.Int32
+	queries := make(chan string, 4)
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			schemaRequests.Add(1)
+			return json.RawMessage(schema)
+		case "transact":
+			queries <- string(params)
+			if strings.Contains(string(params), "Port_Binding") {
+				return []interface{}{map[string]interface{}{"rows": []interface{}{}}}
+			}
+			row := map[string]interface{}{
+				"_uuid":        []interface{}{"uuid", "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"},
+				"name":         "lsp0",
+				"addresses":    "00:00:00:00:00:01 10.0.0.1",
+				"external_ids": []interface{}{"map", []interface{}{}},
+			}
+			return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}
+		}
+		return nil
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	testcases := []struct {
+		table  string
+		column string
+		want   bool
+	}{
+		{table: "Logical_Switch_Port", want: true},
+		{table: "Logical_Switch_Port", column: "name", want: true},
+		{table: "Logical_Switch_Port", column: "up", want: false},
+		{table: "Chassis_Private", want: false},
+	}
+	for _, test := range testcases {
+		var got bool
+		if test.column == "" {
+			got, err = cli.HasTable("OVN_Northbound", test.table)
+		} else {
+			got, err = cli.HasColumn("OVN_Northbound", test.table, test.column)
+		}
+		if err != nil || got != test.want {
+			t.Fatalf("FAIL: expected %s.%s to be present: %t, got %t, %v", test.table, test.column, test.want, got, err)
+		}
+		t.Logf("PASS: %s.%s present: %t", test.table, test.column, got)
+	}
+	if n := schemaRequests.Load(); n != 1 {
+		t.Fatalf("FAIL: expected the schema to be retrieved once, but it was %d times", n)
+	}
+
+	ovn := NewOvnClient()
+	ovn.Database.Northbound.Client = &cli
+	ovn.Database.Southbound.Client = &cli
+	ovn.PartialResults = true
+	ports, err := ovn.GetLogicalSwitchPorts()
+	if len(ports) != 1 || ports[0].IsSet("up") {
+		t.Fatalf("FAIL: expected a port without the up column, got: %v, %v", ports, err)
+	}
+	if q := <-queries; strings.Contains(q, `"up"`) {
+		t.Fatalf("FAIL: expected the up column not to be selected: %s", q)
+	}
+	t.Logf("PASS: the up column is not selected from the older schema")
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
+	"strconv"
 	"strings"
 )
 
@@ -30,6 +31,69 @@ type Condition struct {
 	Function string
 	Value    string
 	Type     string
+	// Operand, when set, is the typed value the column is compared to,
+	// in place of Value and Type. The slices are encoded as sets, the
+	// maps as maps, and UUID values as references.
+	Operand interface{}
+	// key is the key of the map column the value of a query is matched
+	// with, e.g. iface-id of external_ids:'iface-id'=='vm1'.
+	key   string
+	keyed bool
+}
+
+// Equal returns a condition matching the rows whose column is equal to the
+// value.
+func Equal(column string, value interface{}) Condition {
+	return Condition{Column: column, Function: "==", Operand: value}
+}
+
+// NotEqual returns a condition matching the rows whose column is not equal
+// to the value.
+func NotEqual(column string, value interface{}) Condition {
+	return Condition{Column: column, Function: "!=", Operand: value}
+}
+
+// LessThan returns a condition matching the rows whose integer or real
+// column is less than the value.
+func LessThan(column string, value interface{}) Condition {
+	return Condition{Column: column, Function: "<", Operand: value}
+}
+
+// LessOrEqual returns a condition matching the rows whose integer or real
+// column is less than or equal to the value.
+func LessOrEqual(column string, value interface{}) Condition {
+	return Condition{Column: column, Function: "<=", Operand: value}
+}
+
+// GreaterThan returns a condition matching the rows whose integer or real
+// column is greater than the value.
+func GreaterThan(column string, value interface{}) Condition {
+	return Condition{Column: column, Function: ">", Operand: value}
+}
+
+// GreaterOrEqual returns a condition matching the rows whose integer or
+// real column is greater than or equal to the value.
+func GreaterOrEqual(column string, value interface{}) Condition {
+	return Condition{Column: column, Function: ">=", Operand: value}
+}
+
+// Includes returns a condition matching the rows whose set or map column
+// includes all the elements of the value, e.g. a key-value pair of
+// external_ids.
+func Includes(column string, value interface{}) Condition {
+	return Condition{Column: column, Function: "includes", Operand: value}
+}
+
+// Excludes returns a condition matching the rows whose set or map column
+// includes none of the elements of the value.
+func Excludes(column string, value interface{}) Condition {
+	return Condition{Column: column, Function: "excludes", Operand: value}
+}
+
+// And returns the conjunction of the conditions, i.e. the where clause of
+// an operation matching the rows which satisfy all of them.
+func And(conditions ...Condition) []Condition {
+	return append([]Condition{}, conditions...)
 }
 
 // NewCondition - DOCS-TBD
@@ -38,6 +102,13 @@ func NewCondition(s []string) (Condition, error) {
 	if err := c.Parse(strings.Join(s, "")); err != nil {
 		return c, err
 	}
+	c.inferType()
+	return c, nil
+}
+
+// inferType sets the type of the value of the condition, as written in a
+// query, e.g. "integer" for 10, or "string" for a double-quoted value.
+func (c *Condition) inferType() {
 	if strings.Contains(c.Column, "uuid") {
 		c.Type = "string"
 	}
@@ -49,11 +120,202 @@ func NewCondition(s []string) (Condition, error) {
 		if len(value) == len(c.Value)-2 {
 			c.Value = value
 			c.Type = "string"
+			return
+		}
+	}
+	if c.Type == "" {
+		if _, err := strconv.ParseInt(c.Value, 10, 64); err == nil {
+			c.Type = "integer"
+		} else if _, err := strconv.ParseFloat(c.Value, 64); err == nil {
+			c.Type = "real"
+		}
+	}
+}
+
+// parseCondition returns the condition of the tokens of a WHERE clause.
+// The values may be quoted, with single or double quotes, and the columns
+// with backquotes, so that they may hold spaces, commas, or keywords, e.g.
+// 'a, b', or 'it\'s'. A column followed by a colon and a key, e.g.
+// external_ids:'iface-id'=='vm1', matches the rows whose map column holds
+// the pair, or, with !=, does not.
+func parseCondition(tokens []string) (Condition, error) {
+	start, end := -1, -1
+	for i, tok := range tokens {
+		if !isQuoted(tok) && strings.Trim(tok, "=!<>~") == "" {
+			if start < 0 {
+				start = i
+			}
+			end = i + 1
+			continue
+		}
+		if start >= 0 {
+			break
+		}
+	}
+	colon := -1
+	quoted := false
+	for i, tok := range tokens {
+		if isQuoted(tok) {
+			quoted = true
 		}
+		if tok == ":" && i < start && colon < 0 {
+			colon = i
+		}
+	}
+	if !quoted && colon < 0 {
+		return NewCondition(tokens)
+	}
+	if start <= 0 || end == len(tokens) {
+		return Condition{}, fmt.Errorf("invalid condition: '%s'", strings.Join(tokens, ""))
+	}
+	c := Condition{Function: strings.Join(tokens[start:end], "")}
+	if !isFunction(c.Function) {
+		return Condition{}, fmt.Errorf("invalid condition: '%s'", strings.Join(tokens, ""))
+	}
+	value := tokens[end:]
+	if len(value) > 1 {
+		for _, tok := range value {
+			if isQuoted(tok) {
+				// E.g. the doubled quotes of 'it''s', which SQL has.
+				return Condition{}, fmt.Errorf("invalid condition: '%s', a quoted value must stand alone, with its quotes escaped with a backslash, e.g. 'it\\'s'", strings.Join(tokens, ""))
+			}
+		}
+	}
+	column := tokens[:start]
+	if colon >= 0 {
+		column = tokens[:colon]
 	}
+	name, err := joinTokens(column)
+	if err != nil {
+		return Condition{}, err
+	}
+	c.Column = name
+	if colon < 0 {
+		if len(value) == 1 && isQuoted(value[0]) {
+			v, err := unquoteToken(value[0])
+			if err != nil {
+				return Condition{}, err
+			}
+			c.Value, c.Type = v, "string"
+			return c, nil
+		}
+		c.Value = strings.Join(value, "")
+		c.inferType()
+		return c, nil
+	}
+	key, err := joinTokens(tokens[colon+1 : start])
+	if err != nil {
+		return Condition{}, err
+	}
+	switch c.Function {
+	case "==":
+		c.Function = "includes"
+	case "!=":
+		c.Function = "excludes"
+	default:
+		return Condition{}, fmt.Errorf("invalid condition: '%s', a key of %s requires == or !=", strings.Join(tokens, ""), c.Column)
+	}
+	c.key, c.keyed = key, true
+	if len(value) == 1 && isQuoted(value[0]) {
+		v, err := unquoteToken(value[0])
+		if err != nil {
+			return Condition{}, err
+		}
+		c.Operand = map[string]interface{}{key: v}
+		return c, nil
+	}
+	c.Operand = map[string]interface{}{key: parseAtom(strings.Join(value, ""))}
 	return c, nil
 }
 
+// isFunction reports whether the function is one the queries may use.
+func isFunction(f string) bool {
+	switch f {
+	case "<=", "==", "!=", ">=", "=~", ">", "<":
+		return true
+	}
+	return false
+}
+
+// parseAtom returns the value of an unquoted atom of a query, e.g. an
+// integer, or the string as it is.
+func parseAtom(s string) interface{} {
+	if b, err := strconv.ParseBool(s); err == nil && (s == "true" || s == "false") {
+		return b
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// joinTokens joins the tokens of a column or of a key, unquoting them.
+func joinTokens(tokens []string) (string, error) {
+	if len(tokens) == 1 && isQuoted(tokens[0]) {
+		return unquoteToken(tokens[0])
+	}
+	for _, tok := range tokens {
+		if isQuoted(tok) {
+			return "", fmt.Errorf("invalid identifier: '%s'", strings.Join(tokens, ""))
+		}
+	}
+	if len(tokens) == 0 {
+		return "", fmt.Errorf("invalid condition: missing column")
+	}
+	return strings.Join(tokens, ""), nil
+}
+
+// isQuoted reports whether the token is quoted with single, double, or
+// back quotes.
+func isQuoted(tok string) bool {
+	if len(tok) < 2 {
+		return false
+	}
+	switch tok[0] {
+	case '\'', '"', '`':
+		return tok[len(tok)-1] == tok[0]
+	}
+	return false
+}
+
+// unquoteToken returns the text of a quoted token. The escapes of the
+// single and double-quoted ones are those of Go, e.g. \' and \\.
+func unquoteToken(tok string) (string, error) {
+	if tok[0] != '\'' {
+		s, err := strconv.Unquote(tok)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted text: %s", tok)
+		}
+		return s, nil
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	inner := tok[1 : len(tok)-1]
+	for i := 0; i < len(inner); i++ {
+		switch {
+		case inner[i] == '\\' && i+1 < len(inner) && inner[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case inner[i] == '\\' && i+1 < len(inner):
+			b.WriteString(inner[i : i+2])
+			i++
+		case inner[i] == '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteByte(inner[i])
+		}
+	}
+	b.WriteByte('"')
+	s, err := strconv.Unquote(b.String())
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted text: %s", tok)
+	}
+	return s, nil
+}
+
 // Parse - DOCS-TBD
 func (c *Condition) Parse(s string) error {
 	data := []byte(s)
@@ -66,10 +328,10 @@ func (c *Condition) Parse(s string) error {
 		[]byte(`>`),
 		[]byte(`<`),
 	}
-	for offset := range data {
+	for offset := 1; offset < len(data); offset++ {
 		for _, f := range functions {
 			n := len(f)
-			if offset < n {
+			if offset+n > len(data) {
 				continue
 			}
 			if bytes.Equal(data[offset:offset+n], f) {
@@ -109,6 +371,25 @@ func (c Condition) MarshalJSON() ([]byte, error) {
 
 	b.Write(function)
 	b.WriteString(",")
+	if c.Operand != nil {
+		switch c.Function {
+		case "<", "<=", ">", ">=":
+			if !isNumber(c.Operand) {
+				return []byte{}, fmt.Errorf("marshal Condition.Operand: function %s requires an integer or a real, got %T", c.Function, c.Operand)
+			}
+		}
+		operand, err := encodeValue(c.Operand)
+		if err != nil {
+			return []byte{}, fmt.Errorf("marshal Condition.Operand: %s", err)
+		}
+		value, err := json.Marshal(operand)
+		if err != nil {
+			return []byte{}, fmt.Errorf("marshal Condition.Operand: %s", err)
+		}
+		b.Write(value)
+		b.WriteString("]")
+		return b.Bytes(), nil
+	}
 	switch c.Type {
 	case "string":
 		value, err := json.Marshal(c.Value)
@@ -116,9 +397,123 @@ func (c Condition) MarshalJSON() ([]byte, error) {
 			return []byte{}, fmt.Errorf("marshal Condition.Value: %s", err)
 		}
 		b.Write(value)
+	case "uuid":
+		value, err := json.Marshal(UUID(c.Value))
+		if err != nil {
+			return []byte{}, fmt.Errorf("marshal Condition.Value: %s", err)
+		}
+		b.Write(value)
+	case "bool":
+		v, err := strconv.ParseBool(c.Value)
+		if err != nil {
+			return []byte{}, fmt.Errorf("marshal Condition.Value: %s", err)
+		}
+		b.WriteString(strconv.FormatBool(v))
+	case "integer", "real":
+		if _, err := strconv.ParseFloat(c.Value, 64); err != nil {
+			return []byte{}, fmt.Errorf("marshal Condition.Value: %s", err)
+		}
+		b.WriteString(c.Value)
 	default:
 		return []byte{}, fmt.Errorf("marshal Condition.Value: no support for '%s' type", c.Type)
 	}
 	b.WriteString("]")
 	return b.Bytes(), nil
 }
+
+// Match evaluates the condition on the row as the server would, see RFC
+// 7047, Section 5.1, e.g. to filter the rows of a Cache.
+func (c Condition) Match(row Row) (bool, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return false, err
+	}
+	var wire []interface{}
+	if err := json.Unmarshal(b, &wire); err != nil || len(wire) != 3 {
+		return false, fmt.Errorf("invalid condition %s", b)
+	}
+	return matchWire(row, c.Column, c.Function, wire[2])
+}
+
+// matchWire evaluates the condition on the column of the row, with the
+// operand in its JSON form.
+func matchWire(row Row, column, function string, operand interface{}) (bool, error) {
+	value, exists := row[column]
+	if !exists {
+		return false, fmt.Errorf("column %s: %w", column, ErrColumnNotFound)
+	}
+	switch function {
+	case "==":
+		return equalValues(value, operand), nil
+	case "!=":
+		return !equalValues(value, operand), nil
+	case "<", "<=", ">", ">=":
+		x, ok := value.(float64)
+		y, isNumber := operand.(float64)
+		if !ok || !isNumber {
+			return false, fmt.Errorf("column %s: function %s requires an integer or a real", column, function)
+		}
+		switch function {
+		case "<":
+			return x < y, nil
+		case "<=":
+			return x <= y, nil
+		case ">":
+			return x > y, nil
+		}
+		return x >= y, nil
+	case "includes", "excludes":
+		includes := function == "includes"
+		valueKind, _ := splitValue(value)
+		operandKind, _ := splitValue(operand)
+		if valueKind == "map" || operandKind == "map" {
+			pairs, err := newOvsMap(operand)
+			if err != nil {
+				return false, fmt.Errorf("column %s: %s", column, err)
+			}
+			have, err := newOvsMap(value)
+			if err != nil {
+				return false, fmt.Errorf("column %s: %s", column, err)
+			}
+			for k, v := range pairs {
+				if w, exists := have[k]; (exists && w == v) != includes {
+					return false, nil
+				}
+			}
+			return true, nil
+		}
+		elems, err := newOvsSet(operand)
+		if err != nil {
+			return false, fmt.Errorf("column %s: %s", column, err)
+		}
+		have, err := newOvsSet(value)
+		if err != nil {
+			return false, fmt.Errorf("column %s: %s", column, err)
+		}
+		for _, elem := range elems {
+			found := false
+			for _, h := range have {
+				if h == elem {
+					found = true
+					break
+				}
+			}
+			if found != includes {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("column %s: unsupported function %s", column, function)
+}
+
+// matchConditions returns true when the row matches all the conditions.
+func matchConditions(row Row, where []Condition) (bool, error) {
+	for _, c := range where {
+		matched, err := c.Match(row)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"encoding/json"
 	//"github.com/davecgh/go-spew/spew"
 	"testing"
 )
@@ -32,6 +33,10 @@ func TestConditionParse(t *testing.T) {
 		{condition: "db_version==7.3.0", column: "db_version", function: "==", value: "7.4.0", shouldFail: true, shouldErr: false},
 		{condition: "db_version==", column: "db_version", function: "==", value: "", shouldFail: true, shouldErr: true},
 		{condition: "db_version==7.3.0", column: "db_version", function: "==", value: "7.3.0", shouldFail: false, shouldErr: false},
+		{condition: "c==x", column: "c", function: "==", value: "x", shouldFail: false, shouldErr: false},
+		{condition: "tag<=5", column: "tag", function: "<=", value: "5", shouldFail: false, shouldErr: false},
+		{condition: "==x", shouldFail: true, shouldErr: true},
+		{condition: "a=", shouldFail: true, shouldErr: true},
 	} {
 		condition, err := NewCondition([]string{test.condition})
 		if err != nil {
@@ -62,3 +67,53 @@ func TestConditionParse(t *testing.T) {
 		t.Fatalf("Failed %d tests", testFailed)
 	}
 }
+
+func TestConditionBuilders(t *testing.T) {
+	for i, test := range []struct {
+		condition  Condition
+		want       string
+		shouldFail bool
+	}{
+		{condition: Equal("name", `br-"int"`), want: `["name","==","br-\"int\""]`},
+		{condition: NotEqual("admin_state", true), want: `["admin_state","!=",true]`},
+		{condition: LessThan("ofport", 10), want: `["ofport","\u003c",10]`},
+		{condition: LessOrEqual("mtu", 1500), want: `["mtu","\u003c=",1500]`},
+		{condition: GreaterThan("load", 1.5), want: `["load","\u003e",1.5]`},
+		{condition: GreaterOrEqual("tag", uint16(100)), want: `["tag","\u003e=",100]`},
+		{condition: Equal("_uuid", UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8")), want: `["_uuid","==",["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]`},
+		{condition: Includes("ports", []UUID{"6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"}), want: `["ports","includes",["set",[["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]]]`},
+		{condition: Includes("external_ids", map[string]string{"iface-id": "vif [1]"}), want: `["external_ids","includes",["map",[["iface-id","vif [1]"]]]]`},
+		{condition: Excludes("trunks", []int{1, 2}), want: `["trunks","excludes",["set",[1,2]]]`},
+		{condition: Condition{Column: "_uuid", Function: "==", Value: "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8", Type: "uuid"}, want: `["_uuid","==",["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]`},
+		{condition: Condition{Column: "up", Function: "==", Value: "true", Type: "bool"}, want: `["up","==",true]`},
+		{condition: LessThan("name", "br-int"), shouldFail: true},
+		{condition: Includes("ports", [][]string{{"a"}}), shouldFail: true},
+	} {
+		b, err := json.Marshal(test.condition)
+		if err != nil {
+			if !test.shouldFail {
+				t.Fatalf("FAIL: Test %d: expected to marshal, but failed: %v", i, err)
+			}
+			t.Logf("PASS: Test %d: failed to marshal as expected: %v", i, err)
+			continue
+		}
+		if test.shouldFail {
+			t.Fatalf("FAIL: Test %d: expected to fail, but marshaled: %s", i, b)
+		}
+		if string(b) != test.want {
+			t.Fatalf("FAIL: Test %d: expected '%s', but got '%s'", i, test.want, b)
+		}
+		t.Logf("PASS: Test %d: %s", i, b)
+	}
+
+	where := And(Equal("name", "br-int"), Includes("external_ids", map[string]string{"owner": "test"}))
+	b, err := json.Marshal(Select("Bridge", []string{"name"}, where...))
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	want := `{"op":"select","table":"Bridge","where":[["name","==","br-int"],["external_ids","includes",["map",[["owner","test"]]]]],"columns":["name"]}`
+	if string(b) != want {
+		t.Fatalf("FAIL: expected '%s', but got '%s'", want, b)
+	}
+	t.Logf("PASS: conjunction of conditions: %s", b)
+}
@@ -0,0 +1,297 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultReadYourWritesTimeout is the time a read waits for the cluster
+// member to catch up with the writes of the client, before it is sent to
+// the leader instead.
+const DefaultReadYourWritesTimeout = time.Second
+
+// WithReadYourWrites makes the reads of a client observe the rows it has
+// written, even when they are served by a follower lagging behind the
+// leader, see ClusterLeaderWrites. The client records the rows it inserts
+// and deletes, and the versions the rows it updates or mutates have after
+// the commit, as read back from the leader. A later read of their tables
+// starts with wait operations, which hold it until the cluster member has
+// caught up with the rows, for up to the timeout. Should a wait fail, e.g.
+// as another client changed the row in the meantime, the read is sent to
+// the leader instead.
+//
+// The rows are forgotten once a read has observed them.
+func WithReadYourWrites(timeout time.Duration) ClientOption {
+	return func(cli *Client) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid read-your-writes timeout: %s", timeout)
+		}
+		if timeout == 0 {
+			timeout = DefaultReadYourWritesTimeout
+		}
+		cli.ReadYourWrites = true
+		cli.ReadYourWritesTimeout = timeout
+		cli.written = &writeLog{rows: map[writtenRow]writtenState{}}
+		return nil
+	}
+}
+
+// writeLog holds the rows written by a client which have not been read
+// since.
+type writeLog struct {
+	mux  sync.Mutex
+	rows map[writtenRow]writtenState
+}
+
+type writtenRow struct {
+	db    string
+	table string
+	uuid  UUID
+}
+
+// writtenState is the state of a row written, either its version, or
+// whether it has been deleted. A row inserted has neither, as it is
+// waited for to exist. The reads of a row whose version is unknown go to
+// the leader.
+type writtenState struct {
+	version UUID
+	deleted bool
+	unknown bool
+}
+
+// waits returns the wait operations holding a read of the tables until
+// the rows written have reached the cluster member, and the rows. It
+// reports whether the read has to go to the leader instead.
+func (w *writeLog) waits(t Transaction, timeout time.Duration) ([]Operation, []writtenRow, bool) {
+	tables := map[string]bool{}
+	for _, op := range t.Operations {
+		tables[op.Table] = true
+	}
+	ms := int(timeout / time.Millisecond)
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	var ops []Operation
+	var rows []writtenRow
+	leader := false
+	for row, state := range w.rows {
+		if row.db != t.Database || !tables[row.table] {
+			continue
+		}
+		rows = append(rows, row)
+		if state.unknown {
+			leader = true
+			continue
+		}
+		columns := []string{"_uuid"}
+		expected := []map[string]interface{}{{"_uuid": row.uuid}}
+		switch {
+		case state.deleted:
+			expected = []map[string]interface{}{}
+		case state.version != "":
+			columns = []string{"_version"}
+			expected = []map[string]interface{}{{"_version": state.version}}
+		}
+		op := Wait(row.table, columns, "==", expected, Equal("_uuid", row.uuid))
+		op.Timeout = &ms
+		ops = append(ops, op)
+	}
+	return ops, rows, leader
+}
+
+func (w *writeLog) record(row writtenRow, state writtenState) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.rows[row] = state
+}
+
+func (w *writeLog) forget(rows []writtenRow) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	for _, row := range rows {
+		delete(w.rows, row)
+	}
+}
+
+// readWritten sends the read-only transaction, after the wait operations
+// for the rows written by the client. Should a wait fail, the transaction
+// is sent to the leader, without them.
+func (c *Client) readWritten(ctx context.Context, t Transaction) (*Response, error) {
+	waits, rows, leader := c.written.waits(t, c.ReadYourWritesTimeout)
+	if leader {
+		c.written.forget(rows)
+		t.leader = true
+		return c.transactTarget(ctx, t)
+	}
+	if len(waits) == 0 {
+		return c.transactTarget(ctx, t)
+	}
+	held := t
+	held.Operations = append(waits, t.Operations...)
+	resp, err := c.transactTarget(ctx, held)
+	if err != nil && !errors.Is(err, ErrConflict) {
+		return nil, err
+	}
+	c.written.forget(rows)
+	if err != nil {
+		// The response holds the error of the first wait only.
+		t.leader = true
+		return c.transactTarget(ctx, t)
+	}
+	raw, err := splitResults(resp.Result, len(held.Operations))
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range raw[:len(waits)] {
+		if operationError(result) != nil {
+			t.leader = true
+			return c.transactTarget(ctx, t)
+		}
+	}
+	indexes := make([]int, len(waits))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return dropResults(resp, indexes...)
+}
+
+// recordWrites sends the transaction, with a select of the rows before
+// every update, mutation, or deletion, and records the rows written once
+// the transaction has committed.
+func (c *Client) recordWrites(ctx context.Context, t Transaction) (*Response, error) {
+	sent := t
+	sent.Operations = make([]Operation, 0, len(t.Operations))
+	var selects []int
+	for _, op := range t.Operations {
+		switch op.Name {
+		case "update", "mutate", "delete":
+			selects = append(selects, len(sent.Operations))
+			sent.Operations = append(sent.Operations, Select(op.Table, []string{"_uuid"}, op.Conditions...))
+		}
+		sent.Operations = append(sent.Operations, op)
+	}
+	resp, err := c.transactTarget(ctx, sent)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := splitResults(resp.Result, len(sent.Operations))
+	if err != nil || len(raw) > len(sent.Operations) {
+		// The transaction has not committed, so that there is nothing to
+		// record.
+		return dropResults(resp, selects...)
+	}
+	for _, result := range raw {
+		if operationError(result) != nil {
+			return dropResults(resp, selects...)
+		}
+	}
+	written := map[writtenRow]writtenState{}
+	var changed []writtenRow
+	for i, op := range sent.Operations {
+		var res OperationResult
+		if err := json.Unmarshal(raw[i], &res); err != nil {
+			continue
+		}
+		if op.Name == "insert" && res.UUID != "" {
+			written[writtenRow{db: t.Database, table: op.Table, uuid: UUID(res.UUID)}] = writtenState{}
+		}
+		if !containsIndex(selects, i) {
+			continue
+		}
+		for _, r := range res.Rows {
+			uuid, err := r.GetUUID("_uuid")
+			if err != nil {
+				continue
+			}
+			row := writtenRow{db: t.Database, table: op.Table, uuid: uuid}
+			if sent.Operations[i+1].Name == "delete" {
+				written[row] = writtenState{deleted: true}
+				continue
+			}
+			if _, ok := written[row]; !ok {
+				changed = append(changed, row)
+			}
+			written[row] = writtenState{}
+		}
+	}
+	if err := c.readVersions(ctx, t.Database, changed, written); err != nil {
+		// The rows whose versions are unknown are read from the leader.
+		for _, row := range changed {
+			if state := written[row]; !state.deleted && state.version == "" {
+				written[row] = writtenState{unknown: true}
+			}
+		}
+	}
+	for row, state := range written {
+		c.written.record(row, state)
+	}
+	return dropResults(resp, selects...)
+}
+
+// readVersions reads the versions of the rows changed from the leader,
+// which has committed them.
+func (c *Client) readVersions(ctx context.Context, db string, changed []writtenRow, written map[writtenRow]writtenState) error {
+	t := Transaction{Database: db, leader: true}
+	var rows []writtenRow
+	for _, row := range changed {
+		if written[row].deleted {
+			continue
+		}
+		t.Operations = append(t.Operations, Select(row.table, []string{"_uuid", "_version"}, Equal("_uuid", row.uuid)))
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	resp, err := c.transactTarget(ctx, t)
+	if err != nil {
+		return err
+	}
+	raw, err := splitResults(resp.Result, len(rows))
+	if err != nil {
+		return err
+	}
+	for i, row := range rows {
+		var res OperationResult
+		if err := json.Unmarshal(raw[i], &res); err != nil {
+			return err
+		}
+		if len(res.Rows) == 0 {
+			// The row has been deleted since.
+			written[row] = writtenState{deleted: true}
+			continue
+		}
+		version, err := res.Rows[0].GetUUID("_version")
+		if err != nil {
+			return err
+		}
+		written[row] = writtenState{version: version}
+	}
+	return nil
+}
+
+func containsIndex(indexes []int, i int) bool {
+	for _, index := range indexes {
+		if index == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	const (
+		row     = "6d8f1ca4-4e0c-4c5c-9f7e-1f1e2b1f0a01"
+		version = "6d8f1ca4-4e0c-4c5c-9f7e-1f1e2b1f0a02"
+		added   = "6d8f1ca4-4e0c-4c5c-9f7e-1f1e2b1f0a03"
+	)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var sent []string
+	stale := false
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, string(params))
+		results := []interface{}{}
+		for i, arg := range args[1:] {
+			var op struct {
+				Name    string   `json:"op"`
+				Columns []string `json:"columns"`
+			}
+			json.Unmarshal(arg, &op)
+			switch {
+			case op.Name == "wait" && stale:
+				results = append(results, map[string]interface{}{"error": "timed out"})
+				for range args[i+2:] {
+					results = append(results, nil)
+				}
+				return results
+			case op.Name == "wait":
+				results = append(results, map[string]interface{}{})
+			case op.Name == "update" || op.Name == "delete":
+				results = append(results, map[string]interface{}{"count": 1})
+			case op.Name == "insert":
+				results = append(results, map[string]interface{}{"uuid": []string{"uuid", added}})
+			case op.Name == "select" && len(op.Columns) == 1 && op.Columns[0] == "_uuid":
+				results = append(results, map[string]interface{}{"rows": []interface{}{map[string]interface{}{"_uuid": []string{"uuid", row}}}})
+			case op.Name == "select" && len(op.Columns) == 2 && op.Columns[1] == "_version":
+				results = append(results, map[string]interface{}{"rows": []interface{}{map[string]interface{}{"_uuid": []string{"uuid", row}, "_version": []string{"uuid", version}}}})
+			default:
+				results = append(results, map[string]interface{}{"rows": []interface{}{}})
+			}
+		}
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1, WithReadYourWrites(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+	take := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		s := sent
+		sent = nil
+		return s
+	}
+
+	results, err := cli.TransactOperations(ctx, "OVN_Southbound", Update("Chassis", map[string]interface{}{"hostname": "a"}, Equal("name", "ch1")))
+	if err != nil {
+		t.Fatalf("FAIL: expected the update to pass, but failed with: %v", err)
+	}
+	if len(results) != 1 || results[0].Count != 1 {
+		t.Fatalf("FAIL: expected the result of the update only, but got: %+v", results)
+	}
+	if txns := take(); len(txns) != 2 || !strings.Contains(txns[1], `"_version"`) {
+		t.Fatalf("FAIL: expected the update and the read of the version, but got: %v", txns)
+	}
+	t.Logf("PASS: recorded the version of the row updated")
+
+	results, err = cli.TransactOperations(ctx, "OVN_Southbound", Select("Chassis", nil))
+	if err != nil {
+		t.Fatalf("FAIL: expected the select to pass, but failed with: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("FAIL: expected the result of the select only, but got: %+v", results)
+	}
+	wait := `{"op":"wait","table":"Chassis","where":[["_uuid","==",["uuid","` + row + `"]]],"columns":["_version"],"until":"==","rows":[{"_version":["uuid","` + version + `"]}],"timeout":50}`
+	if txns := take(); len(txns) != 1 || !strings.Contains(txns[0], wait) {
+		t.Fatalf("FAIL: expected the select to wait for the version, but got: %v", txns)
+	}
+	if _, err := cli.TransactOperations(ctx, "OVN_Southbound", Select("Chassis", nil)); err != nil {
+		t.Fatalf("FAIL: expected the select to pass, but failed with: %v", err)
+	}
+	if txns := take(); len(txns) != 1 || strings.Contains(txns[0], "wait") {
+		t.Fatalf("FAIL: expected the row to be forgotten once read, but got: %v", txns)
+	}
+	t.Logf("PASS: waited for the version once")
+
+	if _, err := cli.TransactOperations(ctx, "OVN_Southbound", Insert("Chassis", map[string]interface{}{"name": "ch2"}), Delete("Encap", Equal("chassis_name", "ch1"))); err != nil {
+		t.Fatalf("FAIL: expected the insert to pass, but failed with: %v", err)
+	}
+	take()
+	mu.Lock()
+	stale = true
+	mu.Unlock()
+	results, err = cli.TransactOperations(ctx, "OVN_Southbound", Select("Chassis", nil), Select("Encap", nil))
+	if err != nil {
+		t.Fatalf("FAIL: expected the select to pass, but failed with: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("FAIL: expected the results of the selects only, but got: %+v", results)
+	}
+	txns := take()
+	if len(txns) != 2 || strings.Contains(txns[1], "wait") {
+		t.Fatalf("FAIL: expected the select to be resent without the waits, but got: %v", txns)
+	}
+	for _, wait := range []string{
+		`"columns":["_uuid"],"until":"==","rows":[{"_uuid":["uuid","` + added + `"]}]`,
+		`"columns":["_uuid"],"until":"==","rows":[]`,
+	} {
+		if !strings.Contains(txns[0], wait) {
+			t.Fatalf("FAIL: expected the select to wait with %s, but got: %v", wait, txns[0])
+		}
+	}
+	t.Logf("PASS: waited for the rows inserted and deleted, and fell back to the leader")
+
+	if _, err := NewClient("tcp:"+l.Addr().String(), 1, WithReadYourWrites(-time.Second)); err == nil {
+		t.Fatalf("FAIL: expected a negative timeout to be rejected")
+	}
+}
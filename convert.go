@@ -0,0 +1,214 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConvertError is returned by CheckConvert when the data of a database does
+// not fit a new schema, or would be dropped by the conversion to it.
+type ConvertError struct {
+	Name string
+	// Dropped are the tables, e.g. "Gateway_Chassis", and the columns,
+	// e.g. "Chassis.vtep_logical_switches", of the rows the conversion
+	// drops.
+	Dropped []string
+	// Invalid are the values, and the rows, which violate the constraints
+	// of the new schema, e.g. its types, enums, maxRows, and indexes.
+	Invalid []string
+}
+
+func (e *ConvertError) Error() string {
+	var msgs []string
+	if len(e.Invalid) > 0 {
+		msgs = append(msgs, fmt.Sprintf("invalid data: %s", strings.Join(e.Invalid, "; ")))
+	}
+	if len(e.Dropped) > 0 {
+		msgs = append(msgs, fmt.Sprintf("dropped data of %s", strings.Join(e.Dropped, ", ")))
+	}
+	return fmt.Sprintf("converting '%s' database: %s", e.Name, strings.Join(msgs, "; "))
+}
+
+// CheckConvert checks that the rows of the database fit the schema, before
+// converting the database to it: the values of the columns of both schemas
+// must be of the types, and satisfy the constraints, of the new schema,
+// the tables must not hold more rows than their maxRows, and the values of
+// the new indexes must be unique. The rows of the tables and columns
+// missing from the new schema are reported as dropped. It returns a
+// *ConvertError describing the problems, or nil.
+func (c *Client) CheckConvert(ctx context.Context, db string, schema Schema) error {
+	current, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return err
+	}
+	tables := current.GetTables()
+	ops := make([]Operation, len(tables))
+	selected := make([][]string, len(tables))
+	for i, table := range tables {
+		columns := []string{"_uuid"}
+		if t := schema.Table(table); t != nil {
+			for _, column := range current.GetColumns(table) {
+				if t.Column(column) != nil {
+					columns = append(columns, column)
+				}
+			}
+		}
+		selected[i] = columns
+		ops[i] = Select(table, columns)
+	}
+	e := &ConvertError{Name: db}
+	if len(ops) > 0 {
+		results, err := c.TransactOperations(ctx, db, ops...)
+		if err != nil {
+			return err
+		}
+		for i, table := range tables {
+			e.checkTable(current.Table(table), schema.Table(table), table, selected[i][1:], results[i].Rows)
+		}
+	}
+	if len(e.Dropped) == 0 && len(e.Invalid) == 0 {
+		return nil
+	}
+	sort.Strings(e.Dropped)
+	return e
+}
+
+// checkTable checks the rows of the table of the current schema against the
+// table of the new schema, nil when it is dropped.
+func (e *ConvertError) checkTable(current, t *Table, table string, columns []string, rows []Row) {
+	if len(rows) == 0 {
+		return
+	}
+	if t == nil {
+		e.Dropped = append(e.Dropped, table)
+		return
+	}
+	for column := range current.Columns {
+		if t.Column(column) == nil {
+			e.Dropped = append(e.Dropped, table+"."+column)
+		}
+	}
+	if t.MaxRows > 0 && len(rows) > t.MaxRows {
+		e.Invalid = append(e.Invalid, fmt.Sprintf("table %s: %d rows, expected at most %d", table, len(rows), t.MaxRows))
+	}
+	for _, row := range rows {
+		uuid, _ := row.GetString("_uuid")
+		for _, column := range columns {
+			if err := t.Column(column).checkValue(row[column]); err != nil {
+				e.Invalid = append(e.Invalid, fmt.Sprintf("column %s of table %s, row %s: %s", column, table, uuid, err))
+			}
+		}
+	}
+	for _, index := range t.GetIndexes() {
+		seen := make(map[string]string, len(rows))
+		for _, row := range rows {
+			values := make([]interface{}, len(index))
+			for i, column := range index {
+				values[i] = row[column]
+			}
+			b, err := json.Marshal(values)
+			if err != nil {
+				continue
+			}
+			uuid, _ := row.GetString("_uuid")
+			if other, exists := seen[string(b)]; exists {
+				e.Invalid = append(e.Invalid, fmt.Sprintf("table %s, rows %s and %s: duplicate values of index %v", table, other, uuid, index))
+				continue
+			}
+			seen[string(b)] = uuid
+		}
+	}
+}
+
+// Convert converts the database to the schema, e.g. a newer version of it,
+// with the convert method of ovsdb-server. The conversion of a clustered
+// database is routed to its leader, as configured by the ClusterMode of the
+// client. The database is checked with CheckConvert first: the conversion
+// fails without being attempted when the rows do not fit the schema, but
+// the data of the tables and columns missing from it is dropped. The cached
+// schema of the database is discarded once it is converted.
+func (c *Client) Convert(ctx context.Context, db string, schema Schema) error {
+	var e *ConvertError
+	if err := c.CheckConvert(ctx, db, schema); err != nil && !(errors.As(err, &e) && len(e.Invalid) == 0) {
+		return err
+	}
+	b, err := json.Marshal(schema.wire())
+	if err != nil {
+		return fmt.Errorf("'convert' method failed for '%s' database: %v", db, err)
+	}
+	name, err := encodeString(db)
+	if err != nil {
+		return fmt.Errorf("'convert' method failed for '%s' database: %v", db, err)
+	}
+	target, err := c.clusterTarget(ctx, Transaction{Database: db, leader: true})
+	if err != nil {
+		return fmt.Errorf("'convert' method failed for '%s' database: %w", db, err)
+	}
+	if _, err := target.queryContext(ctx, "convert", name+","+string(b)); err != nil {
+		return fmt.Errorf("'convert' method failed for '%s' database: %w", db, err)
+	}
+	for _, cli := range []*Client{c, target} {
+		cli.cacheMux.Lock()
+		delete(cli.Schemas, db)
+		delete(cli.References, db)
+		cli.cacheMux.Unlock()
+	}
+	return nil
+}
+
+// wire returns the schema in its JSON form, see RFC 7047, Section 3.2,
+// with the members holding their default values left out.
+func (sc *Schema) wire() map[string]interface{} {
+	tables := make(map[string]interface{}, len(sc.Tables))
+	for name, t := range sc.Tables {
+		columns := make(map[string]interface{}, len(t.Columns))
+		for column, c := range t.Columns {
+			col := map[string]interface{}{"type": c.Type}
+			if c.Ephemeral {
+				col["ephemeral"] = true
+			}
+			if !c.Mutable {
+				col["mutable"] = false
+			}
+			columns[column] = col
+		}
+		table := map[string]interface{}{"columns": columns}
+		if len(t.Indexes) > 0 {
+			table["indexes"] = t.Indexes
+		}
+		if t.MaxRows > 0 {
+			table["maxRows"] = t.MaxRows
+		}
+		if t.IsRoot {
+			table["isRoot"] = true
+		}
+		tables[name] = table
+	}
+	wire := map[string]interface{}{
+		"name":    sc.Name,
+		"version": sc.Version,
+		"tables":  tables,
+	}
+	if sc.Checksum != "" {
+		wire["cksum"] = sc.Checksum
+	}
+	return wire
+}
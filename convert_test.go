@@ -0,0 +1,124 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	current := `{"name": "Test", "version": "1.0.0", "tables": {
+		"Switch": {"columns": {
+			"name": {"type": "string"},
+			"mode": {"type": "string"},
+			"legacy": {"type": "integer"}},
+			"isRoot": true},
+		"Old": {"columns": {"name": {"type": "string"}}, "isRoot": true}}}`
+	var converted atomic.Value
+	var schemaRequests atomic.Int32
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			schemaRequests.Add(1)
+			return json.RawMessage(current)
+		case "convert":
+			converted.Store(string(params))
+			return map[string]interface{}{}
+		case "transact":
+			switches := []interface{}{
+				map[string]interface{}{"_uuid": []interface{}{"uuid", "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"}, "name": "sw0", "mode": "access", "legacy": 1},
+				map[string]interface{}{"_uuid": []interface{}{"uuid", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"}, "name": "sw0", "mode": "bridge", "legacy": 2},
+			}
+			old := []interface{}{map[string]interface{}{"_uuid": []interface{}{"uuid", "0c5e8c8a-2e8f-4b43-9d4e-2b1b0a9d6f11"}}}
+			// The tables are selected in order.
+			return []interface{}{
+				map[string]interface{}{"rows": old},
+				map[string]interface{}{"rows": switches},
+			}
+		}
+		return nil
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	strict := `{"name": "Test", "version": "2.0.0", "tables": {
+		"Switch": {"columns": {
+			"name": {"type": "string"},
+			"mode": {"type": {"key": {"type": "string", "enum": ["set", ["access", "trunk"]]}}}},
+			"indexes": [["name"]],
+			"maxRows": 1,
+			"isRoot": true}}}`
+	var schema Schema
+	if err := json.Unmarshal([]byte(strict), &schema); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	var e *ConvertError
+	err = cli.Convert(context.Background(), "Test", schema)
+	if !errors.As(err, &e) || len(e.Invalid) != 3 || converted.Load() != nil {
+		t.Fatalf("FAIL: expected the conversion to be refused, got: %v", err)
+	}
+	for _, expected := range []string{"2 rows, expected at most 1", "bridge is not one of [access trunk]", "duplicate values of index [name]"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("FAIL: expected the error to contain %q, got: %v", expected, err)
+		}
+	}
+	if len(e.Dropped) != 2 || e.Dropped[0] != "Old" || e.Dropped[1] != "Switch.legacy" {
+		t.Fatalf("FAIL: unexpected dropped data: %v", e.Dropped)
+	}
+	t.Logf("PASS: refused conversion: %v", err)
+
+	relaxed := strings.Replace(strings.Replace(strict, `"maxRows": 1,`, "", 1), `"indexes": [["name"]],`, "", 1)
+	relaxed = strings.Replace(relaxed, `"trunk"`, `"trunk", "bridge"`, 1)
+	if err := json.Unmarshal([]byte(relaxed), &schema); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	if err := cli.CheckConvert(context.Background(), "Test", schema); !errors.As(err, &e) || len(e.Invalid) != 0 || len(e.Dropped) != 2 {
+		t.Fatalf("FAIL: expected dropped data only, got: %v", err)
+	}
+	if err := cli.Convert(context.Background(), "Test", schema); err != nil {
+		t.Fatalf("FAIL: expected the conversion to succeed, but failed with: %v", err)
+	}
+	var params []json.RawMessage
+	if s, ok := converted.Load().(string); !ok || json.Unmarshal([]byte(s), &params) != nil || len(params) != 2 || string(params[0]) != `"Test"` {
+		t.Fatalf("FAIL: unexpected convert params: %v", converted.Load())
+	}
+	var sent Schema
+	if err := json.Unmarshal(params[1], &sent); err != nil || sent.Version != "2.0.0" || !sent.Tables["Switch"].IsRoot {
+		t.Fatalf("FAIL: unexpected schema sent: %+v, %v", sent, err)
+	}
+	if d := DiffSchemas(schema, sent); !d.Empty() || strings.Contains(string(params[1]), "maxRows") {
+		t.Fatalf("FAIL: unexpected schema sent: %s", params[1])
+	}
+	t.Logf("PASS: converted to %s", sent.Version)
+
+	n := schemaRequests.Load()
+	if _, err := cli.GetSchema("Test"); err != nil || schemaRequests.Load() != n+1 {
+		t.Fatalf("FAIL: expected the cached schema to be discarded, got: %v", err)
+	}
+	t.Logf("PASS: the cached schema was discarded")
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Converter converts the value of a column, in the OVSDB notation, e.g.
+// ["map",[["a",1]]], to a Go value.
+type Converter func(data interface{}) (interface{}, error)
+
+// ConvertTo returns a converter storing the values of a column in values of
+// the type of v, with the conversions of Row.Get, e.g.
+// ConvertTo(map[string]int64(nil)) for the maps of strings to integers, and
+// ConvertTo([]UUID(nil)) for the sets of references.
+func ConvertTo(v interface{}) Converter {
+	t := reflect.TypeOf(v)
+	return func(data interface{}) (interface{}, error) {
+		if t == nil {
+			return nil, fmt.Errorf("no type to convert to")
+		}
+		rv := reflect.New(t).Elem()
+		if err := decodeColumn(rv, data); err != nil {
+			return nil, err
+		}
+		return rv.Interface(), nil
+	}
+}
+
+// ConverterRegistry holds the converters of the columns, by table and
+// column, so that the columns of the schemas the package knows nothing
+// about, e.g. hardware_vtep, are decoded to the Go types of their own, see
+// WithConverters. It is safe for concurrent use.
+type ConverterRegistry struct {
+	mux        sync.RWMutex
+	converters map[string]map[string]Converter
+}
+
+// NewConverterRegistry returns an empty registry.
+func NewConverterRegistry() *ConverterRegistry {
+	return &ConverterRegistry{converters: make(map[string]map[string]Converter)}
+}
+
+// WithConverters makes the client decode the columns of the registry with
+// their converters, e.g. in DumpTable.
+func WithConverters(r *ConverterRegistry) ClientOption {
+	return func(cli *Client) error {
+		cli.Converters = r
+		return nil
+	}
+}
+
+// Register sets the converter of the column of the table. An empty table
+// stands for all tables, e.g. for external_ids, and is overridden by the
+// converters registered for a table.
+func (r *ConverterRegistry) Register(table, column string, conv Converter) error {
+	if column == "" {
+		return fmt.Errorf("converter has no column")
+	}
+	if conv == nil {
+		return fmt.Errorf("converter of column %s is nil", column)
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.converters[table] == nil {
+		r.converters[table] = make(map[string]Converter)
+	}
+	r.converters[table][column] = conv
+	return nil
+}
+
+// Lookup returns the converter of the column of the table, or nil.
+func (r *ConverterRegistry) Lookup(table, column string) Converter {
+	if r == nil {
+		return nil
+	}
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	if conv := r.converters[table][column]; conv != nil {
+		return conv
+	}
+	return r.converters[""][column]
+}
+
+// table returns the converters of the columns of the table, including
+// those registered for all tables, or nil.
+func (r *ConverterRegistry) table(table string) map[string]Converter {
+	if r == nil {
+		return nil
+	}
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	if len(r.converters[""]) == 0 {
+		return r.converters[table]
+	}
+	converters := make(map[string]Converter, len(r.converters[""])+len(r.converters[table]))
+	for _, m := range []map[string]Converter{r.converters[""], r.converters[table]} {
+		for column, conv := range m {
+			converters[column] = conv
+		}
+	}
+	return converters
+}
+
+// ConvertRow returns the columns of the row of the table, with the values
+// of the columns which have a converter converted. The other columns are
+// kept as they are.
+func (r *ConverterRegistry) ConvertRow(table string, row Row) (map[string]interface{}, error) {
+	converted := make(map[string]interface{}, len(row))
+	for name, data := range row {
+		conv := r.Lookup(table, name)
+		if conv == nil {
+			converted[name] = data
+			continue
+		}
+		v, err := conv(data)
+		if err != nil {
+			return nil, &columnError{name, err}
+		}
+		converted[name] = v
+	}
+	return converted, nil
+}
+
+// ConvertRows returns the rows of the result, converted by ConvertRow.
+func (r *Result) ConvertRows(registry *ConverterRegistry) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		converted, err := registry.ConvertRow(r.Table, row)
+		if err != nil {
+			return nil, fmt.Errorf("convert: %s", err)
+		}
+		rows = append(rows, converted)
+	}
+	return rows, nil
+}
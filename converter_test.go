@@ -0,0 +1,116 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConverterRegistry(t *testing.T) {
+	r := NewConverterRegistry()
+	if err := r.Register("Physical_Port", "vlan_stats", ConvertTo(map[string]int64(nil))); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if err := r.Register("", "port_fault_status", ConvertTo([]string(nil))); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if err := r.Register("Physical_Switch", "port_fault_status", func(data interface{}) (interface{}, error) {
+		return "overridden", nil
+	}); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if err := r.Register("Physical_Port", "", ConvertTo("")); err == nil {
+		t.Fatalf("FAIL: expected a converter without column to fail")
+	}
+
+	result := Result{
+		Table: "Physical_Port",
+		Rows: []Row{{
+			"name":              "p0",
+			"vlan_stats":        []interface{}{"map", []interface{}{[]interface{}{"10", float64(2)}}},
+			"port_fault_status": []interface{}{"set", []interface{}{"down"}},
+		}},
+	}
+	rows, err := result.ConvertRows(r)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	expected := map[string]interface{}{
+		"name":              "p0",
+		"vlan_stats":        map[string]int64{"10": 2},
+		"port_fault_status": []string{"down"},
+	}
+	if !reflect.DeepEqual(rows[0], expected) {
+		t.Fatalf("FAIL: expected row %#v, got %#v", expected, rows[0])
+	}
+	if conv := r.Lookup("Physical_Switch", "port_fault_status"); conv == nil {
+		t.Fatalf("FAIL: expected a converter")
+	} else if v, _ := conv(nil); v != "overridden" {
+		t.Fatalf("FAIL: expected the converter of the table to override, got %v", v)
+	}
+
+	result.Rows[0]["vlan_stats"] = "invalid"
+	if _, err := result.ConvertRows(r); err == nil || !strings.Contains(err.Error(), "vlan_stats") {
+		t.Fatalf("FAIL: expected the vlan_stats column to fail, got: %v", err)
+	}
+	t.Logf("PASS: converted row: %v", rows[0])
+}
+
+func TestDumpTableConverters(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "transact":
+			rows := []interface{}{
+				map[string]interface{}{
+					"name":         "br0",
+					"external_ids": []interface{}{"map", []interface{}{[]interface{}{"vlan", "10"}}},
+				},
+			}
+			return []interface{}{map[string]interface{}{"rows": rows}}
+		}
+		return nil
+	})
+	r := NewConverterRegistry()
+	r.Register("Bridge", "external_ids", func(data interface{}) (interface{}, error) {
+		m, err := newOvsMap(data)
+		if err != nil {
+			return nil, err
+		}
+		return len(m), nil
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1, WithConverters(r))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	rows, err := cli.DumpTable("Open_vSwitch", "Bridge")
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("FAIL: expected a row, got: %v, %v", rows, err)
+	}
+	if rows[0]["external_ids"] != 1 || rows[0]["name"] != "br0" {
+		t.Fatalf("FAIL: expected the external_ids to be converted, got: %v", rows[0])
+	}
+	t.Logf("PASS: dumped row: %v", rows[0])
+}
@@ -15,17 +15,26 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
+	"sort"
 )
 
 // OvsDatabase represents an instance of OVS DB.
 type OvsDatabase struct {
 	Client *Client
 	Name   string
-	Socket struct {
+	// Options are applied to the client when connecting to the database,
+	// e.g. WithTLSConfig for "ssl:" remotes.
+	Options []ClientOption
+	Socket  struct {
 		Remote  string
 		Control string
 		Raft    string
+		// Relay, when set, is the remote of the relays of the database,
+		// which the client connects to in place of Remote, e.g.
+		// "ssl:10.0.0.5:16642,ssl:10.0.0.6:16642".
+		Relay string
 	}
 	Port struct {
 		Default int
@@ -48,10 +57,15 @@ type OvsDatabase struct {
 
 // Databases - DOCS-TBD
 func (c *Client) Databases() ([]string, error) {
+	return c.DatabasesContext(context.Background())
+}
+
+// DatabasesContext is like Databases, but honors the context.
+func (c *Client) DatabasesContext(ctx context.Context) ([]string, error) {
 	method := "list_dbs"
-	response, err := c.query(method, nil)
+	response, err := c.queryContext(ctx, method, nil)
 	if err != nil {
-		return nil, fmt.Errorf("'%s' method failed: %v", method, err)
+		return nil, fmt.Errorf("'%s' method failed: %w", method, err)
 	}
 	dbs, err := response.Databases()
 	if err != nil {
@@ -60,9 +74,25 @@ func (c *Client) Databases() ([]string, error) {
 	return dbs, nil
 }
 
+// ListDatabases returns the names of the databases served by the server.
+// It is named after the list_dbs method and is the same as Databases.
+func (c *Client) ListDatabases() ([]string, error) {
+	return c.DatabasesContext(context.Background())
+}
+
+// ListDatabasesContext is like ListDatabases, but honors the context.
+func (c *Client) ListDatabasesContext(ctx context.Context) ([]string, error) {
+	return c.DatabasesContext(ctx)
+}
+
 // DatabaseExists - DOCS-TBD
 func (c *Client) DatabaseExists(dbName string) error {
-	databases, err := c.Databases()
+	return c.DatabaseExistsContext(context.Background(), dbName)
+}
+
+// DatabaseExistsContext is like DatabaseExists, but honors the context.
+func (c *Client) DatabaseExistsContext(ctx context.Context, dbName string) error {
+	databases, err := c.DatabasesContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -73,3 +103,127 @@ func (c *Client) DatabaseExists(dbName string) error {
 	}
 	return fmt.Errorf("database '%s' not found", dbName)
 }
+
+// NewOvsDatabase returns a database named name, served at remote, for
+// registering with OvnClient.RegisterDatabase or OvsClient.RegisterDatabase.
+func NewOvsDatabase(name, remote string, opts ...ClientOption) *OvsDatabase {
+	db := &OvsDatabase{Name: name, Options: opts}
+	db.Socket.Remote = remote
+	return db
+}
+
+// connect connects to the database, unless it is connected already. The
+// options of the client come first, so that the database may override them.
+func (db *OvsDatabase) connect(ctx context.Context, timeout int, logger Logger, opts []ClientOption) error {
+	if db.Client != nil {
+		return nil
+	}
+	remote := db.Socket.Remote
+	if db.Socket.Relay != "" {
+		remote = db.Socket.Relay
+	}
+	opts = append(append([]ClientOption{}, opts...), db.Options...)
+	cli, err := NewClientContext(ctx, remote, timeout, withLogger(logger, opts)...)
+	db.Client = &cli
+	if err != nil {
+		db.Client.closed = true
+		return fmt.Errorf("failed connecting to %s via %s: %s", db.Name, remote, err)
+	}
+	return nil
+}
+
+// IsRelay reports whether the client of the database is connected to a
+// relay of the database, see Client.IsRelay.
+func (db *OvsDatabase) IsRelay(ctx context.Context) (bool, error) {
+	if db.Client == nil {
+		return false, fmt.Errorf("%s: client was not initialized: %w", db.Name, ErrNotConnected)
+	}
+	return db.Client.IsRelay(ctx, db.Name)
+}
+
+// registry holds the databases registered with a client at runtime, in
+// addition to its built-in ones, by name.
+type registry map[string]*OvsDatabase
+
+// register adds db to the registry, unless its name is empty or taken by
+// one of the built-in databases or by a registered one.
+func (r *registry) register(db *OvsDatabase, builtin ...*OvsDatabase) error {
+	if db == nil || db.Name == "" {
+		return fmt.Errorf("database has no name")
+	}
+	for _, b := range builtin {
+		if b.Name == db.Name {
+			return fmt.Errorf("database '%s' is already registered", db.Name)
+		}
+	}
+	if _, exists := (*r)[db.Name]; exists {
+		return fmt.Errorf("database '%s' is already registered", db.Name)
+	}
+	if *r == nil {
+		*r = make(registry)
+	}
+	(*r)[db.Name] = db
+	return nil
+}
+
+// lookup returns the database named name, built-in or registered.
+func (r registry) lookup(name string, builtin ...*OvsDatabase) *OvsDatabase {
+	for _, b := range builtin {
+		if b.Name == name {
+			return b
+		}
+	}
+	return r[name]
+}
+
+// names returns the names of the built-in databases, followed by the
+// sorted names of the registered ones.
+func (r registry) names(builtin ...*OvsDatabase) []string {
+	names := make([]string, 0, len(builtin)+len(r))
+	for _, b := range builtin {
+		names = append(names, b.Name)
+	}
+	registered := make([]string, 0, len(r))
+	for name := range r {
+		registered = append(registered, name)
+	}
+	sort.Strings(registered)
+	return append(names, registered...)
+}
+
+// sorted returns the registered databases, sorted by name.
+func (r registry) sorted() []*OvsDatabase {
+	dbs := make([]*OvsDatabase, 0, len(r))
+	for _, name := range r.names() {
+		dbs = append(dbs, r[name])
+	}
+	return dbs
+}
+
+// selectRows selects all rows of the tables of the database in a single
+// transaction, and stores the rows of each table in the slice of structs
+// pointed to by the matching element of results, see Result.Unmarshal. The
+// rows which cannot be decoded are skipped, or fail the call, depending on
+// the decode mode of the connection.
+func (db *OvsDatabase) selectRows(ctx context.Context, tables []string, results ...interface{}) error {
+	if db.Client == nil {
+		return fmt.Errorf("%s: client was not initialized: %w", db.Name, ErrNotConnected)
+	}
+	ops := make([]Operation, 0, len(tables))
+	for _, table := range tables {
+		ops = append(ops, Select(table, nil))
+	}
+	rows, err := db.Client.TransactOperations(ctx, db.Name, ops...)
+	if err != nil {
+		return fmt.Errorf("%s: %v table error: %s", db.Name, tables, err)
+	}
+	for i, table := range tables {
+		r := Result{Rows: rows[i].Rows, Database: db.Name, Table: table}
+		if err := r.unmarshal(results[i], db.Client.DecodeMode == DecodeStrict, func(e *columnError) error {
+			return db.Client.skipRow(r, e.column, e.err)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
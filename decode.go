@@ -0,0 +1,140 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DecodeMode selects how a client handles the rows it cannot decode, e.g.
+// as a column is missing, or holds a value of an unexpected data type.
+type DecodeMode int
+
+const (
+	// DecodeLenient skips such rows, logs them, and records a warning, see
+	// Client.DecodeWarnings. It suits the deployments mixing schema
+	// versions, and is the default.
+	DecodeLenient DecodeMode = iota
+	// DecodeStrict fails the call with a *DecodeError instead. It suits
+	// the tests.
+	DecodeStrict
+)
+
+// maxDecodeWarnings is the number of warnings a client keeps. The older
+// ones are dropped.
+const maxDecodeWarnings = 100
+
+// WithDecodeMode sets the decode mode of a client.
+func WithDecodeMode(mode DecodeMode) ClientOption {
+	return func(cli *Client) error {
+		if mode != DecodeLenient && mode != DecodeStrict {
+			return fmt.Errorf("invalid decode mode: %d", mode)
+		}
+		cli.DecodeMode = mode
+		return nil
+	}
+}
+
+// DecodeError describes a row of a table which could not be decoded,
+// because of one of its columns.
+type DecodeError struct {
+	Database string
+	Table    string
+	Column   string
+	Err      error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: '%s' table: '%s' column: %v", e.Database, e.Table, e.Column, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// columnError is an error decoding a column of a row.
+type columnError struct {
+	column string
+	err    error
+}
+
+func (e *columnError) Error() string {
+	return fmt.Sprintf("column %s: %s", e.column, e.err)
+}
+
+// decodeLog holds the warnings recorded by a client in the lenient mode.
+type decodeLog struct {
+	mux      sync.Mutex
+	warnings []DecodeError
+}
+
+// DecodeWarnings returns the rows skipped in the lenient mode since the
+// previous call, oldest first, and forgets them. At most the last 100 are
+// kept.
+func (cli *Client) DecodeWarnings() []DecodeError {
+	if cli == nil || cli.decodeLog == nil {
+		return nil
+	}
+	cli.decodeLog.mux.Lock()
+	defer cli.decodeLog.mux.Unlock()
+	warnings := cli.decodeLog.warnings
+	cli.decodeLog.warnings = nil
+	return warnings
+}
+
+// skipRow handles a row of the result which could not be decoded, because
+// of the column. In the strict mode, it returns the *DecodeError the call
+// fails with. Otherwise, it logs the row, records a warning, and returns
+// nil, so that the row is skipped.
+func (cli *Client) skipRow(result Result, column string, reason error) error {
+	e := DecodeError{Database: result.Database, Table: result.Table, Column: column, Err: reason}
+	if cli != nil && cli.DecodeMode == DecodeStrict {
+		return &e
+	}
+	logSkippedRow(cli.logger(), result, column, reason)
+	if cli == nil || cli.decodeLog == nil {
+		return nil
+	}
+	cli.decodeLog.mux.Lock()
+	defer cli.decodeLog.mux.Unlock()
+	if len(cli.decodeLog.warnings) == maxDecodeWarnings {
+		cli.decodeLog.warnings = cli.decodeLog.warnings[1:]
+	}
+	cli.decodeLog.warnings = append(cli.decodeLog.warnings, e)
+	return nil
+}
+
+// skipRow handles a row of the result which could not be decoded, in the
+// decode mode of the connection to its database.
+func (cli *OvnClient) skipRow(result Result, column string, reason error) error {
+	db := cli.registered.lookup(result.Database, cli.builtin()...)
+	if db == nil || db.Client == nil {
+		logSkippedRow(cli.logger(), result, column, reason)
+		return nil
+	}
+	return db.Client.skipRow(result, column, reason)
+}
+
+// skipRow handles a row of the result which could not be decoded, in the
+// decode mode of the connection to its database.
+func (cli *OvsClient) skipRow(result Result, column string, reason error) error {
+	db := cli.registered.lookup(result.Database, &cli.Database.Vswitch)
+	if db == nil || db.Client == nil {
+		logSkippedRow(cli.logger(), result, column, reason)
+		return nil
+	}
+	return db.Client.skipRow(result, column, reason)
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestClientDecodeMode(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "transact":
+			rows := []interface{}{
+				map[string]interface{}{
+					"_uuid": []interface{}{"uuid", "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"},
+					"name":  "br0",
+				},
+				map[string]interface{}{
+					"_uuid": []interface{}{"uuid", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"},
+					"name":  float64(1),
+				},
+			}
+			return []interface{}{map[string]interface{}{"rows": rows}}
+		}
+		return nil
+	})
+
+	lenient, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer lenient.Close()
+	rows, err := lenient.DumpTable("Open_vSwitch", "Bridge")
+	if err != nil || len(rows) != 1 || rows[0]["name"] != "br0" {
+		t.Fatalf("FAIL: expected the valid row only, got: %v, %v", rows, err)
+	}
+	warnings := lenient.DecodeWarnings()
+	if len(warnings) != 1 || warnings[0].Table != "Bridge" || warnings[0].Column != "name" {
+		t.Fatalf("FAIL: expected a warning for the name column, got: %v", warnings)
+	}
+	if warnings := lenient.DecodeWarnings(); len(warnings) != 0 {
+		t.Fatalf("FAIL: expected the warnings to be cleared, got: %v", warnings)
+	}
+	t.Logf("PASS: lenient mode skipped the row: %v", &warnings[0])
+
+	strict, err := NewClient("tcp:"+l.Addr().String(), 1, WithDecodeMode(DecodeStrict))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer strict.Close()
+	var decodeErr *DecodeError
+	if _, err = strict.DumpTable("Open_vSwitch", "Bridge"); !errors.As(err, &decodeErr) || decodeErr.Column != "name" {
+		t.Fatalf("FAIL: expected a decode error for the name column, got: %v", err)
+	}
+	if warnings := strict.DecodeWarnings(); len(warnings) != 0 {
+		t.Fatalf("FAIL: expected no warnings in the strict mode, got: %v", warnings)
+	}
+	t.Logf("PASS: strict mode failed: %v", err)
+
+	if _, err := NewClient("tcp:"+l.Addr().String(), 1, WithDecodeMode(DecodeMode(5))); err == nil {
+		t.Fatalf("FAIL: expected an invalid decode mode to fail")
+	}
+}
+
+func TestClientDecodeModeMissingColumns(t *testing.T) {
+	type bridge struct {
+		UUID UUID   `ovsdb:"_uuid"`
+		Name string `ovsdb:"name"`
+	}
+	result := Result{
+		Database: "Open_vSwitch",
+		Table:    "Bridge",
+		Rows: []Row{
+			{"_uuid": []interface{}{"uuid", "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"}, "name": "br0"},
+			{"_uuid": []interface{}{"uuid", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"}},
+		},
+	}
+	for _, mode := range []DecodeMode{DecodeLenient, DecodeStrict} {
+		cli := &Client{DecodeMode: mode, decodeLog: &decodeLog{}}
+		var bridges []bridge
+		err := result.unmarshal(&bridges, mode == DecodeStrict, func(e *columnError) error {
+			return cli.skipRow(result, e.column, e.err)
+		})
+		switch mode {
+		case DecodeLenient:
+			if err != nil || len(bridges) != 2 || bridges[1].Name != "" || len(cli.DecodeWarnings()) != 0 {
+				t.Fatalf("FAIL: expected the row missing the name to be kept, got: %v, %v", bridges, err)
+			}
+		case DecodeStrict:
+			if !errors.Is(err, ErrColumnNotFound) {
+				t.Fatalf("FAIL: expected ErrColumnNotFound, got: %v", err)
+			}
+		}
+	}
+
+	row := result.Rows[1]
+	if _, _, err := row.GetColumnValue("name", nil); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("FAIL: expected ErrColumnNotFound, got: %v", err)
+	}
+	t.Logf("PASS: missing columns handled in both modes")
+}
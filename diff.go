@@ -0,0 +1,187 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ResultDiff is the difference between two results of the same query,
+// e.g. between two scrapes of an exporter.
+type ResultDiff struct {
+	// Added are the rows of the new result only.
+	Added []Row
+	// Removed are the rows of the old result only.
+	Removed []Row
+	// Changed are the rows of both results whose columns differ.
+	Changed []RowChange
+}
+
+// RowChange is a row of both results whose columns differ.
+type RowChange struct {
+	// Key is the value of the key column of the row.
+	Key interface{}
+	Old Row
+	New Row
+	// Columns are the columns which differ, including the columns of one of
+	// the rows only.
+	Columns map[string]ColumnChange
+}
+
+// ColumnChange is the old and the new value of a column. The value of a
+// column missing from a row is nil.
+type ColumnChange struct {
+	Old interface{}
+	New interface{}
+}
+
+// Empty returns true when the results do not differ.
+func (d *ResultDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffResults compares the rows of two results, matched by the value of the
+// key column, e.g. _uuid or name, which must be an atom. The sets are
+// compared regardless of the order of their elements, and the maps of their
+// pairs. The rows of each list of the difference are sorted by key.
+func DiffResults(before, after Result, keyColumn string) (ResultDiff, error) {
+	var diff ResultDiff
+	oldRows, oldKeys, err := keyRows(before, keyColumn)
+	if err != nil {
+		return diff, fmt.Errorf("diff: old result: %s", err)
+	}
+	newRows, newKeys, err := keyRows(after, keyColumn)
+	if err != nil {
+		return diff, fmt.Errorf("diff: new result: %s", err)
+	}
+	for _, key := range oldKeys {
+		if _, exists := newRows[key]; !exists {
+			diff.Removed = append(diff.Removed, oldRows[key])
+		}
+	}
+	for _, key := range newKeys {
+		newRow := newRows[key]
+		oldRow, exists := oldRows[key]
+		if !exists {
+			diff.Added = append(diff.Added, newRow)
+			continue
+		}
+		if columns := diffRow(oldRow, newRow); len(columns) > 0 {
+			diff.Changed = append(diff.Changed, RowChange{Key: key, Old: oldRow, New: newRow, Columns: columns})
+		}
+	}
+	return diff, nil
+}
+
+// keyRows returns the rows of the result by key, and the keys in order.
+func keyRows(r Result, keyColumn string) (map[interface{}]Row, []interface{}, error) {
+	rows := make(map[interface{}]Row, len(r.Rows))
+	keys := make([]interface{}, 0, len(r.Rows))
+	for i, row := range r.Rows {
+		data, exists := row[keyColumn]
+		if !exists {
+			return nil, nil, fmt.Errorf("row %d: column %s: %s", i, keyColumn, ErrColumnNotFound)
+		}
+		if kind := compositeKind(data); kind != "" {
+			return nil, nil, fmt.Errorf("row %d: column %s is a %s, the key column must be an atom", i, keyColumn, kind)
+		}
+		key := sortKey(data)
+		if key == nil {
+			return nil, nil, fmt.Errorf("row %d: column %s is empty", i, keyColumn)
+		}
+		if _, exists := rows[key]; exists {
+			return nil, nil, fmt.Errorf("row %d: duplicate key %v", i, key)
+		}
+		rows[key] = row
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return compareAtoms(keys[i], keys[j]) < 0
+	})
+	return rows, keys, nil
+}
+
+// compositeKind returns "set" when the value is a set of several elements,
+// and "map" when it is a map, either of which cannot key the rows, or "".
+// The sets of a single element are sent as the element itself.
+func compositeKind(data interface{}) string {
+	v, ok := data.([]interface{})
+	if !ok || len(v) != 2 {
+		return ""
+	}
+	switch v[0] {
+	case "set":
+		if elements, ok := v[1].([]interface{}); ok && len(elements) > 1 {
+			return "set"
+		}
+	case "map":
+		return "map"
+	}
+	return ""
+}
+
+// diffRow returns the columns of the rows which differ.
+func diffRow(oldRow, newRow Row) map[string]ColumnChange {
+	columns := make(map[string]ColumnChange)
+	for column, oldValue := range oldRow {
+		newValue, exists := newRow[column]
+		if !exists || !equalValues(oldValue, newValue) {
+			columns[column] = ColumnChange{Old: oldValue, New: newValue}
+		}
+	}
+	for column, newValue := range newRow {
+		if _, exists := oldRow[column]; !exists {
+			columns[column] = ColumnChange{New: newValue}
+		}
+	}
+	return columns
+}
+
+// equalValues returns true when the values, in the OVSDB notation, are
+// equal, i.e. the same atom, set, or map.
+func equalValues(a, b interface{}) bool {
+	if ma, err := newOvsMap(a); err == nil {
+		mb, err := newOvsMap(b)
+		if err != nil || len(ma) != len(mb) {
+			return false
+		}
+		for k, v := range ma {
+			if w, exists := mb[k]; !exists || w != v {
+				return false
+			}
+		}
+		return true
+	}
+	sa, err := newOvsSet(a)
+	if err != nil {
+		return false
+	}
+	sb, err := newOvsSet(b)
+	if err != nil || len(sa) != len(sb) {
+		return false
+	}
+	count := make(map[interface{}]int, len(sa))
+	for _, elem := range sa {
+		count[elem]++
+	}
+	for _, elem := range sb {
+		if count[elem] == 0 {
+			return false
+		}
+		count[elem]--
+	}
+	return true
+}
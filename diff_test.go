@@ -0,0 +1,86 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	decode := func(data string) Result {
+		var r Result
+		if err := json.Unmarshal([]byte(data), &r); err != nil {
+			t.Fatalf("FAIL: failed to decode the result: %v", err)
+		}
+		return r
+	}
+	before := decode(`{"rows":[
+		{"name":"hv1","encaps":["set",[["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"],["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b2"]]],"nb_cfg":1,"external_ids":["map",[["a","1"],["b","2"]]]},
+		{"name":"hv2","encaps":["set",[]],"nb_cfg":1,"external_ids":["map",[]]},
+		{"name":"hv3","encaps":["set",[]],"nb_cfg":1,"external_ids":["map",[]]}
+	]}`)
+	after := decode(`{"rows":[
+		{"name":"hv4","encaps":["set",[]],"nb_cfg":1,"external_ids":["map",[]]},
+		{"name":"hv1","encaps":["set",[["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b2"],["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"]]],"nb_cfg":1,"external_ids":["map",[["b","2"],["a","1"]]]},
+		{"name":"hv2","encaps":["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b3"],"nb_cfg":2,"external_ids":["map",[]],"hostname":"node2"}
+	]}`)
+	diff, err := DiffResults(before, after, "name")
+	if err != nil {
+		t.Fatalf("FAIL: failed to diff the results: %v", err)
+	}
+	if len(diff.Added) != 1 || diff.Added[0]["name"] != "hv4" {
+		t.Fatalf("FAIL: expected hv4 to be added, got %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0]["name"] != "hv3" {
+		t.Fatalf("FAIL: expected hv3 to be removed, got %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Key != "hv2" {
+		t.Fatalf("FAIL: expected hv2 only to change, got %+v", diff.Changed)
+	}
+	columns := diff.Changed[0].Columns
+	if len(columns) != 3 || columns["nb_cfg"].New != 2.0 || columns["hostname"].Old != nil || columns["hostname"].New != "node2" {
+		t.Fatalf("FAIL: unexpected column changes: %+v", columns)
+	}
+	if _, exists := columns["encaps"]; !exists {
+		t.Fatalf("FAIL: expected encaps to change, got %+v", columns)
+	}
+	t.Logf("PASS: %d added, %d removed, %d changed", len(diff.Added), len(diff.Removed), len(diff.Changed))
+
+	if diff, err := DiffResults(before, before, "name"); err != nil || !diff.Empty() {
+		t.Fatalf("FAIL: expected no difference, got %+v, %v", diff, err)
+	}
+	t.Logf("PASS: no difference between identical results")
+
+	for _, test := range []struct {
+		r   Result
+		key string
+	}{
+		{before, "hostname"},
+		{decode(`{"rows":[{"name":"hv1"},{"name":"hv1"}]}`), "name"},
+		{decode(`{"rows":[{"name":["set",[]]}]}`), "name"},
+		{before, "encaps"},
+		{before, "external_ids"},
+		{decode(`{"rows":[{"name":["set",["a","b"]]},{"name":["set",["a","c"]]}]}`), "name"},
+	} {
+		if _, err := DiffResults(test.r, after, test.key); err == nil {
+			t.Fatalf("FAIL: expected an error for key %s of %v", test.key, test.r.Rows)
+		}
+		if _, err := DiffResults(before, test.r, test.key); err == nil {
+			t.Fatalf("FAIL: expected an error for key %s of %v", test.key, test.r.Rows)
+		}
+	}
+	t.Logf("PASS: rejected missing, duplicate, empty, and composite keys")
+}
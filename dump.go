@@ -0,0 +1,134 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// DumpTable returns all rows of the table, with the values of their columns
+// decoded to the Go types of the columns in the schema of the database: the
+// scalars to string, int64, float64, bool, or UUID, the optional values to
+// those types, or nil when they are empty, the sets to slices, and the maps
+// to maps of those types, e.g. []UUID for Bridge.ports, and
+// map[string]string for external_ids. In the strict decode mode, a row which
+// cannot be decoded, or has a column missing from the schema, fails the
+// call. Otherwise, the row is skipped, and the column kept as it is. The
+// columns with converters, see WithConverters, are converted with them.
+func (c *Client) DumpTable(db, table string) ([]map[string]interface{}, error) {
+	return c.DumpTableContext(context.Background(), db, table)
+}
+
+// DumpTableContext is like DumpTable, but honors the context.
+func (c *Client) DumpTableContext(ctx context.Context, db, table string) ([]map[string]interface{}, error) {
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	t := schema.Table(table)
+	if t == nil {
+		return nil, fmt.Errorf("dump table: %w", &tableNotFoundError{table})
+	}
+	results, err := c.TransactOperations(ctx, db, Select(table, nil))
+	if err != nil {
+		return nil, err
+	}
+	r := Result{Rows: results[0].Rows, Database: db, Table: table}
+	converters := c.Converters.table(table)
+	rows := make([]map[string]interface{}, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		decoded, err := t.decodeRow(row, c.DecodeMode == DecodeStrict, converters)
+		var ce *columnError
+		if errors.As(err, &ce) {
+			if err := c.skipRow(r, ce.column, ce.err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		rows = append(rows, decoded)
+	}
+	return rows, nil
+}
+
+// decodeRow decodes the values of the columns of the row, see DumpTable.
+// The columns missing from the table, or all of them when the table is
+// nil, are kept as they are, unless strict. The columns with converters are
+// converted with them instead.
+func (t *Table) decodeRow(row Row, strict bool, converters map[string]Converter) (map[string]interface{}, error) {
+	decoded := make(map[string]interface{}, len(row))
+	for name, data := range row {
+		if conv := converters[name]; conv != nil {
+			v, err := conv(data)
+			if err != nil {
+				return nil, &columnError{name, err}
+			}
+			decoded[name] = v
+			continue
+		}
+		column := t.Column(name)
+		if column == nil {
+			if strict {
+				return nil, &columnError{name, fmt.Errorf("not in the schema")}
+			}
+			decoded[name] = data
+			continue
+		}
+		v := reflect.New(column.goType()).Elem()
+		if err := decodeColumn(v, data); err != nil {
+			return nil, &columnError{name, err}
+		}
+		if column.IsOptional() {
+			if v.IsNil() {
+				decoded[name] = nil
+				continue
+			}
+			v = v.Elem()
+		}
+		decoded[name] = v.Interface()
+	}
+	return decoded, nil
+}
+
+// goType returns the Go type of the values of the column. An optional value
+// is a pointer, nil when it is empty.
+func (c *Column) goType() reflect.Type {
+	key := c.Key.goType()
+	switch {
+	case c.IsMap():
+		return reflect.MapOf(key, c.Value.goType())
+	case c.IsOptional():
+		return reflect.PtrTo(key)
+	case c.IsSet():
+		return reflect.SliceOf(key)
+	}
+	return key
+}
+
+func (bt *BaseType) goType() reflect.Type {
+	switch bt.Type {
+	case "integer":
+		return reflect.TypeOf(int64(0))
+	case "real":
+		return reflect.TypeOf(float64(0))
+	case "boolean":
+		return reflect.TypeOf(false)
+	case "uuid":
+		return uuidType
+	}
+	return reflect.TypeOf("")
+}
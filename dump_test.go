@@ -0,0 +1,80 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestDumpTable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "transact":
+			rows := []interface{}{
+				map[string]interface{}{
+					"_uuid":        []interface{}{"uuid", "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"},
+					"name":         "br0",
+					"ports":        []interface{}{"uuid", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"},
+					"mirrors":      []interface{}{"set", []interface{}{}},
+					"external_ids": []interface{}{"map", []interface{}{[]interface{}{"owner", "test"}}},
+					"datapath_id":  "0000a6f4b2d2c346",
+					"fail_mode":    []interface{}{"set", []interface{}{}},
+					"flood_vlans":  []interface{}{"set", []interface{}{float64(10), float64(20)}},
+				},
+			}
+			return []interface{}{map[string]interface{}{"rows": rows}}
+		}
+		return nil
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	rows, err := cli.DumpTable("Open_vSwitch", "Bridge")
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("FAIL: expected a row, got: %v, %v", rows, err)
+	}
+	expected := map[string]interface{}{
+		"_uuid":        UUID("36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"),
+		"name":         "br0",
+		"ports":        []UUID{"5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"},
+		"mirrors":      []UUID{},
+		"external_ids": map[string]string{"owner": "test"},
+		"datapath_id":  "0000a6f4b2d2c346",
+		"fail_mode":    nil,
+		"flood_vlans":  []int64{10, 20},
+	}
+	if !reflect.DeepEqual(rows[0], expected) {
+		t.Fatalf("FAIL: expected row %#v, got %#v", expected, rows[0])
+	}
+	t.Logf("PASS: dumped row: %v", rows[0])
+
+	if _, err = cli.DumpTable("Open_vSwitch", "Missing"); !errors.Is(err, ErrTableNotFound) {
+		t.Fatalf("FAIL: expected ErrTableNotFound, got: %v", err)
+	}
+	t.Logf("PASS: %v", err)
+}
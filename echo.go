@@ -15,20 +15,27 @@
 package ovsdb
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Echo - TODO
 func (c *Client) Echo(s string) error {
+	return c.EchoContext(context.Background(), s)
+}
+
+// EchoContext is like Echo, but honors the context.
+func (c *Client) EchoContext(ctx context.Context, s string) error {
 	method := "echo"
 	js, err := encodeString(s)
 	if err != nil {
 		_ = fmt.Errorf("'%s' method failed: %v", method, err)
 	}
-	response, err := c.query(method, js)
+	response, err := c.queryContext(ctx, method, js)
 	if err != nil {
-		return fmt.Errorf("'%s' method failed: %v", method, err)
+		return fmt.Errorf("'%s' method failed: %w", method, err)
 	}
 	if err := matchRequestResponse(s, response); err != nil {
 		return fmt.Errorf("'%s' method failed: %v", method, err)
@@ -36,6 +43,14 @@ func (c *Client) Echo(s string) error {
 	return nil
 }
 
+// EchoWithTimeout is like Echo, but fails when the server does not answer
+// within the timeout.
+func (c *Client) EchoWithTimeout(s string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return c.EchoContext(ctx, s)
+}
+
 func matchRequestResponse(req string, resp *Response) error {
 	var respBody []string
 	if err := json.Unmarshal(resp.Result, &respBody); err != nil {
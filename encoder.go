@@ -30,7 +30,17 @@ type method struct {
 var methods = map[string]method{
 	"echo":                 {Name: "echo"},
 	"list_dbs":             {Name: "list_dbs"},
+	"get_server_id":        {Name: "get_server_id"},
 	"get_schema":           {Name: "get_schema"},
+	"convert":              {Name: "convert"},
+	"lock":                 {Name: "lock"},
+	"steal":                {Name: "steal"},
+	"unlock":               {Name: "unlock"},
+	"monitor":              {Name: "monitor"},
+	"monitor_cond":         {Name: "monitor_cond"},
+	"monitor_cond_since":   {Name: "monitor_cond_since"},
+	"monitor_cond_change":  {Name: "monitor_cond_change"},
+	"monitor_cancel":       {Name: "monitor_cancel"},
 	"transact":             {Name: "transact"},
 	"list-commands":        {Name: "list-commands"},
 	"version":              {Name: "version"},
@@ -95,12 +105,13 @@ func (enc *ovsdbEncoder) Encode(v interface{}) error {
 		switch r.Method {
 		case "list_dbs":
 			e.WriteString("[]")
+		case "get_server_id":
 		case "echo":
 			if r.ID != 0 {
 				s := r.Params[0].(string)
 				e.WriteString(s)
 			}
-		case "get_schema":
+		case "get_schema", "lock", "steal", "unlock", "convert", "monitor", "monitor_cond", "monitor_cond_since", "monitor_cond_change", "monitor_cancel":
 			s := r.Params[0].(string)
 			e.WriteString(s)
 		case "transact":
@@ -15,9 +15,52 @@
 package ovsdb
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 )
 
+var (
+	// ErrTimeout is returned when a request does not complete before the
+	// deadline of its context, or the request timeout of the client.
+	ErrTimeout = errors.New("request timed out")
+	// ErrNotConnected is returned when a client cannot establish a
+	// connection to any of its remotes.
+	ErrNotConnected = errors.New("not connected")
+	// ErrTableNotFound is returned when a table does not exist in a
+	// database.
+	ErrTableNotFound = errors.New("table not found")
+	// ErrColumnNotFound is returned when a row does not contain a column.
+	ErrColumnNotFound = errors.New("column not found")
+	// ErrResponseTooLarge is returned when a message of the server
+	// exceeds the maximum response size of the client.
+	ErrResponseTooLarge = errors.New("response too large")
+	// ErrCircuitOpen is returned when the circuit breaker of a client
+	// stopped sending requests after repeated failures.
+	ErrCircuitOpen = errors.New("circuit breaker open")
+	// ErrClosing is returned for the requests issued after a client
+	// started shutting down.
+	ErrClosing = errors.New("client is closing")
+	// ErrConflict is returned when a wait operation of a transaction, e.g.
+	// the verification of a row, fails, because the row changed.
+	ErrConflict = errors.New("conflicting update")
+	// ErrNotLeader is returned when the member of a clustered database a
+	// transaction was sent to is not, or is no longer, the leader, e.g.
+	// during an election.
+	ErrNotLeader = errors.New("not leader")
+	// ErrResourcesExhausted is returned when the server lacks the
+	// resources to execute a transaction.
+	ErrResourcesExhausted = errors.New("resources exhausted")
+	// ErrNotOwner is returned when an assert operation of a transaction
+	// fails, because the client does not hold the lock.
+	ErrNotOwner = errors.New("not owner")
+	// ErrConstraintViolation is returned when a transaction violates a
+	// constraint of the schema, e.g. a value outside of an enum.
+	ErrConstraintViolation = errors.New("constraint violation")
+)
+
 // Error - TODO
 type Error struct {
 	Message string `json:"error"`
@@ -38,3 +81,165 @@ func (e *Error) String() string {
 	}
 	return s.String()
 }
+
+// OvsdbError is an error object returned by the server, see RFC 7047,
+// Section 3.1. Use errors.As to retrieve it from the errors of a client.
+type OvsdbError Error
+
+func (e *OvsdbError) Error() string {
+	return (*Error)(e).String()
+}
+
+// Is reports whether the error object is the server-side counterpart of
+// the target, e.g. "unknown table" for ErrTableNotFound.
+func (e *OvsdbError) Is(target error) bool {
+	switch target {
+	case ErrTableNotFound:
+		return e.Message == "unknown table"
+	case ErrConflict:
+		return e.Message == "timed out"
+	case ErrNotLeader:
+		return e.Message == "not leader"
+	case ErrResourcesExhausted:
+		return e.Message == "resources exhausted"
+	case ErrNotOwner:
+		return e.Message == "not owner"
+	case ErrConstraintViolation:
+		return e.Message == "constraint violation"
+	}
+	return false
+}
+
+// Transient returns true when the error is likely to go away when the
+// transaction is retried: a timed out transaction, a leadership transfer,
+// or exhausted resources.
+func (e *OvsdbError) Transient() bool {
+	switch e.Message {
+	case "timed out", "not leader", "resources exhausted":
+		return true
+	}
+	return false
+}
+
+// IsTransient returns true when the error, e.g. of a transaction, is a
+// transient error of the server, see OvsdbError.Transient.
+func IsTransient(err error) bool {
+	var e *OvsdbError
+	return errors.As(err, &e) && e.Transient()
+}
+
+// TransactionError is returned when an operation of a transaction, or its
+// commit, fails. None of the operations take effect then, but the results
+// of those preceding the failed one tell what it was applied to, e.g. the
+// rows selected before a failed update.
+type TransactionError struct {
+	Database string
+	// Index is the index of the operation which failed, or -1 when the
+	// commit failed, e.g. as a constraint of the schema was violated.
+	Index     int
+	Operation Operation
+	// Err is the error of the server, with its error and details strings.
+	Err *OvsdbError
+	// Results are the results of the operations preceding the failed one,
+	// or of all of them when the commit failed.
+	Results []OperationResult
+}
+
+func (e *TransactionError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("'transact' method failed: %s", e.Err)
+	}
+	return fmt.Sprintf("'transact' method, %s operation %d on %s failed: %s", e.Operation.Name, e.Index, e.Operation.Table, e.Err)
+}
+
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
+
+// SubqueryError describes a sub-query of a function which gathers data
+// with several queries, e.g. GetSystemInfo, that failed or, as the context
+// was done before it was issued, was skipped.
+type SubqueryError struct {
+	Query   string
+	Skipped bool
+	Err     error
+}
+
+func (e *SubqueryError) Error() string {
+	if e.Skipped {
+		return fmt.Sprintf("the '%s' query was skipped: %s", e.Query, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *SubqueryError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError is returned, together with the data gathered, by the functions
+// of a client in the partial results mode when some of their sub-queries
+// failed or were skipped.
+type MultiError struct {
+	Errors []*SubqueryError
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d of the queries failed or were skipped: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// newOvsdbError converts the "error" member of a JSON-RPC response.
+func newOvsdbError(v interface{}) *OvsdbError {
+	e := &OvsdbError{}
+	switch x := v.(type) {
+	case string:
+		e.Message = x
+	case map[string]interface{}:
+		b, _ := json.Marshal(x)
+		if err := json.Unmarshal(b, e); err != nil {
+			e.Message = fmt.Sprintf("invalid error %v", v)
+		}
+	default:
+		if v != nil {
+			e.Message = fmt.Sprintf("invalid error %v", v)
+		}
+	}
+	if e.Message == "" {
+		e.Message = "unspecified error"
+	}
+	return e
+}
+
+// tableNotFoundError is returned when a table is not in a schema.
+type tableNotFoundError struct {
+	table string
+}
+
+func (e *tableNotFoundError) Error() string {
+	return fmt.Sprintf("Table %s not found", e.table)
+}
+
+func (e *tableNotFoundError) Is(target error) bool {
+	return target == ErrTableNotFound
+}
+
+// contextError returns the error of a done context. An expired deadline is
+// reported as ErrTimeout.
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
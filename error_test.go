@@ -0,0 +1,114 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClientErrors(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return testServerError{map[string]interface{}{
+				"error":   "unknown database",
+				"details": "get_schema request specifies unknown database Foo",
+			}}
+		case "transact":
+			return []interface{}{map[string]interface{}{
+				"error":   "unknown table",
+				"details": "No table named Foo.",
+			}}
+		}
+		return nil
+	})
+	cli, err := NewClient("unix:"+sock, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	_, err = cli.GetSchema("Foo")
+	var ovsdbErr *OvsdbError
+	if !errors.As(err, &ovsdbErr) || ovsdbErr.Message != "unknown database" {
+		t.Fatalf("FAIL: expected unknown database error object, but got: %v", err)
+	}
+	t.Logf("PASS: error object of the response header: %v", err)
+
+	_, err = cli.Transact("Foo", "SELECT * FROM Foo")
+	if !errors.Is(err, ErrTableNotFound) || !errors.As(err, &ovsdbErr) {
+		t.Fatalf("FAIL: expected table not found error, but got: %v", err)
+	}
+	if ovsdbErr.Details != "No table named Foo." {
+		t.Fatalf("FAIL: unexpected details of the error object: %v", ovsdbErr.Details)
+	}
+	t.Logf("PASS: error object of the response body: %v", err)
+
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: expected the connection to survive the errors, but echo failed with: %v", err)
+	}
+
+	schema := Schema{Tables: map[string]Table{}}
+	if _, err := schema.GetColumnType("Foo", "name"); !errors.Is(err, ErrTableNotFound) {
+		t.Fatalf("FAIL: expected table not found error, but got: %v", err)
+	}
+	t.Logf("PASS: table missing from the schema: %v", err)
+}
+
+func TestClientTimeoutError(t *testing.T) {
+	sock := newSilentServer(t)
+	cli, err := NewClient(sock, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	err = cli.EchoWithTimeout("test message", 10*time.Millisecond)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("FAIL: expected timeout error, but got: %v", err)
+	}
+	t.Logf("PASS: timeout error: %v", err)
+}
+
+func TestClientNotConnectedError(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	newTestServer(t, l, nil)
+	cli, err := NewClient("unix:"+sock, 1, WithReconnectPolicy(ReconnectPolicy{MaxRetries: 1}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	l.Close()
+	cli.Failover()
+	err = cli.Echo("test message")
+	if !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("FAIL: expected not connected error, but got: %v", err)
+	}
+	t.Logf("PASS: not connected error: %v", err)
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// EventHandler handles the changes of the rows of a table of a Cache, e.g.
+// to drive a reconciler. The functions, any of which may be nil, receive
+// the rows of the cache, which hold the _uuid column, and must not modify
+// them.
+type EventHandler struct {
+	// OnAdd is called for the rows inserted.
+	OnAdd func(table string, row Row)
+	// OnUpdate is called for the rows modified, with the row before, and
+	// after, the change.
+	OnUpdate func(table string, old, new Row)
+	// OnDelete is called for the rows deleted, with the row before the
+	// deletion.
+	OnDelete func(table string, row Row)
+	// Debounce, when set, coalesces the changes of a row made within the
+	// window into a single call, with the row before the first change and
+	// after the last one, e.g. to weather the churn of MAC_Binding. A row
+	// inserted, then deleted, within the window is not reported. The calls
+	// are made, in order, by a goroutine of their own, once the window of
+	// the first change pending elapses.
+	Debounce time.Duration
+}
+
+// handle calls the function of the change.
+func (h EventHandler) handle(e cacheEvent) {
+	switch {
+	case e.old == nil && h.OnAdd != nil:
+		h.OnAdd(e.table, e.new)
+	case e.new == nil && h.OnDelete != nil:
+		h.OnDelete(e.table, e.old)
+	case e.old != nil && e.new != nil && h.OnUpdate != nil:
+		h.OnUpdate(e.table, e.old, e.new)
+	}
+}
+
+// eventHandler is a registered handler, and its debouncer, if any.
+type eventHandler struct {
+	EventHandler
+	debouncer *debouncer
+}
+
+// cacheEvent is the change of a row of the cache.
+type cacheEvent struct {
+	table    string
+	uuid     UUID
+	old, new Row
+}
+
+// RegisterHandler registers the handler of the changes of the rows of the
+// table, or of all the cached tables when the table is empty. It calls
+// OnAdd for the rows the cache holds first, so that the handler observes
+// every row. The handlers are called in order, by the goroutine keeping
+// the cache up to date, once the cache holds the change, and must neither
+// block, nor register handlers.
+func (c *Cache) RegisterHandler(table string, h EventHandler) error {
+	c.events.Lock()
+	defer c.events.Unlock()
+	if table != "" {
+		c.mux.RLock()
+		_, err := c.table(table)
+		c.mux.RUnlock()
+		if err != nil {
+			return err
+		}
+	}
+	if h.OnAdd != nil {
+		c.mux.RLock()
+		var events []cacheEvent
+		for name, t := range c.tables {
+			if table != "" && name != table {
+				continue
+			}
+			for uuid, row := range t.rows {
+				events = append(events, cacheEvent{table: name, uuid: uuid, new: row})
+			}
+		}
+		c.mux.RUnlock()
+		sortEvents(events)
+		for _, e := range events {
+			h.OnAdd(e.table, e.new)
+		}
+	}
+	eh := &eventHandler{EventHandler: h}
+	if h.Debounce > 0 {
+		eh.debouncer = &debouncer{handler: h, pending: make(map[eventKey]*cacheEvent)}
+	}
+	c.handlers[table] = append(c.handlers[table], eh)
+	return nil
+}
+
+// notify hands the events over to the handlers of their tables, then to
+// those of all the tables. The events must be held.
+func (c *Cache) notify(events []cacheEvent) {
+	for _, e := range events {
+		for _, table := range []string{e.table, ""} {
+			for _, h := range c.handlers[table] {
+				if h.debouncer != nil {
+					h.debouncer.add(e)
+				} else {
+					h.handle(e)
+				}
+			}
+		}
+	}
+}
+
+// observe registers the function, which receives the events of every
+// update once the handlers have, and returns the function unregistering
+// it. The events must be held for both, and are while the function is
+// called.
+func (c *Cache) observe(fn func(events []cacheEvent)) func() {
+	c.nextObserver++
+	id := c.nextObserver
+	c.observers[id] = fn
+	return func() {
+		delete(c.observers, id)
+	}
+}
+
+// sortEvents orders the events by table and UUID.
+func sortEvents(events []cacheEvent) {
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].table != events[j].table {
+			return events[i].table < events[j].table
+		}
+		return events[i].uuid < events[j].uuid
+	})
+}
+
+// flushEvents makes the debounced handlers handle their pending changes
+// right away.
+func (c *Cache) flushEvents() {
+	c.events.Lock()
+	var debouncers []*debouncer
+	for _, handlers := range c.handlers {
+		for _, h := range handlers {
+			if h.debouncer != nil {
+				debouncers = append(debouncers, h.debouncer)
+			}
+		}
+	}
+	c.events.Unlock()
+	for _, d := range debouncers {
+		d.flush()
+	}
+}
+
+type eventKey struct {
+	table string
+	uuid  UUID
+}
+
+// debouncer coalesces the changes of the rows for a handler, see
+// EventHandler.Debounce.
+type debouncer struct {
+	handler EventHandler
+	// calls is held while the handler is called, so that the changes
+	// flushed are handled in order.
+	calls   sync.Mutex
+	mux     sync.Mutex
+	pending map[eventKey]*cacheEvent
+	timer   *time.Timer
+}
+
+// add merges the change with the pending one of the row, if any, and
+// starts the window, unless it runs.
+func (d *debouncer) add(e cacheEvent) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	key := eventKey{table: e.table, uuid: e.uuid}
+	if p, exists := d.pending[key]; exists {
+		p.new = e.new
+	} else {
+		d.pending[key] = &e
+	}
+	if d.timer == nil {
+		d.timer = time.AfterFunc(d.handler.Debounce, d.flush)
+	}
+}
+
+// flush handles the pending changes.
+func (d *debouncer) flush() {
+	d.calls.Lock()
+	defer d.calls.Unlock()
+	d.mux.Lock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	events := make([]cacheEvent, 0, len(d.pending))
+	for _, e := range d.pending {
+		if e.old != nil || e.new != nil {
+			events = append(events, *e)
+		}
+	}
+	d.pending = make(map[eventKey]*cacheEvent)
+	d.mux.Unlock()
+	sortEvents(events)
+	for _, e := range events {
+		d.handler.handle(e)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacheRegisterHandler(t *testing.T) {
+	const br0, br1, br3 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31", "8c5f6e2b-0c2f-4a4a-bd51-7e3067c6b1a4"
+	bridge := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name}
+	}
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": bridge("br0")},
+			br1: map[string]interface{}{"new": bridge("br1")},
+		}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"old": bridge("br0")},
+			br1: map[string]interface{}{"old": bridge("br1"), "new": bridge("br2")},
+			br3: map[string]interface{}{"new": bridge("br3")},
+		}}},
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"name"}})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+
+	if err := cache.RegisterHandler("Port", EventHandler{}); !errors.Is(err, ErrTableNotFound) {
+		t.Fatalf("FAIL: expected the unknown table to fail, got: %v", err)
+	}
+	events := make(chan string, 16)
+	err = cache.RegisterHandler("Bridge", EventHandler{
+		OnAdd: func(table string, row Row) {
+			events <- "add " + row["name"].(string)
+		},
+		OnUpdate: func(table string, old, new Row) {
+			if uuid, _ := new.GetUUID("_uuid"); uuid != br1 {
+				t.Errorf("FAIL: unexpected uuid of the updated row: %v", new)
+			}
+			events <- "update " + old["name"].(string) + " " + new["name"].(string)
+		},
+		OnDelete: func(table string, row Row) {
+			events <- "delete " + row["name"].(string)
+		},
+	})
+	if err != nil {
+		t.Fatalf("FAIL: expected the handler to be registered, but failed with: %v", err)
+	}
+	all := make(chan string, 16)
+	cache.RegisterHandler("", EventHandler{
+		OnDelete: func(table string, row Row) {
+			all <- table + " " + row["name"].(string)
+		},
+	})
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+
+	var got []string
+	for len(got) < 5 {
+		select {
+		case e := <-events:
+			got = append(got, e)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("FAIL: timed out waiting for the events, got: %v", got)
+		}
+	}
+	want := []string{"add br0", "add br1", "delete br0", "update br1 br2", "add br3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FAIL: expected the events %v, got %v", want, got)
+	}
+	if e := <-all; e != "Bridge br0" {
+		t.Fatalf("FAIL: unexpected event of the handler of all tables: %s", e)
+	}
+	t.Logf("PASS: events: %v", got)
+}
+
+func TestCacheDebounce(t *testing.T) {
+	const br0, br1, br3 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31", "8c5f6e2b-0c2f-4a4a-bd51-7e3067c6b1a4"
+	bridge := func(name string) map[string]interface{} {
+		return map[string]interface{}{"name": name}
+	}
+	update := func(rows map[string]interface{}) testServerNotify {
+		return testServerNotify{method: "update", params: map[string]interface{}{"Bridge": rows}}
+	}
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": bridge("br0")},
+			br1: map[string]interface{}{"new": bridge("br1")},
+		}},
+		update(map[string]interface{}{
+			br1: map[string]interface{}{"old": bridge("br1"), "new": bridge("br2")},
+			br3: map[string]interface{}{"new": bridge("br3")},
+		}),
+		update(map[string]interface{}{
+			br1: map[string]interface{}{"old": bridge("br2"), "new": bridge("br4")},
+			br3: map[string]interface{}{"old": bridge("br3")},
+		}),
+		update(map[string]interface{}{
+			br0: map[string]interface{}{"old": bridge("br0")},
+		}),
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewCache(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	events := make(chan string, 16)
+	cache.RegisterHandler("Bridge", EventHandler{
+		OnAdd: func(table string, row Row) {
+			events <- "add " + row["name"].(string)
+		},
+		OnUpdate: func(table string, old, new Row) {
+			events <- "update " + old["name"].(string) + " " + new["name"].(string)
+		},
+		OnDelete: func(table string, row Row) {
+			events <- "delete " + row["name"].(string)
+		},
+		Debounce: time.Hour,
+	})
+	<-events
+	<-events
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("first"))
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("second"))
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if row, _ := cache.Row("Bridge", br1); row["name"] == "br4" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("FAIL: timed out waiting for the updates")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case e := <-events:
+		t.Fatalf("FAIL: unexpected event within the window: %s", e)
+	default:
+	}
+	cache.flushEvents()
+	if e := <-events; e != "update br1 br4" {
+		t.Fatalf("FAIL: expected the coalesced update, got: %s", e)
+	}
+	select {
+	case e := <-events:
+		t.Fatalf("FAIL: unexpected event of the row inserted and deleted: %s", e)
+	default:
+	}
+	t.Logf("PASS: changes coalesced")
+
+	cache.events.Lock()
+	cache.handlers["Bridge"][0].debouncer.handler.Debounce = 10 * time.Millisecond
+	cache.events.Unlock()
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("third"))
+	select {
+	case e := <-events:
+		if e != "delete br0" {
+			t.Fatalf("FAIL: expected the deletion, got: %s", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("FAIL: timed out waiting for the window to elapse")
+	}
+	cache.Close(ctx)
+	t.Logf("PASS: changes handled once the window elapsed")
+}
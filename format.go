@@ -0,0 +1,228 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is an output format of Result.Format.
+type Format int
+
+const (
+	// FormatTable aligns the columns of the rows, under a header, like
+	// ovsdb-client.
+	FormatTable Format = iota
+	// FormatJSON is a JSON array of objects, one per row.
+	FormatJSON
+	// FormatCSV is a header line of the columns, and a line per row.
+	FormatCSV
+)
+
+// Format renders the rows of the result, with the values of their columns
+// decoded per the schema, see DumpTable. In the table and CSV formats, the
+// sets are rendered as [a, b], the maps as {key=value}, and the strings
+// which could be mistaken for other values are quoted, as ovs-vsctl does.
+// In the JSON format, the references are plain strings. The columns are
+// those of the result, or of its rows, sorted, with _uuid first. The values
+// of the columns missing from the schema are rendered as they are.
+func (r *Result) Format(schema Schema, format Format) (string, error) {
+	t := schema.Table(r.Table)
+	columns := r.formatColumns()
+	rows := make([]map[string]interface{}, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		decoded, err := t.decodeRow(row, false, nil)
+		if err != nil {
+			return "", fmt.Errorf("format: %s", err)
+		}
+		rows = append(rows, decoded)
+	}
+	switch format {
+	case FormatTable:
+		return formatTable(columns, rows), nil
+	case FormatJSON:
+		plain := make([]map[string]interface{}, len(rows))
+		for i, row := range rows {
+			plain[i] = make(map[string]interface{}, len(row))
+			for name, v := range row {
+				plain[i][name] = plainValue(v)
+			}
+		}
+		b, err := json.MarshalIndent(plain, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("format: %s", err)
+		}
+		return string(b) + "\n", nil
+	case FormatCSV:
+		var b bytes.Buffer
+		w := csv.NewWriter(&b)
+		if err := w.Write(columns); err != nil {
+			return "", fmt.Errorf("format: %s", err)
+		}
+		for _, row := range rows {
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				record[i] = formatValue(row[column])
+			}
+			if err := w.Write(record); err != nil {
+				return "", fmt.Errorf("format: %s", err)
+			}
+		}
+		w.Flush()
+		return b.String(), w.Error()
+	}
+	return "", fmt.Errorf("format: unsupported format %d", format)
+}
+
+// formatColumns returns the columns of the result, or of its rows, sorted,
+// with _uuid first.
+func (r *Result) formatColumns() []string {
+	set := make(map[string]bool)
+	for column := range r.Columns {
+		set[column] = true
+	}
+	if len(set) == 0 {
+		for _, row := range r.Rows {
+			for column := range row {
+				set[column] = true
+			}
+		}
+	}
+	columns := make([]string, 0, len(set))
+	for column := range set {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if (columns[i] == "_uuid") != (columns[j] == "_uuid") {
+			return columns[i] == "_uuid"
+		}
+		return columns[i] < columns[j]
+	})
+	return columns
+}
+
+// formatTable aligns the values of the columns of the rows under a header.
+func formatTable(columns []string, rows []map[string]interface{}) string {
+	cells := make([][]string, 0, len(rows)+2)
+	cells = append(cells, columns, make([]string, len(columns)))
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = formatValue(row[column])
+		}
+		cells = append(cells, record)
+	}
+	widths := make([]int, len(columns))
+	for _, record := range cells {
+		for i, cell := range record {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	for i, width := range widths {
+		cells[1][i] = strings.Repeat("-", width)
+	}
+	var b strings.Builder
+	for _, record := range cells {
+		for i, cell := range record {
+			if i == len(record)-1 {
+				b.WriteString(cell)
+				continue
+			}
+			fmt.Fprintf(&b, "%-*s ", widths[i], cell)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formatValue renders a decoded value: the sets as [a, b], the maps as
+// {key=value}, sorted by key, and the empty optional values as [].
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "[]"
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		elems := make([]string, rv.Len())
+		for i := range elems {
+			elems[i] = formatValue(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case reflect.Map:
+		pairs := make([]string, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, formatValue(iter.Key().Interface())+"="+formatValue(iter.Value().Interface()))
+		}
+		sort.Strings(pairs)
+		return "{" + strings.Join(pairs, ", ") + "}"
+	case reflect.String:
+		if _, ok := v.(UUID); ok {
+			return rv.String()
+		}
+		return formatString(rv.String())
+	}
+	return fmt.Sprint(v)
+}
+
+// formatString quotes the string when it is empty, holds the delimiters of
+// sets and maps, or could be mistaken for a number or a boolean.
+func formatString(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n,=[]{}\"\\") || s == "true" || s == "false" {
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// plainValue returns the decoded value with its references as strings, for
+// the JSON format.
+func plainValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		elems := make([]interface{}, rv.Len())
+		for i := range elems {
+			elems[i] = plainValue(rv.Index(i).Interface())
+		}
+		return elems
+	case reflect.Map:
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(plainValue(iter.Key().Interface()))] = plainValue(iter.Value().Interface())
+		}
+		return m
+	}
+	if u, ok := v.(UUID); ok {
+		return string(u)
+	}
+	return v
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestResultFormat(t *testing.T) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(testModelSchema), &schema); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	r := Result{
+		Table:   "Bridge",
+		Columns: map[string]string{"_uuid": "string", "name": "string", "ports": "[]string", "external_ids": "map[string]string", "fail_mode": "string", "flood_vlans": "[]int"},
+		Rows: []Row{
+			{
+				"_uuid":        []interface{}{"uuid", "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"},
+				"name":         "br0",
+				"ports":        []interface{}{"set", []interface{}{[]interface{}{"uuid", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"}}},
+				"external_ids": []interface{}{"map", []interface{}{[]interface{}{"owner", "a b"}, []interface{}{"id", "42"}}},
+				"fail_mode":    "secure",
+				"flood_vlans":  []interface{}{"set", []interface{}{float64(10), float64(20)}},
+			},
+			{
+				"_uuid":        []interface{}{"uuid", "0c5e8c8a-2e8f-4b43-9d4e-2b1b0a9d6f11"},
+				"name":         "",
+				"ports":        []interface{}{"set", []interface{}{}},
+				"external_ids": []interface{}{"map", []interface{}{}},
+				"fail_mode":    []interface{}{"set", []interface{}{}},
+				"flood_vlans":  []interface{}{"set", []interface{}{}},
+			},
+		},
+	}
+
+	table, err := r.Format(schema, FormatTable)
+	if err != nil {
+		t.Fatalf("FAIL: expected a table, but failed with: %v", err)
+	}
+	expected := strings.Join([]string{
+		"_uuid                                external_ids           fail_mode flood_vlans name ports",
+		"------------------------------------ ---------------------- --------- ----------- ---- --------------------------------------",
+		`36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c {id="42", owner="a b"} secure    [10, 20]    br0  [5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31]`,
+		`0c5e8c8a-2e8f-4b43-9d4e-2b1b0a9d6f11 {}                     []        []          ""   []`,
+		"",
+	}, "\n")
+	if table != expected {
+		t.Fatalf("FAIL: expected table:\n%s\ngot:\n%s", expected, table)
+	}
+	t.Logf("PASS: table:\n%s", table)
+
+	csv, err := r.Format(schema, FormatCSV)
+	if err != nil || !strings.HasPrefix(csv, "_uuid,external_ids,fail_mode,flood_vlans,name,ports\n36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c,\"{id=\"\"42\"\", owner=\"\"a b\"\"}\",secure,\"[10, 20]\",br0,") {
+		t.Fatalf("FAIL: unexpected CSV: %s, %v", csv, err)
+	}
+	t.Logf("PASS: CSV:\n%s", csv)
+
+	js, err := r.Format(schema, FormatJSON)
+	if err != nil {
+		t.Fatalf("FAIL: expected JSON, but failed with: %v", err)
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal([]byte(js), &rows); err != nil || len(rows) != 2 {
+		t.Fatalf("FAIL: unexpected JSON: %s, %v", js, err)
+	}
+	if rows[0]["_uuid"] != "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c" || rows[0]["ports"].([]interface{})[0] != "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31" || rows[1]["fail_mode"] != nil || rows[0]["flood_vlans"].([]interface{})[1] != float64(20) {
+		t.Fatalf("FAIL: unexpected JSON: %s", js)
+	}
+	t.Logf("PASS: JSON:\n%s", js)
+
+	if _, err := r.Format(schema, Format(42)); err == nil {
+		t.Fatalf("FAIL: expected an unsupported format to fail")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// GetRowByIndex returns the row of the table whose column, which must be an
+// index of the table in the schema of the database, e.g. Bridge.name, has
+// the value, and whether there is one.
+func (c *Client) GetRowByIndex(ctx context.Context, db, table, column string, value interface{}) (Row, bool, error) {
+	return c.GetRowByKey(ctx, db, table, map[string]interface{}{column: value})
+}
+
+// GetRowByKey is like GetRowByIndex, but for the indexes of many columns,
+// e.g. the datapath and the tunnel_key of Port_Binding.
+func (c *Client) GetRowByKey(ctx context.Context, db, table string, key map[string]interface{}) (Row, bool, error) {
+	if c == nil {
+		return nil, false, fmt.Errorf("interface is unavailable")
+	}
+	columns := make([]string, 0, len(key))
+	for column := range key {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, exists := schema.Tables[table]; !exists {
+		return nil, false, fmt.Errorf("get row by index: %w", &tableNotFoundError{table})
+	}
+	if !schema.IsIndex(table, columns...) {
+		return nil, false, fmt.Errorf("get row by index: %v is not an index of table %s", columns, table)
+	}
+	where := make([]Condition, len(columns))
+	for i, column := range columns {
+		value := key[column]
+		if s, ok := value.(string); ok && column == "_uuid" {
+			value = UUID(s)
+		}
+		where[i] = Equal(column, value)
+	}
+	results, err := c.TransactOperations(ctx, db, Select(table, nil, where...))
+	if err != nil {
+		return nil, false, err
+	}
+	switch len(results[0].Rows) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return results[0].Rows[0], true, nil
+	}
+	return nil, false, fmt.Errorf("get row by index: %d rows of table %s match index %v", len(results[0].Rows), table, columns)
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testIndexSchema = `{
+  "name": "OVN_Southbound",
+  "version": "20.21.0",
+  "tables": {
+    "Chassis": {"columns": {"name": {"type": "string"}, "hostname": {"type": "string"}}, "indexes": [["name"]]},
+    "Port_Binding": {"columns": {"logical_port": {"type": "string"}, "datapath": {"type": "uuid"}, "tunnel_key": {"type": "integer"}},
+      "indexes": [["datapath", "tunnel_key"], ["logical_port"]]}
+  }
+}`
+
+func TestGetRowByIndex(t *testing.T) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(testIndexSchema), &schema); err != nil {
+		t.Fatalf("FAIL: failed to decode the schema: %v", err)
+	}
+	for _, test := range []struct {
+		table   string
+		columns []string
+		want    bool
+	}{
+		{"Chassis", []string{"name"}, true},
+		{"Chassis", []string{"hostname"}, false},
+		{"Chassis", []string{"_uuid"}, true},
+		{"Port_Binding", []string{"tunnel_key", "datapath"}, true},
+		{"Port_Binding", []string{"tunnel_key"}, false},
+		{"Missing", []string{"_uuid"}, false},
+	} {
+		if got := schema.IsIndex(test.table, test.columns...); got != test.want {
+			t.Fatalf("FAIL: expected %v to be an index of %s: %v, got %v", test.columns, test.table, test.want, got)
+		}
+	}
+	t.Logf("PASS: indexes of the schema")
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var wheres []string
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testIndexSchema)
+		case "transact":
+		default:
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		var op struct {
+			Where json.RawMessage `json:"where"`
+		}
+		json.Unmarshal(args[1], &op)
+		mu.Lock()
+		wheres = append(wheres, string(op.Where))
+		mu.Unlock()
+		rows := []interface{}{}
+		switch {
+		case strings.Contains(string(op.Where), "hv1"), strings.Contains(string(op.Where), "tunnel_key"):
+			rows = append(rows, map[string]interface{}{"name": "hv1"})
+		case strings.Contains(string(op.Where), "dup"):
+			rows = append(rows, map[string]interface{}{"name": "dup"}, map[string]interface{}{"name": "dup"})
+		}
+		return []interface{}{map[string]interface{}{"rows": rows}}
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+
+	row, found, err := cli.GetRowByIndex(ctx, "OVN_Southbound", "Chassis", "name", "hv1")
+	if err != nil || !found || row["name"] != "hv1" {
+		t.Fatalf("FAIL: expected to find hv1, got %v, %v, %v", row, found, err)
+	}
+	if _, found, err := cli.GetRowByIndex(ctx, "OVN_Southbound", "Chassis", "name", "hv2"); err != nil || found {
+		t.Fatalf("FAIL: expected no hv2, got %v, %v", found, err)
+	}
+	key := map[string]interface{}{"datapath": UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"), "tunnel_key": 1}
+	if _, found, err := cli.GetRowByKey(ctx, "OVN_Southbound", "Port_Binding", key); err != nil || !found {
+		t.Fatalf("FAIL: expected to find the port binding, got %v, %v", found, err)
+	}
+	if _, _, err := cli.GetRowByIndex(ctx, "OVN_Southbound", "Port_Binding", "_uuid", "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"); err != nil {
+		t.Fatalf("FAIL: expected to look up by _uuid, but failed with: %v", err)
+	}
+	expected := `[["name","==","hv1"]] [["name","==","hv2"]] [["datapath","==",["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]],["tunnel_key","==",1]] [["_uuid","==",["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]]`
+	mu.Lock()
+	got := strings.Join(wheres, " ")
+	mu.Unlock()
+	if got != expected {
+		t.Fatalf("FAIL: expected conditions %s, got %s", expected, got)
+	}
+	t.Logf("PASS: looked up the rows by index")
+
+	if _, _, err := cli.GetRowByIndex(ctx, "OVN_Southbound", "Chassis", "hostname", "node1"); err == nil {
+		t.Fatalf("FAIL: expected an error for a column which is not an index")
+	}
+	if _, _, err := cli.GetRowByIndex(ctx, "OVN_Southbound", "Missing", "name", "hv1"); !errors.Is(err, ErrTableNotFound) {
+		t.Fatalf("FAIL: expected ErrTableNotFound, got %v", err)
+	}
+	if _, _, err := cli.GetRowByIndex(ctx, "OVN_Southbound", "Chassis", "name", "dup"); err == nil {
+		t.Fatalf("FAIL: expected an error for many rows matching an index")
+	}
+	t.Logf("PASS: rejected the invalid lookups")
+}
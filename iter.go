@@ -0,0 +1,144 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// DefaultSelectBatchSize is the number of rows SelectIter retrieves per
+// transaction by default.
+const DefaultSelectBatchSize = 1000
+
+// RowIterator walks the rows selected by SelectIter, one batch at a time.
+//
+//	it, err := client.SelectIter(ctx, "OVN_Southbound", "MAC_Binding", nil, 0)
+//	if err != nil {
+//		return err
+//	}
+//	for it.Next() {
+//		row := it.Row()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type RowIterator struct {
+	ctx     context.Context
+	client  *Client
+	db      string
+	table   string
+	columns []string
+	where   []Condition
+	size    int
+	uuids   []string
+	batch   []Row
+	row     Row
+	err     error
+}
+
+// SelectIter selects the columns, or all of them when there are none, of
+// the rows of the table matching the conditions, and returns an iterator
+// over them. Rather than holding the whole result in memory, it lists the
+// UUIDs of the rows first, and retrieves the rows in batches of
+// batchSize, or DefaultSelectBatchSize when it is not positive, in
+// _uuid order. The rows deleted, or no longer matching the conditions,
+// before their batch is retrieved are skipped.
+func (c *Client) SelectIter(ctx context.Context, db, table string, columns []string, batchSize int, where ...Condition) (*RowIterator, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultSelectBatchSize
+	}
+	results, err := c.TransactOperations(ctx, db, Select(table, []string{"_uuid"}, where...))
+	if err != nil {
+		return nil, err
+	}
+	uuids := make([]string, 0, len(results[0].Rows))
+	for _, row := range results[0].Rows {
+		uuid, err := row.GetString("_uuid")
+		if err != nil {
+			return nil, fmt.Errorf("select iterator: %s", err)
+		}
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	return &RowIterator{
+		ctx:     ctx,
+		client:  c,
+		db:      db,
+		table:   table,
+		columns: columns,
+		where:   where,
+		size:    batchSize,
+		uuids:   uuids,
+	}, nil
+}
+
+// Next advances the iterator to the next row, retrieving the next batch
+// when needed. It returns false when there are no more rows, or on error.
+func (it *RowIterator) Next() bool {
+	for len(it.batch) == 0 {
+		if it.err != nil || len(it.uuids) == 0 {
+			it.row = nil
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			it.row = nil
+			return false
+		}
+	}
+	it.row, it.batch = it.batch[0], it.batch[1:]
+	return true
+}
+
+// Row returns the current row.
+func (it *RowIterator) Row() Row {
+	return it.row
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// Remaining returns the number of rows not retrieved yet.
+func (it *RowIterator) Remaining() int {
+	return len(it.uuids) + len(it.batch)
+}
+
+// fetch retrieves the next batch of rows, with one select operation per
+// row, since the conditions of an operation cannot match a range of UUIDs.
+func (it *RowIterator) fetch() error {
+	n := it.size
+	if n > len(it.uuids) {
+		n = len(it.uuids)
+	}
+	ops := make([]Operation, n)
+	for i, uuid := range it.uuids[:n] {
+		where := append([]Condition{Equal("_uuid", UUID(uuid))}, it.where...)
+		ops[i] = Select(it.table, it.columns, where...)
+	}
+	results, err := it.client.TransactOperations(it.ctx, it.db, ops...)
+	if err != nil {
+		return err
+	}
+	it.uuids = it.uuids[n:]
+	for _, result := range results {
+		it.batch = append(it.batch, result.Rows...)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+)
+
+func TestSelectIter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	// The rows are listed in reverse order, to check that the iterator
+	// walks them in _uuid order.
+	var uuids []string
+	for i := 24; i >= 0; i-- {
+		uuids = append(uuids, fmt.Sprintf("00000000-0000-4000-8000-%012d", i))
+	}
+	var mu sync.Mutex
+	deleted := map[string]bool{}
+	var sizes []int
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		mu.Lock()
+		defer mu.Unlock()
+		results := []interface{}{}
+		for _, arg := range args[1:] {
+			var op struct {
+				Columns []string        `json:"columns"`
+				Where   [][]interface{} `json:"where"`
+			}
+			json.Unmarshal(arg, &op)
+			rows := []interface{}{}
+			if len(op.Where) == 0 {
+				for _, uuid := range uuids {
+					rows = append(rows, map[string]interface{}{"_uuid": []interface{}{"uuid", uuid}})
+				}
+				// A row is deleted after the listing.
+				deleted[uuids[10]] = true
+			} else {
+				uuid := op.Where[0][2].([]interface{})[1].(string)
+				if !deleted[uuid] {
+					rows = append(rows, map[string]interface{}{"_uuid": []interface{}{"uuid", uuid}, "mac": "mac-" + uuid[30:]})
+				}
+			}
+			results = append(results, map[string]interface{}{"rows": rows})
+		}
+		if len(args) > 1 {
+			sizes = append(sizes, len(args)-1)
+		}
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	it, err := cli.SelectIter(context.Background(), "OVN_Southbound", "MAC_Binding", []string{"_uuid", "mac"}, 10)
+	if err != nil {
+		t.Fatalf("FAIL: failed to list the rows: %v", err)
+	}
+	if it.Remaining() != 25 {
+		t.Fatalf("FAIL: expected 25 remaining rows, got %d", it.Remaining())
+	}
+	var got []string
+	for it.Next() {
+		uuid, _ := it.Row().GetString("_uuid")
+		got = append(got, uuid)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("FAIL: iteration failed: %v", err)
+	}
+	if len(got) != 24 {
+		t.Fatalf("FAIL: expected 24 rows, got %d", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i-1] >= got[i] {
+			t.Fatalf("FAIL: rows out of _uuid order: %s, %s", got[i-1], got[i])
+		}
+	}
+	t.Logf("PASS: walked %d rows in _uuid order", len(got))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(sizes) != "[1 10 10 5]" {
+		t.Fatalf("FAIL: expected batches of [1 10 10 5] operations, got %v", sizes)
+	}
+	t.Logf("PASS: retrieved the rows in batches of %v", sizes)
+}
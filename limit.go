@@ -0,0 +1,161 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits the rate of the requests of a client to rate per
+// second, with bursts of up to burst requests. The requests in excess wait
+// for their turn, or until their context is done.
+func WithRateLimit(rate float64, burst int) ClientOption {
+	return func(cli *Client) error {
+		if rate <= 0 || burst < 1 {
+			return fmt.Errorf("invalid rate limit: %v requests per second, burst of %d", rate, burst)
+		}
+		cli.limiter = &rateLimiter{
+			rate:   rate,
+			burst:  float64(burst),
+			tokens: float64(burst),
+			last:   time.Now(),
+		}
+		return nil
+	}
+}
+
+// WithCircuitBreaker makes a client stop sending requests after threshold
+// consecutive requests fail, because the server is unreachable or does not
+// respond in time. For the cooldown period, the requests fail with
+// ErrCircuitOpen right away. Then a single request is let through as a
+// probe; its success resumes sending requests, its failure restarts the
+// cooldown.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) ClientOption {
+	return func(cli *Client) error {
+		if threshold < 1 || cooldown <= 0 {
+			return fmt.Errorf("invalid circuit breaker: threshold %d, cooldown %s", threshold, cooldown)
+		}
+		cli.breaker = &circuitBreaker{
+			threshold: threshold,
+			cooldown:  cooldown,
+		}
+		return nil
+	}
+}
+
+// rateLimiter is a token bucket.
+type rateLimiter struct {
+	mux    sync.Mutex
+	rate   float64 // the tokens added per second
+	burst  float64 // the capacity of the bucket
+	tokens float64
+	last   time.Time
+}
+
+// wait takes a token, waiting for one when the bucket is empty.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mux.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	// The token is taken right away, so that the waiting requests
+	// proceed in order. A negative balance is the time they wait.
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mux.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		l.mux.Lock()
+		l.tokens++
+		l.mux.Unlock()
+		return contextError(ctx)
+	}
+	return nil
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker tracks the failures of the requests of a client.
+type circuitBreaker struct {
+	mux       sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     circuitState
+	failures  int
+	openedAt  time.Time
+}
+
+// allow returns ErrCircuitOpen when the request may not be sent.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	switch b.state {
+	case circuitOpen:
+		if wait := b.cooldown - time.Since(b.openedAt); wait > 0 {
+			return fmt.Errorf("%w: retrying in %s", ErrCircuitOpen, wait.Round(time.Millisecond))
+		}
+		b.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		return fmt.Errorf("%w: probing the server", ErrCircuitOpen)
+	}
+	return nil
+}
+
+// done records the outcome of a request which was allowed.
+func (b *circuitBreaker) done(err error) {
+	if b == nil {
+		return
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	switch {
+	case errors.Is(err, ErrTimeout), errors.Is(err, ErrNotConnected):
+		b.failures++
+		if b.state == circuitHalfOpen || b.failures >= b.threshold {
+			b.state = circuitOpen
+			b.openedAt = time.Now()
+		}
+	case errors.Is(err, context.Canceled):
+		// The caller gave up, which says nothing about the server. The
+		// next request is the probe.
+		if b.state == circuitHalfOpen {
+			b.state = circuitOpen
+		}
+	default:
+		// The server responded, possibly with an error.
+		b.state = circuitClosed
+		b.failures = 0
+	}
+}
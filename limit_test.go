@@ -0,0 +1,132 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClientRateLimit(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	newTestServer(t, l, nil)
+	cli, err := NewClient("unix:"+sock, 1, WithRateLimit(50, 2))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		if err := cli.Echo("test message"); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+	// The burst passes right away, the other five requests wait 20ms each.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("FAIL: expected the requests to be limited, but they completed in %v", elapsed)
+	}
+	t.Logf("PASS: 7 requests limited to 50 per second completed in %v", time.Since(start).Round(time.Millisecond))
+
+	if err := cli.EchoWithTimeout("test message", 5*time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("FAIL: expected a request waiting for its turn to time out, but got: %v", err)
+	}
+	t.Logf("PASS: request waiting for its turn timed out")
+
+	if _, err := NewClient("unix:"+sock, 1, WithRateLimit(0, 1)); err == nil {
+		t.Fatalf("FAIL: expected invalid rate limit to fail")
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	sock := newSilentServer(t)
+	cooldown := 100 * time.Millisecond
+	cli, err := NewClient(sock, 1, WithRequestTimeout(20*time.Millisecond), WithCircuitBreaker(2, cooldown))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	for i := 0; i < 2; i++ {
+		if err := cli.Echo("test message"); !errors.Is(err, ErrTimeout) {
+			t.Fatalf("FAIL: attempt %d: expected timeout error, but got: %v", i, err)
+		}
+	}
+	start := time.Now()
+	err = cli.Echo("test message")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("FAIL: expected circuit open error, but got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Fatalf("FAIL: expected the request to fail fast, but it took %v", elapsed)
+	}
+	t.Logf("PASS: circuit opened after repeated timeouts: %v", err)
+
+	time.Sleep(cooldown)
+	if err := cli.Echo("test message"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("FAIL: expected the probe to be sent and time out, but got: %v", err)
+	}
+	if err := cli.Echo("test message"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("FAIL: expected the failed probe to reopen the circuit, but got: %v", err)
+	}
+	t.Logf("PASS: failed probe reopened the circuit")
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	b := &circuitBreaker{threshold: 2, cooldown: 10 * time.Millisecond}
+	for i, test := range []struct {
+		name    string
+		outcome error
+		allowed bool
+	}{
+		{name: "first failure", outcome: ErrNotConnected, allowed: true},
+		{name: "server error resets failures", outcome: errors.New("error in response body"), allowed: true},
+		{name: "failure", outcome: ErrTimeout, allowed: true},
+		{name: "cancelled request", outcome: context.Canceled, allowed: true},
+		{name: "second failure opens circuit", outcome: ErrTimeout, allowed: true},
+		{name: "open circuit", allowed: false},
+	} {
+		err := b.allow()
+		if test.allowed != (err == nil) {
+			t.Fatalf("FAIL: Test %d: %s: unexpected outcome of allow: %v", i, test.name, err)
+		}
+		if err == nil {
+			b.done(test.outcome)
+		}
+		t.Logf("PASS: Test %d: %s", i, test.name)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := b.allow(); err != nil {
+		t.Fatalf("FAIL: expected the probe to be allowed after the cooldown, but got: %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("FAIL: expected a single probe, but got: %v", err)
+	}
+	b.done(context.Canceled)
+	if err := b.allow(); err != nil {
+		t.Fatalf("FAIL: expected another probe after a cancelled one, but got: %v", err)
+	}
+	b.done(nil)
+	if b.state != circuitClosed || b.failures != 0 {
+		t.Fatalf("FAIL: expected a successful probe to close the circuit")
+	}
+	t.Logf("PASS: successful probe closed the circuit")
+}
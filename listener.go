@@ -0,0 +1,168 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Listener accepts the connections ovsdb-server initiates when it is
+// configured with an active remote, e.g. "--remote=tcp:10.0.0.1:6640", and
+// hands them over as clients. It allows acting as a manager of the servers.
+type Listener struct {
+	listener net.Listener
+	proto    string
+	timeout  int
+	opts     []ClientOption
+	config   *tls.Config
+}
+
+// Listen listens on the passive remote, e.g. "ptcp:6640", "ptcp:6640:10.0.0.1",
+// "pssl:6640", or "punix:/var/run/openvswitch/manager.sock". The options are
+// applied to every accepted client. The "pssl:" remotes require
+// WithTLSConfig, and present the certificate of WithCertificateReloader,
+// when set.
+func Listen(remote string, opts ...ClientOption) (*Listener, error) {
+	proto, addr, err := parseListenRemote(remote)
+	if err != nil {
+		return nil, err
+	}
+	settings := Client{}
+	for _, opt := range opts {
+		if err := opt(&settings); err != nil {
+			return nil, err
+		}
+	}
+	if proto == "ssl" && settings.TLSConfig == nil {
+		return nil, fmt.Errorf("the %s remote requires TLS configuration", remote)
+	}
+	if proto == "unix" && namedPipes {
+		return nil, fmt.Errorf("the %s remote is not supported on Windows", remote)
+	}
+	network := proto
+	config := settings.TLSConfig
+	if proto == "ssl" {
+		network = "tcp"
+		if r := settings.CertificateReloader; r != nil {
+			config = config.Clone()
+			config.Certificates = nil
+			config.GetCertificate = r.GetCertificate
+		}
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Listener{
+		listener: l,
+		proto:    proto,
+		timeout:  settings.Timeout,
+		opts:     opts,
+		config:   config,
+	}, nil
+}
+
+// parseListenRemote parses a passive remote into the protocol and the
+// address to listen on.
+func parseListenRemote(remote string) (string, string, error) {
+	r, err := ParseRemote(remote)
+	if err != nil {
+		return "", "", err
+	}
+	if !r.Passive {
+		return "", "", fmt.Errorf("invalid remote %s: unsupported passive remote", remote)
+	}
+	return r.Proto, r.Address, nil
+}
+
+// Addr returns the address the listener listens on.
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Close stops listening. It does not close the accepted clients.
+func (l *Listener) Close() error {
+	return l.listener.Close()
+}
+
+// Accept waits for a server to connect and returns the client for the
+// connection.
+func (l *Listener) Accept() (*Client, error) {
+	return l.AcceptContext(context.Background())
+}
+
+// AcceptContext is like Accept, but honors the context. The client
+// performs the handshake before it is returned, so its capabilities, e.g.
+// the ServerID, identify the server. The client cannot reconnect; once the
+// connection breaks, it fails with ErrNotConnected until the server
+// connects again and is accepted anew.
+func (l *Listener) AcceptContext(ctx context.Context) (*Client, error) {
+	conn, err := l.accept(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l.proto == "ssl" {
+		tlsConn := tls.Server(conn, l.config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", conn.RemoteAddr(), err)
+		}
+		conn = tlsConn
+	}
+	endpoint := l.proto + ":" + conn.RemoteAddr().String()
+	cli := &Client{}
+	if err := cli.init(endpoint, l.timeout, l.opts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	cli.passive = true
+	cli.attach(conn)
+	if err := cli.handshake(ctx); err != nil {
+		cli.disconnect()
+		return nil, err
+	}
+	return cli, nil
+}
+
+// accept accepts a connection. The context interrupts the wait by setting
+// a deadline on the listener, which stays open.
+func (l *Listener) accept(ctx context.Context) (net.Conn, error) {
+	dl, ok := l.listener.(interface{ SetDeadline(time.Time) error })
+	if !ok || ctx.Done() == nil {
+		return l.listener.Accept()
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			dl.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	conn, err := l.listener.Accept()
+	close(done)
+	<-stopped
+	dl.SetDeadline(time.Time{})
+	if err != nil && ctx.Err() != nil {
+		return nil, contextError(ctx)
+	}
+	return conn, err
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseListenRemote(t *testing.T) {
+	for i, test := range []struct {
+		remote     string
+		proto      string
+		addr       string
+		shouldFail bool
+	}{
+		{remote: "ptcp:6640", proto: "tcp", addr: ":6640"},
+		{remote: "ptcp:6640:10.0.0.1", proto: "tcp", addr: "10.0.0.1:6640"},
+		{remote: "ptcp:6640:[::1]", proto: "tcp", addr: "[::1]:6640"},
+		{remote: "pssl:6640", proto: "ssl", addr: ":6640"},
+		{remote: "punix:/var/run/openvswitch/manager.sock", proto: "unix", addr: "/var/run/openvswitch/manager.sock"},
+		{remote: "ptcp:", proto: "tcp", addr: ":6640"},
+		{remote: "ptcp:http", shouldFail: true},
+		{remote: "tcp:10.0.0.1:6640", shouldFail: true},
+	} {
+		proto, addr, err := parseListenRemote(test.remote)
+		if err != nil {
+			if !test.shouldFail {
+				t.Fatalf("FAIL: Test %d: remote '%s', expected to pass, but failed with: %v", i, test.remote, err)
+			}
+			continue
+		}
+		if test.shouldFail {
+			t.Fatalf("FAIL: Test %d: remote '%s', expected to fail, but passed", i, test.remote)
+		}
+		if proto != test.proto || addr != test.addr {
+			t.Fatalf("FAIL: Test %d: remote '%s', expected %s %s, but got %s %s", i, test.remote, test.proto, test.addr, proto, addr)
+		}
+	}
+}
+
+func TestListener(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "manager.sock")
+	l, err := Listen("punix:"+sock, WithReconnectPolicy(ReconnectPolicy{MaxRetries: 1}))
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	_, err = l.AcceptContext(ctx)
+	cancel()
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("FAIL: expected timeout error, but got: %v", err)
+	}
+
+	// The server initiates the connection.
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to connect to the listener: %v", err)
+	}
+	go serveTestConn(conn, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "list_dbs":
+			return []string{"Open_vSwitch", "_Server"}
+		case "get_server_id":
+			return "3b7a5c1e-7d0f-4c2e-9b8a-5f1d2e3c4b5a"
+		}
+		return nil
+	})
+	cli, err := l.Accept()
+	if err != nil {
+		t.Fatalf("FAIL: failed to accept: %v", err)
+	}
+	defer cli.Close()
+	if caps := cli.Capabilities(); caps.ServerID != "3b7a5c1e-7d0f-4c2e-9b8a-5f1d2e3c4b5a" {
+		t.Fatalf("FAIL: unexpected capabilities of the server: %+v", caps)
+	}
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	t.Logf("PASS: accepted connection of server %s", cli.Capabilities().ServerID)
+
+	conn.Close()
+	if err = cli.Echo("test message"); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("FAIL: expected not connected error after the server disconnected, but got: %v", err)
+	}
+	t.Logf("PASS: client of a disconnected server: %v", err)
+}
@@ -0,0 +1,291 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// WithLockHook registers a function called whenever a client acquires or
+// loses a lock, e.g. when another client steals it, or the connection
+// holding it breaks.
+func WithLockHook(fn func(id string, locked bool)) ClientOption {
+	return func(cli *Client) error {
+		cli.OnLockChange = fn
+		return nil
+	}
+}
+
+// Lock requests the lock, see RFC 7047, Section 4.1.8. The locks let the
+// clients of a database, e.g. the replicas of an exporter, coordinate:
+// the transactions of the holder of a lock may assert that it still holds
+// it, see TransactLocked. It returns true when the client acquired the
+// lock right away. Otherwise the server grants the lock once its holder
+// releases it, which is reported by HasLock, and the hook of WithLockHook.
+//
+// The locks are held by the connection of the client, and released by the
+// server when it breaks, so that they have to be requested anew after a
+// reconnect.
+func (c *Client) Lock(ctx context.Context, id string) (bool, error) {
+	var res struct {
+		Locked bool `json:"locked"`
+	}
+	if err := c.lockRequest(ctx, "lock", id, &res); err != nil {
+		return false, err
+	}
+	return c.locks.reply(id, res.Locked), nil
+}
+
+// Steal acquires the lock, taking it away from its holder, if any, which
+// is notified that its lock has been stolen.
+func (c *Client) Steal(ctx context.Context, id string) error {
+	if err := c.lockRequest(ctx, "steal", id, nil); err != nil {
+		return err
+	}
+	c.locks.reply(id, true)
+	return nil
+}
+
+// Unlock releases the lock, or withdraws the request of a lock which has
+// not been acquired yet.
+func (c *Client) Unlock(ctx context.Context, id string) error {
+	if err := c.lockRequest(ctx, "unlock", id, nil); err != nil {
+		return err
+	}
+	c.locks.remove(id)
+	return nil
+}
+
+// HasLock reports whether the client holds the lock.
+func (c *Client) HasLock(id string) bool {
+	if c == nil || c.locks == nil {
+		return false
+	}
+	return c.locks.holds(id)
+}
+
+// TransactLocked is like TransactOperations, but the transaction starts
+// with an assert operation, so that it fails with ErrNotOwner, and has no
+// effect, unless the client still holds the lock when it is executed. It
+// fails right away when the client knows it does not hold the lock. As the
+// lock is held by the connection of the client, the transaction is sent on
+// it, which rules out ClusterLeaderWrites.
+func (c *Client) TransactLocked(ctx context.Context, db string, lock string, ops ...Operation) ([]OperationResult, error) {
+	if c == nil {
+		return nil, fmt.Errorf("interface is unavailable")
+	}
+	if c.ClusterMode == ClusterLeaderWrites {
+		return nil, fmt.Errorf("'transact' method failed: lock %s: the writes of ClusterLeaderWrites are sent to another connection", lock)
+	}
+	if !c.HasLock(lock) {
+		return nil, fmt.Errorf("'transact' method failed: lock %s: %w", lock, ErrNotOwner)
+	}
+	results, err := c.TransactOperations(ctx, db, append([]Operation{Assert(lock)}, ops...)...)
+	var txnErr *TransactionError
+	switch {
+	case errors.As(err, &txnErr) && txnErr.Index == 0:
+		if errors.Is(txnErr.Err, ErrNotOwner) {
+			c.locks.set(lock, false)
+		}
+		return nil, fmt.Errorf("'transact' method failed: lock %s: %w", lock, txnErr.Err)
+	case errors.As(err, &txnErr):
+		// The assert operation is not one of the caller.
+		e := *txnErr
+		if e.Index > 0 {
+			e.Index--
+		}
+		e.Results = e.Results[1:]
+		return nil, &e
+	case err != nil:
+		return nil, err
+	}
+	return results[1:], nil
+}
+
+// lockRequest sends the request of the method, "lock", "steal", or
+// "unlock", and decodes its result into v, unless it is nil.
+func (c *Client) lockRequest(ctx context.Context, method string, id string, v interface{}) error {
+	if c == nil || c.locks == nil {
+		return fmt.Errorf("'%s' method failed: interface is unavailable", method)
+	}
+	if id == "" {
+		return fmt.Errorf("'%s' method failed: empty lock id", method)
+	}
+	js, err := encodeString(id)
+	if err != nil {
+		return fmt.Errorf("'%s' method, lock %s failed: %v", method, id, err)
+	}
+	// The lock is recorded as requested before the request is sent, so
+	// that the notifications following the reply are not missed.
+	added := false
+	if method != "unlock" {
+		added = c.locks.request(id)
+	}
+	response, err := c.queryContext(ctx, method, js)
+	if err == nil && v != nil {
+		err = json.Unmarshal(response.Result, v)
+	}
+	if err != nil {
+		if added {
+			c.locks.remove(id)
+		}
+		return fmt.Errorf("'%s' method, lock %s failed: %w", method, id, err)
+	}
+	return nil
+}
+
+// lockTable holds the locks requested by a client, and whether they are
+// held. The hook is called for the changes, in order, by a goroutine of
+// its own, so that it may issue requests.
+type lockTable struct {
+	mux  sync.Mutex
+	held map[string]bool
+	// notified are the locks whose state has been notified since they
+	// were requested. The notifications are more recent than the replies
+	// to the requests, which are then ignored.
+	notified    map[string]bool
+	onChange    func(id string, locked bool)
+	pending     []lockEvent
+	dispatching bool
+}
+
+type lockEvent struct {
+	id     string
+	locked bool
+}
+
+func (t *lockTable) holds(id string) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.held[id]
+}
+
+// set records the lock as requested, and whether it is held.
+func (t *lockTable) set(id string, locked bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	was := t.held[id]
+	t.held[id] = locked
+	if locked != was {
+		t.emit(id, locked)
+	}
+}
+
+// request records the lock as requested, ahead of the reply, and returns
+// true when it was not requested before.
+func (t *lockTable) request(id string) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	delete(t.notified, id)
+	if _, requested := t.held[id]; requested {
+		return false
+	}
+	t.held[id] = false
+	return true
+}
+
+// reply records whether the lock is held, as replied to its request,
+// unless a notification has been received since, and returns whether it
+// is held.
+func (t *lockTable) reply(id string, locked bool) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.notified[id] {
+		return t.held[id]
+	}
+	was := t.held[id]
+	t.held[id] = locked
+	if locked != was {
+		t.emit(id, locked)
+	}
+	return locked
+}
+
+func (t *lockTable) remove(id string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.held[id] {
+		t.emit(id, false)
+	}
+	delete(t.held, id)
+	delete(t.notified, id)
+}
+
+// reset forgets the locks of a connection which has been replaced.
+func (t *lockTable) reset() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	for id, locked := range t.held {
+		if locked {
+			t.emit(id, false)
+		}
+	}
+	t.held = make(map[string]bool)
+	t.notified = nil
+}
+
+// notify handles the "locked" and "stolen" notifications of the server,
+// whose parameters are the id of the lock.
+func (t *lockTable) notify(method string, params json.RawMessage) {
+	var ids []string
+	if err := json.Unmarshal(params, &ids); err != nil || len(ids) != 1 {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if _, requested := t.held[ids[0]]; !requested {
+		return
+	}
+	locked := method == "locked"
+	if t.notified == nil {
+		t.notified = make(map[string]bool)
+	}
+	t.notified[ids[0]] = true
+	if t.held[ids[0]] != locked {
+		t.held[ids[0]] = locked
+		t.emit(ids[0], locked)
+	}
+}
+
+// emit queues the change for the hook. The table must be locked.
+func (t *lockTable) emit(id string, locked bool) {
+	if t.onChange == nil {
+		return
+	}
+	t.pending = append(t.pending, lockEvent{id: id, locked: locked})
+	if !t.dispatching {
+		t.dispatching = true
+		go t.dispatch()
+	}
+}
+
+func (t *lockTable) dispatch() {
+	for {
+		t.mux.Lock()
+		if len(t.pending) == 0 {
+			t.dispatching = false
+			t.mux.Unlock()
+			return
+		}
+		e := t.pending[0]
+		t.pending = t.pending[1:]
+		t.mux.Unlock()
+		t.onChange(e.id, e.locked)
+	}
+}
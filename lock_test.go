@@ -0,0 +1,183 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLocks(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var transactions []string
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		var args []interface{}
+		json.Unmarshal(params, &args)
+		switch method {
+		case "lock":
+			return map[string]interface{}{"locked": args[0] == "exporter"}
+		case "steal":
+			return map[string]interface{}{"locked": true}
+		case "unlock":
+			switch args[0] {
+			case "exporter":
+				return testServerNotify{method: "locked", params: []string{"other"}, result: map[string]interface{}{}}
+			case "nothing":
+				return testServerNotify{method: "stolen", params: []string{"other"}, result: map[string]interface{}{}}
+			}
+			return map[string]interface{}{}
+		case "transact":
+			mu.Lock()
+			transactions = append(transactions, string(params))
+			mu.Unlock()
+			if strings.Contains(string(params), `"lock":"third"`) {
+				return []interface{}{map[string]interface{}{"error": "not owner"}, nil}
+			}
+			return []interface{}{map[string]interface{}{}, map[string]interface{}{"rows": []interface{}{}}}
+		}
+		return nil
+	})
+	events := make(chan string, 16)
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1, WithLockHook(func(id string, locked bool) {
+		if locked {
+			events <- "+" + id
+		} else {
+			events <- "-" + id
+		}
+	}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+
+	if locked, err := cli.Lock(ctx, "exporter"); err != nil || !locked || !cli.HasLock("exporter") {
+		t.Fatalf("FAIL: expected to acquire the lock, but got %t, %v", locked, err)
+	}
+	results, err := cli.TransactLocked(ctx, "OVN_Southbound", "exporter", Select("Chassis", nil))
+	if err != nil || len(results) != 1 {
+		t.Fatalf("FAIL: expected the locked transaction to pass, but got %+v, %v", results, err)
+	}
+	mu.Lock()
+	sent := transactions[0]
+	mu.Unlock()
+	if !strings.Contains(sent, `{"op":"assert","lock":"exporter"}`) {
+		t.Fatalf("FAIL: expected the transaction to assert the lock, but sent: %s", sent)
+	}
+	t.Logf("PASS: sent %s", sent)
+
+	if locked, err := cli.Lock(ctx, "other"); err != nil || locked || cli.HasLock("other") {
+		t.Fatalf("FAIL: expected the lock to be held by another client, but got %t, %v", locked, err)
+	}
+	if _, err := cli.TransactLocked(ctx, "OVN_Southbound", "other", Select("Chassis", nil)); !errors.Is(err, ErrNotOwner) {
+		t.Fatalf("FAIL: expected ErrNotOwner, but got: %v", err)
+	}
+	if err := cli.Unlock(ctx, "exporter"); err != nil || cli.HasLock("exporter") || !cli.HasLock("other") {
+		t.Fatalf("FAIL: expected to release the lock, and to be granted the other one, but got: %v", err)
+	}
+	if err := cli.Unlock(ctx, "nothing"); err != nil || cli.HasLock("other") {
+		t.Fatalf("FAIL: expected the other lock to be stolen, but got: %v", err)
+	}
+	t.Logf("PASS: tracked the locked and stolen notifications")
+
+	if err := cli.Steal(ctx, "third"); err != nil || !cli.HasLock("third") {
+		t.Fatalf("FAIL: expected to steal the lock, but got: %v", err)
+	}
+	if _, err := cli.TransactLocked(ctx, "OVN_Southbound", "third", Select("Chassis", nil)); !errors.Is(err, ErrNotOwner) || cli.HasLock("third") {
+		t.Fatalf("FAIL: expected the failed assertion to drop the lock, but got: %v", err)
+	}
+	if _, err := cli.Lock(ctx, ""); err == nil {
+		t.Fatalf("FAIL: expected an empty lock id to be rejected")
+	}
+
+	want := []string{"+exporter", "+other", "-exporter", "-other", "+third", "-third"}
+	var got []string
+	for len(got) < len(want) {
+		select {
+		case e := <-events:
+			got = append(got, e)
+		case <-time.After(time.Second):
+			t.Fatalf("FAIL: expected the changes %v, but got %v", want, got)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FAIL: expected the changes %v, but got %v", want, got)
+	}
+	t.Logf("PASS: lock changes %v", got)
+}
+
+func TestLockNotifiedAfterReply(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		var args []string
+		json.Unmarshal(params, &args)
+		switch method {
+		case "lock":
+			// The lock is granted right after it is reported as held by
+			// another client.
+			return testServerNotify{method: "locked", params: args, result: map[string]interface{}{"locked": false}, after: true}
+		case "steal":
+			// The lock is stolen right after it is stolen by the client.
+			return testServerNotify{method: "stolen", params: args, result: map[string]interface{}{}, after: true}
+		}
+		return nil
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+	eventually := func(id string, locked bool) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); cli.HasLock(id) != locked; {
+			if time.Now().After(deadline) {
+				t.Fatalf("FAIL: expected the lock %s to be held: %t, but it is not", id, locked)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		// The late reply does not undo the notification.
+		time.Sleep(20 * time.Millisecond)
+		if cli.HasLock(id) != locked {
+			t.Fatalf("FAIL: expected the lock %s to stay held: %t", id, locked)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if _, err := cli.Lock(ctx, "exporter"); err != nil {
+			t.Fatalf("FAIL: expected to request the lock, but failed with: %v", err)
+		}
+		eventually("exporter", true)
+		if err := cli.Steal(ctx, "other"); err != nil {
+			t.Fatalf("FAIL: expected to steal the lock, but failed with: %v", err)
+		}
+		eventually("other", false)
+		cli.locks.reset()
+	}
+	t.Logf("PASS: the notifications following the replies are tracked")
+}
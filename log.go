@@ -95,6 +95,8 @@ func readLogFile(f OvsDataFile) (map[string]map[string]uint64, int64, error) {
 
 // GetLogFileEventStats TODO
 func (cli *OvnClient) GetLogFileEventStats(name string) (map[string]map[string]uint64, error) {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
 	switch name {
 	case "ovsdb-server-northbound":
 		stats, offset, err := readLogFile(cli.Database.Northbound.File.Log)
@@ -123,6 +125,8 @@ func (cli *OvnClient) GetLogFileEventStats(name string) (map[string]map[string]u
 
 // GetLogFileEventStats TODO
 func (cli *OvsClient) GetLogFileEventStats(name string) (map[string]map[string]uint64, error) {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
 	switch name {
 	case "ovsdb-server":
 		stats, offset, err := readLogFile(cli.Database.Vswitch.File.Log)
@@ -144,6 +148,8 @@ func (cli *OvsClient) GetLogFileEventStats(name string) (map[string]map[string]u
 
 // GetLogFileInfo TODO
 func (cli *OvnClient) GetLogFileInfo(name string) (OvsDataFile, error) {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
 	var i os.FileInfo
 	var err error
 	switch name {
@@ -176,6 +182,8 @@ func (cli *OvnClient) GetLogFileInfo(name string) (OvsDataFile, error) {
 
 // GetLogFileInfo TODO
 func (cli *OvsClient) GetLogFileInfo(name string) (OvsDataFile, error) {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
 	var i os.FileInfo
 	var err error
 	switch name {
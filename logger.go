@@ -0,0 +1,114 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"log"
+)
+
+// Logger receives the diagnostic messages of the package, e.g. about the
+// connection attempts, the durations of requests, and the rows skipped
+// while parsing query results. The messages are discarded unless a logger
+// is set.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// WithLogger sets the logger of a client.
+func WithLogger(logger Logger) ClientOption {
+	return func(cli *Client) error {
+		cli.Logger = logger
+		return nil
+	}
+}
+
+// NewStdLogger returns a Logger writing to the logger of the standard
+// library. The debug messages are written only when debug is true.
+func NewStdLogger(l *log.Logger, debug bool) Logger {
+	return &stdLogger{l: l, debug: debug}
+}
+
+type stdLogger struct {
+	l     *log.Logger
+	debug bool
+}
+
+func (s *stdLogger) Debugf(format string, args ...interface{}) {
+	if s.debug {
+		s.l.Printf("DEBUG "+format, args...)
+	}
+}
+
+func (s *stdLogger) Infof(format string, args ...interface{}) {
+	s.l.Printf("INFO "+format, args...)
+}
+
+func (s *stdLogger) Warnf(format string, args ...interface{}) {
+	s.l.Printf("WARN "+format, args...)
+}
+
+// nopLogger discards the messages.
+type nopLogger struct{}
+
+func (nopLogger) Debugf(format string, args ...interface{}) {}
+func (nopLogger) Infof(format string, args ...interface{})  {}
+func (nopLogger) Warnf(format string, args ...interface{})  {}
+
+// logger returns the logger of the client.
+func (cli *Client) logger() Logger {
+	if cli == nil || cli.Logger == nil {
+		return nopLogger{}
+	}
+	return cli.Logger
+}
+
+// logger returns the logger of the client.
+func (cli *OvsClient) logger() Logger {
+	if cli.Logger == nil {
+		return nopLogger{}
+	}
+	return cli.Logger
+}
+
+// logger returns the logger of the client.
+func (cli *OvnClient) logger() Logger {
+	if cli.Logger == nil {
+		return nopLogger{}
+	}
+	return cli.Logger
+}
+
+// withLogger returns the options of a database connection, preceded by
+// the logger, when set, so that the options may override it.
+func withLogger(logger Logger, opts []ClientOption) []ClientOption {
+	if logger == nil {
+		return opts
+	}
+	return append([]ClientOption{WithLogger(logger)}, opts...)
+}
+
+// logSkippedRow reports a row of the result skipped, because its column
+// could not be parsed.
+func logSkippedRow(logger Logger, result Result, column string, reason interface{}) {
+	logger.Warnf("%s: skipping a row of '%s' table: '%s' column: %v", result.Database, result.Table, column, reason)
+}
+
+// unexpectedType describes a column value of an unexpected data type.
+func unexpectedType(dt string) error {
+	return fmt.Errorf("unexpected data type %s", dt)
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testLogger records the messages.
+type testLogger struct {
+	mux      sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) record(level, format string, args ...interface{}) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.messages = append(l.messages, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) { l.record("DEBUG", format, args...) }
+func (l *testLogger) Infof(format string, args ...interface{})  { l.record("INFO", format, args...) }
+func (l *testLogger) Warnf(format string, args ...interface{})  { l.record("WARN", format, args...) }
+
+func (l *testLogger) contains(s string) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	for _, m := range l.messages {
+		if strings.Contains(m, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClientLogger(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	newTestServer(t, l, nil)
+	logger := &testLogger{}
+	cli, err := NewClient("unix:"+sock, 1, WithLogger(logger))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	cli.Failover()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	for _, want := range []string{
+		"DEBUG connecting to unix:" + sock,
+		"INFO connected to unix:" + sock,
+		"DEBUG 'echo' request completed in",
+		"INFO reconnecting to unix:" + sock,
+	} {
+		if !logger.contains(want) {
+			t.Fatalf("FAIL: expected message %q, but got: %v", want, logger.messages)
+		}
+		t.Logf("PASS: logged %q", want)
+	}
+
+	if _, err := NewClient("unix:"+filepath.Join(t.TempDir(), "missing.sock"), 1, WithLogger(logger)); err == nil {
+		t.Fatalf("FAIL: expected to fail connecting")
+	}
+	if !logger.contains("WARN failed connecting to unix:") {
+		t.Fatalf("FAIL: expected failed connection warning, but got: %v", logger.messages)
+	}
+	t.Logf("PASS: logged failed connection")
+}
+
+func TestLogSkippedRow(t *testing.T) {
+	logger := &testLogger{}
+	result := Result{Database: "OVN_Northbound", Table: "ACL"}
+	logSkippedRow(logger, result, "_uuid", unexpectedType("integer"))
+	want := "WARN OVN_Northbound: skipping a row of 'ACL' table: '_uuid' column: unexpected data type integer"
+	if !logger.contains(want) {
+		t.Fatalf("FAIL: expected message %q, but got: %v", want, logger.messages)
+	}
+	t.Logf("PASS: logged %q", want)
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), false)
+	logger.Debugf("debug %d", 1)
+	logger.Infof("info %d", 2)
+	logger.Warnf("warn %d", 3)
+	if got, want := buf.String(), "INFO info 2\nWARN warn 3\n"; got != want {
+		t.Fatalf("FAIL: expected %q, but got %q", want, got)
+	}
+	buf.Reset()
+	NewStdLogger(log.New(&buf, "", 0), true).Debugf("debug %d", 1)
+	if got, want := buf.String(), "DEBUG debug 1\n"; got != want {
+		t.Fatalf("FAIL: expected %q, but got %q", want, got)
+	}
+	t.Logf("PASS: standard library logger")
+}
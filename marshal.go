@@ -0,0 +1,360 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Unmarshal stores the rows of the result in the slice pointed to by v,
+// whose elements are structs, or pointers to structs, with fields tagged
+// with the columns, e.g.
+//
+//	type Bridge struct {
+//		UUID  UUID              `ovsdb:"_uuid"`
+//		Name  string            `ovsdb:"name"`
+//		Ports []UUID            `ovsdb:"ports"`
+//		IDs   map[string]string `ovsdb:"external_ids"`
+//		Tag   *int              `ovsdb:"tag"`
+//	}
+//
+// See Row.Unmarshal for the conversions.
+func (r *Result) Unmarshal(v interface{}) error {
+	return r.unmarshal(v, false, nil)
+}
+
+// unmarshal is like Unmarshal, but when strict, it fails on the rows missing
+// the columns of the fields too. The errors decoding a column of a row are
+// passed to skip, when set, which returns nil for the row to be skipped.
+func (r *Result) unmarshal(v interface{}, strict bool, skip func(*columnError) error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("unmarshal: expected a pointer to a slice, got %T", v)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	out := reflect.MakeSlice(slice.Type(), 0, len(r.Rows))
+	for i, row := range r.Rows {
+		elem := reflect.New(elemType).Elem()
+		target := elem
+		if elemType.Kind() == reflect.Ptr {
+			elem.Set(reflect.New(elemType.Elem()))
+			target = elem.Elem()
+		}
+		if target.Kind() != reflect.Struct {
+			return fmt.Errorf("unmarshal: expected a slice of structs, got %T", v)
+		}
+		if err := row.decodeStruct(target, strict); err != nil {
+			var ce *columnError
+			if skip == nil || !errors.As(err, &ce) {
+				return fmt.Errorf("unmarshal: row %d: %s", i, err)
+			}
+			if err := skip(ce); err != nil {
+				return err
+			}
+			continue
+		}
+		out = reflect.Append(out, elem)
+	}
+	slice.Set(out)
+	return nil
+}
+
+// Unmarshal stores the columns of the row in the fields of the struct
+// pointed to by v, which are tagged with the columns, e.g. `ovsdb:"name"`.
+// The untagged fields, and the fields of the columns missing from the row,
+// are left as they are. The sets are stored in slices, the maps in maps,
+// and the references in strings or UUIDs, which must be valid, or as UUID
+// and NamedUUID values in the interface{} fields. A pointer field is nil
+// when its optional column, i.e. a set of at most one element, is empty.
+func (r Row) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal: expected a pointer to a struct, got %T", v)
+	}
+	if err := r.decodeStruct(rv.Elem(), false); err != nil {
+		return fmt.Errorf("unmarshal: %s", err)
+	}
+	return nil
+}
+
+// decodeStruct stores the columns of the row in the tagged fields of the
+// struct. When strict, the columns of all those fields must be present.
+func (r Row) decodeStruct(rv reflect.Value, strict bool) error {
+	for i := 0; i < rv.NumField(); i++ {
+		column, _, ok := columnTag(rv.Type().Field(i))
+		if !ok {
+			continue
+		}
+		data, exists := r[column]
+		if !exists {
+			if strict {
+				return &columnError{column, ErrColumnNotFound}
+			}
+			continue
+		}
+		if err := decodeColumn(rv.Field(i), data); err != nil {
+			return &columnError{column, err}
+		}
+	}
+	return nil
+}
+
+// columnTag returns the column of the field and whether it is omitted when
+// empty.
+func columnTag(f reflect.StructField) (string, bool, bool) {
+	tag, ok := f.Tag.Lookup("ovsdb")
+	if !ok || tag == "-" || f.PkgPath != "" {
+		return "", false, false
+	}
+	arr := strings.Split(tag, ",")
+	omitEmpty := false
+	for _, opt := range arr[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	if arr[0] == "" {
+		return "", false, false
+	}
+	return arr[0], omitEmpty, true
+}
+
+// decodeColumn stores the value of a column, in the OVSDB notation, in the
+// field.
+func decodeColumn(field reflect.Value, data interface{}) error {
+	if field.CanAddr() {
+		if o, ok := field.Addr().Interface().(optionalDecoder); ok {
+			return o.decodeOptional(data)
+		}
+	}
+	switch field.Type() {
+	case ovsSetType:
+		s, err := newOvsSet(data)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(s))
+		return nil
+	case ovsMapType:
+		m, err := newOvsMap(data)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(m))
+		return nil
+	}
+	kind, elems := splitValue(data)
+	switch field.Kind() {
+	case reflect.Ptr:
+		if kind == "set" && len(elems) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		v := reflect.New(field.Type().Elem())
+		if err := decodeColumn(v.Elem(), data); err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	case reflect.Slice:
+		if kind == "map" {
+			return fmt.Errorf("cannot store a map in %s", field.Type())
+		}
+		out := reflect.MakeSlice(field.Type(), len(elems), len(elems))
+		for i, elem := range elems {
+			if err := decodeAtom(out.Index(i), elem); err != nil {
+				return err
+			}
+		}
+		field.Set(out)
+		return nil
+	case reflect.Map:
+		if kind != "map" && !(kind == "set" && len(elems) == 0) {
+			return fmt.Errorf("cannot store a %s in %s", kind, field.Type())
+		}
+		out := reflect.MakeMapWithSize(field.Type(), len(elems))
+		for _, elem := range elems {
+			pair, ok := elem.([]interface{})
+			if !ok || len(pair) != 2 {
+				return fmt.Errorf("invalid map pair %v", elem)
+			}
+			k := reflect.New(field.Type().Key()).Elem()
+			if err := decodeAtom(k, pair[0]); err != nil {
+				return err
+			}
+			v := reflect.New(field.Type().Elem()).Elem()
+			if err := decodeAtom(v, pair[1]); err != nil {
+				return err
+			}
+			out.SetMapIndex(k, v)
+		}
+		field.Set(out)
+		return nil
+	}
+	// A scalar holds an atom, or an optional value, i.e. a set of at most
+	// one element.
+	switch {
+	case kind == "map":
+		return fmt.Errorf("cannot store a map in %s", field.Type())
+	case len(elems) == 0:
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	case len(elems) > 1:
+		return fmt.Errorf("cannot store a set of %d elements in %s", len(elems), field.Type())
+	}
+	return decodeAtom(field, elems[0])
+}
+
+// splitValue splits the value, in the OVSDB notation, into its kind, i.e.
+// "set", "map", or "atom", and its elements. An atom is its only element.
+func splitValue(data interface{}) (string, []interface{}) {
+	if arr, ok := data.([]interface{}); ok && len(arr) == 2 {
+		switch arr[0] {
+		case "set", "map":
+			if elems, ok := arr[1].([]interface{}); ok {
+				return arr[0].(string), elems
+			}
+		}
+	}
+	return "atom", []interface{}{data}
+}
+
+var (
+	uuidType   = reflect.TypeOf(UUID(""))
+	ovsSetType = reflect.TypeOf(OvsSet{})
+	ovsMapType = reflect.TypeOf(OvsMap{})
+)
+
+// decodeAtom stores the atom, in the OVSDB notation, in the value.
+func decodeAtom(v reflect.Value, data interface{}) error {
+	if arr, ok := data.([]interface{}); ok && len(arr) == 2 {
+		// A reference, i.e. ["uuid", "..."] or ["named-uuid", "..."].
+		if s, ok := arr[1].(string); ok {
+			switch arr[0] {
+			case "uuid":
+				if v.Type() == uuidType && !UUID(s).Valid() {
+					return fmt.Errorf("invalid uuid %q", s)
+				}
+				data = UUID(s)
+			case "named-uuid":
+				data = NamedUUID(s)
+			}
+		}
+	}
+	switch x := data.(type) {
+	case UUID:
+		if v.Kind() == reflect.String {
+			v.SetString(string(x))
+			return nil
+		}
+	case NamedUUID:
+		if v.Kind() == reflect.String {
+			v.SetString(string(x))
+			return nil
+		}
+	case string:
+		if v.Kind() == reflect.String {
+			v.SetString(x)
+			return nil
+		}
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(x)
+			return nil
+		}
+	case float64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v.SetInt(int64(x))
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if x < 0 {
+				return fmt.Errorf("cannot store %v in %s", x, v.Type())
+			}
+			v.SetUint(uint64(x))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(x)
+			return nil
+		}
+	case int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v.SetInt(x)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if x < 0 {
+				return fmt.Errorf("cannot store %v in %s", x, v.Type())
+			}
+			v.SetUint(uint64(x))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(x))
+			return nil
+		}
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		v.Set(reflect.ValueOf(data))
+		return nil
+	}
+	return fmt.Errorf("cannot store %T in %s", data, v.Type())
+}
+
+// MarshalRow returns the tagged fields of the struct, or of the struct
+// pointed to by v, as a row for the Insert and Update operations. The
+// fields tagged with "omitempty" are left out when they are empty, and the
+// nil pointers are stored as empty sets. The read-only _uuid and _version
+// columns are left out.
+func MarshalRow(v interface{}) (map[string]interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("marshal: expected a struct, got %T", v)
+	}
+	row := make(map[string]interface{})
+	for i := 0; i < rv.NumField(); i++ {
+		column, omitEmpty, ok := columnTag(rv.Type().Field(i))
+		if !ok || column == "_uuid" || column == "_version" {
+			continue
+		}
+		field := rv.Field(i)
+		if omitEmpty && field.IsZero() {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.Ptr:
+			if field.IsNil() {
+				row[column] = nil
+				continue
+			}
+			row[column] = field.Elem().Interface()
+		case reflect.Slice, reflect.Map:
+			if field.IsNil() {
+				row[column] = []interface{}{}
+				continue
+			}
+			row[column] = field.Interface()
+		default:
+			row[column] = field.Interface()
+		}
+	}
+	return row, nil
+}
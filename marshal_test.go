@@ -0,0 +1,121 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testBridge struct {
+	UUID     UUID              `ovsdb:"_uuid"`
+	Name     string            `ovsdb:"name"`
+	Ports    []UUID            `ovsdb:"ports"`
+	IDs      map[string]string `ovsdb:"external_ids"`
+	Tag      *int              `ovsdb:"tag"`
+	Mode     string            `ovsdb:"fail_mode,omitempty"`
+	STP      bool              `ovsdb:"stp_enable"`
+	Comment  string
+	internal string `ovsdb:"internal"`
+}
+
+func TestResultUnmarshal(t *testing.T) {
+	var result Result
+	data := `{"rows":[
+		{"_uuid":["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"],"name":"br0","ports":["set",[["uuid","7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c1"],["uuid","7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c2"]]],
+		 "external_ids":["map",[["owner","ovn"]]],"tag":["set",[]],"fail_mode":["set",[]],"stp_enable":true},
+		{"_uuid":["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b2"],"name":"br1","ports":["uuid","7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c3"],
+		 "external_ids":["map",[]],"tag":10,"fail_mode":"secure","stp_enable":false}
+	]}`
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("FAIL: failed to decode the result: %v", err)
+	}
+	var bridges []testBridge
+	if err := result.Unmarshal(&bridges); err != nil {
+		t.Fatalf("FAIL: failed to unmarshal the rows: %v", err)
+	}
+	tag := 10
+	expected := []testBridge{
+		{UUID: "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1", Name: "br0", Ports: []UUID{"7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c1", "7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c2"}, IDs: map[string]string{"owner": "ovn"}, STP: true},
+		{UUID: "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b2", Name: "br1", Ports: []UUID{"7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c3"}, IDs: map[string]string{}, Tag: &tag, Mode: "secure"},
+	}
+	if !reflect.DeepEqual(bridges, expected) {
+		t.Fatalf("FAIL: expected %+v, got %+v", expected, bridges)
+	}
+	t.Logf("PASS: unmarshaled %d rows", len(bridges))
+
+	var pointers []*testBridge
+	if err := result.Unmarshal(&pointers); err != nil || len(pointers) != 2 || pointers[1].Name != "br1" {
+		t.Fatalf("FAIL: failed to unmarshal the rows into pointers: %v", err)
+	}
+	t.Logf("PASS: unmarshaled the rows into pointers")
+
+	var bridge testBridge
+	if err := result.Rows[0].Unmarshal(&bridge); err != nil || bridge.Name != "br0" {
+		t.Fatalf("FAIL: failed to unmarshal the row: %v", err)
+	}
+	t.Logf("PASS: unmarshaled a single row")
+}
+
+func TestResultUnmarshalErrors(t *testing.T) {
+	testFailures := []struct {
+		row    Row
+		target interface{}
+		err    string
+	}{
+		{Row{"name": 1.0}, &[]testBridge{}, "column name: cannot store float64 in string"},
+		{Row{"ports": []interface{}{"map", []interface{}{}}}, &[]testBridge{}, "cannot store a map"},
+		{Row{"name": []interface{}{"set", []interface{}{"a", "b"}}}, &[]testBridge{}, "set of 2 elements"},
+		{Row{"name": "br0"}, []testBridge{}, "expected a pointer to a slice"},
+		{Row{"name": "br0"}, &[]string{}, "expected a slice of structs"},
+	}
+	for _, test := range testFailures {
+		result := Result{Rows: []Row{test.row}}
+		err := result.Unmarshal(test.target)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("FAIL: expected error %q, got %v", test.err, err)
+		}
+		t.Logf("PASS: %v", err)
+	}
+}
+
+func TestMarshalRow(t *testing.T) {
+	tag := 5
+	row, err := MarshalRow(&testBridge{
+		UUID:  "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1",
+		Name:  "br0",
+		Ports: []UUID{"7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c1"},
+		Tag:   &tag,
+	})
+	if err != nil {
+		t.Fatalf("FAIL: failed to marshal the row: %v", err)
+	}
+	b, err := json.Marshal(Insert("Bridge", row))
+	if err != nil {
+		t.Fatalf("FAIL: failed to marshal the operation: %v", err)
+	}
+	expected := `{"op":"insert","table":"Bridge","row":{"external_ids":["set",[]],"name":"br0","ports":["set",[["uuid","7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c1"]]],"stp_enable":false,"tag":5}}`
+	if string(b) != expected {
+		t.Fatalf("FAIL: expected %s, got %s", expected, b)
+	}
+	t.Logf("PASS: %s", b)
+
+	if _, err := MarshalRow("br0"); err == nil {
+		t.Fatalf("FAIL: expected an error for a non-struct")
+	}
+	t.Logf("PASS: rejected a non-struct")
+}
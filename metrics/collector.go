@@ -0,0 +1,129 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics exposes the metrics of OVSDB clients to Prometheus.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/supergate-hub/ovsdb"
+)
+
+// Collector collects the metrics of OVSDB clients, i.e. the numbers of
+// requests and errors, the durations of requests, the reconnect attempts,
+// and the bytes read and written. It is an ovsdb.Observer, which is set on
+// the clients with ovsdb.WithObserver, e.g. among the Options of an
+// ovsdb.OvsClient so that the control sockets are covered as well, and a
+// prometheus.Collector, which is registered with a registry.
+type Collector struct {
+	requests     *prometheus.CounterVec
+	errors       *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	reconnects   *prometheus.CounterVec
+	bytesRead    prometheus.Counter
+	bytesWritten prometheus.Counter
+}
+
+// NewCollector returns a collector of the metrics with the namespace, e.g.
+// "ovn_exporter".
+func NewCollector(namespace string) *Collector {
+	labels := []string{"database", "method"}
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ovsdb_client",
+			Name:      "requests_total",
+			Help:      "The number of requests sent to the OVSDB servers.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ovsdb_client",
+			Name:      "request_errors_total",
+			Help:      "The number of requests to the OVSDB servers which failed.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "ovsdb_client",
+			Name:      "request_duration_seconds",
+			Help:      "The durations of the requests to the OVSDB servers.",
+			Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, labels),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ovsdb_client",
+			Name:      "reconnects_total",
+			Help:      "The number of attempts to reconnect to the OVSDB servers.",
+		}, []string{"endpoint", "result"}),
+		bytesRead: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ovsdb_client",
+			Name:      "read_bytes_total",
+			Help:      "The number of bytes read from the OVSDB servers.",
+		}),
+		bytesWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ovsdb_client",
+			Name:      "written_bytes_total",
+			Help:      "The number of bytes written to the OVSDB servers.",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.errors.Describe(ch)
+	c.duration.Describe(ch)
+	c.reconnects.Describe(ch)
+	c.bytesRead.Describe(ch)
+	c.bytesWritten.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.errors.Collect(ch)
+	c.duration.Collect(ch)
+	c.reconnects.Collect(ch)
+	c.bytesRead.Collect(ch)
+	c.bytesWritten.Collect(ch)
+}
+
+// ObserveRequest implements ovsdb.Observer.
+func (c *Collector) ObserveRequest(event ovsdb.RequestEvent) {
+	c.requests.WithLabelValues(event.Database, event.Method).Inc()
+	if event.Err != nil {
+		c.errors.WithLabelValues(event.Database, event.Method).Inc()
+	}
+	c.duration.WithLabelValues(event.Database, event.Method).Observe(event.Duration.Seconds())
+}
+
+// ObserveReconnect implements ovsdb.Observer.
+func (c *Collector) ObserveReconnect(event ovsdb.ReconnectEvent) {
+	result := "success"
+	if event.Err != nil {
+		result = "failure"
+	}
+	c.reconnects.WithLabelValues(event.Endpoint, result).Inc()
+}
+
+// ObserveBytes implements ovsdb.Observer.
+func (c *Collector) ObserveBytes(read, written int) {
+	if read > 0 {
+		c.bytesRead.Add(float64(read))
+	}
+	if written > 0 {
+		c.bytesWritten.Add(float64(written))
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/supergate-hub/ovsdb"
+)
+
+// newEchoServer starts a server which answers echo requests.
+func newEchoServer(t *testing.T) string {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				dec := json.NewDecoder(conn)
+				enc := json.NewEncoder(conn)
+				for {
+					var req map[string]interface{}
+					if err := dec.Decode(&req); err != nil {
+						return
+					}
+					resp := map[string]interface{}{"id": req["id"], "result": req["params"], "error": nil}
+					if err := enc.Encode(resp); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		l.Close()
+	})
+	return "unix:" + sock
+}
+
+func TestCollector(t *testing.T) {
+	remote := newEchoServer(t)
+	collector := NewCollector("test")
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatalf("FAIL: failed to register the collector: %v", err)
+	}
+	cli, err := ovsdb.NewClient(remote, 1, ovsdb.WithObserver(collector))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	for i := 0; i < 3; i++ {
+		if err := cli.Echo("test message"); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+	collector.ObserveRequest(ovsdb.RequestEvent{Database: "OVN_Southbound", Method: "transact", Duration: time.Millisecond, Err: errors.New("failed")})
+	collector.ObserveReconnect(ovsdb.ReconnectEvent{Endpoint: remote, Attempt: 1})
+
+	for _, test := range []struct {
+		name   string
+		metric prometheus.Collector
+		want   float64
+	}{
+		{name: "echo requests", metric: collector.requests.WithLabelValues("", "echo"), want: 3},
+		{name: "echo errors", metric: collector.errors.WithLabelValues("", "echo"), want: 0},
+		{name: "transact errors", metric: collector.errors.WithLabelValues("OVN_Southbound", "transact"), want: 1},
+		{name: "reconnects", metric: collector.reconnects.WithLabelValues(remote, "success"), want: 1},
+	} {
+		if got := testutil.ToFloat64(test.metric); got != test.want {
+			t.Fatalf("FAIL: %s: expected %v, but got %v", test.name, test.want, got)
+		}
+		t.Logf("PASS: %s: %v", test.name, test.want)
+	}
+	if testutil.ToFloat64(collector.bytesRead) == 0 || testutil.ToFloat64(collector.bytesWritten) == 0 {
+		t.Fatalf("FAIL: expected the bytes read and written to be counted")
+	}
+	expected := `
+# HELP test_ovsdb_client_requests_total The number of requests sent to the OVSDB servers.
+# TYPE test_ovsdb_client_requests_total counter
+test_ovsdb_client_requests_total{database="",method="echo"} 3
+test_ovsdb_client_requests_total{database="OVN_Southbound",method="transact"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "test_ovsdb_client_requests_total"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	t.Logf("PASS: metrics gathered by the registry")
+}
@@ -0,0 +1,235 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Model is a struct whose fields are tagged with the columns of a table,
+// see Row.Unmarshal and MarshalRow. The models of libovsdb, whose fields
+// share the `ovsdb` tags, and hold the references as strings and the
+// optional values as pointers, are models too, so that the structs written
+// or generated for either package may be used with both.
+type Model interface{}
+
+// ClientDBModel maps the tables of a database to the types of their
+// models, like the ClientDBModel of libovsdb, e.g.
+//
+//	m, err := ovsdb.NewClientDBModel("OVN_Northbound", map[string]ovsdb.Model{
+//		"Logical_Switch":      &LogicalSwitch{},
+//		"Logical_Switch_Port": &LogicalSwitchPort{},
+//	})
+type ClientDBModel struct {
+	name   string
+	types  map[string]reflect.Type
+	tables map[reflect.Type]string
+}
+
+// NewClientDBModel returns the model of the database, whose models are
+// pointers to the structs of the tables.
+func NewClientDBModel(name string, models map[string]Model) (ClientDBModel, error) {
+	m := ClientDBModel{
+		name:   name,
+		types:  make(map[string]reflect.Type, len(models)),
+		tables: make(map[reflect.Type]string, len(models)),
+	}
+	for table, model := range models {
+		t := reflect.TypeOf(model)
+		if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			return ClientDBModel{}, fmt.Errorf("model of table %s: expected a pointer to a struct, got %T", table, model)
+		}
+		if other, exists := m.tables[t.Elem()]; exists {
+			return ClientDBModel{}, fmt.Errorf("model of table %s: %s is the model of table %s", table, t.Elem(), other)
+		}
+		m.types[table] = t.Elem()
+		m.tables[t.Elem()] = table
+	}
+	return m, nil
+}
+
+// Name returns the name of the database.
+func (m ClientDBModel) Name() string {
+	return m.name
+}
+
+// Tables returns the sorted tables of the models.
+func (m ClientDBModel) Tables() []string {
+	tables := make([]string, 0, len(m.types))
+	for table := range m.types {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// FindTable returns the table of the model type, a struct or a pointer to
+// it, or an empty string when it is not a model of the database.
+func (m ClientDBModel) FindTable(t reflect.Type) string {
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return m.tables[t]
+}
+
+// NewModel returns a pointer to a new model of the table.
+func (m ClientDBModel) NewModel(table string) (Model, error) {
+	t, exists := m.types[table]
+	if !exists {
+		return nil, fmt.Errorf("no model of table %s", table)
+	}
+	return reflect.New(t).Interface(), nil
+}
+
+// Validate checks that the schema of the database has the tables of the
+// models and the columns of their tagged fields.
+func (m ClientDBModel) Validate(schema Schema) error {
+	if schema.Name != m.name {
+		return fmt.Errorf("the model of %s database does not match the %s schema", m.name, schema.Name)
+	}
+	for _, table := range m.Tables() {
+		t := schema.Table(table)
+		if t == nil {
+			return fmt.Errorf("model of %s: %w", m.types[table], &tableNotFoundError{table})
+		}
+		typ := m.types[table]
+		for i := 0; i < typ.NumField(); i++ {
+			column, _, ok := columnTag(typ.Field(i))
+			if !ok {
+				continue
+			}
+			if t.Column(column) == nil {
+				return fmt.Errorf("model of %s: column %s of table %s: %w", typ, column, table, ErrColumnNotFound)
+			}
+		}
+	}
+	return nil
+}
+
+// List stores the rows of the table of the models in the slice pointed to
+// by result, whose elements are models of the database, or pointers to
+// them, e.g. *[]LogicalSwitch. The rows are filtered by the conditions.
+// The rows which cannot be decoded are skipped, or fail the call, in the
+// strict decode mode, as do the rows missing a column of the model.
+func (c *Client) List(ctx context.Context, m ClientDBModel, result interface{}, where ...Condition) error {
+	rv := reflect.ValueOf(result)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("list: expected a pointer to a slice of models, got %T", result)
+	}
+	table := m.FindTable(rv.Elem().Type().Elem())
+	if table == "" {
+		return fmt.Errorf("list: %s is not a model of %s database", rv.Elem().Type().Elem(), m.name)
+	}
+	results, err := c.TransactOperations(ctx, m.name, Select(table, nil, where...))
+	if err != nil {
+		return err
+	}
+	r := Result{Rows: results[0].Rows, Database: m.name, Table: table}
+	return r.unmarshal(result, c.DecodeMode == DecodeStrict, func(e *columnError) error {
+		return c.skipRow(r, e.column, e.err)
+	})
+}
+
+// Create returns the insert operations of the models, to be issued with
+// TransactOperations. As with libovsdb, the references held as strings are
+// sent as UUIDs when they are valid, and as the names of the rows inserted
+// by the transaction otherwise, and a model whose _uuid is not a valid UUID
+// is inserted under that name.
+func (c *Client) Create(ctx context.Context, m ClientDBModel, models ...Model) ([]Operation, error) {
+	schema, err := c.GetSchemaContext(ctx, m.name)
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]Operation, 0, len(models))
+	for _, model := range models {
+		table := m.FindTable(reflect.TypeOf(model))
+		if table == "" {
+			return nil, fmt.Errorf("create: %T is not a model of %s database", model, m.name)
+		}
+		t := schema.Table(table)
+		if t == nil {
+			return nil, fmt.Errorf("create: %w", &tableNotFoundError{table})
+		}
+		row, err := MarshalRow(model)
+		if err != nil {
+			return nil, fmt.Errorf("create: %s", err)
+		}
+		for column, v := range row {
+			if col := t.Column(column); col != nil {
+				row[column] = col.references(v)
+			}
+		}
+		op := Insert(table, row)
+		if uuid := modelUUID(model); uuid != "" && !UUID(uuid).Valid() {
+			op.UUIDName = uuid
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// modelUUID returns the _uuid of the model, or an empty string.
+func modelUUID(model Model) string {
+	rv := reflect.Indirect(reflect.ValueOf(model))
+	for i := 0; i < rv.NumField(); i++ {
+		column, _, ok := columnTag(rv.Type().Field(i))
+		if ok && column == "_uuid" && rv.Field(i).Kind() == reflect.String {
+			return rv.Field(i).String()
+		}
+	}
+	return ""
+}
+
+// references converts the strings of the value of a reference column to
+// UUID, or NamedUUID when they are not valid UUIDs.
+func (c *Column) references(v interface{}) interface{} {
+	keyRef := c.Key.Type == "uuid"
+	valueRef := c.IsMap() && c.Value.Type == "uuid"
+	if v == nil || (!keyRef && !valueRef) {
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		elems := make([]interface{}, rv.Len())
+		for i := range elems {
+			elems[i] = reference(rv.Index(i).Interface(), keyRef)
+		}
+		return elems
+	case reflect.Map:
+		pairs := make(map[interface{}]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			pairs[reference(iter.Key().Interface(), keyRef)] = reference(iter.Value().Interface(), valueRef)
+		}
+		return pairs
+	}
+	return reference(v, keyRef)
+}
+
+// reference converts a string to a UUID, or a NamedUUID, when ref is set.
+func reference(v interface{}, ref bool) interface{} {
+	s, ok := v.(string)
+	if !ok || !ref {
+		return v
+	}
+	if UUID(s).Valid() {
+		return UUID(s)
+	}
+	return NamedUUID(s)
+}
@@ -0,0 +1,131 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// testLogicalSwitch and testLogicalSwitchPort are models in the style of
+// the ones libovsdb generates.
+type testLogicalSwitch struct {
+	UUID        string            `ovsdb:"_uuid"`
+	Name        string            `ovsdb:"name"`
+	Ports       []string          `ovsdb:"ports"`
+	ExternalIDs map[string]string `ovsdb:"external_ids"`
+}
+
+type testLogicalSwitchPort struct {
+	UUID string `ovsdb:"_uuid"`
+	Name string `ovsdb:"name"`
+	Up   *bool  `ovsdb:"up"`
+	Tag  *int   `ovsdb:"tag"`
+}
+
+func TestClientDBModel(t *testing.T) {
+	if _, err := NewClientDBModel("OVN_Northbound", map[string]Model{"Logical_Switch": testLogicalSwitch{}}); err == nil {
+		t.Fatalf("FAIL: expected a model which is not a pointer to fail")
+	}
+	m, err := NewClientDBModel("OVN_Northbound", map[string]Model{
+		"Logical_Switch":      &testLogicalSwitch{},
+		"Logical_Switch_Port": &testLogicalSwitchPort{},
+	})
+	if err != nil {
+		t.Fatalf("FAIL: expected a model, but failed with: %v", err)
+	}
+	if table := m.FindTable(reflect.TypeOf(&testLogicalSwitchPort{})); table != "Logical_Switch_Port" {
+		t.Fatalf("FAIL: expected the Logical_Switch_Port table, got %q", table)
+	}
+	if model, err := m.NewModel("Logical_Switch"); err != nil || reflect.TypeOf(model) != reflect.TypeOf(&testLogicalSwitch{}) {
+		t.Fatalf("FAIL: unexpected new model: %T, %v", model, err)
+	}
+	schema, err := BundledSchema("OVN_Northbound")
+	if err != nil {
+		t.Fatalf("FAIL: expected the bundled schema, but failed with: %v", err)
+	}
+	if err := m.Validate(schema); err != nil {
+		t.Fatalf("FAIL: expected the model to match the schema, but failed with: %v", err)
+	}
+	type badPort struct {
+		Name string `ovsdb:"nam"`
+	}
+	bad, _ := NewClientDBModel("OVN_Northbound", map[string]Model{"Logical_Switch_Port": &badPort{}})
+	if err := bad.Validate(schema); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("FAIL: expected ErrColumnNotFound, got: %v", err)
+	}
+	t.Logf("PASS: model of %s: %v", m.Name(), m.Tables())
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		row := map[string]interface{}{
+			"_uuid":        []interface{}{"uuid", "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"},
+			"name":         "ls0",
+			"ports":        []interface{}{"uuid", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"},
+			"external_ids": []interface{}{"map", []interface{}{}},
+		}
+		return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1, WithSchema(schema))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	var switches []testLogicalSwitch
+	if err := cli.List(context.Background(), m, &switches, Equal("name", "ls0")); err != nil {
+		t.Fatalf("FAIL: expected to list the switches, but failed with: %v", err)
+	}
+	if len(switches) != 1 || switches[0].UUID != "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c" || switches[0].Ports[0] != "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31" {
+		t.Fatalf("FAIL: unexpected switches: %+v", switches)
+	}
+	var other []int
+	if err := cli.List(context.Background(), m, &other); err == nil {
+		t.Fatalf("FAIL: expected a slice of another type to fail")
+	}
+	t.Logf("PASS: listed switches: %+v", switches)
+
+	up := true
+	ops, err := cli.Create(context.Background(), m,
+		&testLogicalSwitchPort{UUID: "lsp0", Name: "lsp0", Up: &up},
+		&testLogicalSwitch{Name: "ls1", Ports: []string{"lsp0", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"}},
+	)
+	if err != nil || len(ops) != 2 {
+		t.Fatalf("FAIL: expected two operations, got: %v, %v", ops, err)
+	}
+	if ops[0].Table != "Logical_Switch_Port" || ops[0].UUIDName != "lsp0" || ops[0].Row["up"] != true || ops[0].Row["tag"] != nil {
+		t.Fatalf("FAIL: unexpected port insert: %+v", ops[0])
+	}
+	ports := []interface{}{NamedUUID("lsp0"), UUID("5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31")}
+	if ops[1].Table != "Logical_Switch" || ops[1].UUIDName != "" || !reflect.DeepEqual(ops[1].Row["ports"], ports) {
+		t.Fatalf("FAIL: unexpected switch insert: %+v", ops[1])
+	}
+	for i, op := range ops {
+		if err := schema.ValidateOperation(op); err != nil {
+			t.Fatalf("FAIL: expected operation %d to be valid, but failed with: %v", i, err)
+		}
+	}
+	t.Logf("PASS: created operations: %+v", ops)
+}
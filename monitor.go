@@ -0,0 +1,892 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// RowUpdate is the change of a row reported by a monitor. Old is the row
+// before the change, nil for the inserted rows and for those of the initial
+// snapshot, and New is the row after it, nil for the deleted rows. Both
+// hold the monitored columns of the row, and must not be modified.
+type RowUpdate struct {
+	Old Row `json:"old,omitempty"`
+	New Row `json:"new,omitempty"`
+}
+
+// TableUpdate are the changes of the rows of a table, by UUID.
+type TableUpdate map[UUID]RowUpdate
+
+// TableUpdates are the changes of the tables of a database, by table.
+type TableUpdates map[string]TableUpdate
+
+// zeroTxnID is the last transaction id of a monitor_cond_since request
+// which starts a monitor afresh.
+const zeroTxnID = "00000000-0000-0000-0000-000000000000"
+
+// Monitor is a monitor of tables of a database, started by Client.Monitor.
+type Monitor struct {
+	// Updates receives the initial snapshot of the monitored rows, which
+	// may be empty, then their changes, a value per transaction, in order.
+	// It is closed when the monitor ends, see Err.
+	Updates <-chan TableUpdates
+	// Method is the method the monitor was requested with, "monitor",
+	// "monitor_cond", or "monitor_cond_since".
+	Method string
+
+	id     string
+	db     string
+	client *Client
+	// schema is the schema the updates are applied with. It is replaced
+	// by the goroutine dispatching them, with the monitor locked.
+	schema   Schema
+	requests map[string]MonitorRequest
+	// rows are the monitored rows, by table and UUID. They are only
+	// accessed by the goroutine dispatching the updates, as is delivered.
+	rows      map[string]map[UUID]Row
+	delivered bool
+	sink      func(updates TableUpdates, txnID string, resync bool)
+	// restored is true until the monitor restored with rows is requested,
+	// whose reply is a resync, unless it resumes from their transaction.
+	restored bool
+	// refreshSchema and onSchema are those of the seed.
+	refreshSchema func() bool
+	onSchema      func(schema Schema)
+	// progress, when set, reports the progress of the initial sync.
+	progress *syncProgress
+	updates  chan TableUpdates
+	done     chan struct{}
+
+	mux sync.Mutex
+	// pending are the updates to dispatch, and held those received while
+	// the monitor is requested, which follow its reply.
+	pending []monitorUpdate
+	held    []monitorUpdate
+	live    bool
+	// liveUntil is when the connection of the monitor was last heard from,
+	// once the monitor is no longer live.
+	liveUntil   time.Time
+	started     bool
+	dispatching bool
+	stopped     bool
+	err         error
+	lastTxnID   string
+}
+
+// monitorUpdate is the table updates of a reply, or of a notification, of
+// a monitor. The rows of a resync replace the monitored rows.
+type monitorUpdate struct {
+	body   json.RawMessage
+	resync bool
+	txnID  string
+	// schema, when set, is the schema of the database, which changed as
+	// the monitor resumed, and which the update and those following it
+	// are applied with.
+	schema *Schema
+}
+
+// Monitor monitors the tables of the database, see RFC 7047, Section
+// 4.1.5, so that the changes of their rows are pushed to the client rather
+// than polled. The columns are the monitored columns, by table, and the
+// tables it does not list are monitored in full. It uses the most capable
+// monitor method the server supports, see Capabilities. The context bounds
+// the request of the monitor, which runs until it is cancelled, or its
+// connection breaks.
+//
+// A monitor_cond_since monitor survives its connection: it is requested
+// anew when the connection breaks, from the last transaction it received,
+// so that the server only sends the changes since. When the server no
+// longer has the transaction, e.g. after compacting its log, it sends the
+// rows, and Updates receives their differences with the monitored ones.
+func (c *Client) Monitor(ctx context.Context, db string, tables []string, columns map[string][]string) (*Monitor, error) {
+	requests := make(map[string]MonitorRequest, len(tables))
+	for _, table := range tables {
+		requests[table] = MonitorRequest{Columns: columns[table]}
+	}
+	return c.MonitorTables(ctx, db, requests)
+}
+
+// MonitorRequest selects the columns, and the rows, of a table monitored by
+// MonitorTables.
+type MonitorRequest struct {
+	// Columns are the monitored columns, all of them when empty.
+	Columns []string
+	// Where, when set, restricts the monitored rows to those matching all
+	// the conditions, e.g. the Port_Binding rows of the local chassis. It
+	// requires monitor_cond, see Monitor.SetConditions.
+	Where []Condition
+}
+
+// MonitorTables is like Monitor, but takes the columns, and the conditions
+// on the rows, of the monitored tables, by table.
+func (c *Client) MonitorTables(ctx context.Context, db string, requests map[string]MonitorRequest) (*Monitor, error) {
+	return c.monitorTables(ctx, db, requests, monitorSeed{})
+}
+
+// monitorSeed is the state a monitor starts from: the rows it restores,
+// and the last transaction they are at, and the sink the updates are
+// passed to, in place of Updates, along with the id of their transaction,
+// and whether they resync the rows.
+type monitorSeed struct {
+	rows  map[string]map[UUID]Row
+	txnID string
+	sink  func(updates TableUpdates, txnID string, resync bool)
+	// method, when set, is the method to request the monitor with, rather
+	// than the most capable one.
+	method string
+	// refreshSchema, when set, reports whether the monitor fetches the
+	// schema of the database anew when it resumes.
+	refreshSchema func() bool
+	// onSchema, when set, is called with the schema fetched as the
+	// monitor resumed, should it have changed, ahead of the updates
+	// applied with it.
+	onSchema func(schema Schema)
+}
+
+func (c *Client) monitorTables(ctx context.Context, db string, requests map[string]MonitorRequest, seed monitorSeed) (*Monitor, error) {
+	if c == nil || c.monitors == nil {
+		return nil, fmt.Errorf("'monitor' method failed: interface is unavailable")
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("'monitor' method failed for '%s' database: no tables", db)
+	}
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("'monitor' method failed for '%s' database: %w", db, err)
+	}
+	method := seed.method
+	if method == "" {
+		method = c.Capabilities().MonitorMethod()
+	}
+	tables := make(map[string]MonitorRequest, len(requests))
+	for table, request := range requests {
+		if err := checkMonitorRequest(schema, table, request); err != nil {
+			return nil, fmt.Errorf("'%s' method failed for '%s' database: %w", method, db, err)
+		}
+		if len(request.Where) > 0 && method == "monitor" {
+			return nil, fmt.Errorf("'%s' method failed for '%s' database: table %s: conditions require monitor_cond", method, db, table)
+		}
+		tables[table] = request
+	}
+	updates := make(chan TableUpdates, c.MonitorBuffer)
+	m := &Monitor{
+		Updates:       updates,
+		Method:        method,
+		db:            db,
+		client:        c,
+		schema:        schema,
+		requests:      tables,
+		rows:          make(map[string]map[UUID]Row, len(tables)),
+		updates:       updates,
+		done:          make(chan struct{}),
+		lastTxnID:     zeroTxnID,
+		sink:          seed.sink,
+		refreshSchema: seed.refreshSchema,
+		onSchema:      seed.onSchema,
+	}
+	for table := range tables {
+		m.rows[table] = make(map[UUID]Row)
+		for uuid, row := range seed.rows[table] {
+			m.rows[table][uuid] = row
+			m.restored = true
+		}
+	}
+	if seed.txnID != "" {
+		m.lastTxnID = seed.txnID
+	}
+	if c.OnSyncProgress != nil {
+		m.progress = newSyncProgress(c, db, len(tables))
+	}
+	// The monitor is registered before it is requested, because the
+	// server may send updates right after the initial snapshot.
+	c.monitors.add(m)
+	u, err := m.request(ctx)
+	if err != nil {
+		m.stop(err)
+		return nil, fmt.Errorf("'%s' method failed for '%s' database: %w", method, db, err)
+	}
+	if err := m.start(u); err != nil {
+		return nil, fmt.Errorf("'%s' method failed for '%s' database: %w", method, db, err)
+	}
+	m.watch()
+	return m, nil
+}
+
+// Cancel cancels the monitor, see RFC 7047, Section 4.1.7, and closes its
+// Updates. The request is not sent when the monitor has already ended.
+func (m *Monitor) Cancel(ctx context.Context) error {
+	if !m.stop(nil) {
+		return nil
+	}
+	js, err := encodeString(m.id)
+	if err != nil {
+		return fmt.Errorf("'monitor_cancel' method failed for '%s' database: %v", m.db, err)
+	}
+	if _, err := m.client.queryContext(ctx, "monitor_cancel", js); err != nil {
+		return fmt.Errorf("'monitor_cancel' method failed for '%s' database: %w", m.db, err)
+	}
+	return nil
+}
+
+// Err returns why the monitor ended, e.g. ErrNotConnected when its
+// connection broke. It is nil while the monitor runs, and after it has
+// been cancelled.
+func (m *Monitor) Err() error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.err
+}
+
+// LastTxnID returns the id of the last transaction the monitor received,
+// which it resumes from. It is the all-zero UUID until the server reports
+// one, and empty unless the method is monitor_cond_since.
+func (m *Monitor) LastTxnID() string {
+	if m.Method != "monitor_cond_since" {
+		return ""
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.lastTxnID
+}
+
+// request sends the request of the monitor, and returns its reply. The
+// monitor_cond_since request asks for the changes since the last
+// transaction received, and its reply is a resync when the server did not
+// find it.
+func (m *Monitor) request(ctx context.Context) (monitorUpdate, error) {
+	m.mux.Lock()
+	args := []interface{}{m.db, m.id, m.wire()}
+	since := m.Method == "monitor_cond_since"
+	if since {
+		args = append(args, m.lastTxnID)
+	}
+	m.mux.Unlock()
+	b, err := json.Marshal(args)
+	if err != nil {
+		return monitorUpdate{}, err
+	}
+	response, err := m.client.queryContext(ctx, m.Method, string(b[1:len(b)-1]))
+	if err != nil {
+		return monitorUpdate{}, err
+	}
+	if !since {
+		m.mux.Lock()
+		defer m.mux.Unlock()
+		resync := m.restored
+		m.restored = false
+		return monitorUpdate{body: response.Result, resync: resync}, nil
+	}
+	var reply []json.RawMessage
+	var found bool
+	var txnID string
+	if err := json.Unmarshal(response.Result, &reply); err != nil || len(reply) != 3 {
+		return monitorUpdate{}, fmt.Errorf("invalid reply %s", response.Result)
+	}
+	if err := json.Unmarshal(reply[0], &found); err != nil {
+		return monitorUpdate{}, fmt.Errorf("invalid reply %s", response.Result)
+	}
+	if err := json.Unmarshal(reply[1], &txnID); err != nil {
+		return monitorUpdate{}, fmt.Errorf("invalid reply %s", response.Result)
+	}
+	m.mux.Lock()
+	resync := !found && (m.lastTxnID != zeroTxnID || m.restored)
+	m.restored = false
+	m.lastTxnID = txnID
+	m.mux.Unlock()
+	return monitorUpdate{body: reply[2], resync: resync, txnID: txnID}, nil
+}
+
+// wire returns the monitor requests of the tables to send. The monitor
+// must be locked.
+func (m *Monitor) wire() map[string]interface{} {
+	requests := make(map[string]interface{}, len(m.requests))
+	for table, r := range m.requests {
+		request := make(map[string]interface{})
+		if len(r.Columns) > 0 {
+			request["columns"] = r.Columns
+		}
+		if len(r.Where) > 0 {
+			request["where"] = r.Where
+		}
+		if m.Method == "monitor" {
+			requests[table] = request
+		} else {
+			requests[table] = []interface{}{request}
+		}
+	}
+	return requests
+}
+
+// heard returns when the monitor was last known to be up to date: when its
+// connection was last heard from, while the monitor is live, see
+// Client.LastSeen.
+func (m *Monitor) heard() time.Time {
+	m.mux.Lock()
+	live, until := m.live && !m.stopped, m.liveUntil
+	m.mux.Unlock()
+	if live {
+		return m.client.LastSeen()
+	}
+	return until
+}
+
+// wireRequests returns the requests of the monitored tables, for the
+// snapshots of a cache.
+func (m *Monitor) wireRequests() ([]byte, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return json.Marshal(m.wire())
+}
+
+// checkMonitorRequest checks the table, and the columns of the request, in
+// the schema.
+func checkMonitorRequest(schema Schema, table string, request MonitorRequest) error {
+	t := schema.Table(table)
+	if t == nil {
+		return &tableNotFoundError{table}
+	}
+	for _, col := range request.Columns {
+		if t.Column(col) == nil {
+			return fmt.Errorf("table %s: column %s: %w", table, col, ErrColumnNotFound)
+		}
+	}
+	for _, cond := range request.Where {
+		if t.Column(cond.Column) == nil {
+			return fmt.Errorf("table %s: column %s: %w", table, cond.Column, ErrColumnNotFound)
+		}
+	}
+	return nil
+}
+
+// SetConditions replaces the conditions on the rows of the monitored
+// tables, see ovsdb-server(7), monitor_cond_change. The tables it does not
+// list keep theirs, and those it lists with no conditions are monitored in
+// full. Updates receives the rows which start, or stop, matching them as
+// inserted, or deleted.
+func (m *Monitor) SetConditions(ctx context.Context, where map[string][]Condition) error {
+	if m.Method == "monitor" {
+		return fmt.Errorf("'monitor_cond_change' method failed for '%s' database: conditions require monitor_cond", m.db)
+	}
+	changes := make(map[string]interface{}, len(where))
+	for table, conds := range where {
+		m.mux.Lock()
+		request, exists := m.requests[table]
+		m.mux.Unlock()
+		if !exists {
+			return fmt.Errorf("'monitor_cond_change' method failed for '%s' database: table %s is not monitored", m.db, table)
+		}
+		request.Where = conds
+		m.mux.Lock()
+		schema := m.schema
+		m.mux.Unlock()
+		if err := checkMonitorRequest(schema, table, request); err != nil {
+			return fmt.Errorf("'monitor_cond_change' method failed for '%s' database: %w", m.db, err)
+		}
+		var update interface{} = conds
+		if len(conds) == 0 {
+			// The boolean true matches all the rows.
+			update = []interface{}{true}
+		}
+		changes[table] = []interface{}{map[string]interface{}{"where": update}}
+	}
+	b, err := json.Marshal([]interface{}{m.id, m.id, changes})
+	if err != nil {
+		return fmt.Errorf("'monitor_cond_change' method failed for '%s' database: %v", m.db, err)
+	}
+	if _, err := m.client.queryContext(ctx, "monitor_cond_change", string(b[1:len(b)-1])); err != nil {
+		return fmt.Errorf("'monitor_cond_change' method failed for '%s' database: %w", m.db, err)
+	}
+	// The conditions are kept for the monitor to resume with.
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	for table, conds := range where {
+		request := m.requests[table]
+		request.Where = conds
+		m.requests[table] = request
+	}
+	return nil
+}
+
+// start queues the reply of the request of the monitor, followed by the
+// updates received since it was requested, and dispatches them.
+func (m *Monitor) start(reply monitorUpdate) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.stopped {
+		return m.err
+	}
+	m.pending = append(m.pending, reply)
+	m.pending = append(m.pending, m.held...)
+	m.held = nil
+	m.live = true
+	m.started = true
+	m.kick()
+	return nil
+}
+
+// push queues the table updates of a notification of the monitor.
+func (m *Monitor) push(updates json.RawMessage, txnID string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.stopped {
+		return
+	}
+	if txnID != "" {
+		m.lastTxnID = txnID
+	}
+	u := monitorUpdate{body: updates, txnID: txnID}
+	if !m.live {
+		m.held = append(m.held, u)
+		return
+	}
+	m.pending = append(m.pending, u)
+	m.kick()
+}
+
+// kick starts the goroutine dispatching the updates, unless it runs. The
+// monitor must be locked.
+func (m *Monitor) kick() {
+	if !m.dispatching {
+		m.dispatching = true
+		go m.dispatch()
+	}
+}
+
+// stop ends the monitor with the error, and returns true unless it had
+// already ended. The updates channel is closed by the dispatching
+// goroutine, if it runs.
+func (m *Monitor) stop(err error) bool {
+	m.mux.Lock()
+	if m.stopped {
+		m.mux.Unlock()
+		return false
+	}
+	m.stopped = true
+	m.err = err
+	if m.live {
+		m.liveUntil = m.client.LastSeen()
+	}
+	m.pending = nil
+	m.held = nil
+	if m.progress != nil {
+		m.progress.cancel()
+	}
+	close(m.done)
+	if !m.dispatching {
+		close(m.updates)
+	}
+	m.mux.Unlock()
+	m.client.monitors.remove(m.id)
+	return true
+}
+
+// resumable returns true when the monitor, which has started, is
+// requested anew when its connection breaks.
+func (m *Monitor) resumable() bool {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.Method == "monitor_cond_since" && m.started && !m.stopped
+}
+
+// watch waits for the connection the monitor runs on to break, and ends,
+// or resumes, the monitor then.
+func (m *Monitor) watch() {
+	m.client.mux.Lock()
+	l := m.client.link
+	m.client.mux.Unlock()
+	if l == nil {
+		return
+	}
+	go func() {
+		select {
+		case <-l.done:
+		case <-m.done:
+			return
+		}
+		if !m.resumable() {
+			m.stop(fmt.Errorf("monitor ended: %v: %w", l.err, ErrNotConnected))
+			return
+		}
+		m.resume()
+	}()
+}
+
+// resume requests the monitor anew, which reconnects the client, and
+// holds the updates received meanwhile until the reply has been queued.
+func (m *Monitor) resume() {
+	m.mux.Lock()
+	m.live = false
+	m.liveUntil = m.client.LastSeen()
+	m.mux.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-m.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	var schema *Schema
+	if m.refreshSchema != nil && m.refreshSchema() {
+		s, err := m.client.fetchSchema(ctx, m.db)
+		if err != nil {
+			m.stop(fmt.Errorf("monitor ended: resuming failed: %w", err))
+			return
+		}
+		m.mux.Lock()
+		if !reflect.DeepEqual(s, m.schema) {
+			schema = &s
+		}
+		m.mux.Unlock()
+	}
+	u, err := m.request(ctx)
+	if err != nil {
+		m.stop(fmt.Errorf("monitor ended: resuming failed: %w", err))
+		return
+	}
+	u.schema = schema
+	if err := m.start(u); err != nil {
+		return
+	}
+	m.watch()
+}
+
+func (m *Monitor) dispatch() {
+	for {
+		m.mux.Lock()
+		if m.stopped || len(m.pending) == 0 {
+			m.dispatching = false
+			if m.stopped {
+				close(m.updates)
+			}
+			m.mux.Unlock()
+			return
+		}
+		u := m.pending[0]
+		m.pending = m.pending[1:]
+		if u.schema != nil {
+			m.schema = *u.schema
+		}
+		m.mux.Unlock()
+		if u.schema != nil && m.onSchema != nil {
+			m.onSchema(*u.schema)
+		}
+		updates, err := m.decode(u)
+		if err != nil {
+			m.stop(fmt.Errorf("monitor ended: invalid update: %v", err))
+			continue
+		}
+		if m.progress != nil && !m.delivered {
+			m.progress.done()
+		}
+		if len(updates) == 0 && m.delivered {
+			continue
+		}
+		m.delivered = true
+		if m.sink != nil {
+			m.sink(updates, u.txnID, u.resync)
+			continue
+		}
+		m.deliver(updates)
+	}
+}
+
+// next takes the next update to dispatch, if any, unless the monitor
+// stopped.
+func (m *Monitor) next() (monitorUpdate, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.stopped || len(m.pending) == 0 {
+		return monitorUpdate{}, false
+	}
+	u := m.pending[0]
+	m.pending = m.pending[1:]
+	return u, true
+}
+
+// decode applies the update to the monitored rows, and returns the
+// changes of the rows.
+func (m *Monitor) decode(u monitorUpdate) (TableUpdates, error) {
+	if u.resync {
+		return m.resync(u.body)
+	}
+	return m.apply(u.body)
+}
+
+// apply applies the table updates of the server to the monitored rows,
+// and returns the changes of the rows.
+func (m *Monitor) apply(b json.RawMessage) (TableUpdates, error) {
+	if m.Method == "monitor" {
+		var updates map[string]map[UUID]RowUpdate
+		if err := json.Unmarshal(b, &updates); err != nil {
+			return nil, err
+		}
+		out := make(TableUpdates, len(updates))
+		for table, rows := range updates {
+			out[table] = make(TableUpdate, len(rows))
+			for uuid, u := range rows {
+				out[table][uuid] = m.update(table, uuid, u)
+			}
+			m.tableSynced(len(rows))
+		}
+		return out, nil
+	}
+	var updates map[string]map[UUID]map[string]Row
+	if err := json.Unmarshal(b, &updates); err != nil {
+		return nil, err
+	}
+	out := make(TableUpdates, len(updates))
+	for table, rows := range updates {
+		out[table] = make(TableUpdate, len(rows))
+		for uuid, u := range rows {
+			ru, err := m.update2(table, uuid, u)
+			if err != nil {
+				return nil, fmt.Errorf("table %s: row %s: %v", table, uuid, err)
+			}
+			out[table][uuid] = ru
+		}
+		m.tableSynced(len(rows))
+	}
+	return out, nil
+}
+
+// update applies a row update of the "update" notification, whose old row
+// only holds the modified columns, and whose new row all the monitored
+// columns.
+func (m *Monitor) update(table string, uuid UUID, u RowUpdate) RowUpdate {
+	rows := m.table(table)
+	old, exists := rows[uuid]
+	if !exists {
+		old = u.Old
+	}
+	if u.New == nil {
+		delete(rows, uuid)
+		return RowUpdate{Old: old}
+	}
+	row := make(Row, len(old)+len(u.New))
+	for column, value := range old {
+		row[column] = value
+	}
+	for column, value := range u.New {
+		row[column] = value
+	}
+	rows[uuid] = row
+	return RowUpdate{Old: old, New: row}
+}
+
+// update2 applies a row update of the "update2", or "update3",
+// notification: the row of
+// the initial snapshot, or inserted, the deleted row, or the difference
+// of a modified row, see ovsdb-server(7).
+func (m *Monitor) update2(table string, uuid UUID, u map[string]Row) (RowUpdate, error) {
+	rows := m.table(table)
+	old := rows[uuid]
+	for kind, data := range u {
+		switch kind {
+		case "initial", "insert":
+			rows[uuid] = data
+			return RowUpdate{New: data}, nil
+		case "delete":
+			delete(rows, uuid)
+			return RowUpdate{Old: old}, nil
+		case "modify":
+			t := m.schema.Table(table)
+			row := make(Row, len(old)+len(data))
+			for column, value := range old {
+				row[column] = value
+			}
+			for column, diff := range data {
+				row[column] = applyDiff(t.Column(column), old[column], diff)
+			}
+			rows[uuid] = row
+			return RowUpdate{Old: old, New: row}, nil
+		default:
+			return RowUpdate{}, fmt.Errorf("unsupported update %q", kind)
+		}
+	}
+	return RowUpdate{}, fmt.Errorf("empty update")
+}
+
+// resync replaces the monitored rows with those of a snapshot, and
+// returns their differences: the rows inserted, modified, or deleted
+// since the last update received.
+func (m *Monitor) resync(b json.RawMessage) (TableUpdates, error) {
+	var snapshot map[string]map[UUID]map[string]Row
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, err
+	}
+	out := make(TableUpdates)
+	for table, rows := range m.rows {
+		changes := make(TableUpdate)
+		for uuid, u := range snapshot[table] {
+			row, exists := u["initial"]
+			if !exists {
+				// The snapshot of the monitor method.
+				row, exists = u["new"]
+			}
+			if !exists {
+				return nil, fmt.Errorf("table %s: row %s: expected an initial row", table, uuid)
+			}
+			if old, exists := rows[uuid]; !exists || !reflect.DeepEqual(old, row) {
+				changes[uuid] = RowUpdate{Old: old, New: row}
+			}
+		}
+		for uuid, old := range rows {
+			if _, exists := snapshot[table][uuid]; !exists {
+				changes[uuid] = RowUpdate{Old: old}
+				delete(rows, uuid)
+			}
+		}
+		for uuid, u := range changes {
+			if u.New != nil {
+				rows[uuid] = u.New
+			}
+		}
+		if len(changes) > 0 {
+			out[table] = changes
+		}
+		m.tableSynced(len(snapshot[table]))
+	}
+	return out, nil
+}
+
+func (m *Monitor) table(name string) map[UUID]Row {
+	rows, exists := m.rows[name]
+	if !exists {
+		rows = make(map[UUID]Row)
+		m.rows[name] = rows
+	}
+	return rows
+}
+
+// applyDiff applies the difference of a modified column to its old value:
+// the new value of an atom, the elements added to, or removed from, a set,
+// or the pairs added to, removed from, or updated in a map.
+func applyDiff(column *Column, old, diff interface{}) interface{} {
+	if column == nil || !(column.IsSet() || column.IsMap()) {
+		return diff
+	}
+	var out []interface{}
+	if old != nil {
+		_, elems := splitValue(old)
+		out = append(out, elems...)
+	}
+	_, changes := splitValue(diff)
+	for _, change := range changes {
+		i := -1
+		for j := range out {
+			if column.IsMap() && reflect.DeepEqual(pairKey(out[j]), pairKey(change)) ||
+				!column.IsMap() && reflect.DeepEqual(out[j], change) {
+				i = j
+				break
+			}
+		}
+		switch {
+		case i < 0:
+			out = append(out, change)
+		case column.IsMap() && !reflect.DeepEqual(out[i], change):
+			out[i] = change
+		default:
+			out = append(out[:i], out[i+1:]...)
+		}
+	}
+	if column.IsMap() {
+		return []interface{}{"map", out}
+	}
+	if len(out) == 1 {
+		return out[0]
+	}
+	return []interface{}{"set", out}
+}
+
+// pairKey returns the key of a pair of a map value.
+func pairKey(pair interface{}) interface{} {
+	if p, ok := pair.([]interface{}); ok && len(p) == 2 {
+		return p[0]
+	}
+	return nil
+}
+
+// monitorTable holds the monitors of a client, by id, and hands them the
+// updates of the server.
+type monitorTable struct {
+	mux      sync.Mutex
+	next     uint64
+	monitors map[string]*Monitor
+}
+
+// add registers the monitor under a new id.
+func (t *monitorTable) add(m *Monitor) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.next++
+	m.id = fmt.Sprintf("monitor-%d", t.next)
+	t.monitors[m.id] = m
+}
+
+func (t *monitorTable) remove(id string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	delete(t.monitors, id)
+}
+
+// reset ends the monitors, but those which resume when their connection
+// has been replaced, unless all is set.
+func (t *monitorTable) reset(err error, all bool) {
+	t.mux.Lock()
+	monitors := make([]*Monitor, 0, len(t.monitors))
+	for _, m := range t.monitors {
+		monitors = append(monitors, m)
+	}
+	t.mux.Unlock()
+	for _, m := range monitors {
+		if all || !m.resumable() {
+			m.stop(err)
+		}
+	}
+}
+
+// notify handles the "update" and "update2" notifications of the server,
+// whose parameters are the id of the monitor and the table updates, and
+// the "update3" notifications, which carry the id of the transaction
+// between them.
+func (t *monitorTable) notify(params json.RawMessage) {
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil || len(args) < 2 || len(args) > 3 {
+		return
+	}
+	var id, txnID string
+	if err := json.Unmarshal(args[0], &id); err != nil {
+		return
+	}
+	if len(args) == 3 {
+		if err := json.Unmarshal(args[1], &txnID); err != nil {
+			return
+		}
+	}
+	t.mux.Lock()
+	m, exists := t.monitors[id]
+	t.mux.Unlock()
+	if exists {
+		m.push(args[len(args)-1], txnID)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+)
+
+// OverflowPolicy selects what a monitor does with its updates when the
+// buffer of its Updates is full, as their receiver does not keep up.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the receiver to take the updates, while
+	// those the server sends meanwhile are queued. It is the default.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the updates the receiver has not taken yet,
+	// and sends in their place a resync of all the monitored rows, see
+	// TableUpdates.Resync.
+	OverflowDropOldest
+	// OverflowCoalesce merges the updates queued into one, which holds the
+	// rows before the first change and after the last one, until the
+	// receiver takes it.
+	OverflowCoalesce
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowCoalesce:
+		return "coalesce"
+	}
+	return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+}
+
+// MonitorOverflow describes an overflow of the Updates of a monitor,
+// passed to Client.OnMonitorOverflow.
+type MonitorOverflow struct {
+	Database string
+	Policy   OverflowPolicy
+	// Dropped is the number of updates dropped for a resync, and
+	// Coalesced the number of updates merged into one.
+	Dropped   int
+	Coalesced int
+}
+
+// WithMonitorBuffer sets the number of updates the Updates of the monitors
+// of a client buffer, and the policy they follow when the buffer is full.
+// The function, when set, is called on every overflow, by the goroutine
+// dispatching the updates, so that data loss is never silent. The policies
+// other than OverflowBlock require a buffer.
+func WithMonitorBuffer(size int, policy OverflowPolicy, fn func(MonitorOverflow)) ClientOption {
+	return func(cli *Client) error {
+		if size < 0 {
+			return fmt.Errorf("invalid monitor buffer size: %d", size)
+		}
+		switch policy {
+		case OverflowBlock:
+		case OverflowDropOldest, OverflowCoalesce:
+			if size == 0 {
+				return fmt.Errorf("overflow policy %s requires a monitor buffer", policy)
+			}
+		default:
+			return fmt.Errorf("invalid overflow policy: %d", policy)
+		}
+		cli.MonitorBuffer = size
+		cli.MonitorOverflow = policy
+		cli.OnMonitorOverflow = fn
+		return nil
+	}
+}
+
+// resyncMarker is the table of the updates which resync the monitored rows.
+const resyncMarker = ""
+
+// Resync returns true when the updates are the resync a monitor sends once
+// it dropped updates, see OverflowDropOldest: they hold all the monitored
+// rows, as inserted, which replace those the receiver holds, and an empty
+// table named "", which marks them.
+func (u TableUpdates) Resync() bool {
+	_, exists := u[resyncMarker]
+	return exists
+}
+
+// deliver passes the updates to Updates, following the overflow policy of
+// the client when its buffer is full.
+func (m *Monitor) deliver(updates TableUpdates) {
+	select {
+	case m.updates <- updates:
+		return
+	case <-m.done:
+		return
+	default:
+	}
+	overflow := MonitorOverflow{Database: m.db, Policy: m.client.MonitorOverflow}
+	switch overflow.Policy {
+	case OverflowDropOldest:
+		for drained := false; !drained; {
+			select {
+			case <-m.updates:
+				overflow.Dropped++
+			default:
+				drained = true
+			}
+		}
+		updates = m.snapshot()
+	case OverflowCoalesce:
+		overflow.Coalesced = 1
+		for {
+			u, ok := m.next()
+			if !ok {
+				break
+			}
+			next, err := m.decode(u)
+			if err != nil {
+				m.stop(fmt.Errorf("monitor ended: invalid update: %v", err))
+				return
+			}
+			updates = mergeUpdates(updates, next)
+			overflow.Coalesced++
+			select {
+			case m.updates <- updates:
+				m.overflowed(overflow)
+				return
+			case <-m.done:
+				return
+			default:
+			}
+		}
+	}
+	m.overflowed(overflow)
+	select {
+	case m.updates <- updates:
+	case <-m.done:
+	}
+}
+
+func (m *Monitor) overflowed(overflow MonitorOverflow) {
+	if m.client.OnMonitorOverflow != nil {
+		m.client.OnMonitorOverflow(overflow)
+	}
+}
+
+// snapshot returns all the monitored rows, as inserted, and the resync
+// marker.
+func (m *Monitor) snapshot() TableUpdates {
+	updates := make(TableUpdates, len(m.rows)+1)
+	updates[resyncMarker] = TableUpdate{}
+	for table, rows := range m.rows {
+		updates[table] = make(TableUpdate, len(rows))
+		for uuid, row := range rows {
+			updates[table][uuid] = RowUpdate{New: row}
+		}
+	}
+	return updates
+}
+
+// mergeUpdates returns the changes of the rows of both updates, the next
+// following the first: the rows before the first change, and after the
+// last one. The rows inserted, then deleted, are left out.
+func mergeUpdates(first, next TableUpdates) TableUpdates {
+	for table, rows := range next {
+		merged, exists := first[table]
+		if !exists {
+			first[table] = rows
+			continue
+		}
+		for uuid, u := range rows {
+			if prev, exists := merged[uuid]; exists {
+				u.Old = prev.Old
+			}
+			if u.Old == nil && u.New == nil {
+				delete(merged, uuid)
+				continue
+			}
+			merged[uuid] = u
+		}
+	}
+	return first
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMonitorOverflow(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	update := func(rows map[string]interface{}) testServerNotify {
+		return testServerNotify{method: "update", params: map[string]interface{}{"Bridge": rows}}
+	}
+	server := func() string {
+		remote, _ := newTestMonitorServer(t,
+			map[string]interface{}{"Bridge": map[string]interface{}{
+				br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0"}},
+			}},
+			update(map[string]interface{}{
+				br0: map[string]interface{}{"old": map[string]interface{}{"name": "br0"}, "new": map[string]interface{}{"name": "br2"}},
+			}),
+			update(map[string]interface{}{
+				br1: map[string]interface{}{"new": map[string]interface{}{"name": "br1"}},
+			}),
+			update(map[string]interface{}{
+				br0: map[string]interface{}{"old": map[string]interface{}{"name": "br2"}, "new": map[string]interface{}{"name": "br3"}},
+			}),
+		)
+		return remote
+	}
+	ctx := context.Background()
+	if _, err := NewClient(server(), 1, WithMonitorBuffer(0, OverflowCoalesce, nil)); err == nil {
+		t.Fatalf("FAIL: expected coalescing without a buffer to fail")
+	}
+
+	overflows := make(chan MonitorOverflow, 8)
+	c, err := NewClient(server(), 1, WithMonitorBuffer(1, OverflowDropOldest, func(o MonitorOverflow) { overflows <- o }))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	m, err := c.Monitor(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the monitor to start, but failed with: %v", err)
+	}
+	// The snapshot fills the buffer, so that every update overflows.
+	for i := 0; i < 3; i++ {
+		c.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+		select {
+		case o := <-overflows:
+			if o.Policy != OverflowDropOldest || o.Dropped != 1 || o.Database != "Open_vSwitch" {
+				t.Fatalf("FAIL: unexpected overflow: %+v", o)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("FAIL: timed out waiting for overflow %d", i)
+		}
+	}
+	u := nextUpdates(t, m)
+	if !u.Resync() || len(u["Bridge"]) != 2 || u["Bridge"][br0].New["name"] != "br3" || u["Bridge"][br1].Old != nil {
+		t.Fatalf("FAIL: expected a resync of the rows, got: %+v", u)
+	}
+	t.Logf("PASS: updates dropped for a resync")
+
+	overflows = make(chan MonitorOverflow, 8)
+	c2, err := NewClient(server(), 1, WithMonitorBuffer(1, OverflowCoalesce, func(o MonitorOverflow) { overflows <- o }))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c2.Close()
+	m, err = c2.Monitor(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the monitor to start, but failed with: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		c2.TransactOperations(ctx, "Open_vSwitch", Comment("update"))
+	}
+	if u := nextUpdates(t, m); u.Resync() || u["Bridge"][br0].New["name"] != "br0" {
+		t.Fatalf("FAIL: expected the snapshot, got: %+v", u)
+	}
+	// However the updates were coalesced, their changes add up to those
+	// of the server.
+	rows := map[UUID]RowUpdate{}
+	for len(rows) < 2 || rows[br0].New["name"] != "br3" {
+		for uuid, ru := range nextUpdates(t, m)["Bridge"] {
+			if prev, exists := rows[uuid]; exists {
+				ru.Old = prev.Old
+			}
+			rows[uuid] = ru
+		}
+	}
+	if rows[br0].Old["name"] != "br0" || rows[br1].Old != nil || rows[br1].New["name"] != "br1" {
+		t.Fatalf("FAIL: unexpected changes: %+v", rows)
+	}
+	select {
+	case o := <-overflows:
+		if o.Policy != OverflowCoalesce || o.Coalesced < 1 {
+			t.Fatalf("FAIL: unexpected overflow: %+v", o)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("FAIL: expected an overflow of the buffer")
+	}
+	t.Logf("PASS: updates coalesced")
+}
@@ -0,0 +1,429 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestMonitorServer serves testModelSchema, and answers the monitor
+// requests with the snapshot. Every transaction makes the server send the
+// next of the updates to the last monitor requested.
+func newTestMonitorServer(t *testing.T, snapshot interface{}, updates ...interface{}) (string, func() []string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var id interface{}
+	var requests []string
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "monitor", "monitor_cond", "monitor_cancel":
+			requests = append(requests, method+" "+string(params))
+			var args []interface{}
+			json.Unmarshal(params, &args)
+			if method == "monitor_cancel" {
+				return map[string]interface{}{}
+			}
+			id = args[1]
+			return snapshot
+		case "transact":
+			result := []interface{}{map[string]interface{}{}}
+			if len(updates) == 0 {
+				return result
+			}
+			n := updates[0].(testServerNotify)
+			updates = updates[1:]
+			n.params = []interface{}{id, n.params}
+			n.result = result
+			return n
+		}
+		return nil
+	})
+	return "tcp:" + l.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func nextUpdates(t *testing.T, m *Monitor) TableUpdates {
+	t.Helper()
+	select {
+	case u, ok := <-m.Updates:
+		if !ok {
+			t.Fatalf("FAIL: expected updates, but the monitor ended: %v", m.Err())
+		}
+		return u
+	case <-time.After(5 * time.Second):
+		t.Fatalf("FAIL: timed out waiting for updates")
+	}
+	return nil
+}
+
+func TestClientMonitor(t *testing.T) {
+	const br0 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"
+	remote, requests := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0", "fail_mode": "secure"}},
+		}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{
+				"old": map[string]interface{}{"fail_mode": "secure"},
+				"new": map[string]interface{}{"name": "br0", "fail_mode": "standalone"},
+			},
+		}}},
+		testServerNotify{method: "update", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"old": map[string]interface{}{"name": "br0", "fail_mode": "standalone"}},
+		}}},
+	)
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	if _, err := c.Monitor(ctx, "Open_vSwitch", []string{"Port"}, nil); !errors.Is(err, ErrTableNotFound) {
+		t.Fatalf("FAIL: expected the unknown table to fail, got: %v", err)
+	}
+	if _, err := c.Monitor(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"foo"}}); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("FAIL: expected the unknown column to fail, got: %v", err)
+	}
+
+	m, err := c.Monitor(ctx, "Open_vSwitch", []string{"Bridge"}, map[string][]string{"Bridge": {"name", "fail_mode"}})
+	if err != nil {
+		t.Fatalf("FAIL: expected the monitor to start, but failed with: %v", err)
+	}
+	if m.Method != "monitor" {
+		t.Fatalf("FAIL: expected the monitor method, got: %s", m.Method)
+	}
+	snapshot := nextUpdates(t, m)
+	if u := snapshot["Bridge"][br0]; u.Old != nil || u.New["fail_mode"] != "secure" {
+		t.Fatalf("FAIL: unexpected snapshot: %v", snapshot)
+	}
+	t.Logf("PASS: snapshot: %v", snapshot)
+
+	if _, err := c.TransactOperations(ctx, "Open_vSwitch", Comment("modify")); err != nil {
+		t.Fatalf("FAIL: transaction failed: %v", err)
+	}
+	u := nextUpdates(t, m)["Bridge"][br0]
+	if u.Old["fail_mode"] != "secure" || u.Old["name"] != "br0" || u.New["fail_mode"] != "standalone" {
+		t.Fatalf("FAIL: unexpected modification: %+v", u)
+	}
+	if _, err := c.TransactOperations(ctx, "Open_vSwitch", Comment("delete")); err != nil {
+		t.Fatalf("FAIL: transaction failed: %v", err)
+	}
+	u = nextUpdates(t, m)["Bridge"][br0]
+	if u.New != nil || u.Old["fail_mode"] != "standalone" {
+		t.Fatalf("FAIL: unexpected deletion: %+v", u)
+	}
+	t.Logf("PASS: updates received")
+
+	if err := m.Cancel(ctx); err != nil {
+		t.Fatalf("FAIL: expected the monitor to be cancelled, but failed with: %v", err)
+	}
+	if _, ok := <-m.Updates; ok || m.Err() != nil {
+		t.Fatalf("FAIL: expected the updates to be closed, got: %v", m.Err())
+	}
+	want := []string{
+		`monitor ["Open_vSwitch","monitor-1",{"Bridge":{"columns":["name","fail_mode"]}}]`,
+		`monitor_cancel ["monitor-1"]`,
+	}
+	if got := requests(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("FAIL: unexpected requests: %q", got)
+	}
+	t.Logf("PASS: monitor cancelled")
+}
+
+func TestClientMonitorCond(t *testing.T) {
+	const br0 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"
+	port := func(s string) []interface{} { return []interface{}{"uuid", s} }
+	p1, p2 := "1f0b2a8e-39a8-4ae3-9a7d-33c1a9d4e2f1", "8c5f6e2b-0c2f-4a4a-bd51-7e3067c6b1a4"
+	remote, requests := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"initial": map[string]interface{}{
+				"name":         "br0",
+				"ports":        port(p1),
+				"external_ids": []interface{}{"map", []interface{}{[]interface{}{"a", "1"}, []interface{}{"b", "2"}}},
+			}},
+		}},
+		testServerNotify{method: "update2", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"modify": map[string]interface{}{
+				"ports":        port(p2),
+				"external_ids": []interface{}{"map", []interface{}{[]interface{}{"a", "1"}, []interface{}{"b", "3"}, []interface{}{"c", "4"}}},
+			}},
+		}}},
+		testServerNotify{method: "update2", params: map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"modify": map[string]interface{}{"ports": port(p1)}},
+		}}},
+	)
+	c, err := NewClient(remote, 1, WithCapabilities(Capabilities{MonitorCond: true}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	m, err := c.Monitor(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the monitor to start, but failed with: %v", err)
+	}
+	if m.Method != "monitor_cond" {
+		t.Fatalf("FAIL: expected the monitor_cond method, got: %s", m.Method)
+	}
+	if u := nextUpdates(t, m)["Bridge"][br0]; u.New["name"] != "br0" {
+		t.Fatalf("FAIL: unexpected snapshot: %+v", u)
+	}
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("modify"))
+	u := nextUpdates(t, m)["Bridge"][br0]
+	ports, _ := u.New.GetSet("ports")
+	ids, _ := u.New.GetStringMap("external_ids")
+	if len(ports) != 2 || !reflect.DeepEqual(ids, map[string]string{"b": "3", "c": "4"}) || u.New["name"] != "br0" {
+		t.Fatalf("FAIL: unexpected modification: %+v", u)
+	}
+	if !reflect.DeepEqual(u.Old["ports"], port(p1)) {
+		t.Fatalf("FAIL: unexpected old row: %+v", u.Old)
+	}
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("modify"))
+	u = nextUpdates(t, m)["Bridge"][br0]
+	if !reflect.DeepEqual(u.New["ports"], port(p2)) {
+		t.Fatalf("FAIL: unexpected modification: %+v", u)
+	}
+	t.Logf("PASS: differences applied: %v", u.New)
+	if got := requests(); len(got) != 1 || got[0] != `monitor_cond ["Open_vSwitch","monitor-1",{"Bridge":[{}]}]` {
+		t.Fatalf("FAIL: unexpected requests: %q", got)
+	}
+
+	c.Close()
+	select {
+	case _, ok := <-m.Updates:
+		if ok {
+			t.Fatalf("FAIL: unexpected updates")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("FAIL: timed out waiting for the monitor to end")
+	}
+	if !errors.Is(m.Err(), ErrNotConnected) {
+		t.Fatalf("FAIL: expected the monitor to end with its connection, got: %v", m.Err())
+	}
+	if err := m.Cancel(ctx); err != nil {
+		t.Fatalf("FAIL: expected cancelling an ended monitor to do nothing, got: %v", err)
+	}
+	t.Logf("PASS: monitor ended: %v", m.Err())
+}
+
+func TestClientMonitorCondSince(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	initial := func(names ...string) map[string]interface{} {
+		rows := make(map[string]interface{})
+		for i, name := range names {
+			rows[[]string{br0, br1}[i]] = map[string]interface{}{"initial": map[string]interface{}{"name": name}}
+		}
+		return map[string]interface{}{"Bridge": rows}
+	}
+	replies := []interface{}{
+		[]interface{}{false, "txn-1", initial("br0", "br1")},
+		[]interface{}{true, "txn-3", map[string]interface{}{"Bridge": map[string]interface{}{
+			br1: map[string]interface{}{"modify": map[string]interface{}{"name": "br2"}},
+		}}},
+		[]interface{}{false, "txn-9", initial("br3")},
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var since []interface{}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "monitor_cond_since":
+			var args []interface{}
+			json.Unmarshal(params, &args)
+			since = append(since, args[3])
+			reply := replies[0]
+			replies = replies[1:]
+			return reply
+		case "transact":
+			return testServerNotify{
+				method: "update3",
+				params: []interface{}{"monitor-1", "txn-2", map[string]interface{}{"Bridge": map[string]interface{}{
+					br0: map[string]interface{}{"delete": nil},
+				}}},
+				result: []interface{}{map[string]interface{}{}},
+			}
+		}
+		return nil
+	})
+	c, err := NewClient("tcp:"+l.Addr().String(), 1, WithCapabilities(Capabilities{MonitorCond: true, MonitorCondSince: true}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	breakConnection := func() {
+		c.mux.Lock()
+		c.link.close()
+		c.mux.Unlock()
+	}
+
+	m, err := c.Monitor(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the monitor to start, but failed with: %v", err)
+	}
+	if u := nextUpdates(t, m)["Bridge"]; len(u) != 2 || m.LastTxnID() != "txn-1" {
+		t.Fatalf("FAIL: unexpected snapshot: %v, %s", u, m.LastTxnID())
+	}
+	c.TransactOperations(ctx, "Open_vSwitch", Comment("delete"))
+	if u := nextUpdates(t, m)["Bridge"][br0]; u.New != nil || u.Old["name"] != "br0" || m.LastTxnID() != "txn-2" {
+		t.Fatalf("FAIL: unexpected deletion: %+v, %s", u, m.LastTxnID())
+	}
+	t.Logf("PASS: last transaction: %s", m.LastTxnID())
+
+	breakConnection()
+	u := nextUpdates(t, m)["Bridge"]
+	if len(u) != 1 || u[br1].Old["name"] != "br1" || u[br1].New["name"] != "br2" {
+		t.Fatalf("FAIL: unexpected changes after resuming: %v", u)
+	}
+	t.Logf("PASS: resumed from the last transaction")
+
+	breakConnection()
+	u = nextUpdates(t, m)["Bridge"]
+	if len(u) != 2 || u[br1].New != nil || u[br1].Old["name"] != "br2" || u[br0].Old != nil || u[br0].New["name"] != "br3" {
+		t.Fatalf("FAIL: unexpected changes after resyncing: %v", u)
+	}
+	if m.LastTxnID() != "txn-9" || m.Err() != nil {
+		t.Fatalf("FAIL: unexpected monitor state: %s, %v", m.LastTxnID(), m.Err())
+	}
+	mu.Lock()
+	want := []interface{}{zeroTxnID, "txn-2", "txn-3"}
+	if !reflect.DeepEqual(since, want) {
+		t.Fatalf("FAIL: unexpected last transactions requested: %v", since)
+	}
+	mu.Unlock()
+	t.Logf("PASS: resynced the rows of the server")
+
+	c.Close()
+	if _, ok := <-m.Updates; ok || !errors.Is(m.Err(), ErrNotConnected) {
+		t.Fatalf("FAIL: expected the monitor to end with the client, got: %v", m.Err())
+	}
+}
+
+func TestClientMonitorConditions(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var requests []string
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "monitor_cond":
+			requests = append(requests, method+" "+string(params))
+			return map[string]interface{}{"Bridge": map[string]interface{}{
+				br0: map[string]interface{}{"initial": map[string]interface{}{"name": "br0"}},
+			}}
+		case "monitor_cond_change":
+			requests = append(requests, method+" "+string(params))
+			return testServerNotify{
+				method: "update2",
+				params: []interface{}{"monitor-1", map[string]interface{}{"Bridge": map[string]interface{}{
+					br0: map[string]interface{}{"delete": nil},
+					br1: map[string]interface{}{"insert": map[string]interface{}{"name": "br1"}},
+				}}},
+				result: map[string]interface{}{},
+			}
+		}
+		return nil
+	})
+	ctx := context.Background()
+	where := map[string]MonitorRequest{"Bridge": {Columns: []string{"name"}, Where: []Condition{Equal("name", "br0")}}}
+
+	plain, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer plain.Close()
+	if _, err := plain.MonitorTables(ctx, "Open_vSwitch", where); err == nil {
+		t.Fatalf("FAIL: expected the conditions to require monitor_cond")
+	}
+
+	c, err := NewClient("tcp:"+l.Addr().String(), 1, WithCapabilities(Capabilities{MonitorCond: true}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	if _, err := c.MonitorTables(ctx, "Open_vSwitch", map[string]MonitorRequest{"Bridge": {Where: []Condition{Equal("foo", "br0")}}}); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("FAIL: expected the condition on an unknown column to fail, got: %v", err)
+	}
+	cache, err := c.NewCacheTables(ctx, "Open_vSwitch", where)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	if _, found := cache.Row("Bridge", br0); !found || cache.Len("Bridge") != 1 {
+		t.Fatalf("FAIL: expected the matching bridge only")
+	}
+	if err := cache.SetConditions(ctx, map[string][]Condition{"Port": nil}); err == nil {
+		t.Fatalf("FAIL: expected the conditions of a table which is not monitored to fail")
+	}
+	if err := cache.SetConditions(ctx, map[string][]Condition{"Bridge": {Equal("name", "br1")}}); err != nil {
+		t.Fatalf("FAIL: expected the conditions to change, but failed with: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, found := cache.Row("Bridge", br1); found && cache.Len("Bridge") == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("FAIL: timed out waiting for the rows of the new conditions")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := cache.SetConditions(ctx, map[string][]Condition{"Bridge": nil}); err != nil {
+		t.Fatalf("FAIL: expected the conditions to be removed, but failed with: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		`monitor_cond ["Open_vSwitch","monitor-1",{"Bridge":[{"columns":["name"],"where":[["name","==","br0"]]}]}]`,
+		`monitor_cond_change ["monitor-1","monitor-1",{"Bridge":[{"where":[["name","==","br1"]]}]}]`,
+		`monitor_cond_change ["monitor-1","monitor-1",{"Bridge":[{"where":[true]}]}]`,
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("FAIL: unexpected requests: %q", requests)
+	}
+	t.Logf("PASS: conditions changed")
+}
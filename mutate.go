@@ -0,0 +1,82 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// AddToSet returns a mutate operation inserting the values, a slice or a
+// single value, into the set column of the rows of the table matching the
+// conditions.
+func AddToSet(table, column string, values interface{}, where ...Condition) Operation {
+	return Mutate(table, []Mutation{{Column: column, Mutator: "insert", Value: values}}, where...)
+}
+
+// RemoveFromSet returns a mutate operation deleting the values, a slice or
+// a single value, from the set column of the rows of the table matching
+// the conditions.
+func RemoveFromSet(table, column string, values interface{}, where ...Condition) Operation {
+	return Mutate(table, []Mutation{{Column: column, Mutator: "delete", Value: values}}, where...)
+}
+
+// AddToMap returns a mutate operation inserting the pairs of the map into
+// the map column of the rows of the table matching the conditions. The keys
+// already in the column keep their values, see SetMapValues.
+func AddToMap(table, column string, pairs interface{}, where ...Condition) Operation {
+	return Mutate(table, []Mutation{{Column: column, Mutator: "insert", Value: pairs}}, where...)
+}
+
+// SetMapValues returns a mutate operation setting the pairs of the map in
+// the map column of the rows of the table matching the conditions,
+// replacing the values of the keys already in the column.
+func SetMapValues(table, column string, pairs interface{}, where ...Condition) Operation {
+	return Mutate(table, []Mutation{
+		{Column: column, Mutator: "delete", Value: mapKeys(pairs)},
+		{Column: column, Mutator: "insert", Value: pairs},
+	}, where...)
+}
+
+// RemoveFromMap returns a mutate operation deleting the keys, a slice or a
+// single key, and their values from the map column of the rows of the
+// table matching the conditions.
+func RemoveFromMap(table, column string, keys interface{}, where ...Condition) Operation {
+	return Mutate(table, []Mutation{{Column: column, Mutator: "delete", Value: keys}}, where...)
+}
+
+// IncrementCounter returns a mutate operation adding the delta, which may
+// be negative, to the integer or real column of the rows of the table
+// matching the conditions.
+func IncrementCounter(table, column string, delta interface{}, where ...Condition) Operation {
+	return Mutate(table, []Mutation{{Column: column, Mutator: "+=", Value: delta}}, where...)
+}
+
+// mapKeys returns the keys of the map as a set, in a deterministic order.
+func mapKeys(m interface{}) []interface{} {
+	rv := reflect.ValueOf(m)
+	if rv.Kind() != reflect.Map {
+		return []interface{}{}
+	}
+	keys := make([]interface{}, 0, rv.Len())
+	for _, k := range rv.MapKeys() {
+		keys = append(keys, k.Interface())
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	return keys
+}
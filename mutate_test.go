@@ -0,0 +1,70 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMutateHelpers(t *testing.T) {
+	row := Equal("name", "br-int")
+	port := UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8")
+	for i, test := range []struct {
+		op   Operation
+		want string
+	}{
+		{
+			op:   AddToSet("Bridge", "ports", []UUID{port}, row),
+			want: `[["ports","insert",["set",[["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]]]]`,
+		},
+		{
+			op:   AddToSet("Port", "trunks", 100, Equal("name", "vif1")),
+			want: `[["trunks","insert",100]]`,
+		},
+		{
+			op:   RemoveFromSet("Bridge", "ports", port, row),
+			want: `[["ports","delete",["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]]`,
+		},
+		{
+			op:   AddToMap("Bridge", "external_ids", map[string]string{"owner": "test"}, row),
+			want: `[["external_ids","insert",["map",[["owner","test"]]]]]`,
+		},
+		{
+			op:   SetMapValues("Bridge", "other_config", map[string]string{"hwaddr": "00:00:00:00:00:01", "datapath-id": "1"}, row),
+			want: `[["other_config","delete",["set",["datapath-id","hwaddr"]]],["other_config","insert",["map",[["datapath-id","1"],["hwaddr","00:00:00:00:00:01"]]]]]`,
+		},
+		{
+			op:   RemoveFromMap("Bridge", "external_ids", []string{"owner"}, row),
+			want: `[["external_ids","delete",["set",["owner"]]]]`,
+		},
+		{
+			op:   IncrementCounter("Open_vSwitch", "next_cfg", 1),
+			want: `[["next_cfg","+=",1]]`,
+		},
+	} {
+		if err := test.op.Validate(); err != nil {
+			t.Fatalf("FAIL: Test %d: expected to be valid, but failed: %v", i, err)
+		}
+		b, err := json.Marshal(test.op.Mutations)
+		if err != nil {
+			t.Fatalf("FAIL: Test %d: expected to marshal, but failed: %v", i, err)
+		}
+		if string(b) != test.want {
+			t.Fatalf("FAIL: Test %d: expected '%s', but got '%s'", i, test.want, b)
+		}
+		t.Logf("PASS: Test %d: %s", i, b)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// Observer receives the events of clients, e.g. to collect metrics. Its
+// methods are called synchronously from many goroutines, so they must be
+// fast and safe for concurrent use. The queries of the control sockets
+// are observed too when the observer is among the Options of the
+// OvsClient or of the OvnClient.
+type Observer interface {
+	// ObserveRequest is called when a request completes.
+	ObserveRequest(event RequestEvent)
+	// ObserveReconnect is called after every reconnect attempt.
+	ObserveReconnect(event ReconnectEvent)
+	// ObserveBytes is called with the numbers of bytes read from, and
+	// written to, the connections.
+	ObserveBytes(read, written int)
+}
+
+// RequestEvent describes a completed request of a client.
+type RequestEvent struct {
+	Endpoint string
+	// Database is the database of the request, if any.
+	Database string
+	Method   string
+	// Table and Operation are the tables and the operations of a
+	// transaction, comma-separated.
+	Table     string
+	Operation string
+	Duration  time.Duration
+	// Err is the outcome of the request, nil when it succeeded.
+	Err error
+}
+
+// WithObserver sets the observer of a client.
+func WithObserver(observer Observer) ClientOption {
+	return func(cli *Client) error {
+		cli.Observer = observer
+		return nil
+	}
+}
+
+// newRequestEvent returns the event of the request, which is completed
+// when the request completes.
+func newRequestEvent(endpoint, method string, param interface{}) RequestEvent {
+	event := RequestEvent{
+		Endpoint: endpoint,
+		Method:   method,
+	}
+	switch p := param.(type) {
+	case Transaction:
+		event.Database = p.Database
+		tables, ops := []string{}, []string{}
+		for _, op := range p.Operations {
+			if op.Table != "" && !contains(tables, op.Table) {
+				tables = append(tables, op.Table)
+			}
+			if !contains(ops, op.Name) {
+				ops = append(ops, op.Name)
+			}
+		}
+		event.Table = strings.Join(tables, ",")
+		event.Operation = strings.Join(ops, ",")
+	case string:
+		if method == "get_schema" {
+			event.Database = strings.Trim(p, `"`)
+		}
+	}
+	return event
+}
+
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// observedConn reports the bytes read from, and written to, a connection.
+type observedConn struct {
+	net.Conn
+	observer Observer
+}
+
+func (c *observedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.observer.ObserveBytes(n, 0)
+	}
+	return n, err
+}
+
+func (c *observedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.observer.ObserveBytes(0, n)
+	}
+	return n, err
+}
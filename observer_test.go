@@ -0,0 +1,123 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+)
+
+// testObserver records the events.
+type testObserver struct {
+	mux        sync.Mutex
+	requests   []RequestEvent
+	reconnects []ReconnectEvent
+	read       int
+	written    int
+}
+
+func (o *testObserver) ObserveRequest(event RequestEvent) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	o.requests = append(o.requests, event)
+}
+
+func (o *testObserver) ObserveReconnect(event ReconnectEvent) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	o.reconnects = append(o.reconnects, event)
+}
+
+func (o *testObserver) ObserveBytes(read, written int) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	o.read += read
+	o.written += written
+}
+
+func TestClientObserver(t *testing.T) {
+	_, remotes := newTestCluster(t, 1, 0)
+	observer := &testObserver{}
+	cli, err := NewClient(remotes[0], 1, WithObserver(observer))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	if _, err := cli.Transact("OVN_Southbound", "SELECT * FROM Chassis"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	cli.Failover()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	observer.mux.Lock()
+	defer observer.mux.Unlock()
+	want := []RequestEvent{
+		{Database: "OVN_Southbound", Method: "transact", Table: "Chassis", Operation: "select"},
+		{Database: "OVN_Southbound", Method: "get_schema"},
+		{Method: "echo"},
+	}
+	if len(observer.requests) != len(want) {
+		t.Fatalf("FAIL: expected %d requests, but got: %v", len(want), observer.requests)
+	}
+	for i, event := range observer.requests {
+		if event.Database != want[i].Database || event.Method != want[i].Method || event.Table != want[i].Table || event.Operation != want[i].Operation || event.Err != nil || event.Endpoint != remotes[0] {
+			t.Fatalf("FAIL: Test %d: expected request %+v, but got %+v", i, want[i], event)
+		}
+		t.Logf("PASS: Test %d: observed '%s' request of '%s' database", i, event.Method, event.Database)
+	}
+	if len(observer.reconnects) != 1 || observer.reconnects[0].Err != nil {
+		t.Fatalf("FAIL: expected a successful reconnect, but got: %v", observer.reconnects)
+	}
+	if observer.read == 0 || observer.written == 0 {
+		t.Fatalf("FAIL: expected the bytes read and written, but got %d and %d", observer.read, observer.written)
+	}
+	t.Logf("PASS: observed a reconnect, %d bytes read, %d bytes written", observer.read, observer.written)
+}
+
+// newTestControlServer serves the memory/show command of a control socket.
+func newTestControlServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method == "memory/show" {
+			return "cells:100 monitors:2"
+		}
+		return nil
+	})
+	return "tcp:" + l.Addr().String()
+}
+
+func TestControlSocketObserver(t *testing.T) {
+	observer := &testObserver{}
+	cli := NewOvnClient()
+	cli.Database.Southbound.Socket.Control = newTestControlServer(t)
+	cli.Options = []ClientOption{WithObserver(observer)}
+	if _, err := cli.GetAppMemoryMetricsContext(context.Background(), "ovsdb-server-southbound"); err != nil {
+		t.Fatalf("FAIL: expected the memory metrics, but failed with: %v", err)
+	}
+	observer.mux.Lock()
+	defer observer.mux.Unlock()
+	if len(observer.requests) != 1 || observer.requests[0].Method != "memory/show" || observer.requests[0].Err != nil {
+		t.Fatalf("FAIL: expected the memory/show request, but got: %v", observer.requests)
+	}
+	t.Logf("PASS: observed '%s' request of %s", observer.requests[0].Method, observer.requests[0].Endpoint)
+}
@@ -15,8 +15,12 @@
 package ovsdb
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
+	"sort"
+	"strconv"
 	"strings"
 	"text/scanner"
 )
@@ -28,13 +32,236 @@ type Operation struct {
 	Table      string      `json:"table"`
 	Conditions []Condition `json:"where"`
 	Columns    []string    `json:"columns,omitempty"`
+	// Row holds the column values of insert and update operations. The
+	// slices are encoded as sets, and the maps as maps.
+	Row map[string]interface{} `json:"row,omitempty"`
+	// Mutations are the mutations of mutate operations.
+	Mutations []Mutation `json:"mutations,omitempty"`
+	// Rows, Until, and Timeout are the members of wait operations.
+	Rows    []map[string]interface{} `json:"rows,omitempty"`
+	Until   string                   `json:"until,omitempty"`
+	Timeout *int                     `json:"timeout,omitempty"`
+	// UUIDName names the row of an insert operation, so that the other
+	// operations of the transaction may refer to it.
+	UUIDName string `json:"uuid-name,omitempty"`
+	// Comment is the comment of comment operations.
+	Comment string `json:"comment,omitempty"`
+	// Lock is the lock of assert operations.
+	Lock string `json:"lock,omitempty"`
+	// OrderBy, Descending, and Limit are the ORDER BY and LIMIT clauses of
+	// a select query. The server has no notion of them, so they are applied
+	// to the rows of the result by the client, see Sort.
+	OrderBy    string `json:"-"`
+	Descending bool   `json:"-"`
+	Limit      int    `json:"-"`
 }
 
-// NewOperation - TODO
-func NewOperation(query string) (Operation, error) {
+// Mutation is a mutation of a column, see RFC 7047, Section 5.1. The
+// mutator is one of "+=", "-=", "*=", "/=", "%=", "insert", and "delete".
+type Mutation struct {
+	Column  string
+	Mutator string
+	Value   interface{}
+}
+
+// MarshalJSON encodes the mutation in the OVSDB notation.
+func (m Mutation) MarshalJSON() ([]byte, error) {
+	value, err := encodeValue(m.Value)
+	if err != nil {
+		return []byte{}, fmt.Errorf("marshal Mutation.Value: %s", err)
+	}
+	return json.Marshal([]interface{}{m.Column, m.Mutator, value})
+}
+
+// Select returns a select operation of the columns of the rows of the
+// table matching the conditions. No columns select all of them.
+func Select(table string, columns []string, where ...Condition) Operation {
+	return Operation{Name: "select", Table: table, Columns: columns, Conditions: where}
+}
+
+// Insert returns an insert operation of the row into the table.
+func Insert(table string, row map[string]interface{}) Operation {
+	return Operation{Name: "insert", Table: table, Row: row}
+}
+
+// Update returns an update operation setting the columns of the rows of
+// the table matching the conditions.
+func Update(table string, row map[string]interface{}, where ...Condition) Operation {
+	return Operation{Name: "update", Table: table, Row: row, Conditions: where}
+}
+
+// Mutate returns a mutate operation of the rows of the table matching the
+// conditions.
+func Mutate(table string, mutations []Mutation, where ...Condition) Operation {
+	return Operation{Name: "mutate", Table: table, Mutations: mutations, Conditions: where}
+}
+
+// Delete returns a delete operation of the rows of the table matching the
+// conditions.
+func Delete(table string, where ...Condition) Operation {
+	return Operation{Name: "delete", Table: table, Conditions: where}
+}
+
+// Wait returns a wait operation, which waits until the columns of the rows
+// of the table matching the conditions are equal ("==") or not equal ("!=")
+// to the rows, as specified by until. The timeout, in milliseconds, is set
+// with the Timeout member. Without it, the operation fails unless the
+// condition holds already.
+func Wait(table string, columns []string, until string, rows []map[string]interface{}, where ...Condition) Operation {
+	return Operation{Name: "wait", Table: table, Columns: columns, Until: until, Rows: rows, Conditions: where}
+}
+
+// WaitOp returns a wait operation which fails the transaction unless the
+// row of the table matching the conditions has the expected values of its
+// columns, right away, i.e. with a zero timeout. A transaction starting with
+// it takes effect only when the row has not changed since it was read,
+// which gives it compare-and-swap semantics. The failure matches
+// ErrConflict.
+func WaitOp(table string, expected map[string]interface{}, where ...Condition) Operation {
+	columns := make([]string, 0, len(expected))
+	for column := range expected {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	timeout := 0
+	op := Wait(table, columns, "==", []map[string]interface{}{expected}, where...)
+	op.Timeout = &timeout
+	return op
+}
+
+// Comment returns an operation adding the comment to the log of the
+// database, should the transaction commit changes.
+func Comment(comment string) Operation {
+	return Operation{Name: "comment", Comment: comment}
+}
+
+// Assert returns an operation which fails the transaction, with
+// ErrNotOwner, unless the client holds the lock, see Client.Lock.
+func Assert(lock string) Operation {
+	return Operation{Name: "assert", Lock: lock}
+}
+
+// MarshalJSON encodes the operation with the members of its kind, see RFC
+// 7047, Section 5.2.
+func (t Operation) MarshalJSON() ([]byte, error) {
+	if t.Name == "comment" {
+		// A comment operation has no table.
+		b, err := json.Marshal(struct {
+			Name    string `json:"op"`
+			Comment string `json:"comment"`
+		}{t.Name, t.Comment})
+		if err != nil {
+			return []byte{}, fmt.Errorf("marshal Operation.Comment: %s", err)
+		}
+		return b, nil
+	}
+	if t.Name == "assert" {
+		// An assert operation has no table either.
+		b, err := json.Marshal(struct {
+			Name string `json:"op"`
+			Lock string `json:"lock"`
+		}{t.Name, t.Lock})
+		if err != nil {
+			return []byte{}, fmt.Errorf("marshal Operation.Lock: %s", err)
+		}
+		return b, nil
+	}
+	where := t.Conditions
+	if where == nil {
+		where = []Condition{}
+	}
+	members := []struct {
+		name  string
+		value interface{}
+	}{
+		{"op", t.Name},
+		{"table", t.Table},
+	}
+	add := func(name string, value interface{}) {
+		members = append(members, struct {
+			name  string
+			value interface{}
+		}{name, value})
+	}
+	switch t.Name {
+	case "insert":
+		if t.Row != nil {
+			row, err := encodeRow(t.Row)
+			if err != nil {
+				return []byte{}, fmt.Errorf("marshal Operation.Row: %s", err)
+			}
+			add("row", row)
+		}
+		if t.UUIDName != "" {
+			add("uuid-name", t.UUIDName)
+		}
+	case "update":
+		row, err := encodeRow(t.Row)
+		if err != nil {
+			return []byte{}, fmt.Errorf("marshal Operation.Row: %s", err)
+		}
+		add("where", where)
+		add("row", row)
+	case "mutate":
+		mutations := t.Mutations
+		if mutations == nil {
+			mutations = []Mutation{}
+		}
+		add("where", where)
+		add("mutations", mutations)
+	case "delete":
+		add("where", where)
+	case "wait":
+		columns := t.Columns
+		if columns == nil {
+			columns = []string{}
+		}
+		rows := make([]map[string]interface{}, len(t.Rows))
+		for i, r := range t.Rows {
+			row, err := encodeRow(r)
+			if err != nil {
+				return []byte{}, fmt.Errorf("marshal Operation.Rows: %s", err)
+			}
+			rows[i] = row
+		}
+		add("where", where)
+		add("columns", columns)
+		add("until", t.Until)
+		add("rows", rows)
+		if t.Timeout != nil {
+			add("timeout", *t.Timeout)
+		}
+	default:
+		add("where", where)
+		if len(t.Columns) > 0 {
+			add("columns", t.Columns)
+		}
+	}
+	b := bytes.NewBuffer([]byte("{"))
+	for i, m := range members {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return []byte{}, fmt.Errorf("marshal Operation.%s: %s", m.name, err)
+		}
+		fmt.Fprintf(b, "%q:", m.name)
+		b.Write(value)
+	}
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+// NewOperation parses the query, e.g. "SELECT * FROM Bridge WHERE name==?".
+// The placeholders, "?", in the conditions are bound to the arguments, in
+// order. The arguments are typed values, as the operands of Equal, so
+// that they are sent as they are, no matter the quotes, spaces, or brackets
+// they contain.
+func NewOperation(query string, args ...interface{}) (Operation, error) {
 	t := Operation{}
 	t.Conditions = []Condition{}
-	if err := t.Parse(query); err != nil {
+	if err := t.parse(query, args); err != nil {
 		return t, err
 	}
 	if err := t.Validate(); err != nil {
@@ -45,11 +272,64 @@ func NewOperation(query string) (Operation, error) {
 
 // Parse - TODO
 func (t *Operation) Parse(i string) error {
+	return t.parse(i, nil)
+}
+
+// parse parses the query, binding its placeholders to the arguments.
+func (t *Operation) parse(i string, args []interface{}) error {
+	placeholders, err := t.parseQuery(i)
+	if err != nil {
+		return err
+	}
+	return t.bind(i, placeholders, args)
+}
+
+// bind binds the placeholders, i.e. the conditions at the indexes, to the
+// arguments, in order.
+func (t *Operation) bind(i string, placeholders []int, args []interface{}) error {
+	if len(args) < len(placeholders) {
+		return fmt.Errorf("parser error: no argument for placeholder %d in: %s", len(args)+1, i)
+	}
+	if len(args) > len(placeholders) {
+		return fmt.Errorf("parser error: %d arguments for %d placeholders in: %s", len(args), len(placeholders), i)
+	}
+	for n, index := range placeholders {
+		cond := &t.Conditions[index]
+		cond.Value, cond.Type, cond.Operand = "", "", args[n]
+		if cond.keyed {
+			cond.Operand = map[string]interface{}{cond.key: args[n]}
+		}
+	}
+	return nil
+}
+
+// parseQuery parses the query, and returns the indexes of the conditions
+// which end with a placeholder.
+func (t *Operation) parseQuery(i string) ([]int, error) {
 	var s scanner.Scanner
 	s.Init(strings.NewReader(i))
+	var scanErr error
+	s.Error = func(_ *scanner.Scanner, msg string) {
+		// The single-quoted values are scanned as invalid characters.
+		if msg != "invalid char literal" && scanErr == nil {
+			scanErr = fmt.Errorf("parser error: %s in: %s", msg, i)
+		}
+	}
 	var tok rune
 	stage := "operation"
 	conditions := []string{}
+	var placeholders []int
+	// mark marks the condition as a placeholder, should it be one.
+	mark := func(tokens []string) error {
+		ok, err := isPlaceholder(tokens)
+		if err != nil {
+			return fmt.Errorf("parser error: %s in: %s", err, i)
+		}
+		if ok {
+			placeholders = append(placeholders, len(t.Conditions))
+		}
+		return nil
+	}
 	for tok != scanner.EOF {
 		tok = s.Scan()
 		if s.TokenText() == "" {
@@ -57,57 +337,180 @@ func (t *Operation) Parse(i string) error {
 		}
 		switch stage {
 		case "operation":
+			// The queries only read the database. The writes are built
+			// with Insert, Update, Mutate, and Delete instead, so that
+			// a query never deletes or inserts rows by mistake.
 			t.Name = strings.ToLower(s.TokenText())
+			if t.Name != "select" {
+				return nil, fmt.Errorf("parser error: unsupported operation: %s in: %s", s.TokenText(), i)
+			}
 			stage = "columns"
 		case "columns":
-			if s.TokenText() == "FROM" {
+			if strings.EqualFold(s.TokenText(), "FROM") {
 				stage = "table"
 				continue
 			}
+			if s.TokenText() == "?" {
+				return nil, fmt.Errorf("parser error: placeholders are only supported in conditions")
+			}
 			if s.TokenText() != "," && s.TokenText() != "*" {
-				t.Columns = append(t.Columns, s.TokenText())
+				column, err := identifier(s.TokenText())
+				if err != nil {
+					return nil, fmt.Errorf("parser error: %s in: %s", err, i)
+				}
+				t.Columns = append(t.Columns, column)
 			}
 		case "table":
-			t.Table = s.TokenText()
+			table, err := identifier(s.TokenText())
+			if err != nil {
+				return nil, fmt.Errorf("parser error: %s in: %s", err, i)
+			}
+			t.Table = table
 			stage = "where"
 		case "where":
-			if s.TokenText() != "WHERE" {
-				return fmt.Errorf("parser error: expected WHERE clause")
+			switch {
+			case strings.EqualFold(s.TokenText(), "ORDER"):
+				stage = "order"
+			case strings.EqualFold(s.TokenText(), "LIMIT"):
+				stage = "limits"
+			case strings.EqualFold(s.TokenText(), "WHERE"):
+				stage = "conditions"
+			default:
+				return nil, fmt.Errorf("parser error: expected WHERE clause")
 			}
-			stage = "conditions"
 		case "conditions":
 			if t.Table == "" {
-				return fmt.Errorf("parser error: expected FROM clause followed by a table name")
+				return nil, fmt.Errorf("parser error: expected FROM clause followed by a table name")
 			}
-			if s.TokenText() == "LIMIT" {
-				stage = "lmits"
+			if strings.EqualFold(s.TokenText(), "ORDER") {
+				stage = "order"
+				continue
+			}
+			if strings.EqualFold(s.TokenText(), "LIMIT") {
+				stage = "limits"
 				continue
 			}
 			if s.TokenText() == "," {
-				cond, err := NewCondition(conditions)
+				if err := mark(conditions); err != nil {
+					return nil, err
+				}
+				cond, err := parseCondition(conditions)
 				if err != nil {
-					return fmt.Errorf("parser error: %s for: %s", err, i)
+					return nil, fmt.Errorf("parser error: %s for: %s", err, i)
 				}
 				t.Conditions = append(t.Conditions, cond)
 				conditions = conditions[:0]
 				continue
 			}
 			conditions = append(conditions, s.TokenText())
+		case "order":
+			if !strings.EqualFold(s.TokenText(), "BY") {
+				return nil, fmt.Errorf("parser error: expected BY after ORDER in: %s", i)
+			}
+			stage = "orderby"
+		case "orderby":
+			column, err := identifier(s.TokenText())
+			if err != nil {
+				return nil, fmt.Errorf("parser error: %s in: %s", err, i)
+			}
+			t.OrderBy = column
+			stage = "direction"
+		case "direction":
+			switch {
+			case strings.EqualFold(s.TokenText(), "ASC"):
+				stage = "limit"
+			case strings.EqualFold(s.TokenText(), "DESC"):
+				t.Descending = true
+				stage = "limit"
+			case strings.EqualFold(s.TokenText(), "LIMIT"):
+				stage = "limits"
+			default:
+				return nil, fmt.Errorf("parser error: unexpected '%s' after ORDER BY in: %s", s.TokenText(), i)
+			}
+		case "limit":
+			if !strings.EqualFold(s.TokenText(), "LIMIT") {
+				return nil, fmt.Errorf("parser error: unexpected '%s' after ORDER BY in: %s", s.TokenText(), i)
+			}
+			stage = "limits"
 		case "limits":
-			return fmt.Errorf("parser error: unsupported LIMIT clause")
+			n, err := strconv.Atoi(s.TokenText())
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("parser error: invalid LIMIT '%s' in: %s", s.TokenText(), i)
+			}
+			t.Limit = n
+			stage = "end"
+		case "end":
+			return nil, fmt.Errorf("parser error: unexpected '%s' at the end of: %s", s.TokenText(), i)
 		default:
-			return fmt.Errorf("parser error: unknown stage: %s", stage)
+			return nil, fmt.Errorf("parser error: unknown stage: %s", stage)
 		}
 	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
 	if len(conditions) > 0 {
-		cond, err := NewCondition(conditions)
+		if err := mark(conditions); err != nil {
+			return nil, err
+		}
+		cond, err := parseCondition(conditions)
 		if err != nil {
-			return fmt.Errorf("parser error: invalid condition: %s", conditions)
+			return nil, fmt.Errorf("parser error: invalid condition: %s", conditions)
 		}
 		t.Conditions = append(t.Conditions, cond)
 	}
+	switch stage {
+	case "order", "orderby":
+		return nil, fmt.Errorf("parser error: expected ORDER BY followed by a column in: %s", i)
+	case "limits":
+		return nil, fmt.Errorf("parser error: expected LIMIT followed by a number in: %s", i)
+	}
+	if t.OrderBy != "" && len(t.Columns) > 0 && !contains(t.Columns, t.OrderBy) {
+		return nil, fmt.Errorf("parser error: ORDER BY column %s is not selected in: %s", t.OrderBy, i)
+	}
 	//spew.Dump(t)
-	return nil
+	return placeholders, nil
+}
+
+// isPlaceholder reports whether the tokens of a condition compare a column
+// to a placeholder, i.e. they are exactly <column> <function> ?, or
+// <column>:<key> <function> ?. A placeholder anywhere else, e.g. followed
+// by more tokens, is an error, rather than a condition binding a part of
+// the query it was not written for.
+func isPlaceholder(tokens []string) (bool, error) {
+	found := false
+	for _, tok := range tokens {
+		if tok == "?" {
+			found = true
+		}
+	}
+	if !found {
+		return false, nil
+	}
+	invalid := fmt.Errorf("invalid placeholder in condition '%s', expected <column> <function> ?", strings.Join(tokens, ""))
+	isOperator := func(tok string) bool {
+		return !isQuoted(tok) && strings.Trim(tok, "=!<>~") == ""
+	}
+	end := len(tokens) - 1
+	if tokens[end] != "?" {
+		return false, invalid
+	}
+	start := end
+	for start > 0 && isOperator(tokens[start-1]) {
+		start--
+	}
+	if start == end || !isFunction(strings.Join(tokens[start:end], "")) {
+		return false, invalid
+	}
+	column := tokens[:start]
+	if len(column) == 0 || (len(column) > 1 && (len(column) < 3 || column[1] != ":")) {
+		return false, invalid
+	}
+	for _, tok := range column {
+		if tok == "?" || isOperator(tok) {
+			return false, invalid
+		}
+	}
+	return true, nil
 }
 
 // Validate - TODO
@@ -139,6 +542,31 @@ func (t *Operation) Validate() error {
 			if len(t.Columns) == 0 && m.Required {
 				return fmt.Errorf("validation error: no columns")
 			}
+		case "row":
+			if len(t.Row) == 0 && m.Required {
+				return fmt.Errorf("validation error: no row")
+			}
+		case "mutations":
+			if len(t.Mutations) == 0 && m.Required {
+				return fmt.Errorf("validation error: no mutations")
+			}
+		case "until":
+			if t.Until != "==" && t.Until != "!=" && m.Required {
+				return fmt.Errorf("validation error: until must be == or !=, got '%s'", t.Until)
+			}
+		case "uuid-name":
+			if t.UUIDName != "" && !isIdentifier(t.UUIDName) {
+				return fmt.Errorf("validation error: invalid uuid-name '%s'", t.UUIDName)
+			}
+		case "comment":
+			if t.Comment == "" && m.Required {
+				return fmt.Errorf("validation error: no comment")
+			}
+		case "lock":
+			if t.Lock == "" && m.Required {
+				return fmt.Errorf("validation error: no lock")
+			}
+		case "rows", "timeout":
 		default:
 			return fmt.Errorf("validation error: unsupported transaction member: %s", m.Name)
 		}
@@ -146,6 +574,32 @@ func (t *Operation) Validate() error {
 	return nil
 }
 
+// isIdentifier returns true when the name is an <id>, see RFC 7047,
+// Section 3.1, e.g. a uuid-name.
+func isIdentifier(name string) bool {
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// identifier returns the name of a column or of a table of a query, which
+// may be backquoted, e.g. `my column`.
+func identifier(tok string) (string, error) {
+	if !isQuoted(tok) {
+		return tok, nil
+	}
+	if tok[0] != '`' {
+		return "", fmt.Errorf("expected an identifier, got %s", tok)
+	}
+	return unquoteToken(tok)
+}
+
 type member struct {
 	Name     string
 	Required bool
@@ -158,6 +612,32 @@ type operationConfiguration struct {
 }
 
 var operations = map[string]operationConfiguration{
+	"assert": {
+		Name: "assert",
+		Members: map[string]member{
+			"op": {
+				Name:     "op",
+				Required: true,
+			},
+			"lock": {
+				Name:     "lock",
+				Required: true,
+			},
+		},
+	},
+	"comment": {
+		Name: "comment",
+		Members: map[string]member{
+			"op": {
+				Name:     "op",
+				Required: true,
+			},
+			"comment": {
+				Name:     "comment",
+				Required: true,
+			},
+		},
+	},
 	"select": {
 		Name: "select",
 		Members: map[string]member{
@@ -180,4 +660,121 @@ var operations = map[string]operationConfiguration{
 			},
 		},
 	},
+	"insert": {
+		Name: "insert",
+		Members: map[string]member{
+			"op": {
+				Name:     "op",
+				Required: true,
+			},
+			"table": {
+				Name:     "table",
+				Required: true,
+			},
+			"row": {
+				Name:     "row",
+				Required: false,
+			},
+			"uuid-name": {
+				Name:     "uuid-name",
+				Required: false,
+			},
+		},
+	},
+	"update": {
+		Name: "update",
+		Members: map[string]member{
+			"op": {
+				Name:     "op",
+				Required: true,
+			},
+			"table": {
+				Name:     "table",
+				Required: true,
+			},
+			"where": {
+				Name:     "where",
+				Required: true,
+				Autofill: true,
+			},
+			"row": {
+				Name:     "row",
+				Required: true,
+			},
+		},
+	},
+	"mutate": {
+		Name: "mutate",
+		Members: map[string]member{
+			"op": {
+				Name:     "op",
+				Required: true,
+			},
+			"table": {
+				Name:     "table",
+				Required: true,
+			},
+			"where": {
+				Name:     "where",
+				Required: true,
+				Autofill: true,
+			},
+			"mutations": {
+				Name:     "mutations",
+				Required: true,
+			},
+		},
+	},
+	"delete": {
+		Name: "delete",
+		Members: map[string]member{
+			"op": {
+				Name:     "op",
+				Required: true,
+			},
+			"table": {
+				Name:     "table",
+				Required: true,
+			},
+			"where": {
+				Name:     "where",
+				Required: true,
+				Autofill: true,
+			},
+		},
+	},
+	"wait": {
+		Name: "wait",
+		Members: map[string]member{
+			"op": {
+				Name:     "op",
+				Required: true,
+			},
+			"table": {
+				Name:     "table",
+				Required: true,
+			},
+			"where": {
+				Name:     "where",
+				Required: true,
+				Autofill: true,
+			},
+			"columns": {
+				Name:     "columns",
+				Required: false,
+			},
+			"until": {
+				Name:     "until",
+				Required: true,
+			},
+			"rows": {
+				Name:     "rows",
+				Required: false,
+			},
+			"timeout": {
+				Name:     "timeout",
+				Required: false,
+			},
+		},
+	},
 }
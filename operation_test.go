@@ -109,3 +109,272 @@ func TestMarshalOperation(t *testing.T) {
 		t.Fatalf("Failed %d tests", testFailed)
 	}
 }
+
+func TestOperationBuilders(t *testing.T) {
+	timeout := 1000
+	wait := Wait("Bridge", []string{"name"}, "==", []map[string]interface{}{{"name": "br-int"}},
+		Condition{Column: "name", Function: "==", Value: "br-int", Type: "string"})
+	wait.Timeout = &timeout
+	insert := Insert("Port", map[string]interface{}{"name": "vif1", "tag": 10, "trunks": []int{1, 2}})
+	insert.UUIDName = "new_port"
+	query, _ := NewOperation("SELECT db_version, ovs_version FROM Open_vSwitch WHERE db_version==\"7.3.0\"")
+	for i, test := range []struct {
+		op         Operation
+		want       string
+		shouldFail bool
+	}{
+		{
+			op:   query,
+			want: `{"op":"select","table":"Open_vSwitch","where":[["db_version","==","7.3.0"]],"columns":["db_version","ovs_version"]}`,
+		},
+		{
+			op:   Select("Open_vSwitch", nil),
+			want: `{"op":"select","table":"Open_vSwitch","where":[]}`,
+		},
+		{
+			op:   insert,
+			want: `{"op":"insert","table":"Port","row":{"name":"vif1","tag":10,"trunks":["set",[1,2]]},"uuid-name":"new_port"}`,
+		},
+		{
+			op: Update("Interface", map[string]interface{}{"external_ids": map[string]string{"owner": "test", "iface-id": "vif1"}},
+				Condition{Column: "name", Function: "==", Value: "vif1", Type: "string"}),
+			want: `{"op":"update","table":"Interface","where":[["name","==","vif1"]],"row":{"external_ids":["map",[["iface-id","vif1"],["owner","test"]]]}}`,
+		},
+		{
+			op:   Mutate("Bridge", []Mutation{{Column: "ports", Mutator: "delete", Value: []string{}}}),
+			want: `{"op":"mutate","table":"Bridge","where":[],"mutations":[["ports","delete",["set",[]]]]}`,
+		},
+		{
+			op:   Delete("Port", Condition{Column: "name", Function: "==", Value: "vif1", Type: "string"}),
+			want: `{"op":"delete","table":"Port","where":[["name","==","vif1"]]}`,
+		},
+		{
+			op:   wait,
+			want: `{"op":"wait","table":"Bridge","where":[["name","==","br-int"]],"columns":["name"],"until":"==","rows":[{"name":"br-int"}],"timeout":1000}`,
+		},
+		{
+			op:   Comment(`ovn-controller: "claim" vif1`),
+			want: `{"op":"comment","comment":"ovn-controller: \"claim\" vif1"}`,
+		},
+		{
+			op:         Insert("Port", map[string]interface{}{"trunks": [][]int{{1}}}),
+			shouldFail: true,
+		},
+	} {
+		b, err := json.Marshal(test.op)
+		if err != nil {
+			if !test.shouldFail {
+				t.Fatalf("FAIL: Test %d: expected to marshal, but failed: %v", i, err)
+			}
+			t.Logf("PASS: Test %d: failed to marshal as expected: %v", i, err)
+			continue
+		}
+		if test.shouldFail {
+			t.Fatalf("FAIL: Test %d: expected to fail, but marshaled: %s", i, b)
+		}
+		if string(b) != test.want {
+			t.Fatalf("FAIL: Test %d: expected '%s', but got '%s'", i, test.want, b)
+		}
+		if err := test.op.Validate(); err != nil {
+			t.Fatalf("FAIL: Test %d: expected to be valid, but failed: %v", i, err)
+		}
+		t.Logf("PASS: Test %d: %s", i, b)
+	}
+
+	for i, op := range []Operation{
+		Update("Interface", nil),
+		Mutate("Bridge", nil),
+		Wait("Bridge", nil, "<", nil),
+		Insert("", nil),
+		Comment(""),
+	} {
+		if err := op.Validate(); err == nil {
+			t.Fatalf("FAIL: Test %d: expected %s operation to be invalid", i, op.Name)
+		}
+	}
+}
+
+func TestNewOperationArgs(t *testing.T) {
+	for i, test := range []struct {
+		query      string
+		args       []interface{}
+		want       string
+		shouldFail bool
+	}{
+		{
+			query: "SELECT * FROM Bridge WHERE name==?",
+			args:  []interface{}{`br"0", ["x"]`},
+			want:  `{"op":"select","table":"Bridge","where":[["name","==","br\"0\", [\"x\"]"]]}`,
+		},
+		{
+			query: "SELECT name FROM Interface WHERE ofport==?, external_ids!=?",
+			args:  []interface{}{1, map[string]string{"iface-id": "vif 1"}},
+			want:  `{"op":"select","table":"Interface","where":[["ofport","==",1],["external_ids","!=",["map",[["iface-id","vif 1"]]]]],"columns":["name"]}`,
+		},
+		{
+			query: "SELECT * FROM Port WHERE _uuid==?",
+			args:  []interface{}{UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8")},
+			want:  `{"op":"select","table":"Port","where":[["_uuid","==",["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]]}`,
+		},
+		{
+			query: `SELECT * FROM Bridge WHERE name=="?"`,
+			want:  `{"op":"select","table":"Bridge","where":[["name","==","?"]]}`,
+		},
+		{query: "SELECT * FROM Bridge WHERE name==?", shouldFail: true},
+		{query: "SELECT * FROM Bridge WHERE name==?", args: []interface{}{"br0", "br1"}, shouldFail: true},
+		{query: "SELECT ? FROM Bridge", args: []interface{}{"name"}, shouldFail: true},
+		{query: "SELECT * FROM Bridge WHERE name==? AND ofport>=?", args: []interface{}{"x"}, shouldFail: true},
+		{query: "SELECT * FROM Bridge WHERE name==? AND ofport>=?", args: []interface{}{"x", 1}, shouldFail: true},
+		{query: "SELECT * FROM Bridge WHERE name==?x", args: []interface{}{"x"}, shouldFail: true},
+		{query: "SELECT * FROM Bridge WHERE ==?", args: []interface{}{"x"}, shouldFail: true},
+		{
+			query: "SELECT * FROM Interface WHERE external_ids:iface-id==?",
+			args:  []interface{}{"vm1"},
+			want:  `{"op":"select","table":"Interface","where":[["external_ids","includes",["map",[["iface-id","vm1"]]]]]}`,
+		},
+	} {
+		op, err := NewOperation(test.query, test.args...)
+		if err != nil {
+			if !test.shouldFail {
+				t.Fatalf("FAIL: Test %d: query '%s', expected to pass, but failed with: %v", i, test.query, err)
+			}
+			t.Logf("PASS: Test %d: query '%s', failed as expected: %v", i, test.query, err)
+			continue
+		}
+		if test.shouldFail {
+			t.Fatalf("FAIL: Test %d: query '%s', expected to fail, but passed", i, test.query)
+		}
+		b, err := json.Marshal(op)
+		if err != nil {
+			t.Fatalf("FAIL: Test %d: query '%s', expected to marshal, but failed: %v", i, test.query, err)
+		}
+		if string(b) != test.want {
+			t.Fatalf("FAIL: Test %d: query '%s', expected '%s', but got '%s'", i, test.query, test.want, b)
+		}
+		t.Logf("PASS: Test %d: query '%s' bound to %s", i, test.query, b)
+	}
+}
+
+func TestNewOperationWrites(t *testing.T) {
+	for i, query := range []string{
+		"DELETE FROM Bridge",
+		"delete FROM Bridge WHERE name==br0",
+		"INSERT FROM Bridge",
+		"UPDATE FROM Bridge WHERE name==br0",
+		"MUTATE FROM Bridge",
+		"WAIT FROM Bridge",
+	} {
+		op, err := NewOperation(query)
+		if err == nil {
+			t.Fatalf("FAIL: Test %d: query '%s', expected to fail, but got %s operation", i, query, op.Name)
+		}
+		t.Logf("PASS: Test %d: query '%s', failed as expected: %v", i, query, err)
+	}
+	op := Delete("Bridge", Equal("name", "br0"))
+	if err := op.Validate(); err != nil {
+		t.Fatalf("FAIL: expected delete operation to be valid, but failed: %v", err)
+	}
+}
+
+func TestNewOperationProjection(t *testing.T) {
+	for i, test := range []struct {
+		query string
+		want  string
+	}{
+		{
+			query: `SELECT name, ports FROM Bridge WHERE name=="br0"`,
+			want:  `{"op":"select","table":"Bridge","where":[["name","==","br0"]],"columns":["name","ports"]}`,
+		},
+		{
+			query: `SELECT _uuid,match FROM Logical_Flow WHERE table_id>=10, priority==100, external_ids:stage-name=="ls_in_acl"`,
+			want:  `{"op":"select","table":"Logical_Flow","where":[["table_id","\u003e=",10],["priority","==",100],["external_ids","includes",["map",[["stage-name","ls_in_acl"]]]]],"columns":["_uuid","match"]}`,
+		},
+		{
+			query: `select mac from MAC_Binding where ip=="10.0.0.1"`,
+			want:  `{"op":"select","table":"MAC_Binding","where":[["ip","==","10.0.0.1"]],"columns":["mac"]}`,
+		},
+		{
+			query: `SELECT * FROM Port WHERE tag==1.5`,
+			want:  `{"op":"select","table":"Port","where":[["tag","==",1.5]]}`,
+		},
+	} {
+		op, err := NewOperation(test.query)
+		if err != nil {
+			t.Fatalf("FAIL: Test %d: query '%s', expected to pass, but failed with: %v", i, test.query, err)
+		}
+		b, err := json.Marshal(op)
+		if err != nil {
+			t.Fatalf("FAIL: Test %d: query '%s', expected to marshal, but failed: %v", i, test.query, err)
+		}
+		if string(b) != test.want {
+			t.Fatalf("FAIL: Test %d: query '%s', expected '%s', but got '%s'", i, test.query, test.want, b)
+		}
+		t.Logf("PASS: Test %d: query '%s' sent as %s", i, test.query, b)
+	}
+
+	columns := map[string]string{"_uuid": "string", "name": "string", "ports": "[]string"}
+	if got := projectColumns(columns, []string{"name", "missing"}); len(got) != 1 || got["name"] != "string" {
+		t.Fatalf("FAIL: expected the types of the projected columns, got %v", got)
+	}
+	if got := projectColumns(columns, nil); len(got) != 3 {
+		t.Fatalf("FAIL: expected the types of all the columns, got %v", got)
+	}
+	t.Logf("PASS: projected the column types")
+}
+
+func TestNewOperationQuoting(t *testing.T) {
+	for i, test := range []struct {
+		query      string
+		args       []interface{}
+		want       string
+		shouldFail bool
+	}{
+		{
+			query: `SELECT * FROM Port WHERE name=='a b, FROM c'`,
+			want:  `{"op":"select","table":"Port","where":[["name","==","a b, FROM c"]]}`,
+		},
+		{
+			query: `SELECT * FROM Port WHERE name=='it\'s', other=="say \"hi\""`,
+			want:  `{"op":"select","table":"Port","where":[["name","==","it's"],["other","==","say \"hi\""]]}`,
+		},
+		{
+			query: "SELECT `name` FROM `Port` WHERE `odd col`==5",
+			want:  `{"op":"select","table":"Port","where":[["odd col","==",5]],"columns":["name"]}`,
+		},
+		{
+			query: `SELECT * FROM Interface WHERE external_ids:'iface-id'!='vm 1, a'`,
+			want:  `{"op":"select","table":"Interface","where":[["external_ids","excludes",["map",[["iface-id","vm 1, a"]]]]]}`,
+		},
+		{
+			query: `SELECT * FROM Interface WHERE external_ids:owner==?`,
+			args:  []interface{}{"FROM ovn"},
+			want:  `{"op":"select","table":"Interface","where":[["external_ids","includes",["map",[["owner","FROM ovn"]]]]]}`,
+		},
+		{query: `SELECT * FROM Interface WHERE external_ids:'k'>1`, shouldFail: true},
+		{query: `SELECT * FROM Port WHERE name=='unterminated`, shouldFail: true},
+		{query: `SELECT 'name' FROM Port`, shouldFail: true},
+		{query: `SELECT * FROM Port WHERE name=='it''s'`, shouldFail: true},
+		{query: `SELECT * FROM Port WHERE name=="say ""hi"""`, shouldFail: true},
+		{query: `SELECT * FROM Interface WHERE external_ids:owner=='it''s'`, shouldFail: true},
+	} {
+		op, err := NewOperation(test.query, test.args...)
+		if test.shouldFail {
+			if err == nil {
+				t.Fatalf("FAIL: Test %d: query '%s', expected to fail, but passed", i, test.query)
+			}
+			t.Logf("PASS: Test %d: query '%s' failed as expected: %v", i, test.query, err)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("FAIL: Test %d: query '%s', expected to pass, but failed with: %v", i, test.query, err)
+		}
+		b, err := json.Marshal(op)
+		if err != nil {
+			t.Fatalf("FAIL: Test %d: query '%s', expected to marshal, but failed: %v", i, test.query, err)
+		}
+		if string(b) != test.want {
+			t.Fatalf("FAIL: Test %d: query '%s', expected '%s', but got '%s'", i, test.query, test.want, b)
+		}
+		t.Logf("PASS: Test %d: query '%s' is %s", i, test.query, b)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Optional is the value of an optional column, i.e. of a column whose
+// values are sets of at most one element, e.g. ofport of the Interface
+// table. Present tells an empty column from one holding the zero value,
+// e.g. 0 or "".
+type Optional[T any] struct {
+	Value   T
+	Present bool
+}
+
+// Some returns the optional value holding v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, Present: true}
+}
+
+// Get returns the value, and whether there is one.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Present
+}
+
+// Or returns the value, or v when there is none.
+func (o Optional[T]) Or(v T) T {
+	if !o.Present {
+		return v
+	}
+	return o.Value
+}
+
+// MarshalJSON encodes the value as its only element, or the empty set.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Present {
+		return json.Marshal([]interface{}{"set", []interface{}{}})
+	}
+	atom, err := encodeAtom(o.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(atom)
+}
+
+// UnmarshalJSON decodes the value from its OVSDB notation.
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	return o.decodeOptional(data)
+}
+
+// decodeOptional decodes the value of the column into the optional value,
+// so that the fields of the structs decoded by Unmarshal may be optional.
+func (o *Optional[T]) decodeOptional(data interface{}) error {
+	*o = Optional[T]{}
+	switch kind, elems := splitValue(data); {
+	case kind == "map":
+		return fmt.Errorf("expected an optional value, got a map")
+	case kind == "set" && len(elems) == 0:
+		return nil
+	case kind == "set" && len(elems) > 1:
+		return fmt.Errorf("expected an optional value, got a set of %d elements", len(elems))
+	}
+	if err := decodeColumn(reflect.ValueOf(&o.Value).Elem(), data); err != nil {
+		return err
+	}
+	o.Present = true
+	return nil
+}
+
+// optionalDecoder is implemented by the pointers to optional values.
+type optionalDecoder interface {
+	decodeOptional(data interface{}) error
+}
+
+// GetOptional returns the value of the optional column of the row, with
+// the conversions of Get. The value is not present when the column is the
+// empty set.
+func GetOptional[T any](r Row, column string) (Optional[T], error) {
+	var o Optional[T]
+	data, exists := r[column]
+	if !exists {
+		return o, fmt.Errorf("column %s: %w", column, ErrColumnNotFound)
+	}
+	if err := o.decodeOptional(data); err != nil {
+		return Optional[T]{}, fmt.Errorf("column %s: %s", column, err)
+	}
+	return o, nil
+}
+
+// GetOptionalString returns the value of an optional string column, or
+// of an optional reference.
+func (r Row) GetOptionalString(column string) (Optional[string], error) {
+	return GetOptional[string](r, column)
+}
+
+// GetOptionalInt returns the value of an optional integer column.
+func (r Row) GetOptionalInt(column string) (Optional[int64], error) {
+	return GetOptional[int64](r, column)
+}
+
+// GetOptionalBool returns the value of an optional boolean column.
+func (r Row) GetOptionalBool(column string) (Optional[bool], error) {
+	return GetOptional[bool](r, column)
+}
+
+// GetOptionalUUID returns the value of an optional reference column.
+func (r Row) GetOptionalUUID(column string) (Optional[UUID], error) {
+	return GetOptional[UUID](r, column)
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestGetOptional(t *testing.T) {
+	var row Row
+	data := `{"ofport":["set",[]],"mtu":0,"ifindex":["set",[7]],"mac_in_use":"","link_state":["set",[]],"up":false,
+		"chassis":["set",[["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"]]],"tags":["set",[1,2]],"external_ids":["map",[]]}`
+	if err := json.Unmarshal([]byte(data), &row); err != nil {
+		t.Fatalf("FAIL: failed to decode the row: %v", err)
+	}
+	for _, test := range []struct {
+		column  string
+		value   interface{}
+		present bool
+		fails   bool
+	}{
+		{column: "ofport", value: int64(0)},
+		{column: "mtu", value: int64(0), present: true},
+		{column: "ifindex", value: int64(7), present: true},
+		{column: "mac_in_use", value: "", present: true},
+		{column: "link_state", value: ""},
+		{column: "up", value: false, present: true},
+		{column: "chassis", value: UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"), present: true},
+		{column: "tags", fails: true},
+		{column: "external_ids", fails: true},
+	} {
+		var value interface{}
+		var present bool
+		var err error
+		switch test.value.(type) {
+		case string:
+			var o Optional[string]
+			o, err = row.GetOptionalString(test.column)
+			value, present = o.Get()
+		case bool:
+			var o Optional[bool]
+			o, err = row.GetOptionalBool(test.column)
+			value, present = o.Get()
+		case UUID:
+			var o Optional[UUID]
+			o, err = row.GetOptionalUUID(test.column)
+			value, present = o.Get()
+		default:
+			var o Optional[int64]
+			o, err = row.GetOptionalInt(test.column)
+			value, present = o.Get()
+		}
+		if test.fails {
+			if err == nil {
+				t.Fatalf("FAIL: %s: expected to fail, but got %v", test.column, value)
+			}
+			t.Logf("PASS: %s: %v", test.column, err)
+			continue
+		}
+		if err != nil {
+			t.Fatalf("FAIL: %s: expected to pass, but failed with: %v", test.column, err)
+		}
+		if value != test.value || present != test.present {
+			t.Fatalf("FAIL: %s: expected %v, %t, but got %v, %t", test.column, test.value, test.present, value, present)
+		}
+		t.Logf("PASS: %s: %v, %t", test.column, value, present)
+	}
+	if _, err := GetOptional[string](row, "missing"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("FAIL: expected ErrColumnNotFound, got %v", err)
+	}
+	if v := (Optional[int64]{}).Or(-1); v != -1 {
+		t.Fatalf("FAIL: expected the default of an empty value, got %d", v)
+	}
+
+	var ports []struct {
+		OfPort Optional[int64] `ovsdb:"ofport"`
+		Mtu    Optional[int64] `ovsdb:"mtu"`
+	}
+	r := Result{Rows: []Row{row}}
+	if err := r.Unmarshal(&ports); err != nil {
+		t.Fatalf("FAIL: failed to unmarshal the rows: %v", err)
+	}
+	if ports[0].OfPort.Present || !ports[0].Mtu.Present {
+		t.Fatalf("FAIL: unexpected optional fields: %+v", ports[0])
+	}
+	for _, test := range []struct {
+		value Optional[string]
+		want  string
+	}{
+		{value: Optional[string]{}, want: `["set",[]]`},
+		{value: Some(""), want: `""`},
+		{value: Some("up"), want: `"up"`},
+	} {
+		b, err := json.Marshal(test.value)
+		if err != nil || string(b) != test.want {
+			t.Fatalf("FAIL: expected %s, but got %s, %v", test.want, b, err)
+		}
+		var o Optional[string]
+		if err := json.Unmarshal(b, &o); err != nil || o != test.value {
+			t.Fatalf("FAIL: expected %s to decode as %+v, but got %+v, %v", b, test.value, o, err)
+		}
+	}
+	t.Logf("PASS: optional struct fields and encoding")
+}
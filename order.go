@@ -0,0 +1,163 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"sort"
+)
+
+// Sort applies the ORDER BY and LIMIT clauses of the operation to the rows
+// of its result, and returns them. The values are compared by type: the
+// numbers numerically, the strings and references lexically, and false
+// before true. An optional column sorts as its element, and before all the
+// others when it is empty; a set or a map sorts as its first element.
+func (t Operation) Sort(rows []Row) []Row {
+	idx := t.sortIndex(rows)
+	sorted := make([]Row, len(idx))
+	for i, k := range idx {
+		sorted[i] = rows[k]
+	}
+	return sorted
+}
+
+// sortIndex returns the indexes of the rows, sorted and limited as by Sort.
+func (t Operation) sortIndex(rows []Row) []int {
+	idx := make([]int, len(rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	if t.OrderBy != "" {
+		keys := make([]interface{}, len(rows))
+		for i, row := range rows {
+			keys[i] = sortKey(row[t.OrderBy])
+		}
+		sort.SliceStable(idx, func(i, j int) bool {
+			c := compareAtoms(keys[idx[i]], keys[idx[j]])
+			if t.Descending {
+				return c > 0
+			}
+			return c < 0
+		})
+	}
+	if t.Limit > 0 && len(idx) > t.Limit {
+		idx = idx[:t.Limit]
+	}
+	return idx
+}
+
+// sortKey returns the atom a value sorts as, or nil for an empty set.
+func sortKey(data interface{}) interface{} {
+	if s, err := newOvsSet(data); err == nil {
+		if len(s) == 0 {
+			return nil
+		}
+		return s[0]
+	}
+	// A map sorts as its first key, in the order of the server.
+	if m, ok := data.([]interface{}); ok && len(m) == 2 {
+		if pairs, ok := m[1].([]interface{}); ok && len(pairs) > 0 {
+			if pair, ok := pairs[0].([]interface{}); ok && len(pair) == 2 {
+				if atom, err := typedAtom(pair[0]); err == nil {
+					return atom
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// compareAtoms returns -1, 0, or 1 when the atom a sorts before, with, or
+// after the atom b. The atoms of different types sort by type.
+func compareAtoms(a, b interface{}) int {
+	ra, rb := atomRank(a), atomRank(b)
+	if ra != rb {
+		return compareInts(ra, rb)
+	}
+	switch x := a.(type) {
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case int64, float64:
+		fx, fy := atomFloat(a), atomFloat(b)
+		switch {
+		case fx < fy:
+			return -1
+		case fx > fy:
+			return 1
+		}
+		return 0
+	case string, UUID, NamedUUID:
+		sx, sy := atomString(a), atomString(b)
+		switch {
+		case sx < sy:
+			return -1
+		case sx > sy:
+			return 1
+		}
+		return 0
+	}
+	return 0
+}
+
+// atomRank returns the rank of the type of the atom, the empty value
+// first.
+func atomRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case int64, float64:
+		return 2
+	case string:
+		return 3
+	case UUID, NamedUUID:
+		return 4
+	}
+	return 5
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func atomFloat(v interface{}) float64 {
+	if n, ok := v.(int64); ok {
+		return float64(n)
+	}
+	return v.(float64)
+}
+
+func atomString(v interface{}) string {
+	switch x := v.(type) {
+	case UUID:
+		return string(x)
+	case NamedUUID:
+		return string(x)
+	}
+	return v.(string)
+}
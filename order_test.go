@@ -0,0 +1,100 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestParseOrderByLimit(t *testing.T) {
+	for i, test := range []struct {
+		query      string
+		orderBy    string
+		descending bool
+		limit      int
+		shouldFail bool
+	}{
+		{query: "SELECT * FROM Chassis_Private ORDER BY nb_cfg_timestamp DESC LIMIT 100", orderBy: "nb_cfg_timestamp", descending: true, limit: 100},
+		{query: `SELECT name, nb_cfg FROM Chassis_Private WHERE name!="hv1" order by name asc`, orderBy: "name"},
+		{query: `SELECT * FROM Chassis_Private WHERE name!="hv1" LIMIT 5`, limit: 5},
+		{query: "SELECT * FROM Chassis_Private LIMIT 1", limit: 1},
+		{query: "SELECT * FROM Chassis_Private ORDER BY name LIMIT 3", orderBy: "name", limit: 3},
+		{query: "SELECT * FROM Chassis_Private ORDER BY `nb_cfg_timestamp` DESC", orderBy: "nb_cfg_timestamp", descending: true},
+		{query: `SELECT * FROM Chassis_Private ORDER BY "name"`, shouldFail: true},
+		{query: "SELECT * FROM Chassis_Private ORDER name", shouldFail: true},
+		{query: "SELECT * FROM Chassis_Private ORDER BY", shouldFail: true},
+		{query: "SELECT * FROM Chassis_Private ORDER BY name UP", shouldFail: true},
+		{query: "SELECT * FROM Chassis_Private LIMIT", shouldFail: true},
+		{query: "SELECT * FROM Chassis_Private LIMIT 0", shouldFail: true},
+		{query: "SELECT * FROM Chassis_Private LIMIT 5 ORDER BY name", shouldFail: true},
+		{query: "SELECT name FROM Chassis_Private ORDER BY nb_cfg", shouldFail: true},
+	} {
+		op, err := NewOperation(test.query)
+		if err != nil {
+			if !test.shouldFail {
+				t.Fatalf("FAIL: Test %d: query '%s', expected to pass, but failed with: %v", i, test.query, err)
+			}
+			t.Logf("PASS: Test %d: query '%s', failed as expected: %v", i, test.query, err)
+			continue
+		}
+		if test.shouldFail {
+			t.Fatalf("FAIL: Test %d: query '%s', expected to fail, but passed", i, test.query)
+		}
+		if op.OrderBy != test.orderBy || op.Descending != test.descending || op.Limit != test.limit {
+			t.Fatalf("FAIL: Test %d: query '%s', expected ORDER BY %q desc=%v LIMIT %d, got %q desc=%v LIMIT %d",
+				i, test.query, test.orderBy, test.descending, test.limit, op.OrderBy, op.Descending, op.Limit)
+		}
+		b, _ := json.Marshal(op)
+		t.Logf("PASS: Test %d: query '%s' sent as %s", i, test.query, b)
+	}
+}
+
+func TestOperationSort(t *testing.T) {
+	var rows []Row
+	data := `[
+		{"name":"hv3","nb_cfg_timestamp":1700000000300,"chassis":["set",[]]},
+		{"name":"hv1","nb_cfg_timestamp":1700000000100,"chassis":["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b2"]},
+		{"name":"hv10","nb_cfg_timestamp":20,"chassis":["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"]},
+		{"name":"hv2","nb_cfg_timestamp":1700000000200,"chassis":["set",[["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b0"]]]}
+	]`
+	if err := json.Unmarshal([]byte(data), &rows); err != nil {
+		t.Fatalf("FAIL: failed to decode the rows: %v", err)
+	}
+	names := func(rows []Row) string {
+		var out []interface{}
+		for _, row := range rows {
+			out = append(out, row["name"])
+		}
+		return fmt.Sprint(out)
+	}
+	for i, test := range []struct {
+		op   Operation
+		want string
+	}{
+		{op: Operation{OrderBy: "nb_cfg_timestamp", Descending: true, Limit: 2}, want: "[hv3 hv2]"},
+		{op: Operation{OrderBy: "nb_cfg_timestamp"}, want: "[hv10 hv1 hv2 hv3]"},
+		{op: Operation{OrderBy: "name"}, want: "[hv1 hv10 hv2 hv3]"},
+		{op: Operation{OrderBy: "chassis"}, want: "[hv3 hv2 hv10 hv1]"},
+		{op: Operation{Limit: 3}, want: "[hv3 hv1 hv10]"},
+		{op: Operation{Limit: 10}, want: "[hv3 hv1 hv10 hv2]"},
+	} {
+		if got := names(test.op.Sort(rows)); got != test.want {
+			t.Fatalf("FAIL: Test %d: expected %s, got %s", i, test.want, got)
+		}
+		t.Logf("PASS: Test %d: ORDER BY %q desc=%v LIMIT %d: %s", i, test.op.OrderBy, test.op.Descending, test.op.Limit, test.want)
+	}
+}
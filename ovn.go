@@ -15,22 +15,55 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
+	"sync"
 	//"github.com/davecgh/go-spew/spew"
 )
 
 // OvnClient holds connection to OVN databases (Northbound and Southbound).
 // For Open_vSwitch database operations, use OvsClient instead.
+//
+// The remote of a clustered database may be a comma-separated list of its
+// members, e.g. "ssl:10.0.0.1:6642,ssl:10.0.0.2:6642,ssl:10.0.0.3:6642".
+// The client connects to the first available member and fails over to the
+// next one when the connection breaks.
+//
+// An OvnClient is safe for concurrent use by multiple goroutines. Its
+// methods update the exported fields, e.g. the process information, so a
+// goroutine reading the fields while other goroutines call the methods
+// must hold RLock.
 type OvnClient struct {
+	mux      sync.RWMutex
 	Database struct {
 		Northbound OvsDatabase
 		Southbound OvsDatabase
+		// ICNorthbound and ICSouthbound are the databases of OVN
+		// interconnection, used when Interconnect is set.
+		ICNorthbound OvsDatabase
+		ICSouthbound OvsDatabase
 	}
-	Service struct {
+	// Interconnect, when set, makes the client use the databases of OVN
+	// interconnection, i.e. OVN_IC_Northbound and OVN_IC_Southbound, too.
+	Interconnect bool
+	// registered are the databases added with RegisterDatabase.
+	registered registry
+	Service    struct {
 		Northd OvsDaemon
 	}
 	Timeout int
+	// Logger, when set, receives the diagnostic messages of the client
+	// and of its database connections.
+	Logger Logger
+	// Options are applied to all the connections of the client: to the
+	// databases, ahead of their own Options, and to the control sockets
+	// of the daemons, e.g. WithTracer, WithObserver, or WithSocketCheck.
+	Options []ClientOption
+	// PartialResults, when set, makes the functions which gather data with
+	// several queries return the data gathered by those which succeeded,
+	// together with a *MultiError describing the failed or skipped ones.
+	PartialResults bool
 }
 
 // NewOvnClient creates an instance of a client for OVN stack.
@@ -64,6 +97,28 @@ func NewOvnClient() *OvnClient {
 	cli.Database.Southbound.Port.Ssl = 6632
 	cli.Database.Southbound.Port.Raft = 6644
 
+	cli.Database.ICNorthbound.Name = "OVN_IC_Northbound"
+	cli.Database.ICNorthbound.Socket.Remote = "unix:/run/openvswitch/ovn_ic_nb_db.sock"
+	cli.Database.ICNorthbound.Socket.Control = "unix:/run/openvswitch/ovn_ic_nb_db.ctl"
+	cli.Database.ICNorthbound.File.Data.Path = "/var/lib/openvswitch/ovn_ic_nb_db.db"
+	cli.Database.ICNorthbound.File.Log.Path = "/var/log/openvswitch/ovsdb-server-ic-nb.log"
+	cli.Database.ICNorthbound.File.Pid.Path = "/run/openvswitch/ovn_ic_nb_db.pid"
+	cli.Database.ICNorthbound.Process.User = "openvswitch"
+	cli.Database.ICNorthbound.Process.Group = "openvswitch"
+	cli.Database.ICNorthbound.Port.Default = 6645
+	cli.Database.ICNorthbound.Port.Raft = 6647
+
+	cli.Database.ICSouthbound.Name = "OVN_IC_Southbound"
+	cli.Database.ICSouthbound.Socket.Remote = "unix:/run/openvswitch/ovn_ic_sb_db.sock"
+	cli.Database.ICSouthbound.Socket.Control = "unix:/run/openvswitch/ovn_ic_sb_db.ctl"
+	cli.Database.ICSouthbound.File.Data.Path = "/var/lib/openvswitch/ovn_ic_sb_db.db"
+	cli.Database.ICSouthbound.File.Log.Path = "/var/log/openvswitch/ovsdb-server-ic-sb.log"
+	cli.Database.ICSouthbound.File.Pid.Path = "/run/openvswitch/ovn_ic_sb_db.pid"
+	cli.Database.ICSouthbound.Process.User = "openvswitch"
+	cli.Database.ICSouthbound.Process.Group = "openvswitch"
+	cli.Database.ICSouthbound.Port.Default = 6646
+	cli.Database.ICSouthbound.Port.Raft = 6648
+
 	cli.Service.Northd.Process.ID = 0
 	cli.Service.Northd.Process.User = "openvswitch"
 	cli.Service.Northd.Process.Group = "openvswitch"
@@ -76,21 +131,17 @@ func NewOvnClient() *OvnClient {
 
 // Connect initiates connections to OVN databases.
 func (cli *OvnClient) Connect() error {
+	return cli.ConnectContext(context.Background())
+}
+
+// ConnectContext is like Connect, but honors the context.
+func (cli *OvnClient) ConnectContext(ctx context.Context) error {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
 	errMsgs := []string{}
-	if cli.Database.Northbound.Client == nil {
-		nb, err := NewClient(cli.Database.Northbound.Socket.Remote, cli.Timeout)
-		cli.Database.Northbound.Client = &nb
-		if err != nil {
-			cli.Database.Northbound.Client.closed = true
-			errMsgs = append(errMsgs, fmt.Sprintf("failed connecting to %s via %s: %s", cli.Database.Northbound.Name, cli.Database.Northbound.Socket.Remote, err))
-		}
-	}
-	if cli.Database.Southbound.Client == nil {
-		sb, err := NewClient(cli.Database.Southbound.Socket.Remote, cli.Timeout)
-		cli.Database.Southbound.Client = &sb
-		if err != nil {
-			cli.Database.Southbound.Client.closed = true
-			errMsgs = append(errMsgs, fmt.Sprintf("failed connecting to %s via %s: %s", cli.Database.Southbound.Name, cli.Database.Southbound.Socket.Remote, err))
+	for _, db := range append(cli.builtin(), cli.registered.sorted()...) {
+		if err := db.connect(ctx, cli.Timeout, cli.Logger, cli.Options); err != nil {
+			errMsgs = append(errMsgs, err.Error())
 		}
 	}
 	if len(errMsgs) > 0 {
@@ -101,12 +152,84 @@ func (cli *OvnClient) Connect() error {
 
 // Close closes connections to OVN databases.
 func (cli *OvnClient) Close() {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
 	if cli.Database.Southbound.Client != nil {
 		cli.Database.Southbound.Client.Close()
 	}
 	if cli.Database.Northbound.Client != nil {
 		cli.Database.Northbound.Client.Close()
 	}
+	for _, db := range []*OvsDatabase{&cli.Database.ICNorthbound, &cli.Database.ICSouthbound} {
+		if db.Client != nil {
+			db.Client.Close()
+		}
+	}
+	for _, db := range cli.registered {
+		if db.Client != nil {
+			db.Client.Close()
+		}
+	}
+}
+
+// RegisterDatabase adds a database, other than Northbound and Southbound,
+// and the OVN interconnection ones when Interconnect is set, to the client.
+// The next Connect connects to it, and Close closes the connection.
+func (cli *OvnClient) RegisterDatabase(db *OvsDatabase) error {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	return cli.registered.register(db, cli.builtin()...)
+}
+
+// LookupDatabase returns the database named name, either Northbound,
+// Southbound, an OVN interconnection one when Interconnect is set, or one
+// added with RegisterDatabase, or nil.
+func (cli *OvnClient) LookupDatabase(name string) *OvsDatabase {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	return cli.registered.lookup(name, cli.builtin()...)
+}
+
+// DatabaseNames returns the names of the databases of the client, i.e.
+// Northbound, Southbound, the OVN interconnection ones when Interconnect is
+// set, and the ones added with RegisterDatabase.
+func (cli *OvnClient) DatabaseNames() []string {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	return cli.registered.names(cli.builtin()...)
+}
+
+// builtin returns the databases of the client which are not registered.
+func (cli *OvnClient) builtin() []*OvsDatabase {
+	dbs := []*OvsDatabase{&cli.Database.Northbound, &cli.Database.Southbound}
+	if cli.Interconnect {
+		dbs = append(dbs, &cli.Database.ICNorthbound, &cli.Database.ICSouthbound)
+	}
+	return dbs
+}
+
+// controlOptions returns the options of the clients of the control sockets.
+func (cli *OvnClient) controlOptions() []ClientOption {
+	return withLogger(cli.Logger, cli.Options)
+}
+
+// RLock locks the client for reading its fields.
+func (cli *OvnClient) RLock() {
+	cli.mux.RLock()
+}
+
+// RUnlock undoes a single RLock call.
+func (cli *OvnClient) RUnlock() {
+	cli.mux.RUnlock()
+}
+
+// readLockRefs updates the references to the control sockets and locks
+// the client for reading.
+func (cli *OvnClient) readLockRefs() {
+	cli.mux.Lock()
+	cli.updateRefs()
+	cli.mux.Unlock()
+	cli.mux.RLock()
 }
 
 func (cli *OvnClient) updateRefs() {
@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 )
@@ -27,10 +28,17 @@ type OvnACL struct {
 
 // GetACL returns a list of OVN ACLs.
 func (cli *OvnClient) GetACL() ([]*OvnACL, error) {
+	return cli.GetACLContext(context.Background())
+}
+
+// GetACLContext is like GetACL, but honors the context.
+func (cli *OvnClient) GetACLContext(ctx context.Context) ([]*OvnACL, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
 	acls := []*OvnACL{}
 	// First, get basic information about OVN logical switches.
 	query := "SELECT _uuid, external_ids FROM ACL"
-	result, err := cli.Database.Northbound.Client.Transact(cli.Database.Northbound.Name, query)
+	result, err := cli.Database.Northbound.Client.TransactContext(ctx, cli.Database.Northbound.Name, query)
 	if err != nil {
 		return nil, fmt.Errorf("%s: '%s' table error: %s", cli.Database.Northbound.Name, "ACL", err)
 	}
@@ -40,9 +48,15 @@ func (cli *OvnClient) GetACL() ([]*OvnACL, error) {
 	for _, row := range result.Rows {
 		acl := &OvnACL{}
 		if r, dt, err := row.GetColumnValue("_uuid", result.Columns); err != nil {
+			if err := cli.skipRow(result, "_uuid", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "_uuid", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			acl.UUID = r.(string)
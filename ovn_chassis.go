@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	"net"
 )
@@ -36,40 +37,72 @@ type OvnChassis struct {
 
 // GetChassis returns a list of OVN chassis.
 func (cli *OvnClient) GetChassis() ([]*OvnChassis, error) {
+	return cli.GetChassisContext(context.Background())
+}
+
+// GetChassisContext is like GetChassis, but honors the context.
+func (cli *OvnClient) GetChassisContext(ctx context.Context) ([]*OvnChassis, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	c := newCollector(ctx, cli.PartialResults)
 	chassis := []*OvnChassis{}
 	// First, get the names and UUIDs of chassis.
+	var result Result
 	query := "SELECT _uuid, name, encaps FROM Chassis"
-	result, err := cli.Database.Southbound.Client.Transact(cli.Database.Southbound.Name, query)
-	if err != nil {
-		return nil, fmt.Errorf("%s: '%s' table error: %s", cli.Database.Southbound.Name, "Chassis", err)
-	}
-	if len(result.Rows) == 0 {
-		return nil, fmt.Errorf("%s: no chassis found", cli.Database.Southbound.Name)
+	if err := c.run(query, func() (err error) {
+		result, err = cli.Database.Southbound.Client.TransactContext(ctx, cli.Database.Southbound.Name, query)
+		if err != nil {
+			return fmt.Errorf("%s: '%s' table error: %s", cli.Database.Southbound.Name, "Chassis", err)
+		}
+		if len(result.Rows) == 0 {
+			return fmt.Errorf("%s: no chassis found", cli.Database.Southbound.Name)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 	for _, row := range result.Rows {
 		c := &OvnChassis{}
 		c.Ports = []string{}
 		c.Switches = []string{}
 		if r, dt, err := row.GetColumnValue("_uuid", result.Columns); err != nil {
+			if err := cli.skipRow(result, "_uuid", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "_uuid", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			c.UUID = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("name", result.Columns); err != nil {
+			if err := cli.skipRow(result, "name", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "name", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			c.Name = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("encaps", result.Columns); err != nil {
+			if err := cli.skipRow(result, "encaps", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "encaps", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			c.Encaps.UUID = r.(string)
@@ -78,13 +111,19 @@ func (cli *OvnClient) GetChassis() ([]*OvnChassis, error) {
 	}
 
 	// Second, get the IP addresses of the chassis
+	result = Result{}
 	query = "SELECT _uuid, chassis_name, ip, type FROM Encap"
-	result, err = cli.Database.Southbound.Client.Transact(cli.Database.Southbound.Name, query)
-	if err != nil {
-		return nil, fmt.Errorf("%s: '%s' table error: %s", cli.Database.Southbound.Name, "Encap", err)
-	}
-	if len(result.Rows) == 0 {
-		return nil, fmt.Errorf("%s: no chassis found", cli.Database.Southbound.Name)
+	if err := c.run(query, func() (err error) {
+		result, err = cli.Database.Southbound.Client.TransactContext(ctx, cli.Database.Southbound.Name, query)
+		if err != nil {
+			return fmt.Errorf("%s: '%s' table error: %s", cli.Database.Southbound.Name, "Encap", err)
+		}
+		if len(result.Rows) == 0 {
+			return fmt.Errorf("%s: no chassis found", cli.Database.Southbound.Name)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 	for _, row := range result.Rows {
 		var encapUUID string
@@ -92,33 +131,57 @@ func (cli *OvnClient) GetChassis() ([]*OvnChassis, error) {
 		var chassisName string
 		var chassisIPAddress string
 		if r, dt, err := row.GetColumnValue("_uuid", result.Columns); err != nil {
+			if err := cli.skipRow(result, "_uuid", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "_uuid", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			encapUUID = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("type", result.Columns); err != nil {
+			if err := cli.skipRow(result, "type", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "type", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			encapProto = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("chassis_name", result.Columns); err != nil {
+			if err := cli.skipRow(result, "chassis_name", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "chassis_name", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			chassisName = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("ip", result.Columns); err != nil {
+			if err := cli.skipRow(result, "ip", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "ip", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			chassisIPAddress = r.(string)
@@ -136,11 +199,20 @@ func (cli *OvnClient) GetChassis() ([]*OvnChassis, error) {
 		}
 	}
 
+	result = Result{}
 	query = "SELECT chassis, name, nb_cfg, nb_cfg_timestamp FROM Chassis_Private"
-	result, err = cli.Database.Southbound.Client.Transact(cli.Database.Southbound.Name, query)
-	if err != nil {
-		return chassis, nil
-	}
+	c.run(query, func() error {
+		// The table only exists in the newer schemas, the fields are left
+		// unset without it.
+		exists, err := cli.Database.Southbound.Client.HasTableContext(ctx, cli.Database.Southbound.Name, "Chassis_Private")
+		if err != nil || !exists {
+			return nil
+		}
+		if r, err := cli.Database.Southbound.Client.TransactContext(ctx, cli.Database.Southbound.Name, query); err == nil {
+			result = r
+		}
+		return nil
+	})
 
 	// Create maps for chassis nb_cfg and nb_cfg_timestamp
 	chassisNbCfgMap := make(map[string]int64)
@@ -221,7 +293,44 @@ func (cli *OvnClient) GetChassis() ([]*OvnChassis, error) {
 		// If no entry found, NbCfg and NbCfgTimestamp remain 0 (default)
 	}
 
-	return chassis, nil
+	return chassis, c.err()
+}
+
+// ChassisView is the name of the view of AddChassisView.
+const ChassisView = "Chassis_View"
+
+// AddChassisView adds the view of the chassis, by name, to the cache of a
+// southbound database, which must monitor the columns it joins: the
+// chassis, with their first Encap, and their Chassis_Private row, which
+// the older schemas do not have. Its rows hold what GetChassis returns:
+// name, uuid, hostname and encaps of the Chassis, encap_ip and encap_type
+// of the Encap, and nb_cfg and nb_cfg_timestamp of the Chassis_Private.
+func AddChassisView(cache *Cache) error {
+	sources := []ViewSource{
+		{
+			Table:   "Chassis",
+			Key:     "name",
+			Columns: map[string]string{"uuid": "_uuid", "hostname": "hostname", "encaps": "encaps"},
+		},
+		{
+			Table:    "Encap",
+			Key:      "chassis_name",
+			Columns:  map[string]string{"encap_ip": "ip", "encap_type": "type"},
+			Optional: true,
+		},
+	}
+	cache.mux.RLock()
+	private := cache.schema.Table("Chassis_Private") != nil
+	cache.mux.RUnlock()
+	if private {
+		sources = append(sources, ViewSource{
+			Table:    "Chassis_Private",
+			Key:      "name",
+			Columns:  map[string]string{"nb_cfg": "nb_cfg", "nb_cfg_timestamp": "nb_cfg_timestamp"},
+			Optional: true,
+		})
+	}
+	return cache.AddView(ChassisView, "name", sources...)
 }
 
 // MapPortToChassis updates logical switch ports with the entries from the
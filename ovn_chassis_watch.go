@@ -0,0 +1,355 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChassisState is the liveness of a chassis, as a ChassisWatch judges it.
+type ChassisState int
+
+const (
+	// ChassisUp is a chassis which caught up with the configuration of the
+	// southbound database, or is catching up within the staleness
+	// threshold.
+	ChassisUp ChassisState = iota
+	// ChassisStale is a chassis which has lagged behind the configuration
+	// for longer than the staleness threshold.
+	ChassisStale
+	// ChassisDown is a chassis which has lagged behind the configuration
+	// for longer than the down threshold, or whose Chassis_Private row
+	// was deleted.
+	ChassisDown
+)
+
+func (s ChassisState) String() string {
+	switch s {
+	case ChassisUp:
+		return "up"
+	case ChassisStale:
+		return "stale"
+	case ChassisDown:
+		return "down"
+	}
+	return fmt.Sprintf("ChassisState(%d)", int(s))
+}
+
+// ChassisEvent is the change of the liveness of a chassis. A watch reports
+// the state of every chassis first, then the changes.
+type ChassisEvent struct {
+	// Name is the name of the chassis, from Chassis_Private.
+	Name  string
+	State ChassisState
+	// NbCfg is the sequence number of the configuration the chassis
+	// caught up with, and Target that of SB_Global.
+	NbCfg  int64
+	Target int64
+	// Timestamp is when the chassis caught up with NbCfg, i.e. its
+	// nb_cfg_timestamp, if set.
+	Timestamp time.Time
+	// Lag is how long the chassis has been behind the configuration
+	// without catching up with a newer one.
+	Lag time.Duration
+}
+
+// ChassisWatchConfig holds the thresholds of a ChassisWatch.
+type ChassisWatchConfig struct {
+	// StaleAfter is how long a chassis may lag behind the configuration
+	// before it is stale, 30 seconds when unset.
+	StaleAfter time.Duration
+	// DownAfter is how long a chassis may lag behind the configuration
+	// before it is down, three times StaleAfter when unset.
+	DownAfter time.Duration
+	// Interval is how often the lags are checked, one second when unset.
+	Interval time.Duration
+}
+
+// ChassisWatch turns the heartbeats of the chassis of OVN, i.e. the
+// nb_cfg of their Chassis_Private rows catching up with that of SB_Global,
+// into events on the liveness of the chassis.
+//
+// A chassis only lags behind once the configuration changes, so that the
+// watch notices a silent chassis provided nb_cfg is bumped periodically,
+// e.g. by "ovn-nbctl --wait=hv sync".
+type ChassisWatch struct {
+	// Events receives the changes of the liveness of the chassis. It is
+	// closed once the watch is closed, or its cache ends.
+	Events <-chan ChassisEvent
+
+	cache  *Cache
+	events chan ChassisEvent
+	kick   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+
+	mux     sync.Mutex
+	tracker *chassisTracker
+}
+
+// WatchChassis watches the liveness of the chassis of the cache of the
+// southbound database, which must monitor the nb_cfg column of SB_Global,
+// and the name, nb_cfg and nb_cfg_timestamp columns of Chassis_Private.
+func WatchChassis(cache *Cache, config ChassisWatchConfig) (*ChassisWatch, error) {
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = 30 * time.Second
+	}
+	if config.DownAfter <= 0 {
+		config.DownAfter = 3 * config.StaleAfter
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.DownAfter < config.StaleAfter {
+		return nil, fmt.Errorf("chassis watch: down threshold %s is shorter than stale threshold %s", config.DownAfter, config.StaleAfter)
+	}
+	cache.mux.RLock()
+	for table, columns := range map[string][]string{
+		"SB_Global":       {"nb_cfg"},
+		"Chassis_Private": {"name", "nb_cfg", "nb_cfg_timestamp"},
+	} {
+		t, err := cache.table(table)
+		if err == nil && !t.request.monitors(columns) {
+			err = fmt.Errorf("cache: columns %v of table %s are not monitored", columns, table)
+		}
+		if err != nil {
+			cache.mux.RUnlock()
+			return nil, fmt.Errorf("chassis watch: %w", err)
+		}
+	}
+	cache.mux.RUnlock()
+	events := make(chan ChassisEvent, 64)
+	w := &ChassisWatch{
+		Events:  events,
+		cache:   cache,
+		events:  events,
+		kick:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		tracker: newChassisTracker(config),
+	}
+	observe := func(table string, row Row) {
+		w.mux.Lock()
+		if table == "SB_Global" {
+			w.tracker.observeTarget(rowInteger(row, "nb_cfg"), time.Now())
+		} else {
+			w.tracker.observeChassis(rowUUID(row), row, time.Now())
+		}
+		w.mux.Unlock()
+		w.check()
+	}
+	h := EventHandler{
+		OnAdd: observe,
+		OnUpdate: func(table string, old, new Row) {
+			observe(table, new)
+		},
+		OnDelete: func(table string, row Row) {
+			if table == "Chassis_Private" {
+				w.mux.Lock()
+				w.tracker.observeChassis(rowUUID(row), nil, time.Now())
+				w.mux.Unlock()
+				w.check()
+			}
+		},
+	}
+	// The global row comes first, so that the chassis replayed are not
+	// judged against a target of zero.
+	for _, table := range []string{"SB_Global", "Chassis_Private"} {
+		if err := cache.RegisterHandler(table, h); err != nil {
+			return nil, fmt.Errorf("chassis watch: %w", err)
+		}
+	}
+	go w.run(config.Interval)
+	return w, nil
+}
+
+// rowInteger returns the integer of the column of the row of the cache, or
+// zero.
+func rowInteger(row Row, column string) int64 {
+	if v, ok := row[column].(float64); ok {
+		return int64(v)
+	}
+	return 0
+}
+
+// rowUUID returns the UUID of the row of the cache.
+func rowUUID(row Row) UUID {
+	if v, ok := row["_uuid"].([]interface{}); ok && len(v) == 2 {
+		if s, ok := v[1].(string); ok {
+			return UUID(s)
+		}
+	}
+	return ""
+}
+
+// run checks the lags on every tick, and whenever the rows change, and
+// sends the changes of the liveness to Events.
+func (w *ChassisWatch) run(interval time.Duration) {
+	defer close(w.done)
+	defer close(w.events)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.mux.Lock()
+		events := w.tracker.evaluate(time.Now())
+		w.mux.Unlock()
+		for _, e := range events {
+			select {
+			case w.events <- e:
+			case <-w.stop:
+				return
+			case <-w.cache.Done():
+				return
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-w.kick:
+		case <-w.stop:
+			return
+		case <-w.cache.Done():
+			return
+		}
+	}
+}
+
+// check has the watch check the lags without waiting for the next tick.
+func (w *ChassisWatch) check() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops the watch, and closes Events. The handlers it registered on
+// the cache stay registered, to no effect.
+func (w *ChassisWatch) Close() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// chassisTracker holds the heartbeat math of a ChassisWatch.
+type chassisTracker struct {
+	config  ChassisWatchConfig
+	target  int64
+	chassis map[UUID]*chassisLiveness
+}
+
+// chassisLiveness is what a tracker knows of a chassis. behindSince is
+// zero while the chassis is caught up, or when it last caught up with a
+// configuration, while it lags behind a newer one.
+type chassisLiveness struct {
+	name        string
+	nbCfg       int64
+	timestamp   int64
+	behindSince time.Time
+	state       ChassisState
+	reported    bool
+	deleted     bool
+}
+
+func newChassisTracker(config ChassisWatchConfig) *chassisTracker {
+	return &chassisTracker{config: config, chassis: make(map[UUID]*chassisLiveness)}
+}
+
+// observeTarget records the nb_cfg of SB_Global, which the chassis caught
+// up with lag behind from now on.
+func (t *chassisTracker) observeTarget(target int64, now time.Time) {
+	if target == t.target {
+		return
+	}
+	t.target = target
+	for _, c := range t.chassis {
+		switch {
+		case c.nbCfg >= target:
+			c.behindSince = time.Time{}
+		case c.behindSince.IsZero():
+			c.behindSince = now
+		}
+	}
+}
+
+// observeChassis records the Chassis_Private row of the chassis, or its
+// deletion when the row is nil.
+func (t *chassisTracker) observeChassis(uuid UUID, row Row, now time.Time) {
+	c, exists := t.chassis[uuid]
+	if row == nil {
+		if exists {
+			c.deleted = true
+		}
+		return
+	}
+	nbCfg := rowInteger(row, "nb_cfg")
+	if !exists {
+		c = &chassisLiveness{nbCfg: nbCfg}
+		t.chassis[uuid] = c
+		if nbCfg < t.target {
+			c.behindSince = now
+		}
+	}
+	c.name, _ = row["name"].(string)
+	c.timestamp = rowInteger(row, "nb_cfg_timestamp")
+	if nbCfg != c.nbCfg {
+		c.nbCfg = nbCfg
+		if nbCfg >= t.target {
+			c.behindSince = time.Time{}
+		} else {
+			c.behindSince = now
+		}
+	}
+}
+
+// evaluate returns the changes of the liveness of the chassis, by name,
+// and forgets the chassis deleted.
+func (t *chassisTracker) evaluate(now time.Time) []ChassisEvent {
+	var events []ChassisEvent
+	for uuid, c := range t.chassis {
+		e := ChassisEvent{Name: c.name, State: ChassisUp, NbCfg: c.nbCfg, Target: t.target}
+		if c.timestamp > 0 {
+			e.Timestamp = time.UnixMilli(c.timestamp)
+		}
+		if !c.behindSince.IsZero() {
+			e.Lag = now.Sub(c.behindSince)
+		}
+		switch {
+		case c.deleted:
+			e.State = ChassisDown
+			delete(t.chassis, uuid)
+		case e.Lag >= t.config.DownAfter:
+			e.State = ChassisDown
+		case e.Lag >= t.config.StaleAfter:
+			e.State = ChassisStale
+		}
+		if c.deleted && !c.reported {
+			continue
+		}
+		if c.reported && c.state == e.State {
+			continue
+		}
+		c.reported = true
+		c.state = e.State
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Name < events[j].Name
+	})
+	return events
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChassisTracker(t *testing.T) {
+	const hv1, hv2 = UUID("4a8e5d38-4b1c-4a4e-8b7e-0c7b3b3f4f11"), UUID("9b2d0e41-7c3a-4c5e-a1d2-5f6e7a8b9c22")
+	chassis := func(name string, nbCfg int64) Row {
+		return Row{"name": name, "nb_cfg": float64(nbCfg), "nb_cfg_timestamp": float64(1700000000000)}
+	}
+	states := func(events []ChassisEvent) []string {
+		var s []string
+		for _, e := range events {
+			s = append(s, e.Name+" "+e.State.String())
+		}
+		return s
+	}
+	start := time.Unix(1700000000, 0)
+	at := func(d time.Duration) time.Time {
+		return start.Add(d)
+	}
+	tracker := newChassisTracker(ChassisWatchConfig{StaleAfter: 10 * time.Second, DownAfter: 30 * time.Second})
+	tracker.observeTarget(5, at(0))
+	tracker.observeChassis(hv1, chassis("hv1", 5), at(0))
+	tracker.observeChassis(hv2, chassis("hv2", 5), at(0))
+	events := tracker.evaluate(at(0))
+	if got, want := states(events), []string{"hv1 up", "hv2 up"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FAIL: expected the initial states %v, got: %v", want, got)
+	}
+	if !events[0].Timestamp.Equal(time.UnixMilli(1700000000000)) {
+		t.Fatalf("FAIL: unexpected timestamp: %v", events[0].Timestamp)
+	}
+	t.Logf("PASS: initial states reported")
+
+	// The configuration changes, hv1 catches up, hv2 does not.
+	tracker.observeTarget(6, at(time.Minute))
+	tracker.observeChassis(hv1, chassis("hv1", 6), at(time.Minute+time.Second))
+	if events := tracker.evaluate(at(time.Minute + 5*time.Second)); len(events) != 0 {
+		t.Fatalf("FAIL: unexpected events within the threshold: %v", states(events))
+	}
+	events = tracker.evaluate(at(time.Minute + 10*time.Second))
+	if got, want := states(events), []string{"hv2 stale"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FAIL: expected %v, got: %v", want, got)
+	}
+	if events[0].Lag != 10*time.Second || events[0].NbCfg != 5 || events[0].Target != 6 {
+		t.Fatalf("FAIL: unexpected event: %+v", events[0])
+	}
+	if events := tracker.evaluate(at(time.Minute + 20*time.Second)); len(events) != 0 {
+		t.Fatalf("FAIL: unexpected events of an unchanged state: %v", states(events))
+	}
+	if got, want := states(tracker.evaluate(at(time.Minute+30*time.Second))), []string{"hv2 down"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FAIL: expected %v, got: %v", want, got)
+	}
+	t.Logf("PASS: lagging chassis goes stale, then down")
+
+	// Progress restarts the lag, catching up brings the chassis up.
+	tracker.observeTarget(8, at(2*time.Minute))
+	tracker.observeChassis(hv2, chassis("hv2", 7), at(2*time.Minute))
+	if got, want := states(tracker.evaluate(at(2*time.Minute+5*time.Second))), []string{"hv2 up"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FAIL: expected %v, got: %v", want, got)
+	}
+	tracker.observeChassis(hv1, chassis("hv1", 8), at(2*time.Minute+6*time.Second))
+	tracker.observeChassis(hv2, chassis("hv2", 8), at(2*time.Minute+6*time.Second))
+	if events := tracker.evaluate(at(3 * time.Minute)); len(events) != 0 {
+		t.Fatalf("FAIL: unexpected events of chassis caught up: %v", states(events))
+	}
+	t.Logf("PASS: chassis catching up is up")
+
+	tracker.observeChassis(hv1, nil, at(4*time.Minute))
+	if got, want := states(tracker.evaluate(at(4*time.Minute))), []string{"hv1 down"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FAIL: expected %v, got: %v", want, got)
+	}
+	if _, exists := tracker.chassis[hv1]; exists {
+		t.Fatalf("FAIL: expected the deleted chassis to be forgotten")
+	}
+	t.Logf("PASS: deleted chassis is down")
+}
+
+func TestWatchChassisColumns(t *testing.T) {
+	remote, _ := newTestMonitorServer(t, map[string]interface{}{})
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	cache, err := c.NewCache(context.Background(), "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(context.Background())
+	if _, err := WatchChassis(cache, ChassisWatchConfig{}); err == nil {
+		t.Fatalf("FAIL: expected an error for a cache without Chassis_Private")
+	}
+	if _, err := WatchChassis(cache, ChassisWatchConfig{StaleAfter: time.Minute, DownAfter: time.Second}); err == nil {
+		t.Fatalf("FAIL: expected an error for a down threshold shorter than the stale one")
+	}
+	t.Logf("PASS: watch rejected")
+}
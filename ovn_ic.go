@@ -0,0 +1,186 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"net"
+	"sort"
+)
+
+// OvnAvailabilityZone holds the information about an availability zone of
+// an OVN interconnection deployment, i.e. an OVN deployment of its own,
+// from the Availability_Zone table of the OVN_IC_Southbound database.
+type OvnAvailabilityZone struct {
+	UUID string `json:"uuid" yaml:"uuid" ovsdb:"_uuid"`
+	Name string `json:"name" yaml:"name" ovsdb:"name"`
+	// Gateways are the names of the gateways of the zone.
+	Gateways []string `json:"gateways" yaml:"gateways"`
+}
+
+// OvnICGateway holds the information about a gateway chassis of an
+// availability zone, from the Gateway table of the OVN_IC_Southbound
+// database.
+type OvnICGateway struct {
+	UUID                 string            `json:"uuid" yaml:"uuid" ovsdb:"_uuid"`
+	Name                 string            `json:"name" yaml:"name" ovsdb:"name"`
+	Hostname             string            `json:"hostname" yaml:"hostname" ovsdb:"hostname"`
+	AvailabilityZone     string            `json:"availability_zone" yaml:"availability_zone" ovsdb:"availability_zone"`
+	AvailabilityZoneName string            `json:"availability_zone_name" yaml:"availability_zone_name"`
+	EncapUUIDs           []string          `json:"-" yaml:"-" ovsdb:"encaps"`
+	Encaps               []OvnICEncap      `json:"encaps" yaml:"encaps"`
+	ExternalIDs          map[string]string `json:"external_ids" yaml:"external_ids" ovsdb:"external_ids"`
+}
+
+// OvnICEncap is a tunnel encapsulation of a gateway, e.g. geneve.
+type OvnICEncap struct {
+	Type      string `json:"type" yaml:"type"`
+	IPAddress net.IP `json:"ip" yaml:"ip"`
+}
+
+// OvnTransitSwitch holds the information about a logical switch connecting
+// the availability zones, from the Transit_Switch table of the
+// OVN_IC_Northbound database.
+type OvnTransitSwitch struct {
+	UUID        string            `json:"uuid" yaml:"uuid" ovsdb:"_uuid"`
+	Name        string            `json:"name" yaml:"name" ovsdb:"name"`
+	OtherConfig map[string]string `json:"other_config" yaml:"other_config" ovsdb:"other_config"`
+	ExternalIDs map[string]string `json:"external_ids" yaml:"external_ids" ovsdb:"external_ids"`
+	// TunnelKey is the tunnel key allocated to the switch, from the
+	// Datapath_Binding table of the OVN_IC_Southbound database, or zero.
+	TunnelKey int64 `json:"tunnel_key" yaml:"tunnel_key"`
+}
+
+// icEncap is a row of the Encap table of the OVN_IC_Southbound database.
+type icEncap struct {
+	UUID string `ovsdb:"_uuid"`
+	Type string `ovsdb:"type"`
+	IP   string `ovsdb:"ip"`
+}
+
+// icDatapathBinding is a row of the Datapath_Binding table of the
+// OVN_IC_Southbound database.
+type icDatapathBinding struct {
+	TransitSwitch string `ovsdb:"transit_switch"`
+	TunnelKey     int64  `ovsdb:"tunnel_key"`
+}
+
+// GetAvailabilityZones returns the availability zones of the OVN
+// interconnection deployment, sorted by name. It requires Interconnect.
+func (cli *OvnClient) GetAvailabilityZones() ([]*OvnAvailabilityZone, error) {
+	return cli.GetAvailabilityZonesContext(context.Background())
+}
+
+// GetAvailabilityZonesContext is like GetAvailabilityZones, but honors the
+// context.
+func (cli *OvnClient) GetAvailabilityZonesContext(ctx context.Context) ([]*OvnAvailabilityZone, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	zones := []*OvnAvailabilityZone{}
+	gateways := []*OvnICGateway{}
+	if err := cli.Database.ICSouthbound.selectRows(ctx, []string{"Availability_Zone", "Gateway"}, &zones, &gateways); err != nil {
+		return nil, err
+	}
+	sort.Slice(gateways, func(i, j int) bool { return gateways[i].Name < gateways[j].Name })
+	for _, zone := range zones {
+		zone.Gateways = []string{}
+		for _, gw := range gateways {
+			if gw.AvailabilityZone == zone.UUID {
+				zone.Gateways = append(zone.Gateways, gw.Name)
+			}
+		}
+	}
+	sort.Slice(zones, func(i, j int) bool { return zones[i].Name < zones[j].Name })
+	return zones, nil
+}
+
+// GetICGateways returns the gateways of the availability zones, sorted by
+// zone name and gateway name. It requires Interconnect.
+func (cli *OvnClient) GetICGateways() ([]*OvnICGateway, error) {
+	return cli.GetICGatewaysContext(context.Background())
+}
+
+// GetICGatewaysContext is like GetICGateways, but honors the context.
+func (cli *OvnClient) GetICGatewaysContext(ctx context.Context) ([]*OvnICGateway, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	gateways := []*OvnICGateway{}
+	zones := []*OvnAvailabilityZone{}
+	encaps := []icEncap{}
+	tables := []string{"Gateway", "Availability_Zone", "Encap"}
+	if err := cli.Database.ICSouthbound.selectRows(ctx, tables, &gateways, &zones, &encaps); err != nil {
+		return nil, err
+	}
+	zoneNames := make(map[string]string, len(zones))
+	for _, zone := range zones {
+		zoneNames[zone.UUID] = zone.Name
+	}
+	byUUID := make(map[string]icEncap, len(encaps))
+	for _, encap := range encaps {
+		byUUID[encap.UUID] = encap
+	}
+	for _, gw := range gateways {
+		gw.AvailabilityZoneName = zoneNames[gw.AvailabilityZone]
+		gw.Encaps = []OvnICEncap{}
+		for _, id := range gw.EncapUUIDs {
+			if encap, exists := byUUID[id]; exists {
+				gw.Encaps = append(gw.Encaps, OvnICEncap{Type: encap.Type, IPAddress: net.ParseIP(encap.IP)})
+			}
+		}
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		if gateways[i].AvailabilityZoneName != gateways[j].AvailabilityZoneName {
+			return gateways[i].AvailabilityZoneName < gateways[j].AvailabilityZoneName
+		}
+		return gateways[i].Name < gateways[j].Name
+	})
+	return gateways, nil
+}
+
+// GetTransitSwitches returns the transit switches of the OVN
+// interconnection deployment, sorted by name, with the tunnel keys
+// allocated to them. It requires Interconnect.
+func (cli *OvnClient) GetTransitSwitches() ([]*OvnTransitSwitch, error) {
+	return cli.GetTransitSwitchesContext(context.Background())
+}
+
+// GetTransitSwitchesContext is like GetTransitSwitches, but honors the
+// context.
+func (cli *OvnClient) GetTransitSwitchesContext(ctx context.Context) ([]*OvnTransitSwitch, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	c := newCollector(ctx, cli.PartialResults)
+	switches := []*OvnTransitSwitch{}
+	if err := cli.Database.ICNorthbound.selectRows(ctx, []string{"Transit_Switch"}, &switches); err != nil {
+		return nil, err
+	}
+	sort.Slice(switches, func(i, j int) bool { return switches[i].Name < switches[j].Name })
+
+	// The tunnel keys are allocated by ovn-ic in the southbound database.
+	bindings := []icDatapathBinding{}
+	if err := c.run("Datapath_Binding", func() error {
+		return cli.Database.ICSouthbound.selectRows(ctx, []string{"Datapath_Binding"}, &bindings)
+	}); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]int64, len(bindings))
+	for _, binding := range bindings {
+		keys[binding.TransitSwitch] = binding.TunnelKey
+	}
+	for _, ts := range switches {
+		ts.TunnelKey = keys[ts.Name]
+	}
+	return switches, c.err()
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestOvnClientInterconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	ref := func(id string) interface{} { return []interface{}{"uuid", id} }
+	tables := map[string][]interface{}{
+		"Transit_Switch": {
+			map[string]interface{}{"_uuid": ref("0f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f1"), "name": "ts1",
+				"other_config": []interface{}{"map", []interface{}{}}, "external_ids": []interface{}{"map", []interface{}{}}},
+		},
+		"Datapath_Binding": {
+			map[string]interface{}{"transit_switch": "ts1", "tunnel_key": float64(16711681)},
+		},
+		"Availability_Zone": {
+			map[string]interface{}{"_uuid": ref("1a2b3c4d-5e6f-4a8b-9cad-becf0a1b2c3d"), "name": "az2"},
+			map[string]interface{}{"_uuid": ref("2b3c4d5e-6f7a-4b9c-adbe-cfd01b2c3d4e"), "name": "az1"},
+		},
+		"Gateway": {
+			map[string]interface{}{"_uuid": ref("3c4d5e6f-7a8b-4cad-becf-d0e12c3d4e5f"), "name": "gw-b", "hostname": "node-b",
+				"availability_zone": ref("1a2b3c4d-5e6f-4a8b-9cad-becf0a1b2c3d"),
+				"encaps":            []interface{}{"set", []interface{}{ref("4d5e6f7a-8b9c-4dbe-8fd0-e1f23d4e5f60")}},
+				"external_ids":      []interface{}{"map", []interface{}{}}},
+			map[string]interface{}{"_uuid": ref("5e6f7a8b-9cad-4ecf-9d0e-f1a24e5f6071"), "name": "gw-a", "hostname": "node-a",
+				"availability_zone": ref("2b3c4d5e-6f7a-4b9c-adbe-cfd01b2c3d4e"),
+				"encaps":            []interface{}{"set", []interface{}{}},
+				"external_ids":      []interface{}{"map", []interface{}{}}},
+		},
+		"Encap": {
+			map[string]interface{}{"_uuid": ref("4d5e6f7a-8b9c-4dbe-8fd0-e1f23d4e5f60"), "type": "geneve", "ip": "192.0.2.10"},
+		},
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		results := []interface{}{}
+		for _, arg := range args[1:] {
+			var op struct {
+				Table string `json:"table"`
+			}
+			json.Unmarshal(arg, &op)
+			results = append(results, map[string]interface{}{"rows": tables[op.Table]})
+		}
+		return results
+	})
+	remote := "tcp:" + l.Addr().String()
+
+	client := NewOvnClient()
+	client.Database.Northbound.Socket.Remote = remote
+	client.Database.Southbound.Socket.Remote = remote
+	client.Database.ICNorthbound.Socket.Remote = remote
+	client.Database.ICSouthbound.Socket.Remote = remote
+	if _, err := client.GetTransitSwitches(); err == nil {
+		t.Fatalf("FAIL: expected the interconnection databases to be unavailable")
+	}
+	client.Interconnect = true
+	if err := client.Connect(); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	defer client.Close()
+	if db := client.LookupDatabase("OVN_IC_Southbound"); db != &client.Database.ICSouthbound {
+		t.Fatalf("FAIL: unexpected lookup of OVN_IC_Southbound: %v", db)
+	}
+
+	switches, err := client.GetTransitSwitches()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(switches) != 1 || switches[0].Name != "ts1" || switches[0].TunnelKey != 16711681 {
+		t.Fatalf("FAIL: unexpected transit switches: %+v", switches)
+	}
+	t.Logf("PASS: transit switch: %+v", switches[0])
+
+	zones, err := client.GetAvailabilityZones()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(zones) != 2 || zones[0].Name != "az1" || !reflect.DeepEqual(zones[0].Gateways, []string{"gw-a"}) || !reflect.DeepEqual(zones[1].Gateways, []string{"gw-b"}) {
+		t.Fatalf("FAIL: unexpected availability zones: %+v", zones)
+	}
+	t.Logf("PASS: availability zones: %+v, %+v", zones[0], zones[1])
+
+	gateways, err := client.GetICGateways()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(gateways) != 2 || gateways[0].Name != "gw-a" || gateways[1].AvailabilityZoneName != "az2" {
+		t.Fatalf("FAIL: unexpected gateways: %+v", gateways)
+	}
+	if encaps := gateways[1].Encaps; len(encaps) != 1 || encaps[0].Type != "geneve" || encaps[0].IPAddress.String() != "192.0.2.10" {
+		t.Fatalf("FAIL: unexpected encaps of gateway gw-b: %+v", encaps)
+	}
+	t.Logf("PASS: gateways: %+v, %+v", gateways[0], gateways[1])
+}
@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 )
@@ -31,10 +32,17 @@ type OvnLogicalSwitch struct {
 
 // GetLogicalSwitches returns a list of OVN logical switches.
 func (cli *OvnClient) GetLogicalSwitches() ([]*OvnLogicalSwitch, error) {
+	return cli.GetLogicalSwitchesContext(context.Background())
+}
+
+// GetLogicalSwitchesContext is like GetLogicalSwitches, but honors the context.
+func (cli *OvnClient) GetLogicalSwitchesContext(ctx context.Context) ([]*OvnLogicalSwitch, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
 	switches := []*OvnLogicalSwitch{}
 	// First, get basic information about OVN logical switches.
 	query := "SELECT _uuid, external_ids, name, ports FROM Logical_Switch"
-	result, err := cli.Database.Northbound.Client.Transact(cli.Database.Northbound.Name, query)
+	result, err := cli.Database.Northbound.Client.TransactContext(ctx, cli.Database.Northbound.Name, query)
 	if err != nil {
 		return nil, fmt.Errorf("%s: '%s' table error: %s", cli.Database.Northbound.Name, "Logical_Switch", err)
 	}
@@ -44,22 +52,37 @@ func (cli *OvnClient) GetLogicalSwitches() ([]*OvnLogicalSwitch, error) {
 	for _, row := range result.Rows {
 		sw := &OvnLogicalSwitch{}
 		if r, dt, err := row.GetColumnValue("_uuid", result.Columns); err != nil {
+			if err := cli.skipRow(result, "_uuid", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "_uuid", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			sw.UUID = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("name", result.Columns); err != nil {
+			if err := cli.skipRow(result, "name", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "name", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			sw.Name = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("ports", result.Columns); err != nil {
+			if err := cli.skipRow(result, "ports", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			switch dt {
@@ -83,7 +106,7 @@ func (cli *OvnClient) GetLogicalSwitches() ([]*OvnLogicalSwitch, error) {
 
 	// Next, obtain a tunnel key for the datapath associated with the switch.
 	query = "SELECT _uuid, external_ids, tunnel_key FROM Datapath_Binding"
-	result, err = cli.Database.Southbound.Client.Transact(cli.Database.Southbound.Name, query)
+	result, err = cli.Database.Southbound.Client.TransactContext(ctx, cli.Database.Southbound.Name, query)
 	if err != nil {
 		return nil, fmt.Errorf("%s: '%s' table error: %s", cli.Database.Southbound.Name, "Datapath_Binding", err)
 	}
@@ -95,25 +118,43 @@ func (cli *OvnClient) GetLogicalSwitches() ([]*OvnLogicalSwitch, error) {
 		var bindExternalIDs map[string]string
 		var bindTunnelKey uint64
 		if r, dt, err := row.GetColumnValue("_uuid", result.Columns); err != nil {
+			if err := cli.skipRow(result, "_uuid", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "_uuid", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			bindUUID = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("tunnel_key", result.Columns); err != nil {
+			if err := cli.skipRow(result, "tunnel_key", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "integer" {
+				if err := cli.skipRow(result, "tunnel_key", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			bindTunnelKey = uint64(r.(int64))
 		}
 		if r, dt, err := row.GetColumnValue("external_ids", result.Columns); err != nil {
+			if err := cli.skipRow(result, "external_ids", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "map[string]string" {
+				if err := cli.skipRow(result, "external_ids", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			bindExternalIDs = r.(map[string]string)
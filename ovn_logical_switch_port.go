@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 	"net"
@@ -46,6 +47,15 @@ type OvnLogicalSwitchPort struct {
 	DatapathUUID      string
 	LogicalSwitchUUID string
 	LogicalSwitchName string
+	// set holds the optional columns which hold a value, see IsSet.
+	set map[string]bool
+}
+
+// IsSet reports whether the optional column of the logical switch port,
+// e.g. up, holds a value. It tells a port whose state has not been
+// reported yet from one which is down.
+func (port *OvnLogicalSwitchPort) IsSet(column string) bool {
+	return port.set[column]
 }
 
 func parseLogicalPortAddress(s string) OvnLogicalSwitchPortAddress {
@@ -93,38 +103,68 @@ func parseLogicalPortAddress(s string) OvnLogicalSwitchPortAddress {
 
 // GetLogicalSwitchPorts returns a list of OVN logical switch ports.
 func (cli *OvnClient) GetLogicalSwitchPorts() ([]*OvnLogicalSwitchPort, error) {
+	return cli.GetLogicalSwitchPortsContext(context.Background())
+}
+
+// GetLogicalSwitchPortsContext is like GetLogicalSwitchPorts, but honors the context.
+func (cli *OvnClient) GetLogicalSwitchPortsContext(ctx context.Context) ([]*OvnLogicalSwitchPort, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	c := newCollector(ctx, cli.PartialResults)
 	// First, fetch logical switch ports.
 	ports := []*OvnLogicalSwitchPort{}
+	var result Result
 	query := "SELECT _uuid, addresses, external_ids, name, up FROM Logical_Switch_Port"
-	result, err := cli.Database.Northbound.Client.Transact(cli.Database.Northbound.Name, query)
-	if err != nil {
-		return nil, fmt.Errorf("%s: '%s' table error: %s", cli.Database.Northbound.Name, "Logical_Switch_Port", err)
+	// The up column only exists in the newer schemas, the field is left
+	// unset without it.
+	if exists, err := cli.Database.Northbound.Client.HasColumnContext(ctx, cli.Database.Northbound.Name, "Logical_Switch_Port", "up"); err == nil && !exists {
+		query = "SELECT _uuid, addresses, external_ids, name FROM Logical_Switch_Port"
 	}
-	if len(result.Rows) == 0 {
-		return nil, fmt.Errorf("%s: no logical switch port found", cli.Database.Northbound.Name)
+	if err := c.run(query, func() (err error) {
+		result, err = cli.Database.Northbound.Client.TransactContext(ctx, cli.Database.Northbound.Name, query)
+		if err != nil {
+			return fmt.Errorf("%s: '%s' table error: %s", cli.Database.Northbound.Name, "Logical_Switch_Port", err)
+		}
+		if len(result.Rows) == 0 {
+			return fmt.Errorf("%s: no logical switch port found", cli.Database.Northbound.Name)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 	for _, row := range result.Rows {
-		port := OvnLogicalSwitchPort{}
+		port := OvnLogicalSwitchPort{set: map[string]bool{}}
 		if r, dt, err := row.GetColumnValue("_uuid", result.Columns); err != nil {
+			if err := cli.skipRow(result, "_uuid", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "_uuid", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			port.UUID = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("name", result.Columns); err != nil {
+			if err := cli.skipRow(result, "name", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "name", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			port.Name = r.(string)
 		}
-		if r, dt, err := row.GetColumnValue("up", result.Columns); err == nil {
-			if dt == "bool" {
-				port.Up = r.(bool)
-			}
+		if v, err := row.GetOptionalBool("up"); err == nil && v.Present {
+			port.Up = v.Value
+			port.set["up"] = true
 		}
 		if r, dt, err := row.GetColumnValue("external_ids", result.Columns); err == nil {
 			if dt == "map[string]string" {
@@ -147,13 +187,19 @@ func (cli *OvnClient) GetLogicalSwitchPorts() ([]*OvnLogicalSwitchPort, error) {
 	}
 
 	// Next, gather tunnel ids and other details about the logical ports.
+	result = Result{}
 	query = "SELECT _uuid, chassis, datapath, logical_port, tunnel_key FROM Port_Binding"
-	result, err = cli.Database.Southbound.Client.Transact(cli.Database.Southbound.Name, query)
-	if err != nil {
-		return nil, fmt.Errorf("%s: '%s' table error: %s", cli.Database.Southbound.Name, "Port_Binding", err)
-	}
-	if len(result.Rows) == 0 {
-		return nil, fmt.Errorf("%s: no port binding found", cli.Database.Southbound.Name)
+	if err := c.run(query, func() (err error) {
+		result, err = cli.Database.Southbound.Client.TransactContext(ctx, cli.Database.Southbound.Name, query)
+		if err != nil {
+			return fmt.Errorf("%s: '%s' table error: %s", cli.Database.Southbound.Name, "Port_Binding", err)
+		}
+		if len(result.Rows) == 0 {
+			return fmt.Errorf("%s: no port binding found", cli.Database.Southbound.Name)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 	for _, row := range result.Rows {
 		var portBindingUUID string
@@ -162,41 +208,66 @@ func (cli *OvnClient) GetLogicalSwitchPorts() ([]*OvnLogicalSwitchPort, error) {
 		var portBindingLogicalPortName string
 		var portBindingTunnelKey uint64
 		if r, dt, err := row.GetColumnValue("_uuid", result.Columns); err != nil {
+			if err := cli.skipRow(result, "_uuid", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "_uuid", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			portBindingUUID = r.(string)
 		}
-		if r, dt, err := row.GetColumnValue("chassis", result.Columns); err != nil {
+		// The chassis is empty until the port is bound.
+		if v, err := row.GetOptionalString("chassis"); err != nil {
+			if err := cli.skipRow(result, "chassis", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
-			if dt != "string" {
-				continue
-			}
-			portBindingChassisUUID = r.(string)
+			portBindingChassisUUID = v.Value
 		}
 		if r, dt, err := row.GetColumnValue("datapath", result.Columns); err != nil {
+			if err := cli.skipRow(result, "datapath", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "datapath", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			portBindingDatapathUUID = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("logical_port", result.Columns); err != nil {
+			if err := cli.skipRow(result, "logical_port", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "logical_port", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			portBindingLogicalPortName = r.(string)
 		}
 		if r, dt, err := row.GetColumnValue("tunnel_key", result.Columns); err != nil {
+			if err := cli.skipRow(result, "tunnel_key", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "integer" {
+				if err := cli.skipRow(result, "tunnel_key", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			portBindingTunnelKey = uint64(r.(int64))
@@ -211,5 +282,5 @@ func (cli *OvnClient) GetLogicalSwitchPorts() ([]*OvnLogicalSwitchPort, error) {
 			}
 		}
 	}
-	return ports, nil
+	return ports, c.err()
 }
@@ -26,6 +26,8 @@ import (
 // IsDefaultPortUp returns the TCP port used for database connection.
 // If the value if greater than 0, then the port is in LISTEN state.
 func (cli *OvnClient) IsDefaultPortUp(db string) (int, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
 	var port int
 	var pid int
 	switch db {
@@ -47,6 +49,8 @@ func (cli *OvnClient) IsDefaultPortUp(db string) (int, error) {
 // IsDefaultPortUp returns the TCP port used for database connection.
 // If the value if greater than 0, then the port is in LISTEN state.
 func (cli *OvsClient) IsDefaultPortUp(db string) (int, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
 	var port int
 	var pid int
 	switch db {
@@ -65,6 +69,8 @@ func (cli *OvsClient) IsDefaultPortUp(db string) (int, error) {
 // IsSslPortUp returns the TCP port used for secure database connection.
 // If the value if greater than 0, then the port is in LISTEN state.
 func (cli *OvnClient) IsSslPortUp(db string) (int, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
 	var port int
 	var pid int
 	switch db {
@@ -86,6 +92,8 @@ func (cli *OvnClient) IsSslPortUp(db string) (int, error) {
 // IsSslPortUp returns the TCP port used for secure database connection.
 // If the value if greater than 0, then the port is in LISTEN state.
 func (cli *OvsClient) IsSslPortUp(db string) (int, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
 	var port int
 	var pid int
 	switch db {
@@ -104,6 +112,8 @@ func (cli *OvsClient) IsSslPortUp(db string) (int, error) {
 // IsRaftPortUp returns the TCP port used for clustering (raft).
 // If the value if greater than 0, then the port is in LISTEN state.
 func (cli *OvnClient) IsRaftPortUp(db string) (int, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
 	var port int
 	var pid int
 	switch db {
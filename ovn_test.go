@@ -15,6 +15,9 @@
 package ovsdb
 
 import (
+	"encoding/json"
+	"net"
+	"reflect"
 	"testing"
 )
 
@@ -31,3 +34,55 @@ func TestOvnClientUpdateRefs(t *testing.T) {
 		t.Errorf("UpdateRefs fail. Expected: %s Ctrl: %s", expectedNorthdCtrl, client.Service.Northd.Socket.Control)
 	}
 }
+
+func TestOvnClientRegisterDatabase(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method == "list_dbs" {
+			return []string{"OVN_Northbound", "OVN_Southbound", "OVN_IC_Northbound"}
+		}
+		return nil
+	})
+	remote := "tcp:" + l.Addr().String()
+
+	client := NewOvnClient()
+	client.Database.Northbound.Socket.Remote = remote
+	client.Database.Southbound.Socket.Remote = remote
+	ic := NewOvsDatabase("OVN_IC_Northbound", remote)
+	if err := client.RegisterDatabase(ic); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	for _, db := range []*OvsDatabase{ic, NewOvsDatabase("OVN_Northbound", remote), NewOvsDatabase("", remote)} {
+		if err := client.RegisterDatabase(db); err == nil {
+			t.Fatalf("FAIL: expected registering database '%s' to fail", db.Name)
+		}
+	}
+	if names := client.DatabaseNames(); !reflect.DeepEqual(names, []string{"OVN_Northbound", "OVN_Southbound", "OVN_IC_Northbound"}) {
+		t.Fatalf("FAIL: unexpected database names: %v", names)
+	}
+	if db := client.LookupDatabase("OVN_Southbound"); db != &client.Database.Southbound {
+		t.Fatalf("FAIL: unexpected lookup of OVN_Southbound: %v", db)
+	}
+	if db := client.LookupDatabase("OVN_IC_Southbound"); db != nil {
+		t.Fatalf("FAIL: unexpected lookup of OVN_IC_Southbound: %v", db)
+	}
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	defer client.Close()
+	if ic.Client == nil {
+		t.Fatalf("FAIL: expected OVN_IC_Northbound to be connected")
+	}
+	if err := ic.Client.DatabaseExists("OVN_IC_Northbound"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	dbs, err := ic.Client.ListDatabases()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	t.Logf("PASS: connected to registered database, server databases: %v", dbs)
+}
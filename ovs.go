@@ -15,27 +15,50 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	//"github.com/davecgh/go-spew/spew"
 )
 
 // OvsClient holds connection to OVS databases.
+//
+// An OvsClient is safe for concurrent use by multiple goroutines. Its
+// methods update the exported fields, e.g. System, so a goroutine reading
+// the fields while other goroutines call the methods must hold RLock.
 type OvsClient struct {
+	mux      sync.RWMutex
 	Database struct {
 		Vswitch OvsDatabase
 	}
-	Service struct {
+	// registered are the databases added with RegisterDatabase.
+	registered registry
+	Service    struct {
 		OvnController OvsDaemon
 		Vswitchd      OvsDaemon
 	}
 	Timeout int
-	System  struct {
+	// Logger, when set, receives the diagnostic messages of the client
+	// and of its database connections.
+	Logger Logger
+	// Options are applied to all the connections of the client: to the
+	// databases, ahead of their own Options, and to the control sockets
+	// of the daemons, e.g. WithTracer, WithObserver, or WithSocketCheck.
+	Options []ClientOption
+	// MaxSystemIDLength, when set, is the maximum length of the system-id,
+	// DefaultMaxSystemIDLength by default, see ValidateSystemID.
+	MaxSystemIDLength int
+	System            struct {
 		ID       string
 		RunDir   string
 		Hostname string
 		Type     string
 		Version  string
 	}
+	// PartialResults, when set, makes the functions which gather data with
+	// several queries return the data gathered by those which succeeded,
+	// together with a *MultiError describing the failed or skipped ones.
+	PartialResults bool
 }
 
 // NewOvsClient creates an instance of a client for OVS stack.
@@ -83,22 +106,85 @@ func NewOvsClient() *OvsClient {
 
 // Connect initiates connections to OVS database.
 func (cli *OvsClient) Connect() error {
-	if cli.Database.Vswitch.Client == nil {
-		ovs, err := NewClient(cli.Database.Vswitch.Socket.Remote, cli.Timeout)
-		cli.Database.Vswitch.Client = &ovs
-		if err != nil {
-			cli.Database.Vswitch.Client.closed = true
-			return fmt.Errorf("failed connecting to %s via %s: %s", cli.Database.Vswitch.Name, cli.Database.Vswitch.Socket.Remote, err)
+	return cli.ConnectContext(context.Background())
+}
+
+// ConnectContext is like Connect, but honors the context.
+func (cli *OvsClient) ConnectContext(ctx context.Context) error {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	errMsgs := []string{}
+	for _, db := range append([]*OvsDatabase{&cli.Database.Vswitch}, cli.registered.sorted()...) {
+		if err := db.connect(ctx, cli.Timeout, cli.Logger, cli.Options); err != nil {
+			errMsgs = append(errMsgs, err.Error())
 		}
 	}
+	if len(errMsgs) > 0 {
+		return fmt.Errorf("%s", errMsgs)
+	}
 	return nil
 }
 
 // Close closes connections to OVS database.
 func (cli *OvsClient) Close() {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
 	if cli.Database.Vswitch.Client != nil {
 		cli.Database.Vswitch.Client.Close()
 	}
+	for _, db := range cli.registered {
+		if db.Client != nil {
+			db.Client.Close()
+		}
+	}
+}
+
+// RegisterDatabase adds a database, other than Vswitch, to the client. The
+// next Connect connects to it, and Close closes the connection.
+func (cli *OvsClient) RegisterDatabase(db *OvsDatabase) error {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	return cli.registered.register(db, &cli.Database.Vswitch)
+}
+
+// LookupDatabase returns the database named name, either Vswitch or one
+// added with RegisterDatabase, or nil.
+func (cli *OvsClient) LookupDatabase(name string) *OvsDatabase {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	return cli.registered.lookup(name, &cli.Database.Vswitch)
+}
+
+// DatabaseNames returns the names of the databases of the client, i.e.
+// Vswitch and the ones added with RegisterDatabase.
+func (cli *OvsClient) DatabaseNames() []string {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	return cli.registered.names(&cli.Database.Vswitch)
+}
+
+// controlOptions returns the options of the clients of the control sockets.
+func (cli *OvsClient) controlOptions() []ClientOption {
+	return withLogger(cli.Logger, cli.Options)
+}
+
+// RLock locks the client for reading its fields.
+func (cli *OvsClient) RLock() {
+	cli.mux.RLock()
+}
+
+// RUnlock undoes a single RLock call.
+func (cli *OvsClient) RUnlock() {
+	cli.mux.RUnlock()
+}
+
+// readLockRefs updates the references to the control sockets and locks
+// the client for reading.
+func (cli *OvsClient) readLockRefs() {
+	cli.mux.Lock()
+	cli.updateRefs()
+	cli.mux.Unlock()
+	cli.mux.RLock()
 }
 
 func (cli *OvsClient) updateRefs() {
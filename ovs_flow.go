@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 	"strconv"
@@ -84,16 +85,22 @@ func NewOvsFlowFromString(line string) (*OvsFlow, error) {
 
 // GetOvsFlows returns a list of datapath flows of an OVS instance.
 func (cli *OvsClient) GetOvsFlows() ([]*OvsFlow, error) {
-	cli.updateRefs()
+	return cli.GetOvsFlowsContext(context.Background())
+}
+
+// GetOvsFlowsContext is like GetOvsFlows, but honors the context.
+func (cli *OvsClient) GetOvsFlowsContext(ctx context.Context) ([]*OvsFlow, error) {
+	cli.readLockRefs()
+	defer cli.mux.RUnlock()
 	db := "vswitchd-service"
 	cmd := "dpctl/dump-flows"
 	flows := []*OvsFlow{}
-	app, err := NewClient(cli.Service.Vswitchd.Socket.Control, cli.Timeout)
+	app, err := NewClientContext(ctx, cli.Service.Vswitchd.Socket.Control, cli.Timeout, cli.controlOptions()...)
 	if err != nil {
 		app.Close()
 		return flows, fmt.Errorf("failed '%s' from %s: %s", cmd, db, err)
 	}
-	r, err := app.query(cmd, nil)
+	r, err := app.queryContext(ctx, cmd, nil)
 	if err != nil {
 		app.Close()
 		return flows, fmt.Errorf("the '%s' command failed for %s: %s", cmd, db, err)
@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 )
@@ -62,13 +63,29 @@ type OvsInterface struct {
 	Statistics           map[string]int
 	Status               map[string]string
 	Type                 string
+	// set holds the optional columns which hold a value, see IsSet.
+	set map[string]bool
+}
+
+// IsSet reports whether the optional column of the interface, e.g. ofport
+// or mtu, holds a value. It tells an empty column, e.g. of an interface
+// which has not been created yet, from one holding zero.
+func (intf *OvsInterface) IsSet(column string) bool {
+	return intf.set[column]
 }
 
 // GetDbInterfaces returns a list of interfaces from the Interface table of OVS database.
 func (cli *OvsClient) GetDbInterfaces() ([]*OvsInterface, error) {
+	return cli.GetDbInterfacesContext(context.Background())
+}
+
+// GetDbInterfacesContext is like GetDbInterfaces, but honors the context.
+func (cli *OvsClient) GetDbInterfacesContext(ctx context.Context) ([]*OvsInterface, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
 	intfs := []*OvsInterface{}
 	query := "SELECT * FROM Interface"
-	result, err := cli.Database.Vswitch.Client.Transact(cli.Database.Vswitch.Name, query)
+	result, err := cli.Database.Vswitch.Client.TransactContext(ctx, cli.Database.Vswitch.Name, query)
 	if err != nil {
 		return intfs, fmt.Errorf("The '%s' query failed: %s", query, err)
 	}
@@ -76,11 +93,17 @@ func (cli *OvsClient) GetDbInterfaces() ([]*OvsInterface, error) {
 		return intfs, fmt.Errorf("The '%s' query did not return any rows", query)
 	}
 	for _, row := range result.Rows {
-		intf := &OvsInterface{}
+		intf := &OvsInterface{set: map[string]bool{}}
 		if r, dt, err := row.GetColumnValue("_uuid", result.Columns); err != nil {
+			if err := cli.skipRow(result, "_uuid", err); err != nil {
+				return nil, err
+			}
 			continue
 		} else {
 			if dt != "string" {
+				if err := cli.skipRow(result, "_uuid", unexpectedType(dt)); err != nil {
+					return nil, err
+				}
 				continue
 			}
 			intf.UUID = r.(string)
@@ -100,46 +123,39 @@ func (cli *OvsClient) GetDbInterfaces() ([]*OvsInterface, error) {
 			intf.ExternalIDs = make(map[string]string)
 		}
 
-		if r, dt, err := row.GetColumnValue("ofport", result.Columns); err == nil {
-			if dt == "integer" {
-				intf.OfPort = float64(r.(int64))
-			}
+		if v, err := row.GetOptionalInt("ofport"); err == nil && v.Present {
+			intf.OfPort = float64(v.Value)
+			intf.set["ofport"] = true
 		}
 
-		if r, dt, err := row.GetColumnValue("ifindex", result.Columns); err == nil {
-			if dt == "integer" {
-				intf.IfIndex = float64(r.(int64))
-			}
+		if v, err := row.GetOptionalInt("ifindex"); err == nil && v.Present {
+			intf.IfIndex = float64(v.Value)
+			intf.set["ifindex"] = true
 		}
 
-		if r, dt, err := row.GetColumnValue("mtu", result.Columns); err == nil {
-			if dt == "integer" {
-				intf.Mtu = float64(r.(int64))
-			}
+		if v, err := row.GetOptionalInt("mtu"); err == nil && v.Present {
+			intf.Mtu = float64(v.Value)
+			intf.set["mtu"] = true
 		}
 
-		if r, dt, err := row.GetColumnValue("mac_in_use", result.Columns); err == nil {
-			if dt == "string" {
-				intf.MacInUse = r.(string)
-			}
+		if v, err := row.GetOptionalString("mac_in_use"); err == nil && v.Present {
+			intf.MacInUse = v.Value
+			intf.set["mac_in_use"] = true
 		}
 
-		if r, dt, err := row.GetColumnValue("link_speed", result.Columns); err == nil {
-			if dt == "integer" {
-				intf.LinkSpeed = float64(r.(int64))
-			}
+		if v, err := row.GetOptionalInt("link_speed"); err == nil && v.Present {
+			intf.LinkSpeed = float64(v.Value)
+			intf.set["link_speed"] = true
 		}
 
-		if r, dt, err := row.GetColumnValue("link_state", result.Columns); err == nil {
-			if dt == "string" {
-				intf.LinkState = r.(string)
-			}
+		if v, err := row.GetOptionalString("link_state"); err == nil && v.Present {
+			intf.LinkState = v.Value
+			intf.set["link_state"] = true
 		}
 
-		if r, dt, err := row.GetColumnValue("admin_state", result.Columns); err == nil {
-			if dt == "string" {
-				intf.AdminState = r.(string)
-			}
+		if v, err := row.GetOptionalString("admin_state"); err == nil && v.Present {
+			intf.AdminState = v.Value
+			intf.set["admin_state"] = true
 		}
 
 		if r, dt, err := row.GetColumnValue("ingress_policing_burst", result.Columns); err == nil {
@@ -184,10 +200,9 @@ func (cli *OvsClient) GetDbInterfaces() ([]*OvsInterface, error) {
 			}
 		}
 
-		if r, dt, err := row.GetColumnValue("duplex", result.Columns); err == nil {
-			if dt == "string" {
-				intf.Duplex = r.(string)
-			}
+		if v, err := row.GetOptionalString("duplex"); err == nil && v.Present {
+			intf.Duplex = v.Value
+			intf.set["duplex"] = true
 		}
 
 		intfs = append(intfs, intf)
@@ -15,6 +15,11 @@
 package ovsdb
 
 import (
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -42,3 +47,125 @@ func TestOvsClientUpdateRefs(t *testing.T) {
 		t.Errorf("UpdateRefs fail. Expected: %s Ctrl: %s", expectedControllerCtrl, client.Service.Vswitchd.Socket.Control)
 	}
 }
+
+const testVswitchSchema = `{
+  "name": "Open_vSwitch",
+  "version": "8.3.0",
+  "tables": {
+    "Open_vSwitch": {
+      "columns": {
+        "external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}},
+        "ovs_version": {"type": {"key": "string", "min": 0, "max": 1}},
+        "db_version": {"type": {"key": "string", "min": 0, "max": 1}},
+        "system_type": {"type": {"key": "string", "min": 0, "max": 1}},
+        "system_version": {"type": {"key": "string", "min": 0, "max": 1}}
+      },
+      "isRoot": true
+    }
+  }
+}`
+
+func TestOvsClientConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testVswitchSchema)
+		case "transact":
+			row := map[string]interface{}{
+				"external_ids": []interface{}{"map", []interface{}{
+					[]interface{}{"system-id", "host1"},
+					[]interface{}{"rundir", dir},
+					[]interface{}{"hostname", "host1.example.com"},
+				}},
+				"ovs_version":    "3.1.0",
+				"db_version":     "8.3.0",
+				"system_type":    "ubuntu",
+				"system_version": "22.04",
+			}
+			return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}
+		}
+		return nil
+	})
+	cli := NewOvsClient()
+	cli.Database.Vswitch.Socket.Remote = "unix:" + sock
+	cli.Database.Vswitch.File.Pid.Path = filepath.Join(dir, "ovsdb-server.pid")
+	cli.Service.Vswitchd.File.Pid.Path = filepath.Join(dir, "ovs-vswitchd.pid")
+	if err := cli.Connect(); err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			if err := cli.GetSystemInfo(); err != nil {
+				t.Errorf("FAIL: GetSystemInfo: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			cli.GetAppMemoryMetrics("ovsdb-server")
+		}()
+		go func() {
+			defer wg.Done()
+			cli.GetProcessInfo("ovs-vswitchd")
+		}()
+		go func() {
+			defer wg.Done()
+			cli.RLock()
+			defer cli.RUnlock()
+			_ = cli.System.ID + cli.Database.Vswitch.Version
+		}()
+	}
+	wg.Wait()
+	if cli.System.ID != "host1" || cli.Database.Vswitch.Version != "3.1.0" {
+		t.Fatalf("FAIL: unexpected system information: %+v", cli.System)
+	}
+	t.Logf("PASS: concurrent calls completed: %+v", cli.System)
+}
+
+func TestOvsClientConnectRegistered(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method == "list_dbs" {
+			return []string{"Open_vSwitch", "Local_Config"}
+		}
+		return nil
+	})
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	closed.Close()
+
+	cli := NewOvsClient()
+	cli.Timeout = 1
+	cli.Database.Vswitch.Socket.Remote = "tcp:" + closed.Addr().String()
+	local := NewOvsDatabase("Local_Config", "tcp:"+l.Addr().String())
+	if err := cli.RegisterDatabase(local); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	err = cli.Connect()
+	defer cli.Close()
+	if err == nil || !strings.Contains(err.Error(), "Open_vSwitch") {
+		t.Fatalf("FAIL: expected the connection to Open_vSwitch to fail, but got: %v", err)
+	}
+	if local.Client == nil {
+		t.Fatalf("FAIL: expected Local_Config to be connected despite the failure of Open_vSwitch")
+	}
+	if err := local.Client.DatabaseExists("Local_Config"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	t.Logf("PASS: connected to the registered database, despite: %v", err)
+}
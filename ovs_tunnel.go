@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 	"net"
@@ -184,16 +185,22 @@ func NewOvsTunnelFromString(line string) (*OvsTunnel, error) {
 
 // GetTunnels returns a list of tunnels originating from an OVS instance.
 func (cli *OvsClient) GetTunnels() ([]*OvsTunnel, error) {
-	cli.updateRefs()
+	return cli.GetTunnelsContext(context.Background())
+}
+
+// GetTunnelsContext is like GetTunnels, but honors the context.
+func (cli *OvsClient) GetTunnelsContext(ctx context.Context) ([]*OvsTunnel, error) {
+	cli.readLockRefs()
+	defer cli.mux.RUnlock()
 	db := "vswitchd-service"
 	cmd := "ofproto/list-tunnels"
 	tunnels := []*OvsTunnel{}
-	app, err := NewClient(cli.Service.Vswitchd.Socket.Control, cli.Timeout)
+	app, err := NewClientContext(ctx, cli.Service.Vswitchd.Socket.Control, cli.Timeout, cli.controlOptions()...)
 	if err != nil {
 		app.Close()
 		return tunnels, fmt.Errorf("failed '%s' from %s: %s", cmd, db, err)
 	}
-	r, err := app.query(cmd, nil)
+	r, err := app.queryContext(ctx, cmd, nil)
 	if err != nil {
 		app.Close()
 		return tunnels, fmt.Errorf("the '%s' command failed for %s: %s", cmd, db, err)
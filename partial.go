@@ -0,0 +1,57 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+)
+
+// collector runs the sub-queries of a function which gathers data with
+// several queries. Unless in the partial results mode, the first failure is
+// returned as is.
+type collector struct {
+	ctx     context.Context
+	partial bool
+	errs    []*SubqueryError
+}
+
+func newCollector(ctx context.Context, partial bool) *collector {
+	return &collector{ctx: ctx, partial: partial}
+}
+
+// run runs a sub-query. In the partial results mode, its failure is recorded
+// and nil is returned, and it is skipped once the context is done.
+func (c *collector) run(query string, fn func() error) error {
+	if !c.partial {
+		return fn()
+	}
+	if c.ctx.Err() != nil {
+		c.errs = append(c.errs, &SubqueryError{Query: query, Skipped: true, Err: contextError(c.ctx)})
+		return nil
+	}
+	if err := fn(); err != nil {
+		c.errs = append(c.errs, &SubqueryError{Query: query, Err: err})
+	}
+	return nil
+}
+
+// err returns a *MultiError for the sub-queries which failed or were
+// skipped, or nil.
+func (c *collector) err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return &MultiError{Errors: c.errs}
+}
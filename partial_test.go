@@ -0,0 +1,94 @@
+// Copyright 2020 Paul Greenberg greenpau@outlook.com
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPartialResults(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testVswitchSchema)
+		case "transact":
+			if strings.Contains(string(params), "ovs_version") {
+				return []interface{}{map[string]interface{}{"error": "resources exhausted"}}
+			}
+			row := map[string]interface{}{
+				"external_ids": []interface{}{"map", []interface{}{
+					[]interface{}{"system-id", "host1"},
+				}},
+			}
+			return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}
+		}
+		return nil
+	})
+	cli := NewOvsClient()
+	cli.Database.Vswitch.Socket.Remote = "unix:" + sock
+	cli.Database.Vswitch.File.Pid.Path = filepath.Join(dir, "ovsdb-server.pid")
+	cli.Service.Vswitchd.File.Pid.Path = filepath.Join(dir, "ovs-vswitchd.pid")
+	if err := cli.Connect(); err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	var merr *MultiError
+	err = cli.GetSystemInfo()
+	if err == nil || errors.As(err, &merr) {
+		t.Fatalf("FAIL: expected the error of the failed query, got: %v", err)
+	}
+	if cli.System.ID != "unknown" {
+		t.Fatalf("FAIL: expected no system information to be set, got: %+v", cli.System)
+	}
+
+	cli.PartialResults = true
+	err = cli.GetSystemInfo()
+	if !errors.As(err, &merr) {
+		t.Fatalf("FAIL: expected a MultiError, got: %v", err)
+	}
+	if len(merr.Errors) != 1 || merr.Errors[0].Skipped || !strings.Contains(merr.Errors[0].Query, "ovs_version") {
+		t.Fatalf("FAIL: unexpected sub-query errors: %v", err)
+	}
+	if cli.System.ID != "host1" || cli.System.Hostname != "localhost" || cli.Database.Vswitch.Schema.Version != "8.3.0" {
+		t.Fatalf("FAIL: expected the information gathered to be set, got: %+v %+v", cli.System, cli.Database.Vswitch.Schema)
+	}
+	t.Logf("PASS: partial system information: %v", err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = cli.GetSystemInfoContext(ctx)
+	if !errors.As(err, &merr) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("FAIL: expected a MultiError for the canceled context, got: %v", err)
+	}
+	for _, e := range merr.Errors {
+		if !e.Skipped {
+			t.Fatalf("FAIL: expected the '%s' query to be skipped: %v", e.Query, e)
+		}
+	}
+	t.Logf("PASS: skipped sub-queries: %v", err)
+}
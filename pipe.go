@@ -0,0 +1,43 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"strings"
+)
+
+// pipePrefix is the prefix of the names of local named pipes.
+const pipePrefix = `\\.\pipe\`
+
+// pipeName returns the name of the named pipe which OVS on Windows uses in
+// place of the unix socket at the path. The colons and path separators,
+// which named pipe names cannot contain, are dropped, e.g.
+// "C:/ProgramData/openvswitch/db.sock" becomes
+// `\\.\pipe\CProgramDataopenvswitchdb.sock`.
+func pipeName(path string) string {
+	if strings.HasPrefix(path, pipePrefix) {
+		return path
+	}
+	var b strings.Builder
+	b.WriteString(pipePrefix)
+	for _, c := range path {
+		switch c {
+		case ':', '/', '\\':
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
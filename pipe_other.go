@@ -0,0 +1,33 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// namedPipes is true on platforms where OVS uses named pipes in place of
+// unix sockets.
+const namedPipes = false
+
+// dialPipe fails, because named pipes are specific to Windows.
+func dialPipe(ctx context.Context, name string, timeout time.Duration) (net.Conn, error) {
+	return nil, fmt.Errorf("the %s named pipe is not supported on this platform", name)
+}
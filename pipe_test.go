@@ -0,0 +1,59 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"testing"
+)
+
+func TestPipeName(t *testing.T) {
+	testCases := []struct {
+		path string
+		want string
+	}{
+		{"C:/ProgramData/openvswitch/db.sock", `\\.\pipe\CProgramDataopenvswitchdb.sock`},
+		{`C:\ProgramData\openvswitch\db.sock`, `\\.\pipe\CProgramDataopenvswitchdb.sock`},
+		{"/var/run/openvswitch/db.sock", `\\.\pipe\varrunopenvswitchdb.sock`},
+		{`\\.\pipe\ovsdb`, `\\.\pipe\ovsdb`},
+	}
+	for _, tc := range testCases {
+		if got := pipeName(tc.path); got != tc.want {
+			t.Fatalf("FAIL: expected pipe name %s for %s, but got %s", tc.want, tc.path, got)
+		}
+		t.Logf("PASS: %s maps to %s", tc.path, tc.want)
+	}
+}
+
+func TestParseSocketPipe(t *testing.T) {
+	testCases := []struct {
+		remote string
+		proto  string
+		addr   string
+	}{
+		{`\\.\pipe\CProgramDataopenvswitchdb.sock`, "pipe", `\\.\pipe\CProgramDataopenvswitchdb.sock`},
+		{"unix:C:/ProgramData/openvswitch/db.sock", "unix", "C:/ProgramData/openvswitch/db.sock"},
+		{"unix:/var/run/openvswitch/db.sock", "unix", "/var/run/openvswitch/db.sock"},
+	}
+	for _, tc := range testCases {
+		proto, addr, err := parseSocket(tc.remote)
+		if err != nil {
+			t.Fatalf("FAIL: failed to parse %s: %v", tc.remote, err)
+		}
+		if proto != tc.proto || addr != tc.addr {
+			t.Fatalf("FAIL: expected %s %s for %s, but got %s %s", tc.proto, tc.addr, tc.remote, proto, addr)
+		}
+		t.Logf("PASS: %s parsed as %s %s", tc.remote, proto, addr)
+	}
+}
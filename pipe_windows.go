@@ -0,0 +1,78 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// namedPipes is true on platforms where OVS uses named pipes in place of
+// unix sockets.
+const namedPipes = true
+
+// errPipeBusy is ERROR_PIPE_BUSY, returned while all instances of a named
+// pipe are in use.
+const errPipeBusy = syscall.Errno(231)
+
+// pipeConn is a connection to a named pipe.
+type pipeConn struct {
+	*os.File
+}
+
+func (c *pipeConn) LocalAddr() net.Addr {
+	return pipeAddr(c.Name())
+}
+
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return pipeAddr(c.Name())
+}
+
+// pipeAddr is the address of a named pipe.
+type pipeAddr string
+
+func (a pipeAddr) Network() string {
+	return "pipe"
+}
+
+func (a pipeAddr) String() string {
+	return string(a)
+}
+
+// dialPipe connects to the named pipe. It waits for an instance of the pipe
+// to become available until the timeout expires.
+func dialPipe(ctx context.Context, name string, timeout time.Duration) (net.Conn, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(name, os.O_RDWR, 0)
+		if err == nil {
+			return &pipeConn{f}, nil
+		}
+		if !errors.Is(err, errPipeBusy) || time.Now().After(deadline) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Pipeline queues the queries of a database and sends them as a single
+// multi-operation transaction, which takes one roundtrip rather than one
+// per query, e.g. on high-latency links. A pipeline is not safe for
+// concurrent use.
+type Pipeline struct {
+	client  *Client
+	db      string
+	queries []string
+	ops     []Operation
+	err     error
+}
+
+// Pipeline returns an empty pipeline of the database.
+func (c *Client) Pipeline(db string) *Pipeline {
+	return &Pipeline{client: c, db: db}
+}
+
+// Select queues the query, e.g. "SELECT * FROM Chassis", whose placeholders
+// are bound to the arguments, see NewOperation. An invalid query fails the
+// next Flush.
+func (p *Pipeline) Select(query string, args ...interface{}) *Pipeline {
+	op, err := p.client.newOperation(query, args...)
+	if err != nil {
+		if p.err == nil {
+			p.err = fmt.Errorf("query: '%s' failed: %w", query, err)
+		}
+		return p
+	}
+	p.queries = append(p.queries, query)
+	p.ops = append(p.ops, op)
+	return p
+}
+
+// Len returns the number of the queued queries.
+func (p *Pipeline) Len() int {
+	return len(p.queries)
+}
+
+// Flush sends the queued queries in a single transaction and returns their
+// results, in the order of the queries. The pipeline is empty afterwards.
+// Because the transaction is atomic, the first query which fails, fails
+// them all.
+func (p *Pipeline) Flush(ctx context.Context) ([]Result, error) {
+	queries, ops, err := p.queries, p.ops, p.err
+	p.queries, p.ops, p.err = nil, nil, nil
+	if err != nil {
+		return nil, fmt.Errorf("'transact' method, %w", err)
+	}
+	if len(ops) == 0 {
+		return []Result{}, nil
+	}
+	c := p.client
+	if c == nil {
+		return nil, fmt.Errorf("interface is unavailable")
+	}
+	ctx, done, err := c.admit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("'transact' method failed: %w", err)
+	}
+	defer done()
+	method := "transact"
+	response, err := c.transact(ctx, Transaction{Database: p.db, Operations: ops})
+	if err != nil {
+		return nil, fmt.Errorf("'%s' method, %d queries failed: %w", method, len(ops), err)
+	}
+	raw, err := splitResults(response.Result, len(ops))
+	if err != nil {
+		return nil, fmt.Errorf("'%s' method, %d queries failed: %v", method, len(ops), err)
+	}
+	results := make([]Result, len(ops))
+	for i, op := range ops {
+		if err := operationError(raw[i]); err != nil {
+			return nil, fmt.Errorf("'%s' method, query: '%s' failed: %w", method, queries[i], err)
+		}
+		r := Result{Database: p.db, Table: op.Table}
+		if err := json.Unmarshal(raw[i], &r); err != nil {
+			return nil, fmt.Errorf("'%s' method, query: '%s' failed: %v", method, queries[i], err)
+		}
+		r.applyOrder(op)
+		columns, err := c.getColumns(ctx, p.db, op.Table)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' method, query: '%s' failed: %v", method, queries[i], err)
+		}
+		r.Columns = projectColumns(columns, op.Columns)
+		results[i] = r
+	}
+	return results, nil
+}
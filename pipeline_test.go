@@ -0,0 +1,130 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testPipelineSchema = `{
+  "name": "OVN_Southbound",
+  "version": "20.21.0",
+  "tables": {
+    "Chassis": {"columns": {"name": {"type": "string"}}},
+    "Encap": {"columns": {"ip": {"type": "string"}}}
+  }
+}`
+
+func TestPipeline(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	transactions := 0
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testPipelineSchema)
+		case "transact":
+		default:
+			return nil
+		}
+		mu.Lock()
+		transactions++
+		mu.Unlock()
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		results := []interface{}{}
+		for _, arg := range args[1:] {
+			var op struct {
+				Table string `json:"table"`
+			}
+			json.Unmarshal(arg, &op)
+			if op.Table == "Missing" {
+				results = append(results, map[string]interface{}{"error": "unknown table", "details": "No table named Missing."})
+				continue
+			}
+			results = append(results, map[string]interface{}{"rows": []interface{}{map[string]interface{}{"table": op.Table}}})
+		}
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	p := cli.Pipeline("OVN_Southbound")
+	p.Select("SELECT * FROM Chassis").Select("SELECT ip FROM Encap").Select("SELECT * FROM Chassis WHERE name == \"hv1\"")
+	if p.Len() != 3 {
+		t.Fatalf("FAIL: expected 3 queued queries, but got %d", p.Len())
+	}
+	results, err := p.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("FAIL: expected the pipeline to succeed, but failed with: %v", err)
+	}
+	want := []string{"Chassis", "Encap", "Chassis"}
+	if len(results) != len(want) {
+		t.Fatalf("FAIL: expected %d results, but got %d", len(want), len(results))
+	}
+	for i, r := range results {
+		if r.Table != want[i] || len(r.Rows) != 1 || r.Rows[0]["table"] != want[i] || r.Columns == nil {
+			t.Fatalf("FAIL: Test %d: unexpected result: %+v", i, r)
+		}
+	}
+	mu.Lock()
+	if transactions != 1 {
+		t.Fatalf("FAIL: expected a single transaction, but the server received %d", transactions)
+	}
+	mu.Unlock()
+	if p.Len() != 0 {
+		t.Fatalf("FAIL: expected the pipeline to be empty after flush, but got %d queries", p.Len())
+	}
+	t.Logf("PASS: %d queries sent in a single transaction", len(results))
+
+	results, err = p.Select("SELECT * FROM Chassis").Flush(context.Background())
+	if err != nil || len(results) != 1 || results[0].Table != "Chassis" {
+		t.Fatalf("FAIL: expected a single result, but got %+v, %v", results, err)
+	}
+
+	_, err = p.Select("SELECT * FROM Chassis").Select("SELECT * FROM Missing").Flush(context.Background())
+	var ovsdbErr *OvsdbError
+	if errors.As(err, &ovsdbErr) || !errors.Is(err, ErrTableNotFound) || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("FAIL: expected unknown table error of the schema validation, but got: %v", err)
+	}
+	t.Logf("PASS: invalid query rejected: %v", err)
+
+	cli.SkipSchemaValidation = true
+	_, err = p.Select("SELECT * FROM Chassis").Select("SELECT * FROM Missing").Flush(context.Background())
+	if !errors.As(err, &ovsdbErr) || !errors.Is(err, ErrTableNotFound) || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("FAIL: expected unknown table error, but got: %v", err)
+	}
+	cli.SkipSchemaValidation = false
+	t.Logf("PASS: failed query reported: %v", err)
+
+	if _, err := p.Select("DROP TABLE Chassis").Flush(context.Background()); err == nil {
+		t.Fatalf("FAIL: expected an invalid query to fail the pipeline")
+	}
+	if results, err := p.Flush(context.Background()); err != nil || len(results) != 0 {
+		t.Fatalf("FAIL: expected an empty pipeline to succeed, but got %v, %v", results, err)
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// PollRequest selects the columns, and the rows, of a table polled by a
+// Poller, and how often.
+type PollRequest struct {
+	// Columns are the polled columns, all of them when empty.
+	Columns []string
+	// Where, when set, restricts the polled rows to those matching all the
+	// conditions.
+	Where []Condition
+	// Interval is the delay between the polls of the table, ten seconds
+	// when unset.
+	Interval time.Duration
+}
+
+// PollPolicy controls the delays between the polls of a Poller.
+type PollPolicy struct {
+	// Jitter randomizes each delay by up to the given fraction of it, so
+	// that the polls of the tables, and of the clients, spread out.
+	Jitter float64
+	// InitialBackoff is the delay before polling a table again after a
+	// poll failed, the interval of the table when unset. The delay
+	// doubles with every subsequent failure.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the delay after failures, ten times
+	// the interval of the table when unset.
+	MaxBackoff time.Duration
+}
+
+// Poller polls the rows of tables of a database, for the servers, or the
+// deployments, where monitors are not an option. It selects the rows of
+// every table on an interval of its own, and reports their differences
+// with the rows of the previous poll, as a Monitor does.
+type Poller struct {
+	// Updates receives the rows of the first polls, then their changes, a
+	// value per poll of a table which changed. It is closed when the
+	// poller ends, see Err.
+	Updates <-chan TableUpdates
+
+	db     string
+	client *Client
+	schema Schema
+	policy PollPolicy
+	sink   func(updates TableUpdates, txnID string, resync bool)
+	// rows are the rows of the last poll, by table and UUID. The rows of
+	// a table are only accessed by the goroutine polling it.
+	rows    map[string]map[UUID]Row
+	updates chan TableUpdates
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mux      sync.Mutex
+	requests map[string]PollRequest
+	// polled is when the tables were last polled successfully.
+	polled  map[string]time.Time
+	stopped bool
+	err     error
+}
+
+// Poll polls the tables of the database, see Poller, and returns once the
+// rows of every table were polled. The context bounds the first polls, and
+// the poller runs until it is cancelled, or its client is closed. The
+// polls which fail are retried after a backoff, see PollPolicy.
+func (c *Client) Poll(ctx context.Context, db string, requests map[string]PollRequest, policy PollPolicy) (*Poller, error) {
+	return c.poll(ctx, db, requests, policy, nil)
+}
+
+func (c *Client) poll(ctx context.Context, db string, requests map[string]PollRequest, policy PollPolicy, sink func(TableUpdates, string, bool)) (*Poller, error) {
+	if c == nil {
+		return nil, fmt.Errorf("poller failed: interface is unavailable")
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("poller failed for '%s' database: no tables", db)
+	}
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("poller failed for '%s' database: %w", db, err)
+	}
+	tables := make(map[string]PollRequest, len(requests))
+	for table, request := range requests {
+		if err := checkMonitorRequest(schema, table, MonitorRequest{Columns: request.Columns, Where: request.Where}); err != nil {
+			return nil, fmt.Errorf("poller failed for '%s' database: %w", db, err)
+		}
+		if request.Interval <= 0 {
+			request.Interval = 10 * time.Second
+		}
+		tables[table] = request
+	}
+	updates := make(chan TableUpdates)
+	p := &Poller{
+		Updates:  updates,
+		db:       db,
+		client:   c,
+		schema:   schema,
+		policy:   policy,
+		sink:     sink,
+		rows:     make(map[string]map[UUID]Row, len(tables)),
+		updates:  updates,
+		done:     make(chan struct{}),
+		requests: tables,
+		polled:   make(map[string]time.Time, len(tables)),
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	first := make(TableUpdates, len(tables))
+	for table := range tables {
+		p.rows[table] = make(map[UUID]Row)
+		u, err := p.pollTable(ctx, table)
+		if err != nil {
+			p.cancel()
+			return nil, fmt.Errorf("poller failed for '%s' database: %w", db, err)
+		}
+		first[table] = u
+	}
+	go p.run(first)
+	return p, nil
+}
+
+// run delivers the rows of the first polls, then polls every table on its
+// interval until the poller stops.
+func (p *Poller) run(first TableUpdates) {
+	defer close(p.done)
+	defer close(p.updates)
+	if !p.deliver(first) {
+		return
+	}
+	var wg sync.WaitGroup
+	for table := range p.rows {
+		wg.Add(1)
+		go func(table string) {
+			defer wg.Done()
+			p.pollEvery(table)
+		}(table)
+	}
+	wg.Wait()
+}
+
+// pollEvery polls the table on its interval, or its backoff after the
+// polls which fail, until the poller stops.
+func (p *Poller) pollEvery(table string) {
+	failures := 0
+	for {
+		p.mux.Lock()
+		interval := p.requests[table].Interval
+		p.mux.Unlock()
+		delay := ReconnectPolicy{InitialBackoff: interval, Jitter: p.policy.Jitter}.backoff(1)
+		if failures > 0 {
+			backoff := ReconnectPolicy{
+				InitialBackoff: p.policy.InitialBackoff,
+				MaxBackoff:     p.policy.MaxBackoff,
+				Jitter:         p.policy.Jitter,
+			}
+			if backoff.InitialBackoff <= 0 {
+				backoff.InitialBackoff = interval
+			}
+			if backoff.MaxBackoff <= 0 {
+				backoff.MaxBackoff = 10 * interval
+			}
+			delay = backoff.backoff(failures)
+		}
+		if err := sleepContext(p.ctx, delay); err != nil {
+			return
+		}
+		u, err := p.pollTable(p.ctx, table)
+		if err != nil {
+			if p.ctx.Err() != nil {
+				return
+			}
+			if p.client.isClosing() {
+				p.stop(fmt.Errorf("poller ended: client closed: %w", ErrNotConnected))
+				return
+			}
+			failures++
+			p.client.logger().Warnf("polling table %s of %s failed, attempt %d: %v", table, p.db, failures, err)
+			continue
+		}
+		failures = 0
+		if len(u) == 0 {
+			continue
+		}
+		if !p.deliver(TableUpdates{table: u}) {
+			return
+		}
+	}
+}
+
+// pollTable selects the rows of the table, and returns their differences
+// with those of the previous poll, which they replace.
+func (p *Poller) pollTable(ctx context.Context, table string) (TableUpdate, error) {
+	p.mux.Lock()
+	request := p.requests[table]
+	p.mux.Unlock()
+	rows, err := p.client.selectTable(ctx, p.db, table, MonitorRequest{Columns: request.Columns, Where: request.Where})
+	if err != nil {
+		return nil, err
+	}
+	p.mux.Lock()
+	p.polled[table] = time.Now()
+	p.mux.Unlock()
+	u := make(TableUpdate)
+	old := p.rows[table]
+	for uuid, row := range rows {
+		if prev, exists := old[uuid]; !exists {
+			u[uuid] = RowUpdate{New: row}
+		} else if !reflect.DeepEqual(prev, row) {
+			u[uuid] = RowUpdate{Old: prev, New: row}
+		}
+	}
+	for uuid, prev := range old {
+		if _, exists := rows[uuid]; !exists {
+			u[uuid] = RowUpdate{Old: prev}
+		}
+	}
+	for uuid := range old {
+		delete(old, uuid)
+	}
+	for uuid, row := range rows {
+		old[uuid] = row
+	}
+	return u, nil
+}
+
+// selectTable selects the rows of the table, with the columns, and the
+// conditions, of the request, by UUID. The rows hold the columns, as
+// those of a monitor do, without _uuid and _version.
+func (c *Client) selectTable(ctx context.Context, db, table string, request MonitorRequest) (map[UUID]Row, error) {
+	columns := request.Columns
+	if len(columns) > 0 && !contains(columns, "_uuid") {
+		columns = append(append([]string(nil), columns...), "_uuid")
+	}
+	results, err := c.TransactOperations(ctx, db, Select(table, columns, request.Where...))
+	if err != nil {
+		return nil, err
+	}
+	rows := make(map[UUID]Row, len(results[0].Rows))
+	for _, r := range results[0].Rows {
+		uuid := rowUUID(r)
+		if uuid == "" {
+			return nil, fmt.Errorf("table %s: row without _uuid", table)
+		}
+		row := make(Row, len(r))
+		for column, value := range r {
+			if column != "_uuid" && column != "_version" {
+				row[column] = value
+			}
+		}
+		rows[uuid] = row
+	}
+	return rows, nil
+}
+
+// deliver passes the updates to the sink, or to Updates, and returns false
+// once the poller stopped.
+func (p *Poller) deliver(updates TableUpdates) bool {
+	if p.sink != nil {
+		p.mux.Lock()
+		stopped := p.stopped
+		p.mux.Unlock()
+		if stopped {
+			return false
+		}
+		p.sink(updates, "", false)
+		return true
+	}
+	select {
+	case p.updates <- updates:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// stop stops the poller, and returns false when it already was.
+func (p *Poller) stop(err error) bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.stopped {
+		return false
+	}
+	p.stopped = true
+	p.err = err
+	p.cancel()
+	return true
+}
+
+// Cancel stops the poller, and waits for its polls in flight to return,
+// or for the context to be done. It closes Updates.
+func (p *Poller) Cancel(ctx context.Context) error {
+	p.stop(nil)
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return contextError(ctx)
+	}
+}
+
+// Err returns why the poller ended, e.g. ErrNotConnected when its client
+// was closed. It is nil while the poller runs, and after it has been
+// cancelled.
+func (p *Poller) Err() error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.err
+}
+
+// SetConditions replaces the conditions on the rows of the polled tables,
+// from their next polls. The tables it does not list keep theirs, and
+// those it lists with no conditions are polled in full. Updates receives
+// the rows which start, or stop, matching them as inserted, or deleted.
+func (p *Poller) SetConditions(ctx context.Context, where map[string][]Condition) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	for table, conds := range where {
+		request, exists := p.requests[table]
+		if !exists {
+			return fmt.Errorf("poller failed for '%s' database: table %s is not polled", p.db, table)
+		}
+		if err := checkMonitorRequest(p.schema, table, MonitorRequest{Columns: request.Columns, Where: conds}); err != nil {
+			return fmt.Errorf("poller failed for '%s' database: %w", p.db, err)
+		}
+	}
+	for table, conds := range where {
+		request := p.requests[table]
+		request.Where = conds
+		p.requests[table] = request
+	}
+	return nil
+}
+
+// heard returns when the poller was last known to be up to date, i.e.
+// when the table polled the longest ago was last polled.
+func (p *Poller) heard() time.Time {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	var heard time.Time
+	for _, polled := range p.polled {
+		if heard.IsZero() || polled.Before(heard) {
+			heard = polled
+		}
+	}
+	return heard
+}
+
+// wireRequests returns the requests of the polled tables, in the form of
+// those of monitor_cond, for the snapshots of a cache.
+func (p *Poller) wireRequests() ([]byte, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	requests := make(map[string]interface{}, len(p.requests))
+	for table, r := range p.requests {
+		request := make(map[string]interface{})
+		if len(r.Columns) > 0 {
+			request["columns"] = r.Columns
+		}
+		if len(r.Where) > 0 {
+			request["where"] = r.Where
+		}
+		requests[table] = []interface{}{request}
+	}
+	return json.Marshal(requests)
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestPollServer returns the remote of a server whose Bridge table holds
+// the rows set with the returned function, by UUID and name.
+func newTestPollServer(t *testing.T) (string, func(rows map[string]string)) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	bridges := map[string]string{}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "transact":
+			if !strings.Contains(string(params), `"select"`) {
+				return []interface{}{map[string]interface{}{}}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			rows := []interface{}{}
+			for uuid, name := range bridges {
+				rows = append(rows, map[string]interface{}{
+					"_uuid":    []interface{}{"uuid", uuid},
+					"_version": []interface{}{"uuid", "0a6f4c3c-7e5e-4a55-9c8c-6b2f6a4f1e00"},
+					"name":     name,
+				})
+			}
+			return []interface{}{map[string]interface{}{"rows": rows}}
+		}
+		return nil
+	})
+	return "tcp:" + l.Addr().String(), func(rows map[string]string) {
+		mu.Lock()
+		defer mu.Unlock()
+		bridges = rows
+	}
+}
+
+func nextPollUpdates(t *testing.T, p *Poller) TableUpdates {
+	t.Helper()
+	select {
+	case u, ok := <-p.Updates:
+		if !ok {
+			t.Fatalf("FAIL: expected updates, but the poller ended: %v", p.Err())
+		}
+		return u
+	case <-time.After(5 * time.Second):
+		t.Fatalf("FAIL: timed out waiting for updates")
+	}
+	return nil
+}
+
+func TestClientPoll(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	remote, set := newTestPollServer(t)
+	set(map[string]string{br0: "br0"})
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	if _, err := c.Poll(ctx, "Open_vSwitch", map[string]PollRequest{"Bridge": {Columns: []string{"nope"}}}, PollPolicy{}); err == nil {
+		t.Fatalf("FAIL: expected an error for an unknown column")
+	}
+	p, err := c.Poll(ctx, "Open_vSwitch", map[string]PollRequest{
+		"Bridge": {Columns: []string{"name"}, Interval: 10 * time.Millisecond},
+	}, PollPolicy{Jitter: 0.2})
+	if err != nil {
+		t.Fatalf("FAIL: expected to poll, but failed with: %v", err)
+	}
+	u := nextPollUpdates(t, p)
+	if row := u["Bridge"][br0]; row.Old != nil || row.New["name"] != "br0" || len(row.New) != 1 {
+		t.Fatalf("FAIL: unexpected initial rows: %v", u)
+	}
+	t.Logf("PASS: initial rows polled")
+
+	set(map[string]string{br0: "br2", br1: "br1"})
+	u = nextPollUpdates(t, p)
+	if row := u["Bridge"][br0]; row.Old["name"] != "br0" || row.New["name"] != "br2" {
+		t.Fatalf("FAIL: unexpected update of the modified row: %v", u)
+	}
+	if row := u["Bridge"][br1]; row.Old != nil || row.New["name"] != "br1" {
+		t.Fatalf("FAIL: unexpected update of the inserted row: %v", u)
+	}
+	set(map[string]string{br0: "br2"})
+	u = nextPollUpdates(t, p)
+	if row, exists := u["Bridge"][br1]; !exists || row.Old["name"] != "br1" || row.New != nil || len(u["Bridge"]) != 1 {
+		t.Fatalf("FAIL: unexpected update of the deleted row: %v", u)
+	}
+	t.Logf("PASS: differences between polls reported")
+
+	if err := p.Cancel(ctx); err != nil {
+		t.Fatalf("FAIL: expected to cancel, but failed with: %v", err)
+	}
+	for range p.Updates {
+	}
+	if p.Err() != nil {
+		t.Fatalf("FAIL: unexpected error of a cancelled poller: %v", p.Err())
+	}
+	t.Logf("PASS: poller cancelled")
+}
+
+func TestNewPollingCache(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	remote, set := newTestPollServer(t)
+	set(map[string]string{br0: "br0"})
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	cache, err := c.NewPollingCache(ctx, "Open_vSwitch", map[string]PollRequest{
+		"Bridge": {Interval: 10 * time.Millisecond},
+	}, PollPolicy{})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	if row, exists, err := cache.RowByKey("Bridge", map[string]interface{}{"name": "br0"}); err != nil || !exists || row["_uuid"].([]interface{})[1] != br0 {
+		t.Fatalf("FAIL: expected the polled row, got: %v, %v", row, err)
+	}
+	events := make(chan string, 16)
+	cache.RegisterHandler("Bridge", EventHandler{
+		OnAdd: func(table string, row Row) {
+			events <- "add " + row["name"].(string)
+		},
+		OnDelete: func(table string, row Row) {
+			events <- "delete " + row["name"].(string)
+		},
+	})
+	next := func() string {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatalf("FAIL: timed out waiting for an event")
+		}
+		return ""
+	}
+	if e := next(); e != "add br0" {
+		t.Fatalf("FAIL: expected the existing row, got: %s", e)
+	}
+	set(map[string]string{br1: "br1"})
+	first, second := next(), next()
+	if first != "delete br0" || second != "add br1" {
+		t.Fatalf("FAIL: unexpected events: %s, %s", first, second)
+	}
+	t.Logf("PASS: polled changes handled")
+	if err := cache.Close(ctx); err != nil {
+		t.Fatalf("FAIL: expected to close, but failed with: %v", err)
+	}
+	t.Logf("PASS: cache closed")
+}
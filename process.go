@@ -106,6 +106,8 @@ func getProcessInfoFromFile(f string) (OvsProcess, error) {
 
 // GetProcessInfo returns information about a service or database process.
 func (cli *OvnClient) GetProcessInfo(name string) (OvsProcess, error) {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
 	var p OvsProcess
 	var err error
 	switch name {
@@ -146,6 +148,8 @@ func (cli *OvnClient) GetProcessInfo(name string) (OvsProcess, error) {
 
 // GetProcessInfo returns information about a service or database process.
 func (cli *OvsClient) GetProcessInfo(name string) (OvsProcess, error) {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
 	var p OvsProcess
 	var err error
 	switch name {
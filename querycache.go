@@ -0,0 +1,161 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// DefaultQueryCacheSize is the number of parsed queries a client keeps by
+// default.
+const DefaultQueryCacheSize = 256
+
+// QueryCacheStats are the statistics of the cache of the parsed queries of
+// a client, see WithQueryCacheSize.
+type QueryCacheStats struct {
+	// Size is the number of queries the cache keeps, and Entries the
+	// number it holds.
+	Size    int
+	Entries int
+	// Hits and Misses are the numbers of queries found in the cache, and
+	// parsed, respectively. Evictions is the number of queries dropped to
+	// make room for others.
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// WithQueryCacheSize sets the number of parsed queries a client keeps, so
+// that the queries it sends repeatedly, e.g. every scrape of an exporter,
+// are parsed once. A negative size disables the cache.
+func WithQueryCacheSize(n int) ClientOption {
+	return func(cli *Client) error {
+		if n == 0 {
+			return fmt.Errorf("invalid query cache size: %d", n)
+		}
+		cli.QueryCacheSize = n
+		return nil
+	}
+}
+
+// queryPlan is a parsed query, whose placeholders are yet to be bound.
+type queryPlan struct {
+	query string
+	op    Operation
+	// placeholders are the indexes of the conditions ending with one.
+	placeholders []int
+}
+
+// queryCache holds the parsed queries, and drops the least recently used
+// ones once it is full.
+type queryCache struct {
+	mux   sync.Mutex
+	size  int
+	plans map[string]*list.Element
+	lru   *list.List
+	stats QueryCacheStats
+}
+
+// newQueryCache returns the cache of the size, or nil when it is disabled.
+func newQueryCache(size int) *queryCache {
+	if size < 0 {
+		return nil
+	}
+	if size == 0 {
+		size = DefaultQueryCacheSize
+	}
+	return &queryCache{size: size, plans: make(map[string]*list.Element), lru: list.New()}
+}
+
+// plan returns the parsed query, from the cache if it is there.
+func (c *queryCache) plan(query string) (*queryPlan, error) {
+	c.mux.Lock()
+	if e, ok := c.plans[query]; ok {
+		c.lru.MoveToFront(e)
+		c.stats.Hits++
+		c.mux.Unlock()
+		return e.Value.(*queryPlan), nil
+	}
+	c.stats.Misses++
+	c.mux.Unlock()
+	p := &queryPlan{query: query, op: Operation{Conditions: []Condition{}}}
+	placeholders, err := p.op.parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p.placeholders = placeholders
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if e, ok := c.plans[query]; ok {
+		// The query has been parsed concurrently.
+		return e.Value.(*queryPlan), nil
+	}
+	c.plans[query] = c.lru.PushFront(p)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.plans, oldest.Value.(*queryPlan).query)
+		c.stats.Evictions++
+	}
+	return p, nil
+}
+
+func (c *queryCache) snapshot() QueryCacheStats {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	stats := c.stats
+	stats.Size = c.size
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// bind returns the operation of the parsed query, with its placeholders
+// bound to the arguments. The plan itself is left untouched.
+func (p *queryPlan) bind(args []interface{}) (Operation, error) {
+	op := p.op
+	op.Columns = append([]string(nil), p.op.Columns...)
+	op.Conditions = append([]Condition{}, p.op.Conditions...)
+	if err := op.bind(p.query, p.placeholders, args); err != nil {
+		return op, err
+	}
+	if err := op.Validate(); err != nil {
+		return op, err
+	}
+	return op, nil
+}
+
+// newOperation is like NewOperation, but the query is parsed once for as
+// long as it stays in the cache of the client.
+func (c *Client) newOperation(query string, args ...interface{}) (Operation, error) {
+	if c == nil || c.queries == nil {
+		return NewOperation(query, args...)
+	}
+	p, err := c.queries.plan(query)
+	if err != nil {
+		return Operation{Conditions: []Condition{}}, err
+	}
+	return p.bind(args)
+}
+
+// QueryCacheStats returns the statistics of the cache of the parsed
+// queries of the client. They are all zero when the cache is disabled.
+func (c *Client) QueryCacheStats() QueryCacheStats {
+	if c.queries == nil {
+		return QueryCacheStats{}
+	}
+	return c.queries.snapshot()
+}
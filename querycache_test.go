@@ -0,0 +1,63 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryCache(t *testing.T) {
+	cli := &Client{queries: newQueryCache(2)}
+	query := "SELECT _uuid, name FROM Chassis WHERE name==?, hostname==host1"
+	for _, name := range []string{"ch1", "ch2", "ch1"} {
+		op, err := cli.newOperation(query, name)
+		if err != nil {
+			t.Fatalf("FAIL: expected %q to parse, but failed with: %v", query, err)
+		}
+		want, _ := NewOperation(query, name)
+		if !reflect.DeepEqual(op, want) {
+			t.Fatalf("FAIL: expected %+v, but got %+v", want, op)
+		}
+	}
+	if stats := cli.QueryCacheStats(); stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 || stats.Size != 2 {
+		t.Fatalf("FAIL: unexpected statistics: %+v", stats)
+	}
+	t.Logf("PASS: parsed %q once", query)
+
+	if _, err := cli.newOperation(query); err == nil {
+		t.Fatalf("FAIL: expected a missing argument to fail")
+	}
+	if _, err := cli.newOperation(query, "ch1", "ch2"); err == nil {
+		t.Fatalf("FAIL: expected an extra argument to fail")
+	}
+	if _, err := cli.newOperation("SELECT * FROM Chassis LIMIT 0"); err == nil {
+		t.Fatalf("FAIL: expected an invalid query to fail")
+	}
+	for _, q := range []string{"SELECT * FROM Encap", "SELECT * FROM Port_Binding"} {
+		if _, err := cli.newOperation(q); err != nil {
+			t.Fatalf("FAIL: expected %q to parse, but failed with: %v", q, err)
+		}
+	}
+	if stats := cli.QueryCacheStats(); stats.Entries != 2 || stats.Evictions != 1 {
+		t.Fatalf("FAIL: expected the least recently used query to be evicted: %+v", stats)
+	}
+	t.Logf("PASS: statistics %+v", cli.QueryCacheStats())
+
+	disabled := &Client{queries: newQueryCache(-1)}
+	if _, err := disabled.newOperation(query, "ch1"); err != nil || disabled.QueryCacheStats() != (QueryCacheStats{}) {
+		t.Fatalf("FAIL: expected the disabled cache to parse every query, got: %v, %+v", err, disabled.QueryCacheStats())
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// defaultConnectRetryInterval is the interval between connect attempts
+// unless configured otherwise.
+const defaultConnectRetryInterval = 500 * time.Millisecond
+
+// ReconnectPolicy controls how a client re-establishes its connection after
+// a socket error, e.g. when ovsdb-server restarts.
+type ReconnectPolicy struct {
+	// MaxRetries is the number of reconnect attempts before a request fails.
+	MaxRetries int
+	// InitialBackoff is the delay before the first reconnect attempt. The
+	// delay doubles with every subsequent attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the delay between attempts.
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to the given fraction of it,
+	// e.g. 0.2 for +/-20%.
+	Jitter float64
+}
+
+// ReconnectEvent describes a reconnect attempt of a client.
+type ReconnectEvent struct {
+	Endpoint string
+	// Attempt is the sequence number of the attempt, starting with 1.
+	Attempt int
+	// Delay is the time the client waited before the attempt.
+	Delay time.Duration
+	// Cause is the error which broke the previous connection.
+	Cause error
+	// Err is the outcome of the attempt, nil when the client reconnected.
+	Err error
+}
+
+// DefaultReconnectPolicy returns the policy used by clients unless
+// configured otherwise.
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		MaxRetries:     2,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Jitter:         0.2,
+	}
+}
+
+// WithReconnectPolicy sets the reconnect policy of a client.
+func WithReconnectPolicy(p ReconnectPolicy) ClientOption {
+	return func(cli *Client) error {
+		cli.MaxRetries = p.MaxRetries
+		cli.Reconnect = p
+		return nil
+	}
+}
+
+// WithReconnectHook registers a function called after every reconnect
+// attempt of a client.
+func WithReconnectHook(fn func(ReconnectEvent)) ClientOption {
+	return func(cli *Client) error {
+		cli.OnReconnect = fn
+		return nil
+	}
+}
+
+// WithConnectRetry makes NewClient retry to connect to an endpoint which is
+// not reachable yet, e.g. when the client starts before ovs-vswitchd, up to
+// the number of retries, waiting for the interval between the attempts.
+func WithConnectRetry(retries int, interval time.Duration) ClientOption {
+	return func(cli *Client) error {
+		if retries < 0 {
+			return fmt.Errorf("invalid number of connect retries: %d", retries)
+		}
+		cli.ConnectRetries = retries
+		cli.ConnectRetryInterval = interval
+		return nil
+	}
+}
+
+// connectRetry connects the client, retrying as configured by
+// WithConnectRetry.
+func (cli *Client) connectRetry(ctx context.Context) error {
+	err := cli.connect(ctx)
+	for attempt := 1; err != nil && attempt <= cli.ConnectRetries; attempt++ {
+		interval := cli.connectRetryInterval()
+		cli.logger().Infof("retrying to connect to %s in %s, attempt %d", cli.Endpoint, interval, attempt)
+		if sleepContext(ctx, interval) != nil {
+			break
+		}
+		err = cli.connect(ctx)
+	}
+	return err
+}
+
+func (cli *Client) connectRetryInterval() time.Duration {
+	if cli.ConnectRetryInterval > 0 {
+		return cli.ConnectRetryInterval
+	}
+	return defaultConnectRetryInterval
+}
+
+// WaitForReady blocks until the endpoint of the client is reachable and
+// answers an echo request, or the context is done. A client which failed
+// to connect, e.g. because the daemon has not started yet, may wait for it
+// to come up. The attempts are ConnectRetryInterval apart.
+func (cli *Client) WaitForReady(ctx context.Context) error {
+	for {
+		cli.mux.Lock()
+		var err error
+		if cli.closed {
+			if cli.isClosing() {
+				cli.mux.Unlock()
+				return fmt.Errorf("client unavailable: %w", ErrClosing)
+			}
+			err = cli.connect(ctx)
+		}
+		cli.mux.Unlock()
+		if err == nil {
+			if err = cli.EchoContext(ctx, "ready"); err == nil {
+				return nil
+			}
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s not ready: %w: %w", cli.Endpoint, contextError(ctx), err)
+		}
+		cli.logger().Debugf("%s not ready: %v", cli.Endpoint, err)
+		if err := sleepContext(ctx, cli.connectRetryInterval()); err != nil {
+			return fmt.Errorf("%s not ready: %w", cli.Endpoint, contextError(ctx))
+		}
+	}
+}
+
+// backoff returns the delay before the given reconnect attempt.
+func (p ReconnectPolicy) backoff(attempt int) time.Duration {
+	if attempt < 1 || p.InitialBackoff <= 0 {
+		return 0
+	}
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			delay = p.MaxBackoff
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delta := float64(delay) * p.Jitter
+		delay = time.Duration(float64(delay) - delta + rand.Float64()*2*delta) //nolint:gosec
+	}
+	return delay
+}
+
+// sleepContext waits for the duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
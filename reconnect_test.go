@@ -0,0 +1,295 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// oneShotConn closes the connection after the first response, as if the
+// server restarted.
+type oneShotConn struct {
+	net.Conn
+}
+
+func (c *oneShotConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.Conn.Close()
+	return n, err
+}
+
+// silentConn stops answering once silenced, as if the server hung or the
+// link went down without closing the connection.
+type silentConn struct {
+	net.Conn
+	silent *atomic.Bool
+}
+
+func (c *silentConn) Write(b []byte) (int, error) {
+	if c.silent.Load() {
+		return len(b), nil
+	}
+	return c.Conn.Write(b)
+}
+
+func TestClientReconnect(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	defer l.Close()
+	go func() {
+		for i := 0; ; i++ {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			if i == 0 {
+				conn = &oneShotConn{conn}
+			}
+			go serveTestConn(conn, nil)
+		}
+	}()
+
+	var mu sync.Mutex
+	events := []ReconnectEvent{}
+	cli, err := NewClient("unix:"+sock, 1,
+		WithReconnectPolicy(ReconnectPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}),
+		WithReconnectHook(func(ev ReconnectEvent) {
+			mu.Lock()
+			events = append(events, ev)
+			mu.Unlock()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to %s, but failed with: %v", sock, err)
+	}
+	defer cli.Close()
+	for i := 0; i < 3; i++ {
+		if err := cli.Echo("test message"); err != nil {
+			t.Fatalf("FAIL: echo %d: %v", i, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 1 {
+		t.Fatalf("FAIL: expected a single reconnect event, but got: %v", events)
+	}
+	if events[0].Cause == nil || events[0].Err != nil || events[0].Attempt != 1 {
+		t.Fatalf("FAIL: unexpected reconnect event: %+v", events[0])
+	}
+	t.Logf("PASS: client reconnected after %v", events[0].Cause)
+}
+
+func TestClientReconnectExhausted(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	attempts := 0
+	cli, err := NewClient("unix:"+sock, 1,
+		WithReconnectPolicy(ReconnectPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}),
+		WithReconnectHook(func(ev ReconnectEvent) {
+			attempts++
+		}),
+	)
+	if err == nil {
+		t.Fatalf("FAIL: expected to fail connecting to %s", sock)
+	}
+	if err := cli.Echo("test message"); err == nil {
+		t.Fatalf("FAIL: expected echo to fail")
+	}
+	if attempts != 3 {
+		t.Fatalf("FAIL: expected 3 reconnect attempts, but got %d", attempts)
+	}
+}
+
+// listenLater starts a test server on the unix socket after the delay, as
+// if the daemon started after the client.
+func listenLater(t *testing.T, sock string, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		l, err := net.Listen("unix", sock)
+		if err != nil {
+			t.Errorf("FAIL: failed to listen on %s: %v", sock, err)
+			return
+		}
+		newTestServer(t, l, nil)
+	})
+}
+
+func TestClientConnectRetry(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	if _, err := NewClient("unix:"+sock, 1, WithConnectRetry(-1, 0)); err == nil {
+		t.Fatalf("FAIL: expected to fail with negative connect retries")
+	}
+	if _, err := NewClient("unix:"+sock, 1, WithConnectRetry(2, time.Millisecond)); err == nil {
+		t.Fatalf("FAIL: expected to fail connecting to %s", sock)
+	}
+	listenLater(t, sock, 30*time.Millisecond)
+	cli, err := NewClient("unix:"+sock, 1, WithConnectRetry(100, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to %s after retries, but failed with: %v", sock, err)
+	}
+	defer cli.Close()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	t.Logf("PASS: client connected to %s after retries", sock)
+}
+
+func TestClientWaitForReady(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	cli, err := NewClient("unix:"+sock, 1, WithConnectRetry(0, 10*time.Millisecond))
+	if err == nil {
+		t.Fatalf("FAIL: expected to fail connecting to %s", sock)
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	err = cli.WaitForReady(ctx)
+	cancel()
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("FAIL: expected timeout error, but got: %v", err)
+	}
+
+	listenLater(t, sock, 30*time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := cli.WaitForReady(ctx); err != nil {
+		t.Fatalf("FAIL: expected %s to become ready, but failed with: %v", sock, err)
+	}
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	t.Logf("PASS: client waited for %s to become ready", sock)
+}
+
+func TestClientIdleCheck(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	defer l.Close()
+	silent := new(atomic.Bool)
+	go func() {
+		for i := 0; ; i++ {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			if i == 0 {
+				conn = &silentConn{Conn: conn, silent: silent}
+			}
+			go serveTestConn(conn, nil)
+		}
+	}()
+
+	cli, err := NewClient("unix:"+sock, 1,
+		WithIdleCheck(20*time.Millisecond, 50*time.Millisecond),
+		WithRequestTimeout(5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to %s, but failed with: %v", sock, err)
+	}
+	defer cli.Close()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	silent.Store(true)
+	time.Sleep(30 * time.Millisecond)
+	start := time.Now()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: expected echo to succeed on a new connection, but failed with: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("FAIL: expected the dead connection to be detected by the probe, but echo took %s", elapsed)
+	}
+	t.Logf("PASS: client re-dialed the dead idle connection in %s", time.Since(start))
+}
+
+func TestReconnectPolicyBackoff(t *testing.T) {
+	p := ReconnectPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for i, expected := range []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		if d := p.backoff(i); d != expected {
+			t.Fatalf("FAIL: attempt %d: expected %v, but got %v", i, expected, d)
+		}
+	}
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := p.backoff(2); d < 100*time.Millisecond || d > 300*time.Millisecond {
+			t.Fatalf("FAIL: jittered delay out of range: %v", d)
+		}
+	}
+}
+
+func TestClientFailover(t *testing.T) {
+	dir := t.TempDir()
+	unavailable := "unix:" + filepath.Join(dir, "unavailable.sock")
+	remotes := []string{}
+	for i, name := range []string{"member1.sock", "member2.sock"} {
+		sock := filepath.Join(dir, name)
+		l, err := net.Listen("unix", sock)
+		if err != nil {
+			t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+		}
+		defer l.Close()
+		go func(i int) {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				if i == 0 {
+					conn = &oneShotConn{conn}
+				}
+				go serveTestConn(conn, nil)
+			}
+		}(i)
+		remotes = append(remotes, "unix:"+sock)
+	}
+
+	cli, err := NewClient(unavailable+","+remotes[0]+", "+remotes[1], 1,
+		WithReconnectPolicy(ReconnectPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	if cli.Remote() != remotes[0] {
+		t.Fatalf("FAIL: expected to connect to %s, but connected to %s", remotes[0], cli.Remote())
+	}
+	for i := 0; i < 2; i++ {
+		if err := cli.Echo("test message"); err != nil {
+			t.Fatalf("FAIL: echo %d: %v", i, err)
+		}
+	}
+	if cli.Remote() != remotes[1] {
+		t.Fatalf("FAIL: expected to fail over to %s, but connected to %s", remotes[1], cli.Remote())
+	}
+	cli.Failover()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: echo after failover: %v", err)
+	}
+	if cli.Remote() != remotes[0] {
+		t.Fatalf("FAIL: expected to fail over to %s, but connected to %s", remotes[0], cli.Remote())
+	}
+	t.Logf("PASS: client failed over between cluster members")
+}
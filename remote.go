@@ -0,0 +1,153 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultRemotePort is the port of the "tcp:", "ssl:", "ptcp:", and "pssl:"
+// remotes which do not specify one.
+const DefaultRemotePort = 6640
+
+// Remote is a remote specification in the syntax accepted by ovs-vsctl,
+// ovn-sbctl, and ovsdb-server, e.g. "unix:/var/run/openvswitch/db.sock",
+// "tcp:10.0.0.1:6641", or "ptcp:6640:[::1]".
+type Remote struct {
+	// Proto is one of "unix", "tcp", "ssl", or "pipe".
+	Proto string
+	// Passive is set for the remotes to listen on, i.e. "punix:", "ptcp:",
+	// and "pssl:".
+	Passive bool
+	// Address is the path of the socket or the named pipe, or the host and
+	// the port. The host of a passive remote may be empty.
+	Address string
+}
+
+// ParseRemote parses the remote. Besides the OVS syntax, it accepts a bare
+// socket path, a bare "host:port", and a Windows named pipe, e.g.
+// `\\.\pipe\CProgramDataopenvswitchdb.sock`. The "db:" remotes refer to
+// the database of ovsdb-server and cannot be parsed.
+func ParseRemote(s string) (Remote, error) {
+	if strings.HasPrefix(s, pipePrefix) {
+		return Remote{Proto: "pipe", Address: s}, nil
+	}
+	if strings.HasPrefix(s, "/") {
+		return Remote{Proto: "unix", Address: s}, nil
+	}
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return Remote{}, fmt.Errorf("invalid remote %s: no protocol", s)
+	}
+	method, addr := s[:i], s[i+1:]
+	switch method {
+	case "unix", "punix":
+		// The path may contain colons, e.g. a drive letter on Windows.
+		if addr == "" {
+			return Remote{}, fmt.Errorf("invalid remote %s: no path", s)
+		}
+		return Remote{Proto: "unix", Passive: method == "punix", Address: addr}, nil
+	case "tcp", "ssl":
+		host, port, err := splitRemoteAddr(addr)
+		if err != nil {
+			return Remote{}, fmt.Errorf("invalid %s remote %s: %s", method, s, err)
+		}
+		if host == "" {
+			return Remote{}, fmt.Errorf("invalid %s remote %s: no host", method, s)
+		}
+		return Remote{Proto: method, Address: net.JoinHostPort(host, port)}, nil
+	case "ptcp", "pssl":
+		// The port comes first, followed by an optional address, which is
+		// enclosed in brackets when it is an IPv6 one.
+		arr := strings.SplitN(addr, ":", 2)
+		port := arr[0]
+		if port == "" {
+			port = strconv.Itoa(DefaultRemotePort)
+		}
+		if err := checkRemotePort(port); err != nil {
+			return Remote{}, fmt.Errorf("invalid %s remote %s: %s", method, s, err)
+		}
+		host := ""
+		if len(arr) > 1 {
+			host = strings.TrimSuffix(strings.TrimPrefix(arr[1], "["), "]")
+		}
+		return Remote{Proto: method[1:], Passive: true, Address: net.JoinHostPort(host, port)}, nil
+	case "db":
+		return Remote{}, fmt.Errorf("invalid remote %s: the db remotes are only read by ovsdb-server", s)
+	}
+	// A bare "host:port", as accepted by the earlier versions.
+	if _, _, err := net.SplitHostPort(s); err == nil {
+		return Remote{Proto: "tcp", Address: s}, nil
+	}
+	return Remote{}, fmt.Errorf("invalid remote %s: unsupported protocol %s", s, method)
+}
+
+// String returns the remote in the OVS syntax.
+func (r Remote) String() string {
+	switch {
+	case r.Proto == "pipe":
+		return r.Address
+	case r.Proto == "unix" && r.Passive:
+		return "punix:" + r.Address
+	case r.Proto == "unix":
+		return "unix:" + r.Address
+	case r.Passive:
+		host, port, err := net.SplitHostPort(r.Address)
+		if err != nil {
+			return "p" + r.Proto + ":" + r.Address
+		}
+		if host == "" {
+			return "p" + r.Proto + ":" + port
+		}
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		return "p" + r.Proto + ":" + port + ":" + host
+	}
+	return r.Proto + ":" + r.Address
+}
+
+// splitRemoteAddr splits the "host[:port]" part of an active remote,
+// defaulting to DefaultRemotePort.
+func splitRemoteAddr(addr string) (string, string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		// No port, i.e. a host name, an IPv4 address, or an IPv6 address
+		// enclosed in brackets.
+		host = addr
+		if strings.HasPrefix(addr, "[") && strings.HasSuffix(addr, "]") {
+			host = addr[1 : len(addr)-1]
+		} else if strings.Contains(addr, ":") {
+			return "", "", err
+		}
+		port = strconv.Itoa(DefaultRemotePort)
+	}
+	if err := checkRemotePort(port); err != nil {
+		return "", "", err
+	}
+	return host, port, nil
+}
+
+// checkRemotePort checks that the port is a number between 0 and 65535.
+func checkRemotePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("invalid port %s", port)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	for i, test := range []struct {
+		remote     string
+		want       Remote
+		str        string
+		shouldFail bool
+	}{
+		{remote: "unix:/var/run/openvswitch/db.sock", want: Remote{Proto: "unix", Address: "/var/run/openvswitch/db.sock"}},
+		{remote: "/var/run/openvswitch/db.sock", want: Remote{Proto: "unix", Address: "/var/run/openvswitch/db.sock"}, str: "unix:/var/run/openvswitch/db.sock"},
+		{remote: "punix:/var/run/openvswitch/manager.sock", want: Remote{Proto: "unix", Passive: true, Address: "/var/run/openvswitch/manager.sock"}},
+		{remote: "tcp:10.0.0.1:6641", want: Remote{Proto: "tcp", Address: "10.0.0.1:6641"}},
+		{remote: "tcp:10.0.0.1", want: Remote{Proto: "tcp", Address: "10.0.0.1:6640"}, str: "tcp:10.0.0.1:6640"},
+		{remote: "tcp:[::1]:6641", want: Remote{Proto: "tcp", Address: "[::1]:6641"}},
+		{remote: "tcp:[::1]", want: Remote{Proto: "tcp", Address: "[::1]:6640"}, str: "tcp:[::1]:6640"},
+		{remote: "ssl:ovn-central.example.com:6642", want: Remote{Proto: "ssl", Address: "ovn-central.example.com:6642"}},
+		{remote: "ptcp:6640", want: Remote{Proto: "tcp", Passive: true, Address: ":6640"}},
+		{remote: "ptcp:", want: Remote{Proto: "tcp", Passive: true, Address: ":6640"}, str: "ptcp:6640"},
+		{remote: "ptcp:6641:[::1]", want: Remote{Proto: "tcp", Passive: true, Address: "[::1]:6641"}},
+		{remote: "pssl:6642:10.0.0.1", want: Remote{Proto: "ssl", Passive: true, Address: "10.0.0.1:6642"}},
+		{remote: "127.0.0.1:6640", want: Remote{Proto: "tcp", Address: "127.0.0.1:6640"}, str: "tcp:127.0.0.1:6640"},
+		{remote: `\\.\pipe\CProgramDataopenvswitchdb.sock`, want: Remote{Proto: "pipe", Address: `\\.\pipe\CProgramDataopenvswitchdb.sock`}},
+		{remote: "unix:", shouldFail: true},
+		{remote: "tcp:", shouldFail: true},
+		{remote: "tcp::1", shouldFail: true},
+		{remote: "tcp:10.0.0.1:70000", shouldFail: true},
+		{remote: "ptcp:http", shouldFail: true},
+		{remote: "db:Open_vSwitch,Open_vSwitch,manager_options", shouldFail: true},
+		{remote: "udp:10.0.0.1:6640", shouldFail: true},
+		{remote: "db.sock", shouldFail: true},
+	} {
+		r, err := ParseRemote(test.remote)
+		if err != nil {
+			if !test.shouldFail {
+				t.Fatalf("FAIL: Test %d: remote '%s', expected to pass, but failed with: %v", i, test.remote, err)
+			}
+			t.Logf("PASS: Test %d: remote '%s' failed as expected: %v", i, test.remote, err)
+			continue
+		}
+		if test.shouldFail {
+			t.Fatalf("FAIL: Test %d: remote '%s', expected to fail, but passed", i, test.remote)
+		}
+		if r != test.want {
+			t.Fatalf("FAIL: Test %d: remote '%s', expected %+v, but got %+v", i, test.remote, test.want, r)
+		}
+		str := test.str
+		if str == "" {
+			str = test.remote
+		}
+		if r.String() != str {
+			t.Fatalf("FAIL: Test %d: remote '%s', expected string %s, but got %s", i, test.remote, str, r.String())
+		}
+		t.Logf("PASS: Test %d: remote '%s' parsed as %+v", i, test.remote, r)
+	}
+}
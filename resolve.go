@@ -0,0 +1,121 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ResolveReferences follows the reference columns of the rows of the
+// result, or all the reference columns of its table when there are none,
+// and attaches the rows they refer to to the result, see Referenced and
+// Deref. The schema gives the tables referred to. The rows of a table are
+// retrieved in bulk, with a single transaction per table. The references to
+// rows which no longer exist are left unresolved.
+func (c *Client) ResolveReferences(ctx context.Context, schema Schema, r *Result, columns ...string) error {
+	if r.Table == "" {
+		return fmt.Errorf("resolve references: result has no table")
+	}
+	refs := schema.GetRefColumns(r.Table)
+	if len(columns) > 0 {
+		selected := make(map[string]string, len(columns))
+		for _, column := range columns {
+			refTable, exists := refs[column]
+			if !exists {
+				return fmt.Errorf("resolve references: column %s of table %s holds no reference", column, r.Table)
+			}
+			selected[column] = refTable
+		}
+		refs = selected
+	}
+	// The UUIDs referred to, per table.
+	wanted := make(map[string]map[UUID]bool)
+	for _, row := range r.Rows {
+		for column, refTable := range refs {
+			for _, uuid := range rowReferences(row[column]) {
+				if _, done := r.Referenced[uuid]; done {
+					continue
+				}
+				if wanted[refTable] == nil {
+					wanted[refTable] = make(map[UUID]bool)
+				}
+				wanted[refTable][uuid] = true
+			}
+		}
+	}
+	tables := make([]string, 0, len(wanted))
+	for table := range wanted {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	if r.Referenced == nil {
+		r.Referenced = make(map[UUID]Row)
+	}
+	for _, table := range tables {
+		uuids := make([]string, 0, len(wanted[table]))
+		for uuid := range wanted[table] {
+			uuids = append(uuids, string(uuid))
+		}
+		sort.Strings(uuids)
+		ops := make([]Operation, len(uuids))
+		for i, uuid := range uuids {
+			ops[i] = Select(table, nil, Equal("_uuid", UUID(uuid)))
+		}
+		results, err := c.TransactOperations(ctx, r.Database, ops...)
+		if err != nil {
+			return fmt.Errorf("resolve references to %s: %w", table, err)
+		}
+		for i, result := range results {
+			for _, row := range result.Rows {
+				r.Referenced[UUID(uuids[i])] = row
+			}
+		}
+	}
+	return nil
+}
+
+// Deref returns the rows the column of the row refers to, once
+// ResolveReferences retrieved them.
+func (r *Result) Deref(row Row, column string) []Row {
+	var rows []Row
+	for _, uuid := range rowReferences(row[column]) {
+		if ref, exists := r.Referenced[uuid]; exists {
+			rows = append(rows, ref)
+		}
+	}
+	return rows
+}
+
+// rowReferences returns the UUIDs a value refers to: the elements of a
+// set, or the keys and values of a map.
+func rowReferences(data interface{}) []UUID {
+	var elems []interface{}
+	if s, err := newOvsSet(data); err == nil {
+		elems = s
+	} else if m, err := newOvsMap(data); err == nil {
+		for k, v := range m {
+			elems = append(elems, k, v)
+		}
+	}
+	var uuids []UUID
+	for _, elem := range elems {
+		if uuid, ok := elem.(UUID); ok {
+			uuids = append(uuids, uuid)
+		}
+	}
+	return uuids
+}
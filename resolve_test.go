@@ -0,0 +1,128 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+)
+
+const testResolveSchema = `{
+  "name": "OVN_Southbound",
+  "version": "20.21.0",
+  "tables": {
+    "Chassis": {"columns": {
+      "name": {"type": "string"},
+      "encaps": {"type": {"key": {"type": "uuid", "refTable": "Encap"}, "min": 1, "max": "unlimited"}},
+      "other_config": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+    }},
+    "Chassis_Private": {"columns": {
+      "name": {"type": "string"},
+      "chassis": {"type": {"key": {"type": "uuid", "refTable": "Chassis", "refType": "weak"}, "min": 0, "max": 1}}
+    }},
+    "Encap": {"columns": {"ip": {"type": "string"}}}
+  }
+}`
+
+func TestResolveReferences(t *testing.T) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(testResolveSchema), &schema); err != nil {
+		t.Fatalf("FAIL: failed to decode the schema: %v", err)
+	}
+	if refs := schema.GetRefColumns("Chassis"); len(refs) != 1 || refs["encaps"] != "Encap" {
+		t.Fatalf("FAIL: unexpected reference columns: %v", refs)
+	}
+	if refTable := schema.GetRefTable("Chassis_Private", "chassis"); refTable != "Chassis" {
+		t.Fatalf("FAIL: expected Chassis, got %q", refTable)
+	}
+	t.Logf("PASS: found the reference columns")
+
+	encap := func(i int) string { return fmt.Sprintf("00000000-0000-4000-8000-%012d", i) }
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var transactions []string
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		results := []interface{}{}
+		table := ""
+		for _, arg := range args[1:] {
+			var op struct {
+				Table string          `json:"table"`
+				Where [][]interface{} `json:"where"`
+			}
+			json.Unmarshal(arg, &op)
+			table = op.Table
+			uuid := op.Where[0][2].([]interface{})[1].(string)
+			rows := []interface{}{}
+			// The third encap no longer exists.
+			if uuid != encap(3) {
+				rows = append(rows, map[string]interface{}{"_uuid": []interface{}{"uuid", uuid}, "ip": "ip-" + uuid[35:]})
+			}
+			results = append(results, map[string]interface{}{"rows": rows})
+		}
+		mu.Lock()
+		transactions = append(transactions, fmt.Sprintf("%s:%d", table, len(args)-1))
+		mu.Unlock()
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	var r Result
+	data := fmt.Sprintf(`{"rows":[
+		{"name":"hv1","encaps":["set",[["uuid",%q],["uuid",%q]]],"other_config":["map",[]]},
+		{"name":"hv2","encaps":["uuid",%q]},
+		{"name":"hv3","encaps":["set",[["uuid",%q],["uuid",%q]]]}
+	]}`, encap(1), encap(2), encap(2), encap(3), encap(4))
+	json.Unmarshal([]byte(data), &r)
+	r.Database, r.Table = "OVN_Southbound", "Chassis"
+	if err := cli.ResolveReferences(context.Background(), schema, &r); err != nil {
+		t.Fatalf("FAIL: failed to resolve the references: %v", err)
+	}
+	mu.Lock()
+	if fmt.Sprint(transactions) != "[Encap:4]" {
+		t.Fatalf("FAIL: expected a single transaction of 4 selects, got %v", transactions)
+	}
+	mu.Unlock()
+	for i, want := range []string{"[ip-1 ip-2]", "[ip-2]", "[ip-4]"} {
+		var ips []interface{}
+		for _, encap := range r.Deref(r.Rows[i], "encaps") {
+			ips = append(ips, encap["ip"])
+		}
+		if fmt.Sprint(ips) != want {
+			t.Fatalf("FAIL: row %d: expected %s, got %v", i, want, ips)
+		}
+	}
+	t.Logf("PASS: resolved %d references", len(r.Referenced))
+
+	if err := cli.ResolveReferences(context.Background(), schema, &r, "name"); err == nil {
+		t.Fatalf("FAIL: expected an error for a column without references")
+	}
+	t.Logf("PASS: rejected a column without references")
+}
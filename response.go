@@ -32,14 +32,19 @@ type Response struct {
 
 // UnmarshalJSON - TODO
 func (r *Response) UnmarshalJSON(b []byte) error {
+	// The result of a transaction with a single operation is unwrapped.
+	// The results of many operations remain an array.
 	if bytes.HasPrefix(b, []byte(`[{"`)) {
-		b = bytes.TrimLeft(b, "[")
-		b = bytes.TrimRight(b, "]")
+		var results []json.RawMessage
+		if err := json.Unmarshal(b, &results); err == nil && len(results) == 1 {
+			b = results[0]
+		}
 	}
 	if err := json.Unmarshal(b, &r.Result); err != nil {
 		return err
 	}
-	if bytes.HasPrefix(b, []byte(`{"error":`)) {
+	// The members of an error object may come in any order.
+	if bytes.HasPrefix(b, []byte(`{"`)) && bytes.Contains(b, []byte(`"error":`)) {
 		if err := json.Unmarshal(b, &r.Error); err != nil {
 			return err
 		}
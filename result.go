@@ -15,6 +15,8 @@
 package ovsdb
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 	"reflect"
@@ -26,14 +28,125 @@ type Result struct {
 	Database string
 	Table    string
 	Columns  map[string]string
+	// Referenced holds the rows the rows refer to, by UUID, once
+	// ResolveReferences retrieved them.
+	Referenced map[UUID]Row
+	// raw holds the undecoded rows, in the order of Rows.
+	raw []json.RawMessage
+}
+
+// UnmarshalJSON decodes the rows of the result, and keeps them undecoded
+// as well, see Raw.
+func (r *Result) UnmarshalJSON(b []byte) error {
+	var result struct {
+		Rows []json.RawMessage `json:"rows"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return err
+	}
+	var rows []Row
+	if result.Rows != nil {
+		rows = make([]Row, len(result.Rows))
+	}
+	for i, raw := range result.Rows {
+		if err := json.Unmarshal(raw, &rows[i]); err != nil {
+			return err
+		}
+	}
+	r.Rows, r.raw = rows, result.Rows
+	return nil
+}
+
+// Raw returns the rows of the result as the server sent them, in the order
+// of Rows, for the callers decoding some columns on their own. It returns
+// nil for the results not decoded from a response.
+func (r *Result) Raw() []json.RawMessage {
+	if len(r.raw) != len(r.Rows) {
+		return nil
+	}
+	return r.raw
+}
+
+// applyOrder applies the ORDER BY and LIMIT clauses of the operation to the
+// rows of the result, see Operation.Sort.
+func (r *Result) applyOrder(op Operation) {
+	idx := op.sortIndex(r.Rows)
+	rows := make([]Row, len(idx))
+	var raw []json.RawMessage
+	if len(r.raw) == len(r.Rows) {
+		raw = make([]json.RawMessage, len(idx))
+	}
+	for i, k := range idx {
+		rows[i] = r.Rows[k]
+		if raw != nil {
+			raw[i] = r.raw[k]
+		}
+	}
+	r.Rows, r.raw = rows, raw
 }
 
 // Row - TODO
 type Row map[string]interface{}
 
+// decodeRows decodes the rows of the result of an operation one at a time,
+// and passes each of them to fn.
+func decodeRows(b []byte, fn func(Row) error) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok != "rows" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			var row Row
+			if err := dec.Decode(&row); err != nil {
+				return err
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token, which must be the delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %s, but got %v", delim, tok)
+	}
+	return nil
+}
+
 // GetColumnValue - TODO
 func (r *Row) GetColumnValue(column string, columns map[string]string) (interface{}, string, error) {
-	data := (*r)[column]
+	data, exists := (*r)[column]
+	if !exists {
+		return nil, "", fmt.Errorf("column %s: %w", column, ErrColumnNotFound)
+	}
+	if data == nil {
+		return nil, "", fmt.Errorf("Column '%s' contains unsupported data type: null", column)
+	}
 	dataType := reflect.TypeOf(data).Kind().String()
 	switch dataType {
 	case "string":
@@ -130,3 +243,73 @@ func (r *Row) GetColumnValue(column string, columns map[string]string) (interfac
 	}
 	return nil, "", fmt.Errorf("Column '%s' contains unsupported data type: %s, %v", column, dataType, data)
 }
+
+// Get stores the value of the column in the value pointed to by v, with
+// the conversions of Unmarshal, e.g. an integer is decoded from its float64
+// form, and an optional column is stored as its only element, or the zero
+// value when it is empty.
+func (r Row) Get(column string, v interface{}) error {
+	data, exists := r[column]
+	if !exists {
+		return fmt.Errorf("column %s: %w", column, ErrColumnNotFound)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("column %s: expected a pointer, got %T", column, v)
+	}
+	if err := decodeColumn(rv.Elem(), data); err != nil {
+		return fmt.Errorf("column %s: %s", column, err)
+	}
+	return nil
+}
+
+// MustGet is like Get, but panics when the column cannot be stored in v.
+// It is meant for tests and fixtures.
+func (r Row) MustGet(column string, v interface{}) {
+	if err := r.Get(column, v); err != nil {
+		panic(err)
+	}
+}
+
+// GetString returns the value of a string column, or the string of a
+// reference.
+func (r Row) GetString(column string) (string, error) {
+	var s string
+	err := r.Get(column, &s)
+	return s, err
+}
+
+// GetInt returns the value of an integer column.
+func (r Row) GetInt(column string) (int64, error) {
+	var n int64
+	err := r.Get(column, &n)
+	return n, err
+}
+
+// GetBool returns the value of a boolean column.
+func (r Row) GetBool(column string) (bool, error) {
+	var b bool
+	err := r.Get(column, &b)
+	return b, err
+}
+
+// GetUUID returns the value of a reference column, e.g. _uuid.
+func (r Row) GetUUID(column string) (UUID, error) {
+	var u UUID
+	err := r.Get(column, &u)
+	return u, err
+}
+
+// GetStringSet returns the elements of a set of strings, or of references.
+func (r Row) GetStringSet(column string) ([]string, error) {
+	var set []string
+	err := r.Get(column, &set)
+	return set, err
+}
+
+// GetStringMap returns the pairs of a map of strings, e.g. external_ids.
+func (r Row) GetStringMap(column string) (map[string]string, error) {
+	var m map[string]string
+	err := r.Get(column, &m)
+	return m, err
+}
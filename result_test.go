@@ -0,0 +1,111 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRowGetters(t *testing.T) {
+	var row Row
+	data := `{"_uuid":["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"],"name":"br0","ofport":["set",[]],"tag":10,"stp_enable":true,
+		"ports":["set",[["uuid","7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c1"],["uuid","7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c2"]]],"flood_vlans":["set",["a"]],
+		"external_ids":["map",[["owner","ovn"]]]}`
+	if err := json.Unmarshal([]byte(data), &row); err != nil {
+		t.Fatalf("FAIL: failed to decode the row: %v", err)
+	}
+	if v, err := row.GetUUID("_uuid"); err != nil || v != "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1" {
+		t.Fatalf("FAIL: GetUUID returned %q, %v", v, err)
+	}
+	if v, err := row.GetString("name"); err != nil || v != "br0" {
+		t.Fatalf("FAIL: GetString returned %q, %v", v, err)
+	}
+	if v, err := row.GetInt("tag"); err != nil || v != 10 {
+		t.Fatalf("FAIL: GetInt returned %d, %v", v, err)
+	}
+	if v, err := row.GetInt("ofport"); err != nil || v != 0 {
+		t.Fatalf("FAIL: GetInt of an empty optional column returned %d, %v", v, err)
+	}
+	if v, err := row.GetBool("stp_enable"); err != nil || !v {
+		t.Fatalf("FAIL: GetBool returned %v, %v", v, err)
+	}
+	if v, err := row.GetStringSet("ports"); err != nil || !reflect.DeepEqual(v, []string{"7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c1", "7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c2"}) {
+		t.Fatalf("FAIL: GetStringSet returned %v, %v", v, err)
+	}
+	if v, err := row.GetStringSet("flood_vlans"); err != nil || !reflect.DeepEqual(v, []string{"a"}) {
+		t.Fatalf("FAIL: GetStringSet returned %v, %v", v, err)
+	}
+	if v, err := row.GetStringMap("external_ids"); err != nil || v["owner"] != "ovn" {
+		t.Fatalf("FAIL: GetStringMap returned %v, %v", v, err)
+	}
+	t.Logf("PASS: typed getters")
+
+	if _, err := row.GetString("missing"); !errors.Is(err, ErrColumnNotFound) {
+		t.Fatalf("FAIL: expected ErrColumnNotFound, got %v", err)
+	}
+	if _, err := row.GetBool("name"); err == nil {
+		t.Fatalf("FAIL: expected an error for a string column")
+	}
+	t.Logf("PASS: getter errors")
+
+	var tag int
+	row.MustGet("tag", &tag)
+	if tag != 10 {
+		t.Fatalf("FAIL: MustGet stored %d", tag)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("FAIL: expected MustGet to panic")
+		}
+		t.Logf("PASS: MustGet panicked on a missing column")
+	}()
+	row.MustGet("missing", &tag)
+}
+
+func TestResultRaw(t *testing.T) {
+	var r Result
+	data := `{"rows":[{"name":"hv2","nb_cfg":2,"other_config":["map",[["big",12345678901234567890]]]},{"name":"hv1","nb_cfg":1}]}`
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatalf("FAIL: failed to decode the result: %v", err)
+	}
+	raw := r.Raw()
+	if len(raw) != 2 || string(raw[1]) != `{"name":"hv1","nb_cfg":1}` {
+		t.Fatalf("FAIL: unexpected raw rows: %s", raw)
+	}
+	// The raw row keeps the integer which does not fit a float64.
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(raw[0], &row); err != nil || string(row["other_config"]) != `["map",[["big",12345678901234567890]]]` {
+		t.Fatalf("FAIL: failed to decode the raw row: %s, %v", row["other_config"], err)
+	}
+	t.Logf("PASS: %s", raw[0])
+
+	op, err := NewOperation("SELECT * FROM Chassis ORDER BY nb_cfg LIMIT 1")
+	if err != nil {
+		t.Fatalf("FAIL: failed to parse the query: %v", err)
+	}
+	r.applyOrder(op)
+	if len(r.Rows) != 1 || r.Rows[0]["name"] != "hv1" || len(r.Raw()) != 1 || string(r.Raw()[0]) != `{"name":"hv1","nb_cfg":1}` {
+		t.Fatalf("FAIL: expected the raw rows to follow the order of the rows, got %s", r.Raw())
+	}
+	t.Logf("PASS: raw rows sorted along the rows")
+
+	if raw := (&Result{Rows: []Row{{"name": "hv1"}}}).Raw(); raw != nil {
+		t.Fatalf("FAIL: expected no raw rows for a result not decoded, got %s", raw)
+	}
+	t.Logf("PASS: no raw rows for a result not decoded")
+}
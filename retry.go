@@ -0,0 +1,89 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RetryPolicy controls the retries of the read-only transactions of a
+// client which fail with a transient error of the server, e.g. while the
+// members of a clustered database elect a new leader.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries before the transaction fails.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry. The delay
+	// doubles with every subsequent retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the delay between retries.
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to the given fraction of it.
+	Jitter float64
+}
+
+// WithTransactRetry sets the retry policy of the read-only transactions of
+// a client, e.g. the selects of an exporter. The transactions which modify
+// the database are never retried, since they may have been committed.
+func WithTransactRetry(p RetryPolicy) ClientOption {
+	return func(cli *Client) error {
+		if p.MaxRetries < 0 {
+			return fmt.Errorf("invalid transaction retries: %d", p.MaxRetries)
+		}
+		cli.TransactRetry = p
+		return nil
+	}
+}
+
+// transactRetry sends the read-only transaction, and retries it while it
+// fails with a transient error, either of the transaction as a whole or of
+// one of its operations. The cluster member is selected again for every
+// attempt, so that a new leader is picked up.
+func (c *Client) transactRetry(ctx context.Context, t Transaction) (*Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.transactOnce(ctx, t)
+		cause := err
+		if err == nil {
+			cause = transientResult(resp)
+		}
+		// The last response goes to the caller as it is, along with the
+		// errors of its operations.
+		if cause == nil || !IsTransient(cause) || attempt >= c.TransactRetry.MaxRetries {
+			return resp, err
+		}
+		delay := ReconnectPolicy(c.TransactRetry).backoff(attempt + 1)
+		c.logger().Infof("retrying transaction on %s in %s, attempt %d: %v", c.Endpoint, delay, attempt+1, cause)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, contextError(ctx)
+		}
+	}
+}
+
+// transientResult returns the transient error of an operation of the
+// response, if any.
+func transientResult(resp *Response) error {
+	var results []json.RawMessage
+	if err := json.Unmarshal(resp.Result, &results); err != nil {
+		results = []json.RawMessage{resp.Result}
+	}
+	for _, result := range results {
+		if err := operationError(result); err != nil && IsTransient(err) {
+			return err
+		}
+	}
+	return nil
+}
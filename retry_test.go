@@ -0,0 +1,108 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransactRetry(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	calls := 0
+	failures := 0
+	message := "not leader"
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		results := []interface{}{}
+		for range args[1:] {
+			if failures > 0 {
+				results = append(results, map[string]interface{}{"error": message})
+				continue
+			}
+			results = append(results, map[string]interface{}{"rows": []interface{}{}})
+		}
+		failures--
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1, WithTransactRetry(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	reset := func(n int, msg string) {
+		mu.Lock()
+		calls, failures, message = 0, n, msg
+		mu.Unlock()
+	}
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+
+	for _, test := range []struct {
+		failures int
+		message  string
+		ops      []Operation
+		calls    int
+		err      error
+	}{
+		{failures: 2, message: "not leader", ops: []Operation{Select("Chassis", nil)}, calls: 3},
+		{failures: 2, message: "timed out", ops: []Operation{Select("Chassis", nil), Select("Encap", nil)}, calls: 3},
+		{failures: 3, message: "resources exhausted", ops: []Operation{Select("Chassis", nil)}, calls: 3, err: ErrResourcesExhausted},
+		{failures: 3, message: "not leader", ops: []Operation{Select("Chassis", nil), Select("Encap", nil)}, calls: 3, err: ErrNotLeader},
+		{failures: 1, message: "unknown table", ops: []Operation{Select("Chassis", nil)}, calls: 1, err: ErrTableNotFound},
+		{failures: 1, message: "not leader", ops: []Operation{Delete("Chassis")}, calls: 1, err: ErrNotLeader},
+	} {
+		reset(test.failures, test.message)
+		_, err := cli.TransactOperations(context.Background(), "OVN_Southbound", test.ops...)
+		if test.err == nil && err != nil {
+			t.Fatalf("FAIL: %d %q failures: expected to pass, but failed with: %v", test.failures, test.message, err)
+		}
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Fatalf("FAIL: %d %q failures: expected %v, but got: %v", test.failures, test.message, test.err, err)
+		}
+		if count() != test.calls {
+			t.Fatalf("FAIL: %d %q failures: expected %d transactions, but got %d", test.failures, test.message, test.calls, count())
+		}
+		t.Logf("PASS: %d %q failures of %d operations: %d transactions, %v", test.failures, test.message, len(test.ops), count(), err)
+	}
+
+	if !IsTransient(fmt.Errorf("wrapped: %w", &OvsdbError{Message: "timed out"})) || IsTransient(errors.New("timed out")) {
+		t.Fatalf("FAIL: unexpected classification of the errors")
+	}
+	if _, err := NewClient("tcp:"+l.Addr().String(), 1, WithTransactRetry(RetryPolicy{MaxRetries: -1})); err == nil {
+		t.Fatalf("FAIL: expected negative retries to be rejected")
+	}
+	t.Logf("PASS: classified the errors")
+}
@@ -15,11 +15,13 @@
 package ovsdb
 
 import (
-	//"encoding/json"
+	"context"
+	"encoding/json"
 	"fmt"
 	//"github.com/davecgh/go-spew/spew"
 	"reflect"
 	"sort"
+	"time"
 )
 
 // Schema - TODO
@@ -30,7 +32,8 @@ type Schema struct {
 	Version  string           `json:"version"`
 }
 
-// Table - TODO
+// Table is a table of a schema. MaxRows is 0 when the number of rows is not
+// limited.
 type Table struct {
 	Columns map[string]Column `json:"columns"`
 	Indexes []interface{}     `json:"indexes"`
@@ -38,33 +41,329 @@ type Table struct {
 	IsRoot  bool              `json:"isRoot"`
 }
 
-// Column - TODO
+// Column is a column of a table, see RFC 7047, Section 3.2. Type holds
+// the column type as it appears in the schema, and Key, Value, Min and Max
+// its parsed form.
 type Column struct {
 	Type      interface{} `json:"type"`
 	Ephemeral bool        `json:"ephemeral"`
-	Mutable   bool        `json:"mutable"`
+	// Mutable is true, unless the schema says otherwise.
+	Mutable bool     `json:"mutable"`
+	Key     BaseType `json:"-"`
+	// Value is the type of the values of a map, or nil.
+	Value *BaseType `json:"-"`
+	Min   int       `json:"-"`
+	// Max is the maximum number of elements, or Unlimited.
+	Max int `json:"-"`
+}
+
+// Unlimited is the Max of the columns holding any number of elements.
+const Unlimited = -1
+
+// BaseType is the type of the keys, or values, of a column: an atomic
+// type, with the constraints of the schema on its values.
+type BaseType struct {
+	// Type is one of "integer", "real", "boolean", "string" or "uuid".
+	Type string
+	// Enum, when not nil, holds the only values allowed.
+	Enum       []interface{}
+	MinInteger *int64
+	MaxInteger *int64
+	MinReal    *float64
+	MaxReal    *float64
+	MinLength  *int
+	MaxLength  *int
+	// RefTable is the table the UUIDs refer to, and RefType is "strong"
+	// or "weak", or both are empty.
+	RefTable string
+	RefType  string
+}
+
+// UnmarshalJSON decodes the column, and parses its type.
+func (c *Column) UnmarshalJSON(b []byte) error {
+	var col struct {
+		Type      interface{} `json:"type"`
+		Ephemeral bool        `json:"ephemeral"`
+		Mutable   *bool       `json:"mutable"`
+	}
+	if err := json.Unmarshal(b, &col); err != nil {
+		return err
+	}
+	*c = Column{
+		Type:      col.Type,
+		Ephemeral: col.Ephemeral,
+		Mutable:   col.Mutable == nil || *col.Mutable,
+	}
+	return c.parseType()
+}
+
+// parseType parses the type of the column, which is either an atomic type
+// or an object with the key, value, min and max members.
+func (c *Column) parseType() error {
+	c.Min, c.Max = 1, 1
+	t, ok := c.Type.(map[string]interface{})
+	if !ok {
+		return c.Key.parse(c.Type)
+	}
+	if err := c.Key.parse(t["key"]); err != nil {
+		return fmt.Errorf("key: %s", err)
+	}
+	if value, exists := t["value"]; exists {
+		c.Value = &BaseType{}
+		if err := c.Value.parse(value); err != nil {
+			return fmt.Errorf("value: %s", err)
+		}
+	}
+	if v, exists := t["min"]; exists {
+		n, ok := v.(float64)
+		if !ok || n < 0 || n > 1 {
+			return fmt.Errorf("invalid min %v", v)
+		}
+		c.Min = int(n)
+	}
+	switch v := t["max"].(type) {
+	case nil:
+	case float64:
+		if v < 1 || v < float64(c.Min) {
+			return fmt.Errorf("invalid max %v", v)
+		}
+		c.Max = int(v)
+	case string:
+		if v != "unlimited" {
+			return fmt.Errorf("invalid max %q", v)
+		}
+		c.Max = Unlimited
+	default:
+		return fmt.Errorf("invalid max %v", v)
+	}
+	return nil
+}
+
+// parse parses a base type, which is either the name of an atomic type or
+// an object with the type member and the constraints.
+func (bt *BaseType) parse(v interface{}) error {
+	*bt = BaseType{}
+	switch x := v.(type) {
+	case string:
+		bt.Type = x
+	case map[string]interface{}:
+		bt.Type, _ = x["type"].(string)
+		if enum, exists := x["enum"]; exists {
+			if kind, elems := splitValue(enum); kind == "set" {
+				bt.Enum = elems
+			} else {
+				bt.Enum = []interface{}{enum}
+			}
+		}
+		bt.MinInteger = numberMember[int64](x, "minInteger")
+		bt.MaxInteger = numberMember[int64](x, "maxInteger")
+		bt.MinReal = numberMember[float64](x, "minReal")
+		bt.MaxReal = numberMember[float64](x, "maxReal")
+		bt.MinLength = numberMember[int](x, "minLength")
+		bt.MaxLength = numberMember[int](x, "maxLength")
+		bt.RefTable, _ = x["refTable"].(string)
+		if bt.RefTable != "" {
+			bt.RefType = "strong"
+			if refType, ok := x["refType"].(string); ok {
+				bt.RefType = refType
+			}
+		}
+	}
+	switch bt.Type {
+	case "integer", "real", "boolean", "string", "uuid":
+		return nil
+	}
+	return fmt.Errorf("unsupported type %v", v)
+}
+
+// numberMember returns the numeric member of a base type, or nil.
+func numberMember[T int | int64 | float64](m map[string]interface{}, name string) *T {
+	n, ok := m[name].(float64)
+	if !ok {
+		return nil
+	}
+	v := T(n)
+	return &v
+}
+
+// IsMap returns true when the column is a map.
+func (c *Column) IsMap() bool {
+	return c.Value != nil
+}
+
+// IsSet returns true when the column is a set, including an optional
+// value, rather than a scalar or a map.
+func (c *Column) IsSet() bool {
+	return c.Value == nil && !(c.Min == 1 && c.Max == 1)
+}
+
+// IsOptional returns true when the column is a set of at most one element.
+func (c *Column) IsOptional() bool {
+	return c.Value == nil && c.Min == 0 && c.Max == 1
+}
+
+// Table returns the table of the schema, or nil when it does not exist.
+func (sc *Schema) Table(name string) *Table {
+	t, exists := sc.Tables[name]
+	if !exists {
+		return nil
+	}
+	return &t
+}
+
+// Column returns the column of the table, or nil when the table is nil or
+// has no such column. The _uuid and _version columns are UUIDs.
+func (t *Table) Column(name string) *Column {
+	if t == nil {
+		return nil
+	}
+	if name == "_uuid" || name == "_version" {
+		return &Column{Type: "uuid", Key: BaseType{Type: "uuid"}, Min: 1, Max: 1}
+	}
+	c, exists := t.Columns[name]
+	if !exists {
+		return nil
+	}
+	return &c
+}
+
+// NewRow returns a row of the table holding the default values of its
+// columns, to be filled in for an Insert operation: the empty sets and maps
+// of the columns which may be empty, and zeros, empty strings, false, or
+// the all-zero UUID for the others, of the Go types of DumpTable, e.g.
+// map[string]string for external_ids. The defaults are brought within the
+// ranges of the columns, and the default of an enum is its first value, so
+// that the row satisfies the constraints of the schema. The references
+// must be set before the row is inserted.
+func (sc *Schema) NewRow(table string) (map[string]interface{}, error) {
+	t := sc.Table(table)
+	if t == nil {
+		return nil, fmt.Errorf("new row: %w", &tableNotFoundError{table})
+	}
+	row := make(map[string]interface{}, len(t.Columns))
+	for name, c := range t.Columns {
+		row[name] = c.defaultValue()
+	}
+	return row, nil
+}
+
+// defaultValue returns the default value of the column, see Schema.NewRow.
+func (c *Column) defaultValue() interface{} {
+	switch {
+	case c.IsMap():
+		m := reflect.MakeMap(c.goType())
+		if c.Min > 0 {
+			m.SetMapIndex(c.Key.defaultAtom(), c.Value.defaultAtom())
+		}
+		return m.Interface()
+	case c.IsOptional():
+		return nil
+	case c.IsSet():
+		s := reflect.MakeSlice(c.goType(), 0, 1)
+		if c.Min > 0 {
+			s = reflect.Append(s, c.Key.defaultAtom())
+		}
+		return s.Interface()
+	}
+	return c.Key.defaultAtom().Interface()
+}
+
+// defaultAtom returns the default atom of the type, within its range, or
+// the first value of its enum.
+func (bt *BaseType) defaultAtom() reflect.Value {
+	v := reflect.New(bt.goType()).Elem()
+	if len(bt.Enum) > 0 {
+		if err := decodeAtom(v, bt.Enum[0]); err == nil {
+			return v
+		}
+	}
+	switch bt.Type {
+	case "integer":
+		if bt.MinInteger != nil && *bt.MinInteger > 0 {
+			v.SetInt(*bt.MinInteger)
+		} else if bt.MaxInteger != nil && *bt.MaxInteger < 0 {
+			v.SetInt(*bt.MaxInteger)
+		}
+	case "real":
+		if bt.MinReal != nil && *bt.MinReal > 0 {
+			v.SetFloat(*bt.MinReal)
+		} else if bt.MaxReal != nil && *bt.MaxReal < 0 {
+			v.SetFloat(*bt.MaxReal)
+		}
+	case "uuid":
+		v.SetString("00000000-0000-0000-0000-000000000000")
+	}
+	return v
+}
+
+// GetIndexes returns the indexes of the table, see Schema.GetIndexes.
+func (t *Table) GetIndexes() [][]string {
+	var indexes [][]string
+	if t == nil {
+		return indexes
+	}
+	for _, index := range t.Indexes {
+		arr, ok := index.([]interface{})
+		if !ok {
+			continue
+		}
+		var columns []string
+		for _, column := range arr {
+			if s, ok := column.(string); ok {
+				columns = append(columns, s)
+			}
+		}
+		if len(columns) == len(arr) && len(columns) > 0 {
+			indexes = append(indexes, columns)
+		}
+	}
+	return indexes
 }
 
 // GetSchema - TODO
 func (c *Client) GetSchema(s string) (Schema, error) {
-	if _, exists := c.Schemas[s]; exists {
-		return c.Schemas[s], nil
+	return c.GetSchemaContext(context.Background(), s)
+}
+
+// GetSchemaContext is like GetSchema, but honors the context.
+func (c *Client) GetSchemaContext(ctx context.Context, s string) (Schema, error) {
+	c.cacheMux.Lock()
+	if schema, exists := c.Schemas[s]; exists {
+		c.cacheMux.Unlock()
+		return schema, nil
 	}
+	c.cacheMux.Unlock()
+	return c.fetchSchema(ctx, s)
+}
+
+// fetchSchema retrieves the schema of the database from the server, rather
+// than from the schemas of the client, and replaces the one they hold.
+func (c *Client) fetchSchema(ctx context.Context, s string) (Schema, error) {
 	method := "get_schema"
 	js, err := encodeString(s)
 	if err != nil {
 		_ = fmt.Errorf("'%s' method failed: %v", method, err)
 	}
-	response, err := c.query(method, js)
+	response, err := c.queryContext(ctx, method, js)
 	if err != nil {
-		return Schema{}, fmt.Errorf("'%s' method failed for '%s' database: %v", method, s, err)
+		return Schema{}, fmt.Errorf("'%s' method failed for '%s' database: %w", method, s, err)
 	}
 	schema, err := response.GetSchema()
 	if err != nil {
 		return Schema{}, fmt.Errorf("'%s' method failed for '%s' database: %v", method, s, err)
 	}
+	c.cacheMux.Lock()
+	defer c.cacheMux.Unlock()
 	c.Schemas[s] = schema
-	return c.Schemas[s], nil
+	return schema, nil
+}
+
+// GetSchemaWithTimeout is like GetSchema, but fails when the schema is not
+// retrieved within the timeout.
+func (c *Client) GetSchemaWithTimeout(s string, timeout time.Duration) (Schema, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return c.GetSchemaContext(ctx, s)
 }
 
 // GetTables - TODO
@@ -113,7 +412,7 @@ func (sc *Schema) GetColumnType(table, column string) (string, error) {
 	}
 	var columnType string
 	if _, exists := sc.Tables[table]; !exists {
-		return "", fmt.Errorf("Table %s not found", table)
+		return "", &tableNotFoundError{table}
 	}
 	if _, exists := sc.Tables[table].Columns[column]; !exists {
 		return "", fmt.Errorf("Column %s not found in Table %s", column, table)
@@ -180,3 +479,73 @@ func (sc *Schema) GetColumnType(table, column string) (string, error) {
 	}
 	return columnType, nil
 }
+
+// GetRefTable returns the table the column refers to, i.e. the refTable of
+// its keys, or of its values for a map, or "" when it holds no reference.
+func (sc *Schema) GetRefTable(table, column string) string {
+	t, exists := sc.Tables[table]
+	if !exists {
+		return ""
+	}
+	c, exists := t.Columns[column]
+	if !exists {
+		return ""
+	}
+	m, ok := c.Type.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	for _, member := range []string{"key", "value"} {
+		if base, ok := m[member].(map[string]interface{}); ok {
+			if refTable, ok := base["refTable"].(string); ok {
+				return refTable
+			}
+		}
+	}
+	return ""
+}
+
+// GetRefColumns returns the columns of the table which hold references, and
+// the tables they refer to.
+func (sc *Schema) GetRefColumns(table string) map[string]string {
+	refs := make(map[string]string)
+	for _, column := range sc.GetColumns(table) {
+		if refTable := sc.GetRefTable(table, column); refTable != "" {
+			refs[column] = refTable
+		}
+	}
+	return refs
+}
+
+// GetIndexes returns the indexes of the table, i.e. the sets of columns
+// whose values are unique across its rows, see RFC 7047, Section 3.2.
+func (sc *Schema) GetIndexes(table string) [][]string {
+	return sc.Table(table).GetIndexes()
+}
+
+// IsIndex returns true when the columns, in any order, are an index of the
+// table. The _uuid column is an index of every table.
+func (sc *Schema) IsIndex(table string, columns ...string) bool {
+	if _, exists := sc.Tables[table]; !exists {
+		return false
+	}
+	if len(columns) == 1 && columns[0] == "_uuid" {
+		return true
+	}
+	for _, index := range sc.GetIndexes(table) {
+		if len(index) != len(columns) {
+			continue
+		}
+		matched := true
+		for _, column := range columns {
+			if !contains(index, column) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -16,6 +16,9 @@ package ovsdb
 
 import (
 	//"github.com/davecgh/go-spew/spew"
+	"encoding/json"
+	"errors"
+	"reflect"
 	"sort"
 	"testing"
 )
@@ -240,3 +243,139 @@ func TestSchemaTypesAll(t *testing.T) {
 	}
 	t.Logf("PASS: schema.GetTables")
 }
+
+const testModelSchema = `{
+  "name": "Open_vSwitch",
+  "version": "8.3.0",
+  "tables": {
+    "Bridge": {
+      "columns": {
+        "name": {"type": "string", "mutable": false},
+        "ports": {"type": {"key": {"type": "uuid", "refTable": "Port"}, "min": 0, "max": "unlimited"}},
+        "mirrors": {"type": {"key": {"type": "uuid", "refTable": "Mirror", "refType": "weak"}, "min": 0, "max": "unlimited"}},
+        "external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}},
+        "datapath_id": {"type": {"key": "string", "min": 0, "max": 1}, "ephemeral": true},
+        "fail_mode": {"type": {"key": {"type": "string", "enum": ["set", ["standalone", "secure"]]}, "min": 0, "max": 1}},
+        "protocols": {"type": {"key": {"type": "string", "enum": "OpenFlow10"}, "min": 0, "max": "unlimited"}},
+        "flood_vlans": {"type": {"key": {"type": "integer", "minInteger": 0, "maxInteger": 4095}, "min": 0, "max": 4096}}
+      },
+      "indexes": [["name"]],
+      "maxRows": 100,
+      "isRoot": true
+    }
+  }
+}`
+
+func TestSchemaModel(t *testing.T) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(testModelSchema), &schema); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	bridge := schema.Table("Bridge")
+	if bridge == nil || bridge.MaxRows != 100 || !bridge.IsRoot {
+		t.Fatalf("FAIL: unexpected table: %+v", bridge)
+	}
+	if indexes := bridge.GetIndexes(); len(indexes) != 1 || indexes[0][0] != "name" {
+		t.Fatalf("FAIL: unexpected indexes: %v", indexes)
+	}
+
+	name := bridge.Column("name")
+	if name.Mutable || name.Ephemeral || name.Key.Type != "string" || name.IsSet() || name.IsMap() {
+		t.Fatalf("FAIL: unexpected name column: %+v", name)
+	}
+	if c := bridge.Column("datapath_id"); !c.Mutable || !c.Ephemeral || !c.IsOptional() || !c.IsSet() {
+		t.Fatalf("FAIL: unexpected datapath_id column: %+v", c)
+	}
+	ports := bridge.Column("ports")
+	if !ports.IsSet() || ports.IsOptional() || ports.Min != 0 || ports.Max != Unlimited ||
+		ports.Key.Type != "uuid" || ports.Key.RefTable != "Port" || ports.Key.RefType != "strong" {
+		t.Fatalf("FAIL: unexpected ports column: %+v", ports)
+	}
+	if c := bridge.Column("mirrors"); c.Key.RefType != "weak" {
+		t.Fatalf("FAIL: unexpected mirrors column: %+v", c)
+	}
+	ids := bridge.Column("external_ids")
+	if !ids.IsMap() || ids.Key.Type != "string" || ids.Value.Type != "string" {
+		t.Fatalf("FAIL: unexpected external_ids column: %+v", ids)
+	}
+	if c := bridge.Column("fail_mode"); len(c.Key.Enum) != 2 || c.Key.Enum[1] != "secure" {
+		t.Fatalf("FAIL: unexpected fail_mode enum: %v", c.Key.Enum)
+	}
+	if c := bridge.Column("protocols"); len(c.Key.Enum) != 1 || c.Key.Enum[0] != "OpenFlow10" {
+		t.Fatalf("FAIL: unexpected protocols enum: %v", c.Key.Enum)
+	}
+	vlans := bridge.Column("flood_vlans")
+	if vlans.Max != 4096 || vlans.Key.MinInteger == nil || *vlans.Key.MinInteger != 0 || *vlans.Key.MaxInteger != 4095 || vlans.Key.MaxReal != nil {
+		t.Fatalf("FAIL: unexpected flood_vlans column: %+v", vlans)
+	}
+	if c := bridge.Column("_uuid"); c == nil || c.Key.Type != "uuid" {
+		t.Fatalf("FAIL: unexpected _uuid column: %+v", c)
+	}
+	if bridge.Column("unknown") != nil || schema.Table("Unknown").Column("name") != nil {
+		t.Fatalf("FAIL: expected no metadata for the unknown table and column")
+	}
+	t.Logf("PASS: schema model")
+
+	for _, invalid := range []string{
+		`{"type": "blob"}`,
+		`{"type": {"key": "string", "max": 0}}`,
+		`{"type": {"key": "string", "min": 2, "max": 2}}`,
+		`{"type": {"key": "string", "value": {"type": "map"}}}`,
+	} {
+		var c Column
+		if err := json.Unmarshal([]byte(invalid), &c); err == nil {
+			t.Fatalf("FAIL: expected an error for %s", invalid)
+		}
+	}
+	t.Logf("PASS: invalid column types rejected")
+}
+
+func TestSchemaNewRow(t *testing.T) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(testModelSchema), &schema); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	row, err := schema.NewRow("Bridge")
+	if err != nil {
+		t.Fatalf("FAIL: expected a row, but failed with: %v", err)
+	}
+	expected := map[string]interface{}{
+		"name":         "",
+		"ports":        []UUID{},
+		"mirrors":      []UUID{},
+		"external_ids": map[string]string{},
+		"datapath_id":  nil,
+		"fail_mode":    nil,
+		"protocols":    []string{},
+		"flood_vlans":  []int64{},
+	}
+	if !reflect.DeepEqual(row, expected) {
+		t.Fatalf("FAIL: expected row %#v, got %#v", expected, row)
+	}
+	row["external_ids"].(map[string]string)["owner"] = "test"
+	row["ports"] = append(row["ports"].([]UUID), "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c")
+	if err := schema.ValidateOperation(Insert("Bridge", row)); err != nil {
+		t.Fatalf("FAIL: expected a valid insert, but failed with: %v", err)
+	}
+	if _, err := schema.NewRow("Missing"); !errors.Is(err, ErrTableNotFound) {
+		t.Fatalf("FAIL: expected ErrTableNotFound, got: %v", err)
+	}
+	t.Logf("PASS: new row: %v", row)
+
+	for _, name := range BundledSchemas() {
+		schema, err := BundledSchema(name)
+		if err != nil {
+			t.Fatalf("FAIL: expected the %s schema to be bundled, but failed with: %v", name, err)
+		}
+		for _, table := range schema.GetTables() {
+			row, err := schema.NewRow(table)
+			if err != nil {
+				t.Fatalf("FAIL: expected a row of %s, but failed with: %v", table, err)
+			}
+			if err := schema.ValidateOperation(Insert(table, row)); err != nil {
+				t.Fatalf("FAIL: expected the new row of %s.%s to be valid, but failed with: %v", name, table, err)
+			}
+		}
+	}
+	t.Logf("PASS: the new rows of the bundled schemas are valid")
+}
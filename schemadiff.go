@@ -0,0 +1,151 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaDiff is the difference between two schemas, e.g. of two releases
+// of OVN. The tables are named, e.g. "Chassis_Private", and the columns are
+// qualified by their tables, e.g. "Chassis.other_config". The columns of
+// the added and removed tables are not listed.
+type SchemaDiff struct {
+	Name       string
+	OldVersion string
+	NewVersion string
+	// AddedTables are the tables of the new schema only.
+	AddedTables []string
+	// RemovedTables are the tables of the old schema only.
+	RemovedTables []string
+	// AddedColumns are the columns of the tables of both schemas, which
+	// the new schema only has.
+	AddedColumns []string
+	// RemovedColumns are the columns of the tables of both schemas, which
+	// the old schema only has.
+	RemovedColumns []string
+	// ChangedColumns are the columns of both schemas whose types differ.
+	ChangedColumns []ColumnTypeChange
+}
+
+// ColumnTypeChange is the old and the new type of a column, e.g. "boolean"
+// and "optional boolean".
+type ColumnTypeChange struct {
+	Column string
+	Old    string
+	New    string
+}
+
+// DiffSchemas compares an old schema, a, with a new one, b. The columns
+// whose types differ hold other kinds of values, atomic types, or
+// references, see Schema.Compatible; their constraints are not compared.
+// Each list of the difference is sorted.
+func DiffSchemas(a, b Schema) SchemaDiff {
+	d := SchemaDiff{Name: b.Name, OldVersion: a.Version, NewVersion: b.Version}
+	for _, table := range b.GetTables() {
+		if a.Table(table) == nil {
+			d.AddedTables = append(d.AddedTables, table)
+		}
+	}
+	for _, table := range a.GetTables() {
+		to := b.Table(table)
+		if to == nil {
+			d.RemovedTables = append(d.RemovedTables, table)
+			continue
+		}
+		from := a.Table(table)
+		for _, column := range b.GetColumns(table) {
+			if from.Column(column) == nil {
+				d.AddedColumns = append(d.AddedColumns, table+"."+column)
+			}
+		}
+		for _, column := range a.GetColumns(table) {
+			c := to.Column(column)
+			switch {
+			case c == nil:
+				d.RemovedColumns = append(d.RemovedColumns, table+"."+column)
+			case !c.sameType(from.Column(column)):
+				d.ChangedColumns = append(d.ChangedColumns, ColumnTypeChange{
+					Column: table + "." + column,
+					Old:    from.Column(column).typeString(),
+					New:    c.typeString(),
+				})
+			}
+		}
+	}
+	sort.Strings(d.AddedTables)
+	sort.Strings(d.RemovedTables)
+	sort.Strings(d.AddedColumns)
+	sort.Strings(d.RemovedColumns)
+	sort.Slice(d.ChangedColumns, func(i, j int) bool {
+		return d.ChangedColumns[i].Column < d.ChangedColumns[j].Column
+	})
+	return d
+}
+
+// Empty returns true when the schemas have the same tables and columns, of
+// the same types.
+func (d *SchemaDiff) Empty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 &&
+		len(d.AddedColumns) == 0 && len(d.RemovedColumns) == 0 &&
+		len(d.ChangedColumns) == 0
+}
+
+// String returns the difference one change per line, e.g.
+// "+ Chassis_Private" or "~ Port_Binding.up: boolean -> optional boolean".
+func (d *SchemaDiff) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s -> %s\n", d.Name, d.OldVersion, d.NewVersion)
+	for _, table := range d.AddedTables {
+		fmt.Fprintf(&sb, "+ %s\n", table)
+	}
+	for _, table := range d.RemovedTables {
+		fmt.Fprintf(&sb, "- %s\n", table)
+	}
+	for _, column := range d.AddedColumns {
+		fmt.Fprintf(&sb, "+ %s\n", column)
+	}
+	for _, column := range d.RemovedColumns {
+		fmt.Fprintf(&sb, "- %s\n", column)
+	}
+	for _, change := range d.ChangedColumns {
+		fmt.Fprintf(&sb, "~ %s: %s -> %s\n", change.Column, change.Old, change.New)
+	}
+	return sb.String()
+}
+
+// typeString describes the type of the column, e.g. "string",
+// "optional integer", "set of uuid(Port)", or "map of string to string".
+func (c *Column) typeString() string {
+	key := c.Key.typeString()
+	switch {
+	case c.IsMap():
+		return fmt.Sprintf("map of %s to %s", key, c.Value.typeString())
+	case c.IsOptional():
+		return "optional " + key
+	case c.IsSet():
+		return "set of " + key
+	}
+	return key
+}
+
+func (bt *BaseType) typeString() string {
+	if bt.RefTable != "" {
+		return fmt.Sprintf("%s(%s)", bt.Type, bt.RefTable)
+	}
+	return bt.Type
+}
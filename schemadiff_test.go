@@ -0,0 +1,80 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	from := `{"name": "OVN_Southbound", "version": "20.17.0", "tables": {
+		"Chassis": {"columns": {
+			"name": {"type": "string"},
+			"nb_cfg": {"type": {"key": "integer"}},
+			"vtep_logical_switches": {"type": {"key": "string", "min": 0, "max": "unlimited"}}}},
+		"Port_Binding": {"columns": {
+			"logical_port": {"type": "string"},
+			"up": {"type": "boolean"}}},
+		"Gateway_Chassis": {"columns": {"name": {"type": "string"}}}}}`
+	to := `{"name": "OVN_Southbound", "version": "20.21.0", "tables": {
+		"Chassis": {"columns": {
+			"name": {"type": "string"},
+			"nb_cfg": {"type": {"key": "integer"}},
+			"other_config": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}}},
+		"Chassis_Private": {"columns": {"name": {"type": "string"}}},
+		"Port_Binding": {"columns": {
+			"logical_port": {"type": "string"},
+			"up": {"type": {"key": "boolean", "min": 0, "max": 1}}}}}}`
+	var a, b Schema
+	if err := json.Unmarshal([]byte(from), &a); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	if err := json.Unmarshal([]byte(to), &b); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+
+	d := DiffSchemas(a, b)
+	expected := strings.Join([]string{
+		"OVN_Southbound 20.17.0 -> 20.21.0",
+		"+ Chassis_Private",
+		"- Gateway_Chassis",
+		"+ Chassis.other_config",
+		"- Chassis.vtep_logical_switches",
+		"~ Port_Binding.up: boolean -> optional boolean",
+		"",
+	}, "\n")
+	if d.Empty() || d.String() != expected {
+		t.Fatalf("FAIL: expected diff:\n%s\nbut got:\n%s", expected, d.String())
+	}
+	t.Logf("PASS: diff of %s %s and %s", d.Name, d.OldVersion, d.NewVersion)
+
+	if d := DiffSchemas(b, b); !d.Empty() {
+		t.Fatalf("FAIL: expected no differences of a schema with itself, but got:\n%s", d.String())
+	}
+	t.Logf("PASS: no differences of a schema with itself")
+
+	for _, name := range BundledSchemas() {
+		schema, err := BundledSchema(name)
+		if err != nil {
+			t.Fatalf("FAIL: expected the %s schema to be bundled, but failed with: %v", name, err)
+		}
+		if d := DiffSchemas(schema, schema); !d.Empty() {
+			t.Fatalf("FAIL: expected no differences of the bundled %s schema with itself, but got:\n%s", name, d.String())
+		}
+	}
+	t.Logf("PASS: no differences of the bundled schemas with themselves")
+}
@@ -0,0 +1,274 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ServerDatabaseName is the name of the database ovsdb-server uses to
+// report the status of the databases it serves.
+const ServerDatabaseName = "_Server"
+
+// leaderCheckInterval is how long a confirmed leadership is trusted before
+// it is verified again.
+const leaderCheckInterval = 5 * time.Second
+
+// ServerDatabase represents a row of the Database table of the _Server
+// database, i.e. the status of a database from the perspective of the
+// server the client is connected to.
+type ServerDatabase struct {
+	Name string
+	// Model is "standalone", "clustered", or "relay".
+	Model string
+	// Connected is true when the server is connected to its cluster.
+	Connected bool
+	// Leader is true when the server is the leader of the cluster. It is
+	// also true for standalone databases.
+	Leader    bool
+	ClusterID string
+	ServerID  string
+	Index     int64
+}
+
+// IsRelay reports whether the database is served by a relay, i.e. an
+// ovsdb-server started with a "relay:<database>:<source>" argument, which
+// keeps a copy of the database of its source, e.g. the OVN_Southbound
+// cluster, and forwards the transactions to it.
+func (sd ServerDatabase) IsRelay() bool {
+	return sd.Model == "relay"
+}
+
+// ClusterMode selects the members of a clustered database the requests of
+// a client are sent to.
+type ClusterMode int
+
+const (
+	// ClusterAnyMember sends all requests to the member the client is
+	// connected to.
+	ClusterAnyMember ClusterMode = iota
+	// ClusterLeaderWrites sends read-only transactions to the member the
+	// client is connected to, and all other transactions to the leader.
+	ClusterLeaderWrites
+	// ClusterLeaderOnly sends all transactions to the leader. A relay
+	// counts as the leader, as it forwards the transactions to its source.
+	ClusterLeaderOnly
+)
+
+// WithClusterMode sets the cluster mode of a client.
+func WithClusterMode(mode ClusterMode) ClientOption {
+	return func(cli *Client) error {
+		cli.ClusterMode = mode
+		return nil
+	}
+}
+
+// leaderState records when the connection of a client was last confirmed
+// to be to the leader of a database.
+type leaderState struct {
+	conn      uint64
+	checkedAt time.Time
+}
+
+// GetServerDatabase returns the status of a database reported by the
+// server the client is connected to.
+func (c *Client) GetServerDatabase(db string) (ServerDatabase, error) {
+	return c.GetServerDatabaseContext(context.Background(), db)
+}
+
+// GetServerDatabaseContext is like GetServerDatabase, but honors the
+// context.
+func (c *Client) GetServerDatabaseContext(ctx context.Context, db string) (ServerDatabase, error) {
+	sd := ServerDatabase{}
+	query := "SELECT name, model, connected, leader, cid, sid, index FROM Database WHERE name==?"
+	result, err := c.TransactContext(ctx, ServerDatabaseName, query, db)
+	if err != nil {
+		return sd, err
+	}
+	if len(result.Rows) == 0 {
+		return sd, fmt.Errorf("database '%s' not found in %s", db, ServerDatabaseName)
+	}
+	row := result.Rows[0]
+	if r, dt, err := row.GetColumnValue("name", result.Columns); err == nil && dt == "string" {
+		sd.Name = r.(string)
+	}
+	if r, dt, err := row.GetColumnValue("model", result.Columns); err == nil && dt == "string" {
+		sd.Model = r.(string)
+	}
+	if r, dt, err := row.GetColumnValue("connected", result.Columns); err == nil && dt == "bool" {
+		sd.Connected = r.(bool)
+	}
+	if r, dt, err := row.GetColumnValue("leader", result.Columns); err == nil && dt == "bool" {
+		sd.Leader = r.(bool)
+	}
+	// The cid, sid, and index columns are empty for standalone databases.
+	if r, dt, err := row.GetColumnValue("cid", result.Columns); err == nil && dt == "string" {
+		sd.ClusterID = r.(string)
+	}
+	if r, dt, err := row.GetColumnValue("sid", result.Columns); err == nil && dt == "string" {
+		sd.ServerID = r.(string)
+	}
+	if r, dt, err := row.GetColumnValue("index", result.Columns); err == nil && dt == "integer" {
+		sd.Index = r.(int64)
+	}
+	return sd, nil
+}
+
+// IsRelay reports whether the server the client is connected to serves the
+// database as a relay, see ServerDatabase.IsRelay, so that the callers may
+// tell the relays serving the large deployments apart from the members of
+// the cluster.
+func (c *Client) IsRelay(ctx context.Context, db string) (bool, error) {
+	sd, err := c.GetServerDatabaseContext(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	return sd.IsRelay(), nil
+}
+
+// GetLeader returns the remote of the cluster member which is the leader
+// of the database. It queries every member of the cluster with a separate
+// connection and leaves the connection of the client intact.
+func (c *Client) GetLeader(db string) (string, error) {
+	return c.GetLeaderContext(context.Background(), db)
+}
+
+// GetLeaderContext is like GetLeader, but honors the context.
+func (c *Client) GetLeaderContext(ctx context.Context, db string) (string, error) {
+	errMsgs := []string{}
+	for _, remote := range splitRemotes(c.Endpoint) {
+		member, err := NewClientContext(ctx, remote, c.Timeout, c.options...)
+		if err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("%s: %s", remote, err))
+			continue
+		}
+		member.MaxRetries = 0
+		sd, err := member.GetServerDatabaseContext(ctx, db)
+		member.Close()
+		if err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("%s: %s", remote, err))
+			continue
+		}
+		if sd.Leader {
+			return remote, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if len(errMsgs) > 0 {
+		return "", fmt.Errorf("no leader found for '%s' database: %s", db, errMsgs)
+	}
+	return "", fmt.Errorf("no leader found for '%s' database", db)
+}
+
+// clusterTarget returns the client the transaction should be sent with,
+// according to the cluster mode of the client.
+func (c *Client) clusterTarget(ctx context.Context, t Transaction) (*Client, error) {
+	if t.Database == ServerDatabaseName {
+		return c, nil
+	}
+	switch c.ClusterMode {
+	case ClusterLeaderOnly:
+		if err := c.ensureLeader(ctx, t.Database); err != nil {
+			return nil, err
+		}
+		return c, nil
+	case ClusterLeaderWrites:
+		if t.readOnly() && !t.leader {
+			return c, nil
+		}
+		// The leader is dialed under leaderMux, rather than cacheMux,
+		// so that a slow dial does not hold up the reads of the schemas
+		// by the other requests.
+		c.leaderMux.Lock()
+		c.cacheMux.Lock()
+		leader := c.leader
+		c.cacheMux.Unlock()
+		if leader == nil {
+			opts := append([]ClientOption{}, c.options...)
+			opts = append(opts, WithClusterMode(ClusterLeaderOnly))
+			cli, err := NewClientContext(ctx, c.Endpoint, c.Timeout, opts...)
+			if err != nil && ctx.Err() != nil {
+				c.leaderMux.Unlock()
+				return nil, err
+			}
+			// A failed connection is re-established by the next request.
+			leader = &cli
+			c.cacheMux.Lock()
+			c.leader = leader
+			c.cacheMux.Unlock()
+		}
+		c.leaderMux.Unlock()
+		if err := leader.ensureLeader(ctx, t.Database); err != nil {
+			return nil, err
+		}
+		return leader, nil
+	}
+	return c, nil
+}
+
+// ensureLeader makes sure the client is connected to the leader of the
+// database. When it is not, the client switches to the leader.
+func (c *Client) ensureLeader(ctx context.Context, db string) error {
+	if len(c.remotes) < 2 {
+		return nil
+	}
+	c.mux.Lock()
+	conn, closed := c.connID, c.closed
+	c.mux.Unlock()
+	c.cacheMux.Lock()
+	state, exists := c.leaders[db]
+	c.cacheMux.Unlock()
+	if exists && !closed && state.conn == conn && time.Since(state.checkedAt) < leaderCheckInterval {
+		return nil
+	}
+	for i := 0; i < 2; i++ {
+		sd, err := c.GetServerDatabaseContext(ctx, db)
+		if err != nil {
+			return err
+		}
+		if sd.Leader || sd.IsRelay() {
+			c.mux.Lock()
+			conn := c.connID
+			c.mux.Unlock()
+			c.cacheMux.Lock()
+			if c.leaders == nil {
+				c.leaders = make(map[string]leaderState)
+			}
+			c.leaders[db] = leaderState{conn: conn, checkedAt: time.Now()}
+			c.cacheMux.Unlock()
+			return nil
+		}
+		if i > 0 {
+			break
+		}
+		remote, err := c.GetLeaderContext(ctx, db)
+		if err != nil {
+			return err
+		}
+		c.mux.Lock()
+		c.disconnect()
+		for j, r := range c.remotes {
+			if r == remote {
+				c.remote = j
+			}
+		}
+		c.mux.Unlock()
+	}
+	return fmt.Errorf("the leader of '%s' database changed while switching to it", db)
+}
@@ -0,0 +1,259 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testServerSchema = `{
+  "name": "_Server",
+  "version": "1.2.0",
+  "tables": {
+    "Database": {
+      "columns": {
+        "name": {"type": "string"},
+        "model": {"type": {"key": {"type": "string", "enum": ["set", ["standalone", "clustered", "relay"]]}}},
+        "connected": {"type": "boolean"},
+        "leader": {"type": "boolean"},
+        "schema": {"type": {"key": {"type": "string"}, "min": 0, "max": 1}},
+        "cid": {"type": {"key": {"type": "uuid"}, "min": 0, "max": 1}},
+        "sid": {"type": {"key": {"type": "uuid"}, "min": 0, "max": 1}},
+        "index": {"type": {"key": {"type": "integer"}, "min": 0, "max": 1}}
+      },
+      "isRoot": true
+    }
+  }
+}`
+
+const testSouthboundSchema = `{
+  "name": "OVN_Southbound",
+  "version": "20.0.0",
+  "tables": {
+    "Chassis": {
+      "columns": {
+        "name": {"type": "string"}
+      },
+      "isRoot": true
+    }
+  }
+}`
+
+// clusterMember is a fake member of a clustered OVN_Southbound database.
+type clusterMember struct {
+	mux    sync.Mutex
+	leader bool
+	sid    string
+	// model is the model of the database, "clustered" by default.
+	model string
+	// transactions are the tables of the transactions received by the
+	// member, except for the ones of the _Server database.
+	transactions []string
+}
+
+func (m *clusterMember) handle(method string, params json.RawMessage) interface{} {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	var args []json.RawMessage
+	json.Unmarshal(params, &args)
+	var db string
+	if len(args) > 0 {
+		json.Unmarshal(args[0], &db)
+	}
+	switch method {
+	case "get_schema":
+		if db == ServerDatabaseName {
+			return json.RawMessage(testServerSchema)
+		}
+		return json.RawMessage(testSouthboundSchema)
+	case "transact":
+		if db == ServerDatabaseName {
+			model := m.model
+			if model == "" {
+				model = "clustered"
+			}
+			row := map[string]interface{}{
+				"name":      "OVN_Southbound",
+				"model":     model,
+				"connected": true,
+				"leader":    m.leader,
+				"cid":       []interface{}{"uuid", "8e1a2c16-3b5e-4a1e-9d2c-1f7a0d1c5b11"},
+				"sid":       []interface{}{"uuid", m.sid},
+				"index":     42,
+			}
+			return []interface{}{map[string]interface{}{"rows": []interface{}{row}}}
+		}
+		var op struct {
+			Table string `json:"table"`
+		}
+		if len(args) > 1 {
+			json.Unmarshal(args[1], &op)
+		}
+		m.transactions = append(m.transactions, op.Table)
+		return []interface{}{map[string]interface{}{"rows": []interface{}{}}}
+	}
+	return nil
+}
+
+// newTestCluster starts the fake members of a cluster and returns their
+// remotes. The member at index leader is the leader of the cluster.
+func newTestCluster(t *testing.T, size, leader int) ([]*clusterMember, []string) {
+	dir := t.TempDir()
+	members := []*clusterMember{}
+	remotes := []string{}
+	for i := 0; i < size; i++ {
+		sock := filepath.Join(dir, "member"+string(rune('1'+i))+".sock")
+		l, err := net.Listen("unix", sock)
+		if err != nil {
+			t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+		}
+		m := &clusterMember{leader: i == leader, sid: "5d9b3e7a-0c4f-4b8e-a1d2-00000000000" + string(rune('1'+i))}
+		newTestServer(t, l, m.handle)
+		members = append(members, m)
+		remotes = append(remotes, "unix:"+sock)
+	}
+	return members, remotes
+}
+
+func TestGetServerDatabase(t *testing.T) {
+	_, remotes := newTestCluster(t, 1, 0)
+	cli, err := NewClient(remotes[0], 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	sd, err := cli.GetServerDatabase("OVN_Southbound")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if sd.Name != "OVN_Southbound" || sd.Model != "clustered" || !sd.Connected || !sd.Leader {
+		t.Fatalf("FAIL: unexpected database status: %+v", sd)
+	}
+	if sd.ServerID != "5d9b3e7a-0c4f-4b8e-a1d2-000000000001" || sd.Index != 42 {
+		t.Fatalf("FAIL: unexpected database status: %+v", sd)
+	}
+	t.Logf("PASS: database status: %+v", sd)
+}
+
+func TestGetLeader(t *testing.T) {
+	_, remotes := newTestCluster(t, 3, 2)
+	cli, err := NewClient(strings.Join(remotes, ","), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	leader, err := cli.GetLeader("OVN_Southbound")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if leader != remotes[2] {
+		t.Fatalf("FAIL: expected leader %s, but got %s", remotes[2], leader)
+	}
+	if cli.Remote() != remotes[0] {
+		t.Fatalf("FAIL: expected the client to stay connected to %s, but connected to %s", remotes[0], cli.Remote())
+	}
+	t.Logf("PASS: found leader %s", leader)
+}
+
+func TestClientClusterMode(t *testing.T) {
+	for i, test := range []struct {
+		mode ClusterMode
+		// reads and writes are the indexes of the members expected to
+		// receive read-only and other transactions.
+		reads  int
+		writes int
+	}{
+		{mode: ClusterAnyMember, reads: 0, writes: 0},
+		{mode: ClusterLeaderWrites, reads: 0, writes: 1},
+		{mode: ClusterLeaderOnly, reads: 1, writes: 1},
+	} {
+		members, remotes := newTestCluster(t, 2, 1)
+		cli, err := NewClient(strings.Join(remotes, ","), 1, WithClusterMode(test.mode))
+		if err != nil {
+			t.Fatalf("FAIL: Test %d: expected to connect, but failed with: %v", i, err)
+		}
+		if _, err := cli.Transact("OVN_Southbound", "SELECT * FROM Chassis"); err != nil {
+			t.Fatalf("FAIL: Test %d: %v", i, err)
+		}
+		write := Transaction{
+			Database:   "OVN_Southbound",
+			Operations: []Operation{{Name: "delete", Table: "Chassis", Conditions: []Condition{}}},
+		}
+		if _, err := cli.transact(context.Background(), write); err != nil {
+			t.Fatalf("FAIL: Test %d: %v", i, err)
+		}
+		cli.Close()
+		got := [][]string{}
+		for _, m := range members {
+			m.mux.Lock()
+			got = append(got, m.transactions)
+			m.mux.Unlock()
+		}
+		expected := [][]string{{}, {}}
+		expected[test.reads] = append(expected[test.reads], "Chassis")
+		expected[test.writes] = append(expected[test.writes], "Chassis")
+		for j := range members {
+			if len(got[j]) != len(expected[j]) {
+				t.Fatalf("FAIL: Test %d: expected member %d to receive %d transactions, but received %d", i, j, len(expected[j]), len(got[j]))
+			}
+		}
+		t.Logf("PASS: Test %d: transactions were routed as expected: %v", i, got)
+	}
+}
+
+func TestClientRelay(t *testing.T) {
+	members, remotes := newTestCluster(t, 2, -1)
+	for _, m := range members {
+		m.model = "relay"
+	}
+	cli, err := NewClient(strings.Join(remotes, ","), 1, WithClusterMode(ClusterLeaderOnly))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	relay, err := cli.IsRelay(context.Background(), "OVN_Southbound")
+	if err != nil || !relay {
+		t.Fatalf("FAIL: expected the server to be a relay, got %v, %v", relay, err)
+	}
+	if _, err := cli.Transact("OVN_Southbound", "SELECT * FROM Chassis"); err != nil {
+		t.Fatalf("FAIL: expected the relay to serve the transaction, but failed with: %v", err)
+	}
+	members[0].mux.Lock()
+	transactions := members[0].transactions
+	members[0].mux.Unlock()
+	if len(transactions) != 1 {
+		t.Fatalf("FAIL: expected the relay connected to to receive the transaction, got %v", transactions)
+	}
+	t.Logf("PASS: transaction sent to the relay %s", cli.Remote())
+
+	client := NewOvnClient()
+	client.Database.Northbound.Socket.Remote = remotes[1]
+	client.Database.Southbound.Socket.Remote = "unix:/nonexistent/ovnsb_db.sock"
+	client.Database.Southbound.Socket.Relay = remotes[1]
+	if err := client.Connect(); err != nil {
+		t.Fatalf("FAIL: expected to connect to the relay, but failed with: %v", err)
+	}
+	defer client.Close()
+	if relay, err := client.Database.Southbound.IsRelay(context.Background()); err != nil || !relay {
+		t.Fatalf("FAIL: expected the southbound database to be served by a relay, got %v, %v", relay, err)
+	}
+	t.Logf("PASS: OVN client connected to the southbound relay %s", client.Database.Southbound.Client.Remote())
+}
@@ -0,0 +1,264 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// OvsSet is a set value, whose elements keep their OVSDB types: the
+// integers are int64, the reals float64, the booleans bool, the strings
+// string, and the references UUID or NamedUUID. It encodes as
+// ["set", [...]], and decodes from a set, or from the single atom that
+// stands for a set of one element.
+type OvsSet []interface{}
+
+// OvsMap is a map value, whose keys and values keep their OVSDB types, see
+// OvsSet. It encodes as ["map", [[key, value], ...]].
+type OvsMap map[interface{}]interface{}
+
+// MarshalJSON encodes the set in the OVSDB notation.
+func (s OvsSet) MarshalJSON() ([]byte, error) {
+	v, err := encodeValue([]interface{}(s))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes the set from the OVSDB notation.
+func (s *OvsSet) UnmarshalJSON(b []byte) error {
+	data, err := decodeNumbers(b)
+	if err != nil {
+		return err
+	}
+	set, err := newOvsSet(data)
+	if err != nil {
+		return err
+	}
+	*s = set
+	return nil
+}
+
+// Decode stores the elements of the set in the slice pointed to by v, e.g.
+// a *[]int for the trunks of a port.
+func (s OvsSet) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("expected a pointer to a slice, got %T", v)
+	}
+	out := reflect.MakeSlice(rv.Elem().Type(), len(s), len(s))
+	for i, elem := range s {
+		if err := decodeAtom(out.Index(i), elem); err != nil {
+			return err
+		}
+	}
+	rv.Elem().Set(out)
+	return nil
+}
+
+// Strings returns the elements of a set of strings, or of references.
+func (s OvsSet) Strings() ([]string, error) {
+	var out []string
+	err := s.Decode(&out)
+	return out, err
+}
+
+// Ints returns the elements of a set of integers.
+func (s OvsSet) Ints() ([]int64, error) {
+	var out []int64
+	err := s.Decode(&out)
+	return out, err
+}
+
+// UUIDs returns the elements of a set of references.
+func (s OvsSet) UUIDs() ([]UUID, error) {
+	var out []UUID
+	err := s.Decode(&out)
+	return out, err
+}
+
+// MarshalJSON encodes the map in the OVSDB notation.
+func (m OvsMap) MarshalJSON() ([]byte, error) {
+	v, err := encodeValue(map[interface{}]interface{}(m))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes the map from the OVSDB notation.
+func (m *OvsMap) UnmarshalJSON(b []byte) error {
+	data, err := decodeNumbers(b)
+	if err != nil {
+		return err
+	}
+	out, err := newOvsMap(data)
+	if err != nil {
+		return err
+	}
+	*m = out
+	return nil
+}
+
+// Decode stores the pairs of the map in the map pointed to by v, e.g. a
+// *map[string]int.
+func (m OvsMap) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("expected a pointer to a map, got %T", v)
+	}
+	t := rv.Elem().Type()
+	out := reflect.MakeMapWithSize(t, len(m))
+	for key, value := range m {
+		k := reflect.New(t.Key()).Elem()
+		if err := decodeAtom(k, key); err != nil {
+			return err
+		}
+		v := reflect.New(t.Elem()).Elem()
+		if err := decodeAtom(v, value); err != nil {
+			return err
+		}
+		out.SetMapIndex(k, v)
+	}
+	rv.Elem().Set(out)
+	return nil
+}
+
+// StringMap returns the pairs of a map of strings, e.g. external_ids.
+func (m OvsMap) StringMap() (map[string]string, error) {
+	var out map[string]string
+	err := m.Decode(&out)
+	return out, err
+}
+
+// GetSet returns the elements of a set column, or of an atom column as a
+// set of one element.
+func (r Row) GetSet(column string) (OvsSet, error) {
+	data, exists := r[column]
+	if !exists {
+		return nil, fmt.Errorf("column %s: %w", column, ErrColumnNotFound)
+	}
+	s, err := newOvsSet(data)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %s", column, err)
+	}
+	return s, nil
+}
+
+// GetMap returns the pairs of a map column.
+func (r Row) GetMap(column string) (OvsMap, error) {
+	data, exists := r[column]
+	if !exists {
+		return nil, fmt.Errorf("column %s: %w", column, ErrColumnNotFound)
+	}
+	m, err := newOvsMap(data)
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %s", column, err)
+	}
+	return m, nil
+}
+
+// newOvsSet returns the set of a decoded value.
+func newOvsSet(data interface{}) (OvsSet, error) {
+	kind, elems := splitValue(data)
+	if kind == "map" {
+		return nil, fmt.Errorf("cannot store a map in a set")
+	}
+	s := make(OvsSet, len(elems))
+	for i, elem := range elems {
+		atom, err := typedAtom(elem)
+		if err != nil {
+			return nil, err
+		}
+		s[i] = atom
+	}
+	return s, nil
+}
+
+// newOvsMap returns the map of a decoded value. The empty set stands for
+// the empty map.
+func newOvsMap(data interface{}) (OvsMap, error) {
+	kind, elems := splitValue(data)
+	if kind != "map" && !(kind == "set" && len(elems) == 0) {
+		return nil, fmt.Errorf("cannot store a %s in a map", kind)
+	}
+	m := make(OvsMap, len(elems))
+	for _, elem := range elems {
+		pair, ok := elem.([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, fmt.Errorf("invalid map pair %v", elem)
+		}
+		key, err := typedAtom(pair[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := typedAtom(pair[1])
+		if err != nil {
+			return nil, err
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+// typedAtom returns the atom of a decoded value with its OVSDB type. The
+// numbers decoded with json.Number keep their type; the float64 numbers of
+// a row, which lost it, are integers when they have no fraction.
+func typedAtom(data interface{}) (interface{}, error) {
+	switch x := data.(type) {
+	case string, bool:
+		return x, nil
+	case json.Number:
+		if strings.ContainsAny(x.String(), ".eE") {
+			return x.Float64()
+		}
+		return x.Int64()
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+			return int64(x), nil
+		}
+		return x, nil
+	case []interface{}:
+		if len(x) == 2 {
+			if s, ok := x[1].(string); ok {
+				switch x[0] {
+				case "uuid":
+					return UUID(s), nil
+				case "named-uuid":
+					return NamedUUID(s), nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("invalid atom %v", data)
+}
+
+// decodeNumbers decodes the JSON value, keeping the numbers as
+// json.Number.
+func decodeNumbers(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOvsSet(t *testing.T) {
+	testCases := []struct {
+		data string
+		want OvsSet
+	}{
+		{`["set",[10,20]]`, OvsSet{int64(10), int64(20)}},
+		{`["set",[1.5,2.0]]`, OvsSet{1.5, 2.0}},
+		{`["set",[true]]`, OvsSet{true}},
+		{`["set",[["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]]`, OvsSet{UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8")}},
+		{`"br0"`, OvsSet{"br0"}},
+		{`["set",[]]`, OvsSet{}},
+	}
+	for _, test := range testCases {
+		var s OvsSet
+		if err := json.Unmarshal([]byte(test.data), &s); err != nil {
+			t.Fatalf("FAIL: failed to unmarshal %s: %v", test.data, err)
+		}
+		if !reflect.DeepEqual(s, test.want) {
+			t.Fatalf("FAIL: %s: expected %#v, got %#v", test.data, test.want, s)
+		}
+		t.Logf("PASS: %s: %#v", test.data, s)
+	}
+
+	var s OvsSet
+	if err := json.Unmarshal([]byte(`["map",[]]`), &s); err == nil {
+		t.Fatalf("FAIL: expected an error for a map")
+	}
+
+	b, err := json.Marshal(OvsSet{int64(10), "a", UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8")})
+	expected := `["set",[10,"a",["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]]`
+	if err != nil || string(b) != expected {
+		t.Fatalf("FAIL: expected %s, got %s, %v", expected, b, err)
+	}
+	t.Logf("PASS: %s", b)
+
+	var trunks []int
+	if err := (OvsSet{int64(10), int64(20)}).Decode(&trunks); err != nil || !reflect.DeepEqual(trunks, []int{10, 20}) {
+		t.Fatalf("FAIL: failed to decode the trunks: %v, %v", trunks, err)
+	}
+	if _, err := (OvsSet{int64(10)}).Strings(); err == nil {
+		t.Fatalf("FAIL: expected an error for an integer set as strings")
+	}
+	t.Logf("PASS: converted the sets")
+}
+
+func TestOvsMap(t *testing.T) {
+	var m OvsMap
+	if err := json.Unmarshal([]byte(`["map",[["owner","ovn"],["priority",100],["enabled",true]]]`), &m); err != nil {
+		t.Fatalf("FAIL: failed to unmarshal the map: %v", err)
+	}
+	want := OvsMap{"owner": "ovn", "priority": int64(100), "enabled": true}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("FAIL: expected %#v, got %#v", want, m)
+	}
+	t.Logf("PASS: %#v", m)
+
+	if _, err := m.StringMap(); err == nil {
+		t.Fatalf("FAIL: expected an error for a mixed map as strings")
+	}
+	var ids map[string]int
+	if err := (OvsMap{"a": int64(1), "b": int64(2)}).Decode(&ids); err != nil || ids["b"] != 2 {
+		t.Fatalf("FAIL: failed to decode the map: %v, %v", ids, err)
+	}
+	t.Logf("PASS: converted the maps")
+
+	b, err := json.Marshal(OvsMap{"b": int64(2), "a": "x"})
+	expected := `["map",[["a","x"],["b",2]]]`
+	if err != nil || string(b) != expected {
+		t.Fatalf("FAIL: expected %s, got %s, %v", expected, b, err)
+	}
+	t.Logf("PASS: %s", b)
+}
+
+func TestRowGetSetAndMap(t *testing.T) {
+	var row Row
+	json.Unmarshal([]byte(`{"trunks":["set",[100,200]],"tag":5,"other_config":["map",[["stp-priority",["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]]],"options":["set",[]]}`), &row)
+	trunks, err := row.GetSet("trunks")
+	if err != nil || !reflect.DeepEqual(trunks, OvsSet{int64(100), int64(200)}) {
+		t.Fatalf("FAIL: GetSet returned %#v, %v", trunks, err)
+	}
+	if tag, err := row.GetSet("tag"); err != nil || !reflect.DeepEqual(tag, OvsSet{int64(5)}) {
+		t.Fatalf("FAIL: GetSet of an atom returned %#v, %v", tag, err)
+	}
+	config, err := row.GetMap("other_config")
+	if err != nil || config["stp-priority"] != UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8") {
+		t.Fatalf("FAIL: GetMap returned %#v, %v", config, err)
+	}
+	if options, err := row.GetMap("options"); err != nil || len(options) != 0 {
+		t.Fatalf("FAIL: GetMap of an empty map returned %#v, %v", options, err)
+	}
+	t.Logf("PASS: typed sets and maps of a row")
+
+	var port struct {
+		Trunks OvsSet `ovsdb:"trunks"`
+		Config OvsMap `ovsdb:"other_config"`
+	}
+	if err := row.Unmarshal(&port); err != nil || !reflect.DeepEqual(port.Trunks, trunks) || len(port.Config) != 1 {
+		t.Fatalf("FAIL: failed to unmarshal the typed sets and maps: %+v, %v", port, err)
+	}
+	t.Logf("PASS: unmarshaled the typed sets and maps")
+}
@@ -0,0 +1,53 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// WithSocketCheck makes the client check the unix sockets it connects to
+// with CheckSocket, so that a lack of permissions is reported along with
+// the owner of the socket rather than as a bare EACCES. Among the Options
+// of an OvsClient or of an OvnClient, it applies to the control sockets of
+// the daemons as well.
+func WithSocketCheck() ClientOption {
+	return func(cli *Client) error {
+		cli.SocketCheck = true
+		return nil
+	}
+}
+
+// CheckSocket verifies that the unix socket exists and that the process
+// has the permissions to connect to it, e.g. a database or a control
+// socket. The errors wrap fs.ErrNotExist or fs.ErrPermission.
+func CheckSocket(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("socket %s does not exist, is the daemon running? %w", path, fs.ErrNotExist)
+		}
+		if os.IsPermission(err) {
+			return fmt.Errorf("socket %s is not accessible, check the permissions of its directory: %w", path, fs.ErrPermission)
+		}
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s is not a socket", path)
+	}
+	return checkSocketOwner(path, info)
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSocket(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	defer l.Close()
+	file := filepath.Join(dir, "conf.db")
+	if err := os.WriteFile(file, []byte{}, 0600); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	if err := CheckSocket(sock); err != nil {
+		t.Fatalf("FAIL: expected socket %s to pass the check, but failed with: %v", sock, err)
+	}
+	if err := CheckSocket(filepath.Join(dir, "missing.sock")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("FAIL: expected not exist error, but got: %v", err)
+	}
+	if err := CheckSocket(file); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("FAIL: expected not a socket error, but got: %v", err)
+	}
+	t.Logf("PASS: socket checks")
+
+	if os.Geteuid() == 0 {
+		t.Logf("PASS: skipping permission checks, running as root")
+		return
+	}
+	if err := os.Chmod(sock, 0); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	err = CheckSocket(sock)
+	if !errors.Is(err, fs.ErrPermission) || !strings.Contains(err.Error(), "owned by") {
+		t.Fatalf("FAIL: expected permission error, but got: %v", err)
+	}
+	_, err = NewClient("unix:"+sock, 1, WithSocketCheck())
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("FAIL: expected permission error from the client, but got: %v", err)
+	}
+	t.Logf("PASS: permission error: %v", err)
+}
+
+func TestControlSocketCheck(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ovnnb_db.ctl")
+	if err := os.WriteFile(file, []byte{}, 0600); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	cli := NewOvnClient()
+	cli.Database.Northbound.Socket.Control = "unix:" + file
+	cli.Options = []ClientOption{WithSocketCheck()}
+	_, err := cli.GetAppMemoryMetricsContext(context.Background(), "ovsdb-server-northbound")
+	if err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("FAIL: expected the control socket to fail the check, but got: %v", err)
+	}
+	t.Logf("PASS: control socket check: %v", err)
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package ovsdb
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// checkSocketOwner verifies that the process may write to the socket,
+// which connecting to it requires.
+func checkSocketOwner(path string, info os.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	uid := os.Geteuid()
+	if uid == 0 {
+		return nil
+	}
+	perm := info.Mode().Perm()
+	switch {
+	case int(st.Uid) == uid:
+		if perm&0200 != 0 {
+			return nil
+		}
+	case inGroup(int(st.Gid)):
+		if perm&0020 != 0 {
+			return nil
+		}
+	default:
+		if perm&0002 != 0 {
+			return nil
+		}
+	}
+	owner := strconv.Itoa(int(st.Uid))
+	if u, err := user.LookupId(owner); err == nil {
+		owner = u.Username
+	}
+	group := strconv.Itoa(int(st.Gid))
+	if g, err := user.LookupGroupId(group); err == nil {
+		group = g.Name
+	}
+	if int(st.Uid) == uid {
+		return fmt.Errorf("socket %s is owned by %s:%s with mode %s, which does not allow the owner to connect: %w",
+			path, owner, group, info.Mode(), fs.ErrPermission)
+	}
+	return fmt.Errorf("socket %s is owned by %s:%s with mode %s, run as %s or as a member of the %s group: %w",
+		path, owner, group, info.Mode(), owner, group, fs.ErrPermission)
+}
+
+// inGroup returns true when the process is a member of the group.
+func inGroup(gid int) bool {
+	if os.Getegid() == gid {
+		return true
+	}
+	groups, err := os.Getgroups()
+	if err != nil {
+		return false
+	}
+	for _, g := range groups {
+		if g == gid {
+			return true
+		}
+	}
+	return false
+}
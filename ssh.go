@@ -0,0 +1,180 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHConfig configures tunneling the connections of a client through SSH,
+// e.g. to collect from hypervisors reachable only over SSH. The client logs
+// in to the SSH server and connects to its remote, e.g.
+// "unix:/var/run/openvswitch/db.sock", from there.
+type SSHConfig struct {
+	// Address is the address of the SSH server, e.g. "10.0.0.1:22". The
+	// port defaults to 22.
+	Address string
+	User    string
+	// PrivateKey is the PEM encoded private key of the user.
+	PrivateKey []byte
+	// Passphrase decrypts the private key, when it is encrypted.
+	Passphrase []byte
+	// HostKeyCallback verifies the host key of the server. When it is not
+	// set, the key must be in one of the KnownHosts files.
+	HostKeyCallback ssh.HostKeyCallback
+	// KnownHosts are the OpenSSH known_hosts files with the host keys.
+	KnownHosts []string
+	// Jump, when set, is the jump host the server is reached through.
+	Jump *SSHConfig
+}
+
+// WithSSH makes a client tunnel its connections through SSH.
+func WithSSH(config *SSHConfig) ClientOption {
+	return func(cli *Client) error {
+		if config == nil || config.Address == "" || config.User == "" {
+			return fmt.Errorf("the SSH configuration requires an address and a user")
+		}
+		cli.SSH = config
+		return nil
+	}
+}
+
+// address returns the address of the SSH server with the port.
+func (c *SSHConfig) address() string {
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return net.JoinHostPort(c.Address, "22")
+	}
+	return c.Address
+}
+
+// clientConfig returns the configuration of the SSH client.
+func (c *SSHConfig) clientConfig(timeout time.Duration) (*ssh.ClientConfig, error) {
+	var signer ssh.Signer
+	var err error
+	if len(c.Passphrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(c.PrivateKey, c.Passphrase)
+	} else {
+		signer, err = ssh.ParsePrivateKey(c.PrivateKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH private key of %s@%s: %w", c.User, c.Address, err)
+	}
+	callback := c.HostKeyCallback
+	if callback == nil {
+		if len(c.KnownHosts) == 0 {
+			return nil, fmt.Errorf("the SSH server %s requires a host key callback or known hosts files", c.Address)
+		}
+		if callback, err = knownhosts.New(c.KnownHosts...); err != nil {
+			return nil, fmt.Errorf("failed loading known hosts: %w", err)
+		}
+	}
+	return &ssh.ClientConfig{
+		User:            c.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: callback,
+		Timeout:         timeout,
+	}, nil
+}
+
+// sshClient is a client of an SSH server, along with the clients of the
+// jump hosts it was reached through.
+type sshClient struct {
+	*ssh.Client
+	jump *sshClient
+}
+
+func (c *sshClient) Close() error {
+	err := c.Client.Close()
+	if c.jump != nil {
+		c.jump.Close()
+	}
+	return err
+}
+
+// dialSSH logs in to the SSH server, through the jump host, if any.
+func dialSSH(ctx context.Context, c *SSHConfig, timeout time.Duration) (*sshClient, error) {
+	config, err := c.clientConfig(timeout)
+	if err != nil {
+		return nil, err
+	}
+	addr := c.address()
+	var jump *sshClient
+	var conn net.Conn
+	if c.Jump != nil {
+		if jump, err = dialSSH(ctx, c.Jump, timeout); err != nil {
+			return nil, err
+		}
+		conn, err = jump.DialContext(ctx, "tcp", addr)
+	} else {
+		dialer := net.Dialer{Timeout: timeout}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		if jump != nil {
+			jump.Close()
+		}
+		return nil, fmt.Errorf("failed connecting to SSH server %s: %w", addr, err)
+	}
+	// The handshake does not honor the context, but its deadline.
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		if jump != nil {
+			jump.Close()
+		}
+		return nil, fmt.Errorf("SSH handshake with %s failed: %w", addr, err)
+	}
+	conn.SetDeadline(time.Time{})
+	return &sshClient{Client: ssh.NewClient(sshConn, chans, reqs), jump: jump}, nil
+}
+
+// sshConn is a connection tunneled through SSH. Closing it logs out of the
+// SSH server.
+type sshConn struct {
+	net.Conn
+	client *sshClient
+}
+
+func (c *sshConn) Close() error {
+	err := c.Conn.Close()
+	c.client.Close()
+	return err
+}
+
+// dialSSHTunnel connects to the address, which is either a unix socket or
+// a TCP address, from the SSH server.
+func dialSSHTunnel(ctx context.Context, c *SSHConfig, network, addr string, timeout time.Duration) (net.Conn, error) {
+	client, err := dialSSH(ctx, c, timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.DialContext(ctx, network, addr)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed connecting to %s:%s via SSH server %s: %w", network, addr, c.Address, err)
+	}
+	return &sshConn{Conn: conn, client: client}, nil
+}
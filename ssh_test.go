@@ -0,0 +1,220 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// newTestSSHServer starts an SSH server which lets the user with the key
+// open direct-tcpip and direct-streamlocal channels. It returns the address
+// and the host key of the server.
+func newTestSSHServer(t *testing.T, user ssh.PublicKey) (string, ssh.PublicKey) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "ovs" && bytes.Equal(key.Marshal(), user.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key of %s", conn.User())
+		},
+	}
+	config.AddHostKey(signer)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSHConn(conn, config)
+		}
+	}()
+	t.Cleanup(func() {
+		l.Close()
+	})
+	return l.Addr().String(), signer.PublicKey()
+}
+
+func serveTestSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		var network, addr string
+		switch newChan.ChannelType() {
+		case "direct-tcpip":
+			var msg struct {
+				Host     string
+				Port     uint32
+				OrigHost string
+				OrigPort uint32
+			}
+			if err := ssh.Unmarshal(newChan.ExtraData(), &msg); err != nil {
+				newChan.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			network, addr = "tcp", net.JoinHostPort(msg.Host, fmt.Sprint(msg.Port))
+		case "direct-streamlocal@openssh.com":
+			var msg struct {
+				Path      string
+				Reserved0 string
+				Reserved1 uint32
+			}
+			if err := ssh.Unmarshal(newChan.ExtraData(), &msg); err != nil {
+				newChan.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			network, addr = "unix", msg.Path
+		default:
+			newChan.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		target, err := net.Dial(network, addr)
+		if err != nil {
+			newChan.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		ch, chReqs, err := newChan.Accept()
+		if err != nil {
+			target.Close()
+			continue
+		}
+		go ssh.DiscardRequests(chReqs)
+		go func() {
+			io.Copy(ch, target)
+			ch.Close()
+		}()
+		go func() {
+			io.Copy(target, ch)
+			target.Close()
+		}()
+	}
+}
+
+func TestClientSSH(t *testing.T) {
+	userPub, userKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(userKey, "")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	var privateKey bytes.Buffer
+	if err := pem.Encode(&privateKey, block); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	sshUser, err := ssh.NewPublicKey(userPub)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	sshAddr, hostKey := newTestSSHServer(t, sshUser)
+
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	ul, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	newTestServer(t, ul, nil)
+	tl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, tl, nil)
+
+	config := &SSHConfig{
+		Address:         sshAddr,
+		User:            "ovs",
+		PrivateKey:      privateKey.Bytes(),
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+	}
+	jumpConfig := *config
+	jumpConfig.Jump = config
+	for i, test := range []struct {
+		name   string
+		remote string
+		config *SSHConfig
+	}{
+		{name: "unix", remote: "unix:" + sock, config: config},
+		{name: "tcp", remote: "tcp:" + tl.Addr().String(), config: config},
+		{name: "jump", remote: "unix:" + sock, config: &jumpConfig},
+	} {
+		cli, err := NewClient(test.remote, 1, WithSSH(test.config))
+		if err != nil {
+			t.Fatalf("FAIL: Test %d: %s: expected to connect over SSH, but failed with: %v", i, test.name, err)
+		}
+		if err := cli.Echo("test message"); err != nil {
+			t.Fatalf("FAIL: Test %d: %s: %v", i, test.name, err)
+		}
+		cli.Close()
+		t.Logf("PASS: Test %d: %s: 'echo' method over SSH completed successfully", i, test.name)
+	}
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	otherSigner, err := ssh.NewSignerFromKey(otherKey)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	for i, test := range []struct {
+		name   string
+		config SSHConfig
+		want   string
+	}{
+		{name: "host key mismatch", config: SSHConfig{Address: sshAddr, User: "ovs", PrivateKey: privateKey.Bytes(), HostKeyCallback: ssh.FixedHostKey(otherSigner.PublicKey())}, want: "handshake"},
+		{name: "no host key callback", config: SSHConfig{Address: sshAddr, User: "ovs", PrivateKey: privateKey.Bytes()}, want: "host key callback"},
+		{name: "unknown user", config: SSHConfig{Address: sshAddr, User: "root", PrivateKey: privateKey.Bytes(), HostKeyCallback: ssh.FixedHostKey(hostKey)}, want: "unable to authenticate"},
+		{name: "invalid key", config: SSHConfig{Address: sshAddr, User: "ovs", PrivateKey: []byte("key"), HostKeyCallback: ssh.FixedHostKey(hostKey)}, want: "invalid SSH private key"},
+	} {
+		config := test.config
+		cli, err := NewClient("unix:"+sock, 1, WithSSH(&config))
+		if err == nil {
+			cli.Close()
+			t.Fatalf("FAIL: Test %d: %s: expected to fail connecting", i, test.name)
+		}
+		if !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("FAIL: Test %d: %s: expected error containing %q, but got: %v", i, test.name, test.want, err)
+		}
+		t.Logf("PASS: Test %d: %s: %v", i, test.name, err)
+	}
+}
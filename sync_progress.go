@@ -0,0 +1,151 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// syncProgressInterval is the interval between the reports of a monitor
+// waiting for its initial snapshot.
+const syncProgressInterval = 500 * time.Millisecond
+
+// SyncProgress describes the progress of the initial sync of a monitor,
+// passed to Client.OnSyncProgress. The server sends the rows of all the
+// tables in a single reply: while it is received, Bytes grows, then the
+// tables are counted in as their rows are applied, until Done.
+type SyncProgress struct {
+	Database string
+	// Bytes is the number of bytes the client received since it requested
+	// the monitor.
+	Bytes int64
+	// Tables is the number of monitored tables, TablesDone the number of
+	// those whose rows are applied, and Rows the number of their rows.
+	Tables     int
+	TablesDone int
+	Rows       int
+	// Elapsed is the time since the monitor was requested.
+	Elapsed time.Duration
+	Done    bool
+}
+
+// WithSyncProgress registers a function called with the progress of the
+// initial sync of the monitors of a client, every half second while the
+// reply is received, as every table is applied, and once it is done, so
+// that a sync of a large database is told apart from a hung one.
+func WithSyncProgress(fn func(SyncProgress)) ClientOption {
+	return func(cli *Client) error {
+		cli.OnSyncProgress = fn
+		return nil
+	}
+}
+
+// syncProgress reports the progress of the initial sync of a monitor.
+type syncProgress struct {
+	fn       func(SyncProgress)
+	received *atomic.Int64
+	start    time.Time
+	bytes    int64
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mux      sync.Mutex
+	progress SyncProgress
+}
+
+func newSyncProgress(cli *Client, db string, tables int) *syncProgress {
+	p := &syncProgress{
+		fn:       cli.OnSyncProgress,
+		received: cli.received,
+		start:    time.Now(),
+		bytes:    cli.received.Load(),
+		stop:     make(chan struct{}),
+		progress: SyncProgress{Database: db, Tables: tables},
+	}
+	go func() {
+		ticker := time.NewTicker(syncProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.report(func(*SyncProgress) {})
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// report updates the progress, and passes it to the function.
+func (p *syncProgress) report(update func(progress *SyncProgress)) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.progress.Done {
+		return
+	}
+	update(&p.progress)
+	p.progress.Bytes = p.received.Load() - p.bytes
+	p.progress.Elapsed = time.Since(p.start)
+	p.fn(p.progress)
+}
+
+// table reports a table whose rows are applied.
+func (p *syncProgress) table(rows int) {
+	p.report(func(progress *SyncProgress) {
+		progress.TablesDone++
+		progress.Rows += rows
+	})
+}
+
+// done reports the end of the sync, and stops the reports. The tables the
+// reply left out have no rows.
+func (p *syncProgress) done() {
+	p.cancel()
+	p.report(func(progress *SyncProgress) {
+		progress.TablesDone = progress.Tables
+		progress.Done = true
+	})
+}
+
+// cancel stops the reports of a sync which failed.
+func (p *syncProgress) cancel() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+// tableSynced reports a table of the initial snapshot whose rows are
+// applied, along with their number.
+func (m *Monitor) tableSynced(rows int) {
+	if m.progress != nil && !m.delivered {
+		m.progress.table(rows)
+	}
+}
+
+// countedConn counts the bytes read from a connection.
+type countedConn struct {
+	io.ReadWriteCloser
+	n *atomic.Int64
+}
+
+func (c *countedConn) Read(b []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(b)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestSyncProgress(t *testing.T) {
+	const br0, br1 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c", "5d1c1ca2-4d3b-4bd1-9c8a-1b0e2c6f2d31"
+	remote, _ := newTestMonitorServer(t,
+		map[string]interface{}{"Bridge": map[string]interface{}{
+			br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0"}},
+			br1: map[string]interface{}{"new": map[string]interface{}{"name": "br1"}},
+		}},
+	)
+	var mu sync.Mutex
+	var reports []SyncProgress
+	c, err := NewClient(remote, 1, WithSyncProgress(func(p SyncProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, p)
+	}))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	m, err := c.Monitor(ctx, "Open_vSwitch", []string{"Bridge"}, nil)
+	if err != nil {
+		t.Fatalf("FAIL: expected the monitor to start, but failed with: %v", err)
+	}
+	defer m.Cancel(ctx)
+	nextUpdates(t, m)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) < 2 {
+		t.Fatalf("FAIL: expected the table, then the end of the sync, to be reported, got: %+v", reports)
+	}
+	table, done := reports[len(reports)-2], reports[len(reports)-1]
+	if table.Done || table.TablesDone != 1 || table.Rows != 2 || table.Tables != 1 || table.Database != "Open_vSwitch" {
+		t.Fatalf("FAIL: unexpected progress of the table: %+v", table)
+	}
+	if !done.Done || done.TablesDone != 1 || done.Rows != 2 || done.Bytes <= 0 || done.Bytes < table.Bytes || done.Elapsed <= 0 {
+		t.Fatalf("FAIL: unexpected end of the sync: %+v", done)
+	}
+	t.Logf("PASS: sync progress: %+v", done)
+
+}
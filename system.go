@@ -16,6 +16,7 @@ package ovsdb
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"regexp"
@@ -46,9 +47,49 @@ type OvsDaemon struct {
 	}
 }
 
+// DefaultMaxSystemIDLength is the maximum length of a system-id, in bytes,
+// unless OvsClient.MaxSystemIDLength sets another one. vswitch.ovsschema
+// does not limit system IDs to a particular length and a common ID to use
+// is UUID (36 bytes). However, some tools use FQDNs for system-ids which
+// are limited to 253 octets per RFC1035. Hence the limit, to avoid
+// arbitrary length for system IDs and to have a sane limit.
+const DefaultMaxSystemIDLength = 253
+
+// ValidateSystemID checks a system-id, as found in the external_ids of the
+// Open_vSwitch table, or in the system-id.conf file, and returns it without
+// the surrounding white space. The system-id must not be empty, nor longer
+// than maxLen bytes, or DefaultMaxSystemIDLength when maxLen is not
+// positive. When value, the value type of the external_ids column in the
+// schema, is not nil, the system-id must satisfy its constraints too.
+func ValidateSystemID(id string, maxLen int, value *BaseType) (string, error) {
+	if maxLen <= 0 {
+		maxLen = DefaultMaxSystemIDLength
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", fmt.Errorf("system-id is empty")
+	}
+	if len(id) > maxLen {
+		return id, fmt.Errorf("system-id is greater than what is allowed: %d vs %d", len(id), maxLen)
+	}
+	if value != nil {
+		if err := value.checkAtom(id, true); err != nil {
+			return id, fmt.Errorf("system-id violates the schema: %s", err)
+		}
+	}
+	return id, nil
+}
+
 // GetSystemID TODO
 func (cli *OvsClient) GetSystemID() error {
-	systemID, err := getSystemID(cli.Database.Vswitch.Client, cli.Database.Vswitch.Name, cli.Database.Vswitch.File.SystemID.Path)
+	return cli.GetSystemIDContext(context.Background())
+}
+
+// GetSystemIDContext is like GetSystemID, but honors the context.
+func (cli *OvsClient) GetSystemIDContext(ctx context.Context) error {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	systemID, err := getSystemID(ctx, cli.Database.Vswitch.Client, cli.Database.Vswitch.Name, cli.Database.Vswitch.File.SystemID.Path, cli.MaxSystemIDLength)
 	if err != nil {
 		return err
 	}
@@ -56,25 +97,30 @@ func (cli *OvsClient) GetSystemID() error {
 	return nil
 }
 
-func getSystemID(client *Client, dbName string, filepath string) (string, error) {
+// getSystemID returns the system-id found in the database, or else in the
+// file, validated with ValidateSystemID against the schema of the database,
+// when known.
+func getSystemID(ctx context.Context, client *Client, dbName string, filepath string, maxLen int) (string, error) {
 	var systemID string
 	var dbErr error
+	var value *BaseType
 
 	// First, try to query database if client is provided
 	if client != nil && dbName != "" {
 		query := fmt.Sprintf("SELECT external_ids FROM %s", dbName)
-		result, err := client.Transact(dbName, query)
+		if schema, err := client.GetSchemaContext(ctx, dbName); err == nil {
+			if column := schema.Table(dbName).Column("external_ids"); column != nil {
+				value = column.Value
+			}
+		}
+		result, err := client.TransactContext(ctx, dbName, query)
 		if err == nil && len(result.Rows) > 0 {
 			col := "external_ids"
 			rowData, dataType, err := result.Rows[0].GetColumnValue(col, result.Columns)
 			if err == nil && dataType == "map[string]string" {
 				externalIDs := rowData.(map[string]string)
-				if dbSystemID, exists := externalIDs["system-id"]; exists && dbSystemID != "" {
-					systemID = dbSystemID
-					if len(systemID) > 253 {
-						return systemID, fmt.Errorf("system-id is greater than what the exporter currently allows: %d vs 253", len(systemID))
-					}
-					return systemID, nil
+				if dbSystemID, exists := externalIDs["system-id"]; exists && strings.TrimSpace(dbSystemID) != "" {
+					return ValidateSystemID(dbSystemID, maxLen, value)
 				}
 			}
 		} else if err != nil {
@@ -103,23 +149,16 @@ func getSystemID(client *Client, dbName string, filepath string) (string, error)
 		}
 		return "", err
 	}
-	// vswitch.ovsschema does not limit system IDs to a particular length and a common
-	// ID to use is UUID (36 bytes). However, some tools use FQDNs for system-ids which
-	// are limited to 253 octets per RFC1035. Hence the current limit checked by the
-	// exporter is 253 bytes to avoid arbitrary length for system IDs and to have a sane limit.
-	if len(systemID) > 253 {
-		return systemID, fmt.Errorf("system-id is greater than what the exporter currently allows: %d vs 253", len(systemID))
-	}
-	return systemID, nil
+	return ValidateSystemID(systemID, maxLen, value)
 }
 
-func getVersionViaAppctl(sock string, timeout int) (string, error) {
+func getVersionViaAppctl(ctx context.Context, sock string, timeout int, opts []ClientOption) (string, error) {
 	cmd := "version"
-	app, err := NewClient(sock, timeout)
+	app, err := NewClientContext(ctx, sock, timeout, opts...)
 	if err != nil {
 		return "", fmt.Errorf("failed to connect to socket %s: %s", sock, err)
 	}
-	r, err := app.query(cmd, nil)
+	r, err := app.queryContext(ctx, cmd, nil)
 	app.Close()
 	if err != nil {
 		return "", fmt.Errorf("the '%s' command failed: %s", cmd, err)
@@ -163,10 +202,10 @@ func getSystemInfoFromOS() (string, string) {
 	return systemType, systemVersion
 }
 
-func populateVersionFromAppctl(systemInfo map[string]string, sock string, timeout int, schema *Schema) {
+func populateVersionFromAppctl(ctx context.Context, systemInfo map[string]string, sock string, timeout int, opts []ClientOption, schema *Schema) {
 	// Get OVS version via ovs-appctl if missing from DB
 	if val, exists := systemInfo["ovs_version"]; !exists || val == "" {
-		versionStr, err := getVersionViaAppctl(sock, timeout)
+		versionStr, err := getVersionViaAppctl(ctx, sock, timeout, opts)
 		if err == nil {
 			systemInfo["ovs_version"] = parseOvsVersion(versionStr)
 		} else {
@@ -241,7 +280,7 @@ func parseSystemInfo(systemID string, result Result) (map[string]string, error)
 		break //nolint:staticcheck
 	}
 	if dbSystemID, exists := systemInfo["system-id"]; exists {
-		if dbSystemID != systemID {
+		if strings.TrimSpace(dbSystemID) != systemID {
 			return systemInfo, fmt.Errorf("found 'system-id' mismatch %s (db) vs. %s (config)", dbSystemID, systemID)
 		}
 	} else {
@@ -264,26 +303,55 @@ func parseSystemInfo(systemID string, result Result) (map[string]string, error)
 // GetSystemInfo returns a hash containing system information, e.g. `system_id`
 // associated with the Open_vSwitch database.
 func (cli *OvsClient) GetSystemInfo() error {
+	return cli.GetSystemInfoContext(context.Background())
+}
+
+// GetSystemInfoContext is like GetSystemInfo, but honors the context.
+func (cli *OvsClient) GetSystemInfoContext(ctx context.Context) error {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	c := newCollector(ctx, cli.PartialResults)
 	// Get system-id (tries database first, falls back to file)
-	systemID, err := getSystemID(cli.Database.Vswitch.Client, cli.Database.Vswitch.Name, cli.Database.Vswitch.File.SystemID.Path)
+	var systemID string
+	err := c.run("system-id", func() error {
+		var err error
+		systemID, err = getSystemID(ctx, cli.Database.Vswitch.Client, cli.Database.Vswitch.Name, cli.Database.Vswitch.File.SystemID.Path, cli.MaxSystemIDLength)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
+	systemInfo := make(map[string]string)
 	query := fmt.Sprintf("SELECT ovs_version, db_version, system_type, system_version, external_ids FROM %s", cli.Database.Vswitch.Name)
-	result, err := cli.Database.Vswitch.Client.Transact(cli.Database.Vswitch.Name, query)
+	err = c.run(query, func() error {
+		result, err := cli.Database.Vswitch.Client.TransactContext(ctx, cli.Database.Vswitch.Name, query)
+		if err != nil {
+			return fmt.Errorf("The '%s' query failed: %s", query, err)
+		}
+		if len(result.Rows) == 0 {
+			return fmt.Errorf("The '%s' query did not return any rows", query)
+		}
+		info, err := parseSystemInfo(systemID, result)
+		if err != nil {
+			return fmt.Errorf("The '%s' query returned results but erred: %s", query, err)
+		}
+		systemInfo = info
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("The '%s' query failed: %s", query, err)
-	}
-	if len(result.Rows) == 0 {
-		return fmt.Errorf("The '%s' query did not return any rows", query)
+		return err
 	}
-	systemInfo, err := parseSystemInfo(systemID, result)
-	if err != nil {
-		return fmt.Errorf("The '%s' query returned results but erred: %s", query, err)
+	if _, exists := systemInfo["system-id"]; !exists && systemID != "" {
+		systemInfo["system-id"] = systemID
 	}
 	// Get schema for db_version
-	schema, _ := cli.Database.Vswitch.Client.GetSchema(cli.Database.Vswitch.Name)
+	var schema Schema
+	c.run("get_schema", func() error {
+		var err error
+		schema, err = cli.Database.Vswitch.Client.GetSchemaContext(ctx, cli.Database.Vswitch.Name)
+		return err
+	})
 	// Ensure PID is read and socket path is updated before using control socket
 	if cli.Database.Vswitch.Process.ID == 0 {
 		p, pidErr := getProcessInfoFromFile(cli.Database.Vswitch.File.Pid.Path)
@@ -293,13 +361,23 @@ func (cli *OvsClient) GetSystemInfo() error {
 	}
 	cli.updateRefs()
 	// Query version information via ovs-appctl for fields not in DB (OVS 3.x+)
-	populateVersionFromAppctl(systemInfo, cli.Database.Vswitch.Socket.Control, cli.Timeout, &schema)
-	cli.System.ID = systemInfo["system-id"]
-	cli.System.RunDir = systemInfo["rundir"]
-	cli.System.Hostname = systemInfo["hostname"]
-	cli.System.Type = systemInfo["system_type"]
-	cli.System.Version = systemInfo["system_version"]
-	cli.Database.Vswitch.Version = systemInfo["ovs_version"]
-	cli.Database.Vswitch.Schema.Version = systemInfo["db_version"]
-	return nil
+	c.run("version", func() error {
+		populateVersionFromAppctl(ctx, systemInfo, cli.Database.Vswitch.Socket.Control, cli.Timeout, cli.controlOptions(), &schema)
+		return nil
+	})
+	// In the partial results mode, only the information gathered is set.
+	for key, field := range map[string]*string{
+		"system-id":      &cli.System.ID,
+		"rundir":         &cli.System.RunDir,
+		"hostname":       &cli.System.Hostname,
+		"system_type":    &cli.System.Type,
+		"system_version": &cli.System.Version,
+		"ovs_version":    &cli.Database.Vswitch.Version,
+		"db_version":     &cli.Database.Vswitch.Schema.Version,
+	} {
+		if v, exists := systemInfo[key]; exists {
+			*field = v
+		}
+	}
+	return c.err()
 }
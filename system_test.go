@@ -15,6 +15,7 @@
 package ovsdb
 
 import (
+	"context"
 	"os"
 	"testing"
 )
@@ -155,7 +156,7 @@ func TestPopulateVersionFromAppctl(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Note: We can't test the actual appctl query without a running OVS
 			// but we can test the logic with a fake socket that will fail
-			populateVersionFromAppctl(tt.systemInfo, "/nonexistent/socket", 1, tt.schema)
+			populateVersionFromAppctl(context.Background(), tt.systemInfo, "/nonexistent/socket", 1, nil, tt.schema)
 
 			// Check that fields were populated (either with real values or "unknown")
 			if tt.expectOvsVer {
@@ -191,7 +192,7 @@ func TestPopulateVersionFromAppctlWithSchema(t *testing.T) {
 	schema := &Schema{Version: "7.16.1"}
 
 	// Populate with a fake socket (will fail to connect, but should still populate from schema)
-	populateVersionFromAppctl(systemInfo, "/nonexistent/socket", 1, schema)
+	populateVersionFromAppctl(context.Background(), systemInfo, "/nonexistent/socket", 1, nil, schema)
 
 	// db_version should be populated from schema
 	if dbVersion, exists := systemInfo["db_version"]; !exists {
@@ -215,3 +216,48 @@ func TestPopulateVersionFromAppctlWithSchema(t *testing.T) {
 		t.Error("Expected system_version to be populated")
 	}
 }
+
+func TestValidateSystemID(t *testing.T) {
+	maxLength := 8
+	value := &BaseType{Type: "string", MaxLength: &maxLength}
+	tests := []struct {
+		name     string
+		id       string
+		maxLen   int
+		value    *BaseType
+		expected string
+		fail     bool
+	}{
+		{name: "uuid", id: "6c5cb7a1-6c42-4c4e-9d4c-3c7b5e8f6a1b\n", expected: "6c5cb7a1-6c42-4c4e-9d4c-3c7b5e8f6a1b"},
+		{name: "empty", id: "  ", fail: true},
+		{name: "default limit", id: string(make([]byte, DefaultMaxSystemIDLength+1)), fail: true},
+		{name: "configured limit", id: "node-1.example.com", maxLen: 10, fail: true},
+		{name: "schema constraint", id: "node-1.example", value: value, fail: true},
+		{name: "within schema constraint", id: " node-1 ", value: value, expected: "node-1"},
+	}
+	for _, test := range tests {
+		id, err := ValidateSystemID(test.id, test.maxLen, test.value)
+		if test.fail {
+			if err == nil {
+				t.Fatalf("FAIL: %s: expected system-id %q to be rejected", test.name, test.id)
+			}
+			continue
+		}
+		if err != nil || id != test.expected {
+			t.Fatalf("FAIL: %s: expected %q, got %q, %v", test.name, test.expected, id, err)
+		}
+	}
+	t.Logf("PASS: system-ids validated")
+
+	path := t.TempDir() + "/system-id.conf"
+	if err := os.WriteFile(path, []byte("node-1.example.com \n"), 0o600); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if id, err := getSystemID(context.Background(), nil, "", path, 0); err != nil || id != "node-1.example.com" {
+		t.Fatalf("FAIL: expected the system-id of the file, got %q, %v", id, err)
+	}
+	if _, err := getSystemID(context.Background(), nil, "", path, 10); err == nil {
+		t.Fatalf("FAIL: expected the system-id of the file to exceed the limit")
+	}
+	t.Logf("PASS: system-id read from file")
+}
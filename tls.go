@@ -0,0 +1,184 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TLSOptions holds the settings for "ssl:" remotes. The file options mirror
+// the --private-key, --certificate, and --ca-cert options of ovn-sbctl and
+// ovs-vsctl.
+type TLSOptions struct {
+	PrivateKey    string
+	Certificate   string
+	CACertificate string
+	// ServerName overrides the name used to verify the server certificate.
+	// By default, the host part of the remote is used.
+	ServerName string
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS12. The
+	// default is TLS 1.2.
+	MinVersion uint16
+}
+
+// NewTLSConfig returns TLS configuration for the provided options.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: opts.ServerName,
+		MinVersion: opts.MinVersion,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	if opts.CACertificate != "" {
+		pem, err := os.ReadFile(opts.CACertificate)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading CA certificate: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates found in %s", opts.CACertificate)
+		}
+		config.RootCAs = pool
+	}
+	if opts.Certificate != "" || opts.PrivateKey != "" {
+		if opts.Certificate == "" || opts.PrivateKey == "" {
+			return nil, fmt.Errorf("both certificate and private key are required")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.Certificate, opts.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading certificate and private key: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// CertificateReloader serves a certificate and its private key from files,
+// and reloads them when the files change, e.g. when they are rotated by
+// cert-manager or Vault. The files are checked on every TLS handshake, by
+// Reload, and by Watch.
+type CertificateReloader struct {
+	certFile   string
+	keyFile    string
+	mux        sync.Mutex
+	cert       *tls.Certificate
+	certStamp  fileStamp
+	keyStamp   fileStamp
+	generation atomic.Uint64
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewCertificateReloader returns a reloader of the certificate and the
+// private key in the files.
+func NewCertificateReloader(certFile, keyFile string) (*CertificateReloader, error) {
+	r := &CertificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reloads the certificate and the private key when either file
+// changed, and returns true when it did. On error, e.g. when only one of
+// the files has been replaced yet, the previous certificate remains in use.
+func (r *CertificateReloader) Reload() (bool, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	certStamp, err := statFile(r.certFile)
+	if err != nil {
+		return false, fmt.Errorf("failed reading certificate: %s", err)
+	}
+	keyStamp, err := statFile(r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed reading private key: %s", err)
+	}
+	if r.cert != nil && certStamp == r.certStamp && keyStamp == r.keyStamp {
+		return false, nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed loading certificate and private key: %s", err)
+	}
+	r.cert = &cert
+	r.certStamp = certStamp
+	r.keyStamp = keyStamp
+	r.generation.Add(1)
+	return true, nil
+}
+
+// Watch reloads the certificate every interval until the context is done.
+// The errors, if any, are passed to the onError function, which may be nil.
+func (r *CertificateReloader) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// GetCertificate returns the current certificate. It is suitable for the
+// GetCertificate field of tls.Config of servers.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, _ := r.current()
+	return cert, nil
+}
+
+// GetClientCertificate returns the current certificate. It is suitable for
+// the GetClientCertificate field of tls.Config of clients.
+func (r *CertificateReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, _ := r.current()
+	return cert, nil
+}
+
+// current reloads the certificate, if necessary, and returns it along with
+// its generation.
+func (r *CertificateReloader) current() (*tls.Certificate, uint64) {
+	r.Reload()
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.cert, r.generation.Load()
+}
+
+// statFile returns the stamp of the file.
+func statFile(path string) (fileStamp, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: fi.ModTime(), size: fi.Size()}, nil
+}
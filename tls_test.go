@@ -0,0 +1,251 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCertificate creates a self-signed certificate for 127.0.0.1 and
+// returns the paths to the certificate and its private key.
+func writeTestCertificate(t *testing.T, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("FAIL: failed generating key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("FAIL: failed creating certificate: %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("FAIL: failed marshaling key: %v", err)
+	}
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, name+"-cert.pem")
+	keyPath := filepath.Join(dir, name+"-privkey.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	return certPath, keyPath
+}
+
+func TestClientSSL(t *testing.T) {
+	serverCert, serverKey := writeTestCertificate(t, "ovsdb-server")
+	clientCert, clientKey := writeTestCertificate(t, "ovsdb-client")
+	serverConfig, err := NewTLSConfig(TLSOptions{
+		Certificate:   serverCert,
+		PrivateKey:    serverKey,
+		CACertificate: clientCert,
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	serverConfig.ClientCAs = serverConfig.RootCAs
+	serverConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	l, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, nil)
+	sock := "ssl:" + l.Addr().String()
+
+	if _, err := NewClient(sock, 1); err == nil {
+		t.Fatalf("FAIL: expected to fail connecting to %s without TLS configuration", sock)
+	}
+
+	clientConfig, err := NewTLSConfig(TLSOptions{
+		Certificate:   clientCert,
+		PrivateKey:    clientKey,
+		CACertificate: serverCert,
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	cli, err := NewClient(sock, 1, WithTLSConfig(clientConfig))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to %s, but failed with: %v", sock, err)
+	}
+	defer cli.Close()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	t.Logf("PASS: 'echo' method over %s completed successfully", sock)
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	if _, err := NewTLSConfig(TLSOptions{Certificate: "/nonexistent/cert.pem"}); err == nil {
+		t.Fatalf("FAIL: expected to fail without private key")
+	}
+	if _, err := NewTLSConfig(TLSOptions{CACertificate: "/nonexistent/cacert.pem"}); err == nil {
+		t.Fatalf("FAIL: expected to fail with nonexistent CA certificate")
+	}
+	config, err := NewTLSConfig(TLSOptions{ServerName: "ovn-central"})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if config.ServerName != "ovn-central" || config.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("FAIL: unexpected TLS configuration: %s, %d", config.ServerName, config.MinVersion)
+	}
+}
+
+// replaceTestCertificate replaces the certificate and the private key in
+// the files with the ones in the other files.
+func replaceTestCertificate(t *testing.T, certPath, keyPath, otherCertPath, otherKeyPath string) {
+	later := time.Now().Add(time.Minute)
+	for dst, src := range map[string]string{certPath: otherCertPath, keyPath: otherKeyPath} {
+		b, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		if err := os.WriteFile(dst, b, 0600); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		if err := os.Chtimes(dst, later, later); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+}
+
+func TestCertificateReloader(t *testing.T) {
+	if _, err := NewCertificateReloader("/nonexistent/cert.pem", "/nonexistent/privkey.pem"); err == nil {
+		t.Fatalf("FAIL: expected to fail with nonexistent certificate")
+	}
+	certPath, keyPath := writeTestCertificate(t, "ovsdb-client")
+	newCertPath, newKeyPath := writeTestCertificate(t, "ovsdb-client-rotated")
+	r, err := NewCertificateReloader(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if reloaded, err := r.Reload(); reloaded || err != nil {
+		t.Fatalf("FAIL: expected no reload of unchanged files, but got %t, %v", reloaded, err)
+	}
+	cert, _ := r.GetClientCertificate(nil)
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+	if leaf.Subject.CommonName != "ovsdb-client" {
+		t.Fatalf("FAIL: unexpected certificate %s", leaf.Subject.CommonName)
+	}
+
+	// A half-rotated pair keeps the previous certificate in use.
+	if err := os.WriteFile(keyPath, []byte("garbage"), 0600); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if reloaded, err := r.Reload(); reloaded || err == nil {
+		t.Fatalf("FAIL: expected to fail reloading a mismatched pair, but got %t, %v", reloaded, err)
+	}
+	if cert, _ := r.GetCertificate(nil); cert == nil {
+		t.Fatalf("FAIL: expected the previous certificate to remain in use")
+	}
+
+	replaceTestCertificate(t, certPath, keyPath, newCertPath, newKeyPath)
+	if reloaded, err := r.Reload(); !reloaded || err != nil {
+		t.Fatalf("FAIL: expected to reload rotated files, but got %t, %v", reloaded, err)
+	}
+	cert, _ = r.GetClientCertificate(nil)
+	leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+	if leaf.Subject.CommonName != "ovsdb-client-rotated" {
+		t.Fatalf("FAIL: expected the rotated certificate, but got %s", leaf.Subject.CommonName)
+	}
+	t.Logf("PASS: certificate reloaded after rotation")
+}
+
+func TestClientCertificateRehandshake(t *testing.T) {
+	serverCert, serverKey := writeTestCertificate(t, "ovsdb-server")
+	serverConfig, err := NewTLSConfig(TLSOptions{
+		Certificate: serverCert,
+		PrivateKey:  serverKey,
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	peers := make(chan string, 4)
+	serverConfig.ClientAuth = tls.RequireAnyClientCert
+	serverConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+		peers <- cs.PeerCertificates[0].Subject.CommonName
+		return nil
+	}
+	l, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, nil)
+	sock := "ssl:" + l.Addr().String()
+
+	certPath, keyPath := writeTestCertificate(t, "ovsdb-client")
+	newCertPath, newKeyPath := writeTestCertificate(t, "ovsdb-client-rotated")
+	r, err := NewCertificateReloader(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	clientConfig, err := NewTLSConfig(TLSOptions{CACertificate: serverCert})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	cli, err := NewClient(sock, 1, WithTLSConfig(clientConfig), WithCertificateReloader(r, true))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect to %s, but failed with: %v", sock, err)
+	}
+	defer cli.Close()
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if peer := <-peers; peer != "ovsdb-client" {
+		t.Fatalf("FAIL: expected the client to present ovsdb-client, but got %s", peer)
+	}
+
+	replaceTestCertificate(t, certPath, keyPath, newCertPath, newKeyPath)
+	if _, err := r.Reload(); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if err := cli.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	select {
+	case peer := <-peers:
+		if peer != "ovsdb-client-rotated" {
+			t.Fatalf("FAIL: expected the client to present ovsdb-client-rotated, but got %s", peer)
+		}
+	default:
+		t.Fatalf("FAIL: expected the client to reconnect with the reloaded certificate")
+	}
+	t.Logf("PASS: client reconnected with the reloaded certificate")
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+)
+
+// Tracer traces the requests of clients, e.g. with distributed tracing
+// spans. The requests include transactions and the queries of the control
+// sockets of the daemons, when the tracer is set with the Options of the
+// OvsClient or of the OvnClient.
+type Tracer interface {
+	// StartRequest is called when a request starts, with its event
+	// lacking the duration and the outcome. The returned context, e.g.
+	// with the span of the request, is used for the request, and the
+	// returned function is called with the outcome when it completes.
+	StartRequest(ctx context.Context, event RequestEvent) (context.Context, func(err error))
+}
+
+// WithTracer sets the tracer of a client.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(cli *Client) error {
+		cli.Tracer = tracer
+		return nil
+	}
+}
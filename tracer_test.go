@@ -0,0 +1,52 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// testTracer records the requests it traces.
+type testTracer struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+func (tr *testTracer) StartRequest(ctx context.Context, event RequestEvent) (context.Context, func(err error)) {
+	return ctx, func(err error) {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		tr.spans = append(tr.spans, event.Method)
+	}
+}
+
+func TestControlSocketTracer(t *testing.T) {
+	tracer := &testTracer{}
+	cli := NewOvnClient()
+	cli.Database.Northbound.Socket.Control = newTestControlServer(t)
+	cli.Options = []ClientOption{WithTracer(tracer)}
+	metrics, err := cli.GetAppMemoryMetricsContext(context.Background(), "ovsdb-server-northbound")
+	if err != nil || metrics["cells"] != 100 {
+		t.Fatalf("FAIL: expected the memory metrics, but got %v, %v", metrics, err)
+	}
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if !contains(tracer.spans, "memory/show") {
+		t.Fatalf("FAIL: expected a span of the memory/show command, but got %v", tracer.spans)
+	}
+	t.Logf("PASS: traced %v", tracer.spans)
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing traces the requests of OVSDB clients with OpenTelemetry.
+package tracing
+
+import (
+	"context"
+
+	"github.com/supergate-hub/ovsdb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer of the package.
+const instrumentationName = "github.com/supergate-hub/ovsdb/tracing"
+
+// Tracer creates a span for every request of OVSDB clients, a child of the
+// span in the context of the request, if any. It is an ovsdb.Tracer, which
+// is set on the clients with ovsdb.WithTracer.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a tracer creating the spans with the provider. When the
+// provider is nil, the global provider is used.
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// StartRequest implements ovsdb.Tracer.
+func (t *Tracer) StartRequest(ctx context.Context, event ovsdb.RequestEvent) (context.Context, func(error)) {
+	name := event.Method
+	if event.Database != "" {
+		name += " " + event.Database
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "ovsdb"),
+		attribute.String("ovsdb.method", event.Method),
+		attribute.String("ovsdb.endpoint", event.Endpoint),
+	}
+	if event.Database != "" {
+		attrs = append(attrs, attribute.String("db.name", event.Database))
+	}
+	if event.Table != "" {
+		attrs = append(attrs, attribute.String("ovsdb.table", event.Table))
+	}
+	if event.Operation != "" {
+		attrs = append(attrs, attribute.String("db.operation", event.Operation))
+	}
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
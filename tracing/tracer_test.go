@@ -0,0 +1,134 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/supergate-hub/ovsdb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testSchema = `{"name":"OVN_Southbound","version":"20.0.0","tables":{"Chassis":{"columns":{"name":{"type":"string"}}}}}`
+
+// newTestServer starts a server which answers echo, get_schema, and
+// transact requests.
+func newTestServer(t *testing.T) string {
+	sock := filepath.Join(t.TempDir(), "db.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen on %s: %v", sock, err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				dec := json.NewDecoder(conn)
+				enc := json.NewEncoder(conn)
+				for {
+					var req map[string]interface{}
+					if err := dec.Decode(&req); err != nil {
+						return
+					}
+					var result interface{} = req["params"]
+					switch req["method"] {
+					case "get_schema":
+						result = json.RawMessage(testSchema)
+					case "transact":
+						result = []interface{}{map[string]interface{}{"rows": []interface{}{}}}
+					}
+					resp := map[string]interface{}{"id": req["id"], "result": result, "error": nil}
+					if err := enc.Encode(resp); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		l.Close()
+	})
+	return "unix:" + sock
+}
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	remote := newTestServer(t)
+	cli, err := ovsdb.NewClient(remote, 1, ovsdb.WithTracer(NewTracer(provider)))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "collect")
+	if _, err := cli.TransactContext(ctx, "OVN_Southbound", "SELECT * FROM Chassis"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	parent.End()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cli.EchoContext(cancelled, "test message"); err == nil {
+		t.Fatalf("FAIL: expected a cancelled request to fail")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("FAIL: expected 4 spans, but got %d", len(spans))
+	}
+	for i, want := range []struct {
+		name   string
+		attrs  map[attribute.Key]string
+		child  bool
+		failed bool
+	}{
+		{name: "transact OVN_Southbound", attrs: map[attribute.Key]string{"db.name": "OVN_Southbound", "ovsdb.table": "Chassis", "db.operation": "select"}, child: true},
+		{name: "get_schema OVN_Southbound", attrs: map[attribute.Key]string{"db.name": "OVN_Southbound"}, child: true},
+		{name: "collect"},
+		{name: "echo", failed: true},
+	} {
+		span := spans[i]
+		if span.Name() != want.name {
+			t.Fatalf("FAIL: Test %d: expected span %s, but got %s", i, want.name, span.Name())
+		}
+		attrs := map[attribute.Key]string{}
+		for _, kv := range span.Attributes() {
+			attrs[kv.Key] = kv.Value.AsString()
+		}
+		for k, v := range want.attrs {
+			if attrs[k] != v {
+				t.Fatalf("FAIL: Test %d: expected %s attribute %q, but got %q", i, k, v, attrs[k])
+			}
+		}
+		if child := span.Parent().SpanID() == parent.SpanContext().SpanID(); child != want.child {
+			t.Fatalf("FAIL: Test %d: unexpected parent of %s span", i, span.Name())
+		}
+		if failed := span.Status().Code == codes.Error; failed != want.failed {
+			t.Fatalf("FAIL: Test %d: unexpected status of %s span: %v", i, span.Name(), span.Status())
+		}
+		t.Logf("PASS: Test %d: %s span", i, span.Name())
+	}
+}
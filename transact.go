@@ -15,8 +15,12 @@
 package ovsdb
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 	//"github.com/davecgh/go-spew/spew"
 )
 
@@ -24,6 +28,9 @@ import (
 type Transaction struct {
 	Database   string
 	Operations []Operation
+	// leader routes a read-only transaction to the leader, as if it
+	// modified the database.
+	leader bool
 }
 
 // ToBytes - TODO
@@ -54,12 +61,148 @@ func (t *Transaction) ToString() (string, error) {
 	return string(b[:n]), nil
 }
 
-// Transact - TODO
-func (c *Client) Transact(db string, query string) (Result, error) {
+// Explain returns the exact params of the transact request for the
+// transaction, i.e. the JSON array of the database and the operations,
+// without sending it. It fails on an invalid operation, but does not check
+// the operations against a schema, see Txn.Explain.
+func (t *Transaction) Explain() ([]byte, error) {
+	for i := range t.Operations {
+		if err := t.Operations[i].Validate(); err != nil {
+			return nil, fmt.Errorf("explain: operation %d: %s", i, err)
+		}
+	}
+	b, n, err := t.ToBytes()
+	if err != nil {
+		return nil, fmt.Errorf("explain: %s", err)
+	}
+	out := make([]byte, 0, n+2)
+	out = append(out, '[')
+	out = append(out, b[:n]...)
+	return append(out, ']'), nil
+}
+
+// TransactWithTimeout is like Transact, but fails when the transaction
+// does not complete within the timeout.
+func (c *Client) TransactWithTimeout(db string, query string, timeout time.Duration, args ...interface{}) (Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return c.TransactContext(ctx, db, query, args...)
+}
+
+// readOnly returns true when the transaction does not modify the database.
+func (t *Transaction) readOnly() bool {
+	for _, op := range t.Operations {
+		switch op.Name {
+		case "select", "wait", "comment", "assert":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// transact validates the transaction against the cached schema, unless
+// SkipSchemaValidation is set, and sends it to the cluster member selected
+// by the cluster mode of the client.
+func (c *Client) transact(ctx context.Context, t Transaction) (*Response, error) {
+	if err := c.validateTransaction(t); err != nil {
+		return nil, err
+	}
+	if c.TransactRetry.MaxRetries <= 0 || !t.readOnly() {
+		return c.transactOnce(ctx, t)
+	}
+	return c.transactRetry(ctx, t)
+}
+
+// transactOnce sends the transaction to the cluster member selected by the
+// cluster mode of the client, once.
+func (c *Client) transactOnce(ctx context.Context, t Transaction) (*Response, error) {
+	if c.ReadYourWrites && c.written != nil && t.Database != ServerDatabaseName {
+		if t.readOnly() {
+			return c.readWritten(ctx, t)
+		}
+		return c.recordWrites(ctx, t)
+	}
+	return c.transactTarget(ctx, t)
+}
+
+// transactTarget sends the transaction to the cluster member selected by
+// the cluster mode of the client.
+func (c *Client) transactTarget(ctx context.Context, t Transaction) (*Response, error) {
+	target, err := c.clusterTarget(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	t, stamped := c.stamp(t)
+	resp, err := target.queryContext(ctx, "transact", t)
+	if err != nil || !stamped {
+		return resp, err
+	}
+	return dropResults(resp, len(t.Operations)-1)
+}
+
+// stamp adds the identity of the client to the transaction, when it
+// modifies the database, as a comment operation following the others.
+func (c *Client) stamp(t Transaction) (Transaction, bool) {
+	if c.Identity == "" || t.readOnly() {
+		return t, false
+	}
+	ops := make([]Operation, 0, len(t.Operations)+1)
+	ops = append(ops, t.Operations...)
+	t.Operations = append(ops, Comment(c.Identity))
+	return t, true
+}
+
+// dropResults removes the results of the operations at the indexes from
+// the response, e.g. of the comment operation added by stamp, so that it
+// holds the results of the operations of the caller only.
+func dropResults(resp *Response, indexes ...int) (*Response, error) {
+	var results []json.RawMessage
+	if err := json.Unmarshal(resp.Result, &results); err != nil {
+		return resp, nil
+	}
+	kept := make([]json.RawMessage, 0, len(results))
+	for i, result := range results {
+		if !containsIndex(indexes, i) {
+			kept = append(kept, result)
+		}
+	}
+	if len(kept) == len(results) {
+		return resp, nil
+	}
+	b, err := json.Marshal(kept)
+	if err != nil {
+		return nil, err
+	}
+	stripped := &Response{Seq: resp.Seq}
+	if err := stripped.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	if stripped.Error.Message != "" {
+		return nil, fmt.Errorf("error in response body: %w", (*OvsdbError)(&stripped.Error))
+	}
+	return stripped, nil
+}
+
+// Transact sends the query, e.g. "SELECT * FROM Bridge WHERE name==?", to
+// the database. The placeholders of the query are bound to the arguments,
+// see NewOperation.
+func (c *Client) Transact(db string, query string, args ...interface{}) (Result, error) {
+	return c.TransactContext(context.Background(), db, query, args...)
+}
+
+// TransactContext is like Transact, but the request is abandoned when the
+// context is cancelled or its deadline expires.
+func (c *Client) TransactContext(ctx context.Context, db string, query string, args ...interface{}) (Result, error) {
 	if c == nil {
 		return Result{}, fmt.Errorf("interface is unavailable")
 	}
-	op, err := NewOperation(query)
+	ctx, done, err := c.admit(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("'transact' method, query: '%s' failed: %w", query, err)
+	}
+	defer done()
+	op, err := c.newOperation(query, args...)
 	if err != nil {
 		return Result{}, err
 	}
@@ -69,20 +212,212 @@ func (c *Client) Transact(db string, query string) (Result, error) {
 	}
 	params.Operations = append(params.Operations, op)
 	method := "transact"
-	response, err := c.query(method, params)
+	response, err := c.transact(ctx, params)
 	if err != nil {
-		return Result{}, fmt.Errorf("'%s' method, query: '%s' failed: %v", method, query, err)
+		return Result{}, fmt.Errorf("'%s' method, query: '%s' failed: %w", method, query, err)
 	}
 	var r Result
 	if err := json.Unmarshal(response.Result, &r); err != nil {
 		return Result{}, fmt.Errorf("'%s' method, query: '%s' failed: %v", method, query, err)
 	}
+	r.applyOrder(op)
 	r.Database = db
 	r.Table = op.Table
-	columns, err := c.getColumns(db, op.Table)
+	columns, err := c.getColumns(ctx, db, op.Table)
 	if err != nil {
 		return Result{}, fmt.Errorf("'%s' method, query: '%s' failed: %v", method, query, err)
 	}
-	r.Columns = columns
+	r.Columns = projectColumns(columns, op.Columns)
 	return r, nil
 }
+
+// projectColumns returns the types of the columns selected by the
+// operation, or of all the columns of the table when it selects them all.
+func projectColumns(columns map[string]string, selected []string) map[string]string {
+	if len(selected) == 0 {
+		return columns
+	}
+	out := make(map[string]string, len(selected))
+	for _, column := range selected {
+		if t, exists := columns[column]; exists {
+			out[column] = t
+		}
+	}
+	return out
+}
+
+// TransactEach is like TransactContext, but rather than returning the rows
+// of the result, it passes them to fn one at a time, as they are decoded.
+// It avoids holding the decoded rows of huge results, e.g. all the logical
+// flows of a large southbound database, in memory at once. It stops at the
+// first error of fn and returns it.
+func (c *Client) TransactEach(ctx context.Context, db string, query string, fn func(Row) error) error {
+	if c == nil {
+		return fmt.Errorf("interface is unavailable")
+	}
+	ctx, done, err := c.admit(ctx)
+	if err != nil {
+		return fmt.Errorf("'transact' method, query: '%s' failed: %w", query, err)
+	}
+	defer done()
+	op, err := c.newOperation(query)
+	if err != nil {
+		return err
+	}
+	if op.OrderBy != "" {
+		return fmt.Errorf("'transact' method, query: '%s' failed: ORDER BY requires all the rows, use TransactContext", query)
+	}
+	params := Transaction{
+		Database:   db,
+		Operations: []Operation{op},
+	}
+	method := "transact"
+	response, err := c.transact(ctx, params)
+	if err != nil {
+		return fmt.Errorf("'%s' method, query: '%s' failed: %w", method, query, err)
+	}
+	n := 0
+	err = decodeRows(response.Result, func(row Row) error {
+		if op.Limit > 0 && n >= op.Limit {
+			return errLimitReached
+		}
+		n++
+		return fn(row)
+	})
+	if err != nil && err != errLimitReached {
+		return fmt.Errorf("'%s' method, query: '%s' failed: %w", method, query, err)
+	}
+	return nil
+}
+
+// errLimitReached stops the decoding of the rows past the LIMIT of a query.
+var errLimitReached = errors.New("limit reached")
+
+// OperationResult is the result of an operation of a transaction, see
+// RFC 7047, Section 5.2.
+type OperationResult struct {
+	// Count is the number of rows updated, mutated, or deleted.
+	Count int
+	// UUID is the UUID of the row inserted.
+	UUID string
+	// Rows are the rows selected.
+	Rows []Row
+}
+
+// UnmarshalJSON decodes the result of an operation.
+func (r *OperationResult) UnmarshalJSON(b []byte) error {
+	var res struct {
+		Count int      `json:"count"`
+		UUID  []string `json:"uuid"`
+		Rows  []Row    `json:"rows"`
+	}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return err
+	}
+	r.Count = res.Count
+	r.Rows = res.Rows
+	if len(res.UUID) == 2 && res.UUID[0] == "uuid" {
+		r.UUID = res.UUID[1]
+	}
+	return nil
+}
+
+// TransactOperations sends the operations, e.g. built with Insert, Update,
+// Mutate, Delete, Wait, and Select, in a single transaction of the database
+// and returns their results, in the order of the operations. The
+// transaction is atomic: when an operation fails, none of them takes effect
+// and the error of the operation is returned.
+func (c *Client) TransactOperations(ctx context.Context, db string, ops ...Operation) ([]OperationResult, error) {
+	if c == nil {
+		return nil, fmt.Errorf("interface is unavailable")
+	}
+	method := "transact"
+	for i := range ops {
+		if err := ops[i].Validate(); err != nil {
+			return nil, fmt.Errorf("'%s' method, operation %d failed: %s", method, i, err)
+		}
+	}
+	if len(ops) == 0 {
+		return []OperationResult{}, nil
+	}
+	ctx, done, err := c.admit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' method failed: %w", method, err)
+	}
+	defer done()
+	response, err := c.transact(ctx, Transaction{Database: db, Operations: ops})
+	if err != nil {
+		return nil, fmt.Errorf("'%s' method failed: %w", method, err)
+	}
+	raw, err := splitResults(response.Result, len(ops))
+	if err != nil {
+		return nil, fmt.Errorf("'%s' method failed: %w", method, err)
+	}
+	results := make([]OperationResult, len(ops))
+	for i, op := range ops {
+		if err := operationError(raw[i]); err != nil {
+			return nil, &TransactionError{Database: db, Index: i, Operation: op, Err: err, Results: results[:i]}
+		}
+		if err := json.Unmarshal(raw[i], &results[i]); err != nil {
+			return nil, fmt.Errorf("'%s' method, %s operation %d on %s failed: %v", method, op.Name, i, op.Table, err)
+		}
+	}
+	if len(raw) > len(ops) {
+		// The error of the commit follows the results of the operations.
+		if err := operationError(raw[len(ops)]); err != nil {
+			return nil, &TransactionError{Database: db, Index: -1, Err: err, Results: results}
+		}
+	}
+	return results, nil
+}
+
+// splitResults splits the result of a transaction into the results of its
+// n operations, which may be followed by the error of the commit. The
+// result of a single operation comes unwrapped.
+func splitResults(result json.RawMessage, n int) ([]json.RawMessage, error) {
+	if n == 1 && !bytes.HasPrefix(bytes.TrimSpace(result), []byte("[")) {
+		return []json.RawMessage{result}, nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(result, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) < n {
+		return nil, fmt.Errorf("expected %d results, but got %d", n, len(raw))
+	}
+	return raw, nil
+}
+
+// operationError returns the error object of the result of an operation,
+// if any.
+func operationError(result json.RawMessage) *OvsdbError {
+	var e Error
+	if err := json.Unmarshal(result, &e); err == nil && e.Message != "" {
+		return (*OvsdbError)(&e)
+	}
+	return nil
+}
+
+// TransactResult is the outcome of a transaction issued with TransactAsync.
+type TransactResult struct {
+	Result Result
+	Err    error
+}
+
+// TransactAsync issues the transaction in the background and returns a
+// channel which receives its outcome. It allows fanning out many queries,
+// e.g. of different tables, without waiting for each of them in turn. The
+// channel is buffered, so the outcome may be ignored.
+func (c *Client) TransactAsync(db string, query string, args ...interface{}) <-chan TransactResult {
+	return c.TransactAsyncContext(context.Background(), db, query, args...)
+}
+
+// TransactAsyncContext is like TransactAsync, but honors the context.
+func (c *Client) TransactAsyncContext(ctx context.Context, db string, query string, args ...interface{}) <-chan TransactResult {
+	ch := make(chan TransactResult, 1)
+	go func() {
+		r, err := c.TransactContext(ctx, db, query, args...)
+		ch <- TransactResult{Result: r, Err: err}
+	}()
+	return ch
+}
@@ -15,7 +15,13 @@
 package ovsdb
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	//"github.com/davecgh/go-spew/spew"
+	"net"
+	"strings"
 	"testing"
 )
 
@@ -96,3 +102,296 @@ func TestTransactMethod(t *testing.T) {
 		t.Fatalf("Failed %d tests", testsFailed)
 	}
 }
+
+func TestTransactAsync(t *testing.T) {
+	members, remotes := newTestCluster(t, 1, 0)
+	cli, err := NewClient(remotes[0], 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	pending := []<-chan TransactResult{}
+	for i := 0; i < 20; i++ {
+		pending = append(pending, cli.TransactAsync("OVN_Southbound", "SELECT * FROM Chassis"))
+	}
+	for i, ch := range pending {
+		r := <-ch
+		if r.Err != nil {
+			t.Fatalf("FAIL: Test %d: %v", i, r.Err)
+		}
+		if r.Result.Table != "Chassis" {
+			t.Fatalf("FAIL: Test %d: expected result for Chassis table, but got %s", i, r.Result.Table)
+		}
+	}
+	members[0].mux.Lock()
+	received := len(members[0].transactions)
+	members[0].mux.Unlock()
+	if received != len(pending) {
+		t.Fatalf("FAIL: expected %d transactions, but the server received %d", len(pending), received)
+	}
+	t.Logf("PASS: %d asynchronous transactions completed", len(pending))
+}
+
+func TestTransactEach(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	rows := []map[string]interface{}{}
+	for i := 0; i < 1000; i++ {
+		rows = append(rows, map[string]interface{}{
+			"logical_datapath": []interface{}{"uuid", fmt.Sprintf("%08d-0000-0000-0000-000000000000", i)},
+			"match":            "ip4.dst == 10.0.0.1",
+			"table_id":         i,
+		})
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		return []interface{}{map[string]interface{}{"rows": rows}}
+	})
+	remote := "tcp:" + l.Addr().String()
+
+	cli, err := NewClient(remote, 1, WithReadBufferSize(1<<16))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	count := 0
+	err = cli.TransactEach(context.Background(), "OVN_Southbound", "SELECT * FROM Logical_Flow", func(row Row) error {
+		if row["match"] != "ip4.dst == 10.0.0.1" {
+			return fmt.Errorf("unexpected row: %v", row)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FAIL: expected to stream the rows, but failed with: %v", err)
+	}
+	if count != len(rows) {
+		t.Fatalf("FAIL: expected %d rows, but got %d", len(rows), count)
+	}
+	t.Logf("PASS: streamed %d rows", count)
+
+	stop := errors.New("stop")
+	count = 0
+	err = cli.TransactEach(context.Background(), "OVN_Southbound", "SELECT * FROM Logical_Flow", func(row Row) error {
+		count++
+		if count == 10 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || count != 10 {
+		t.Fatalf("FAIL: expected to stop after 10 rows, but got %d rows and error: %v", count, err)
+	}
+	t.Logf("PASS: streaming stopped by the callback")
+
+	count = 0
+	err = cli.TransactEach(context.Background(), "OVN_Southbound", "SELECT * FROM Logical_Flow LIMIT 25", func(row Row) error {
+		count++
+		return nil
+	})
+	if err != nil || count != 25 {
+		t.Fatalf("FAIL: expected to stop after 25 rows, but got %d rows and error: %v", count, err)
+	}
+	t.Logf("PASS: streaming stopped by the limit")
+	if err := cli.TransactEach(context.Background(), "OVN_Southbound", "SELECT * FROM Logical_Flow ORDER BY table_id", func(row Row) error {
+		return nil
+	}); err == nil {
+		t.Fatalf("FAIL: expected ORDER BY to be rejected while streaming")
+	}
+	t.Logf("PASS: streaming rejected ORDER BY")
+
+	limited, err := NewClient(remote, 1, WithMaxResponseSize(4096))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer limited.Close()
+	if err := limited.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: expected a small response to pass, but failed with: %v", err)
+	}
+	err = limited.TransactEach(context.Background(), "OVN_Southbound", "SELECT * FROM Logical_Flow", func(row Row) error {
+		return nil
+	})
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("FAIL: expected response too large error, but got: %v", err)
+	}
+	t.Logf("PASS: response too large: %v", err)
+	if err := limited.Echo("test message"); err != nil {
+		t.Fatalf("FAIL: expected the client to reconnect, but failed with: %v", err)
+	}
+	t.Logf("PASS: client reconnected after a response too large")
+}
+
+func TestDecodeRows(t *testing.T) {
+	testCases := []struct {
+		input string
+		rows  int
+		fail  bool
+	}{
+		{`{"rows":[{"name":"a"},{"name":"b"}]}`, 2, false},
+		{`{"count":1,"rows":[{"name":"a"}],"details":{"x":[1,2]}}`, 1, false},
+		{`{"rows":[]}`, 0, false},
+		{`{"count":1}`, 0, false},
+		{`[{"rows":[]}]`, 0, true},
+		{`{"rows":{"name":"a"}}`, 0, true},
+		{`{"rows":[{"name":"a"}`, 1, true},
+	}
+	for i, tc := range testCases {
+		rows := 0
+		err := decodeRows([]byte(tc.input), func(row Row) error {
+			rows++
+			return nil
+		})
+		if tc.fail != (err != nil) {
+			t.Fatalf("FAIL: Test %d: %s: unexpected error: %v", i, tc.input, err)
+		}
+		if rows != tc.rows {
+			t.Fatalf("FAIL: Test %d: %s: expected %d rows, but got %d", i, tc.input, tc.rows, rows)
+		}
+		t.Logf("PASS: Test %d: %s: %d rows", i, tc.input, rows)
+	}
+}
+
+func TestTransactOperations(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		results := []interface{}{}
+		for _, arg := range args[1:] {
+			var op struct {
+				Name  string `json:"op"`
+				Table string `json:"table"`
+			}
+			json.Unmarshal(arg, &op)
+			switch {
+			case op.Table == "Missing":
+				results = append(results, map[string]interface{}{"error": "unknown table", "details": "No table named Missing."})
+			case op.Name == "insert":
+				results = append(results, map[string]interface{}{"uuid": []interface{}{"uuid", "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"}})
+			case op.Name == "select":
+				results = append(results, map[string]interface{}{"rows": []interface{}{map[string]interface{}{"name": "br-int"}}})
+			case op.Name == "wait":
+				results = append(results, map[string]interface{}{})
+			default:
+				results = append(results, map[string]interface{}{"count": 1})
+			}
+		}
+		if string(args[0]) == `"Constrained"` {
+			results = append(results, map[string]interface{}{"error": "referential integrity violation"})
+		}
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	ctx := context.Background()
+	name := Condition{Column: "name", Function: "==", Value: "vif1", Type: "string"}
+	results, err := cli.TransactOperations(ctx, "Open_vSwitch",
+		Insert("Port", map[string]interface{}{"name": "vif1"}),
+		Update("Port", map[string]interface{}{"tag": 10}, name),
+		Delete("Port", name),
+		Select("Bridge", []string{"name"}),
+	)
+	if err != nil {
+		t.Fatalf("FAIL: expected the transaction to succeed, but failed with: %v", err)
+	}
+	if len(results) != 4 || results[0].UUID != "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8" || results[1].Count != 1 || results[2].Count != 1 || len(results[3].Rows) != 1 {
+		t.Fatalf("FAIL: unexpected results: %+v", results)
+	}
+	t.Logf("PASS: results of the operations: %+v", results)
+
+	results, err = cli.TransactOperations(ctx, "Open_vSwitch", Wait("Bridge", []string{"name"}, "==", nil))
+	if err != nil || len(results) != 1 {
+		t.Fatalf("FAIL: expected the wait operation to succeed, but got %+v, %v", results, err)
+	}
+
+	_, err = cli.TransactOperations(ctx, "Open_vSwitch", Delete("Port", name), Delete("Missing"))
+	if !errors.Is(err, ErrTableNotFound) || !strings.Contains(err.Error(), "operation 1") {
+		t.Fatalf("FAIL: expected unknown table error of the second operation, but got: %v", err)
+	}
+	var txnErr *TransactionError
+	if !errors.As(err, &txnErr) || txnErr.Index != 1 || txnErr.Operation.Table != "Missing" || txnErr.Err.Details != "No table named Missing." {
+		t.Fatalf("FAIL: expected the second operation to be identified, but got: %+v", txnErr)
+	}
+	if len(txnErr.Results) != 1 || txnErr.Results[0].Count != 1 {
+		t.Fatalf("FAIL: expected the result of the first operation, but got: %+v", txnErr.Results)
+	}
+	t.Logf("PASS: failed operation reported: %v", err)
+
+	_, err = cli.TransactOperations(ctx, "Constrained", Delete("Port", name))
+	var ovsdbErr *OvsdbError
+	if !errors.As(err, &ovsdbErr) || ovsdbErr.Message != "referential integrity violation" {
+		t.Fatalf("FAIL: expected the error of the commit, but got: %v", err)
+	}
+	if !errors.As(err, &txnErr) || txnErr.Index != -1 || len(txnErr.Results) != 1 {
+		t.Fatalf("FAIL: expected the commit to be identified, but got: %+v", txnErr)
+	}
+	t.Logf("PASS: failed commit reported: %v", err)
+
+	if _, err := cli.TransactOperations(ctx, "Open_vSwitch", Update("Port", nil)); err == nil {
+		t.Fatalf("FAIL: expected an invalid operation to fail")
+	}
+}
+
+func TestTransactionExplain(t *testing.T) {
+	tx := Transaction{
+		Database: "Open_vSwitch",
+		Operations: []Operation{
+			Select("Bridge", []string{"name"}, Equal("name", "br0")),
+			Mutate("Bridge", []Mutation{{Column: "flood_vlans", Mutator: "insert", Value: []int{10}}}, Equal("name", "br0")),
+		},
+	}
+	b, err := tx.Explain()
+	if err != nil {
+		t.Fatalf("FAIL: failed to explain the transaction: %v", err)
+	}
+	expected := `["Open_vSwitch",{"op":"select","table":"Bridge","where":[["name","==","br0"]],"columns":["name"]},` +
+		`{"op":"mutate","table":"Bridge","where":[["name","==","br0"]],"mutations":[["flood_vlans","insert",["set",[10]]]]}]`
+	if string(b) != expected {
+		t.Fatalf("FAIL: expected %s, got %s", expected, b)
+	}
+	var params []interface{}
+	if err := json.Unmarshal(b, &params); err != nil || len(params) != 3 {
+		t.Fatalf("FAIL: expected a JSON array of 3 params, got %s: %v", b, err)
+	}
+	t.Logf("PASS: %s", b)
+
+	tx.Operations = append(tx.Operations, Operation{Name: "merge", Table: "Bridge"})
+	if _, err := tx.Explain(); err == nil {
+		t.Fatalf("FAIL: expected an error for an unsupported operation")
+	}
+	t.Logf("PASS: rejected an invalid operation")
+
+	txn := (&Client{}).Txn("Open_vSwitch")
+	name := txn.Insert("Port", map[string]interface{}{"name": "p0"})
+	txn.Add(Mutate("Bridge", []Mutation{{Column: "ports", Mutator: "insert", Value: []interface{}{name}}}, Equal("name", "br0")))
+	b, err = txn.Explain()
+	if err != nil {
+		t.Fatalf("FAIL: failed to explain the txn: %v", err)
+	}
+	expected = `["Open_vSwitch",{"op":"insert","table":"Port","row":{"name":"p0"},"uuid-name":"row1"},` +
+		`{"op":"mutate","table":"Bridge","where":[["name","==","br0"]],"mutations":[["ports","insert",["set",[["named-uuid","row1"]]]]]}]`
+	if string(b) != expected || txn.Len() != 2 {
+		t.Fatalf("FAIL: expected %s with 2 operations left, got %s with %d", expected, b, txn.Len())
+	}
+	t.Logf("PASS: %s", b)
+
+	var nilClient *Client
+	if _, err := nilClient.Txn("Open_vSwitch").Explain(); err == nil {
+		t.Fatalf("FAIL: expected an error for a transaction without a client")
+	}
+	t.Logf("PASS: rejected a transaction without a client")
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// Txn builds a transaction of many operations of a database, which take
+// effect atomically on Commit. The rows inserted by the transaction may be
+// referred to by the other operations, e.g. a new Port may be attached to
+// a Bridge in the same transaction. A transaction is not safe for
+// concurrent use.
+type Txn struct {
+	client *Client
+	db     string
+	ops    []Operation
+	named  int
+}
+
+// TxnResult is the result of a committed transaction.
+type TxnResult struct {
+	// Results are the results of the operations, in order.
+	Results []OperationResult
+	// UUIDs are the UUIDs of the inserted rows, by their names.
+	UUIDs map[NamedUUID]string
+}
+
+// Txn returns an empty transaction of the database.
+func (c *Client) Txn(db string) *Txn {
+	return &Txn{client: c, db: db}
+}
+
+// Add adds the operations to the transaction.
+func (t *Txn) Add(ops ...Operation) *Txn {
+	t.ops = append(t.ops, ops...)
+	return t
+}
+
+// Insert adds an insert operation of the row into the table, and returns
+// the name the other operations of the transaction refer to the row by.
+func (t *Txn) Insert(table string, row map[string]interface{}) NamedUUID {
+	t.named++
+	name := NamedUUID(fmt.Sprintf("row%d", t.named))
+	op := Insert(table, row)
+	op.UUIDName = string(name)
+	t.ops = append(t.ops, op)
+	return name
+}
+
+// Verify adds a wait operation which fails the transaction unless the row
+// of the table matching the conditions still has the expected values, see
+// WaitOp.
+func (t *Txn) Verify(table string, expected map[string]interface{}, where ...Condition) *Txn {
+	return t.Add(WaitOp(table, expected, where...))
+}
+
+// UpdateIf adds an update of the row of the table matching the conditions,
+// which takes effect only when the row still has the expected values. The
+// commit fails with ErrConflict otherwise, e.g. when another writer updated
+// the row first.
+func (t *Txn) UpdateIf(table string, expected, row map[string]interface{}, where ...Condition) *Txn {
+	return t.Add(WaitOp(table, expected, where...), Update(table, row, where...))
+}
+
+// Len returns the number of the operations of the transaction.
+func (t *Txn) Len() int {
+	return len(t.ops)
+}
+
+// Explain returns the exact params of the transact request Commit would
+// send, see Transaction.Explain, and fails as Commit would before sending
+// it, e.g. on an operation invalid in the cached schema. The transaction is
+// left as it is.
+func (t *Txn) Explain() ([]byte, error) {
+	if t.client == nil {
+		return nil, fmt.Errorf("explain: interface is unavailable")
+	}
+	tx := Transaction{Database: t.db, Operations: t.ops}
+	if err := t.client.validateTransaction(tx); err != nil {
+		return nil, fmt.Errorf("explain: %w", err)
+	}
+	if stamped, ok := t.client.stamp(tx); ok {
+		tx = stamped
+	}
+	return tx.Explain()
+}
+
+// Comment adds a comment operation, which adds the comment to the log of
+// the database when the transaction commits changes.
+func (t *Txn) Comment(comment string) *Txn {
+	return t.Add(Comment(comment))
+}
+
+// Assert queues an assert operation, which fails the transaction unless
+// the client holds the lock, see Client.Lock.
+func (t *Txn) Assert(lock string) *Txn {
+	return t.Add(Assert(lock))
+}
+
+// Commit sends the operations in a single transaction and returns their
+// results. When an operation fails, the server rolls back the transaction
+// as a whole, and the error identifies the operation. The transaction is
+// empty afterwards.
+func (t *Txn) Commit(ctx context.Context) (*TxnResult, error) {
+	ops := t.ops
+	t.ops, t.named = nil, 0
+	results, err := t.client.TransactOperations(ctx, t.db, ops...)
+	if err != nil {
+		return nil, err
+	}
+	r := &TxnResult{
+		Results: results,
+		UUIDs:   make(map[NamedUUID]string),
+	}
+	for i, op := range ops {
+		if op.Name == "insert" && op.UUIDName != "" {
+			r.UUIDs[NamedUUID(op.UUIDName)] = results[i].UUID
+		}
+	}
+	return r, nil
+}
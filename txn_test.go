@@ -0,0 +1,255 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTxnCommit(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var received []json.RawMessage
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		mu.Lock()
+		received = args[1:]
+		mu.Unlock()
+		results := []interface{}{}
+		for i, arg := range args[1:] {
+			var op struct {
+				Name  string `json:"op"`
+				Table string `json:"table"`
+			}
+			json.Unmarshal(arg, &op)
+			switch {
+			case op.Table == "Missing":
+				results = append(results, map[string]interface{}{"error": "unknown table"})
+				// The operations after the failed one are not executed.
+				for range args[i+2:] {
+					results = append(results, nil)
+				}
+				return results
+			case op.Name == "insert":
+				results = append(results, map[string]interface{}{"uuid": []interface{}{"uuid", "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"}})
+			default:
+				results = append(results, map[string]interface{}{"count": 1})
+			}
+		}
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	txn := cli.Txn("Open_vSwitch")
+	iface := txn.Insert("Interface", map[string]interface{}{"name": "vif1"})
+	port := txn.Insert("Port", map[string]interface{}{"name": "vif1", "interfaces": []NamedUUID{iface}})
+	txn.Add(Mutate("Bridge", []Mutation{{Column: "ports", Mutator: "insert", Value: []NamedUUID{port}}}, Equal("name", "br-int")))
+	if txn.Len() != 3 {
+		t.Fatalf("FAIL: expected 3 operations, but got %d", txn.Len())
+	}
+	r, err := txn.Commit(context.Background())
+	if err != nil {
+		t.Fatalf("FAIL: expected the transaction to commit, but failed with: %v", err)
+	}
+	if len(r.Results) != 3 || r.Results[2].Count != 1 || r.UUIDs[port] != "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8" || len(r.UUIDs) != 2 {
+		t.Fatalf("FAIL: unexpected result: %+v", r)
+	}
+	mu.Lock()
+	wire := string(received[1]) + string(received[2])
+	mu.Unlock()
+	if !strings.Contains(wire, `"uuid-name":"row2"`) || !strings.Contains(wire, `["named-uuid","row1"]`) || !strings.Contains(wire, `["named-uuid","row2"]`) {
+		t.Fatalf("FAIL: expected references to the named rows, but sent: %s", wire)
+	}
+	t.Logf("PASS: transaction committed: %+v", r)
+	if txn.Len() != 0 {
+		t.Fatalf("FAIL: expected the transaction to be empty after commit")
+	}
+
+	txn.Insert("Port", map[string]interface{}{"name": "vif2"})
+	txn.Add(Delete("Missing"), Delete("Port"))
+	_, err = txn.Commit(context.Background())
+	if !errors.Is(err, ErrTableNotFound) || !strings.Contains(err.Error(), "operation 1") {
+		t.Fatalf("FAIL: expected the error of the second operation, but got: %v", err)
+	}
+	t.Logf("PASS: transaction rolled back: %v", err)
+
+	bad := Insert("Port", nil)
+	bad.UUIDName = "1port"
+	if _, err := txn.Add(bad).Commit(context.Background()); err == nil {
+		t.Fatalf("FAIL: expected an invalid uuid-name to fail")
+	}
+}
+
+func TestTxnUpdateIf(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	// The server keeps the version of a single row, and evaluates the
+	// wait operations against it.
+	var mu sync.Mutex
+	version := 1.0
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		mu.Lock()
+		defer mu.Unlock()
+		results := []interface{}{}
+		for _, arg := range args[1:] {
+			var op struct {
+				Name    string                   `json:"op"`
+				Until   string                   `json:"until"`
+				Rows    []map[string]interface{} `json:"rows"`
+				Row     map[string]interface{}   `json:"row"`
+				Timeout *int                     `json:"timeout"`
+			}
+			json.Unmarshal(arg, &op)
+			switch op.Name {
+			case "wait":
+				if op.Until != "==" || op.Timeout == nil || *op.Timeout != 0 || len(op.Rows) != 1 || op.Rows[0]["version"] != version {
+					return append(results, map[string]interface{}{"error": "timed out", "details": `"wait" timed out`})
+				}
+				results = append(results, map[string]interface{}{})
+			case "update":
+				version = op.Row["version"].(float64)
+				results = append(results, map[string]interface{}{"count": 1})
+			}
+		}
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	ctx := context.Background()
+	where := Equal("name", "br-int")
+	update := func(from, to int) error {
+		_, err := cli.Txn("Open_vSwitch").
+			UpdateIf("Bridge", map[string]interface{}{"version": from}, map[string]interface{}{"version": to}, where).
+			Commit(ctx)
+		return err
+	}
+	if err := update(1, 2); err != nil {
+		t.Fatalf("FAIL: expected the update to succeed, but failed with: %v", err)
+	}
+	t.Logf("PASS: row updated from version 1 to 2")
+	err = update(1, 3)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("FAIL: expected a conflict, but got: %v", err)
+	}
+	t.Logf("PASS: stale update rejected: %v", err)
+
+	if _, err := cli.Txn("Open_vSwitch").Verify("Bridge", map[string]interface{}{"version": 2}, where).Commit(ctx); err != nil {
+		t.Fatalf("FAIL: expected the verification to succeed, but failed with: %v", err)
+	}
+	b, _ := json.Marshal(WaitOp("Bridge", map[string]interface{}{"version": 2, "name": "br-int"}, where))
+	want := `{"op":"wait","table":"Bridge","where":[["name","==","br-int"]],"columns":["name","version"],"until":"==","rows":[{"name":"br-int","version":2}],"timeout":0}`
+	if string(b) != want {
+		t.Fatalf("FAIL: expected '%s', but got '%s'", want, b)
+	}
+}
+
+func TestTxnIdentity(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var received [][]json.RawMessage
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		if method != "transact" {
+			return nil
+		}
+		var args []json.RawMessage
+		json.Unmarshal(params, &args)
+		mu.Lock()
+		received = append(received, args[1:])
+		mu.Unlock()
+		results := []interface{}{}
+		for _, arg := range args[1:] {
+			var op struct {
+				Name  string `json:"op"`
+				Table string `json:"table"`
+			}
+			json.Unmarshal(arg, &op)
+			switch {
+			case op.Table == "Missing":
+				results = append(results, map[string]interface{}{"error": "unknown table"})
+			case op.Name == "select":
+				results = append(results, map[string]interface{}{"rows": []interface{}{map[string]interface{}{"name": "br0"}}})
+			case op.Name == "comment":
+				results = append(results, map[string]interface{}{})
+			default:
+				results = append(results, map[string]interface{}{"count": 1})
+			}
+		}
+		return results
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1, WithIdentity("exporter@host1"))
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+
+	results, err := cli.TransactOperations(context.Background(), "Open_vSwitch", Delete("Port", Equal("name", "vif1")))
+	if err != nil || len(results) != 1 || results[0].Count != 1 {
+		t.Fatalf("FAIL: expected the result of the delete only, got %+v, %v", results, err)
+	}
+	if _, err := cli.TransactOperations(context.Background(), "Open_vSwitch", Select("Bridge", []string{"name"})); err != nil {
+		t.Fatalf("FAIL: expected the select to pass, but failed with: %v", err)
+	}
+	_, err = cli.TransactOperations(context.Background(), "Open_vSwitch", Delete("Missing"))
+	if !errors.Is(err, ErrTableNotFound) {
+		t.Fatalf("FAIL: expected the error of the delete, got %v", err)
+	}
+	mu.Lock()
+	if len(received) != 3 || len(received[0]) != 2 || len(received[1]) != 1 || len(received[2]) != 2 {
+		t.Fatalf("FAIL: expected the modifying transactions only to be stamped, got %v", received)
+	}
+	if string(received[0][1]) != `{"op":"comment","comment":"exporter@host1"}` {
+		t.Fatalf("FAIL: unexpected comment: %s", received[0][1])
+	}
+	mu.Unlock()
+	t.Logf("PASS: stamped the modifying transactions")
+
+	b, err := cli.Txn("Open_vSwitch").Comment("rename").Add(Update("Bridge", map[string]interface{}{"name": "br1"}, Equal("name", "br0"))).Explain()
+	expected := `["Open_vSwitch",{"op":"comment","comment":"rename"},{"op":"update","table":"Bridge","where":[["name","==","br0"]],"row":{"name":"br1"}},{"op":"comment","comment":"exporter@host1"}]`
+	if err != nil || string(b) != expected {
+		t.Fatalf("FAIL: expected %s, got %s, %v", expected, b, err)
+	}
+	t.Logf("PASS: %s", b)
+}
@@ -24,11 +24,14 @@ import (
 )
 
 func parseSocket(s string) (string, string, error) {
-	if strings.HasPrefix(s, "unix") {
-		arr := strings.Split(s, ":")
-		return arr[0], arr[1], nil
+	r, err := ParseRemote(s)
+	if err != nil {
+		return "", "", err
+	}
+	if r.Passive {
+		return "", "", fmt.Errorf("invalid remote %s: cannot connect to a passive remote", s)
 	}
-	return "tcp", s, nil
+	return r.Proto, r.Address, nil
 }
 
 func encodeString(s string) (string, error) {
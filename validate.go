@@ -0,0 +1,274 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// WithoutSchemaValidation makes a client send its transactions without
+// validating them against the cached schemas first, see
+// Schema.ValidateOperation.
+func WithoutSchemaValidation() ClientOption {
+	return func(cli *Client) error {
+		cli.SkipSchemaValidation = true
+		return nil
+	}
+}
+
+// wireOperation is an operation as it is sent, in the OVSDB notation.
+type wireOperation struct {
+	Name      string                   `json:"op"`
+	Table     string                   `json:"table"`
+	Where     [][]interface{}          `json:"where"`
+	Columns   []string                 `json:"columns"`
+	Row       map[string]interface{}   `json:"row"`
+	Rows      []map[string]interface{} `json:"rows"`
+	Mutations [][]interface{}          `json:"mutations"`
+}
+
+// ValidateOperation checks the operation against the schema: its table,
+// the columns it refers to, the types of its values, the membership of
+// the values in the enums, the number of elements of the sets, and the
+// immutable columns it updates or mutates. The violations of the
+// constraints of the schema are reported as ErrConstraintViolation, and
+// the unknown tables and columns as ErrTableNotFound and ErrColumnNotFound.
+func (sc *Schema) ValidateOperation(op Operation) error {
+	switch op.Name {
+	case "comment", "assert", "commit", "abort":
+		return nil
+	}
+	b, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	var w wireOperation
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+	t := sc.Table(w.Table)
+	if t == nil {
+		return &tableNotFoundError{w.Table}
+	}
+	column := func(name string) (*Column, error) {
+		if c := t.Column(name); c != nil {
+			return c, nil
+		}
+		return nil, fmt.Errorf("column %s of table %s: %w", name, w.Table, ErrColumnNotFound)
+	}
+	for _, name := range w.Columns {
+		if _, err := column(name); err != nil {
+			return err
+		}
+	}
+	for _, cond := range w.Where {
+		name, _ := cond[0].(string)
+		c, err := column(name)
+		if err != nil {
+			return err
+		}
+		if err := c.checkOperand(cond[2]); err != nil {
+			return fmt.Errorf("condition on column %s of table %s: %s: %w", name, w.Table, err, ErrConstraintViolation)
+		}
+	}
+	for name, value := range w.Row {
+		c, err := column(name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case name == "_uuid" || name == "_version":
+			return fmt.Errorf("column %s of table %s is read-only: %w", name, w.Table, ErrConstraintViolation)
+		case w.Name == "update" && !c.Mutable:
+			return fmt.Errorf("column %s of table %s is immutable: %w", name, w.Table, ErrConstraintViolation)
+		}
+		if err := c.checkValue(value); err != nil {
+			return fmt.Errorf("column %s of table %s: %s: %w", name, w.Table, err, ErrConstraintViolation)
+		}
+	}
+	for _, row := range w.Rows {
+		for name, value := range row {
+			c, err := column(name)
+			if err != nil {
+				return err
+			}
+			if err := c.checkOperand(value); err != nil {
+				return fmt.Errorf("column %s of table %s: %s: %w", name, w.Table, err, ErrConstraintViolation)
+			}
+		}
+	}
+	for _, m := range w.Mutations {
+		name, _ := m[0].(string)
+		mutator, _ := m[1].(string)
+		c, err := column(name)
+		if err != nil {
+			return err
+		}
+		if name == "_uuid" || name == "_version" || !c.Mutable {
+			return fmt.Errorf("column %s of table %s is immutable: %w", name, w.Table, ErrConstraintViolation)
+		}
+		switch mutator {
+		case "+=", "-=", "*=", "/=", "%=":
+			if c.IsMap() || (c.Key.Type != "integer" && c.Key.Type != "real") || (mutator == "%=" && c.Key.Type != "integer") {
+				return fmt.Errorf("column %s of table %s: mutator %s requires an integer or a real column: %w", name, w.Table, mutator, ErrConstraintViolation)
+			}
+		}
+		if err := c.checkOperand(m[2]); err != nil {
+			return fmt.Errorf("mutation of column %s of table %s: %s: %w", name, w.Table, err, ErrConstraintViolation)
+		}
+	}
+	return nil
+}
+
+// checkValue checks the value of the column, in the OVSDB notation, as
+// stored by an insert or update operation.
+func (c *Column) checkValue(v interface{}) error {
+	kind, elems := splitValue(v)
+	if kind == "map" && !c.IsMap() || kind != "map" && c.IsMap() && !(kind == "set" && len(elems) == 0) {
+		return fmt.Errorf("unexpected %s value", kind)
+	}
+	if len(elems) < c.Min || c.Max != Unlimited && len(elems) > c.Max {
+		return fmt.Errorf("%d elements, expected %s", len(elems), c.size())
+	}
+	for _, elem := range elems {
+		if kind != "map" {
+			if err := c.Key.checkAtom(elem, true); err != nil {
+				return err
+			}
+			continue
+		}
+		pair, ok := elem.([]interface{})
+		if !ok || len(pair) != 2 {
+			return fmt.Errorf("invalid pair %v", elem)
+		}
+		if err := c.Key.checkAtom(pair[0], true); err != nil {
+			return fmt.Errorf("key: %s", err)
+		}
+		if err := c.Value.checkAtom(pair[1], true); err != nil {
+			return fmt.Errorf("value: %s", err)
+		}
+	}
+	return nil
+}
+
+// checkOperand checks the operand of a condition, a mutation, or a wait
+// operation: an atom, a set, or a map of the types of the column. The
+// values need not be members of the enums.
+func (c *Column) checkOperand(v interface{}) error {
+	kind, elems := splitValue(v)
+	if kind == "map" && !c.IsMap() {
+		return fmt.Errorf("unexpected map value")
+	}
+	for _, elem := range elems {
+		if kind != "map" {
+			if err := c.Key.checkAtom(elem, false); err != nil {
+				return err
+			}
+			continue
+		}
+		pair, ok := elem.([]interface{})
+		if !ok || len(pair) != 2 {
+			return fmt.Errorf("invalid pair %v", elem)
+		}
+		if err := c.Key.checkAtom(pair[0], false); err != nil {
+			return fmt.Errorf("key: %s", err)
+		}
+		if err := c.Value.checkAtom(pair[1], false); err != nil {
+			return fmt.Errorf("value: %s", err)
+		}
+	}
+	return nil
+}
+
+// size describes the number of elements of the values of the column.
+func (c *Column) size() string {
+	switch {
+	case c.Max == Unlimited:
+		return fmt.Sprintf("at least %d", c.Min)
+	case c.Min == c.Max:
+		return fmt.Sprintf("%d", c.Min)
+	}
+	return fmt.Sprintf("%d to %d", c.Min, c.Max)
+}
+
+// checkAtom checks the type of the atom and, when strict, its constraints.
+func (bt *BaseType) checkAtom(v interface{}, strict bool) error {
+	switch bt.Type {
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			return fmt.Errorf("%v is not an integer", v)
+		}
+		if strict && (bt.MinInteger != nil && n < float64(*bt.MinInteger) || bt.MaxInteger != nil && n > float64(*bt.MaxInteger)) {
+			return fmt.Errorf("%v is out of range", v)
+		}
+	case "real":
+		n, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("%v is not a real", v)
+		}
+		if strict && (bt.MinReal != nil && n < *bt.MinReal || bt.MaxReal != nil && n > *bt.MaxReal) {
+			return fmt.Errorf("%v is out of range", v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%v is not a boolean", v)
+		}
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a string", v)
+		}
+		if strict && (bt.MinLength != nil && len(s) < *bt.MinLength || bt.MaxLength != nil && len(s) > *bt.MaxLength) {
+			return fmt.Errorf("the length of %q is out of range", s)
+		}
+	case "uuid":
+		arr, ok := v.([]interface{})
+		if !ok || len(arr) != 2 || (arr[0] != "uuid" && arr[0] != "named-uuid") {
+			return fmt.Errorf("%v is not a uuid", v)
+		}
+	}
+	if !strict || bt.Enum == nil {
+		return nil
+	}
+	for _, e := range bt.Enum {
+		if e == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("%v is not one of %v", v, bt.Enum)
+}
+
+// validateTransaction checks the operations of the transaction against the
+// cached schema of its database, if any.
+func (c *Client) validateTransaction(t Transaction) error {
+	if c.SkipSchemaValidation {
+		return nil
+	}
+	c.cacheMux.Lock()
+	schema, exists := c.Schemas[t.Database]
+	c.cacheMux.Unlock()
+	if !exists {
+		return nil
+	}
+	for i, op := range t.Operations {
+		if err := schema.ValidateOperation(op); err != nil {
+			return fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Paul Greenberg greenpau@outlook.com
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSchemaValidateOperation(t *testing.T) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(testModelSchema), &schema); err != nil {
+		t.Fatalf("FAIL: failed to parse the schema: %v", err)
+	}
+	testcases := []struct {
+		name   string
+		op     Operation
+		target error
+		err    string
+	}{
+		{name: "select", op: Select("Bridge", []string{"name", "_uuid"}, Equal("name", "br0"))},
+		{name: "insert", op: Insert("Bridge", map[string]interface{}{
+			"name":         "br0",
+			"ports":        []UUID{"36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"},
+			"external_ids": map[string]string{"owner": "test"},
+			"fail_mode":    Some("secure"),
+			"flood_vlans":  []int{1, 4095},
+		})},
+		{name: "named reference", op: Insert("Bridge", map[string]interface{}{"name": "br0", "ports": []NamedUUID{"port0"}})},
+		{name: "comment", op: Comment("no table")},
+		{name: "wait", op: Wait("Bridge", []string{"name"}, "==", []map[string]interface{}{{"name": "br0"}}, Equal("name", "br0"))},
+		{name: "mutate", op: Mutate("Bridge", []Mutation{{Column: "external_ids", Mutator: "insert", Value: map[string]string{"a": "b"}}})},
+		{name: "unknown table", op: Select("Missing", nil), target: ErrTableNotFound, err: "Table Missing not found"},
+		{name: "unknown column", op: Select("Bridge", []string{"nam"}), target: ErrColumnNotFound, err: "column nam of table Bridge"},
+		{name: "unknown condition column", op: Select("Bridge", nil, Equal("nam", "br0")), target: ErrColumnNotFound, err: "column nam"},
+		{name: "condition type", op: Select("Bridge", nil, Equal("name", 5)), target: ErrConstraintViolation, err: "5 is not a string"},
+		{name: "value type", op: Insert("Bridge", map[string]interface{}{"name": true}), target: ErrConstraintViolation, err: "column name of table Bridge: true is not a string"},
+		{name: "not an enum member", op: Insert("Bridge", map[string]interface{}{"fail_mode": "open"}), target: ErrConstraintViolation, err: "open is not one of [standalone secure]"},
+		{name: "out of range", op: Insert("Bridge", map[string]interface{}{"flood_vlans": []int{4096}}), target: ErrConstraintViolation, err: "4096 is out of range"},
+		{name: "not an integer", op: Insert("Bridge", map[string]interface{}{"flood_vlans": []float64{1.5}}), target: ErrConstraintViolation, err: "1.5 is not an integer"},
+		{name: "too many elements", op: Insert("Bridge", map[string]interface{}{"datapath_id": []string{"a", "b"}}), target: ErrConstraintViolation, err: "2 elements, expected 0 to 1"},
+		{name: "map for a set", op: Insert("Bridge", map[string]interface{}{"ports": map[string]string{"a": "b"}}), target: ErrConstraintViolation, err: "unexpected map value"},
+		{name: "not a reference", op: Insert("Bridge", map[string]interface{}{"ports": []string{"port0"}}), target: ErrConstraintViolation, err: "is not a uuid"},
+		{name: "immutable update", op: Update("Bridge", map[string]interface{}{"name": "br1"}), target: ErrConstraintViolation, err: "column name of table Bridge is immutable"},
+		{name: "read-only column", op: Insert("Bridge", map[string]interface{}{"_uuid": UUID("36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c")}), target: ErrConstraintViolation, err: "is read-only"},
+		{name: "immutable mutation", op: Mutate("Bridge", []Mutation{{Column: "name", Mutator: "insert", Value: []string{"a"}}}), target: ErrConstraintViolation, err: "is immutable"},
+		{name: "arithmetic on a string", op: Mutate("Bridge", []Mutation{{Column: "datapath_id", Mutator: "+=", Value: 1}}), target: ErrConstraintViolation, err: "requires an integer or a real column"},
+	}
+	for _, tc := range testcases {
+		err := schema.ValidateOperation(tc.op)
+		if tc.target == nil {
+			if err != nil {
+				t.Fatalf("FAIL: %s: expected the operation to be valid, but failed with: %v", tc.name, err)
+			}
+			t.Logf("PASS: %s: valid", tc.name)
+			continue
+		}
+		if !errors.Is(err, tc.target) || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("FAIL: %s: expected %q (%v), got: %v", tc.name, tc.err, tc.target, err)
+		}
+		t.Logf("PASS: %s: %v", tc.name, err)
+	}
+}
+
+func TestTransactValidation(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var transactions int32
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "transact":
+			atomic.AddInt32(&transactions, 1)
+			return []interface{}{map[string]interface{}{"error": "constraint violation", "details": "sent to the server"}}
+		}
+		return nil
+	})
+	cli, err := NewClient("tcp:"+l.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+	op := Insert("Bridge", map[string]interface{}{"fail_mode": "open"})
+
+	// The schema is not cached yet, hence the server rejects the operation.
+	if _, err := cli.TransactOperations(ctx, "Open_vSwitch", op); !errors.Is(err, ErrConstraintViolation) || !strings.Contains(err.Error(), "sent to the server") {
+		t.Fatalf("FAIL: expected the error of the server, got: %v", err)
+	}
+	if _, err := cli.GetSchema("Open_vSwitch"); err != nil {
+		t.Fatalf("FAIL: failed to get the schema: %v", err)
+	}
+	_, err = cli.TransactOperations(ctx, "Open_vSwitch", Comment("valid"), op)
+	if !errors.Is(err, ErrConstraintViolation) || !strings.Contains(err.Error(), "operation 1: column fail_mode of table Bridge: open is not one of") {
+		t.Fatalf("FAIL: expected the error of the schema validation, got: %v", err)
+	}
+	if n := atomic.LoadInt32(&transactions); n != 1 {
+		t.Fatalf("FAIL: expected the invalid transaction not to be sent, but the server received %d", n)
+	}
+	t.Logf("PASS: invalid transaction rejected locally: %v", err)
+	if _, err := cli.Txn("Open_vSwitch").Add(op).Explain(); !errors.Is(err, ErrConstraintViolation) {
+		t.Fatalf("FAIL: expected the explain to fail the schema validation, got: %v", err)
+	}
+	t.Logf("PASS: invalid transaction not explained")
+
+	cli.SkipSchemaValidation = true
+	if _, err := cli.TransactOperations(ctx, "Open_vSwitch", op); !strings.Contains(err.Error(), "sent to the server") {
+		t.Fatalf("FAIL: expected the error of the server, got: %v", err)
+	}
+	t.Logf("PASS: validation skipped")
+}
@@ -0,0 +1,199 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// UUID is the UUID of a row, which encodes as a reference to the row,
+// e.g. ["uuid", "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"].
+type UUID string
+
+// ParseUUID returns the UUID in its canonical form, i.e. 32 hexadecimal
+// digits in groups of 8, 4, 4, 4, and 12 separated by hyphens.
+func ParseUUID(s string) (UUID, error) {
+	u := UUID(s)
+	if !u.Valid() {
+		return "", fmt.Errorf("invalid uuid %q", s)
+	}
+	return u, nil
+}
+
+// Valid returns whether the UUID is in its canonical form.
+func (u UUID) Valid() bool {
+	if len(u) != 36 {
+		return false
+	}
+	for i := 0; i < len(u); i++ {
+		c := u[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MarshalJSON encodes the UUID in the OVSDB notation.
+func (u UUID) MarshalJSON() ([]byte, error) {
+	if !u.Valid() {
+		return nil, fmt.Errorf("invalid uuid %q", string(u))
+	}
+	return json.Marshal([]string{"uuid", string(u)})
+}
+
+// UnmarshalJSON decodes the UUID from the OVSDB notation.
+func (u *UUID) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalReference(b, "uuid")
+	if err != nil {
+		return err
+	}
+	v, err := ParseUUID(s)
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// NamedUUID refers to the row inserted by an operation of the same
+// transaction, whose uuid-name it is, e.g. ["named-uuid", "new_port"].
+type NamedUUID string
+
+// MarshalJSON encodes the named UUID in the OVSDB notation.
+func (u NamedUUID) MarshalJSON() ([]byte, error) {
+	if !isIdentifier(string(u)) {
+		return nil, fmt.Errorf("invalid named-uuid %q", string(u))
+	}
+	return json.Marshal([]string{"named-uuid", string(u)})
+}
+
+// UnmarshalJSON decodes the named UUID from the OVSDB notation.
+func (u *NamedUUID) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalReference(b, "named-uuid")
+	if err != nil {
+		return err
+	}
+	if !isIdentifier(s) {
+		return fmt.Errorf("invalid named-uuid %q", s)
+	}
+	*u = NamedUUID(s)
+	return nil
+}
+
+// unmarshalReference decodes a reference of the kind, e.g.
+// ["uuid", "..."], and returns its value.
+func unmarshalReference(b []byte, kind string) (string, error) {
+	var arr []string
+	if err := json.Unmarshal(b, &arr); err != nil || len(arr) != 2 || arr[0] != kind {
+		return "", fmt.Errorf("expected a %s reference, got %s", kind, b)
+	}
+	return arr[1], nil
+}
+
+// encodeRow encodes the column values of a row in the OVSDB notation.
+func encodeRow(row map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(row))
+	for column, v := range row {
+		value, err := encodeValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %s", column, err)
+		}
+		out[column] = value
+	}
+	return out, nil
+}
+
+// encodeValue encodes the value in the OVSDB notation, see RFC 7047,
+// Section 5.1. Slices become sets, maps become maps, and nil becomes the
+// empty set. The types implementing json.Marshaler encode themselves.
+func encodeValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return []interface{}{"set", []interface{}{}}, nil
+	}
+	if _, ok := v.(json.Marshaler); ok {
+		return v, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		elems := make([]interface{}, rv.Len())
+		for i := range elems {
+			atom, err := encodeAtom(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = atom
+		}
+		return []interface{}{"set", elems}, nil
+	case reflect.Map:
+		pairs := make([][]interface{}, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key, err := encodeAtom(iter.Key().Interface())
+			if err != nil {
+				return nil, err
+			}
+			value, err := encodeAtom(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, []interface{}{key, value})
+		}
+		// The pairs are sorted to make the encoding deterministic.
+		sort.Slice(pairs, func(i, j int) bool {
+			return fmt.Sprint(pairs[i][0]) < fmt.Sprint(pairs[j][0])
+		})
+		return []interface{}{"map", pairs}, nil
+	}
+	return encodeAtom(v)
+}
+
+// encodeAtom checks that the value is an atom, i.e. an integer, a real, a
+// boolean, a string, or a json.Marshaler, e.g. a UUID.
+func encodeAtom(v interface{}) (interface{}, error) {
+	if _, ok := v.(json.Marshaler); ok {
+		return v, nil
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+// isNumber returns true when the value is an integer or a real.
+func isNumber(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUUID(t *testing.T) {
+	testCases := []struct {
+		s     string
+		valid bool
+	}{
+		{"6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8", true},
+		{"6F3A1C2E-9B8D-4E7F-A1B2-C3D4E5F6A7B8", true},
+		{"6f3a1c2e9b8d4e7fa1b2c3d4e5f6a7b8", false},
+		{"6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7bz", false},
+		{"6f3a1c2e-9b8d-4e7f-a1b2_c3d4e5f6a7b8", false},
+		{"", false},
+	}
+	for _, test := range testCases {
+		u, err := ParseUUID(test.s)
+		if test.valid != (err == nil) {
+			t.Fatalf("FAIL: ParseUUID(%q) returned %v", test.s, err)
+		}
+		if test.valid && string(u) != test.s {
+			t.Fatalf("FAIL: ParseUUID(%q) returned %q", test.s, u)
+		}
+		if _, err := json.Marshal(UUID(test.s)); test.valid != (err == nil) {
+			t.Fatalf("FAIL: marshaling %q returned %v", test.s, err)
+		}
+		t.Logf("PASS: %q valid=%v", test.s, test.valid)
+	}
+
+	var u UUID
+	if err := json.Unmarshal([]byte(`["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]`), &u); err != nil || u != "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8" {
+		t.Fatalf("FAIL: failed to unmarshal the uuid: %q, %v", u, err)
+	}
+	for _, data := range []string{`"6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"`, `["named-uuid","row1"]`, `["uuid","row1"]`} {
+		if err := json.Unmarshal([]byte(data), &u); err == nil {
+			t.Fatalf("FAIL: expected an error for %s", data)
+		}
+	}
+	t.Logf("PASS: unmarshaled the uuids")
+
+	var n NamedUUID
+	if err := json.Unmarshal([]byte(`["named-uuid","new_port"]`), &n); err != nil || n != "new_port" {
+		t.Fatalf("FAIL: failed to unmarshal the named uuid: %q, %v", n, err)
+	}
+	if _, err := json.Marshal(NamedUUID("1port")); err == nil {
+		t.Fatalf("FAIL: expected an error for an invalid named uuid")
+	}
+	t.Logf("PASS: named uuids")
+
+	// The references are kept typed in the untyped values of a row.
+	var row Row
+	json.Unmarshal([]byte(`{"ports":["set",[["uuid","6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8"]]],"_uuid":["uuid","x"]}`), &row)
+	var ports []interface{}
+	if err := row.Get("ports", &ports); err != nil || ports[0] != UUID("6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b8") {
+		t.Fatalf("FAIL: expected a UUID element, got %#v, %v", ports, err)
+	}
+	if _, err := row.GetUUID("_uuid"); err == nil {
+		t.Fatalf("FAIL: expected an error for an invalid uuid")
+	}
+	t.Logf("PASS: row references")
+}
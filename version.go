@@ -0,0 +1,75 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"sort"
+)
+
+// ID returns the UUID of the row, i.e. its _uuid column, or the empty UUID
+// when the column has not been selected.
+func (r Row) ID() UUID {
+	u, _ := r.GetUUID("_uuid")
+	return u
+}
+
+// Version returns the version of the row, i.e. its _version column, which
+// the server changes whenever the row is modified. It is the empty UUID
+// when the column has not been selected, e.g. by "SELECT _uuid, name".
+func (r Row) Version() UUID {
+	v, _ := r.GetUUID("_version")
+	return v
+}
+
+// Versions returns the versions of the rows of the result, by their UUIDs,
+// e.g. to be kept until the next poll, see ChangedSince. The rows without
+// _uuid or _version are left out.
+func (r Result) Versions() map[UUID]UUID {
+	versions := make(map[UUID]UUID, len(r.Rows))
+	for _, row := range r.Rows {
+		id, version := row.ID(), row.Version()
+		if id == "" || version == "" {
+			continue
+		}
+		versions[id] = version
+	}
+	return versions
+}
+
+// ChangedSince compares the rows of the result with the versions of an
+// earlier poll of the table, see Versions. It returns the rows which are
+// new or have been modified since, in the order of the result, and the
+// UUIDs of the rows which are gone. Only the versions are compared, so that
+// the values of the columns need not be. The rows without _uuid or _version
+// are returned as changed, as there is no telling.
+func (r Result) ChangedSince(versions map[UUID]UUID) ([]Row, []UUID) {
+	var changed []Row
+	seen := make(map[UUID]bool, len(r.Rows))
+	for _, row := range r.Rows {
+		id, version := row.ID(), row.Version()
+		seen[id] = true
+		if id == "" || version == "" || versions[id] != version {
+			changed = append(changed, row)
+		}
+	}
+	var removed []UUID
+	for id := range versions {
+		if !seen[id] {
+			removed = append(removed, id)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	return changed, removed
+}
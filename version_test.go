@@ -0,0 +1,62 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestResultChangedSince(t *testing.T) {
+	const (
+		a  = "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b1"
+		b  = "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b2"
+		c  = "6f3a1c2e-9b8d-4e7f-a1b2-c3d4e5f6a7b3"
+		v1 = "7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c1"
+		v2 = "7e2b0d1f-8a9c-4d6e-b0c1-d2e3f4a5b6c2"
+	)
+	decode := func(rows string) Result {
+		var r Result
+		if err := json.Unmarshal([]byte(`{"rows":`+rows+`}`), &r); err != nil {
+			t.Fatalf("FAIL: failed to decode the rows: %v", err)
+		}
+		return r
+	}
+	before := decode(`[{"_uuid":["uuid","` + a + `"],"_version":["uuid","` + v1 + `"]},{"_uuid":["uuid","` + b + `"],"_version":["uuid","` + v1 + `"]}]`)
+	if id, version := before.Rows[0].ID(), before.Rows[0].Version(); id != a || version != v1 {
+		t.Fatalf("FAIL: expected %s at %s, but got %s at %s", a, v1, id, version)
+	}
+	versions := before.Versions()
+	if !reflect.DeepEqual(versions, map[UUID]UUID{a: v1, b: v1}) {
+		t.Fatalf("FAIL: unexpected versions: %v", versions)
+	}
+	t.Logf("PASS: versions %v", versions)
+
+	after := decode(`[{"_uuid":["uuid","` + a + `"],"_version":["uuid","` + v1 + `"]},{"_uuid":["uuid","` + c + `"],"_version":["uuid","` + v2 + `"]}]`)
+	changed, removed := after.ChangedSince(versions)
+	if len(changed) != 1 || changed[0].ID() != c || !reflect.DeepEqual(removed, []UUID{b}) {
+		t.Fatalf("FAIL: expected %s to be added and %s removed, but got %v, %v", c, b, changed, removed)
+	}
+	after = decode(`[{"_uuid":["uuid","` + a + `"],"_version":["uuid","` + v2 + `"]},{"_uuid":["uuid","` + b + `"]}]`)
+	changed, removed = after.ChangedSince(versions)
+	if len(changed) != 2 || len(removed) != 0 {
+		t.Fatalf("FAIL: expected the modified row and the one without a version to change, but got %v, %v", changed, removed)
+	}
+	if changed, _ := before.ChangedSince(versions); len(changed) != 0 {
+		t.Fatalf("FAIL: expected no changes, but got %v", changed)
+	}
+	t.Logf("PASS: detected the changes between the polls")
+}
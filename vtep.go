@@ -0,0 +1,133 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// VtepClient holds the connection to the hardware_vtep database, which
+// top-of-rack switches acting as VXLAN tunnel endpoints, i.e. hardware
+// VTEPs, are controlled through, see vtep(5). The database is usually
+// served by the ovsdb-server of the switch, next to Open_vSwitch.
+//
+// A VtepClient is safe for concurrent use by multiple goroutines.
+type VtepClient struct {
+	mux      sync.RWMutex
+	Database struct {
+		Vtep OvsDatabase
+	}
+	// registered are the databases added with RegisterDatabase.
+	registered registry
+	Timeout    int
+	// Logger, when set, receives the diagnostic messages of the client
+	// and of its database connections.
+	Logger Logger
+	// Options are applied to all the connections of the client: to the
+	// databases, ahead of their own Options, and to the control sockets
+	// of the daemons, e.g. WithTracer, WithObserver, or WithSocketCheck.
+	Options []ClientOption
+}
+
+// NewVtepClient creates an instance of a client for the hardware_vtep
+// database.
+func NewVtepClient() *VtepClient {
+	cli := VtepClient{}
+	cli.Timeout = 2
+
+	cli.Database.Vtep.Name = "hardware_vtep"
+	cli.Database.Vtep.Socket.Remote = "unix:/var/run/openvswitch/db.sock"
+	cli.Database.Vtep.File.Data.Path = "/etc/openvswitch/vtep.db"
+	cli.Database.Vtep.Process.User = "openvswitch"
+	cli.Database.Vtep.Process.Group = "openvswitch"
+	cli.Database.Vtep.Version = "unknown"
+	cli.Database.Vtep.Schema.Version = "unknown"
+	cli.Database.Vtep.Port.Default = 6640
+	cli.Database.Vtep.Port.Ssl = 6630
+
+	return &cli
+}
+
+// Connect initiates the connection to the hardware_vtep database, and to
+// the databases added with RegisterDatabase.
+func (cli *VtepClient) Connect() error {
+	return cli.ConnectContext(context.Background())
+}
+
+// ConnectContext is like Connect, but honors the context.
+func (cli *VtepClient) ConnectContext(ctx context.Context) error {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	errMsgs := []string{}
+	for _, db := range append([]*OvsDatabase{&cli.Database.Vtep}, cli.registered.sorted()...) {
+		if err := db.connect(ctx, cli.Timeout, cli.Logger, cli.Options); err != nil {
+			errMsgs = append(errMsgs, err.Error())
+		}
+	}
+	if len(errMsgs) > 0 {
+		return fmt.Errorf("%s", errMsgs)
+	}
+	return nil
+}
+
+// Close closes the connections of the client.
+func (cli *VtepClient) Close() {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	if cli.Database.Vtep.Client != nil {
+		cli.Database.Vtep.Client.Close()
+	}
+	for _, db := range cli.registered {
+		if db.Client != nil {
+			db.Client.Close()
+		}
+	}
+}
+
+// RLock locks the client for reading its fields.
+func (cli *VtepClient) RLock() {
+	cli.mux.RLock()
+}
+
+// RUnlock undoes a single RLock call.
+func (cli *VtepClient) RUnlock() {
+	cli.mux.RUnlock()
+}
+
+// RegisterDatabase adds a database, other than Vtep, to the client. The
+// next Connect connects to it, and Close closes the connection.
+func (cli *VtepClient) RegisterDatabase(db *OvsDatabase) error {
+	cli.mux.Lock()
+	defer cli.mux.Unlock()
+	return cli.registered.register(db, &cli.Database.Vtep)
+}
+
+// LookupDatabase returns the database named name, either Vtep or one added
+// with RegisterDatabase, or nil.
+func (cli *VtepClient) LookupDatabase(name string) *OvsDatabase {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	return cli.registered.lookup(name, &cli.Database.Vtep)
+}
+
+// DatabaseNames returns the names of the databases of the client, i.e.
+// Vtep and the ones added with RegisterDatabase.
+func (cli *VtepClient) DatabaseNames() []string {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	return cli.registered.names(&cli.Database.Vtep)
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"net"
+	"sort"
+)
+
+// VtepUcastMacRemote holds the information about a unicast MAC address
+// learned by the control plane, e.g. of a hypervisor, i.e. a row of the
+// Ucast_Macs_Remote table, together with the logical switch and the
+// tunnel endpoint it refers to.
+type VtepUcastMacRemote struct {
+	UUID string `json:"uuid" yaml:"uuid" ovsdb:"_uuid"`
+	MAC  string `json:"mac" yaml:"mac" ovsdb:"MAC"`
+	// IPAddress is the IP address of the MAC address, the empty string
+	// when it is unknown.
+	IPAddress         string `json:"ipaddr" yaml:"ipaddr" ovsdb:"ipaddr"`
+	LogicalSwitch     string `json:"logical_switch" yaml:"logical_switch" ovsdb:"logical_switch"`
+	LogicalSwitchName string `json:"logical_switch_name" yaml:"logical_switch_name"`
+	Locator           string `json:"locator" yaml:"locator" ovsdb:"locator"`
+	// LocatorIP is the address of the tunnel endpoint the MAC address is
+	// reached through, and Encapsulation its tunnel type, e.g.
+	// "vxlan_over_ipv4".
+	LocatorIP     net.IP `json:"locator_ip" yaml:"locator_ip"`
+	Encapsulation string `json:"encapsulation_type" yaml:"encapsulation_type"`
+}
+
+// vtepLogicalSwitch is a row of the Logical_Switch table.
+type vtepLogicalSwitch struct {
+	UUID string `ovsdb:"_uuid"`
+	Name string `ovsdb:"name"`
+}
+
+// vtepPhysicalLocator is a row of the Physical_Locator table.
+type vtepPhysicalLocator struct {
+	UUID          string `ovsdb:"_uuid"`
+	DstIP         string `ovsdb:"dst_ip"`
+	Encapsulation string `ovsdb:"encapsulation_type"`
+}
+
+// GetUcastMacsRemote returns the remote unicast MAC addresses of the
+// database, sorted by logical switch name and MAC address.
+func (cli *VtepClient) GetUcastMacsRemote() ([]*VtepUcastMacRemote, error) {
+	return cli.GetUcastMacsRemoteContext(context.Background())
+}
+
+// GetUcastMacsRemoteContext is like GetUcastMacsRemote, but honors the
+// context.
+func (cli *VtepClient) GetUcastMacsRemoteContext(ctx context.Context) ([]*VtepUcastMacRemote, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	macs := []*VtepUcastMacRemote{}
+	switches := []vtepLogicalSwitch{}
+	locators := []vtepPhysicalLocator{}
+	tables := []string{"Ucast_Macs_Remote", "Logical_Switch", "Physical_Locator"}
+	if err := cli.Database.Vtep.selectRows(ctx, tables, &macs, &switches, &locators); err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(switches))
+	for _, ls := range switches {
+		names[ls.UUID] = ls.Name
+	}
+	byUUID := make(map[string]vtepPhysicalLocator, len(locators))
+	for _, locator := range locators {
+		byUUID[locator.UUID] = locator
+	}
+	for _, mac := range macs {
+		mac.LogicalSwitchName = names[mac.LogicalSwitch]
+		if locator, exists := byUUID[mac.Locator]; exists {
+			mac.LocatorIP = net.ParseIP(locator.DstIP)
+			mac.Encapsulation = locator.Encapsulation
+		}
+	}
+	sort.Slice(macs, func(i, j int) bool {
+		if macs[i].LogicalSwitchName != macs[j].LogicalSwitchName {
+			return macs[i].LogicalSwitchName < macs[j].LogicalSwitchName
+		}
+		return macs[i].MAC < macs[j].MAC
+	})
+	return macs, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"sort"
+)
+
+// VtepPhysicalSwitch holds the information about a hardware VTEP, i.e. a
+// row of the Physical_Switch table.
+type VtepPhysicalSwitch struct {
+	UUID          string            `json:"uuid" yaml:"uuid" ovsdb:"_uuid"`
+	Name          string            `json:"name" yaml:"name" ovsdb:"name"`
+	Description   string            `json:"description" yaml:"description" ovsdb:"description"`
+	ManagementIPs []string          `json:"management_ips" yaml:"management_ips" ovsdb:"management_ips"`
+	TunnelIPs     []string          `json:"tunnel_ips" yaml:"tunnel_ips" ovsdb:"tunnel_ips"`
+	Ports         []string          `json:"ports" yaml:"ports" ovsdb:"ports"`
+	FaultStatus   []string          `json:"switch_fault_status" yaml:"switch_fault_status" ovsdb:"switch_fault_status"`
+	OtherConfig   map[string]string `json:"other_config" yaml:"other_config" ovsdb:"other_config"`
+}
+
+// VtepPhysicalPort holds the information about a port of a hardware VTEP,
+// i.e. a row of the Physical_Port table.
+type VtepPhysicalPort struct {
+	UUID        string `json:"uuid" yaml:"uuid" ovsdb:"_uuid"`
+	Name        string `json:"name" yaml:"name" ovsdb:"name"`
+	Description string `json:"description" yaml:"description" ovsdb:"description"`
+	// VLANBindings maps the VLANs of the port to the UUIDs of the logical
+	// switches they are bound to, with VLAN 0 standing for the untagged
+	// traffic.
+	VLANBindings map[int64]string `json:"vlan_bindings" yaml:"vlan_bindings" ovsdb:"vlan_bindings"`
+	// VLANStats maps the VLANs to the UUIDs of their Logical_Binding_Stats.
+	VLANStats   map[int64]string `json:"vlan_stats" yaml:"vlan_stats" ovsdb:"vlan_stats"`
+	FaultStatus []string         `json:"port_fault_status" yaml:"port_fault_status" ovsdb:"port_fault_status"`
+	// Switch is the name of the physical switch the port belongs to.
+	Switch string `json:"switch" yaml:"switch"`
+}
+
+// GetPhysicalSwitches returns the hardware VTEPs of the database, sorted
+// by name.
+func (cli *VtepClient) GetPhysicalSwitches() ([]*VtepPhysicalSwitch, error) {
+	return cli.GetPhysicalSwitchesContext(context.Background())
+}
+
+// GetPhysicalSwitchesContext is like GetPhysicalSwitches, but honors the
+// context.
+func (cli *VtepClient) GetPhysicalSwitchesContext(ctx context.Context) ([]*VtepPhysicalSwitch, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	switches := []*VtepPhysicalSwitch{}
+	if err := cli.Database.Vtep.selectRows(ctx, []string{"Physical_Switch"}, &switches); err != nil {
+		return nil, err
+	}
+	sort.Slice(switches, func(i, j int) bool { return switches[i].Name < switches[j].Name })
+	return switches, nil
+}
+
+// GetPhysicalPorts returns the ports of the hardware VTEPs of the database,
+// sorted by the names of their switches and their own names.
+func (cli *VtepClient) GetPhysicalPorts() ([]*VtepPhysicalPort, error) {
+	return cli.GetPhysicalPortsContext(context.Background())
+}
+
+// GetPhysicalPortsContext is like GetPhysicalPorts, but honors the context.
+func (cli *VtepClient) GetPhysicalPortsContext(ctx context.Context) ([]*VtepPhysicalPort, error) {
+	cli.mux.RLock()
+	defer cli.mux.RUnlock()
+	ports := []*VtepPhysicalPort{}
+	switches := []*VtepPhysicalSwitch{}
+	if err := cli.Database.Vtep.selectRows(ctx, []string{"Physical_Port", "Physical_Switch"}, &ports, &switches); err != nil {
+		return nil, err
+	}
+	owners := make(map[string]string)
+	for _, sw := range switches {
+		for _, port := range sw.Ports {
+			owners[port] = sw.Name
+		}
+	}
+	for _, port := range ports {
+		port.Switch = owners[port.UUID]
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Switch != ports[j].Switch {
+			return ports[i].Switch < ports[j].Switch
+		}
+		return ports[i].Name < ports[j].Name
+	})
+	return ports, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func newTestVtepServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	tables := map[string][]interface{}{
+		"Physical_Switch": {
+			map[string]interface{}{
+				"_uuid":               []interface{}{"uuid", "1b6f3c1e-0d6a-4a4e-9a61-0b0b6f3a2c01"},
+				"name":                "tor-1",
+				"description":         "rack 1",
+				"management_ips":      "10.0.0.11",
+				"tunnel_ips":          []interface{}{"set", []interface{}{"192.168.10.11"}},
+				"ports":               []interface{}{"set", []interface{}{[]interface{}{"uuid", "2c7a4d2f-1e7b-4b5f-8b72-1c1c7a4b3d02"}}},
+				"switch_fault_status": []interface{}{"set", []interface{}{}},
+				"other_config":        []interface{}{"map", []interface{}{}},
+			},
+		},
+		"Physical_Port": {
+			map[string]interface{}{
+				"_uuid":             []interface{}{"uuid", "2c7a4d2f-1e7b-4b5f-8b72-1c1c7a4b3d02"},
+				"name":              "eth1",
+				"description":       "",
+				"vlan_bindings":     []interface{}{"map", []interface{}{[]interface{}{float64(100), []interface{}{"uuid", "3d8b5e30-2f8c-4c60-9c83-2d2d8b5c4e03"}}}},
+				"vlan_stats":        []interface{}{"map", []interface{}{}},
+				"port_fault_status": []interface{}{"set", []interface{}{}},
+			},
+		},
+		"Logical_Switch": {
+			map[string]interface{}{
+				"_uuid": []interface{}{"uuid", "3d8b5e30-2f8c-4c60-9c83-2d2d8b5c4e03"},
+				"name":  "ls0",
+			},
+		},
+		"Physical_Locator": {
+			map[string]interface{}{
+				"_uuid":              []interface{}{"uuid", "4e9c6f41-3a9d-4d71-ad94-3e3e9c6d5f04"},
+				"dst_ip":             "192.168.10.21",
+				"encapsulation_type": "vxlan_over_ipv4",
+			},
+		},
+		"Ucast_Macs_Remote": {
+			map[string]interface{}{
+				"_uuid":          []interface{}{"uuid", "5fad7052-4bae-4e82-bea5-4f4fad7e6a05"},
+				"MAC":            "52:54:00:12:34:56",
+				"ipaddr":         "10.1.0.5",
+				"logical_switch": []interface{}{"uuid", "3d8b5e30-2f8c-4c60-9c83-2d2d8b5c4e03"},
+				"locator":        []interface{}{"uuid", "4e9c6f41-3a9d-4d71-ad94-3e3e9c6d5f04"},
+			},
+			map[string]interface{}{
+				"_uuid":          []interface{}{"uuid", "60be8163-5cbf-4f93-8fb6-505fbe8f7b06"},
+				"MAC":            float64(1),
+				"ipaddr":         "",
+				"logical_switch": []interface{}{"uuid", "3d8b5e30-2f8c-4c60-9c83-2d2d8b5c4e03"},
+				"locator":        []interface{}{"uuid", "4e9c6f41-3a9d-4d71-ad94-3e3e9c6d5f04"},
+			},
+		},
+	}
+	schema, _ := BundledSchema("hardware_vtep")
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		switch method {
+		case "get_schema":
+			return schema
+		case "transact":
+			var args []json.RawMessage
+			json.Unmarshal(params, &args)
+			results := []interface{}{}
+			for _, arg := range args[1:] {
+				var op struct {
+					Table string `json:"table"`
+				}
+				json.Unmarshal(arg, &op)
+				results = append(results, map[string]interface{}{"rows": tables[op.Table]})
+			}
+			return results
+		}
+		return nil
+	})
+	return "tcp:" + l.Addr().String()
+}
+
+func TestVtepClient(t *testing.T) {
+	client := NewVtepClient()
+	client.Database.Vtep.Socket.Remote = newTestVtepServer(t)
+	if _, err := client.GetPhysicalSwitches(); err == nil {
+		t.Fatalf("FAIL: expected the client to fail before connecting")
+	}
+	if err := client.Connect(); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	defer client.Close()
+
+	switches, err := client.GetPhysicalSwitches()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	expectedSwitch := &VtepPhysicalSwitch{
+		UUID:          "1b6f3c1e-0d6a-4a4e-9a61-0b0b6f3a2c01",
+		Name:          "tor-1",
+		Description:   "rack 1",
+		ManagementIPs: []string{"10.0.0.11"},
+		TunnelIPs:     []string{"192.168.10.11"},
+		Ports:         []string{"2c7a4d2f-1e7b-4b5f-8b72-1c1c7a4b3d02"},
+		FaultStatus:   []string{},
+		OtherConfig:   map[string]string{},
+	}
+	if len(switches) != 1 || !reflect.DeepEqual(switches[0], expectedSwitch) {
+		t.Fatalf("FAIL: expected switch %+v, got %+v", expectedSwitch, switches)
+	}
+	t.Logf("PASS: physical switch: %+v", switches[0])
+
+	ports, err := client.GetPhysicalPorts()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(ports) != 1 || ports[0].Switch != "tor-1" || ports[0].VLANBindings[100] != "3d8b5e30-2f8c-4c60-9c83-2d2d8b5c4e03" {
+		t.Fatalf("FAIL: unexpected physical ports: %+v", ports)
+	}
+	t.Logf("PASS: physical port: %+v", ports[0])
+
+	macs, err := client.GetUcastMacsRemote()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(macs) != 1 {
+		t.Fatalf("FAIL: expected the invalid row to be skipped, got: %+v", macs)
+	}
+	mac := macs[0]
+	if mac.MAC != "52:54:00:12:34:56" || mac.LogicalSwitchName != "ls0" || mac.LocatorIP.String() != "192.168.10.21" || mac.Encapsulation != "vxlan_over_ipv4" {
+		t.Fatalf("FAIL: unexpected remote MAC: %+v", mac)
+	}
+	if warnings := client.Database.Vtep.Client.DecodeWarnings(); len(warnings) != 1 || warnings[0].Column != "MAC" {
+		t.Fatalf("FAIL: expected a warning for the invalid row, got: %v", warnings)
+	}
+	t.Logf("PASS: remote MAC: %+v", mac)
+}
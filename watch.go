@@ -0,0 +1,167 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WatchMode is how a watch follows the changes of the rows: the monitor
+// method it requested, or polling.
+type WatchMode string
+
+const (
+	WatchMonitorCondSince WatchMode = "monitor_cond_since"
+	WatchMonitorCond      WatchMode = "monitor_cond"
+	WatchMonitor          WatchMode = "monitor"
+	WatchPoll             WatchMode = "poll"
+)
+
+// watchLadder are the modes of a watch, from the most capable one.
+var watchLadder = []WatchMode{WatchMonitorCondSince, WatchMonitorCond, WatchMonitor, WatchPoll}
+
+// WatchConfig configures the polls of a watch which falls back to polling.
+type WatchConfig struct {
+	// PollInterval is the interval between the polls of a table, 10
+	// seconds by default.
+	PollInterval time.Duration
+	// Poll is the policy of the polls.
+	Poll PollPolicy
+}
+
+// Watch follows the changes of the rows of tables, started by
+// Client.Watch, with a Monitor, or a Poller.
+type Watch struct {
+	// Updates receives the rows, then their changes, see Monitor.Updates
+	// and Poller.Updates. It is closed when the watch ends, see Err.
+	Updates <-chan TableUpdates
+	// Mode is how the watch follows the changes of the rows.
+	Mode WatchMode
+
+	feed cacheFeed
+}
+
+// Watch follows the changes of the rows of the tables with the most
+// capable mode the server supports: it starts from the monitor method of
+// the capabilities of the server, or from monitor_cond_since when they
+// are unknown, and moves down to monitor_cond, monitor, then polling, as
+// the server rejects the methods, so that it works against old servers,
+// e.g. those predating monitor_cond_since, which came with Open vSwitch
+// 2.12. The tables with conditions skip the monitor method.
+func (c *Client) Watch(ctx context.Context, db string, requests map[string]MonitorRequest, config WatchConfig) (*Watch, error) {
+	return c.watch(ctx, db, requests, config, monitorSeed{})
+}
+
+func (c *Client) watch(ctx context.Context, db string, requests map[string]MonitorRequest, config WatchConfig, seed monitorSeed) (*Watch, error) {
+	if c == nil {
+		return nil, fmt.Errorf("watch failed: interface is unavailable")
+	}
+	ladder := watchLadder
+	if c.Handshake || c.pinned {
+		for i, mode := range watchLadder {
+			if string(mode) == c.Capabilities().MonitorMethod() {
+				ladder = watchLadder[i:]
+				break
+			}
+		}
+	}
+	conditions := false
+	for _, request := range requests {
+		if len(request.Where) > 0 {
+			conditions = true
+		}
+	}
+	for _, mode := range ladder {
+		if mode == WatchPoll {
+			break
+		}
+		if mode == WatchMonitor && conditions {
+			continue
+		}
+		seed.method = string(mode)
+		m, err := c.monitorTables(ctx, db, requests, seed)
+		if err == nil {
+			return &Watch{Updates: m.Updates, Mode: mode, feed: m}, nil
+		}
+		if !isUnknownMethod(err) {
+			return nil, err
+		}
+		c.logger().Debugf("'%s' method is not supported, falling back: %v", mode, err)
+	}
+	polls := make(map[string]PollRequest, len(requests))
+	for table, request := range requests {
+		polls[table] = PollRequest{Columns: request.Columns, Where: request.Where, Interval: config.PollInterval}
+	}
+	p, err := c.poll(ctx, db, polls, config.Poll, seed.sink)
+	if err != nil {
+		return nil, err
+	}
+	return &Watch{Updates: p.Updates, Mode: WatchPoll, feed: p}, nil
+}
+
+// isUnknownMethod returns true when the server rejected the request, as it
+// does not know its method.
+func isUnknownMethod(err error) bool {
+	var e *OvsdbError
+	return errors.As(err, &e) && e.Message == "unknown method"
+}
+
+// Cancel cancels the monitor, or the poller, of the watch, and closes its
+// Updates.
+func (w *Watch) Cancel(ctx context.Context) error {
+	return w.feed.Cancel(ctx)
+}
+
+// Err returns why the watch ended, see Monitor.Err and Poller.Err.
+func (w *Watch) Err() error {
+	return w.feed.Err()
+}
+
+// SetConditions replaces the conditions on the rows of the watched tables,
+// see Monitor.SetConditions and Poller.SetConditions. A watch of the
+// monitor mode does not support them.
+func (w *Watch) SetConditions(ctx context.Context, where map[string][]Condition) error {
+	return w.feed.SetConditions(ctx, where)
+}
+
+// NewWatchCache is like NewCacheTables, but keeps the cache up to date
+// with a Watch, so that it works against the servers which support none
+// of the monitor methods, see Cache.Mode.
+func (c *Client) NewWatchCache(ctx context.Context, db string, requests map[string]MonitorRequest, config WatchConfig) (*Cache, error) {
+	schema, err := c.GetSchemaContext(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	cache := newCacheOf(c, db, schema, requests)
+	w, err := c.watch(ctx, db, requests, config, cache.seed())
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	if err := cache.follow(ctx, w.feed, w.Updates); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// Mode returns how the cache follows the changes of the rows.
+func (c *Cache) Mode() WatchMode {
+	if m, ok := c.feed.(*Monitor); ok {
+		return WatchMode(m.Method)
+	}
+	return WatchPoll
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 Paul Greenberg (greenpau@outlook.com)
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// newTestWatchServer serves the Bridge table with the monitor methods,
+// while it rejects those which are not supported as unknown, and with
+// the select operation.
+func newTestWatchServer(t *testing.T, supported ...string) (string, func() []string) {
+	const br0 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("FAIL: failed to listen: %v", err)
+	}
+	var mu sync.Mutex
+	var methods []string
+	newTestServer(t, l, func(method string, params json.RawMessage) interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		switch method {
+		case "get_schema":
+			return json.RawMessage(testModelSchema)
+		case "monitor", "monitor_cond", "monitor_cond_since":
+			methods = append(methods, method)
+			if !contains(supported, method) {
+				return testServerError{err: map[string]interface{}{"error": "unknown method", "details": method}}
+			}
+			return map[string]interface{}{"Bridge": map[string]interface{}{
+				br0: map[string]interface{}{"new": map[string]interface{}{"name": "br0"}},
+			}}
+		case "transact":
+			methods = append(methods, method)
+			return []interface{}{map[string]interface{}{"rows": []interface{}{
+				map[string]interface{}{"_uuid": []interface{}{"uuid", br0}, "name": "br0"},
+			}}}
+		}
+		return nil
+	})
+	return "tcp:" + l.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), methods...)
+	}
+}
+
+func TestClientWatch(t *testing.T) {
+	const br0 = "36bd7b30-5bc5-4e7a-8a23-e9a7f1a3ae7c"
+	ctx := context.Background()
+	remote, methods := newTestWatchServer(t, "monitor")
+	c, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c.Close()
+	requests := map[string]MonitorRequest{"Bridge": {Columns: []string{"name"}}}
+	w, err := c.Watch(ctx, "Open_vSwitch", requests, WatchConfig{})
+	if err != nil {
+		t.Fatalf("FAIL: expected the watch to start, but failed with: %v", err)
+	}
+	defer w.Cancel(ctx)
+	if w.Mode != WatchMonitor {
+		t.Fatalf("FAIL: expected the watch to fall back to the monitor method, got: %s", w.Mode)
+	}
+	if u := <-w.Updates; u["Bridge"][br0].New["name"] != "br0" {
+		t.Fatalf("FAIL: unexpected updates: %v", u)
+	}
+	if got, want := methods(), []string{"monitor_cond_since", "monitor_cond", "monitor"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("FAIL: unexpected methods: %q", got)
+	}
+	t.Logf("PASS: watch fell back to %s", w.Mode)
+
+	// The conditions require monitor_cond.
+	requests = map[string]MonitorRequest{"Bridge": {Columns: []string{"name"}, Where: []Condition{{Column: "name", Function: "==", Value: "br0", Type: "string"}}}}
+	p, err := c.Watch(ctx, "Open_vSwitch", requests, WatchConfig{})
+	if err != nil {
+		t.Fatalf("FAIL: expected the watch to start, but failed with: %v", err)
+	}
+	defer p.Cancel(ctx)
+	if p.Mode != WatchPoll {
+		t.Fatalf("FAIL: expected the watch to fall back to polling, got: %s", p.Mode)
+	}
+	if u := <-p.Updates; u["Bridge"][br0].New["name"] != "br0" {
+		t.Fatalf("FAIL: unexpected updates: %v", u)
+	}
+	t.Logf("PASS: watch fell back to %s", p.Mode)
+
+	remote, _ = newTestWatchServer(t)
+	c2, err := NewClient(remote, 1)
+	if err != nil {
+		t.Fatalf("FAIL: expected to connect, but failed with: %v", err)
+	}
+	defer c2.Close()
+	cache, err := c2.NewWatchCache(ctx, "Open_vSwitch", map[string]MonitorRequest{"Bridge": {}}, WatchConfig{})
+	if err != nil {
+		t.Fatalf("FAIL: expected the cache to sync, but failed with: %v", err)
+	}
+	defer cache.Close(ctx)
+	if cache.Mode() != WatchPoll || cache.Len("Bridge") != 1 {
+		t.Fatalf("FAIL: expected a polling cache of the bridge, got: %s, %d rows", cache.Mode(), cache.Len("Bridge"))
+	}
+	t.Logf("PASS: cache follows the rows with %s", cache.Mode())
+}